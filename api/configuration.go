@@ -9,4 +9,7 @@ type Configuration struct {
 	RequestLoggingLevel  string
 	TokenLifetimeMinute  int
 	SegmentWriteKey      string
+	// The proxies whose X-Forwarded-For header is trusted to find the IP of the clients, checked against the IP
+	// allowlists of the API keys. No proxy is trusted if empty: the IP of the client is the remote address.
+	TrustedProxies []string
 }
@@ -16,6 +16,7 @@ import (
 type dependencies struct {
 	Authentication Authentication
 	TokenHandler   TokenHandler
+	TokenValidator *token.Validator
 	SegmentClient  analytics.Client
 }
 
@@ -33,5 +34,6 @@ func InitDependencies(ctx context.Context, conf Configuration, dbPool *pgxpool.P
 		Authentication: NewAuthentication(tokenValidator),
 		SegmentClient:  segmentClient,
 		TokenHandler:   NewTokenHandler(tokenGenerator),
+		TokenValidator: tokenValidator,
 	}
 }
@@ -1,12 +1,13 @@
 package api
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/checkmarble/marble-backend/dto"
-	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/pure_utils"
 	"github.com/checkmarble/marble-backend/utils"
 )
@@ -34,12 +35,13 @@ func (api *API) handlePostApiKey(c *gin.Context) {
 		return
 	}
 
+	createInput, err := dto.AdaptCreateApiKeyInput(organizationId, input)
+	if presentError(c, err) {
+		return
+	}
+
 	usecase := api.UsecasesWithCreds(c.Request).NewApiKeyUseCase()
-	apiKey, err := usecase.CreateApiKey(c.Request.Context(), models.CreateApiKeyInput{
-		OrganizationId: organizationId,
-		Description:    input.Description,
-		Role:           models.RoleFromString(input.Role),
-	})
+	apiKey, err := usecase.CreateApiKey(c.Request.Context(), createInput)
 	if presentError(c, err) {
 		return
 	}
@@ -64,3 +66,24 @@ func (api *API) handleRevokeApiKey(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (api *API) handleRotateApiKey(c *gin.Context) {
+	var apiKeyUriInput ApiKeyUriInput
+	if err := c.ShouldBindUri(&apiKeyUriInput); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var data dto.RotateApiKeyBody
+	if err := c.ShouldBindJSON(&data); err != nil && !errors.Is(err, io.EOF) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewApiKeyUseCase()
+	apiKey, err := usecase.RotateApiKey(c.Request.Context(),
+		dto.AdaptRotateApiKeyInput(apiKeyUriInput.ApiKeyId, data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"api_key": dto.AdaptCreatedApiKeyDto(apiKey)})
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 
 	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/models/ast"
 )
 
@@ -23,3 +26,52 @@ func (api *API) handleAvailableFunctions(c *gin.Context) {
 		"functions": functions,
 	})
 }
+
+func (api *API) handleParseFormula(c *gin.Context) {
+	var input dto.ParseFormulaBody
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	node, err := ast.ParseDsl(input.Formula)
+	var syntaxErr ast.DslSyntaxError
+	if errors.As(err, &syntaxErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": dto.FormulaSyntaxErrorDto{
+				Message: syntaxErr.Message,
+				Line:    syntaxErr.Position.Line,
+				Column:  syntaxErr.Position.Column,
+			},
+		})
+		return
+	} else if presentError(c, err) {
+		return
+	}
+
+	nodeDto, err := dto.AdaptNodeDto(node)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ast_expression": nodeDto})
+}
+
+func (api *API) handleFormatFormula(c *gin.Context) {
+	var input dto.FormatFormulaBody
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	node, err := dto.AdaptASTNode(input.AstExpression)
+	if err != nil {
+		presentError(c, fmt.Errorf("invalid ast expression: %w %w", err, models.BadParameterError))
+		return
+	}
+	formula, err := ast.FormatDsl(node)
+	if err != nil {
+		presentError(c, fmt.Errorf("ast expression cannot be formatted: %w %w", err, models.BadParameterError))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"formula": formula})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+var auditEventPaginationDefaults = dto.PaginationDefaults{
+	Limit:  25,
+	SortBy: "created_at",
+	Order:  models.SortingOrderDesc,
+}
+
+func (api *API) handleListAuditEvents(c *gin.Context) {
+	organizationId, err := utils.OrganizationIdFromRequest(c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var filters dto.AuditEventFilters
+	if err := c.ShouldBind(&filters); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var paginationAndSorting dto.PaginationAndSortingInput
+	if err := c.ShouldBind(&paginationAndSorting); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	paginationAndSorting = dto.WithPaginationDefaults(paginationAndSorting, auditEventPaginationDefaults)
+
+	usecase := api.UsecasesWithCreds(c.Request).NewAuditEventUsecase()
+	events, hasMore, err := usecase.ListAuditEvents(c.Request.Context(), organizationId,
+		dto.AdaptAuditEventFilters(filters), dto.AdaptPaginationAndSortingInput(paginationAndSorting))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":    pure_utils.Map(events, dto.AdaptAuditEvent),
+		"has_more": hasMore,
+	})
+}
+
+// handleExportAuditEvents downloads all the audit events matching the filters as newline delimited JSON
+func (api *API) handleExportAuditEvents(c *gin.Context) {
+	organizationId, err := utils.OrganizationIdFromRequest(c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var filters dto.AuditEventFilters
+	if err := c.ShouldBind(&filters); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Writer.Header().Set("Content-Disposition", "attachment; filename=\"audit_events.ndjson\"")
+	usecase := api.UsecasesWithCreds(c.Request).NewAuditEventUsecase()
+	_, err = usecase.ExportAuditEvents(c.Request.Context(), organizationId,
+		dto.AdaptAuditEventFilters(filters), c.Writer)
+	if err != nil {
+		// note: the error can only be presented if nothing has been written yet, like a security error
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		presentError(c, err)
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+)
+
+// handleDownloadBlob serves the files of the local blob storage backend through their signed download urls
+func (api *API) handleDownloadBlob(c *gin.Context) {
+	var params dto.DownloadBlobParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	fileName := strings.TrimPrefix(c.Param("file_name"), "/")
+
+	usecase := api.usecases.NewBlobDownloadUsecase()
+	blob, err := usecase.OpenSignedBlob(c.Request.Context(), c.Param("bucket"), fileName, params.Expires, params.Signature)
+	if presentError(c, err) {
+		return
+	}
+	defer blob.Reader.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(fileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, -1, contentType, blob.Reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", path.Base(fileName)),
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+// handleListCustomRolePermissions lists the permissions which can be given by a custom role
+func (api *API) handleListCustomRolePermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": pure_utils.Map(models.CUSTOM_ROLE_PERMISSIONS, models.Permission.String),
+	})
+}
+
+func (api *API) handleListCustomRoles(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	customRoles, err := usecase.ListCustomRoles(c.Request.Context(), c.Param("organization_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"custom_roles": pure_utils.Map(customRoles, dto.AdaptCustomRole)})
+}
+
+func (api *API) handleGetCustomRole(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	customRole, err := usecase.GetCustomRole(c.Request.Context(), c.Param("organization_id"), c.Param("custom_role_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"custom_role": dto.AdaptCustomRole(customRole)})
+}
+
+func (api *API) handleCreateCustomRole(c *gin.Context) {
+	var data dto.CreateCustomRoleBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	input, err := dto.AdaptCreateCustomRoleInput(c.Param("organization_id"), data)
+	if presentError(c, err) {
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	customRole, err := usecase.CreateCustomRole(c.Request.Context(), input)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"custom_role": dto.AdaptCustomRole(customRole)})
+}
+
+func (api *API) handlePatchCustomRole(c *gin.Context) {
+	var data dto.UpdateCustomRoleBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	input, err := dto.AdaptUpdateCustomRoleInput(c.Param("custom_role_id"), data)
+	if presentError(c, err) {
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	customRole, err := usecase.UpdateCustomRole(c.Request.Context(), c.Param("organization_id"), input)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"custom_role": dto.AdaptCustomRole(customRole)})
+}
+
+func (api *API) handleDeleteCustomRole(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	err := usecase.DeleteCustomRole(c.Request.Context(), c.Param("organization_id"), c.Param("custom_role_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handlePutUserCustomRole(c *gin.Context) {
+	var data dto.AssignCustomRoleBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	user, err := usecase.SetUserCustomRole(c.Request.Context(), c.Param("organization_id"),
+		models.UserId(c.Param("user_id")), data.CustomRoleId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": dto.AdaptUserDto(user)})
+}
+
+func (api *API) handlePutApiKeyCustomRole(c *gin.Context) {
+	var data dto.AssignCustomRoleBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewCustomRoleUsecase()
+	apiKey, err := usecase.SetApiKeyCustomRole(c.Request.Context(), c.Param("organization_id"),
+		c.Param("api_key_id"), data.CustomRoleId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"api_key": dto.AdaptApiKeyDto(apiKey)})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/checkmarble/marble-backend/dto"
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
 	"github.com/checkmarble/marble-backend/utils"
 )
 
@@ -185,6 +186,61 @@ func (api *API) DeleteDataModel(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+type deleteDataModelElementQuery struct {
+	// keep the ingested data under another name instead of dropping it
+	Archive bool `form:"archive"`
+}
+
+// presentDataModelDeletion answers a deletion refused because the element is used with the list of the elements using
+// it
+func presentDataModelDeletion(c *gin.Context, dependencies []models.DataModelDependency, err error) {
+	if presentError(c, err) {
+		return
+	}
+	if len(dependencies) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"dependencies": pure_utils.Map(dependencies, dto.AdaptDataModelDependencyDto),
+		})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) DeleteDataModelTable(c *gin.Context) {
+	var query deleteDataModelElementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDataModelUseCase()
+	dependencies, err := usecase.DeleteDataModelTable(c.Request.Context(), c.Param("tableID"), query.Archive)
+	presentDataModelDeletion(c, dependencies, err)
+}
+
+func (api *API) DeleteDataModelField(c *gin.Context) {
+	var query deleteDataModelElementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDataModelUseCase()
+	dependencies, err := usecase.DeleteDataModelField(c.Request.Context(), c.Param("fieldID"), query.Archive)
+	presentDataModelDeletion(c, dependencies, err)
+}
+
+func (api *API) DeleteDataModelLink(c *gin.Context) {
+	organizationID, err := utils.OrganizationIdFromRequest(c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDataModelUseCase()
+	dependencies, err := usecase.DeleteDataModelLink(c.Request.Context(), organizationID, c.Param("linkID"))
+	presentDataModelDeletion(c, dependencies, err)
+}
+
 func (api *API) OpenAPI(c *gin.Context) {
 	organizationID, err := utils.OrganizationIdFromRequest(c.Request)
 	if presentError(c, err) {
@@ -24,12 +24,85 @@ func (api *API) handleGetDecision(c *gin.Context) {
 
 	usecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
 	decision, err := usecase.GetDecision(c.Request.Context(), decisionID)
+	if errors.Is(err, models.NotFoundError) {
+		// the decision may not be taken yet, or may have failed, if it was created asynchronously
+		asyncDecision, asyncErr := usecase.GetAsyncDecision(c.Request.Context(), decisionID)
+		if asyncErr == nil {
+			status := http.StatusAccepted
+			if asyncDecision.Status == models.AsyncDecisionFailure {
+				status = http.StatusUnprocessableEntity
+			}
+			c.JSON(status, dto.NewAPIAsyncDecision(asyncDecision, api.marbleAppHost))
+			return
+		}
+	}
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIDecisionWithRule(decision, api.marbleAppHost, true))
+}
+
+func (api *API) handleReviewDecision(c *gin.Context) {
+	decisionID := c.Param("decision_id")
+
+	var requestData dto.ReviewDecisionBody
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	decision, err := usecase.ReviewDecision(c.Request.Context(), models.ReviewDecisionInput{
+		DecisionId:  decisionID,
+		Disposition: models.DecisionReviewDisposition(requestData.Disposition),
+		Note:        requestData.Note,
+	})
 	if presentError(c, err) {
 		return
 	}
 	c.JSON(http.StatusOK, dto.NewAPIDecisionWithRule(decision, api.marbleAppHost, true))
 }
 
+func (api *API) handlePostAsyncDecision(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var requestData dto.CreateDecisionWithScenarioBody
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	decisionUsecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	asyncDecision, err := decisionUsecase.CreateAsyncDecision(
+		c.Request.Context(),
+		models.CreateAsyncDecisionInput{
+			OrganizationId:     organizationId,
+			PayloadRaw:         requestData.TriggerObjectRaw,
+			ScenarioId:         requestData.ScenarioId,
+			TriggerObjectTable: requestData.TriggerObjectType,
+		},
+	)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusAccepted, dto.NewAPIAsyncDecision(
+		models.AsyncDecisionWithResult{AsyncDecision: asyncDecision}, api.marbleAppHost))
+}
+
+func (api *API) handleGetAsyncDecision(c *gin.Context) {
+	decisionID := c.Param("decision_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	asyncDecision, err := usecase.GetAsyncDecision(c.Request.Context(), decisionID)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIAsyncDecision(asyncDecision, api.marbleAppHost))
+}
+
 func (api *API) handleListDecisions(c *gin.Context) {
 	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
 	if presentError(c, err) {
@@ -92,8 +165,31 @@ func (api *API) handlePostDecision(c *gin.Context) {
 		return
 	}
 
-	// make a decision
 	decisionUsecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+
+	var dryRunParams dto.DryRunDecisionParams
+	if err := c.ShouldBindQuery(&dryRunParams); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	if dryRunParams.DryRun {
+		decision, err := decisionUsecase.DryRunDecision(
+			c.Request.Context(),
+			models.DryRunDecisionInput{
+				OrganizationId:     organizationId,
+				PayloadRaw:         requestData.TriggerObjectRaw,
+				ScenarioId:         requestData.ScenarioId,
+				TriggerObjectTable: requestData.TriggerObjectType,
+			},
+		)
+		if returnExpectedDecisionError(c, err) || presentError(c, err) {
+			return
+		}
+		c.JSON(http.StatusOK, dto.NewAPIDecisionWithRule(decision, api.marbleAppHost, true))
+		return
+	}
+
+	// make a decision
 	decision, err := decisionUsecase.CreateDecision(
 		c.Request.Context(),
 		models.CreateDecisionInput{
@@ -101,6 +197,7 @@ func (api *API) handlePostDecision(c *gin.Context) {
 			PayloadRaw:         requestData.TriggerObjectRaw,
 			ScenarioId:         requestData.ScenarioId,
 			TriggerObjectTable: requestData.TriggerObjectType,
+			IdempotencyKey:     c.GetHeader(models.IDEMPOTENCY_KEY_HEADER),
 		},
 		false,
 		true,
@@ -112,6 +209,35 @@ func (api *API) handlePostDecision(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.NewAPIDecisionWithRule(decision, api.marbleAppHost, false))
 }
 
+func (api *API) handleDryRunScenarioIteration(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+	iterationId := c.Param("iteration_id")
+
+	var requestData dto.CreateDecisionBody
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	decisionUsecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	decision, err := decisionUsecase.DryRunDecision(
+		c.Request.Context(),
+		models.DryRunDecisionInput{
+			OrganizationId:      organizationId,
+			PayloadRaw:          requestData.TriggerObjectRaw,
+			TriggerObjectTable:  requestData.TriggerObjectType,
+			ScenarioIterationId: &iterationId,
+		},
+	)
+	if returnExpectedDecisionError(c, err) || presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIDecisionWithRule(decision, api.marbleAppHost, true))
+}
+
 func returnExpectedDecisionError(c *gin.Context, err error) bool {
 	if err == nil {
 		return false
@@ -148,6 +274,7 @@ func (api *API) handlePostAllDecisions(c *gin.Context) {
 			OrganizationId:     organizationId,
 			PayloadRaw:         requestData.TriggerObjectRaw,
 			TriggerObjectTable: requestData.TriggerObjectType,
+			IdempotencyKey:     c.GetHeader(models.IDEMPOTENCY_KEY_HEADER),
 		},
 	)
 	if presentError(c, err) {
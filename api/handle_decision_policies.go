@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type DecisionPolicyUriInput struct {
+	DecisionPolicyId string `uri:"policy_id" binding:"required,uuid"`
+}
+
+func (api *API) handleListDecisionPolicies(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionPolicyUsecase()
+	policies, err := usecase.ListDecisionPolicies(c.Request.Context(), organizationId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"decision_policies": pure_utils.Map(policies, dto.AdaptDecisionPolicyDto)})
+}
+
+func (api *API) handlePostDecisionPolicy(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var data dto.CreateDecisionPolicyBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionPolicyUsecase()
+	policy, err := usecase.CreateDecisionPolicy(c.Request.Context(),
+		dto.AdaptCreateDecisionPolicyInput(organizationId, data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"decision_policy": dto.AdaptDecisionPolicyDto(policy)})
+}
+
+func (api *API) handleGetDecisionPolicy(c *gin.Context) {
+	var uriInput DecisionPolicyUriInput
+	if err := c.ShouldBindUri(&uriInput); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionPolicyUsecase()
+	policy, err := usecase.GetDecisionPolicy(c.Request.Context(), uriInput.DecisionPolicyId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"decision_policy": dto.AdaptDecisionPolicyDto(policy)})
+}
+
+func (api *API) handlePatchDecisionPolicy(c *gin.Context) {
+	var uriInput DecisionPolicyUriInput
+	if err := c.ShouldBindUri(&uriInput); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var data dto.UpdateDecisionPolicyBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionPolicyUsecase()
+	policy, err := usecase.UpdateDecisionPolicy(c.Request.Context(),
+		dto.AdaptUpdateDecisionPolicyInput(uriInput.DecisionPolicyId, data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"decision_policy": dto.AdaptDecisionPolicyDto(policy)})
+}
+
+func (api *API) handleDeleteDecisionPolicy(c *gin.Context) {
+	var uriInput DecisionPolicyUriInput
+	if err := c.ShouldBindUri(&uriInput); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewDecisionPolicyUsecase()
+	err := usecase.DeleteDecisionPolicy(c.Request.Context(), uriInput.DecisionPolicyId)
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handlePostPolicyDecision(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var requestData dto.CreatePolicyDecisionBody
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	decisionUsecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	policyDecision, err := decisionUsecase.CreatePolicyDecision(
+		c.Request.Context(),
+		models.CreatePolicyDecisionInput{
+			OrganizationId:     organizationId,
+			PayloadRaw:         requestData.TriggerObjectRaw,
+			TriggerObjectTable: requestData.TriggerObjectType,
+		},
+	)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIPolicyDecision(policyDecision, api.marbleAppHost, false))
+}
+
+func (api *API) handleGetPolicyDecision(c *gin.Context) {
+	policyDecisionId := c.Param("policy_decision_id")
+
+	decisionUsecase := api.UsecasesWithCreds(c.Request).NewDecisionUsecase()
+	policyDecision, err := decisionUsecase.GetPolicyDecision(c.Request.Context(), policyDecisionId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAPIPolicyDecision(policyDecision, api.marbleAppHost, true))
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+)
+
+// handleExportOrganizationConfig downloads the configuration of the organization, to be applied to another organization
+func (api *API) handleExportOrganizationConfig(c *gin.Context) {
+	organizationId := c.Param("organization_id")
+	usecase := api.UsecasesWithCreds(c.Request).NewOrganizationConfigUsecase()
+	config, err := usecase.ExportOrganizationConfig(c.Request.Context(), organizationId)
+	if presentError(c, err) {
+		return
+	}
+	c.Header("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"organization_config_%s.json\"", organizationId))
+	c.JSON(http.StatusOK, dto.AdaptOrganizationConfigDto(config))
+}
+
+func (api *API) handlePlanOrganizationConfig(c *gin.Context) {
+	var data dto.OrganizationConfigDto
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewOrganizationConfigUsecase()
+	plan, err := usecase.PlanOrganizationConfig(c.Request.Context(), c.Param("organization_id"),
+		dto.AdaptOrganizationConfig(data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, dto.AdaptOrganizationConfigPlanDto(plan))
+}
+
+// handleApplyOrganizationConfig applies the configuration unless its plan has conflicts, in which case nothing is
+// changed and the plan is returned with a 409 status
+func (api *API) handleApplyOrganizationConfig(c *gin.Context) {
+	var data dto.OrganizationConfigDto
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewOrganizationConfigUsecase()
+	plan, err := usecase.ApplyOrganizationConfig(c.Request.Context(), c.Param("organization_id"),
+		dto.AdaptOrganizationConfig(data))
+	if presentError(c, err) {
+		return
+	}
+	status := http.StatusOK
+	if len(plan.Conflicts) > 0 {
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.AdaptOrganizationConfigPlanDto(plan))
+}
@@ -14,6 +14,7 @@ import (
 
 type tokenGenerator interface {
 	GenerateToken(ctx context.Context, key string, firebaseToken string) (string, time.Time, error)
+	FromUser(ctx context.Context, user models.User) (string, time.Time, error)
 }
 
 type TokenHandler struct {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/checkmarble/marble-backend/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,6 +23,11 @@ func (m *mockGenerator) GenerateToken(ctx context.Context, key string, firebaseT
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
 
+func (m *mockGenerator) FromUser(ctx context.Context, user models.User) (string, time.Time, error) {
+	args := m.Called(ctx, user)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
 func TestToken_GenerateToken(t *testing.T) {
 	t.Run("nominal", func(t *testing.T) {
 		tok := accessToken{
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+// rateLimitMiddleware counts the request against the rate limits and monthly quota of the organization and of the API
+// key of the caller. A failure to count the request does not refuse it.
+func (api *API) rateLimitMiddleware(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewRateLimitUsecase()
+	status, err := usecase.Allow(c.Request.Context())
+	if err != nil {
+		utils.LogRequestError(c.Request, "could not count the request against the rate limits", "error", err.Error())
+		c.Next()
+		return
+	}
+	if status == nil {
+		c.Next()
+		return
+	}
+
+	for name, values := range dto.RateLimitHeaders(*status) {
+		c.Header(name, values[0])
+	}
+	if !status.Allowed {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, dto.APIErrorResponse{Message: status.Reason})
+		return
+	}
+	c.Next()
+}
+
+func (api *API) handleListRateLimits(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewRateLimitUsecase()
+	usages, err := usecase.ListRateLimits(c.Request.Context(), c.Param("organization_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rate_limits": pure_utils.Map(usages, dto.AdaptRateLimit)})
+}
+
+func (api *API) handlePutRateLimit(c *gin.Context) {
+	var data dto.RateLimitPolicy
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewRateLimitUsecase()
+	err := usecase.UpsertRateLimit(c.Request.Context(),
+		dto.AdaptUpsertRateLimitInput(c.Param("organization_id"), apiKeyIdParam(c), data))
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handleDeleteRateLimit(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewRateLimitUsecase()
+	err := usecase.DeleteRateLimit(c.Request.Context(), c.Param("organization_id"), apiKeyIdParam(c))
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// apiKeyIdParam is the API key of the rate limit routes, or nil for the rate limits of the organization
+func apiKeyIdParam(c *gin.Context) *string {
+	if apiKeyId := c.Param("api_key_id"); apiKeyId != "" {
+		return &apiKeyId
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func (api *API) getRulePerformanceReport(c *gin.Context) (models.RulePerformanceReport, bool) {
+	var params dto.RulePerformanceParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Status(http.StatusBadRequest)
+		return models.RulePerformanceReport{}, false
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewRulePerformanceUsecase()
+	report, err := usecase.GetRulePerformanceReport(c.Request.Context(), models.RulePerformanceReportInput{
+		ScenarioId: c.Param("scenario_id"),
+		StartDate:  params.StartDate,
+		EndDate:    params.EndDate,
+	})
+	if presentError(c, err) {
+		return models.RulePerformanceReport{}, false
+	}
+	return report, true
+}
+
+func (api *API) handleGetRulePerformance(c *gin.Context) {
+	report, ok := api.getRulePerformanceReport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dto.AdaptRulePerformanceReport(report))
+}
+
+func (api *API) handleGetRulePerformanceCsv(c *gin.Context) {
+	report, ok := api.getRulePerformanceReport(c)
+	if !ok {
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/csv")
+	c.Writer.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"rule_performance_%s.csv\"", report.ScenarioId))
+	if err := dto.WriteRulePerformanceReportCsv(c.Writer, report); err != nil {
+		presentError(c, err)
+	}
+}
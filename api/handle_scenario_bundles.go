@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func (api *API) handleExportScenario(c *gin.Context) {
+	var params dto.ExportScenarioParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	if params.Format == "" {
+		params.Format = "json"
+	}
+	if params.Format != "json" && params.Format != "yaml" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	scenarioId := c.Param("scenario_id")
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioBundleUsecase()
+	bundle, err := usecase.ExportScenario(c.Request.Context(), scenarioId)
+	if presentError(c, err) {
+		return
+	}
+	bundleDto, err := dto.AdaptScenarioBundleDto(bundle)
+	if presentError(c, err) {
+		return
+	}
+
+	c.Writer.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"scenario_%s.%s\"", scenarioId, params.Format))
+	if params.Format == "yaml" {
+		content, err := dto.MarshalScenarioBundleYaml(bundleDto)
+		if presentError(c, err) {
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml", content)
+		return
+	}
+	c.JSON(http.StatusOK, bundleDto)
+}
+
+// handleImportScenario accepts a bundle in JSON, or in YAML if the content type says so
+func (api *API) handleImportScenario(c *gin.Context) {
+	organizationId, err := utils.OrgIDFromCtx(c.Request.Context(), c.Request)
+	if presentError(c, err) {
+		return
+	}
+
+	var params dto.ImportScenarioParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var bundleDto dto.ScenarioBundleDto
+	if strings.Contains(c.ContentType(), "yaml") {
+		bundleDto, err = dto.UnmarshalScenarioBundleYaml(body)
+	} else {
+		err = json.Unmarshal(body, &bundleDto)
+	}
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	bundle, err := dto.AdaptScenarioBundle(bundleDto)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioBundleUsecase()
+	result, err := usecase.ImportScenario(c.Request.Context(), organizationId, bundle, params.DryRun)
+	if presentError(c, err) {
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case len(result.Report.Conflicts) > 0:
+		status = http.StatusConflict
+	case params.DryRun:
+		status = http.StatusOK
+	}
+	c.JSON(status, dto.AdaptScenarioImportResultDto(result))
+}
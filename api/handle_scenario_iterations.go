@@ -109,6 +109,27 @@ func (api *API) GetScenarioIteration(c *gin.Context) {
 	c.JSON(http.StatusOK, scenarioIterationDto)
 }
 
+func (api *API) DiffScenarioIteration(c *gin.Context) {
+	iterationID := c.Param("iteration_id")
+	var params dto.DiffScenarioIterationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioIterationUsecase()
+	diff, err := usecase.DiffScenarioIteration(c.Request.Context(), iterationID, params.BaseIterationId)
+	if presentError(c, err) {
+		return
+	}
+
+	diffDto, err := dto.AdaptScenarioIterationDiffDto(diff)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, diffDto)
+}
+
 func (api *API) UpdateScenarioIteration(c *gin.Context) {
 	organizationId, err := utils.OrganizationIdFromRequest(c.Request)
 	if presentError(c, err) {
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func (api *API) handleListScenarioTemplates(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	templates, err := usecase.ListScenarioTemplates(c.Request.Context())
+	if presentError(c, err) {
+		return
+	}
+	templatesDto, err := pure_utils.MapErr(templates, dto.AdaptScenarioTemplateDto)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scenario_templates": templatesDto})
+}
+
+func (api *API) handleGetScenarioTemplate(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	template, err := usecase.GetScenarioTemplate(c.Request.Context(), c.Param("template_id"))
+	if presentError(c, err) {
+		return
+	}
+	templateDto, err := dto.AdaptScenarioTemplateDto(template)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scenario_template": templateDto})
+}
+
+func (api *API) handleCreateScenarioTemplate(c *gin.Context) {
+	var data dto.CreateScenarioTemplateBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	input, err := dto.AdaptCreateScenarioTemplateInput(data)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	template, err := usecase.CreateScenarioTemplate(c.Request.Context(), input)
+	if presentError(c, err) {
+		return
+	}
+	templateDto, err := dto.AdaptScenarioTemplateDto(template)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"scenario_template": templateDto})
+}
+
+func (api *API) handlePatchScenarioTemplate(c *gin.Context) {
+	var data dto.UpdateScenarioTemplateBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	input, err := dto.AdaptUpdateScenarioTemplateInput(c.Param("template_id"), data)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	template, err := usecase.UpdateScenarioTemplate(c.Request.Context(), input)
+	if presentError(c, err) {
+		return
+	}
+	templateDto, err := dto.AdaptScenarioTemplateDto(template)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scenario_template": templateDto})
+}
+
+func (api *API) handleDeleteScenarioTemplate(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	err := usecase.DeleteScenarioTemplate(c.Request.Context(), c.Param("template_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handleInstantiateScenarioTemplate creates a scenario from a template in the organization of the request, which the
+// Marble admins choose with the organization-id query parameter
+func (api *API) handleInstantiateScenarioTemplate(c *gin.Context) {
+	organizationId, err := utils.OrganizationIdFromRequest(c.Request)
+	if presentError(c, err) {
+		return
+	}
+	var data dto.InstantiateScenarioTemplateBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScenarioTemplateUsecase()
+	result, err := usecase.InstantiateScenarioTemplate(c.Request.Context(), models.InstantiateScenarioTemplateInput{
+		TemplateId:     c.Param("template_id"),
+		OrganizationId: organizationId,
+		ScenarioName:   data.ScenarioName,
+		Values:         data.Values,
+		DryRun:         data.DryRun,
+	})
+	if presentError(c, err) {
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case len(result.Report.Conflicts) > 0:
+		status = http.StatusConflict
+	case data.DryRun:
+		status = http.StatusOK
+	}
+	c.JSON(status, dto.AdaptScenarioTemplateInstantiationDto(result))
+}
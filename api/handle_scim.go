@@ -0,0 +1,322 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	sentrygin "github.com/getsentry/sentry-go/gin"
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const scimOrganizationIdKey = "scimOrganizationId"
+
+func scimJSON(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", "application/scim+json")
+	c.JSON(status, body)
+}
+
+// presentScimError is presentError for the SCIM endpoints, whose errors follow the SCIM format
+func presentScimError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	status, scimType := http.StatusInternalServerError, ""
+	switch {
+	case errors.Is(err, models.BadParameterError):
+		status, scimType = http.StatusBadRequest, "invalidValue"
+	case errors.Is(err, models.UnAuthorizedError):
+		status = http.StatusUnauthorized
+	case errors.Is(err, models.ForbiddenError):
+		status = http.StatusForbidden
+	case errors.Is(err, models.NotFoundError):
+		status = http.StatusNotFound
+	case errors.Is(err, models.ConflictError):
+		status, scimType = http.StatusConflict, "uniqueness"
+	}
+
+	detail := err.Error()
+	if status == http.StatusInternalServerError {
+		utils.LogRequestError(c.Request, fmt.Sprintf("Unexpected Error: %+v", err))
+		if hub := sentrygin.GetHubFromContext(c); hub != nil {
+			hub.CaptureException(err)
+		} else {
+			sentry.CaptureException(err)
+		}
+		detail = "An unexpected error occurred"
+	} else {
+		utils.LogRequestInfo(c.Request, fmt.Sprintf("SCIM error: %v", err))
+	}
+
+	scimJSON(c, status, dto.ScimError{
+		Schemas:  []string{dto.SCIM_SCHEMA_ERROR},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+	return true
+}
+
+// scimAuthentication authenticates the identity provider with the SCIM token of its organization
+func (api *API) scimAuthentication(c *gin.Context) {
+	token, err := ParseAuthorizationBearerHeader(c.Request.Header)
+	if err == nil && token == "" {
+		err = fmt.Errorf("missing SCIM token: %w", models.UnAuthorizedError)
+	}
+	if presentScimError(c, err) {
+		c.Abort()
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	organizationId, err := usecase.OrganizationIdOfToken(c.Request.Context(), token)
+	if presentScimError(c, err) {
+		c.Abort()
+		return
+	}
+	c.Set(scimOrganizationIdKey, organizationId)
+	c.Next()
+}
+
+func bindScimJSON(c *gin.Context, body any) bool {
+	if err := c.ShouldBindJSON(body); err != nil {
+		presentScimError(c, fmt.Errorf("%w: %w", models.BadParameterError, err))
+		return false
+	}
+	return true
+}
+
+func (api *API) handleScimServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, dto.NewScimServiceProviderConfig())
+}
+
+func (api *API) handleScimListUsers(c *gin.Context) {
+	var params dto.ScimListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		presentScimError(c, fmt.Errorf("%w: %w", models.BadParameterError, err))
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	users, total, err := usecase.ListUsers(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		params.Filter, params.StartIndexOrDefault(), params.CountOrDefault())
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.NewScimListResponse(
+		pure_utils.Map(users, dto.AdaptScimUser), total, params.StartIndexOrDefault()))
+}
+
+func (api *API) handleScimGetUser(c *gin.Context) {
+	usecase := api.usecases.NewScimUsecase()
+	user, err := usecase.GetUser(c.Request.Context(), c.GetString(scimOrganizationIdKey), c.Param("user_id"))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimUser(user))
+}
+
+func (api *API) handleScimCreateUser(c *gin.Context) {
+	var body dto.ScimUserBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	user, err := usecase.CreateUser(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		dto.AdaptScimUserInput(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusCreated, dto.AdaptScimUser(user))
+}
+
+func (api *API) handleScimReplaceUser(c *gin.Context) {
+	var body dto.ScimUserBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	user, err := usecase.ReplaceUser(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		c.Param("user_id"), dto.AdaptScimUserInput(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimUser(user))
+}
+
+func (api *API) handleScimPatchUser(c *gin.Context) {
+	var body dto.ScimPatchBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	user, err := usecase.PatchUser(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		c.Param("user_id"), dto.AdaptScimPatchOperations(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimUser(user))
+}
+
+func (api *API) handleScimDeleteUser(c *gin.Context) {
+	usecase := api.usecases.NewScimUsecase()
+	err := usecase.DeleteUser(c.Request.Context(), c.GetString(scimOrganizationIdKey), c.Param("user_id"))
+	if presentScimError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handleScimListGroups(c *gin.Context) {
+	var params dto.ScimListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		presentScimError(c, fmt.Errorf("%w: %w", models.BadParameterError, err))
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	groups, total, err := usecase.ListGroups(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		params.Filter, params.StartIndexOrDefault(), params.CountOrDefault())
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.NewScimListResponse(
+		pure_utils.Map(groups, dto.AdaptScimGroup), total, params.StartIndexOrDefault()))
+}
+
+func (api *API) handleScimGetGroup(c *gin.Context) {
+	usecase := api.usecases.NewScimUsecase()
+	group, err := usecase.GetGroup(c.Request.Context(), c.GetString(scimOrganizationIdKey), c.Param("group_id"))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimGroup(group))
+}
+
+func (api *API) handleScimCreateGroup(c *gin.Context) {
+	var body dto.ScimGroupBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	group, err := usecase.CreateGroup(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		dto.AdaptScimGroupInput(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusCreated, dto.AdaptScimGroup(group))
+}
+
+func (api *API) handleScimReplaceGroup(c *gin.Context) {
+	var body dto.ScimGroupBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	group, err := usecase.ReplaceGroup(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		c.Param("group_id"), dto.AdaptScimGroupInput(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimGroup(group))
+}
+
+func (api *API) handleScimPatchGroup(c *gin.Context) {
+	var body dto.ScimPatchBody
+	if !bindScimJSON(c, &body) {
+		return
+	}
+
+	usecase := api.usecases.NewScimUsecase()
+	group, err := usecase.PatchGroup(c.Request.Context(), c.GetString(scimOrganizationIdKey),
+		c.Param("group_id"), dto.AdaptScimPatchOperations(body))
+	if presentScimError(c, err) {
+		return
+	}
+	scimJSON(c, http.StatusOK, dto.AdaptScimGroup(group))
+}
+
+func (api *API) handleScimDeleteGroup(c *gin.Context) {
+	usecase := api.usecases.NewScimUsecase()
+	err := usecase.DeleteGroup(c.Request.Context(), c.GetString(scimOrganizationIdKey), c.Param("group_id"))
+	if presentScimError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Configuration of the SCIM provisioning by the admins of the organization
+
+func (api *API) handleListScimTokens(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScimConfigUsecase()
+	tokens, err := usecase.ListScimTokens(c.Request.Context(), c.Param("organization_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scim_tokens": pure_utils.Map(tokens, dto.AdaptScimToken)})
+}
+
+func (api *API) handleCreateScimToken(c *gin.Context) {
+	var data dto.CreateScimTokenBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScimConfigUsecase()
+	token, err := usecase.CreateScimToken(c.Request.Context(), models.CreateScimTokenInput{
+		OrganizationId: c.Param("organization_id"),
+		Description:    data.Description,
+	})
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"scim_token": dto.AdaptCreatedScimToken(token)})
+}
+
+func (api *API) handleDeleteScimToken(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScimConfigUsecase()
+	err := usecase.DeleteScimToken(c.Request.Context(), c.Param("organization_id"), c.Param("token_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handleListScimGroupMappings(c *gin.Context) {
+	usecase := api.UsecasesWithCreds(c.Request).NewScimConfigUsecase()
+	groups, err := usecase.ListScimGroups(c.Request.Context(), c.Param("organization_id"))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scim_groups": pure_utils.Map(groups, dto.AdaptScimGroupMapping)})
+}
+
+func (api *API) handlePatchScimGroupMapping(c *gin.Context) {
+	var data dto.UpdateScimGroupMappingBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScimConfigUsecase()
+	group, err := usecase.UpdateScimGroupMapping(c.Request.Context(), c.Param("organization_id"),
+		dto.AdaptUpdateScimGroupMappingInput(c.Param("group_id"), data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scim_group": dto.AdaptScimGroupMapping(group)})
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func (api *API) handleGetSsoProvider(c *gin.Context) {
+	organizationId := c.Param("organization_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewSsoProviderUsecase()
+	provider, err := usecase.GetSsoProvider(c.Request.Context(), organizationId)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sso_provider": dto.AdaptSsoProvider(provider)})
+}
+
+func (api *API) handlePutSsoProvider(c *gin.Context) {
+	organizationId := c.Param("organization_id")
+	var data dto.UpsertSsoProviderBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewSsoProviderUsecase()
+	provider, err := usecase.UpsertSsoProvider(c.Request.Context(),
+		dto.AdaptUpsertSsoProviderInput(organizationId, data))
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sso_provider": dto.AdaptSsoProvider(provider)})
+}
+
+func (api *API) handleDeleteSsoProvider(c *gin.Context) {
+	organizationId := c.Param("organization_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewSsoProviderUsecase()
+	err := usecase.DeleteSsoProvider(c.Request.Context(), organizationId)
+	if presentError(c, err) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (api *API) handleStartSsoLogin(c *gin.Context) {
+	organizationId := c.Param("organization_id")
+	var params dto.StartSsoLoginParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		presentError(c, errors.Wrap(models.BadParameterError, err.Error()))
+		return
+	}
+
+	usecase := api.usecases.NewSsoLoginUsecase()
+	authorizationUrl, err := usecase.StartLogin(c.Request.Context(), organizationId, params.RedirectUri)
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authorizationUrl})
+}
+
+type ssoLoginFinisher interface {
+	FinishLogin(ctx context.Context, state, code string) (models.User, error)
+}
+
+// GenerateSsoToken returns a Marble token for the user logged in with the identity provider of their organization,
+// from the state and code the identity provider redirected them with
+func (t *TokenHandler) GenerateSsoToken(ssoLogin ssoLoginFinisher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var data dto.SsoTokenBody
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		user, err := ssoLogin.FinishLogin(c.Request.Context(), data.State, data.Code)
+		if errors.Is(err, models.ErrUnknownUser) {
+			_ = c.Error(fmt.Errorf("ssoLogin.FinishLogin error: %w", err))
+			c.JSON(http.StatusUnauthorized, dto.APIErrorResponse{
+				Message:   "Unknown user: ErrUnknownUser",
+				ErrorCode: dto.UnknownUser,
+			})
+			return
+		}
+		if presentError(c, err) {
+			return
+		}
+
+		marbleToken, expirationTime, err := t.generator.FromUser(c.Request.Context(), user)
+		if presentError(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, accessToken{
+			AccessToken: marbleToken,
+			TokenType:   "Bearer",
+			ExpiresAt:   expirationTime,
+		})
+	}
+}
@@ -142,3 +142,89 @@ func (api *API) handleUpdateWebhook(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"webhook": dto.AdaptWebhook(webhook)})
 }
+
+func (api *API) handleReplayWebhookEvents(c *gin.Context) {
+	webhookId := c.Param("webhook_id")
+
+	creds, found := utils.CredentialsFromCtx(c.Request.Context())
+	if !found {
+		presentError(c, fmt.Errorf("no credentials in context"))
+		return
+	}
+
+	var data dto.WebhookEventsReplayBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewWebhooksUsecase()
+
+	result, err := usecase.ReplayWebhookEvents(c.Request.Context(),
+		creds.OrganizationId,
+		null.StringFromPtr(creds.PartnerId),
+		webhookId,
+		models.WebhookEventsReplay{
+			StartDate: data.StartDate,
+			EndDate:   data.EndDate,
+		})
+	if presentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"replayed_events": result.ReplayedEvents, "has_more": result.HasMore})
+}
+
+func (api *API) handleListWebhookEvents(c *gin.Context) {
+	creds, found := utils.CredentialsFromCtx(c.Request.Context())
+	if !found {
+		presentError(c, fmt.Errorf("no credentials in context"))
+		return
+	}
+
+	var filters dto.WebhookEventFilters
+	if err := c.ShouldBind(&filters); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	usecase := api.UsecasesWithCreds(c.Request).NewWebhooksUsecase()
+
+	webhookEvents, err := usecase.ListWebhookEvents(c.Request.Context(),
+		creds.OrganizationId,
+		null.StringFromPtr(creds.PartnerId),
+		dto.AdaptWebhookEventFilters(filters))
+	if presentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook_events": pure_utils.Map(webhookEvents, dto.AdaptWebhookEvent),
+	})
+}
+
+func (api *API) handleGetWebhookEvent(c *gin.Context) {
+	webhookEventId := c.Param("webhook_event_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewWebhooksUsecase()
+
+	webhookEvent, err := usecase.GetWebhookEvent(c.Request.Context(), webhookEventId)
+	if presentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook_event": dto.AdaptWebhookEventWithDeliveries(webhookEvent)})
+}
+
+func (api *API) handleReplayWebhookEvent(c *gin.Context) {
+	webhookEventId := c.Param("webhook_event_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewWebhooksUsecase()
+
+	webhookEvent, err := usecase.ReplayWebhookEvent(c.Request.Context(), webhookEventId)
+	if presentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook_event": dto.AdaptWebhookEvent(webhookEvent)})
+}
@@ -50,6 +50,11 @@ func (a *Authentication) Middleware(c *gin.Context) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
+	if !credentials.AllowsIp(c.ClientIP()) {
+		_ = c.Error(fmt.Errorf("the api key does not allow requests from %s", c.ClientIP()))
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
 
 	newContext := context.WithValue(c.Request.Context(), utils.ContextKeyCredentials, credentials)
 	if attr, ok := identityAttr(credentials.ActorIdentity); ok {
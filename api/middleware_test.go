@@ -87,6 +87,86 @@ func TestAuthentication_Middleware(t *testing.T) {
 		mValidator.AssertExpectations(t)
 	})
 
+	t.Run("IP not allowed by the api key", func(t *testing.T) {
+		networks, err := models.ParseAllowedNetworks([]string{"10.0.0.0/8"})
+		assert.NoError(t, err)
+		credentials := models.Credentials{
+			OrganizationId:  "organization",
+			Role:            models.API_CLIENT,
+			ActorIdentity:   models.Identity{ApiKeyId: "api_key_id"},
+			AllowedNetworks: networks,
+		}
+
+		mValidator := new(mockValidator)
+		mValidator.On("Validate", mock.Anything, "", "key").
+			Return(credentials, nil).Twice()
+
+		m := Authentication{
+			validator: mValidator,
+		}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/test", m.Middleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "https://checkmarble.com/test", nil)
+		req.Header.Add("X-API-Key", "key")
+		req.RemoteAddr = "192.168.1.12:1234"
+		r := httptest.NewRecorder()
+		router.ServeHTTP(r, req)
+		assert.Equal(t, http.StatusForbidden, r.Code)
+
+		req = httptest.NewRequest(http.MethodGet, "https://checkmarble.com/test", nil)
+		req.Header.Add("X-API-Key", "key")
+		req.RemoteAddr = "10.1.2.3:1234"
+		r = httptest.NewRecorder()
+		router.ServeHTTP(r, req)
+		assert.Equal(t, http.StatusOK, r.Code)
+
+		mValidator.AssertExpectations(t)
+	})
+
+	t.Run("spoofed X-Forwarded-For", func(t *testing.T) {
+		networks, err := models.ParseAllowedNetworks([]string{"10.0.0.0/8"})
+		assert.NoError(t, err)
+		credentials := models.Credentials{
+			OrganizationId:  "organization",
+			Role:            models.API_CLIENT,
+			ActorIdentity:   models.Identity{ApiKeyId: "api_key_id"},
+			AllowedNetworks: networks,
+		}
+
+		mValidator := new(mockValidator)
+		mValidator.On("Validate", mock.Anything, "", "key").
+			Return(credentials, nil).Twice()
+
+		m := Authentication{
+			validator: mValidator,
+		}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/test", m.Middleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		// no proxy is trusted, as when TRUSTED_PROXIES is empty: the header is ignored
+		assert.NoError(t, router.SetTrustedProxies(nil))
+		req := httptest.NewRequest(http.MethodGet, "https://checkmarble.com/test", nil)
+		req.Header.Add("X-API-Key", "key")
+		req.Header.Add("X-Forwarded-For", "10.1.2.3")
+		req.RemoteAddr = "192.168.1.12:1234"
+		r := httptest.NewRecorder()
+		router.ServeHTTP(r, req)
+		assert.Equal(t, http.StatusForbidden, r.Code)
+
+		// the header is used when it is set by a trusted proxy
+		assert.NoError(t, router.SetTrustedProxies([]string{"192.168.1.0/24"}))
+		r = httptest.NewRecorder()
+		router.ServeHTTP(r, req)
+		assert.Equal(t, http.StatusOK, r.Code)
+
+		mValidator.AssertExpectations(t)
+	})
+
 	t.Run("bad token", func(t *testing.T) {
 		m := Authentication{}
 
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -58,6 +59,9 @@ func InitRouter(
 	logger := utils.LoggerFromContext(ctx)
 
 	r := gin.New()
+	if err := r.SetTrustedProxies(conf.TrustedProxies); err != nil {
+		panic(fmt.Errorf("invalid trusted proxies: %w", err))
+	}
 
 	r.Use(gin.Recovery())
 	r.Use(sentrygin.New(sentrygin.Options{Repanic: true}))
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
 	limits "github.com/gin-contrib/size"
 	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
@@ -31,37 +32,72 @@ func (api *API) routes(auth Authentication, tokenHandler TokenHandler) {
 	api.router.GET("/liveness", HandleLivenessProbe)
 	api.router.POST("/token", tokenHandler.GenerateToken)
 	api.router.GET("/validate-license/*license_key", api.handleValidateLicense)
+	api.router.GET("/sso/:organization_id/login", api.handleStartSsoLogin)
+	ssoLogin := api.usecases.NewSsoLoginUsecase()
+	api.router.POST("/sso/token", tokenHandler.GenerateSsoToken(&ssoLogin))
+	api.scimRoutes()
+	api.router.GET(repositories.BLOB_DOWNLOAD_ROUTE+"/:bucket/*file_name", api.handleDownloadBlob)
 
 	router := api.router.Use(auth.Middleware)
 
 	router.GET("/credentials", api.handleGetCredentials)
 
 	router.GET("/ast-expression/available-functions", api.handleAvailableFunctions)
+	router.POST("/ast-expression/parse", api.handleParseFormula)
+	router.POST("/ast-expression/format", api.handleFormatFormula)
 
 	router.GET("/decisions", api.handleListDecisions)
-	router.POST("/decisions", timeoutMiddleware(models.DECISION_TIMEOUT), api.handlePostDecision)
-	router.POST("/decisions/all", timeoutMiddleware(models.SEQUENTIAL_DECISION_TIMEOUT), api.handlePostAllDecisions)
+	router.POST("/decisions", api.rateLimitMiddleware, timeoutMiddleware(models.DECISION_TIMEOUT), api.handlePostDecision)
+	router.POST("/decisions/all", api.rateLimitMiddleware, timeoutMiddleware(models.SEQUENTIAL_DECISION_TIMEOUT),
+		api.handlePostAllDecisions)
+	router.POST("/decisions/async", api.rateLimitMiddleware, api.handlePostAsyncDecision)
+	router.GET("/decisions/async/:decision_id", api.handleGetAsyncDecision)
 	router.GET("/decisions/:decision_id", api.handleGetDecision)
 	router.GET("/decisions/:decision_id/active-snoozes", api.handleSnoozesOfDecision)
 	router.POST("/decisions/:decision_id/snooze", api.handleSnoozeDecision)
-
-	router.POST("/ingestion/:object_type", api.handleIngestion)
-	router.POST("/ingestion/:object_type/batch", timeoutMiddleware(batchIngestionTimeout), api.handleCsvIngestion)
+	router.POST("/decisions/:decision_id/review", api.handleReviewDecision)
+
+	router.GET("/decision-policies", api.handleListDecisionPolicies)
+	router.POST("/decision-policies", api.handlePostDecisionPolicy)
+	router.GET("/decision-policies/:policy_id", api.handleGetDecisionPolicy)
+	router.PATCH("/decision-policies/:policy_id", api.handlePatchDecisionPolicy)
+	router.DELETE("/decision-policies/:policy_id", api.handleDeleteDecisionPolicy)
+	router.POST("/policy-decisions", api.rateLimitMiddleware, timeoutMiddleware(models.SEQUENTIAL_DECISION_TIMEOUT),
+		api.handlePostPolicyDecision)
+	router.GET("/policy-decisions/:policy_decision_id", api.handleGetPolicyDecision)
+
+	router.POST("/ingestion/:object_type", api.rateLimitMiddleware, api.handleIngestion)
+	router.POST("/ingestion/:object_type/batch", api.rateLimitMiddleware, timeoutMiddleware(batchIngestionTimeout),
+		api.handleCsvIngestion)
 	router.GET("/ingestion/:object_type/upload-logs", api.handleListUploadLogs)
 
 	router.GET("/scenarios", api.ListScenarios)
 	router.POST("/scenarios", api.CreateScenario)
+	router.POST("/scenarios/import", api.handleImportScenario)
 	router.GET("/scenarios/:scenario_id", api.GetScenario)
+	router.GET("/scenarios/:scenario_id/export", api.handleExportScenario)
 	router.PATCH("/scenarios/:scenario_id", api.UpdateScenario)
+	router.GET("/scenarios/:scenario_id/rule-performance", api.handleGetRulePerformance)
+	router.GET("/scenarios/:scenario_id/rule-performance.csv", api.handleGetRulePerformanceCsv)
+
+	router.GET("/scenario-templates", api.handleListScenarioTemplates)
+	router.POST("/scenario-templates", api.handleCreateScenarioTemplate)
+	router.GET("/scenario-templates/:template_id", api.handleGetScenarioTemplate)
+	router.PATCH("/scenario-templates/:template_id", api.handlePatchScenarioTemplate)
+	router.DELETE("/scenario-templates/:template_id", api.handleDeleteScenarioTemplate)
+	router.POST("/scenario-templates/:template_id/instantiate", api.handleInstantiateScenarioTemplate)
 
 	router.GET("/scenario-iterations", api.ListScenarioIterations)
 	router.POST("/scenario-iterations", api.CreateScenarioIteration)
 	router.GET("/scenario-iterations/:iteration_id", api.GetScenarioIteration)
 	router.POST("/scenario-iterations/:iteration_id", api.CreateDraftFromIteration)
 	router.PATCH("/scenario-iterations/:iteration_id", api.UpdateScenarioIteration)
+	router.GET("/scenario-iterations/:iteration_id/diff", api.DiffScenarioIteration)
 	router.POST("/scenario-iterations/:iteration_id/validate", api.ValidateScenarioIteration)
 	router.POST("/scenario-iterations/:iteration_id/commit", api.CommitScenarioIterationVersion)
 	router.POST("/scenario-iterations/:iteration_id/schedule-execution", api.handleCreateScheduledExecution)
+	router.POST("/scenario-iterations/:iteration_id/dry-run", timeoutMiddleware(models.DECISION_TIMEOUT),
+		api.handleDryRunScenarioIteration)
 	router.GET("/scenario-iterations/:iteration_id/active-snoozes", api.handleSnoozesOfScenarioIteartion)
 
 	router.GET("/scenario-iteration-rules", api.ListRules)
@@ -78,14 +114,19 @@ func (api *API) routes(auth Authentication, tokenHandler TokenHandler) {
 
 	router.GET("/scheduled-executions", api.handleListScheduledExecution)
 	router.GET("/scheduled-executions/:execution_id", api.handleGetScheduledExecution)
+	router.POST("/scheduled-executions/:execution_id/cancel", api.handleCancelScheduledExecution)
 	router.GET("/scheduled-executions/:execution_id/decisions.zip",
 		api.handleGetScheduledExecutionDecisions)
 
+	router.GET("/audit-events", api.handleListAuditEvents)
+	router.GET("/audit-events/export", api.handleExportAuditEvents)
+
 	router.GET("/analytics", api.handleListAnalytics)
 
 	router.GET("/apikeys", api.handleListApiKeys)
 	router.POST("/apikeys", api.handlePostApiKey)
 	router.DELETE("/apikeys/:api_key_id", api.handleRevokeApiKey)
+	router.POST("/apikeys/:api_key_id/rotate", api.handleRotateApiKey)
 
 	router.GET("/custom-lists", api.handleGetAllCustomLists)
 	router.POST("/custom-lists", api.handlePostCustomList)
@@ -110,6 +151,30 @@ func (api *API) routes(auth Authentication, tokenHandler TokenHandler) {
 	router.PATCH("/organizations/:organization_id", api.handlePatchOrganization)
 	router.DELETE("/organizations/:organization_id", api.handleDeleteOrganization)
 	router.GET("/organizations/:organization_id/users", api.handleGetOrganizationUsers)
+	router.GET("/organizations/:organization_id/sso-provider", api.handleGetSsoProvider)
+	router.PUT("/organizations/:organization_id/sso-provider", api.handlePutSsoProvider)
+	router.DELETE("/organizations/:organization_id/sso-provider", api.handleDeleteSsoProvider)
+	router.GET("/organizations/:organization_id/scim-tokens", api.handleListScimTokens)
+	router.POST("/organizations/:organization_id/scim-tokens", api.handleCreateScimToken)
+	router.DELETE("/organizations/:organization_id/scim-tokens/:token_id", api.handleDeleteScimToken)
+	router.GET("/organizations/:organization_id/scim-groups", api.handleListScimGroupMappings)
+	router.PATCH("/organizations/:organization_id/scim-groups/:group_id", api.handlePatchScimGroupMapping)
+	router.GET("/organizations/:organization_id/permissions", api.handleListCustomRolePermissions)
+	router.GET("/organizations/:organization_id/custom-roles", api.handleListCustomRoles)
+	router.POST("/organizations/:organization_id/custom-roles", api.handleCreateCustomRole)
+	router.GET("/organizations/:organization_id/custom-roles/:custom_role_id", api.handleGetCustomRole)
+	router.PATCH("/organizations/:organization_id/custom-roles/:custom_role_id", api.handlePatchCustomRole)
+	router.DELETE("/organizations/:organization_id/custom-roles/:custom_role_id", api.handleDeleteCustomRole)
+	router.PUT("/organizations/:organization_id/users/:user_id/custom-role", api.handlePutUserCustomRole)
+	router.PUT("/organizations/:organization_id/apikeys/:api_key_id/custom-role", api.handlePutApiKeyCustomRole)
+	router.GET("/organizations/:organization_id/rate-limits", api.handleListRateLimits)
+	router.PUT("/organizations/:organization_id/rate-limits", api.handlePutRateLimit)
+	router.DELETE("/organizations/:organization_id/rate-limits", api.handleDeleteRateLimit)
+	router.PUT("/organizations/:organization_id/rate-limits/apikeys/:api_key_id", api.handlePutRateLimit)
+	router.DELETE("/organizations/:organization_id/rate-limits/apikeys/:api_key_id", api.handleDeleteRateLimit)
+	router.GET("/organizations/:organization_id/config/export", api.handleExportOrganizationConfig)
+	router.POST("/organizations/:organization_id/config/plan", api.handlePlanOrganizationConfig)
+	router.POST("/organizations/:organization_id/config/apply", api.handleApplyOrganizationConfig)
 
 	router.GET("/partners", api.handleListPartners)
 	router.POST("/partners", api.handleCreatePartner)
@@ -147,9 +212,12 @@ func (api *API) routes(auth Authentication, tokenHandler TokenHandler) {
 	router.GET("/data-model", api.GetDataModel)
 	router.POST("/data-model/tables", api.CreateTable)
 	router.PATCH("/data-model/tables/:tableID", api.UpdateDataModelTable)
+	router.DELETE("/data-model/tables/:tableID", api.DeleteDataModelTable)
 	router.POST("/data-model/links", api.CreateLink)
+	router.DELETE("/data-model/links/:linkID", api.DeleteDataModelLink)
 	router.POST("/data-model/tables/:tableID/fields", api.CreateField)
 	router.PATCH("/data-model/fields/:fieldID", api.UpdateDataModelField)
+	router.DELETE("/data-model/fields/:fieldID", api.DeleteDataModelField)
 	router.DELETE("/data-model", api.DeleteDataModel)
 	router.GET("/data-model/openapi", api.OpenAPI)
 	router.POST("/data-model/pivots", api.createDataModelPivot)
@@ -180,6 +248,31 @@ func (api *API) routes(auth Authentication, tokenHandler TokenHandler) {
 	router.GET("/webhooks/:webhook_id", api.handleGetWebhook)
 	router.PATCH("/webhooks/:webhook_id", api.handleUpdateWebhook)
 	router.DELETE("/webhooks/:webhook_id", api.handleDeleteWebhook)
+	router.POST("/webhooks/:webhook_id/replay", api.handleReplayWebhookEvents)
+	router.GET("/webhook-events", api.handleListWebhookEvents)
+	router.GET("/webhook-events/:webhook_event_id", api.handleGetWebhookEvent)
+	router.POST("/webhook-events/:webhook_event_id/replay", api.handleReplayWebhookEvent)
 
 	router.GET("/rule-snoozes/:rule_snooze_id", api.handleGetSnoozesById)
 }
+
+// SCIM 2.0 endpoints of the identity providers, authenticated by the SCIM token of their organization
+func (api *API) scimRoutes() {
+	scim := api.router.Group("/scim/v2", api.scimAuthentication)
+
+	scim.GET("/ServiceProviderConfig", api.handleScimServiceProviderConfig)
+
+	scim.GET("/Users", api.handleScimListUsers)
+	scim.POST("/Users", api.handleScimCreateUser)
+	scim.GET("/Users/:user_id", api.handleScimGetUser)
+	scim.PUT("/Users/:user_id", api.handleScimReplaceUser)
+	scim.PATCH("/Users/:user_id", api.handleScimPatchUser)
+	scim.DELETE("/Users/:user_id", api.handleScimDeleteUser)
+
+	scim.GET("/Groups", api.handleScimListGroups)
+	scim.POST("/Groups", api.handleScimCreateGroup)
+	scim.GET("/Groups/:group_id", api.handleScimGetGroup)
+	scim.PUT("/Groups/:group_id", api.handleScimReplaceGroup)
+	scim.PATCH("/Groups/:group_id", api.handleScimPatchGroup)
+	scim.DELETE("/Groups/:group_id", api.handleScimDeleteGroup)
+}
@@ -28,6 +28,20 @@ func (api *API) handleGetScheduledExecution(c *gin.Context) {
 	})
 }
 
+func (api *API) handleCancelScheduledExecution(c *gin.Context) {
+	scheduledExecutionID := c.Param("execution_id")
+
+	usecase := api.UsecasesWithCreds(c.Request).NewScheduledExecutionUsecase()
+	execution, err := usecase.CancelScheduledExecution(c.Request.Context(), scheduledExecutionID)
+
+	if presentError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"scheduled_execution": dto.AdaptScheduledExecutionDto(execution),
+	})
+}
+
 func (api *API) handleGetScheduledExecutionDecisions(c *gin.Context) {
 	scheduledExecutionID := c.Param("execution_id")
 	organizationId, err := utils.OrganizationIdFromRequest(c.Request)
@@ -58,6 +58,12 @@ func RunBatchIngestion() error {
 	infra.SetupSentry(jobConfig.sentryDsn, jobConfig.env)
 	defer sentry.Flush(3 * time.Second)
 
+	blobStorageConfig, err := readBlobStorageConfig()
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: jobConfig.appName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -79,12 +85,12 @@ func RunBatchIngestion() error {
 	repositories := repositories.NewRepositories(
 		pool,
 		repositories.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
+		repositories.WithBlobStorage(blobStorageConfig),
 		repositories.WithConvoyClientProvider(
 			infra.InitializeConvoyRessources(convoyConfiguration)),
 	)
 	uc := usecases.NewUsecases(repositories,
 		usecases.WithGcsIngestionBucket(gcpConfig.GcsIngestionBucket),
-		usecases.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
 		usecases.WithLicense(license))
 
 	err = jobs.IngestDataFromCsv(ctx, uc)
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+// readBlobStorageConfig reads the configuration of the storage of case files, CSV ingestion files and transfer
+// check enrichment files, shared by the server and the jobs
+func readBlobStorageConfig() (repositories.BlobStorageConfig, error) {
+	backend, err := models.BlobStorageBackendFrom(utils.GetEnv("BLOB_STORAGE_BACKEND", ""))
+	if err != nil {
+		return repositories.BlobStorageConfig{}, err
+	}
+
+	config := repositories.BlobStorageConfig{
+		Backend:               backend,
+		LocalDirectory:        utils.GetEnv("BLOB_STORAGE_LOCAL_DIRECTORY", "blob-storage"),
+		LocalDownloadUrl:      utils.GetEnv("BLOB_STORAGE_LOCAL_DOWNLOAD_URL", ""),
+		LocalUrlSigningSecret: utils.GetEnv("BLOB_STORAGE_URL_SIGNING_SECRET", ""),
+		S3ForcePathStyle:      utils.GetEnv("AWS_S3_FORCE_PATH_STYLE", false),
+	}
+	if backend == models.BlobStorageBackendLocal && config.LocalUrlSigningSecret == "" {
+		return repositories.BlobStorageConfig{}, errors.New(
+			"BLOB_STORAGE_URL_SIGNING_SECRET is required with the local blob storage backend")
+	}
+	return config, nil
+}
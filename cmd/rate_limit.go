@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+// readRateLimitConfig reads the backend of the quota counters and the rate limits of the organizations without their
+// own, which do not limit anything by default
+func readRateLimitConfig() (models.RateLimitConfiguration, error) {
+	backend, err := models.RateLimitBackendFrom(utils.GetEnv("RATE_LIMIT_BACKEND", ""))
+	if err != nil {
+		return models.RateLimitConfiguration{}, err
+	}
+
+	requestsPerSecond := 0.
+	if value := utils.GetEnv("RATE_LIMIT_DEFAULT_REQUESTS_PER_SECOND", ""); value != "" {
+		requestsPerSecond, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return models.RateLimitConfiguration{}, errors.Wrap(err,
+				"RATE_LIMIT_DEFAULT_REQUESTS_PER_SECOND is not a number")
+		}
+	}
+
+	config := models.RateLimitConfiguration{
+		Backend: backend,
+		DefaultPolicy: models.RateLimitPolicy{
+			RequestsPerSecond: requestsPerSecond,
+			Burst:             utils.GetEnv("RATE_LIMIT_DEFAULT_BURST", 0),
+			MonthlyQuota:      int64(utils.GetEnv("RATE_LIMIT_DEFAULT_MONTHLY_QUOTA", 0)),
+		},
+	}
+	if err := config.DefaultPolicy.Validate(); err != nil {
+		return models.RateLimitConfiguration{}, err
+	}
+	return config, nil
+}
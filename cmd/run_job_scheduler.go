@@ -45,6 +45,9 @@ func RunJobScheduler() error {
 		sentryDsn                   string
 		fakeAwsS3Repository         bool
 		failedWebhooksRetryPageSize int
+		schedulerTimezone           string
+		webhookDeliveryBackend      string
+		webhooksAllowPrivateNetwork bool
 	}{
 		env:                         utils.GetEnv("ENV", "development"),
 		appName:                     "marble-backend",
@@ -52,6 +55,9 @@ func RunJobScheduler() error {
 		sentryDsn:                   utils.GetEnv("SENTRY_DSN", ""),
 		fakeAwsS3Repository:         utils.GetEnv("FAKE_AWS_S3", false),
 		failedWebhooksRetryPageSize: utils.GetEnv("FAILED_WEBHOOKS_RETRY_PAGE_SIZE", 1000),
+		schedulerTimezone:           utils.GetEnv("SCHEDULER_TIMEZONE", usecases.DEFAULT_SCHEDULER_TIMEZONE),
+		webhookDeliveryBackend:      utils.GetEnv("WEBHOOK_DELIVERY_BACKEND", string(models.WebhookDeliveryBackendConvoy)),
+		webhooksAllowPrivateNetwork: utils.GetEnv("WEBHOOKS_ALLOW_PRIVATE_NETWORK", false),
 	}
 
 	logger := utils.NewLogger(jobConfig.loggingFormat)
@@ -61,6 +67,23 @@ func RunJobScheduler() error {
 	infra.SetupSentry(jobConfig.sentryDsn, jobConfig.env)
 	defer sentry.Flush(3 * time.Second)
 
+	webhookDeliveryBackend, err := models.WebhookDeliveryBackendFrom(jobConfig.webhookDeliveryBackend)
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
+	blobStorageConfig, err := readBlobStorageConfig()
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
+	if _, err := time.LoadLocation(jobConfig.schedulerTimezone); err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: jobConfig.appName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -81,17 +104,20 @@ func RunJobScheduler() error {
 
 	repositories := repositories.NewRepositories(pool,
 		repositories.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
+		repositories.WithBlobStorage(blobStorageConfig),
 		repositories.WithConvoyClientProvider(
 			infra.InitializeConvoyRessources(convoyConfiguration)),
 	)
 	uc := usecases.NewUsecases(repositories,
 		usecases.WithGcsIngestionBucket(gcpConfig.GcsIngestionBucket),
 		usecases.WithFakeAwsS3Repository(jobConfig.fakeAwsS3Repository),
-		usecases.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
 		usecases.WithFailedWebhooksRetryPageSize(jobConfig.failedWebhooksRetryPageSize),
+		usecases.WithSchedulerTimezone(jobConfig.schedulerTimezone),
+		usecases.WithWebhookDeliveryBackend(webhookDeliveryBackend),
+		usecases.WithWebhooksAllowPrivateNetwork(jobConfig.webhooksAllowPrivateNetwork),
 		usecases.WithLicense(license))
 
-	jobs.RunScheduler(ctx, uc)
+	jobs.RunScheduler(ctx, uc, jobConfig.schedulerTimezone)
 
 	return nil
 }
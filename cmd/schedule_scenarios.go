@@ -38,15 +38,17 @@ func RunScheduleScenarios() error {
 		KillIfReadLicenseError: utils.GetEnv("KILL_IF_READ_LICENSE_ERROR", false),
 	}
 	jobConfig := struct {
-		env           string
-		appName       string
-		loggingFormat string
-		sentryDsn     string
+		env               string
+		appName           string
+		loggingFormat     string
+		sentryDsn         string
+		schedulerTimezone string
 	}{
-		env:           utils.GetEnv("ENV", "development"),
-		appName:       "marble-backend",
-		loggingFormat: utils.GetEnv("LOGGING_FORMAT", "text"),
-		sentryDsn:     utils.GetEnv("SENTRY_DSN", ""),
+		env:               utils.GetEnv("ENV", "development"),
+		appName:           "marble-backend",
+		loggingFormat:     utils.GetEnv("LOGGING_FORMAT", "text"),
+		sentryDsn:         utils.GetEnv("SENTRY_DSN", ""),
+		schedulerTimezone: utils.GetEnv("SCHEDULER_TIMEZONE", usecases.DEFAULT_SCHEDULER_TIMEZONE),
 	}
 
 	logger := utils.NewLogger(jobConfig.loggingFormat)
@@ -56,6 +58,11 @@ func RunScheduleScenarios() error {
 	infra.SetupSentry(jobConfig.sentryDsn, jobConfig.env)
 	defer sentry.Flush(3 * time.Second)
 
+	if _, err := time.LoadLocation(jobConfig.schedulerTimezone); err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: jobConfig.appName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -79,6 +86,7 @@ func RunScheduleScenarios() error {
 		repositories.WithConvoyClientProvider(
 			infra.InitializeConvoyRessources(convoyConfiguration)))
 	uc := usecases.NewUsecases(repositories,
+		usecases.WithSchedulerTimezone(jobConfig.schedulerTimezone),
 		usecases.WithLicense(license))
 
 	err = jobs.ScheduleDueScenarios(ctx, uc)
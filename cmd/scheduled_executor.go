@@ -38,17 +38,21 @@ func RunScheduledExecuter() error {
 		KillIfReadLicenseError: utils.GetEnv("KILL_IF_READ_LICENSE_ERROR", false),
 	}
 	jobConfig := struct {
-		env                 string
-		appName             string
-		loggingFormat       string
-		sentryDsn           string
-		fakeAwsS3Repository bool
+		env                         string
+		appName                     string
+		loggingFormat               string
+		sentryDsn                   string
+		fakeAwsS3Repository         bool
+		webhookDeliveryBackend      string
+		webhooksAllowPrivateNetwork bool
 	}{
-		env:                 utils.GetEnv("ENV", "development"),
-		appName:             "marble-backend",
-		loggingFormat:       utils.GetEnv("LOGGING_FORMAT", "text"),
-		sentryDsn:           utils.GetEnv("SENTRY_DSN", ""),
-		fakeAwsS3Repository: utils.GetEnv("FAKE_AWS_S3", false),
+		env:                         utils.GetEnv("ENV", "development"),
+		appName:                     "marble-backend",
+		loggingFormat:               utils.GetEnv("LOGGING_FORMAT", "text"),
+		sentryDsn:                   utils.GetEnv("SENTRY_DSN", ""),
+		fakeAwsS3Repository:         utils.GetEnv("FAKE_AWS_S3", false),
+		webhookDeliveryBackend:      utils.GetEnv("WEBHOOK_DELIVERY_BACKEND", string(models.WebhookDeliveryBackendConvoy)),
+		webhooksAllowPrivateNetwork: utils.GetEnv("WEBHOOKS_ALLOW_PRIVATE_NETWORK", false),
 	}
 
 	logger := utils.NewLogger(jobConfig.loggingFormat)
@@ -58,6 +62,18 @@ func RunScheduledExecuter() error {
 	infra.SetupSentry(jobConfig.sentryDsn, jobConfig.env)
 	defer sentry.Flush(3 * time.Second)
 
+	webhookDeliveryBackend, err := models.WebhookDeliveryBackendFrom(jobConfig.webhookDeliveryBackend)
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
+	blobStorageConfig, err := readBlobStorageConfig()
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: jobConfig.appName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -78,11 +94,14 @@ func RunScheduledExecuter() error {
 
 	repositories := repositories.NewRepositories(
 		pool,
+		repositories.WithBlobStorage(blobStorageConfig),
 		repositories.WithConvoyClientProvider(
 			infra.InitializeConvoyRessources(convoyConfiguration)))
 
 	uc := usecases.NewUsecases(repositories,
 		usecases.WithFakeAwsS3Repository(jobConfig.fakeAwsS3Repository),
+		usecases.WithWebhookDeliveryBackend(webhookDeliveryBackend),
+		usecases.WithWebhooksAllowPrivateNetwork(jobConfig.webhooksAllowPrivateNetwork),
 		usecases.WithLicense(license))
 
 	err = jobs.ExecuteAllScheduledScenarios(ctx, uc)
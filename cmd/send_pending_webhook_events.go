@@ -43,12 +43,16 @@ func RunSendPendingWebhookEvents() error {
 		loggingFormat               string
 		sentryDsn                   string
 		failedWebhooksRetryPageSize int
+		webhookDeliveryBackend      string
+		webhooksAllowPrivateNetwork bool
 	}{
 		env:                         utils.GetEnv("ENV", "development"),
 		appName:                     "marble-backend",
 		loggingFormat:               utils.GetEnv("LOGGING_FORMAT", "text"),
 		sentryDsn:                   utils.GetEnv("SENTRY_DSN", ""),
 		failedWebhooksRetryPageSize: utils.GetEnv("FAILED_WEBHOOKS_RETRY_PAGE_SIZE", 1000),
+		webhookDeliveryBackend:      utils.GetEnv("WEBHOOK_DELIVERY_BACKEND", string(models.WebhookDeliveryBackendConvoy)),
+		webhooksAllowPrivateNetwork: utils.GetEnv("WEBHOOKS_ALLOW_PRIVATE_NETWORK", false),
 	}
 
 	logger := utils.NewLogger(jobConfig.loggingFormat)
@@ -58,6 +62,12 @@ func RunSendPendingWebhookEvents() error {
 	infra.SetupSentry(jobConfig.sentryDsn, jobConfig.env)
 	defer sentry.Flush(3 * time.Second)
 
+	webhookDeliveryBackend, err := models.WebhookDeliveryBackendFrom(jobConfig.webhookDeliveryBackend)
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: jobConfig.appName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -81,6 +91,8 @@ func RunSendPendingWebhookEvents() error {
 			infra.InitializeConvoyRessources(convoyConfiguration)))
 	uc := usecases.NewUsecases(repositories,
 		usecases.WithFailedWebhooksRetryPageSize(jobConfig.failedWebhooksRetryPageSize),
+		usecases.WithWebhookDeliveryBackend(webhookDeliveryBackend),
+		usecases.WithWebhooksAllowPrivateNetwork(jobConfig.webhooksAllowPrivateNetwork),
 		usecases.WithLicense(license))
 
 	err = jobs.SendPendingWebhookEvents(ctx, uc)
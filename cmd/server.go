@@ -5,11 +5,13 @@ import (
 	"log/slog"
 	"net/http"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/checkmarble/marble-backend/api"
 	"github.com/checkmarble/marble-backend/infra"
+	"github.com/checkmarble/marble-backend/jobs"
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases"
@@ -30,6 +32,7 @@ func RunServer() error {
 		RequestLoggingLevel:  utils.GetEnv("REQUEST_LOGGING_LEVEL", "all"),
 		TokenLifetimeMinute:  utils.GetEnv("TOKEN_LIFETIME_MINUTE", 60*2),
 		SegmentWriteKey:      utils.GetEnv("SEGMENT_WRITE_KEY", ""),
+		TrustedProxies:       splitNonEmpty(utils.GetEnv("TRUSTED_PROXIES", "")),
 	}
 	gcpConfig := infra.GcpConfig{
 		FakeGcsRepository:                utils.GetEnv("FAKE_GCS", false),
@@ -71,13 +74,22 @@ func RunServer() error {
 		KillIfReadLicenseError: utils.GetEnv("KILL_IF_READ_LICENSE_ERROR", false),
 	}
 	serverConfig := struct {
-		jwtSigningKey string
-		loggingFormat string
-		sentryDsn     string
+		jwtSigningKey                string
+		loggingFormat                string
+		sentryDsn                    string
+		webhookDeliveryBackend       string
+		webhooksAllowPrivateNetwork  bool
+		idempotencyKeyRetentionHours int
+		asyncDecisionWorkers         int
 	}{
-		jwtSigningKey: utils.GetEnv("AUTHENTICATION_JWT_SIGNING_KEY", ""),
-		loggingFormat: utils.GetEnv("LOGGING_FORMAT", "text"),
-		sentryDsn:     utils.GetEnv("SENTRY_DSN", ""),
+		jwtSigningKey:               utils.GetEnv("AUTHENTICATION_JWT_SIGNING_KEY", ""),
+		loggingFormat:               utils.GetEnv("LOGGING_FORMAT", "text"),
+		sentryDsn:                   utils.GetEnv("SENTRY_DSN", ""),
+		webhookDeliveryBackend:      utils.GetEnv("WEBHOOK_DELIVERY_BACKEND", string(models.WebhookDeliveryBackendConvoy)),
+		webhooksAllowPrivateNetwork: utils.GetEnv("WEBHOOKS_ALLOW_PRIVATE_NETWORK", false),
+		idempotencyKeyRetentionHours: utils.GetEnv("IDEMPOTENCY_KEY_RETENTION_HOURS",
+			int(models.DEFAULT_IDEMPOTENCY_KEY_RETENTION/time.Hour)),
+		asyncDecisionWorkers: utils.GetEnv("ASYNC_DECISION_WORKERS", 4),
 	}
 
 	logger := utils.NewLogger(serverConfig.loggingFormat)
@@ -88,6 +100,24 @@ func RunServer() error {
 	infra.SetupSentry(serverConfig.sentryDsn, apiConfig.Env)
 	defer sentry.Flush(3 * time.Second)
 
+	webhookDeliveryBackend, err := models.WebhookDeliveryBackendFrom(serverConfig.webhookDeliveryBackend)
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
+	blobStorageConfig, err := readBlobStorageConfig()
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
+	rateLimitConfig, err := readRateLimitConfig()
+	if err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+		return err
+	}
+
 	tracingConfig := infra.TelemetryConfiguration{
 		ApplicationName: apiConfig.AppName,
 		Enabled:         gcpConfig.EnableTracing,
@@ -108,15 +138,19 @@ func RunServer() error {
 		repositories.WithMetabase(infra.InitializeMetabase(metabaseConfig)),
 		repositories.WithTransferCheckEnrichmentBucket(gcpConfig.GcsTransferCheckEnrichmentBucket),
 		repositories.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
+		repositories.WithBlobStorage(blobStorageConfig),
 		repositories.WithConvoyClientProvider(
 			infra.InitializeConvoyRessources(convoyConfiguration)),
 	)
 
 	uc := usecases.NewUsecases(repositories,
-		usecases.WithFakeGcsRepository(gcpConfig.FakeGcsRepository),
 		usecases.WithGcsIngestionBucket(gcpConfig.GcsIngestionBucket),
 		usecases.WithGcsCaseManagerBucket(gcpConfig.GcsCaseManagerBucket),
+		usecases.WithWebhookDeliveryBackend(webhookDeliveryBackend),
+		usecases.WithWebhooksAllowPrivateNetwork(serverConfig.webhooksAllowPrivateNetwork),
+		usecases.WithIdempotencyKeyRetention(time.Duration(serverConfig.idempotencyKeyRetentionHours)*time.Hour),
 		usecases.WithLicense(license),
+		usecases.WithRateLimitConfiguration(rateLimitConfig),
 	)
 
 	////////////////////////////////////////////////////////////
@@ -157,6 +191,12 @@ func RunServer() error {
 		logger.InfoContext(ctx, "server returned")
 	}()
 
+	go deps.TokenValidator.RunApiKeyUsageFlush(notify)
+
+	if serverConfig.asyncDecisionWorkers > 0 {
+		go jobs.RunAsyncDecisionWorkers(notify, uc, serverConfig.asyncDecisionWorkers)
+	}
+
 	<-notify.Done()
 	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -168,6 +208,21 @@ func RunServer() error {
 			errors.Wrap(err, "Error while shutting down the server"),
 		)
 	}
+	// the usage of the api keys counted by the last requests
+	if err := deps.TokenValidator.FlushApiKeyUsages(shutdownCtx); err != nil {
+		utils.LogAndReportSentryError(ctx, err)
+	}
 
 	return err
 }
+
+// splitNonEmpty splits a comma separated list of values
+func splitNonEmpty(list string) []string {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
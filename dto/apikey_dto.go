@@ -1,18 +1,56 @@
 package dto
 
 import (
+	"net/netip"
 	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
 )
 
+type ApiKeyScope struct {
+	ScenarioIds        []string `json:"scenario_ids"`
+	TriggerObjectTypes []string `json:"trigger_object_types"`
+	IngestionTables    []string `json:"ingestion_tables"`
+}
+
+func AdaptApiKeyScope(scope models.ApiKeyScope) ApiKeyScope {
+	return ApiKeyScope{
+		ScenarioIds:        nonNilStrings(scope.ScenarioIds),
+		TriggerObjectTypes: nonNilStrings(scope.TriggerObjectTypes),
+		IngestionTables:    nonNilStrings(scope.IngestionTables),
+	}
+}
+
+func AdaptApiKeyScopeInput(scope ApiKeyScope) models.ApiKeyScope {
+	return models.ApiKeyScope{
+		ScenarioIds:        scope.ScenarioIds,
+		TriggerObjectTypes: scope.TriggerObjectTypes,
+		IngestionTables:    scope.IngestionTables,
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 type ApiKey struct {
-	Id             string    `json:"id"`
-	CreatedAt      time.Time `json:"created_at"`
-	Description    string    `json:"description"`
-	OrganizationId string    `json:"organization_id"`
-	Prefix         string    `json:"prefix"`
-	Role           string    `json:"role"`
+	Id             string      `json:"id"`
+	CreatedAt      time.Time   `json:"created_at"`
+	Description    string      `json:"description"`
+	OrganizationId string      `json:"organization_id"`
+	Prefix         string      `json:"prefix"`
+	Role           string      `json:"role"`
+	CustomRoleId   *string     `json:"custom_role_id"`
+	ExpiresAt      *time.Time  `json:"expires_at"`
+	Scope          ApiKeyScope `json:"scope"`
+	AllowedIps     []string    `json:"allowed_ips"`
+	RotatedFromId  *string     `json:"rotated_from_id"`
+	LastUsedAt     *time.Time  `json:"last_used_at"`
+	RequestCount   int64       `json:"request_count"`
 }
 
 func AdaptApiKeyDto(apiKey models.ApiKey) ApiKey {
@@ -23,6 +61,13 @@ func AdaptApiKeyDto(apiKey models.ApiKey) ApiKey {
 		OrganizationId: apiKey.OrganizationId,
 		Prefix:         apiKey.Prefix,
 		Role:           apiKey.Role.String(),
+		CustomRoleId:   apiKey.CustomRoleId,
+		ExpiresAt:      apiKey.ExpiresAt,
+		Scope:          AdaptApiKeyScope(apiKey.Scope),
+		AllowedIps:     pure_utils.Map(apiKey.AllowedNetworks, netip.Prefix.String),
+		RotatedFromId:  apiKey.RotatedFromId,
+		LastUsedAt:     apiKey.LastUsedAt,
+		RequestCount:   apiKey.RequestCount,
 	}
 }
 
@@ -39,6 +84,42 @@ func AdaptCreatedApiKeyDto(apiKey models.CreatedApiKey) CreatedApiKey {
 }
 
 type CreateApiKeyBody struct {
-	Description string `json:"description"`
-	Role        string `json:"role"`
+	Description string       `json:"description"`
+	Role        string       `json:"role"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+	Scope       *ApiKeyScope `json:"scope"`
+	// IP addresses or CIDR ranges
+	AllowedIps []string `json:"allowed_ips"`
+}
+
+func AdaptCreateApiKeyInput(organizationId string, body CreateApiKeyBody) (models.CreateApiKeyInput, error) {
+	networks, err := models.ParseAllowedNetworks(body.AllowedIps)
+	if err != nil {
+		return models.CreateApiKeyInput{}, err
+	}
+	input := models.CreateApiKeyInput{
+		OrganizationId:  organizationId,
+		Description:     body.Description,
+		Role:            models.RoleFromString(body.Role),
+		ExpiresAt:       body.ExpiresAt,
+		AllowedNetworks: networks,
+	}
+	if body.Scope != nil {
+		input.Scope = AdaptApiKeyScopeInput(*body.Scope)
+	}
+	return input, nil
+}
+
+type RotateApiKeyBody struct {
+	// How long the replaced key is still accepted, 24 hours if not specified
+	OverlapSeconds *int `json:"overlap_seconds"`
+}
+
+func AdaptRotateApiKeyInput(apiKeyId string, body RotateApiKeyBody) models.RotateApiKeyInput {
+	input := models.RotateApiKeyInput{ApiKeyId: apiKeyId}
+	if body.OverlapSeconds != nil {
+		overlap := time.Duration(*body.OverlapSeconds) * time.Second
+		input.Overlap = &overlap
+	}
+	return input
 }
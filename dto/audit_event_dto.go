@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type AuditEvent struct {
+	Id             string          `json:"id"`
+	OrganizationId *string         `json:"organization_id"`
+	Operation      string          `json:"operation"`
+	UserId         *string         `json:"user_id"`
+	ApiKeyId       *string         `json:"api_key_id"`
+	Table          string          `json:"table"`
+	EntityId       string          `json:"entity_id"`
+	Data           json.RawMessage `json:"data"`
+	PreviousData   json.RawMessage `json:"previous_data,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func AdaptAuditEvent(event models.AuditEvent) AuditEvent {
+	return AuditEvent{
+		Id:             event.Id,
+		OrganizationId: event.OrganizationId,
+		Operation:      string(event.Operation),
+		UserId:         event.UserId,
+		ApiKeyId:       event.ApiKeyId,
+		Table:          event.Table,
+		EntityId:       event.EntityId,
+		Data:           event.Data,
+		PreviousData:   event.PreviousData,
+		CreatedAt:      event.CreatedAt,
+	}
+}
+
+type AuditEventFilters struct {
+	Tables     []string  `form:"table[]"`
+	Operations []string  `form:"operation[]"`
+	EntityId   string    `form:"entity_id"`
+	UserId     string    `form:"user_id"`
+	ApiKeyId   string    `form:"api_key_id"`
+	StartDate  time.Time `form:"start_date"`
+	EndDate    time.Time `form:"end_date"`
+}
+
+func AdaptAuditEventFilters(filters AuditEventFilters) models.AuditEventFilters {
+	return models.AuditEventFilters{
+		Tables: filters.Tables,
+		Operations: pure_utils.Map(filters.Operations, func(operation string) models.AuditOperation {
+			return models.AuditOperation(operation)
+		}),
+		EntityId:  filters.EntityId,
+		UserId:    filters.UserId,
+		ApiKeyId:  filters.ApiKeyId,
+		StartDate: filters.StartDate,
+		EndDate:   filters.EndDate,
+	}
+}
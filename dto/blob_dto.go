@@ -0,0 +1,6 @@
+package dto
+
+type DownloadBlobParams struct {
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
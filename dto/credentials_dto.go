@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"net/netip"
+
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/pure_utils"
 )
@@ -10,6 +12,7 @@ type Identity struct {
 	Email      string `json:"email,omitempty"`
 	FirstName  string `json:"first_name,omitempty"`
 	LastName   string `json:"last_name,omitempty"`
+	ApiKeyId   string `json:"api_key_id,omitempty"`
 	ApiKeyName string `json:"api_key_name,omitempty"`
 }
 
@@ -19,38 +22,74 @@ type Credentials struct {
 	PartnerId      *string  `json:"partner_id,omitempty"`
 	Permissions    []string `json:"permissions"`
 	Role           string   `json:"role"`
+	CustomRoleId   *string  `json:"custom_role_id,omitempty"`
+
+	ApiKeyScope *ApiKeyScope `json:"api_key_scope,omitempty"`
+	AllowedIps  []string     `json:"allowed_ips,omitempty"`
 }
 
 func AdaptCredentialDto(creds models.Credentials) Credentials {
-	permissions := pure_utils.Map(creds.Role.Permissions(),
+	permissions := pure_utils.Map(creds.Permissions(),
 		func(p models.Permission) string { return p.String() })
 
+	var apiKeyScope *ApiKeyScope
+	if !creds.ApiKeyScope.IsEmpty() {
+		scope := AdaptApiKeyScope(creds.ApiKeyScope)
+		apiKeyScope = &scope
+	}
+
 	return Credentials{
 		ActorIdentity: Identity{
 			UserId:     string(creds.ActorIdentity.UserId),
 			Email:      creds.ActorIdentity.Email,
 			FirstName:  creds.ActorIdentity.FirstName,
 			LastName:   creds.ActorIdentity.LastName,
+			ApiKeyId:   creds.ActorIdentity.ApiKeyId,
 			ApiKeyName: creds.ActorIdentity.ApiKeyName,
 		},
 		OrganizationId: creds.OrganizationId,
 		PartnerId:      creds.PartnerId,
 		Permissions:    permissions,
 		Role:           creds.Role.String(),
+		CustomRoleId:   creds.CustomRoleId,
+		ApiKeyScope:    apiKeyScope,
+		AllowedIps:     pure_utils.Map(creds.AllowedNetworks, netip.Prefix.String),
 	}
 }
 
 func AdaptCredential(dto Credentials) models.Credentials {
-	return models.Credentials{
+	credentials := models.Credentials{
 		ActorIdentity: models.Identity{
 			UserId:     models.UserId(dto.ActorIdentity.UserId),
 			Email:      dto.ActorIdentity.Email,
 			FirstName:  dto.ActorIdentity.FirstName,
 			LastName:   dto.ActorIdentity.LastName,
+			ApiKeyId:   dto.ActorIdentity.ApiKeyId,
 			ApiKeyName: dto.ActorIdentity.ApiKeyName,
 		},
 		OrganizationId: dto.OrganizationId,
 		PartnerId:      dto.PartnerId,
 		Role:           models.RoleFromString(dto.Role),
 	}
+	if dto.CustomRoleId != nil {
+		credentials.CustomRoleId = dto.CustomRoleId
+		credentials.CustomRolePermissions = make([]models.Permission, 0, len(dto.Permissions))
+		for _, name := range dto.Permissions {
+			if permission, err := models.PermissionFromString(name); err == nil {
+				credentials.CustomRolePermissions = append(credentials.CustomRolePermissions, permission)
+			}
+		}
+	}
+	if dto.ApiKeyScope != nil {
+		credentials.ApiKeyScope = AdaptApiKeyScopeInput(*dto.ApiKeyScope)
+	}
+	if len(dto.AllowedIps) > 0 {
+		// the networks were validated when the key was created, a token with invalid networks is refused everywhere
+		networks, err := models.ParseAllowedNetworks(dto.AllowedIps)
+		if err != nil {
+			networks = []netip.Prefix{{}}
+		}
+		credentials.AllowedNetworks = networks
+	}
+	return credentials
 }
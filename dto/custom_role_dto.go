@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type CustomRole struct {
+	Id             string    `json:"id"`
+	OrganizationId string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Permissions    []string  `json:"permissions"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func AdaptCustomRole(customRole models.CustomRole) CustomRole {
+	return CustomRole{
+		Id:             customRole.Id,
+		OrganizationId: customRole.OrganizationId,
+		Name:           customRole.Name,
+		Description:    customRole.Description,
+		Permissions:    pure_utils.Map(customRole.Permissions, models.Permission.String),
+		CreatedAt:      customRole.CreatedAt,
+		UpdatedAt:      customRole.UpdatedAt,
+	}
+}
+
+func adaptPermissions(names []string) ([]models.Permission, error) {
+	permissions := make([]models.Permission, 0, len(names))
+	for _, name := range names {
+		permission, err := models.PermissionFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+type CreateCustomRoleBody struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func AdaptCreateCustomRoleInput(organizationId string, body CreateCustomRoleBody) (models.CreateCustomRoleInput, error) {
+	permissions, err := adaptPermissions(body.Permissions)
+	if err != nil {
+		return models.CreateCustomRoleInput{}, err
+	}
+	return models.CreateCustomRoleInput{
+		OrganizationId: organizationId,
+		Name:           body.Name,
+		Description:    body.Description,
+		Permissions:    permissions,
+	}, nil
+}
+
+type UpdateCustomRoleBody struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+func AdaptUpdateCustomRoleInput(customRoleId string, body UpdateCustomRoleBody) (models.UpdateCustomRoleInput, error) {
+	input := models.UpdateCustomRoleInput{
+		Id:          customRoleId,
+		Name:        body.Name,
+		Description: body.Description,
+	}
+	if body.Permissions != nil {
+		permissions, err := adaptPermissions(*body.Permissions)
+		if err != nil {
+			return models.UpdateCustomRoleInput{}, err
+		}
+		input.Permissions = &permissions
+	}
+	return input, nil
+}
+
+type AssignCustomRoleBody struct {
+	// null to give back the permissions of the built-in role
+	CustomRoleId *string `json:"custom_role_id"`
+}
@@ -125,3 +125,21 @@ func AdaptDataModelDto(dataModel models.DataModel) DataModel {
 		Tables: pure_utils.MapValues(dataModel.Tables, AdaptTableDto),
 	}
 }
+
+type DataModelDependencyDto struct {
+	Kind       string `json:"kind"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	ScenarioId string `json:"scenario_id,omitempty"`
+	Version    *int   `json:"version,omitempty"`
+}
+
+func AdaptDataModelDependencyDto(dependency models.DataModelDependency) DataModelDependencyDto {
+	return DataModelDependencyDto{
+		Kind:       string(dependency.Kind),
+		Id:         dependency.Id,
+		Name:       dependency.Name,
+		ScenarioId: dependency.ScenarioId,
+		Version:    dependency.Version,
+	}
+}
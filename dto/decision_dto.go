@@ -21,6 +21,20 @@ type DecisionFilters struct {
 	ScheduledExecutionIds []string  `form:"scheduled_execution_id[]"`
 	StartDate             time.Time `form:"start_date"`
 	TriggerObjects        []string  `form:"trigger_object[]"`
+	ReviewDispositions    []string  `form:"review_disposition[]"`
+	IsReviewed            *bool     `form:"is_reviewed"`
+}
+
+type ReviewDecisionBody struct {
+	Disposition string `json:"disposition" binding:"required"`
+	Note        string `json:"note"`
+}
+
+type APIDecisionReview struct {
+	Disposition string    `json:"disposition"`
+	ReviewedBy  *string   `json:"reviewed_by"`
+	ReviewedAt  time.Time `json:"reviewed_at"`
+	Note        string    `json:"note"`
 }
 
 type CreateDecisionBody struct {
@@ -34,6 +48,10 @@ type CreateDecisionWithScenarioBody struct {
 	TriggerObjectType string          `json:"object_type" binding:"required"`
 }
 
+type DryRunDecisionParams struct {
+	DryRun bool `form:"dry_run"`
+}
+
 type CreateDecisionInputDto struct {
 	Body *CreateDecisionBody `in:"body=json"`
 }
@@ -81,6 +99,8 @@ type APIDecision struct {
 	Scenario             APIDecisionScenario `json:"scenario"`
 	Score                int                 `json:"score"`
 	ScheduledExecutionId *string             `json:"scheduled_execution_id"`
+	OutputValues         map[string]any      `json:"output_values"`
+	Review               *APIDecisionReview  `json:"review"`
 }
 
 type APIDecisionWithRules struct {
@@ -106,6 +126,19 @@ func NewAPIDecision(decision models.Decision, marbleAppHost string) APIDecision
 		},
 		Score:                decision.Score,
 		ScheduledExecutionId: decision.ScheduledExecutionId,
+		OutputValues:         decision.OutputValues,
+	}
+	if apiDecision.OutputValues == nil {
+		apiDecision.OutputValues = map[string]any{}
+	}
+
+	if decision.Review != nil {
+		apiDecision.Review = &APIDecisionReview{
+			Disposition: string(decision.Review.Disposition),
+			ReviewedBy:  (*string)(decision.Review.ReviewedBy),
+			ReviewedAt:  decision.Review.ReviewedAt,
+			Note:        decision.Review.Note,
+		}
 	}
 
 	if decision.Case != nil {
@@ -226,3 +259,27 @@ func AdaptDecisionsMetadata(
 	metadata.Count.Skipped = nbSkipped
 	return metadata
 }
+
+type APIAsyncDecision struct {
+	Id        string                `json:"id"`
+	Status    string                `json:"status"`
+	Error     *string               `json:"error"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Decision  *APIDecisionWithRules `json:"decision,omitempty"`
+}
+
+func NewAPIAsyncDecision(asyncDecision models.AsyncDecisionWithResult, marbleAppHost string) APIAsyncDecision {
+	apiAsyncDecision := APIAsyncDecision{
+		Id:        asyncDecision.Id,
+		Status:    string(asyncDecision.Status),
+		Error:     asyncDecision.Error,
+		CreatedAt: asyncDecision.CreatedAt,
+		UpdatedAt: asyncDecision.UpdatedAt,
+	}
+	if asyncDecision.Decision != nil {
+		decision := NewAPIDecisionWithRule(*asyncDecision.Decision, marbleAppHost, false)
+		apiAsyncDecision.Decision = &decision
+	}
+	return apiAsyncDecision
+}
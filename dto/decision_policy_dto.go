@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type DecisionPolicyDto struct {
+	Id                   string    `json:"id"`
+	TriggerObjectType    string    `json:"trigger_object_type"`
+	ScenarioIds          []string  `json:"scenario_ids"`
+	StopOnReject         bool      `json:"stop_on_reject"`
+	Aggregation          string    `json:"aggregation"`
+	ScoreReviewThreshold *int      `json:"score_review_threshold"`
+	ScoreRejectThreshold *int      `json:"score_reject_threshold"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+func AdaptDecisionPolicyDto(policy models.DecisionPolicy) DecisionPolicyDto {
+	return DecisionPolicyDto{
+		Id:                   policy.Id,
+		TriggerObjectType:    policy.TriggerObjectType,
+		ScenarioIds:          policy.ScenarioIds,
+		StopOnReject:         policy.StopOnReject,
+		Aggregation:          string(policy.Aggregation),
+		ScoreReviewThreshold: policy.ScoreReviewThreshold,
+		ScoreRejectThreshold: policy.ScoreRejectThreshold,
+		CreatedAt:            policy.CreatedAt,
+		UpdatedAt:            policy.UpdatedAt,
+	}
+}
+
+type CreateDecisionPolicyBody struct {
+	TriggerObjectType    string   `json:"trigger_object_type" binding:"required"`
+	ScenarioIds          []string `json:"scenario_ids" binding:"required"`
+	StopOnReject         bool     `json:"stop_on_reject"`
+	Aggregation          string   `json:"aggregation" binding:"required"`
+	ScoreReviewThreshold *int     `json:"score_review_threshold"`
+	ScoreRejectThreshold *int     `json:"score_reject_threshold"`
+}
+
+func AdaptCreateDecisionPolicyInput(organizationId string, body CreateDecisionPolicyBody) models.CreateDecisionPolicyInput {
+	return models.CreateDecisionPolicyInput{
+		OrganizationId:       organizationId,
+		TriggerObjectType:    body.TriggerObjectType,
+		ScenarioIds:          body.ScenarioIds,
+		StopOnReject:         body.StopOnReject,
+		Aggregation:          models.DecisionPolicyAggregation(body.Aggregation),
+		ScoreReviewThreshold: body.ScoreReviewThreshold,
+		ScoreRejectThreshold: body.ScoreRejectThreshold,
+	}
+}
+
+type UpdateDecisionPolicyBody struct {
+	ScenarioIds          *[]string            `json:"scenario_ids"`
+	StopOnReject         *bool                `json:"stop_on_reject"`
+	Aggregation          *string              `json:"aggregation"`
+	ScoreReviewThreshold pure_utils.Null[int] `json:"score_review_threshold"`
+	ScoreRejectThreshold pure_utils.Null[int] `json:"score_reject_threshold"`
+}
+
+func AdaptUpdateDecisionPolicyInput(id string, body UpdateDecisionPolicyBody) models.UpdateDecisionPolicyInput {
+	input := models.UpdateDecisionPolicyInput{
+		Id:                   id,
+		ScenarioIds:          body.ScenarioIds,
+		StopOnReject:         body.StopOnReject,
+		ScoreReviewThreshold: body.ScoreReviewThreshold,
+		ScoreRejectThreshold: body.ScoreRejectThreshold,
+	}
+	if body.Aggregation != nil {
+		aggregation := models.DecisionPolicyAggregation(*body.Aggregation)
+		input.Aggregation = &aggregation
+	}
+	return input
+}
+
+type CreatePolicyDecisionBody struct {
+	TriggerObjectRaw  json.RawMessage `json:"trigger_object" binding:"required"`
+	TriggerObjectType string          `json:"object_type" binding:"required"`
+}
+
+type APIPolicyDecision struct {
+	Id                 string                 `json:"id"`
+	DecisionPolicyId   *string                `json:"decision_policy_id"`
+	TriggerObjectType  string                 `json:"trigger_object_type"`
+	Outcome            string                 `json:"outcome"`
+	Score              int                    `json:"score"`
+	SkippedScenarioIds []string               `json:"skipped_scenario_ids"`
+	StoppedEarly       bool                   `json:"stopped_early"`
+	CreatedAt          time.Time              `json:"created_at"`
+	Decisions          []APIDecisionWithRules `json:"decisions"`
+}
+
+func NewAPIPolicyDecision(
+	policyDecision models.PolicyDecisionWithDecisions,
+	marbleAppHost string,
+	withRuleExecution bool,
+) APIPolicyDecision {
+	return APIPolicyDecision{
+		Id:                 policyDecision.Id,
+		DecisionPolicyId:   policyDecision.DecisionPolicyId,
+		TriggerObjectType:  policyDecision.TriggerObjectType,
+		Outcome:            policyDecision.Outcome.String(),
+		Score:              policyDecision.Score,
+		SkippedScenarioIds: policyDecision.SkippedScenarioIds,
+		StoppedEarly:       policyDecision.StoppedEarly,
+		CreatedAt:          policyDecision.CreatedAt,
+		Decisions: pure_utils.Map(policyDecision.Decisions, func(d models.DecisionWithRuleExecutions) APIDecisionWithRules {
+			return NewAPIDecisionWithRule(d, marbleAppHost, withRuleExecution)
+		}),
+	}
+}
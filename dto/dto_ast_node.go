@@ -86,3 +86,17 @@ func adaptFunctionName(f string) (ast.Function, error) {
 
 	return ast.FUNC_UNKNOWN, fmt.Errorf("unknown function: %v", f)
 }
+
+type ParseFormulaBody struct {
+	Formula string `json:"formula"`
+}
+
+type FormatFormulaBody struct {
+	AstExpression NodeDto `json:"ast_expression"`
+}
+
+type FormulaSyntaxErrorDto struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
@@ -0,0 +1,229 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type OrganizationConfigDto struct {
+	Version           int                                     `json:"version"`
+	ExportedAt        time.Time                               `json:"exported_at"`
+	DataModel         OrganizationConfigDataModelDto          `json:"data_model"`
+	CustomLists       []OrganizationConfigCustomListDto       `json:"custom_lists"`
+	Inboxes           []OrganizationConfigInboxDto            `json:"inboxes"`
+	Tags              []OrganizationConfigTagDto              `json:"tags"`
+	Webhooks          []OrganizationConfigWebhookDto          `json:"webhooks"`
+	ScenarioWorkflows []OrganizationConfigScenarioWorkflowDto `json:"scenario_workflows"`
+}
+
+type OrganizationConfigDataModelDto struct {
+	Tables []OrganizationConfigTableDto `json:"tables"`
+	Links  []OrganizationConfigLinkDto  `json:"links"`
+	Pivots []OrganizationConfigPivotDto `json:"pivots"`
+}
+
+type OrganizationConfigTableDto struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Fields      []OrganizationConfigFieldDto `json:"fields"`
+}
+
+type OrganizationConfigFieldDto struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	DataType    string `json:"data_type"`
+	Nullable    bool   `json:"nullable"`
+	IsEnum      bool   `json:"is_enum"`
+	IsUnique    bool   `json:"is_unique"`
+}
+
+type OrganizationConfigLinkDto struct {
+	Name        string `json:"name"`
+	ChildTable  string `json:"child_table"`
+	ChildField  string `json:"child_field"`
+	ParentTable string `json:"parent_table"`
+	ParentField string `json:"parent_field"`
+}
+
+type OrganizationConfigPivotDto struct {
+	BaseTable string   `json:"base_table"`
+	Field     string   `json:"field"`
+	PathLinks []string `json:"path_links"`
+}
+
+type OrganizationConfigCustomListDto struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Values      []string `json:"values"`
+}
+
+type OrganizationConfigInboxDto struct {
+	Name string `json:"name"`
+}
+
+type OrganizationConfigTagDto struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type OrganizationConfigWebhookDto struct {
+	Url               string   `json:"url"`
+	EventTypes        []string `json:"event_types"`
+	HttpTimeout       *int     `json:"http_timeout"`
+	RateLimit         *int     `json:"rate_limit"`
+	RateLimitDuration *int     `json:"rate_limit_duration"`
+}
+
+type OrganizationConfigScenarioWorkflowDto struct {
+	ScenarioName string   `json:"scenario_name"`
+	WorkflowType string   `json:"workflow_type"`
+	InboxName    *string  `json:"inbox_name"`
+	Outcomes     []string `json:"outcomes"`
+}
+
+type OrganizationConfigChangeDto struct {
+	Resource string   `json:"resource"`
+	Key      string   `json:"key"`
+	Action   string   `json:"action"`
+	Details  []string `json:"details"`
+}
+
+type OrganizationConfigConflictDto struct {
+	Resource string `json:"resource"`
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+}
+
+type OrganizationConfigPlanDto struct {
+	Changes   []OrganizationConfigChangeDto   `json:"changes"`
+	Conflicts []OrganizationConfigConflictDto `json:"conflicts"`
+}
+
+func AdaptOrganizationConfigDto(config models.OrganizationConfig) OrganizationConfigDto {
+	return OrganizationConfigDto{
+		Version:    config.Version,
+		ExportedAt: config.ExportedAt,
+		DataModel: OrganizationConfigDataModelDto{
+			Tables: pure_utils.Map(config.DataModel.Tables, func(table models.OrganizationConfigTable) OrganizationConfigTableDto {
+				return OrganizationConfigTableDto{
+					Name:        table.Name,
+					Description: table.Description,
+					Fields: pure_utils.Map(table.Fields, func(field models.OrganizationConfigField) OrganizationConfigFieldDto {
+						return OrganizationConfigFieldDto{
+							Name:        field.Name,
+							Description: field.Description,
+							DataType:    field.DataType.String(),
+							Nullable:    field.Nullable,
+							IsEnum:      field.IsEnum,
+							IsUnique:    field.IsUnique,
+						}
+					}),
+				}
+			}),
+			Links: pure_utils.Map(config.DataModel.Links, func(link models.OrganizationConfigLink) OrganizationConfigLinkDto {
+				return OrganizationConfigLinkDto(link)
+			}),
+			Pivots: pure_utils.Map(config.DataModel.Pivots, func(pivot models.OrganizationConfigPivot) OrganizationConfigPivotDto {
+				return OrganizationConfigPivotDto(pivot)
+			}),
+		},
+		CustomLists: pure_utils.Map(config.CustomLists, func(customList models.OrganizationConfigCustomList) OrganizationConfigCustomListDto {
+			return OrganizationConfigCustomListDto(customList)
+		}),
+		Inboxes: pure_utils.Map(config.Inboxes, func(inbox models.OrganizationConfigInbox) OrganizationConfigInboxDto {
+			return OrganizationConfigInboxDto(inbox)
+		}),
+		Tags: pure_utils.Map(config.Tags, func(tag models.OrganizationConfigTag) OrganizationConfigTagDto {
+			return OrganizationConfigTagDto(tag)
+		}),
+		Webhooks: pure_utils.Map(config.Webhooks, func(webhook models.OrganizationConfigWebhook) OrganizationConfigWebhookDto {
+			return OrganizationConfigWebhookDto(webhook)
+		}),
+		ScenarioWorkflows: pure_utils.Map(config.ScenarioWorkflows,
+			func(workflow models.OrganizationConfigScenarioWorkflow) OrganizationConfigScenarioWorkflowDto {
+				return OrganizationConfigScenarioWorkflowDto{
+					ScenarioName: workflow.ScenarioName,
+					WorkflowType: string(workflow.WorkflowType),
+					InboxName:    workflow.InboxName,
+					Outcomes:     pure_utils.Map(workflow.Outcomes, func(o models.Outcome) string { return o.String() }),
+				}
+			}),
+	}
+}
+
+func AdaptOrganizationConfig(config OrganizationConfigDto) models.OrganizationConfig {
+	return models.OrganizationConfig{
+		Version:    config.Version,
+		ExportedAt: config.ExportedAt,
+		DataModel: models.OrganizationConfigDataModel{
+			Tables: pure_utils.Map(config.DataModel.Tables, func(table OrganizationConfigTableDto) models.OrganizationConfigTable {
+				return models.OrganizationConfigTable{
+					Name:        table.Name,
+					Description: table.Description,
+					Fields: pure_utils.Map(table.Fields, func(field OrganizationConfigFieldDto) models.OrganizationConfigField {
+						return models.OrganizationConfigField{
+							Name:        field.Name,
+							Description: field.Description,
+							DataType:    models.DataTypeFrom(field.DataType),
+							Nullable:    field.Nullable,
+							IsEnum:      field.IsEnum,
+							IsUnique:    field.IsUnique,
+						}
+					}),
+				}
+			}),
+			Links: pure_utils.Map(config.DataModel.Links, func(link OrganizationConfigLinkDto) models.OrganizationConfigLink {
+				return models.OrganizationConfigLink(link)
+			}),
+			Pivots: pure_utils.Map(config.DataModel.Pivots, func(pivot OrganizationConfigPivotDto) models.OrganizationConfigPivot {
+				if pivot.PathLinks == nil {
+					pivot.PathLinks = []string{}
+				}
+				return models.OrganizationConfigPivot(pivot)
+			}),
+		},
+		CustomLists: pure_utils.Map(config.CustomLists, func(customList OrganizationConfigCustomListDto) models.OrganizationConfigCustomList {
+			return models.OrganizationConfigCustomList(customList)
+		}),
+		Inboxes: pure_utils.Map(config.Inboxes, func(inbox OrganizationConfigInboxDto) models.OrganizationConfigInbox {
+			return models.OrganizationConfigInbox(inbox)
+		}),
+		Tags: pure_utils.Map(config.Tags, func(tag OrganizationConfigTagDto) models.OrganizationConfigTag {
+			return models.OrganizationConfigTag(tag)
+		}),
+		Webhooks: pure_utils.Map(config.Webhooks, func(webhook OrganizationConfigWebhookDto) models.OrganizationConfigWebhook {
+			return models.OrganizationConfigWebhook(webhook)
+		}),
+		ScenarioWorkflows: pure_utils.Map(config.ScenarioWorkflows,
+			func(workflow OrganizationConfigScenarioWorkflowDto) models.OrganizationConfigScenarioWorkflow {
+				return models.OrganizationConfigScenarioWorkflow{
+					ScenarioName: workflow.ScenarioName,
+					WorkflowType: models.WorkflowType(workflow.WorkflowType),
+					InboxName:    workflow.InboxName,
+					Outcomes:     pure_utils.Map(workflow.Outcomes, models.OutcomeFrom),
+				}
+			}),
+	}
+}
+
+func AdaptOrganizationConfigPlanDto(plan models.OrganizationConfigPlan) OrganizationConfigPlanDto {
+	return OrganizationConfigPlanDto{
+		Changes: pure_utils.Map(plan.Changes, func(change models.OrganizationConfigChange) OrganizationConfigChangeDto {
+			return OrganizationConfigChangeDto{
+				Resource: string(change.Resource),
+				Key:      change.Key,
+				Action:   string(change.Action),
+				Details:  change.Details,
+			}
+		}),
+		Conflicts: pure_utils.Map(plan.Conflicts, func(conflict models.OrganizationConfigConflict) OrganizationConfigConflictDto {
+			return OrganizationConfigConflictDto{
+				Resource: string(conflict.Resource),
+				Key:      conflict.Key,
+				Message:  conflict.Message,
+			}
+		}),
+	}
+}
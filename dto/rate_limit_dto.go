@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type RateLimitPolicy struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	MonthlyQuota      int64   `json:"monthly_quota"`
+}
+
+type RateLimit struct {
+	OrganizationId string  `json:"organization_id"`
+	ApiKeyId       *string `json:"api_key_id"`
+	// The default rate limits apply to the organization, which has none of its own
+	IsDefault bool `json:"is_default"`
+	RateLimitPolicy
+	Period          string `json:"period"`
+	RequestsInMonth int64  `json:"requests_in_month"`
+}
+
+func AdaptRateLimit(usage models.RateLimitUsage) RateLimit {
+	return RateLimit{
+		OrganizationId: usage.Settings.OrganizationId,
+		ApiKeyId:       usage.Settings.ApiKeyId,
+		IsDefault:      usage.Settings.Id == "",
+		RateLimitPolicy: RateLimitPolicy{
+			RequestsPerSecond: usage.Settings.Policy.RequestsPerSecond,
+			Burst:             usage.Settings.Policy.Burst,
+			MonthlyQuota:      usage.Settings.Policy.MonthlyQuota,
+		},
+		Period:          usage.Period,
+		RequestsInMonth: usage.RequestsInMonth,
+	}
+}
+
+func AdaptUpsertRateLimitInput(organizationId string, apiKeyId *string, body RateLimitPolicy) models.UpsertRateLimitInput {
+	return models.UpsertRateLimitInput{
+		OrganizationId: organizationId,
+		ApiKeyId:       apiKeyId,
+		Policy: models.RateLimitPolicy{
+			RequestsPerSecond: body.RequestsPerSecond,
+			Burst:             body.Burst,
+			MonthlyQuota:      body.MonthlyQuota,
+		},
+	}
+}
+
+// RateLimitHeaders are the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers of the IETF draft, and the
+// Retry-After header of a refused request
+func RateLimitHeaders(status models.RateLimitStatus) http.Header {
+	headers := http.Header{}
+	headers.Set("RateLimit-Limit", strconv.FormatInt(status.Limit, 10))
+	headers.Set("RateLimit-Remaining", strconv.FormatInt(status.Remaining, 10))
+	headers.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(status.Reset), 10))
+	if !status.Allowed {
+		headers.Set("Retry-After", strconv.FormatInt(max(1, ceilSeconds(status.RetryAfter)), 10))
+	}
+	return headers
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	headers := RateLimitHeaders(models.RateLimitStatus{
+		Allowed:   true,
+		Limit:     10,
+		Remaining: 4,
+		Reset:     5500 * time.Millisecond,
+	})
+	assert.Equal(t, "10", headers.Get("RateLimit-Limit"))
+	assert.Equal(t, "4", headers.Get("RateLimit-Remaining"))
+	assert.Equal(t, "6", headers.Get("RateLimit-Reset"))
+	assert.Empty(t, headers.Get("Retry-After"))
+
+	headers = RateLimitHeaders(models.RateLimitStatus{
+		Allowed:    false,
+		Limit:      10,
+		Reset:      10 * time.Second,
+		RetryAfter: 100 * time.Millisecond,
+	})
+	assert.Equal(t, "0", headers.Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", headers.Get("Retry-After"))
+}
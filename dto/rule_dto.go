@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
 )
 
 type ListRulesInput struct {
@@ -22,6 +23,7 @@ type RuleDto struct {
 	Name                 string    `json:"name"`
 	Description          string    `json:"description"`
 	FormulaAstExpression *NodeDto  `json:"formula_ast_expression"`
+	Formula              *string   `json:"formula"`
 	ScoreModifier        int       `json:"scoreModifier"`
 	CreatedAt            time.Time `json:"createdAt"`
 	RuleGroup            string    `json:"rule_group"`
@@ -33,6 +35,7 @@ type CreateRuleInputBody struct {
 	Name                 string   `json:"name"`
 	Description          string   `json:"description"`
 	FormulaAstExpression *NodeDto `json:"formula_ast_expression"`
+	Formula              *string  `json:"formula"`
 	ScoreModifier        int      `json:"scoreModifier"`
 	RuleGroup            string   `json:"rule_group"`
 }
@@ -46,6 +49,7 @@ type UpdateRuleBody struct {
 	Name                 *string  `json:"name,omitempty"`
 	Description          *string  `json:"description,omitempty"`
 	FormulaAstExpression *NodeDto `json:"formula_ast_expression"`
+	Formula              *string  `json:"formula"`
 	ScoreModifier        *int     `json:"scoreModifier,omitempty"`
 	RuleGroup            *string  `json:"rule_group"`
 }
@@ -60,12 +64,18 @@ type DeleteRuleInput struct {
 
 func AdaptRuleDto(rule models.Rule) (RuleDto, error) {
 	var formulaAstExpression *NodeDto
+	var formula *string
 	if rule.FormulaAstExpression != nil {
 		nodeDto, err := AdaptNodeDto(*rule.FormulaAstExpression)
 		if err != nil {
 			return RuleDto{}, err
 		}
 		formulaAstExpression = &nodeDto
+
+		// formulas that cannot be written in the text syntax are only returned as ast
+		if text, err := ast.FormatDsl(*rule.FormulaAstExpression); err == nil {
+			formula = &text
+		}
 	}
 
 	return RuleDto{
@@ -75,6 +85,7 @@ func AdaptRuleDto(rule models.Rule) (RuleDto, error) {
 		Name:                 rule.Name,
 		Description:          rule.Description,
 		FormulaAstExpression: formulaAstExpression,
+		Formula:              formula,
 		ScoreModifier:        rule.ScoreModifier,
 		CreatedAt:            rule.CreatedAt,
 		RuleGroup:            rule.RuleGroup,
@@ -101,6 +112,13 @@ func AdaptCreateRuleInput(body CreateRuleInputBody, organizationId string) (mode
 		}
 		createRuleInput.FormulaAstExpression = &node
 	}
+	formula, err := adaptRuleFormula(body.Formula, body.FormulaAstExpression)
+	if err != nil {
+		return models.CreateRuleInput{}, err
+	}
+	if formula != nil {
+		createRuleInput.FormulaAstExpression = formula
+	}
 
 	return createRuleInput, nil
 }
@@ -124,6 +142,28 @@ func AdaptUpdateRule(ruleId string, body UpdateRuleBody) (models.UpdateRuleInput
 		}
 		updateRuleInput.FormulaAstExpression = &node
 	}
+	formula, err := adaptRuleFormula(body.Formula, body.FormulaAstExpression)
+	if err != nil {
+		return models.UpdateRuleInput{}, err
+	}
+	if formula != nil {
+		updateRuleInput.FormulaAstExpression = formula
+	}
 
 	return updateRuleInput, nil
 }
+
+// adaptRuleFormula parses the formula given in the text syntax, if any
+func adaptRuleFormula(formula *string, formulaAstExpression *NodeDto) (*ast.Node, error) {
+	if formula == nil {
+		return nil, nil
+	}
+	if formulaAstExpression != nil {
+		return nil, fmt.Errorf("formula and formula_ast_expression cannot both be given: %w", models.BadParameterError)
+	}
+	node, err := ast.ParseDsl(*formula)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula: %w %w", err, models.BadParameterError)
+	}
+	return &node, nil
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+func TestAdaptCreateRuleInput_formula(t *testing.T) {
+	formula := `payload.amount > 1000`
+	input, err := AdaptCreateRuleInput(CreateRuleInputBody{Name: "rule", Formula: &formula}, "org")
+	assert.NoError(t, err)
+	assert.Equal(t, ast.FUNC_GREATER, input.FormulaAstExpression.Function)
+
+	invalid := `payload.amount >`
+	_, err = AdaptCreateRuleInput(CreateRuleInputBody{Name: "rule", Formula: &invalid}, "org")
+	assert.ErrorIs(t, err, models.BadParameterError)
+	assert.ErrorContains(t, err, "line 1, column 17")
+
+	_, err = AdaptCreateRuleInput(CreateRuleInputBody{
+		Name:                 "rule",
+		Formula:              &formula,
+		FormulaAstExpression: &NodeDto{Constant: true},
+	}, "org")
+	assert.ErrorIs(t, err, models.BadParameterError)
+}
+
+func TestAdaptRuleDto_formula(t *testing.T) {
+	node := ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("is_blocked"))
+	ruleDto, err := AdaptRuleDto(models.Rule{FormulaAstExpression: &node})
+	assert.NoError(t, err)
+	assert.Equal(t, "payload.is_blocked", *ruleDto.Formula)
+}
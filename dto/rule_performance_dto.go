@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type RulePerformanceParams struct {
+	StartDate time.Time `form:"start_date" binding:"required"`
+	EndDate   time.Time `form:"end_date" binding:"required"`
+}
+
+type APIRulePerformance struct {
+	RuleId            string   `json:"rule_id"`
+	RuleName          string   `json:"rule_name"`
+	NbMatched         int      `json:"nb_matched"`
+	TruePositives     int      `json:"true_positives"`
+	FalsePositives    int      `json:"false_positives"`
+	FalseNegatives    int      `json:"false_negatives"`
+	TrueNegatives     int      `json:"true_negatives"`
+	Precision         *float64 `json:"precision"`
+	Recall            *float64 `json:"recall"`
+	FalsePositiveRate *float64 `json:"false_positive_rate"`
+	Lift              *float64 `json:"lift"`
+}
+
+type APIRulePerformanceReport struct {
+	ScenarioId  string               `json:"scenario_id"`
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"`
+	NbDecisions int                  `json:"nb_decisions"`
+	NbLabeled   int                  `json:"nb_labeled"`
+	NbPositives int                  `json:"nb_positives"`
+	Rules       []APIRulePerformance `json:"rules"`
+}
+
+func AdaptRulePerformance(p models.RulePerformance) APIRulePerformance {
+	return APIRulePerformance{
+		RuleId:            p.RuleId,
+		RuleName:          p.RuleName,
+		NbMatched:         p.NbMatched,
+		TruePositives:     p.TruePositives,
+		FalsePositives:    p.FalsePositives,
+		FalseNegatives:    p.FalseNegatives,
+		TrueNegatives:     p.TrueNegatives,
+		Precision:         p.Precision(),
+		Recall:            p.Recall(),
+		FalsePositiveRate: p.FalsePositiveRate(),
+		Lift:              p.Lift(),
+	}
+}
+
+func AdaptRulePerformanceReport(report models.RulePerformanceReport) APIRulePerformanceReport {
+	return APIRulePerformanceReport{
+		ScenarioId:  report.ScenarioId,
+		StartDate:   report.StartDate,
+		EndDate:     report.EndDate,
+		NbDecisions: report.NbDecisions,
+		NbLabeled:   report.NbLabeled,
+		NbPositives: report.NbPositives,
+		Rules:       pure_utils.Map(report.Rules, AdaptRulePerformance),
+	}
+}
+
+// WriteRulePerformanceReportCsv writes one line per rule. Metrics that cannot be computed (no labeled decision) are
+// left empty.
+func WriteRulePerformanceReportCsv(w io.Writer, report models.RulePerformanceReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"rule_id", "rule_name", "nb_matched", "true_positives", "false_positives", "false_negatives",
+		"true_negatives", "precision", "recall", "false_positive_rate", "lift",
+	})
+	if err != nil {
+		return err
+	}
+
+	formatRatio := func(r *float64) string {
+		if r == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*r, 'f', 4, 64)
+	}
+	for _, rule := range report.Rules {
+		err := writer.Write([]string{
+			rule.RuleId,
+			rule.RuleName,
+			strconv.Itoa(rule.NbMatched),
+			strconv.Itoa(rule.TruePositives),
+			strconv.Itoa(rule.FalsePositives),
+			strconv.Itoa(rule.FalseNegatives),
+			strconv.Itoa(rule.TrueNegatives),
+			formatRatio(rule.Precision()),
+			formatRatio(rule.Recall()),
+			formatRatio(rule.FalsePositiveRate()),
+			formatRatio(rule.Lift()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
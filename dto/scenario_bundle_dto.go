@@ -0,0 +1,283 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type ExportScenarioParams struct {
+	Format string `form:"format"`
+}
+
+type ImportScenarioParams struct {
+	DryRun bool `form:"dry_run"`
+}
+
+type ScenarioBundleDto struct {
+	Version     int                                 `json:"version"`
+	ExportedAt  time.Time                           `json:"exported_at"`
+	Scenario    ScenarioBundleScenarioDto           `json:"scenario"`
+	Iterations  []ScenarioBundleIterationDto        `json:"iterations"`
+	CustomLists []ScenarioBundleCustomListDto       `json:"custom_lists"`
+	DataModel   []ScenarioBundleFieldRequirementDto `json:"data_model"`
+}
+
+type ScenarioBundleScenarioDto struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	TriggerObjectType string `json:"trigger_object_type"`
+}
+
+type ScenarioBundleIterationDto struct {
+	Version                       *int                    `json:"version"`
+	TriggerConditionAstExpression *NodeDto                `json:"trigger_condition_ast_expression"`
+	Rules                         []ScenarioBundleRuleDto `json:"rules"`
+	ScoreReviewThreshold          *int                    `json:"score_review_threshold"`
+	ScoreRejectThreshold          *int                    `json:"score_reject_threshold"`
+	BatchTriggerSQL               string                  `json:"batch_trigger_sql"`
+	Schedule                      string                  `json:"schedule"`
+	ScheduleTimezone              string                  `json:"schedule_timezone"`
+	OutputVariables               []OutputVariableDto     `json:"output_variables"`
+}
+
+type ScenarioBundleRuleDto struct {
+	DisplayOrder         int      `json:"display_order"`
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	FormulaAstExpression *NodeDto `json:"formula_ast_expression"`
+	ScoreModifier        int      `json:"score_modifier"`
+	RuleGroup            string   `json:"rule_group"`
+}
+
+type ScenarioBundleCustomListDto struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Values      []string `json:"values"`
+}
+
+type ScenarioBundleFieldRequirementDto struct {
+	TableName string   `json:"table_name"`
+	Path      []string `json:"path"`
+	FieldName string   `json:"field_name"`
+	DataType  string   `json:"data_type"`
+}
+
+func AdaptScenarioBundleDto(bundle models.ScenarioBundle) (ScenarioBundleDto, error) {
+	iterations, err := pure_utils.MapErr(bundle.Iterations, adaptScenarioBundleIterationDto)
+	if err != nil {
+		return ScenarioBundleDto{}, err
+	}
+	return ScenarioBundleDto{
+		Version:    bundle.Version,
+		ExportedAt: bundle.ExportedAt,
+		Scenario: ScenarioBundleScenarioDto{
+			Name:              bundle.Scenario.Name,
+			Description:       bundle.Scenario.Description,
+			TriggerObjectType: bundle.Scenario.TriggerObjectType,
+		},
+		Iterations: iterations,
+		CustomLists: pure_utils.Map(bundle.CustomLists, func(customList models.ScenarioBundleCustomList) ScenarioBundleCustomListDto {
+			return ScenarioBundleCustomListDto(customList)
+		}),
+		DataModel: pure_utils.Map(bundle.DataModel, func(requirement models.ScenarioBundleFieldRequirement) ScenarioBundleFieldRequirementDto {
+			path := requirement.Path
+			if path == nil {
+				path = []string{}
+			}
+			return ScenarioBundleFieldRequirementDto{
+				TableName: requirement.TableName,
+				Path:      path,
+				FieldName: requirement.FieldName,
+				DataType:  requirement.DataType.String(),
+			}
+		}),
+	}, nil
+}
+
+func adaptScenarioBundleIterationDto(iteration models.ScenarioBundleIteration) (ScenarioBundleIterationDto, error) {
+	triggerCondition, err := adaptOptionalNodeDto(iteration.TriggerConditionAstExpression)
+	if err != nil {
+		return ScenarioBundleIterationDto{}, err
+	}
+	rules, err := pure_utils.MapErr(iteration.Rules, func(rule models.ScenarioBundleRule) (ScenarioBundleRuleDto, error) {
+		formula, err := adaptOptionalNodeDto(rule.FormulaAstExpression)
+		if err != nil {
+			return ScenarioBundleRuleDto{}, err
+		}
+		return ScenarioBundleRuleDto{
+			DisplayOrder:         rule.DisplayOrder,
+			Name:                 rule.Name,
+			Description:          rule.Description,
+			FormulaAstExpression: formula,
+			ScoreModifier:        rule.ScoreModifier,
+			RuleGroup:            rule.RuleGroup,
+		}, nil
+	})
+	if err != nil {
+		return ScenarioBundleIterationDto{}, err
+	}
+	outputVariables, err := pure_utils.MapErr(iteration.OutputVariables, AdaptOutputVariableDto)
+	if err != nil {
+		return ScenarioBundleIterationDto{}, err
+	}
+	return ScenarioBundleIterationDto{
+		Version:                       iteration.Version,
+		TriggerConditionAstExpression: triggerCondition,
+		Rules:                         rules,
+		ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+		ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+		BatchTriggerSQL:               iteration.BatchTriggerSQL,
+		Schedule:                      iteration.Schedule,
+		ScheduleTimezone:              iteration.ScheduleTimezone,
+		OutputVariables:               outputVariables,
+	}, nil
+}
+
+func AdaptScenarioBundle(bundle ScenarioBundleDto) (models.ScenarioBundle, error) {
+	iterations, err := pure_utils.MapErr(bundle.Iterations, adaptScenarioBundleIteration)
+	if err != nil {
+		return models.ScenarioBundle{}, err
+	}
+	return models.ScenarioBundle{
+		Version:    bundle.Version,
+		ExportedAt: bundle.ExportedAt,
+		Scenario: models.ScenarioBundleScenario{
+			Name:              bundle.Scenario.Name,
+			Description:       bundle.Scenario.Description,
+			TriggerObjectType: bundle.Scenario.TriggerObjectType,
+		},
+		Iterations: iterations,
+		CustomLists: pure_utils.Map(bundle.CustomLists, func(customList ScenarioBundleCustomListDto) models.ScenarioBundleCustomList {
+			return models.ScenarioBundleCustomList(customList)
+		}),
+		DataModel: pure_utils.Map(bundle.DataModel, func(requirement ScenarioBundleFieldRequirementDto) models.ScenarioBundleFieldRequirement {
+			return models.ScenarioBundleFieldRequirement{
+				TableName: requirement.TableName,
+				Path:      requirement.Path,
+				FieldName: requirement.FieldName,
+				DataType:  models.DataTypeFrom(requirement.DataType),
+			}
+		}),
+	}, nil
+}
+
+func adaptScenarioBundleIteration(iteration ScenarioBundleIterationDto) (models.ScenarioBundleIteration, error) {
+	triggerCondition, err := adaptOptionalASTNode(iteration.TriggerConditionAstExpression)
+	if err != nil {
+		return models.ScenarioBundleIteration{}, err
+	}
+	rules, err := pure_utils.MapErr(iteration.Rules, func(rule ScenarioBundleRuleDto) (models.ScenarioBundleRule, error) {
+		formula, err := adaptOptionalASTNode(rule.FormulaAstExpression)
+		if err != nil {
+			return models.ScenarioBundleRule{}, err
+		}
+		return models.ScenarioBundleRule{
+			DisplayOrder:         rule.DisplayOrder,
+			Name:                 rule.Name,
+			Description:          rule.Description,
+			FormulaAstExpression: formula,
+			ScoreModifier:        rule.ScoreModifier,
+			RuleGroup:            rule.RuleGroup,
+		}, nil
+	})
+	if err != nil {
+		return models.ScenarioBundleIteration{}, err
+	}
+	outputVariables, err := pure_utils.MapErr(iteration.OutputVariables, AdaptOutputVariable)
+	if err != nil {
+		return models.ScenarioBundleIteration{}, err
+	}
+	return models.ScenarioBundleIteration{
+		Version:                       iteration.Version,
+		TriggerConditionAstExpression: triggerCondition,
+		Rules:                         rules,
+		ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+		ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+		BatchTriggerSQL:               iteration.BatchTriggerSQL,
+		Schedule:                      iteration.Schedule,
+		ScheduleTimezone:              iteration.ScheduleTimezone,
+		OutputVariables:               outputVariables,
+	}, nil
+}
+
+func adaptOptionalASTNode(nodeDto *NodeDto) (*ast.Node, error) {
+	if nodeDto == nil {
+		return nil, nil
+	}
+	node, err := AdaptASTNode(*nodeDto)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// MarshalScenarioBundleYaml encodes the bundle in YAML, with the same keys as its JSON encoding
+func MarshalScenarioBundleYaml(bundle ScenarioBundleDto) ([]byte, error) {
+	jsonBundle, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	var document any
+	if err := json.Unmarshal(jsonBundle, &document); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(document)
+}
+
+// UnmarshalScenarioBundleYaml decodes a bundle encoded in YAML (or in JSON, which is a subset of YAML)
+func UnmarshalScenarioBundleYaml(data []byte) (ScenarioBundleDto, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return ScenarioBundleDto{}, err
+	}
+	jsonBundle, err := json.Marshal(document)
+	if err != nil {
+		return ScenarioBundleDto{}, err
+	}
+	var bundle ScenarioBundleDto
+	if err := json.Unmarshal(jsonBundle, &bundle); err != nil {
+		return ScenarioBundleDto{}, err
+	}
+	return bundle, nil
+}
+
+type ScenarioImportConflictDto struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+type ScenarioImportCustomListDto struct {
+	Name     string  `json:"name"`
+	SourceId string  `json:"source_id"`
+	TargetId *string `json:"target_id"`
+	Create   bool    `json:"create"`
+}
+
+type ScenarioImportResultDto struct {
+	Conflicts   []ScenarioImportConflictDto   `json:"conflicts"`
+	CustomLists []ScenarioImportCustomListDto `json:"custom_lists"`
+	Scenario    *ScenarioDto                  `json:"scenario"`
+}
+
+func AdaptScenarioImportResultDto(result models.ScenarioImportResult) ScenarioImportResultDto {
+	resultDto := ScenarioImportResultDto{
+		Conflicts: pure_utils.Map(result.Report.Conflicts, func(conflict models.ScenarioImportConflict) ScenarioImportConflictDto {
+			return ScenarioImportConflictDto{Kind: string(conflict.Kind), Message: conflict.Message}
+		}),
+		CustomLists: pure_utils.Map(result.Report.CustomLists, func(customList models.ScenarioImportCustomList) ScenarioImportCustomListDto {
+			return ScenarioImportCustomListDto(customList)
+		}),
+	}
+	if result.Scenario != nil {
+		scenarioDto := AdaptScenarioDto(*result.Scenario)
+		resultDto.Scenario = &scenarioDto
+	}
+	return resultDto
+}
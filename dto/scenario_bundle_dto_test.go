@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioBundleYamlRoundTrip(t *testing.T) {
+	version := 2
+	bundle := ScenarioBundleDto{
+		Version:  1,
+		Scenario: ScenarioBundleScenarioDto{Name: "scenario", TriggerObjectType: "transactions"},
+		Iterations: []ScenarioBundleIterationDto{{
+			Version: &version,
+			TriggerConditionAstExpression: &NodeDto{
+				FuncName: ">",
+				Children: []NodeDto{{Constant: "amount"}, {Constant: 1.5}},
+			},
+			Rules:           []ScenarioBundleRuleDto{},
+			OutputVariables: []OutputVariableDto{},
+		}},
+		CustomLists: []ScenarioBundleCustomListDto{{Id: "list", Name: "list", Values: []string{"a", "b"}}},
+		DataModel:   []ScenarioBundleFieldRequirementDto{{TableName: "transactions", Path: []string{}, FieldName: "amount", DataType: "Float"}},
+	}
+
+	content, err := MarshalScenarioBundleYaml(bundle)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "trigger_object_type: transactions")
+
+	decoded, err := UnmarshalScenarioBundleYaml(content)
+	assert.NoError(t, err)
+
+	expected, _ := json.Marshal(bundle)
+	actual, _ := json.Marshal(decoded)
+	assert.JSONEq(t, string(expected), string(actual))
+}
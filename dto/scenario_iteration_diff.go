@@ -0,0 +1,164 @@
+package dto
+
+import (
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type DiffScenarioIterationParams struct {
+	BaseIterationId *string `form:"base_iteration_id"`
+}
+
+type ValueChangeDto[T any] struct {
+	Before T `json:"before"`
+	After  T `json:"after"`
+}
+
+func adaptValueChangeDto[T any](change *models.ValueChange[T]) *ValueChangeDto[T] {
+	if change == nil {
+		return nil
+	}
+	return &ValueChangeDto[T]{Before: change.Before, After: change.After}
+}
+
+type NodeDiffDto struct {
+	Path   string   `json:"path"`
+	Change string   `json:"change"`
+	Before *NodeDto `json:"before"`
+	After  *NodeDto `json:"after"`
+}
+
+func AdaptNodeDiffDto(diff ast.NodeDiff) (NodeDiffDto, error) {
+	before, err := adaptOptionalNodeDto(diff.Before)
+	if err != nil {
+		return NodeDiffDto{}, err
+	}
+	after, err := adaptOptionalNodeDto(diff.After)
+	if err != nil {
+		return NodeDiffDto{}, err
+	}
+	return NodeDiffDto{
+		Path:   diff.Path,
+		Change: string(diff.Change),
+		Before: before,
+		After:  after,
+	}, nil
+}
+
+func adaptOptionalNodeDto(node *ast.Node) (*NodeDto, error) {
+	if node == nil {
+		return nil, nil
+	}
+	nodeDto, err := AdaptNodeDto(*node)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeDto, nil
+}
+
+type RuleDiffDto struct {
+	BaseRuleId           string                  `json:"base_rule_id"`
+	TargetRuleId         string                  `json:"target_rule_id"`
+	Name                 *ValueChangeDto[string] `json:"name"`
+	Description          *ValueChangeDto[string] `json:"description"`
+	ScoreModifier        *ValueChangeDto[int]    `json:"score_modifier"`
+	RuleGroup            *ValueChangeDto[string] `json:"rule_group"`
+	DisplayOrder         *ValueChangeDto[int]    `json:"display_order"`
+	FormulaAstExpression []NodeDiffDto           `json:"formula_ast_expression"`
+}
+
+func AdaptRuleDiffDto(diff models.RuleDiff) (RuleDiffDto, error) {
+	formulaDiff, err := pure_utils.MapErr(diff.FormulaAstExpression, AdaptNodeDiffDto)
+	if err != nil {
+		return RuleDiffDto{}, err
+	}
+	return RuleDiffDto{
+		BaseRuleId:           diff.BaseRule.Id,
+		TargetRuleId:         diff.TargetRule.Id,
+		Name:                 adaptValueChangeDto(diff.Name),
+		Description:          adaptValueChangeDto(diff.Description),
+		ScoreModifier:        adaptValueChangeDto(diff.ScoreModifier),
+		RuleGroup:            adaptValueChangeDto(diff.RuleGroup),
+		DisplayOrder:         adaptValueChangeDto(diff.DisplayOrder),
+		FormulaAstExpression: formulaDiff,
+	}, nil
+}
+
+type OutputVariableDiffDto struct {
+	Name                 string        `json:"name"`
+	FormulaAstExpression []NodeDiffDto `json:"formula_ast_expression"`
+}
+
+func AdaptOutputVariableDiffDto(diff models.OutputVariableDiff) (OutputVariableDiffDto, error) {
+	formulaDiff, err := pure_utils.MapErr(diff.FormulaAstExpression, AdaptNodeDiffDto)
+	if err != nil {
+		return OutputVariableDiffDto{}, err
+	}
+	return OutputVariableDiffDto{Name: diff.Name, FormulaAstExpression: formulaDiff}, nil
+}
+
+type ScenarioIterationDiffDto struct {
+	BaseIterationId               string                  `json:"base_iteration_id"`
+	TargetIterationId             string                  `json:"target_iteration_id"`
+	ScoreReviewThreshold          *ValueChangeDto[*int]   `json:"score_review_threshold"`
+	ScoreRejectThreshold          *ValueChangeDto[*int]   `json:"score_reject_threshold"`
+	TriggerConditionAstExpression []NodeDiffDto           `json:"trigger_condition_ast_expression"`
+	BatchTriggerSQL               *ValueChangeDto[string] `json:"batch_trigger_sql"`
+	Schedule                      *ValueChangeDto[string] `json:"schedule"`
+	ScheduleTimezone              *ValueChangeDto[string] `json:"schedule_timezone"`
+	AddedRules                    []RuleDto               `json:"added_rules"`
+	RemovedRules                  []RuleDto               `json:"removed_rules"`
+	ModifiedRules                 []RuleDiffDto           `json:"modified_rules"`
+	AddedOutputVariables          []OutputVariableDto     `json:"added_output_variables"`
+	RemovedOutputVariables        []OutputVariableDto     `json:"removed_output_variables"`
+	ModifiedOutputVariables       []OutputVariableDiffDto `json:"modified_output_variables"`
+}
+
+func AdaptScenarioIterationDiffDto(diff models.ScenarioIterationDiff) (ScenarioIterationDiffDto, error) {
+	triggerDiff, err := pure_utils.MapErr(diff.TriggerConditionAstExpression, AdaptNodeDiffDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	addedRules, err := pure_utils.MapErr(diff.AddedRules, AdaptRuleDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	removedRules, err := pure_utils.MapErr(diff.RemovedRules, AdaptRuleDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	modifiedRules, err := pure_utils.MapErr(diff.ModifiedRules, AdaptRuleDiffDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	addedOutputVariables, err := pure_utils.MapErr(diff.AddedOutputVariables, AdaptOutputVariableDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	removedOutputVariables, err := pure_utils.MapErr(diff.RemovedOutputVariables, AdaptOutputVariableDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+	modifiedOutputVariables, err := pure_utils.MapErr(diff.ModifiedOutputVariables, AdaptOutputVariableDiffDto)
+	if err != nil {
+		return ScenarioIterationDiffDto{}, err
+	}
+
+	return ScenarioIterationDiffDto{
+		BaseIterationId:               diff.BaseIterationId,
+		TargetIterationId:             diff.TargetIterationId,
+		ScoreReviewThreshold:          adaptValueChangeDto(diff.ScoreReviewThreshold),
+		ScoreRejectThreshold:          adaptValueChangeDto(diff.ScoreRejectThreshold),
+		TriggerConditionAstExpression: triggerDiff,
+		BatchTriggerSQL:               adaptValueChangeDto(diff.BatchTriggerSQL),
+		Schedule:                      adaptValueChangeDto(diff.Schedule),
+		ScheduleTimezone:              adaptValueChangeDto(diff.ScheduleTimezone),
+		AddedRules:                    addedRules,
+		RemovedRules:                  removedRules,
+		ModifiedRules:                 modifiedRules,
+		AddedOutputVariables:          addedOutputVariables,
+		RemovedOutputVariables:        removedOutputVariables,
+		ModifiedOutputVariables:       modifiedOutputVariables,
+	}, nil
+}
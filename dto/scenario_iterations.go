@@ -7,6 +7,7 @@ import (
 	"github.com/cockroachdb/errors"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
 )
 
 // Read DTO
@@ -24,12 +25,44 @@ type ScenarioIterationDto struct {
 }
 
 type ScenarioIterationBodyDto struct {
-	TriggerConditionAstExpression *NodeDto  `json:"trigger_condition_ast_expression"`
-	Rules                         []RuleDto `json:"rules"`
-	ScoreReviewThreshold          *int      `json:"scoreReviewThreshold"`
-	ScoreRejectThreshold          *int      `json:"scoreRejectThreshold"`
-	BatchTriggerSQL               string    `json:"batchTriggerSql"`
-	Schedule                      string    `json:"schedule"`
+	TriggerConditionAstExpression *NodeDto            `json:"trigger_condition_ast_expression"`
+	Rules                         []RuleDto           `json:"rules"`
+	ScoreReviewThreshold          *int                `json:"scoreReviewThreshold"`
+	ScoreRejectThreshold          *int                `json:"scoreRejectThreshold"`
+	BatchTriggerSQL               string              `json:"batchTriggerSql"`
+	Schedule                      string              `json:"schedule"`
+	ScheduleTimezone              string              `json:"scheduleTimezone"`
+	OutputVariables               []OutputVariableDto `json:"outputVariables"`
+}
+
+type OutputVariableDto struct {
+	Name                 string   `json:"name"`
+	FormulaAstExpression *NodeDto `json:"formula_ast_expression"`
+}
+
+func AdaptOutputVariableDto(outputVariable models.OutputVariable) (OutputVariableDto, error) {
+	outputVariableDto := OutputVariableDto{Name: outputVariable.Name}
+	if outputVariable.FormulaAstExpression != nil {
+		formula, err := AdaptNodeDto(*outputVariable.FormulaAstExpression)
+		if err != nil {
+			return OutputVariableDto{}, fmt.Errorf("unable to marshal output variable %s: %w", outputVariable.Name, err)
+		}
+		outputVariableDto.FormulaAstExpression = &formula
+	}
+	return outputVariableDto, nil
+}
+
+func AdaptOutputVariable(outputVariableDto OutputVariableDto) (models.OutputVariable, error) {
+	outputVariable := models.OutputVariable{Name: outputVariableDto.Name}
+	if outputVariableDto.FormulaAstExpression != nil {
+		formula, err := AdaptASTNode(*outputVariableDto.FormulaAstExpression)
+		if err != nil {
+			return models.OutputVariable{}, errors.Wrapf(models.BadParameterError,
+				"invalid formula of output variable %s", outputVariableDto.Name)
+		}
+		outputVariable.FormulaAstExpression = &formula
+	}
+	return outputVariable, nil
 }
 
 func AdaptScenarioIterationWithBodyDto(si models.ScenarioIteration) (ScenarioIterationWithBodyDto, error) {
@@ -38,6 +71,7 @@ func AdaptScenarioIterationWithBodyDto(si models.ScenarioIteration) (ScenarioIte
 		ScoreRejectThreshold: si.ScoreRejectThreshold,
 		BatchTriggerSQL:      si.BatchTriggerSQL,
 		Schedule:             si.Schedule,
+		ScheduleTimezone:     si.ScheduleTimezone,
 		Rules:                make([]RuleDto, len(si.Rules)),
 	}
 	for i, rule := range si.Rules {
@@ -58,6 +92,12 @@ func AdaptScenarioIterationWithBodyDto(si models.ScenarioIteration) (ScenarioIte
 		body.TriggerConditionAstExpression = &triggerDto
 	}
 
+	var err error
+	body.OutputVariables, err = pure_utils.MapErr(si.OutputVariables, AdaptOutputVariableDto)
+	if err != nil {
+		return ScenarioIterationWithBodyDto{}, err
+	}
+
 	return ScenarioIterationWithBodyDto{
 		ScenarioIterationDto: ScenarioIterationDto{
 			Id:         si.Id,
@@ -73,11 +113,13 @@ func AdaptScenarioIterationWithBodyDto(si models.ScenarioIteration) (ScenarioIte
 // Update iteration DTO
 type UpdateScenarioIterationBody struct {
 	Body struct {
-		TriggerConditionAstExpression *NodeDto `json:"trigger_condition_ast_expression"`
-		ScoreReviewThreshold          *int     `json:"scoreReviewThreshold,omitempty"`
-		ScoreRejectThreshold          *int     `json:"scoreRejectThreshold,omitempty"`
-		Schedule                      *string  `json:"schedule"`
-		BatchTriggerSQL               *string  `json:"batchTriggerSQL"`
+		TriggerConditionAstExpression *NodeDto             `json:"trigger_condition_ast_expression"`
+		ScoreReviewThreshold          *int                 `json:"scoreReviewThreshold,omitempty"`
+		ScoreRejectThreshold          *int                 `json:"scoreRejectThreshold,omitempty"`
+		Schedule                      *string              `json:"schedule"`
+		ScheduleTimezone              *string              `json:"scheduleTimezone"`
+		BatchTriggerSQL               *string              `json:"batchTriggerSQL"`
+		OutputVariables               *[]OutputVariableDto `json:"outputVariables"`
 	} `json:"body,omitempty"`
 }
 
@@ -88,6 +130,7 @@ func AdaptUpdateScenarioIterationInput(input UpdateScenarioIterationBody, iterat
 			ScoreReviewThreshold: input.Body.ScoreReviewThreshold,
 			ScoreRejectThreshold: input.Body.ScoreRejectThreshold,
 			Schedule:             input.Body.Schedule,
+			ScheduleTimezone:     input.Body.ScheduleTimezone,
 			BatchTriggerSQL:      input.Body.BatchTriggerSQL,
 		},
 	}
@@ -103,6 +146,14 @@ func AdaptUpdateScenarioIterationInput(input UpdateScenarioIterationBody, iterat
 		updateScenarioIterationInput.Body.TriggerConditionAstExpression = &trigger
 	}
 
+	if input.Body.OutputVariables != nil {
+		outputVariables, err := pure_utils.MapErr(*input.Body.OutputVariables, AdaptOutputVariable)
+		if err != nil {
+			return models.UpdateScenarioIterationInput{}, err
+		}
+		updateScenarioIterationInput.Body.OutputVariables = &outputVariables
+	}
+
 	return updateScenarioIterationInput, nil
 }
 
@@ -115,7 +166,9 @@ type CreateScenarioIterationBody struct {
 		ScoreReviewThreshold          *int                  `json:"scoreReviewThreshold,omitempty"`
 		ScoreRejectThreshold          *int                  `json:"scoreRejectThreshold,omitempty"`
 		Schedule                      string                `json:"schedule"`
+		ScheduleTimezone              string                `json:"scheduleTimezone"`
 		BatchTriggerSQL               string                `json:"batchTriggerSQL"`
+		OutputVariables               []OutputVariableDto   `json:"outputVariables"`
 	} `json:"body,omitempty"`
 }
 
@@ -130,6 +183,7 @@ func AdaptCreateScenarioIterationInput(input CreateScenarioIterationBody, organi
 			ScoreRejectThreshold: input.Body.ScoreRejectThreshold,
 			BatchTriggerSQL:      input.Body.BatchTriggerSQL,
 			Schedule:             input.Body.Schedule,
+			ScheduleTimezone:     input.Body.ScheduleTimezone,
 			Rules:                make([]models.CreateRuleInput, len(input.Body.Rules)),
 		}
 
@@ -151,6 +205,12 @@ func AdaptCreateScenarioIterationInput(input CreateScenarioIterationBody, organi
 			createScenarioIterationInput.Body.TriggerConditionAstExpression = &trigger
 		}
 
+		outputVariables, err := pure_utils.MapErr(input.Body.OutputVariables, AdaptOutputVariable)
+		if err != nil {
+			return models.CreateScenarioIterationInput{}, err
+		}
+		createScenarioIterationInput.Body.OutputVariables = outputVariables
+
 	}
 	return createScenarioIterationInput, nil
 }
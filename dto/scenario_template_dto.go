@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+type ScenarioTemplatePlaceholderDto struct {
+	Name         string  `json:"name"`
+	Kind         string  `json:"kind"`
+	Description  string  `json:"description"`
+	DefaultValue *string `json:"default_value"`
+}
+
+type ScenarioTemplateDto struct {
+	Id           string                           `json:"id"`
+	Name         string                           `json:"name"`
+	Description  string                           `json:"description"`
+	Placeholders []ScenarioTemplatePlaceholderDto `json:"placeholders"`
+	Bundle       ScenarioBundleDto                `json:"bundle"`
+	CreatedAt    time.Time                        `json:"created_at"`
+	UpdatedAt    time.Time                        `json:"updated_at"`
+}
+
+type CreateScenarioTemplateBody struct {
+	Name         string                           `json:"name"`
+	Description  string                           `json:"description"`
+	Placeholders []ScenarioTemplatePlaceholderDto `json:"placeholders"`
+	Bundle       ScenarioBundleDto                `json:"bundle"`
+}
+
+type UpdateScenarioTemplateBody struct {
+	Name         *string                           `json:"name"`
+	Description  *string                           `json:"description"`
+	Placeholders *[]ScenarioTemplatePlaceholderDto `json:"placeholders"`
+	Bundle       *ScenarioBundleDto                `json:"bundle"`
+}
+
+type InstantiateScenarioTemplateBody struct {
+	ScenarioName string            `json:"scenario_name"`
+	Values       map[string]string `json:"values"`
+	DryRun       bool              `json:"dry_run"`
+}
+
+type ScenarioTemplateIterationValidationDto struct {
+	Version *int     `json:"version"`
+	Errors  []string `json:"errors"`
+}
+
+type ScenarioTemplateInstantiationDto struct {
+	ScenarioImportResultDto
+	Validations []ScenarioTemplateIterationValidationDto `json:"validations"`
+}
+
+func adaptScenarioTemplatePlaceholderDto(placeholder models.ScenarioTemplatePlaceholder) ScenarioTemplatePlaceholderDto {
+	return ScenarioTemplatePlaceholderDto{
+		Name:         placeholder.Name,
+		Kind:         string(placeholder.Kind),
+		Description:  placeholder.Description,
+		DefaultValue: placeholder.DefaultValue,
+	}
+}
+
+func adaptScenarioTemplatePlaceholder(placeholder ScenarioTemplatePlaceholderDto) models.ScenarioTemplatePlaceholder {
+	return models.ScenarioTemplatePlaceholder{
+		Name:         placeholder.Name,
+		Kind:         models.ScenarioTemplatePlaceholderKind(placeholder.Kind),
+		Description:  placeholder.Description,
+		DefaultValue: placeholder.DefaultValue,
+	}
+}
+
+func AdaptScenarioTemplateDto(template models.ScenarioTemplate) (ScenarioTemplateDto, error) {
+	bundle, err := AdaptScenarioBundleDto(template.Bundle)
+	if err != nil {
+		return ScenarioTemplateDto{}, err
+	}
+	return ScenarioTemplateDto{
+		Id:           template.Id,
+		Name:         template.Name,
+		Description:  template.Description,
+		Placeholders: pure_utils.Map(template.Placeholders, adaptScenarioTemplatePlaceholderDto),
+		Bundle:       bundle,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}, nil
+}
+
+func AdaptCreateScenarioTemplateInput(body CreateScenarioTemplateBody) (models.CreateScenarioTemplateInput, error) {
+	bundle, err := AdaptScenarioBundle(body.Bundle)
+	if err != nil {
+		return models.CreateScenarioTemplateInput{}, err
+	}
+	return models.CreateScenarioTemplateInput{
+		Name:         body.Name,
+		Description:  body.Description,
+		Placeholders: pure_utils.Map(body.Placeholders, adaptScenarioTemplatePlaceholder),
+		Bundle:       bundle,
+	}, nil
+}
+
+func AdaptUpdateScenarioTemplateInput(templateId string, body UpdateScenarioTemplateBody) (models.UpdateScenarioTemplateInput, error) {
+	input := models.UpdateScenarioTemplateInput{
+		Id:          templateId,
+		Name:        body.Name,
+		Description: body.Description,
+	}
+	if body.Placeholders != nil {
+		placeholders := pure_utils.Map(*body.Placeholders, adaptScenarioTemplatePlaceholder)
+		input.Placeholders = &placeholders
+	}
+	if body.Bundle != nil {
+		bundle, err := AdaptScenarioBundle(*body.Bundle)
+		if err != nil {
+			return models.UpdateScenarioTemplateInput{}, err
+		}
+		input.Bundle = &bundle
+	}
+	return input, nil
+}
+
+func AdaptScenarioTemplateInstantiationDto(instantiation models.ScenarioTemplateInstantiation) ScenarioTemplateInstantiationDto {
+	return ScenarioTemplateInstantiationDto{
+		ScenarioImportResultDto: AdaptScenarioImportResultDto(instantiation.ScenarioImportResult),
+		Validations: pure_utils.Map(instantiation.Validations,
+			func(validation models.ScenarioTemplateIterationValidation) ScenarioTemplateIterationValidationDto {
+				return ScenarioTemplateIterationValidationDto(validation)
+			}),
+	}
+}
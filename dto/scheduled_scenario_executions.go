@@ -13,6 +13,7 @@ type APIScheduledExecution struct {
 	StartedAt                 time.Time  `json:"started_at"`
 	FinishedAt                *time.Time `json:"finished_at"`
 	NumberOfCreatedDecisions  int        `json:"number_of_created_decisions"`
+	NumberOfEvaluatedObjects  int        `json:"number_of_evaluated_objects"`
 	ScenarioId                string     `json:"scenario_id"`
 	ScenarioName              string     `json:"scenario_name"`
 	ScenarioTriggerObjectType string     `json:"scenario_trigger_object_type"`
@@ -27,6 +28,7 @@ func AdaptScheduledExecutionDto(ExecutionBatch models.ScheduledExecution) APISch
 		StartedAt:                 ExecutionBatch.StartedAt,
 		FinishedAt:                ExecutionBatch.FinishedAt,
 		NumberOfCreatedDecisions:  ExecutionBatch.NumberOfCreatedDecisions,
+		NumberOfEvaluatedObjects:  ExecutionBatch.NumberOfEvaluatedObjects,
 		ScenarioId:                ExecutionBatch.ScenarioId,
 		ScenarioName:              ExecutionBatch.Scenario.Name,
 		ScenarioTriggerObjectType: ExecutionBatch.Scenario.TriggerObjectType,
@@ -0,0 +1,321 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+const (
+	SCIM_SCHEMA_USER          = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIM_SCHEMA_GROUP         = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIM_SCHEMA_LIST_RESPONSE = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIM_SCHEMA_PATCH_OP      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIM_SCHEMA_ERROR         = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIM_SCHEMA_SP_CONFIG     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+type ScimMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+}
+
+type ScimName struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type ScimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type ScimValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type ScimUser struct {
+	Schemas  []string    `json:"schemas"`
+	Id       string      `json:"id"`
+	UserName string      `json:"userName"`
+	Name     ScimName    `json:"name"`
+	Emails   []ScimEmail `json:"emails"`
+	Active   bool        `json:"active"`
+	Roles    []ScimValue `json:"roles"`
+	Groups   []ScimValue `json:"groups"`
+	Meta     ScimMeta    `json:"meta"`
+}
+
+func AdaptScimUser(user models.ScimUser) ScimUser {
+	return ScimUser{
+		Schemas:  []string{SCIM_SCHEMA_USER},
+		Id:       string(user.UserId),
+		UserName: user.Email,
+		Name:     ScimName{GivenName: user.FirstName, FamilyName: user.LastName},
+		Emails:   []ScimEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:   user.Active(),
+		Roles:    []ScimValue{{Value: user.Role.String()}},
+		Groups: pure_utils.Map(user.Groups, func(group models.ScimGroupRef) ScimValue {
+			return ScimValue{Value: group.Id, Display: group.DisplayName}
+		}),
+		Meta: ScimMeta{ResourceType: "User"},
+	}
+}
+
+type ScimUserBody struct {
+	UserName string      `json:"userName"`
+	Name     ScimName    `json:"name"`
+	Emails   []ScimEmail `json:"emails"`
+	// users are active if not specified
+	Active *bool       `json:"active"`
+	Roles  []ScimValue `json:"roles"`
+}
+
+func AdaptScimUserInput(body ScimUserBody) models.ScimUserInput {
+	input := models.ScimUserInput{
+		Email:     body.UserName,
+		FirstName: body.Name.GivenName,
+		LastName:  body.Name.FamilyName,
+		Active:    body.Active == nil || *body.Active,
+	}
+	if input.Email == "" {
+		for _, email := range body.Emails {
+			if email.Primary || input.Email == "" {
+				input.Email = email.Value
+			}
+		}
+	}
+	if len(body.Roles) > 0 {
+		input.Role = models.RoleFromString(body.Roles[0].Value)
+	}
+	return input
+}
+
+type ScimGroup struct {
+	Schemas     []string    `json:"schemas"`
+	Id          string      `json:"id"`
+	DisplayName string      `json:"displayName"`
+	ExternalId  string      `json:"externalId,omitempty"`
+	Members     []ScimValue `json:"members"`
+	Meta        ScimMeta    `json:"meta"`
+}
+
+func AdaptScimGroup(group models.ScimGroup) ScimGroup {
+	return ScimGroup{
+		Schemas:     []string{SCIM_SCHEMA_GROUP},
+		Id:          group.Id,
+		DisplayName: group.DisplayName,
+		ExternalId:  group.ExternalId,
+		Members: pure_utils.Map(group.Members, func(member models.ScimGroupMember) ScimValue {
+			return ScimValue{Value: string(member.UserId), Display: member.Email}
+		}),
+		Meta: ScimMeta{
+			ResourceType: "Group",
+			Created:      &group.CreatedAt,
+			LastModified: &group.UpdatedAt,
+		},
+	}
+}
+
+type ScimGroupBody struct {
+	DisplayName string      `json:"displayName"`
+	ExternalId  string      `json:"externalId"`
+	Members     []ScimValue `json:"members"`
+}
+
+func AdaptScimGroupInput(body ScimGroupBody) models.ScimGroupInput {
+	return models.ScimGroupInput{
+		DisplayName: body.DisplayName,
+		ExternalId:  body.ExternalId,
+		MemberIds:   pure_utils.Map(body.Members, func(member ScimValue) string { return member.Value }),
+	}
+}
+
+type ScimPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+type ScimPatchBody struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []ScimPatchOperation `json:"Operations"`
+}
+
+func AdaptScimPatchOperations(body ScimPatchBody) []models.ScimPatchOperation {
+	return pure_utils.Map(body.Operations, func(operation ScimPatchOperation) models.ScimPatchOperation {
+		return models.ScimPatchOperation{Op: operation.Op, Path: operation.Path, Value: operation.Value}
+	})
+}
+
+type ScimListParams struct {
+	Filter     string `form:"filter"`
+	StartIndex int    `form:"startIndex"`
+	Count      *int   `form:"count"`
+}
+
+func (params ScimListParams) StartIndexOrDefault() int {
+	return max(params.StartIndex, 1)
+}
+
+func (params ScimListParams) CountOrDefault() int {
+	if params.Count == nil {
+		return models.SCIM_MAX_RESULTS
+	}
+	return min(max(*params.Count, 0), models.SCIM_MAX_RESULTS)
+}
+
+type ScimListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+func NewScimListResponse[T any](resources []T, totalResults, startIndex int) ScimListResponse[T] {
+	return ScimListResponse[T]{
+		Schemas:      []string{SCIM_SCHEMA_LIST_RESPONSE},
+		TotalResults: totalResults,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+type ScimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+type ScimSupported struct {
+	Supported bool `json:"supported"`
+}
+
+type ScimFilterSupported struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type ScimBulkSupported struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type ScimAuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ScimServiceProviderConfig struct {
+	Schemas               []string                   `json:"schemas"`
+	Patch                 ScimSupported              `json:"patch"`
+	Bulk                  ScimBulkSupported          `json:"bulk"`
+	Filter                ScimFilterSupported        `json:"filter"`
+	ChangePassword        ScimSupported              `json:"changePassword"`
+	Sort                  ScimSupported              `json:"sort"`
+	Etag                  ScimSupported              `json:"etag"`
+	AuthenticationSchemes []ScimAuthenticationScheme `json:"authenticationSchemes"`
+}
+
+func NewScimServiceProviderConfig() ScimServiceProviderConfig {
+	return ScimServiceProviderConfig{
+		Schemas: []string{SCIM_SCHEMA_SP_CONFIG},
+		Patch:   ScimSupported{Supported: true},
+		Filter:  ScimFilterSupported{Supported: true, MaxResults: models.SCIM_MAX_RESULTS},
+		AuthenticationSchemes: []ScimAuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "SCIM token",
+			Description: "A SCIM token created by an admin of the organization, sent as a bearer token",
+		}},
+	}
+}
+
+// Configuration of the SCIM provisioning by the admins of the organization
+
+type ScimToken struct {
+	Id             string    `json:"id"`
+	OrganizationId string    `json:"organization_id"`
+	Description    string    `json:"description"`
+	Prefix         string    `json:"prefix"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func AdaptScimToken(token models.ScimToken) ScimToken {
+	return ScimToken{
+		Id:             token.Id,
+		OrganizationId: token.OrganizationId,
+		Description:    token.Description,
+		Prefix:         token.Prefix,
+		CreatedAt:      token.CreatedAt,
+	}
+}
+
+type CreatedScimToken struct {
+	ScimToken
+	Token string `json:"token"`
+}
+
+func AdaptCreatedScimToken(token models.CreatedScimToken) CreatedScimToken {
+	return CreatedScimToken{
+		ScimToken: AdaptScimToken(token.ScimToken),
+		Token:     token.Token,
+	}
+}
+
+type CreateScimTokenBody struct {
+	Description string `json:"description"`
+}
+
+type ScimGroupMember struct {
+	UserId string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+type ScimGroupMapping struct {
+	Id          string            `json:"id"`
+	DisplayName string            `json:"display_name"`
+	ExternalId  string            `json:"external_id"`
+	Role        string            `json:"role"`
+	InboxId     *string           `json:"inbox_id"`
+	Members     []ScimGroupMember `json:"members"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+func AdaptScimGroupMapping(group models.ScimGroup) ScimGroupMapping {
+	return ScimGroupMapping{
+		Id:          group.Id,
+		DisplayName: group.DisplayName,
+		ExternalId:  group.ExternalId,
+		Role:        group.Role.String(),
+		InboxId:     group.InboxId,
+		Members: pure_utils.Map(group.Members, func(member models.ScimGroupMember) ScimGroupMember {
+			return ScimGroupMember{UserId: string(member.UserId), Email: member.Email}
+		}),
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+type UpdateScimGroupMappingBody struct {
+	// empty for no role
+	Role    string  `json:"role"`
+	InboxId *string `json:"inbox_id"`
+}
+
+func AdaptUpdateScimGroupMappingInput(groupId string, body UpdateScimGroupMappingBody) models.UpdateScimGroupMappingInput {
+	return models.UpdateScimGroupMappingInput{
+		GroupId: groupId,
+		Role:    models.RoleFromString(body.Role),
+		InboxId: body.InboxId,
+	}
+}
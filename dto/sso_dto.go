@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type SsoProvider struct {
+	Id              string            `json:"id"`
+	OrganizationId  string            `json:"organization_id"`
+	IssuerUrl       string            `json:"issuer_url"`
+	ClientId        string            `json:"client_id"`
+	HasClientSecret bool              `json:"has_client_secret"`
+	Scopes          []string          `json:"scopes"`
+	RoleClaim       string            `json:"role_claim"`
+	RoleMappings    map[string]string `json:"role_mappings"`
+	DefaultRole     string            `json:"default_role"`
+	JitProvisioning bool              `json:"jit_provisioning"`
+	Enabled         bool              `json:"enabled"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+func AdaptSsoProvider(provider models.SsoProvider) SsoProvider {
+	roleMappings := make(map[string]string, len(provider.RoleMappings))
+	for claimValue, role := range provider.RoleMappings {
+		roleMappings[claimValue] = role.String()
+	}
+	return SsoProvider{
+		Id:              provider.Id,
+		OrganizationId:  provider.OrganizationId,
+		IssuerUrl:       provider.IssuerUrl,
+		ClientId:        provider.ClientId,
+		HasClientSecret: provider.ClientSecret != "",
+		Scopes:          provider.ScopesOrDefault(),
+		RoleClaim:       provider.RoleClaim,
+		RoleMappings:    roleMappings,
+		DefaultRole:     provider.DefaultRole.String(),
+		JitProvisioning: provider.JitProvisioning,
+		Enabled:         provider.Enabled,
+		CreatedAt:       provider.CreatedAt,
+		UpdatedAt:       provider.UpdatedAt,
+	}
+}
+
+type UpsertSsoProviderBody struct {
+	IssuerUrl string `json:"issuer_url"`
+	ClientId  string `json:"client_id"`
+	// omitted to keep the current client secret
+	ClientSecret    *string           `json:"client_secret"`
+	Scopes          []string          `json:"scopes"`
+	RoleClaim       string            `json:"role_claim"`
+	RoleMappings    map[string]string `json:"role_mappings"`
+	DefaultRole     string            `json:"default_role"`
+	JitProvisioning bool              `json:"jit_provisioning"`
+	Enabled         bool              `json:"enabled"`
+}
+
+func AdaptUpsertSsoProviderInput(organizationId string, body UpsertSsoProviderBody) models.UpsertSsoProviderInput {
+	roleMappings := make(map[string]models.Role, len(body.RoleMappings))
+	for claimValue, roleName := range body.RoleMappings {
+		roleMappings[claimValue] = models.RoleFromString(roleName)
+	}
+	return models.UpsertSsoProviderInput{
+		OrganizationId:  organizationId,
+		IssuerUrl:       body.IssuerUrl,
+		ClientId:        body.ClientId,
+		ClientSecret:    body.ClientSecret,
+		Scopes:          body.Scopes,
+		RoleClaim:       body.RoleClaim,
+		RoleMappings:    roleMappings,
+		DefaultRole:     models.RoleFromString(body.DefaultRole),
+		JitProvisioning: body.JitProvisioning,
+		Enabled:         body.Enabled,
+	}
+}
+
+type StartSsoLoginParams struct {
+	RedirectUri string `form:"redirect_uri" binding:"required"`
+}
+
+type SsoTokenBody struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/pure_utils"
 )
@@ -72,3 +74,108 @@ type WebhookUpdateBody struct {
 	RateLimit         *int      `json:"rate_limit,omitempty"`
 	RateLimitDuration *int      `json:"rate_limit_duration,omitempty"`
 }
+
+type WebhookEventFilters struct {
+	DeliveryStatus []string  `form:"delivery_status[]"`
+	EventTypes     []string  `form:"event_type[]"`
+	StartDate      time.Time `form:"start_date"`
+	EndDate        time.Time `form:"end_date"`
+	Limit          uint64    `form:"limit"`
+}
+
+func AdaptWebhookEventFilters(filters WebhookEventFilters) models.WebhookEventFilters {
+	return models.WebhookEventFilters{
+		DeliveryStatus: pure_utils.Map(filters.DeliveryStatus, func(s string) models.WebhookEventDeliveryStatus {
+			return models.WebhookEventDeliveryStatus(s)
+		}),
+		EventTypes: pure_utils.Map(filters.EventTypes, func(s string) models.WebhookEventType {
+			return models.WebhookEventType(s)
+		}),
+		StartDate: filters.StartDate,
+		EndDate:   filters.EndDate,
+		Limit:     filters.Limit,
+	}
+}
+
+type WebhookEvent struct {
+	Id             string         `json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	EventType      string         `json:"event_type"`
+	DeliveryStatus string         `json:"delivery_status"`
+	RetryCount     int            `json:"retry_count"`
+	ReplayCount    int            `json:"replay_count"`
+	Data           map[string]any `json:"data"`
+}
+
+func AdaptWebhookEvent(webhookEvent models.WebhookEvent) WebhookEvent {
+	return WebhookEvent{
+		Id:             webhookEvent.Id,
+		CreatedAt:      webhookEvent.CreatedAt,
+		UpdatedAt:      webhookEvent.UpdatedAt,
+		EventType:      string(webhookEvent.EventContent.Type),
+		DeliveryStatus: string(webhookEvent.DeliveryStatus),
+		RetryCount:     webhookEvent.RetryCount,
+		ReplayCount:    webhookEvent.ReplayCount,
+		Data:           webhookEvent.EventContent.Data,
+	}
+}
+
+type WebhookDeliveryAttempt struct {
+	Id              string    `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	StatusCode      *int      `json:"status_code"`
+	LatencyMs       int64     `json:"latency_ms"`
+	ResponseExcerpt string    `json:"response_excerpt"`
+	Error           string    `json:"error,omitempty"`
+}
+
+func AdaptWebhookDeliveryAttempt(attempt models.WebhookDeliveryAttempt) WebhookDeliveryAttempt {
+	return WebhookDeliveryAttempt{
+		Id:              attempt.Id,
+		CreatedAt:       attempt.CreatedAt,
+		StatusCode:      attempt.StatusCode,
+		LatencyMs:       attempt.Latency.Milliseconds(),
+		ResponseExcerpt: attempt.ResponseExcerpt,
+		Error:           attempt.Error,
+	}
+}
+
+type WebhookDelivery struct {
+	Id               string                   `json:"id"`
+	WebhookId        string                   `json:"webhook_id"`
+	Status           string                   `json:"status"`
+	Attempts         int                      `json:"attempts"`
+	CreatedAt        time.Time                `json:"created_at"`
+	UpdatedAt        time.Time                `json:"updated_at"`
+	DeliveryAttempts []WebhookDeliveryAttempt `json:"delivery_attempts"`
+}
+
+func AdaptWebhookDelivery(delivery models.WebhookDeliveryWithAttempts) WebhookDelivery {
+	return WebhookDelivery{
+		Id:               delivery.Id,
+		WebhookId:        delivery.WebhookId,
+		Status:           string(delivery.Status),
+		Attempts:         delivery.Attempts,
+		CreatedAt:        delivery.CreatedAt,
+		UpdatedAt:        delivery.UpdatedAt,
+		DeliveryAttempts: pure_utils.Map(delivery.DeliveryAttempts, AdaptWebhookDeliveryAttempt),
+	}
+}
+
+type WebhookEventWithDeliveries struct {
+	WebhookEvent
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+func AdaptWebhookEventWithDeliveries(webhookEvent models.WebhookEventWithDeliveries) WebhookEventWithDeliveries {
+	return WebhookEventWithDeliveries{
+		WebhookEvent: AdaptWebhookEvent(webhookEvent.WebhookEvent),
+		Deliveries:   pure_utils.Map(webhookEvent.Deliveries, AdaptWebhookDelivery),
+	}
+}
+
+type WebhookEventsReplayBody struct {
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+}
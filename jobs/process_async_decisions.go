@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/checkmarble/marble-backend/usecases"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const asyncDecisionsPollInterval = 1 * time.Second
+
+// RunAsyncDecisionWorkers takes the asynchronous decisions of the job queue until the context is cancelled, with at
+// most "concurrency" decisions evaluated at the same time by this process. It runs in the API server, not in the
+// cron scheduler, so that the decisions are taken within seconds of their creation.
+func RunAsyncDecisionWorkers(ctx context.Context, uc usecases.Usecases, concurrency int) {
+	logger := utils.LoggerFromContext(ctx).With("job", "process_async_decisions")
+	ctx = utils.StoreLoggerInContext(ctx, logger)
+	logger.InfoContext(ctx, "starting async decision workers", slog.Int("concurrency", concurrency))
+
+	ticker := time.NewTicker(asyncDecisionsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			usecasesWithCreds := GenerateUsecaseWithCredForMarbleAdmin(ctx, uc)
+			decisionUsecase := usecasesWithCreds.NewDecisionUsecase()
+			if err := decisionUsecase.ProcessAsyncDecisions(ctx, concurrency); err != nil {
+				utils.LogAndReportSentryError(ctx, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/checkmarble/marble-backend/usecases"
+)
+
+// Runs every hour
+func PurgeExpiredIdempotencyKeys(ctx context.Context, uc usecases.Usecases) error {
+	return executeWithMonitoring(
+		ctx,
+		uc,
+		"purge-expired-idempotency-keys",
+		func(
+			ctx context.Context, usecases usecases.Usecases,
+		) error {
+			usecasesWithCreds := GenerateUsecaseWithCredForMarbleAdmin(ctx, usecases)
+			decisionUsecase := usecasesWithCreds.NewDecisionUsecase()
+			return decisionUsecase.PurgeExpiredIdempotencyKeys(ctx)
+		},
+	)
+}
@@ -15,10 +15,12 @@ func errToReturnCode(err error) int {
 	return 0
 }
 
-func RunScheduler(ctx context.Context, usecases usecases.Usecases) {
+// RunScheduler runs the cron jobs. Several instances of the scheduler can run at the same time: scheduled executions,
+// CSV ingestions and webhook events are dispatched between them through the job queue.
+func RunScheduler(ctx context.Context, usecases usecases.Usecases, timezone string) {
 	taskr := tasker.New(tasker.Option{
 		Verbose: true,
-		Tz:      "Europe/Paris",
+		Tz:      timezone,
 	}).WithContext(ctx)
 
 	notConcurrent := false
@@ -50,5 +52,12 @@ func RunScheduler(ctx context.Context, usecases usecases.Usecases) {
 		return errToReturnCode(err), err
 	})
 
+	taskr.Task("0 * * * *", func(ctx context.Context) (int, error) {
+		logger := utils.LoggerFromContext(ctx).With("job", "purge_expired_idempotency_keys")
+		ctx = utils.StoreLoggerInContext(ctx, logger)
+		err := PurgeExpiredIdempotencyKeys(ctx, usecases)
+		return errToReturnCode(err), err
+	})
+
 	taskr.Run()
 }
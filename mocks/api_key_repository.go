@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -32,3 +33,8 @@ func (r *ApiKeyRepository) SoftDeleteApiKey(ctx context.Context, exec repositori
 	args := r.Called(exec, apiKeyId)
 	return args.Error(0)
 }
+
+func (r *ApiKeyRepository) ExpireApiKey(ctx context.Context, exec repositories.Executor, apiKeyId string, expiresAt time.Time) error {
+	args := r.Called(exec, apiKeyId, expiresAt)
+	return args.Error(0)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type AsyncDecisionRepository struct {
+	mock.Mock
+}
+
+func (r *AsyncDecisionRepository) CreateAsyncDecision(ctx context.Context, exec repositories.Executor, id string,
+	input models.CreateAsyncDecisionInput,
+) error {
+	args := r.Called(exec, id, input)
+	return args.Error(0)
+}
+
+func (r *AsyncDecisionRepository) GetAsyncDecision(ctx context.Context, exec repositories.Executor,
+	id string,
+) (models.AsyncDecision, error) {
+	args := r.Called(exec, id)
+	return args.Get(0).(models.AsyncDecision), args.Error(1)
+}
+
+func (r *AsyncDecisionRepository) UpdateAsyncDecisionStatus(ctx context.Context, exec repositories.Executor,
+	id string, status models.AsyncDecisionStatus, asyncDecisionError *string,
+) error {
+	args := r.Called(exec, id, status, asyncDecisionError)
+	return args.Error(0)
+}
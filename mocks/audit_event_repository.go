@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type AuditEventRepository struct {
+	mock.Mock
+}
+
+func (r *AuditEventRepository) ListAuditEvents(ctx context.Context, exec repositories.Executor, organizationId string,
+	filters models.AuditEventFilters, pagination models.PaginationAndSorting,
+) ([]models.AuditEvent, error) {
+	args := r.Called(exec, organizationId, filters, pagination)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
@@ -59,9 +59,8 @@ func (cl *CustomListRepository) AddCustomListValue(
 	exec repositories.Executor,
 	addCustomListValue models.AddCustomListValueInput,
 	newCustomListId string,
-	userId *models.UserId,
 ) error {
-	args := cl.Called(ctx, exec, addCustomListValue, userId)
+	args := cl.Called(ctx, exec, addCustomListValue)
 	return args.Error(0)
 }
 
@@ -69,8 +68,7 @@ func (cl *CustomListRepository) DeleteCustomListValue(
 	ctx context.Context,
 	exec repositories.Executor,
 	deleteCustomListValue models.DeleteCustomListValueInput,
-	userId *models.UserId,
 ) error {
-	args := cl.Called(ctx, exec, deleteCustomListValue, userId)
+	args := cl.Called(ctx, exec, deleteCustomListValue)
 	return args.Error(0)
 }
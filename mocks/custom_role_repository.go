@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type CustomRoleRepository struct {
+	mock.Mock
+}
+
+func (r *CustomRoleRepository) GetCustomRoleById(ctx context.Context, exec repositories.Executor,
+	customRoleId string,
+) (models.CustomRole, error) {
+	args := r.Called(exec, customRoleId)
+	return args.Get(0).(models.CustomRole), args.Error(1)
+}
+
+func (r *CustomRoleRepository) ListCustomRoles(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.CustomRole, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.CustomRole), args.Error(1)
+}
+
+func (r *CustomRoleRepository) CreateCustomRole(ctx context.Context, exec repositories.Executor,
+	input models.CreateCustomRoleInput, newCustomRoleId string,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *CustomRoleRepository) UpdateCustomRole(ctx context.Context, exec repositories.Executor,
+	input models.UpdateCustomRoleInput,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *CustomRoleRepository) DeleteCustomRole(ctx context.Context, exec repositories.Executor, customRoleId string) error {
+	args := r.Called(exec, customRoleId)
+	return args.Error(0)
+}
+
+func (r *CustomRoleRepository) CountCustomRoleAssignments(ctx context.Context, exec repositories.Executor,
+	customRoleId string,
+) (int, error) {
+	args := r.Called(exec, customRoleId)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *CustomRoleRepository) SetUserCustomRole(ctx context.Context, exec repositories.Executor,
+	userId models.UserId, customRoleId *string,
+) error {
+	args := r.Called(exec, userId, customRoleId)
+	return args.Error(0)
+}
+
+func (r *CustomRoleRepository) SetApiKeyCustomRole(ctx context.Context, exec repositories.Executor,
+	apiKeyId string, customRoleId *string,
+) error {
+	args := r.Called(exec, apiKeyId, customRoleId)
+	return args.Error(0)
+}
+
+func (r *CustomRoleRepository) GetApiKeyById(ctx context.Context, exec repositories.Executor,
+	apiKeyId string,
+) (models.ApiKey, error) {
+	args := r.Called(exec, apiKeyId)
+	return args.Get(0).(models.ApiKey), args.Error(1)
+}
@@ -28,6 +28,21 @@ func (d *DataModelRepository) DeleteDataModel(ctx context.Context, exec reposito
 	return args.Error(0)
 }
 
+func (d *DataModelRepository) DeleteDataModelTable(ctx context.Context, exec repositories.Executor, tableID string) error {
+	args := d.Called(ctx, exec, tableID)
+	return args.Error(0)
+}
+
+func (d *DataModelRepository) DeleteDataModelField(ctx context.Context, exec repositories.Executor, fieldID string) error {
+	args := d.Called(ctx, exec, fieldID)
+	return args.Error(0)
+}
+
+func (d *DataModelRepository) DeleteDataModelLink(ctx context.Context, exec repositories.Executor, linkID string) error {
+	args := d.Called(ctx, exec, linkID)
+	return args.Error(0)
+}
+
 func (d *DataModelRepository) CreateDataModelTable(ctx context.Context, exec repositories.Executor, organizationID, tableID, name, description string) error {
 	args := d.Called(ctx, exec, organizationID, tableID, name, description)
 	return args.Error(0)
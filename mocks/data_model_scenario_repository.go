@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type DataModelScenarioRepository struct {
+	mock.Mock
+}
+
+func (m *DataModelScenarioRepository) ListScenariosOfOrganization(ctx context.Context,
+	exec repositories.Executor, organizationId string,
+) ([]models.Scenario, error) {
+	args := m.Called(ctx, exec, organizationId)
+	return args.Get(0).([]models.Scenario), args.Error(1)
+}
+
+func (m *DataModelScenarioRepository) ListScenarioIterations(ctx context.Context, exec repositories.Executor,
+	organizationId string, filters models.GetScenarioIterationFilters,
+) ([]models.ScenarioIteration, error) {
+	args := m.Called(ctx, exec, organizationId, filters)
+	return args.Get(0).([]models.ScenarioIteration), args.Error(1)
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type DecisionPolicyRepository struct {
+	mock.Mock
+}
+
+func (r *DecisionPolicyRepository) GetDecisionPolicy(ctx context.Context, exec repositories.Executor,
+	id string,
+) (models.DecisionPolicy, error) {
+	args := r.Called(exec, id)
+	return args.Get(0).(models.DecisionPolicy), args.Error(1)
+}
+
+func (r *DecisionPolicyRepository) GetDecisionPolicyOfTriggerObjectType(ctx context.Context,
+	exec repositories.Executor, organizationId string, triggerObjectType string,
+) (models.DecisionPolicy, error) {
+	args := r.Called(exec, organizationId, triggerObjectType)
+	return args.Get(0).(models.DecisionPolicy), args.Error(1)
+}
+
+func (r *DecisionPolicyRepository) ListDecisionPolicies(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.DecisionPolicy, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.DecisionPolicy), args.Error(1)
+}
+
+func (r *DecisionPolicyRepository) CreateDecisionPolicy(ctx context.Context, exec repositories.Executor,
+	id string, input models.CreateDecisionPolicyInput,
+) error {
+	args := r.Called(exec, id, input)
+	return args.Error(0)
+}
+
+func (r *DecisionPolicyRepository) UpdateDecisionPolicy(ctx context.Context, exec repositories.Executor,
+	policy models.DecisionPolicy,
+) error {
+	args := r.Called(exec, policy)
+	return args.Error(0)
+}
+
+func (r *DecisionPolicyRepository) DeleteDecisionPolicy(ctx context.Context, exec repositories.Executor, id string) error {
+	args := r.Called(exec, id)
+	return args.Error(0)
+}
+
+func (r *DecisionPolicyRepository) GetScenarioById(ctx context.Context, exec repositories.Executor,
+	scenarioId string,
+) (models.Scenario, error) {
+	args := r.Called(exec, scenarioId)
+	return args.Get(0).(models.Scenario), args.Error(1)
+}
+
+func (r *DecisionPolicyRepository) StorePolicyDecision(ctx context.Context, exec repositories.Executor,
+	policyDecision models.PolicyDecision,
+) error {
+	args := r.Called(exec, policyDecision)
+	return args.Error(0)
+}
+
+func (r *DecisionPolicyRepository) GetPolicyDecision(ctx context.Context, exec repositories.Executor,
+	id string,
+) (models.PolicyDecision, error) {
+	args := r.Called(exec, id)
+	return args.Get(0).(models.PolicyDecision), args.Error(1)
+}
@@ -0,0 +1,123 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type DecisionRepository struct {
+	mock.Mock
+}
+
+func (r *DecisionRepository) DecisionWithRuleExecutionsById(ctx context.Context, exec repositories.Executor,
+	decisionId string,
+) (models.DecisionWithRuleExecutions, error) {
+	args := r.Called(exec, decisionId)
+	return args.Get(0).(models.DecisionWithRuleExecutions), args.Error(1)
+}
+
+func (r *DecisionRepository) DecisionsWithRuleExecutionsByIds(ctx context.Context, exec repositories.Executor,
+	decisionIds []string,
+) ([]models.DecisionWithRuleExecutions, error) {
+	args := r.Called(exec, decisionIds)
+	return args.Get(0).([]models.DecisionWithRuleExecutions), args.Error(1)
+}
+
+func (r *DecisionRepository) DecisionsById(ctx context.Context, exec repositories.Executor,
+	decisionIds []string,
+) ([]models.Decision, error) {
+	args := r.Called(exec, decisionIds)
+	return args.Get(0).([]models.Decision), args.Error(1)
+}
+
+func (r *DecisionRepository) DecisionsByCaseId(ctx context.Context, exec repositories.Executor,
+	organizationId, caseId string,
+) ([]models.DecisionWithRuleExecutions, error) {
+	args := r.Called(exec, organizationId, caseId)
+	return args.Get(0).([]models.DecisionWithRuleExecutions), args.Error(1)
+}
+
+func (r *DecisionRepository) DecisionsByObjectId(ctx context.Context, exec repositories.Executor,
+	organizationId string, objectId string,
+) ([]models.DecisionCore, error) {
+	args := r.Called(exec, organizationId, objectId)
+	return args.Get(0).([]models.DecisionCore), args.Error(1)
+}
+
+func (r *DecisionRepository) DecisionsOfScheduledExecution(ctx context.Context, exec repositories.Executor,
+	organizationId string, scheduledExecutionId string,
+) (<-chan models.DecisionWithRuleExecutions, <-chan error) {
+	args := r.Called(exec, organizationId, scheduledExecutionId)
+	return args.Get(0).(<-chan models.DecisionWithRuleExecutions), args.Get(1).(<-chan error)
+}
+
+func (r *DecisionRepository) StoreDecision(ctx context.Context, exec repositories.Executor,
+	decision models.DecisionWithRuleExecutions, organizationId string, newDecisionId string,
+) error {
+	args := r.Called(exec, decision, organizationId, newDecisionId)
+	return args.Error(0)
+}
+
+func (r *DecisionRepository) DecisionsOfOrganization(ctx context.Context, exec repositories.Executor,
+	organizationId string, paginationAndSorting models.PaginationAndSorting, filters models.DecisionFilters,
+) ([]models.DecisionWithRank, error) {
+	args := r.Called(exec, organizationId, paginationAndSorting, filters)
+	return args.Get(0).([]models.DecisionWithRank), args.Error(1)
+}
+
+func (r *DecisionRepository) UpdateDecisionCaseId(ctx context.Context, exec repositories.Executor,
+	decisionsIds []string, caseId string,
+) error {
+	args := r.Called(exec, decisionsIds, caseId)
+	return args.Error(0)
+}
+
+func (r *DecisionRepository) ReviewDecision(ctx context.Context, exec repositories.Executor,
+	decisionId string, review models.DecisionReview,
+) error {
+	args := r.Called(exec, decisionId, review)
+	return args.Error(0)
+}
+
+func (r *DecisionRepository) LabelDecisions(ctx context.Context, exec repositories.Executor,
+	decisionIds []string, review models.DecisionReview,
+) ([]string, error) {
+	args := r.Called(exec, decisionIds, review)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type DecisionUsecaseRepository struct {
+	mock.Mock
+}
+
+func (r *DecisionUsecaseRepository) GetScenarioById(ctx context.Context, exec repositories.Executor,
+	scenarioId string,
+) (models.Scenario, error) {
+	args := r.Called(exec, scenarioId)
+	return args.Get(0).(models.Scenario), args.Error(1)
+}
+
+func (r *DecisionUsecaseRepository) ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.Scenario, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.Scenario), args.Error(1)
+}
+
+func (r *DecisionUsecaseRepository) GetScenarioIteration(ctx context.Context, exec repositories.Executor,
+	scenarioIterationId string,
+) (models.ScenarioIteration, error) {
+	args := r.Called(exec, scenarioIterationId)
+	return args.Get(0).(models.ScenarioIteration), args.Error(1)
+}
+
+func (r *DecisionUsecaseRepository) GetCaseById(ctx context.Context, exec repositories.Executor,
+	caseId string,
+) (models.Case, error) {
+	args := r.Called(exec, caseId)
+	return args.Get(0).(models.Case), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type DecisionWorkflows struct {
+	mock.Mock
+}
+
+func (m *DecisionWorkflows) AutomaticDecisionToCase(ctx context.Context, tx repositories.Executor,
+	scenario models.Scenario, decision models.DecisionWithRuleExecutions, webhookEventId string,
+) (bool, error) {
+	args := m.Called(tx, scenario, decision, webhookEventId)
+	return args.Bool(0), args.Error(1)
+}
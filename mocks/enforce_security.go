@@ -40,6 +40,21 @@ func (e *EnforceSecurity) CreateScheduledExecution(organizationId string) error
 	return args.Error(0)
 }
 
+func (e *EnforceSecurity) ReadPolicyDecision(policyDecision models.PolicyDecision) error {
+	args := e.Called(policyDecision)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReadAsyncDecision(asyncDecision models.AsyncDecision) error {
+	args := e.Called(asyncDecision)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReviewDecision(decision models.Decision) error {
+	args := e.Called(decision)
+	return args.Error(0)
+}
+
 func (e *EnforceSecurity) ReadScenario(scenario models.Scenario) error {
 	args := e.Called(scenario)
 	return args.Error(0)
@@ -75,11 +90,26 @@ func (e *EnforceSecurity) CreateScenario(organizationId string) error {
 	return args.Error(0)
 }
 
+func (e *EnforceSecurity) ReadScenarioTemplates() error {
+	args := e.Called()
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ManageScenarioTemplates() error {
+	args := e.Called()
+	return args.Error(0)
+}
+
 func (e *EnforceSecurity) CreateRule(scenarioIteration models.ScenarioIteration) error {
 	args := e.Called(scenarioIteration)
 	return args.Error(0)
 }
 
+func (e *EnforceSecurity) DryRunScenarioIteration(scenarioIteration models.ScenarioIteration) error {
+	args := e.Called(scenarioIteration)
+	return args.Error(0)
+}
+
 func (e *EnforceSecurity) ReadInbox(i models.Inbox) error {
 	args := e.Called(i)
 	return args.Error(0)
@@ -146,3 +176,88 @@ func (e *EnforceSecurity) WriteDataModel(organizationId string) error {
 	args := e.Called(organizationId)
 	return args.Error(0)
 }
+
+func (e *EnforceSecurity) ManageSsoProvider(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ManageScim(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ManageCustomRoles(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReadRateLimits(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ManageRateLimits(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReadAuditEvents(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ExportOrganizationConfig(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ImportOrganizationConfig(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) DecideOnScenario(scenario models.Scenario) error {
+	args := e.Called(scenario)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) DecideOnTriggerObject(triggerObjectType string) error {
+	args := e.Called(triggerObjectType)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) IngestInTable(tableName string) error {
+	args := e.Called(tableName)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReadDecisionPolicy(policy models.DecisionPolicy) error {
+	args := e.Called(policy)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ListDecisionPolicies(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) WriteDecisionPolicy(organizationId string) error {
+	args := e.Called(organizationId)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ReadCustomList(customList models.CustomList) error {
+	args := e.Called(customList)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) ModifyCustomList(customList models.CustomList) error {
+	args := e.Called(customList)
+	return args.Error(0)
+}
+
+func (e *EnforceSecurity) CreateCustomList() error {
+	args := e.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type IdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (r *IdempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, exec repositories.Executor,
+	organizationId string, scope models.IdempotencyKeyScope, key string,
+) (*models.IdempotencyKey, error) {
+	args := r.Called(exec, organizationId, scope, key)
+	return args.Get(0).(*models.IdempotencyKey), args.Error(1)
+}
+
+func (r *IdempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, exec repositories.Executor,
+	input models.IdempotencyKeyCreate,
+) (bool, error) {
+	args := r.Called(exec, input)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *IdempotencyKeyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, exec repositories.Executor) error {
+	args := r.Called(exec)
+	return args.Error(0)
+}
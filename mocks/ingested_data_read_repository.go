@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type IngestedDataReadRepository struct {
+	mock.Mock
+}
+
+func (r *IngestedDataReadRepository) GetDbField(ctx context.Context, exec repositories.Executor,
+	readParams models.DbFieldReadParams,
+) (any, error) {
+	args := r.Called(ctx, exec, readParams)
+	return args.Get(0), args.Error(1)
+}
+
+func (r *IngestedDataReadRepository) ListObjectsFromTableBatch(
+	ctx context.Context,
+	exec repositories.Executor,
+	table models.Table,
+	afterObjectId *string,
+	limit int,
+) ([]models.ClientObject, error) {
+	args := r.Called(ctx, exec, table, afterObjectId, limit)
+	return args.Get(0).([]models.ClientObject), args.Error(1)
+}
+
+func (r *IngestedDataReadRepository) QueryIngestedObject(
+	ctx context.Context,
+	exec repositories.Executor,
+	table models.Table,
+	objectId string,
+) ([]map[string]any, error) {
+	args := r.Called(ctx, exec, table, objectId)
+	return args.Get(0).([]map[string]any), args.Error(1)
+}
+
+func (r *IngestedDataReadRepository) QueryAggregatedValue(
+	ctx context.Context,
+	exec repositories.Executor,
+	tableName string,
+	fieldName string,
+	fieldType models.DataType,
+	aggregator ast.Aggregator,
+	filters []ast.Filter,
+) (any, error) {
+	args := r.Called(ctx, exec, tableName, fieldName, fieldType, aggregator, filters)
+	return args.Get(0), args.Error(1)
+}
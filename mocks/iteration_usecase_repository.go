@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type IterationUsecaseRepository struct {
+	mock.Mock
+}
+
+func (r *IterationUsecaseRepository) GetScenarioIteration(ctx context.Context, exec repositories.Executor,
+	scenarioIterationId string,
+) (models.ScenarioIteration, error) {
+	args := r.Called(exec, scenarioIterationId)
+	return args.Get(0).(models.ScenarioIteration), args.Error(1)
+}
+
+func (r *IterationUsecaseRepository) ListScenarioIterations(ctx context.Context, exec repositories.Executor,
+	organizationId string, filters models.GetScenarioIterationFilters,
+) ([]models.ScenarioIteration, error) {
+	args := r.Called(exec, organizationId, filters)
+	return args.Get(0).([]models.ScenarioIteration), args.Error(1)
+}
+
+func (r *IterationUsecaseRepository) CreateScenarioIterationAndRules(ctx context.Context,
+	exec repositories.Executor, organizationId string, scenarioIteration models.CreateScenarioIterationInput,
+) (models.ScenarioIteration, error) {
+	args := r.Called(exec, organizationId, scenarioIteration)
+	return args.Get(0).(models.ScenarioIteration), args.Error(1)
+}
+
+func (r *IterationUsecaseRepository) UpdateScenarioIteration(ctx context.Context, exec repositories.Executor,
+	scenarioIteration models.UpdateScenarioIterationInput,
+) (models.ScenarioIteration, error) {
+	args := r.Called(exec, scenarioIteration)
+	return args.Get(0).(models.ScenarioIteration), args.Error(1)
+}
+
+func (r *IterationUsecaseRepository) UpdateScenarioIterationVersion(ctx context.Context,
+	exec repositories.Executor, scenarioIterationId string, newVersion int,
+) error {
+	args := r.Called(exec, scenarioIterationId, newVersion)
+	return args.Error(0)
+}
+
+func (r *IterationUsecaseRepository) DeleteScenarioIteration(ctx context.Context, exec repositories.Executor,
+	scenarioIterationId string,
+) error {
+	args := r.Called(exec, scenarioIterationId)
+	return args.Error(0)
+}
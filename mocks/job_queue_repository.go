@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type JobQueueRepository struct {
+	mock.Mock
+}
+
+func (r *JobQueueRepository) ClaimJobs(ctx context.Context, exec repositories.Executor,
+	input models.JobClaimInput,
+) ([]models.Job, error) {
+	args := r.Called(exec, input)
+	return args.Get(0).([]models.Job), args.Error(1)
+}
+
+func (r *JobQueueRepository) HeartbeatJob(ctx context.Context, exec repositories.Executor, jobId string,
+	workerId string, leaseDuration time.Duration,
+) error {
+	args := r.Called(exec, jobId, workerId, leaseDuration)
+	return args.Error(0)
+}
+
+func (r *JobQueueRepository) CompleteJob(ctx context.Context, exec repositories.Executor, jobId string, workerId string) error {
+	args := r.Called(exec, jobId, workerId)
+	return args.Error(0)
+}
+
+func (r *JobQueueRepository) FailJob(ctx context.Context, exec repositories.Executor, jobId string,
+	workerId string, jobError string, retryAt *time.Time,
+) error {
+	args := r.Called(exec, jobId, workerId, jobError, retryAt)
+	return args.Error(0)
+}
+
+func (r *JobQueueRepository) PostponeJob(ctx context.Context, exec repositories.Executor, jobId string,
+	workerId string, runAt time.Time,
+) error {
+	args := r.Called(exec, jobId, workerId, runAt)
+	return args.Error(0)
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type OidcClient struct {
+	mock.Mock
+}
+
+func (c *OidcClient) AuthorizationUrl(ctx context.Context, provider models.SsoProvider, state models.SsoLoginState) (string, error) {
+	args := c.Called(provider, state)
+	return args.String(0), args.Error(1)
+}
+
+func (c *OidcClient) ExchangeCode(ctx context.Context, provider models.SsoProvider, state models.SsoLoginState, code string) (models.SsoIdentity, error) {
+	args := c.Called(provider, state, code)
+	return args.Get(0).(models.SsoIdentity), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type OrganizationConfigRepository struct {
+	mock.Mock
+}
+
+func (r *OrganizationConfigRepository) ListInboxes(ctx context.Context, exec repositories.Executor,
+	organizationId string, inboxIds []string, withCaseCount bool,
+) ([]models.Inbox, error) {
+	args := r.Called(exec, organizationId, inboxIds, withCaseCount)
+	return args.Get(0).([]models.Inbox), args.Error(1)
+}
+
+func (r *OrganizationConfigRepository) ListOrganizationTags(ctx context.Context, exec repositories.Executor,
+	organizationId string, withCaseCount bool,
+) ([]models.Tag, error) {
+	args := r.Called(exec, organizationId, withCaseCount)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (r *OrganizationConfigRepository) ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.Scenario, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.Scenario), args.Error(1)
+}
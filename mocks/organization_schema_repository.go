@@ -36,3 +36,27 @@ func (m *OrganizationSchemaRepository) CreateField(
 	args := m.Called(ctx, exec, tableName, field)
 	return args.Error(0)
 }
+
+func (m *OrganizationSchemaRepository) DeleteTable(ctx context.Context, exec repositories.Executor, tableName string) error {
+	args := m.Called(ctx, exec, tableName)
+	return args.Error(0)
+}
+
+func (m *OrganizationSchemaRepository) ArchiveTable(ctx context.Context, exec repositories.Executor, tableName, archivedName string) error {
+	args := m.Called(ctx, exec, tableName, archivedName)
+	return args.Error(0)
+}
+
+func (m *OrganizationSchemaRepository) DeleteField(ctx context.Context, exec repositories.Executor, tableName, fieldName string) error {
+	args := m.Called(ctx, exec, tableName, fieldName)
+	return args.Error(0)
+}
+
+func (m *OrganizationSchemaRepository) ArchiveField(
+	ctx context.Context,
+	exec repositories.Executor,
+	tableName, fieldName, archivedName string,
+) error {
+	args := m.Called(ctx, exec, tableName, fieldName, archivedName)
+	return args.Error(0)
+}
@@ -26,3 +26,18 @@ func (m *Database) GetApiKeyByHash(ctx context.Context, hash []byte) (models.Api
 	args := m.Called(ctx, hash)
 	return args.Get(0).(models.ApiKey), args.Error(1)
 }
+
+func (m *Database) GetCustomRoleById(ctx context.Context, customRoleId string) (models.CustomRole, error) {
+	args := m.Called(ctx, customRoleId)
+	return args.Get(0).(models.CustomRole), args.Error(1)
+}
+
+func (m *Database) GetApiKeyById(ctx context.Context, apiKeyId string) (models.ApiKey, error) {
+	args := m.Called(ctx, apiKeyId)
+	return args.Get(0).(models.ApiKey), args.Error(1)
+}
+
+func (m *Database) RecordApiKeyUsages(ctx context.Context, usages map[string]models.ApiKeyUsage) error {
+	args := m.Called(ctx, usages)
+	return args.Error(0)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type RateLimitRepository struct {
+	mock.Mock
+}
+
+func (r *RateLimitRepository) ListRateLimitSettings(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.RateLimitSettings, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.RateLimitSettings), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type RulePerformanceRepository struct {
+	mock.Mock
+}
+
+func (r *RulePerformanceRepository) GetScenarioById(ctx context.Context, exec repositories.Executor,
+	scenarioId string,
+) (models.Scenario, error) {
+	args := r.Called(exec, scenarioId)
+	return args.Get(0).(models.Scenario), args.Error(1)
+}
+
+func (r *RulePerformanceRepository) CountDecisionLabels(ctx context.Context, exec repositories.Executor,
+	input models.RulePerformanceReportInput,
+) ([]models.DecisionLabelCount, error) {
+	args := r.Called(exec, input)
+	return args.Get(0).([]models.DecisionLabelCount), args.Error(1)
+}
+
+func (r *RulePerformanceRepository) CountRuleExecutionLabels(ctx context.Context, exec repositories.Executor,
+	input models.RulePerformanceReportInput,
+) ([]models.RuleExecutionLabelCount, error) {
+	args := r.Called(exec, input)
+	return args.Get(0).([]models.RuleExecutionLabelCount), args.Error(1)
+}
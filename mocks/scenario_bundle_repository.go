@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type ScenarioBundleRepository struct {
+	mock.Mock
+}
+
+func (r *ScenarioBundleRepository) GetScenarioById(ctx context.Context, exec repositories.Executor,
+	scenarioId string,
+) (models.Scenario, error) {
+	args := r.Called(exec, scenarioId)
+	return args.Get(0).(models.Scenario), args.Error(1)
+}
+
+func (r *ScenarioBundleRepository) ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor,
+	organizationId string,
+) ([]models.Scenario, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.Scenario), args.Error(1)
+}
+
+func (r *ScenarioBundleRepository) CreateScenario(ctx context.Context, exec repositories.Executor,
+	organizationId string, scenario models.CreateScenarioInput, newScenarioId string,
+) error {
+	args := r.Called(exec, organizationId, scenario, newScenarioId)
+	return args.Error(0)
+}
+
+func (r *ScenarioBundleRepository) ListScenarioIterations(ctx context.Context, exec repositories.Executor,
+	organizationId string, filters models.GetScenarioIterationFilters,
+) ([]models.ScenarioIteration, error) {
+	args := r.Called(exec, organizationId, filters)
+	return args.Get(0).([]models.ScenarioIteration), args.Error(1)
+}
+
+func (r *ScenarioBundleRepository) CreateScenarioIterationAndRules(ctx context.Context,
+	exec repositories.Executor, organizationId string, scenarioIteration models.CreateScenarioIterationInput,
+) (models.ScenarioIteration, error) {
+	args := r.Called(exec, organizationId, scenarioIteration)
+	return args.Get(0).(models.ScenarioIteration), args.Error(1)
+}
+
+func (r *ScenarioBundleRepository) UpdateScenarioIterationVersion(ctx context.Context,
+	exec repositories.Executor, scenarioIterationId string, newVersion int,
+) error {
+	args := r.Called(exec, scenarioIterationId, newVersion)
+	return args.Error(0)
+}
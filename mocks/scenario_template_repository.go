@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type ScenarioTemplateRepository struct {
+	mock.Mock
+}
+
+func (r *ScenarioTemplateRepository) GetScenarioTemplateById(ctx context.Context, exec repositories.Executor,
+	templateId string,
+) (models.ScenarioTemplate, error) {
+	args := r.Called(exec, templateId)
+	return args.Get(0).(models.ScenarioTemplate), args.Error(1)
+}
+
+func (r *ScenarioTemplateRepository) ListScenarioTemplates(ctx context.Context,
+	exec repositories.Executor,
+) ([]models.ScenarioTemplate, error) {
+	args := r.Called(exec)
+	return args.Get(0).([]models.ScenarioTemplate), args.Error(1)
+}
+
+func (r *ScenarioTemplateRepository) CreateScenarioTemplate(ctx context.Context, exec repositories.Executor,
+	input models.CreateScenarioTemplateInput, newTemplateId string,
+) error {
+	args := r.Called(exec, input, newTemplateId)
+	return args.Error(0)
+}
+
+func (r *ScenarioTemplateRepository) UpdateScenarioTemplate(ctx context.Context, exec repositories.Executor,
+	input models.UpdateScenarioTemplateInput,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *ScenarioTemplateRepository) DeleteScenarioTemplate(ctx context.Context, exec repositories.Executor,
+	templateId string,
+) error {
+	args := r.Called(exec, templateId)
+	return args.Error(0)
+}
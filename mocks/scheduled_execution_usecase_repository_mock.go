@@ -53,3 +53,10 @@ func (s *ScheduledExecutionUsecaseRepository) GetScenarioIteration(ctx context.C
 	args := s.Called(exec, scenarioIterationId)
 	return args.Get(0).(models.ScenarioIteration), args.Error(1)
 }
+
+func (s *ScheduledExecutionUsecaseRepository) EnqueueJob(ctx context.Context,
+	exec repositories.Executor, input models.JobEnqueueInput,
+) error {
+	args := s.Called(exec, input)
+	return args.Error(0)
+}
@@ -0,0 +1,141 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type ScimRepository struct {
+	mock.Mock
+}
+
+func (r *ScimRepository) GetScimTokenById(ctx context.Context, exec repositories.Executor, tokenId string) (models.ScimToken, error) {
+	args := r.Called(exec, tokenId)
+	return args.Get(0).(models.ScimToken), args.Error(1)
+}
+
+func (r *ScimRepository) GetScimTokenByHash(ctx context.Context, exec repositories.Executor, hash []byte) (models.ScimToken, error) {
+	args := r.Called(exec, hash)
+	return args.Get(0).(models.ScimToken), args.Error(1)
+}
+
+func (r *ScimRepository) ListScimTokens(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.ScimToken, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).([]models.ScimToken), args.Error(1)
+}
+
+func (r *ScimRepository) CreateScimToken(ctx context.Context, exec repositories.Executor, token models.ScimToken) error {
+	args := r.Called(exec, token)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) SoftDeleteScimToken(ctx context.Context, exec repositories.Executor, tokenId string) error {
+	args := r.Called(exec, tokenId)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) ListScimUsers(ctx context.Context, exec repositories.Executor, organizationId, email string) ([]models.User, error) {
+	args := r.Called(exec, organizationId, email)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (r *ScimRepository) GetScimUser(ctx context.Context, exec repositories.Executor, organizationId string,
+	userId models.UserId,
+) (models.User, error) {
+	args := r.Called(exec, organizationId, userId)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (r *ScimRepository) ReactivateUser(ctx context.Context, exec repositories.Executor, userId models.UserId) error {
+	args := r.Called(exec, userId)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) ListScimGroups(ctx context.Context, exec repositories.Executor, organizationId,
+	displayName string,
+) ([]models.ScimGroup, error) {
+	args := r.Called(exec, organizationId, displayName)
+	return args.Get(0).([]models.ScimGroup), args.Error(1)
+}
+
+func (r *ScimRepository) GetScimGroup(ctx context.Context, exec repositories.Executor, organizationId,
+	groupId string,
+) (models.ScimGroup, error) {
+	args := r.Called(exec, organizationId, groupId)
+	return args.Get(0).(models.ScimGroup), args.Error(1)
+}
+
+func (r *ScimRepository) ListScimGroupsOfUser(ctx context.Context, exec repositories.Executor,
+	userId models.UserId,
+) ([]models.ScimGroup, error) {
+	args := r.Called(exec, userId)
+	return args.Get(0).([]models.ScimGroup), args.Error(1)
+}
+
+func (r *ScimRepository) ListScimGroupMembers(ctx context.Context, exec repositories.Executor,
+	groupIds []string,
+) ([]models.ScimGroupMember, error) {
+	args := r.Called(exec, groupIds)
+	return args.Get(0).([]models.ScimGroupMember), args.Error(1)
+}
+
+func (r *ScimRepository) CreateScimGroup(ctx context.Context, exec repositories.Executor, organizationId string,
+	input models.ScimGroupInput, newGroupId string,
+) error {
+	args := r.Called(exec, organizationId, input)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) UpdateScimGroup(ctx context.Context, exec repositories.Executor, groupId string,
+	input models.ScimGroupInput,
+) error {
+	args := r.Called(exec, groupId, input)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) UpdateScimGroupMapping(ctx context.Context, exec repositories.Executor,
+	input models.UpdateScimGroupMappingInput,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) SetScimGroupMembers(ctx context.Context, exec repositories.Executor, groupId string,
+	userIds []string,
+) error {
+	args := r.Called(exec, groupId, userIds)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) DeleteScimGroup(ctx context.Context, exec repositories.Executor, groupId string) error {
+	args := r.Called(exec, groupId)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) GetInboxById(ctx context.Context, exec repositories.Executor, inboxId string) (models.Inbox, error) {
+	args := r.Called(exec, inboxId)
+	return args.Get(0).(models.Inbox), args.Error(1)
+}
+
+func (r *ScimRepository) ListInboxUsers(ctx context.Context, exec repositories.Executor,
+	filters models.InboxUserFilterInput,
+) ([]models.InboxUser, error) {
+	args := r.Called(exec, filters)
+	return args.Get(0).([]models.InboxUser), args.Error(1)
+}
+
+func (r *ScimRepository) CreateInboxUser(ctx context.Context, exec repositories.Executor,
+	input models.CreateInboxUserInput, newInboxUserId string,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *ScimRepository) DeleteInboxUser(ctx context.Context, exec repositories.Executor, inboxUserId string) error {
+	args := r.Called(exec, inboxUserId)
+	return args.Error(0)
+}
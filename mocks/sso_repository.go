@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type SsoRepository struct {
+	mock.Mock
+}
+
+func (r *SsoRepository) GetSsoProviderOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) (models.SsoProvider, error) {
+	args := r.Called(exec, organizationId)
+	return args.Get(0).(models.SsoProvider), args.Error(1)
+}
+
+func (r *SsoRepository) UpsertSsoProvider(ctx context.Context, exec repositories.Executor, input models.UpsertSsoProviderInput) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *SsoRepository) DeleteSsoProvider(ctx context.Context, exec repositories.Executor, organizationId string) error {
+	args := r.Called(exec, organizationId)
+	return args.Error(0)
+}
+
+func (r *SsoRepository) CreateSsoLoginState(ctx context.Context, exec repositories.Executor, state models.SsoLoginState) error {
+	args := r.Called(exec, state)
+	return args.Error(0)
+}
+
+func (r *SsoRepository) ConsumeSsoLoginState(ctx context.Context, exec repositories.Executor, state string) (models.SsoLoginState, error) {
+	args := r.Called(exec, state)
+	return args.Get(0).(models.SsoLoginState), args.Error(1)
+}
+
+func (r *SsoRepository) DeleteExpiredSsoLoginStates(ctx context.Context, exec repositories.Executor, now time.Time) error {
+	args := r.Called(exec, now)
+	return args.Error(0)
+}
+
+func (r *SsoRepository) ListScimUsers(ctx context.Context, exec repositories.Executor, organizationId, email string) ([]models.User, error) {
+	args := r.Called(exec, organizationId, email)
+	return args.Get(0).([]models.User), args.Error(1)
+}
@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type ValidateScenarioIteration struct {
+	mock.Mock
+}
+
+func (v *ValidateScenarioIteration) Validate(ctx context.Context, si models.ScenarioAndIteration) models.ScenarioValidation {
+	args := v.Called(si)
+	return args.Get(0).(models.ScenarioValidation)
+}
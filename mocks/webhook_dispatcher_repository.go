@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type WebhookDispatcherRepository struct {
+	mock.Mock
+}
+
+func (r *WebhookDispatcherRepository) GetWebhook(ctx context.Context, exec repositories.Executor,
+	webhookId string,
+) (models.Webhook, error) {
+	args := r.Called(exec, webhookId)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (r *WebhookDispatcherRepository) ListWebhooks(ctx context.Context, exec repositories.Executor,
+	organizationId string, partnerId null.String,
+) ([]models.Webhook, error) {
+	args := r.Called(exec, organizationId, partnerId)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (r *WebhookDispatcherRepository) CreateWebhook(ctx context.Context, exec repositories.Executor,
+	webhookId string, organizationId string, partnerId null.String, input models.WebhookRegister,
+) error {
+	args := r.Called(exec, webhookId, organizationId, partnerId, input)
+	return args.Error(0)
+}
+
+func (r *WebhookDispatcherRepository) UpdateWebhook(ctx context.Context, exec repositories.Executor,
+	input models.Webhook,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *WebhookDispatcherRepository) DeleteWebhook(ctx context.Context, exec repositories.Executor,
+	webhookId string,
+) error {
+	args := r.Called(exec, webhookId)
+	return args.Error(0)
+}
+
+func (r *WebhookDispatcherRepository) GetOrCreateWebhookDelivery(ctx context.Context, exec repositories.Executor,
+	webhookEventId string, webhookId string,
+) (models.WebhookDelivery, error) {
+	args := r.Called(exec, webhookEventId, webhookId)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func (r *WebhookDispatcherRepository) MarkWebhookDeliveryAttempted(ctx context.Context, exec repositories.Executor,
+	deliveryId string, status models.WebhookEventDeliveryStatus,
+) error {
+	args := r.Called(exec, deliveryId, status)
+	return args.Error(0)
+}
+
+func (r *WebhookDispatcherRepository) CreateWebhookDeliveryAttempt(ctx context.Context, exec repositories.Executor,
+	input models.WebhookDeliveryAttemptCreate,
+) error {
+	args := r.Called(exec, input)
+	return args.Error(0)
+}
+
+func (r *WebhookDispatcherRepository) CountWebhookDeliveryAttemptsSince(ctx context.Context,
+	exec repositories.Executor, webhookId string, since time.Time,
+) (int, error) {
+	args := r.Called(exec, webhookId, since)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,146 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+type WebhooksBackend struct {
+	mock.Mock
+}
+
+func (m *WebhooksBackend) GetWebhook(ctx context.Context, webhookId string) (models.Webhook, error) {
+	args := m.Called(webhookId)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *WebhooksBackend) ListWebhooks(ctx context.Context, organizationId string, partnerId null.String) ([]models.Webhook, error) {
+	args := m.Called(organizationId, partnerId)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *WebhooksBackend) RegisterWebhook(ctx context.Context, organizationId string, partnerId null.String,
+	input models.WebhookRegister,
+) (models.Webhook, error) {
+	args := m.Called(organizationId, partnerId, input)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *WebhooksBackend) UpdateWebhook(ctx context.Context, input models.Webhook) (models.Webhook, error) {
+	args := m.Called(input)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *WebhooksBackend) DeleteWebhook(ctx context.Context, webhookId string) error {
+	args := m.Called(webhookId)
+	return args.Error(0)
+}
+
+type WebhookEventsLogRepository struct {
+	mock.Mock
+}
+
+func (m *WebhookEventsLogRepository) GetWebhookEvent(ctx context.Context, exec repositories.Executor,
+	webhookEventId string,
+) (models.WebhookEvent, error) {
+	args := m.Called(exec, webhookEventId)
+	return args.Get(0).(models.WebhookEvent), args.Error(1)
+}
+
+func (m *WebhookEventsLogRepository) ListWebhookEvents(ctx context.Context, exec repositories.Executor,
+	filters models.WebhookEventFilters,
+) ([]models.WebhookEvent, error) {
+	args := m.Called(exec, filters)
+	return args.Get(0).([]models.WebhookEvent), args.Error(1)
+}
+
+func (m *WebhookEventsLogRepository) MarkWebhookEventForReplay(ctx context.Context, exec repositories.Executor,
+	webhookEventId string,
+) error {
+	args := m.Called(exec, webhookEventId)
+	return args.Error(0)
+}
+
+func (m *WebhookEventsLogRepository) ListWebhookDeliveries(ctx context.Context, exec repositories.Executor,
+	webhookEventId string,
+) ([]models.WebhookDelivery, error) {
+	args := m.Called(exec, webhookEventId)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookEventsLogRepository) ListWebhookDeliveryAttempts(ctx context.Context, exec repositories.Executor,
+	deliveryIds []string,
+) ([]models.WebhookDeliveryAttempt, error) {
+	args := m.Called(exec, deliveryIds)
+	return args.Get(0).([]models.WebhookDeliveryAttempt), args.Error(1)
+}
+
+func (m *WebhookEventsLogRepository) ResetWebhookDeliveries(ctx context.Context, exec repositories.Executor,
+	webhookEventId string, webhookId *string, statuses []models.WebhookEventDeliveryStatus,
+) error {
+	args := m.Called(exec, webhookEventId, webhookId, statuses)
+	return args.Error(0)
+}
+
+type EnforceSecurityWebhook struct {
+	mock.Mock
+}
+
+func (m *EnforceSecurityWebhook) CanCreateWebhook(ctx context.Context, organizationId string, partnerId null.String) error {
+	args := m.Called(organizationId, partnerId)
+	return args.Error(0)
+}
+
+func (m *EnforceSecurityWebhook) CanReadWebhook(ctx context.Context, webhook models.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *EnforceSecurityWebhook) CanModifyWebhook(ctx context.Context, webhook models.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *EnforceSecurityWebhook) CanReadWebhookEvents(ctx context.Context, organizationId string, partnerId null.String) error {
+	args := m.Called(organizationId, partnerId)
+	return args.Error(0)
+}
+
+func (m *EnforceSecurityWebhook) CanReadWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error {
+	args := m.Called(webhookEvent)
+	return args.Error(0)
+}
+
+func (m *EnforceSecurityWebhook) CanReplayWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error {
+	args := m.Called(webhookEvent)
+	return args.Error(0)
+}
+
+type WebhookEventsSender struct {
+	mock.Mock
+}
+
+func (m *WebhookEventsSender) CreateWebhookEvent(ctx context.Context, tx repositories.Executor,
+	input models.WebhookEventCreate,
+) error {
+	args := m.Called(tx, input)
+	return args.Error(0)
+}
+
+func (m *WebhookEventsSender) SendWebhookEventAsync(ctx context.Context, webhookEventId string) {
+	m.Called(webhookEventId)
+}
+
+type JobEnqueuer struct {
+	mock.Mock
+}
+
+func (m *JobEnqueuer) EnqueueJob(ctx context.Context, exec repositories.Executor, input models.JobEnqueueInput) error {
+	args := m.Called(exec, input)
+	return args.Error(0)
+}
@@ -1,6 +1,17 @@
 package models
 
-import "time"
+import (
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	API_KEY_DEFAULT_ROTATION_OVERLAP = 24 * time.Hour
+	API_KEY_MAX_ROTATION_OVERLAP     = 30 * 24 * time.Hour
+)
 
 type ApiKey struct {
 	Id             string
@@ -11,12 +22,126 @@ type ApiKey struct {
 	PartnerId      *string
 	Prefix         string
 	Role           Role
+	CustomRoleId   *string
+	// The key is refused after its expiration date, if any
+	ExpiresAt *time.Time
+	Scope     ApiKeyScope
+	// The key is only accepted from these networks, if any
+	AllowedNetworks []netip.Prefix
+	// The key this key replaced, if it was created by a rotation
+	RotatedFromId *string
+	LastUsedAt    *time.Time
+	RequestCount  int64
+}
+
+func (apiKey ApiKey) IsExpired(now time.Time) bool {
+	return apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)
+}
+
+// ApiKeyUsage counts the requests authenticated by an API key over a period
+type ApiKeyUsage struct {
+	RequestCount int64
+	LastUsedAt   time.Time
+}
+
+func (usage ApiKeyUsage) Add(other ApiKeyUsage) ApiKeyUsage {
+	usage.RequestCount += other.RequestCount
+	if other.LastUsedAt.After(usage.LastUsedAt) {
+		usage.LastUsedAt = other.LastUsedAt
+	}
+	return usage
+}
+
+// ApiKeyScope restricts what an API key can do. An empty list does not restrict anything.
+type ApiKeyScope struct {
+	// The scenarios on which the key can take decisions
+	ScenarioIds []string
+	// The trigger objects on which the key can take decisions
+	TriggerObjectTypes []string
+	// The tables in which the key can ingest data
+	IngestionTables []string
+}
+
+func (scope ApiKeyScope) IsEmpty() bool {
+	return len(scope.ScenarioIds) == 0 && len(scope.TriggerObjectTypes) == 0 && len(scope.IngestionTables) == 0
+}
+
+func (scope ApiKeyScope) AllowsScenario(scenario Scenario) bool {
+	return (len(scope.ScenarioIds) == 0 || slices.Contains(scope.ScenarioIds, scenario.Id)) &&
+		scope.AllowsTriggerObjectType(scenario.TriggerObjectType)
+}
+
+func (scope ApiKeyScope) AllowsTriggerObjectType(triggerObjectType string) bool {
+	return len(scope.TriggerObjectTypes) == 0 || slices.Contains(scope.TriggerObjectTypes, triggerObjectType)
+}
+
+func (scope ApiKeyScope) AllowsIngestion(tableName string) bool {
+	return len(scope.IngestionTables) == 0 || slices.Contains(scope.IngestionTables, tableName)
+}
+
+// ParseAllowedNetworks parses a list of IP addresses and CIDR ranges, an IP address is a range of one address
+func ParseAllowedNetworks(values []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		network, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return nil, errors.Wrapf(BadParameterError, "%s is not an IP address nor a CIDR range", value)
+			}
+			network = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		networks = append(networks, network.Masked())
+	}
+	return networks, nil
+}
+
+// NetworksAllowIp tells if the IP is in one of the networks, any IP is allowed if there is no network
+func NetworksAllowIp(networks []netip.Prefix, ip string) bool {
+	if len(networks) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return slices.ContainsFunc(networks, func(network netip.Prefix) bool {
+		return network.Contains(addr)
+	})
 }
 
 type CreateApiKeyInput struct {
-	Description    string
-	OrganizationId string
-	Role           Role
+	Description     string
+	OrganizationId  string
+	Role            Role
+	ExpiresAt       *time.Time
+	Scope           ApiKeyScope
+	AllowedNetworks []netip.Prefix
+}
+
+func (input CreateApiKeyInput) Validate(now time.Time) error {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return errors.Wrap(BadParameterError, "expiration date must be in the future")
+	}
+	return nil
+}
+
+type RotateApiKeyInput struct {
+	ApiKeyId string
+	// How long the replaced key is still accepted, nil for the default overlap
+	Overlap *time.Duration
+}
+
+func (input RotateApiKeyInput) OverlapOrDefault() (time.Duration, error) {
+	if input.Overlap == nil {
+		return API_KEY_DEFAULT_ROTATION_OVERLAP, nil
+	}
+	if *input.Overlap < 0 || *input.Overlap > API_KEY_MAX_ROTATION_OVERLAP {
+		return 0, errors.Wrapf(BadParameterError, "rotation overlap must be between 0 and %s",
+			API_KEY_MAX_ROTATION_OVERLAP)
+	}
+	return *input.Overlap, nil
 }
 
 type CreatedApiKey struct {
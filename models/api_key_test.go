@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyScope(t *testing.T) {
+	scenario := Scenario{Id: "scenario_id", TriggerObjectType: "transactions"}
+
+	assert.True(t, ApiKeyScope{}.AllowsScenario(scenario))
+	assert.True(t, ApiKeyScope{}.AllowsIngestion("transactions"))
+
+	scope := ApiKeyScope{ScenarioIds: []string{"other_scenario_id"}}
+	assert.False(t, scope.AllowsScenario(scenario))
+	assert.True(t, scope.AllowsIngestion("transactions"))
+
+	scope = ApiKeyScope{TriggerObjectTypes: []string{"transactions"}, IngestionTables: []string{"accounts"}}
+	assert.True(t, scope.AllowsScenario(scenario))
+	assert.False(t, scope.AllowsScenario(Scenario{Id: "scenario_id", TriggerObjectType: "accounts"}))
+	assert.True(t, scope.AllowsIngestion("accounts"))
+	assert.False(t, scope.AllowsIngestion("transactions"))
+}
+
+func TestParseAllowedNetworks(t *testing.T) {
+	networks, err := ParseAllowedNetworks([]string{"10.0.0.0/8", "192.168.1.12", "2001:db8::/32"})
+	assert.NoError(t, err)
+	assert.Len(t, networks, 3)
+	assert.Equal(t, "192.168.1.12/32", networks[1].String())
+
+	assert.True(t, NetworksAllowIp(networks, "10.1.2.3"))
+	assert.True(t, NetworksAllowIp(networks, "192.168.1.12"))
+	assert.True(t, NetworksAllowIp(networks, "::ffff:10.1.2.3"))
+	assert.True(t, NetworksAllowIp(networks, "2001:db8::1"))
+	assert.False(t, NetworksAllowIp(networks, "192.168.1.13"))
+	assert.False(t, NetworksAllowIp(networks, "not an ip"))
+	assert.True(t, NetworksAllowIp(nil, "192.168.1.13"))
+
+	_, err = ParseAllowedNetworks([]string{"10.0.0.0/33"})
+	assert.ErrorIs(t, err, BadParameterError)
+}
+
+func TestApiKey_IsExpired(t *testing.T) {
+	now := time.Now()
+	assert.False(t, ApiKey{}.IsExpired(now))
+
+	expiresAt := now.Add(time.Hour)
+	assert.False(t, ApiKey{ExpiresAt: &expiresAt}.IsExpired(now))
+	assert.True(t, ApiKey{ExpiresAt: &expiresAt}.IsExpired(expiresAt))
+}
+
+func TestRotateApiKeyInput_OverlapOrDefault(t *testing.T) {
+	overlap, err := RotateApiKeyInput{}.OverlapOrDefault()
+	assert.NoError(t, err)
+	assert.Equal(t, API_KEY_DEFAULT_ROTATION_OVERLAP, overlap)
+
+	zero := time.Duration(0)
+	overlap, err = RotateApiKeyInput{Overlap: &zero}.OverlapOrDefault()
+	assert.NoError(t, err)
+	assert.Equal(t, zero, overlap)
+
+	tooLong := API_KEY_MAX_ROTATION_OVERLAP + time.Second
+	_, err = RotateApiKeyInput{Overlap: &tooLong}.OverlapOrDefault()
+	assert.ErrorIs(t, err, BadParameterError)
+}
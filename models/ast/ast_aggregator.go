@@ -12,9 +12,16 @@ const (
 	AGGREGATOR_UNKNOWN        Aggregator = "Unkown aggregator"
 )
 
+const (
+	AggregatorArgumentTableName = "tableName"
+	AggregatorArgumentFieldName = "fieldName"
+)
+
 var FuncAggregatorAttributes = FuncAttributes{
 	DebugName:         "FUNC_AGGREGATOR",
 	AstName:           "Aggregator",
 	NumberOfArguments: 4,
-	NamedArguments:    []string{"tableName", "fieldName", "aggregator", "filters", "label"},
+	NamedArguments: []string{
+		AggregatorArgumentTableName, AggregatorArgumentFieldName, "aggregator", "filters", "label",
+	},
 }
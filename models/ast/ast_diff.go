@@ -0,0 +1,92 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+type NodeChange string
+
+const (
+	NodeAdded    NodeChange = "added"
+	NodeRemoved  NodeChange = "removed"
+	NodeModified NodeChange = "modified"
+)
+
+// NodeDiff is one structural difference between two node trees.
+// Path locates the node in the trees, e.g. "children[0].named_children.value", and is empty for the root node.
+// Before is nil for an added node, After is nil for a removed node.
+type NodeDiff struct {
+	Path   string
+	Change NodeChange
+	Before *Node
+	After  *Node
+}
+
+// DiffNodes returns the structural differences between two node trees, in a stable order.
+// A node whose function or constant changed is reported as modified as a whole, its children are not compared.
+func DiffNodes(before, after *Node) []NodeDiff {
+	diffs := make([]NodeDiff, 0)
+	switch {
+	case before == nil && after == nil:
+	case before == nil:
+		diffs = append(diffs, NodeDiff{Change: NodeAdded, After: after})
+	case after == nil:
+		diffs = append(diffs, NodeDiff{Change: NodeRemoved, Before: before})
+	default:
+		diffs = diffNodes(diffs, "", *before, *after)
+	}
+	return diffs
+}
+
+func diffNodes(diffs []NodeDiff, path string, before, after Node) []NodeDiff {
+	if before.Function != after.Function || !reflect.DeepEqual(before.Constant, after.Constant) {
+		return append(diffs, NodeDiff{Path: path, Change: NodeModified, Before: &before, After: &after})
+	}
+
+	for i := 0; i < max(len(before.Children), len(after.Children)); i++ {
+		childPath := joinNodePath(path, fmt.Sprintf("children[%d]", i))
+		switch {
+		case i >= len(before.Children):
+			diffs = append(diffs, NodeDiff{Path: childPath, Change: NodeAdded, After: &after.Children[i]})
+		case i >= len(after.Children):
+			diffs = append(diffs, NodeDiff{Path: childPath, Change: NodeRemoved, Before: &before.Children[i]})
+		default:
+			diffs = diffNodes(diffs, childPath, before.Children[i], after.Children[i])
+		}
+	}
+
+	names := make([]string, 0, len(before.NamedChildren))
+	for name := range before.NamedChildren {
+		names = append(names, name)
+	}
+	for name := range after.NamedChildren {
+		if _, ok := before.NamedChildren[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		childPath := joinNodePath(path, "named_children."+name)
+		beforeChild, inBefore := before.NamedChildren[name]
+		afterChild, inAfter := after.NamedChildren[name]
+		switch {
+		case !inBefore:
+			diffs = append(diffs, NodeDiff{Path: childPath, Change: NodeAdded, After: &afterChild})
+		case !inAfter:
+			diffs = append(diffs, NodeDiff{Path: childPath, Change: NodeRemoved, Before: &beforeChild})
+		default:
+			diffs = diffNodes(diffs, childPath, beforeChild, afterChild)
+		}
+	}
+	return diffs
+}
+
+func joinNodePath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
+}
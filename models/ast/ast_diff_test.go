@@ -0,0 +1,73 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffNodes_identical(t *testing.T) {
+	node := Node{Function: FUNC_ADD}.AddChild(NewNodeConstant(1)).AddChild(NewNodeConstant(2))
+	other := Node{Function: FUNC_ADD}.AddChild(NewNodeConstant(1)).AddChild(NewNodeConstant(2))
+
+	assert.Empty(t, DiffNodes(&node, &other))
+	assert.Empty(t, DiffNodes(nil, nil))
+}
+
+func TestDiffNodes_root(t *testing.T) {
+	node := NewNodeConstant(true)
+
+	diffs := DiffNodes(nil, &node)
+	assert.Equal(t, []NodeDiff{{Change: NodeAdded, After: &node}}, diffs)
+
+	diffs = DiffNodes(&node, nil)
+	assert.Equal(t, []NodeDiff{{Change: NodeRemoved, Before: &node}}, diffs)
+}
+
+func TestDiffNodes_children(t *testing.T) {
+	before := Node{Function: FUNC_AND}.
+		AddChild(NewNodeConstant(1)).
+		AddChild(Node{Function: FUNC_EQUAL}.AddChild(NewNodeConstant("a")).AddChild(NewNodeConstant("b")))
+	after := Node{Function: FUNC_AND}.
+		AddChild(NewNodeConstant(1)).
+		AddChild(Node{Function: FUNC_EQUAL}.AddChild(NewNodeConstant("a")).AddChild(NewNodeConstant("c"))).
+		AddChild(NewNodeConstant(3))
+
+	diffs := DiffNodes(&before, &after)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, "children[1].children[1]", diffs[0].Path)
+	assert.Equal(t, NodeModified, diffs[0].Change)
+	assert.Equal(t, "b", diffs[0].Before.Constant)
+	assert.Equal(t, "c", diffs[0].After.Constant)
+	assert.Equal(t, "children[2]", diffs[1].Path)
+	assert.Equal(t, NodeAdded, diffs[1].Change)
+	assert.Nil(t, diffs[1].Before)
+}
+
+func TestDiffNodes_named_children(t *testing.T) {
+	before := Node{Function: FUNC_PAYLOAD}.
+		AddNamedChild("b", NewNodeConstant("x")).
+		AddNamedChild("a", NewNodeConstant("y"))
+	after := Node{Function: FUNC_PAYLOAD}.
+		AddNamedChild("c", NewNodeConstant("x")).
+		AddNamedChild("a", NewNodeConstant("z"))
+
+	diffs := DiffNodes(&before, &after)
+	assert.Len(t, diffs, 3)
+	assert.Equal(t, "named_children.a", diffs[0].Path)
+	assert.Equal(t, NodeModified, diffs[0].Change)
+	assert.Equal(t, "named_children.b", diffs[1].Path)
+	assert.Equal(t, NodeRemoved, diffs[1].Change)
+	assert.Equal(t, "named_children.c", diffs[2].Path)
+	assert.Equal(t, NodeAdded, diffs[2].Change)
+}
+
+func TestDiffNodes_function_changed(t *testing.T) {
+	before := Node{Function: FUNC_GREATER}.AddChild(NewNodeConstant(1)).AddChild(NewNodeConstant(2))
+	after := Node{Function: FUNC_LESS}.AddChild(NewNodeConstant(1)).AddChild(NewNodeConstant(3))
+
+	diffs := DiffNodes(&before, &after)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "", diffs[0].Path)
+	assert.Equal(t, NodeModified, diffs[0].Change)
+}
@@ -0,0 +1,241 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Position of a character in a formula written in the text syntax. Line and Column start at 1, Column counts
+// characters, Offset counts bytes.
+type DslPosition struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// DslSyntaxError is returned when a formula written in the text syntax cannot be parsed
+type DslSyntaxError struct {
+	Position DslPosition
+	Message  string
+}
+
+func (e DslSyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Position.Line, e.Position.Column, e.Message)
+}
+
+type dslTokenKind int
+
+const (
+	dslTokenEOF dslTokenKind = iota
+	dslTokenIdent
+	dslTokenNumber
+	dslTokenString
+	dslTokenOperator
+	dslTokenLeftParen
+	dslTokenRightParen
+	dslTokenLeftBracket
+	dslTokenRightBracket
+	dslTokenLeftBrace
+	dslTokenRightBrace
+	dslTokenComma
+	dslTokenColon
+	dslTokenDot
+)
+
+type dslToken struct {
+	kind     dslTokenKind
+	text     string
+	position DslPosition
+}
+
+func (token dslToken) describe() string {
+	if token.kind == dslTokenEOF {
+		return "end of formula"
+	}
+	return fmt.Sprintf("%q", token.text)
+}
+
+var dslPunctuation = map[rune]dslTokenKind{
+	'(': dslTokenLeftParen,
+	')': dslTokenRightParen,
+	'[': dslTokenLeftBracket,
+	']': dslTokenRightBracket,
+	'{': dslTokenLeftBrace,
+	'}': dslTokenRightBrace,
+	',': dslTokenComma,
+	':': dslTokenColon,
+	'.': dslTokenDot,
+}
+
+// operators, the longest first so that ">=" is not read as ">"
+var dslOperators = []string{">=", "<=", "!=", "==", "≠", ">", "<", "=", "+", "-", "*", "/"}
+
+type dslLexer struct {
+	input    string
+	position DslPosition
+}
+
+func lexDsl(input string) ([]dslToken, error) {
+	lexer := dslLexer{input: input, position: DslPosition{Line: 1, Column: 1}}
+	tokens := make([]dslToken, 0)
+	for {
+		token, err := lexer.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+		if token.kind == dslTokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (lexer *dslLexer) peek() rune {
+	r, _ := utf8.DecodeRuneInString(lexer.input[lexer.position.Offset:])
+	return r
+}
+
+func (lexer *dslLexer) advance(n int) {
+	for _, r := range lexer.input[lexer.position.Offset : lexer.position.Offset+n] {
+		if r == '\n' {
+			lexer.position.Line++
+			lexer.position.Column = 1
+		} else {
+			lexer.position.Column++
+		}
+	}
+	lexer.position.Offset += n
+}
+
+func (lexer *dslLexer) skipSpacesAndComments() {
+	for lexer.position.Offset < len(lexer.input) {
+		rest := lexer.input[lexer.position.Offset:]
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsSpace(r):
+			lexer.advance(size)
+		case strings.HasPrefix(rest, "#"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			lexer.advance(end)
+		default:
+			return
+		}
+	}
+}
+
+func (lexer *dslLexer) next() (dslToken, error) {
+	lexer.skipSpacesAndComments()
+	start := lexer.position
+	if start.Offset >= len(lexer.input) {
+		return dslToken{kind: dslTokenEOF, position: start}, nil
+	}
+	rest := lexer.input[start.Offset:]
+	r := lexer.peek()
+
+	token := func(kind dslTokenKind, length int) (dslToken, error) {
+		lexer.advance(length)
+		return dslToken{kind: kind, text: rest[:length], position: start}, nil
+	}
+
+	if kind, ok := dslPunctuation[r]; ok {
+		return token(kind, 1)
+	}
+	if isDslIdentStart(r) {
+		length := strings.IndexFunc(rest, func(r rune) bool { return !isDslIdentPart(r) })
+		if length < 0 {
+			length = len(rest)
+		}
+		return token(dslTokenIdent, length)
+	}
+	if r >= '0' && r <= '9' {
+		return token(dslTokenNumber, scanDslNumber(rest))
+	}
+	if r == '"' {
+		length, ok := scanDslString(rest)
+		if !ok {
+			return dslToken{}, DslSyntaxError{Position: start, Message: "unterminated string"}
+		}
+		return token(dslTokenString, length)
+	}
+	for _, operator := range dslOperators {
+		if strings.HasPrefix(rest, operator) {
+			return token(dslTokenOperator, len(operator))
+		}
+	}
+	return dslToken{}, DslSyntaxError{Position: start, Message: fmt.Sprintf("unexpected character %q", r)}
+}
+
+func isDslIdentStart(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isDslIdentPart(r rune) bool {
+	return isDslIdentStart(r) || r >= '0' && r <= '9'
+}
+
+func isDslIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if i == 0 && !isDslIdentStart(r) || !isDslIdentPart(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// scanDslNumber returns the length of the number at the start of s: digits, an optional fraction and exponent
+func scanDslNumber(s string) int {
+	i := 0
+	digits := func() {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	digits()
+	if i+1 < len(s) && s[i] == '.' && s[i+1] >= '0' && s[i+1] <= '9' {
+		i++
+		digits()
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+// scanDslString returns the length of the double quoted string at the start of s, escapes included
+func scanDslString(s string) (int, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\n':
+			return 0, false
+		case '"':
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func unquoteDslString(token dslToken) (string, error) {
+	value, err := strconv.Unquote(token.text)
+	if err != nil {
+		return "", DslSyntaxError{Position: token.position, Message: "invalid string " + token.text}
+	}
+	return value, nil
+}
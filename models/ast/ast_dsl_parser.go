@@ -0,0 +1,495 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Text syntax of formulas, meant to be read and reviewed by humans. Any node tree can be written in it:
+//
+//	payload.amount > 1000 and IsInList(payload.country, list("high_risk"))
+//
+//   - operators, from the lowest to the highest precedence: `or`, `and`, `not`, comparisons (`=`, `!=`, `>`, `>=`,
+//     `<`, `<=`), `+` `-`, `*` `/`. Comparisons cannot be chained.
+//   - constants: numbers, double quoted strings, `true`, `false`, `null`, `[...]` arrays and `{"key": ...}` objects
+//   - `payload.field` reads a field of the trigger object
+//   - `db.table.link.field` reads a field of an ingested object, following the links from the trigger table
+//   - `list("id")` is the custom list with this id
+//   - any other function is called by its name: `TimeAdd(TimeNow(), duration: "P1D", sign: "-")`, with positional
+//     arguments first and named arguments after. Operators can be called by their quoted name: `">"(a, b)`.
+//   - `#` starts a comment, until the end of the line
+
+var dslFunctionsByName = func() map[string]Function {
+	result := make(map[string]Function, len(FuncAttributesMap))
+	for f, attributes := range FuncAttributesMap {
+		if attributes.AstName != "" {
+			result[attributes.AstName] = f
+		}
+	}
+	return result
+}()
+
+var dslComparisonOperators = map[string]Function{
+	"=":  FUNC_EQUAL,
+	"==": FUNC_EQUAL,
+	"!=": FUNC_NOT_EQUAL,
+	"≠":  FUNC_NOT_EQUAL,
+	">":  FUNC_GREATER,
+	">=": FUNC_GREATER_OR_EQUAL,
+	"<":  FUNC_LESS,
+	"<=": FUNC_LESS_OR_EQUAL,
+}
+
+var dslAdditiveOperators = map[string]Function{
+	"+": FUNC_ADD,
+	"-": FUNC_SUBTRACT,
+}
+
+var dslMultiplicativeOperators = map[string]Function{
+	"*": FUNC_MULTIPLY,
+	"/": FUNC_DIVIDE,
+}
+
+// dslMaxDepth bounds the nesting of the parenthesized expressions, function arguments, `not` and constants, whose
+// recursive parsing could otherwise exhaust the stack on a crafted formula
+const dslMaxDepth = 100
+
+// ParseDsl parses a formula written in the text syntax. Errors are DslSyntaxError, with the position of the problem.
+func ParseDsl(input string) (Node, error) {
+	tokens, err := lexDsl(input)
+	if err != nil {
+		return Node{}, err
+	}
+	parser := dslParser{tokens: tokens}
+	node, err := parser.parseExpression()
+	if err != nil {
+		return Node{}, err
+	}
+	if token := parser.peek(); token.kind != dslTokenEOF {
+		return Node{}, parser.unexpected(token, "end of formula")
+	}
+	return node, nil
+}
+
+type dslParser struct {
+	tokens []dslToken
+	index  int
+	depth  int
+}
+
+// enter is called when the parser goes one level deeper, with a matching leave when it comes back
+func (parser *dslParser) enter() error {
+	parser.depth++
+	if parser.depth > dslMaxDepth {
+		return DslSyntaxError{
+			Position: parser.peek().position,
+			Message:  fmt.Sprintf("the formula is nested more than %d levels deep", dslMaxDepth),
+		}
+	}
+	return nil
+}
+
+func (parser *dslParser) leave() {
+	parser.depth--
+}
+
+func (parser *dslParser) peek() dslToken {
+	return parser.tokens[parser.index]
+}
+
+func (parser *dslParser) peekNext() dslToken {
+	if parser.index+1 < len(parser.tokens) {
+		return parser.tokens[parser.index+1]
+	}
+	return parser.tokens[len(parser.tokens)-1]
+}
+
+func (parser *dslParser) advance() dslToken {
+	token := parser.tokens[parser.index]
+	if token.kind != dslTokenEOF {
+		parser.index++
+	}
+	return token
+}
+
+func (parser *dslParser) isKeyword(keyword string) bool {
+	token := parser.peek()
+	return token.kind == dslTokenIdent && token.text == keyword
+}
+
+func (parser *dslParser) unexpected(token dslToken, expected string) error {
+	return DslSyntaxError{
+		Position: token.position,
+		Message:  fmt.Sprintf("expected %s, found %s", expected, token.describe()),
+	}
+}
+
+func (parser *dslParser) expect(kind dslTokenKind, expected string) (dslToken, error) {
+	token := parser.peek()
+	if token.kind != kind {
+		return dslToken{}, parser.unexpected(token, expected)
+	}
+	return parser.advance(), nil
+}
+
+func (parser *dslParser) parseExpression() (Node, error) {
+	if err := parser.enter(); err != nil {
+		return Node{}, err
+	}
+	defer parser.leave()
+	return parser.parseLogical("or", FUNC_OR, parser.parseAnd)
+}
+
+func (parser *dslParser) parseAnd() (Node, error) {
+	return parser.parseLogical("and", FUNC_AND, parser.parseNot)
+}
+
+// parseLogical parses a chain of operands separated by the keyword into a single node
+func (parser *dslParser) parseLogical(keyword string, f Function, parseOperand func() (Node, error)) (Node, error) {
+	first, err := parseOperand()
+	if err != nil || !parser.isKeyword(keyword) {
+		return first, err
+	}
+	node := Node{Function: f}.AddChild(first)
+	for parser.isKeyword(keyword) {
+		parser.advance()
+		operand, err := parseOperand()
+		if err != nil {
+			return Node{}, err
+		}
+		node = node.AddChild(operand)
+	}
+	return node, nil
+}
+
+func (parser *dslParser) parseNot() (Node, error) {
+	if !parser.isKeyword("not") {
+		return parser.parseComparison()
+	}
+	if err := parser.enter(); err != nil {
+		return Node{}, err
+	}
+	defer parser.leave()
+	parser.advance()
+	operand, err := parser.parseNot()
+	if err != nil {
+		return Node{}, err
+	}
+	return Node{Function: FUNC_NOT}.AddChild(operand), nil
+}
+
+func (parser *dslParser) parseComparison() (Node, error) {
+	left, err := parser.parseBinary(dslAdditiveOperators, parser.parseTerm)
+	if err != nil {
+		return Node{}, err
+	}
+	token := parser.peek()
+	f, ok := dslComparisonOperators[token.text]
+	if token.kind != dslTokenOperator || !ok {
+		return left, nil
+	}
+	parser.advance()
+	right, err := parser.parseBinary(dslAdditiveOperators, parser.parseTerm)
+	if err != nil {
+		return Node{}, err
+	}
+	if next := parser.peek(); next.kind == dslTokenOperator {
+		if _, ok := dslComparisonOperators[next.text]; ok {
+			return Node{}, DslSyntaxError{
+				Position: next.position,
+				Message:  "comparisons cannot be chained, use parentheses",
+			}
+		}
+	}
+	return Node{Function: f}.AddChild(left).AddChild(right), nil
+}
+
+func (parser *dslParser) parseTerm() (Node, error) {
+	return parser.parseBinary(dslMultiplicativeOperators, parser.parseUnary)
+}
+
+// parseBinary parses left associative operators
+func (parser *dslParser) parseBinary(operators map[string]Function, parseOperand func() (Node, error)) (Node, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return Node{}, err
+	}
+	for {
+		token := parser.peek()
+		f, ok := operators[token.text]
+		if token.kind != dslTokenOperator || !ok {
+			return left, nil
+		}
+		parser.advance()
+		right, err := parseOperand()
+		if err != nil {
+			return Node{}, err
+		}
+		left = Node{Function: f}.AddChild(left).AddChild(right)
+	}
+}
+
+func (parser *dslParser) parseUnary() (Node, error) {
+	token := parser.peek()
+	if token.kind == dslTokenOperator && token.text == "-" {
+		if parser.peekNext().kind != dslTokenNumber {
+			return Node{}, DslSyntaxError{
+				Position: token.position,
+				Message:  "unary minus is only allowed before a number, use 0 - x",
+			}
+		}
+	}
+	return parser.parsePrimary()
+}
+
+func (parser *dslParser) parsePrimary() (Node, error) {
+	token := parser.peek()
+	switch token.kind {
+	case dslTokenLeftParen:
+		parser.advance()
+		node, err := parser.parseExpression()
+		if err != nil {
+			return Node{}, err
+		}
+		if _, err := parser.expect(dslTokenRightParen, `")"`); err != nil {
+			return Node{}, err
+		}
+		return node, nil
+	case dslTokenString:
+		if parser.peekNext().kind == dslTokenLeftParen {
+			name, err := unquoteDslString(parser.advance())
+			if err != nil {
+				return Node{}, err
+			}
+			return parser.parseCall(token, name)
+		}
+	case dslTokenIdent:
+		switch token.text {
+		case "payload":
+			return parser.parsePayload()
+		case "db":
+			return parser.parseDatabaseAccess()
+		case "list":
+			return parser.parseCustomListAccess()
+		case "true", "false", "null":
+		case "and", "or", "not":
+			return Node{}, parser.unexpected(token, "a value")
+		default:
+			if parser.peekNext().kind != dslTokenLeftParen {
+				return Node{}, DslSyntaxError{
+					Position: token.position,
+					Message:  fmt.Sprintf("unknown identifier %q, function calls need parentheses", token.text),
+				}
+			}
+			parser.advance()
+			return parser.parseCall(token, token.text)
+		}
+	}
+
+	value, err := parser.parseConstant()
+	if err != nil {
+		return Node{}, err
+	}
+	return NewNodeConstant(value), nil
+}
+
+// parseCall parses the arguments of a function call, the name has already been read
+func (parser *dslParser) parseCall(nameToken dslToken, name string) (Node, error) {
+	f, ok := dslFunctionsByName[name]
+	if !ok {
+		return Node{}, DslSyntaxError{Position: nameToken.position, Message: fmt.Sprintf("unknown function %q", name)}
+	}
+	parser.advance() // "("
+
+	node := Node{Function: f}
+	for parser.peek().kind != dslTokenRightParen {
+		token := parser.peek()
+		if (token.kind == dslTokenIdent || token.kind == dslTokenString) && parser.peekNext().kind == dslTokenColon {
+			argumentName := token.text
+			if token.kind == dslTokenString {
+				var err error
+				if argumentName, err = unquoteDslString(token); err != nil {
+					return Node{}, err
+				}
+			}
+			if _, exists := node.NamedChildren[argumentName]; exists {
+				return Node{}, DslSyntaxError{
+					Position: token.position,
+					Message:  fmt.Sprintf("argument %q is given twice", argumentName),
+				}
+			}
+			parser.advance()
+			parser.advance()
+			argument, err := parser.parseExpression()
+			if err != nil {
+				return Node{}, err
+			}
+			node = node.AddNamedChild(argumentName, argument)
+		} else {
+			if len(node.NamedChildren) > 0 {
+				return Node{}, DslSyntaxError{
+					Position: token.position,
+					Message:  "positional arguments must come before named arguments",
+				}
+			}
+			argument, err := parser.parseExpression()
+			if err != nil {
+				return Node{}, err
+			}
+			node = node.AddChild(argument)
+		}
+
+		if parser.peek().kind != dslTokenComma {
+			break
+		}
+		parser.advance()
+	}
+	if _, err := parser.expect(dslTokenRightParen, `"," or ")"`); err != nil {
+		return Node{}, err
+	}
+	return node, nil
+}
+
+func (parser *dslParser) parsePayload() (Node, error) {
+	parser.advance()
+	if _, err := parser.expect(dslTokenDot, `"." after payload`); err != nil {
+		return Node{}, err
+	}
+	field, err := parser.expect(dslTokenIdent, "a field name")
+	if err != nil {
+		return Node{}, err
+	}
+	return Node{Function: FUNC_PAYLOAD}.AddChild(NewNodeConstant(field.text)), nil
+}
+
+func (parser *dslParser) parseDatabaseAccess() (Node, error) {
+	parser.advance()
+	names := make([]string, 0)
+	for parser.peek().kind == dslTokenDot || len(names) == 0 {
+		if _, err := parser.expect(dslTokenDot, `"." after db`); err != nil {
+			return Node{}, err
+		}
+		name, err := parser.expect(dslTokenIdent, "a table, link or field name")
+		if err != nil {
+			return Node{}, err
+		}
+		names = append(names, name.text)
+	}
+	if len(names) < 2 {
+		return Node{}, parser.unexpected(parser.peek(), `".field" after the table name`)
+	}
+
+	path := make([]any, 0, len(names)-2)
+	for _, link := range names[1 : len(names)-1] {
+		path = append(path, link)
+	}
+	return Node{Function: FUNC_DB_ACCESS}.
+		AddNamedChild(AttributeFuncDbAccess.ArgumentTableName, NewNodeConstant(names[0])).
+		AddNamedChild(AttributeFuncDbAccess.ArgumentFieldName, NewNodeConstant(names[len(names)-1])).
+		AddNamedChild(AttributeFuncDbAccess.ArgumentPathName, NewNodeConstant(path)), nil
+}
+
+func (parser *dslParser) parseCustomListAccess() (Node, error) {
+	parser.advance()
+	if _, err := parser.expect(dslTokenLeftParen, `"(" after list`); err != nil {
+		return Node{}, err
+	}
+	idToken, err := parser.expect(dslTokenString, "the id of the custom list as a string")
+	if err != nil {
+		return Node{}, err
+	}
+	id, err := unquoteDslString(idToken)
+	if err != nil {
+		return Node{}, err
+	}
+	if _, err := parser.expect(dslTokenRightParen, `")"`); err != nil {
+		return Node{}, err
+	}
+	return NewNodeCustomListAccess(id), nil
+}
+
+// parseConstant parses a literal value, with the types of a JSON document: numbers are float64
+func (parser *dslParser) parseConstant() (any, error) {
+	token := parser.advance()
+	switch token.kind {
+	case dslTokenNumber:
+		return parseDslNumber(token, "")
+	case dslTokenOperator:
+		if token.text == "-" && parser.peek().kind == dslTokenNumber {
+			return parseDslNumber(parser.advance(), "-")
+		}
+	case dslTokenString:
+		return unquoteDslString(token)
+	case dslTokenIdent:
+		switch token.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	case dslTokenLeftBracket:
+		if err := parser.enter(); err != nil {
+			return nil, err
+		}
+		defer parser.leave()
+		values := make([]any, 0)
+		for parser.peek().kind != dslTokenRightBracket {
+			value, err := parser.parseConstant()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if parser.peek().kind != dslTokenComma {
+				break
+			}
+			parser.advance()
+		}
+		if _, err := parser.expect(dslTokenRightBracket, `"," or "]"`); err != nil {
+			return nil, err
+		}
+		return values, nil
+	case dslTokenLeftBrace:
+		if err := parser.enter(); err != nil {
+			return nil, err
+		}
+		defer parser.leave()
+		values := make(map[string]any)
+		for parser.peek().kind != dslTokenRightBrace {
+			keyToken, err := parser.expect(dslTokenString, "a quoted key")
+			if err != nil {
+				return nil, err
+			}
+			key, err := unquoteDslString(keyToken)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := parser.expect(dslTokenColon, `":"`); err != nil {
+				return nil, err
+			}
+			value, err := parser.parseConstant()
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			if parser.peek().kind != dslTokenComma {
+				break
+			}
+			parser.advance()
+		}
+		if _, err := parser.expect(dslTokenRightBrace, `"," or "}"`); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	return nil, parser.unexpected(token, "a value")
+}
+
+func parseDslNumber(token dslToken, sign string) (float64, error) {
+	value, err := strconv.ParseFloat(sign+token.text, 64)
+	if err != nil {
+		return 0, DslSyntaxError{Position: token.position, Message: "invalid number " + token.text}
+	}
+	return value, nil
+}
@@ -0,0 +1,371 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Expressions longer than this are split on several lines by FormatDsl
+const DSL_LINE_WIDTH = 100
+
+const dslIndent = "    "
+
+// precedence of the expressions of the text syntax, the higher binds the tighter
+const (
+	dslPrecedenceOr = iota + 1
+	dslPrecedenceAnd
+	dslPrecedenceNot
+	dslPrecedenceComparison
+	dslPrecedenceAdditive
+	dslPrecedenceMultiplicative
+	dslPrecedencePrimary
+)
+
+var dslInfixOperators = map[Function]struct {
+	symbol     string
+	precedence int
+}{
+	FUNC_EQUAL:            {"=", dslPrecedenceComparison},
+	FUNC_NOT_EQUAL:        {"!=", dslPrecedenceComparison},
+	FUNC_GREATER:          {">", dslPrecedenceComparison},
+	FUNC_GREATER_OR_EQUAL: {">=", dslPrecedenceComparison},
+	FUNC_LESS:             {"<", dslPrecedenceComparison},
+	FUNC_LESS_OR_EQUAL:    {"<=", dslPrecedenceComparison},
+	FUNC_ADD:              {"+", dslPrecedenceAdditive},
+	FUNC_SUBTRACT:         {"-", dslPrecedenceAdditive},
+	FUNC_MULTIPLY:         {"*", dslPrecedenceMultiplicative},
+	FUNC_DIVIDE:           {"/", dslPrecedenceMultiplicative},
+}
+
+// FormatDsl writes the node tree in the text syntax described in ast_dsl_parser.go. Parsing the result gives back an
+// equivalent tree, numbers being float64 as in a JSON document.
+func FormatDsl(node Node) (string, error) {
+	text, _, err := formatDslNode(node, "")
+	return text, err
+}
+
+// formatDslNode returns the text of the node and its precedence. Lines after the first one start with indent.
+func formatDslNode(node Node, indent string) (string, int, error) {
+	flat, precedence, err := formatDslNodeFlat(node)
+	if err != nil || len(indent)+len(flat) <= DSL_LINE_WIDTH {
+		return flat, precedence, err
+	}
+
+	switch {
+	case node.Function == FUNC_CONSTANT:
+		return flat, precedence, nil
+	case isDslLogical(node):
+		keyword, precedence := dslLogicalKeyword(node.Function)
+		parts := make([]string, len(node.Children))
+		for i, child := range node.Children {
+			text, err := formatDslOperand(child, indent, precedence, false)
+			if err != nil {
+				return "", 0, err
+			}
+			parts[i] = text
+		}
+		return strings.Join(parts, "\n"+indent+keyword+" "), precedence, nil
+	case node.Function == FUNC_NOT && isDslOperatorNode(node, 1):
+		operand, err := formatDslOperand(node.Children[0], indent, dslPrecedenceNot, true)
+		return "not " + operand, dslPrecedenceNot, err
+	case isDslInfix(node):
+		operator := dslInfixOperators[node.Function]
+		left, err := formatDslOperand(node.Children[0], indent, operator.precedence,
+			operator.precedence != dslPrecedenceComparison)
+		if err != nil {
+			return "", 0, err
+		}
+		right, err := formatDslOperand(node.Children[1], indent, operator.precedence, false)
+		return left + " " + operator.symbol + " " + right, operator.precedence, err
+	}
+
+	if isDslShorthand(node) {
+		return flat, precedence, nil
+	}
+	name, err := dslFunctionName(node.Function)
+	if err != nil {
+		return "", 0, err
+	}
+	arguments, err := formatDslArguments(node, func(child Node) (string, error) {
+		text, _, err := formatDslNode(child, indent+dslIndent)
+		return text, err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	var b strings.Builder
+	b.WriteString(name + "(\n")
+	for _, argument := range arguments {
+		b.WriteString(indent + dslIndent + argument + ",\n")
+	}
+	b.WriteString(indent + ")")
+	return b.String(), dslPrecedencePrimary, nil
+}
+
+// formatDslOperand formats an operand of an operator, in parentheses if it binds less tightly than the operator.
+// If allowEqual, an operand of the same precedence does not need parentheses.
+func formatDslOperand(node Node, indent string, precedence int, allowEqual bool) (string, error) {
+	text, operandPrecedence, err := formatDslNode(node, indent)
+	if err != nil {
+		return "", err
+	}
+	if operandPrecedence > precedence || allowEqual && operandPrecedence == precedence {
+		return text, nil
+	}
+	if !strings.Contains(text, "\n") && len(indent)+len(text)+2 <= DSL_LINE_WIDTH {
+		return "(" + text + ")", nil
+	}
+	text, _, err = formatDslNode(node, indent+dslIndent)
+	return "(\n" + indent + dslIndent + text + "\n" + indent + ")", err
+}
+
+func formatDslNodeFlat(node Node) (string, int, error) {
+	switch {
+	case node.Function == FUNC_CONSTANT:
+		if len(node.Children) > 0 || len(node.NamedChildren) > 0 {
+			return "", 0, fmt.Errorf("constant node with children cannot be formatted")
+		}
+		text, err := formatDslConstant(node.Constant)
+		return text, dslPrecedencePrimary, err
+	case node.Constant != nil:
+		return "", 0, fmt.Errorf("node with both a function and a constant cannot be formatted")
+	case isDslLogical(node):
+		keyword, precedence := dslLogicalKeyword(node.Function)
+		parts := make([]string, len(node.Children))
+		for i, child := range node.Children {
+			text, err := formatDslFlatOperand(child, precedence, false)
+			if err != nil {
+				return "", 0, err
+			}
+			parts[i] = text
+		}
+		return strings.Join(parts, " "+keyword+" "), precedence, nil
+	case node.Function == FUNC_NOT && isDslOperatorNode(node, 1):
+		operand, err := formatDslFlatOperand(node.Children[0], dslPrecedenceNot, true)
+		return "not " + operand, dslPrecedenceNot, err
+	case isDslInfix(node):
+		operator := dslInfixOperators[node.Function]
+		left, err := formatDslFlatOperand(node.Children[0], operator.precedence,
+			operator.precedence != dslPrecedenceComparison)
+		if err != nil {
+			return "", 0, err
+		}
+		right, err := formatDslFlatOperand(node.Children[1], operator.precedence, false)
+		return left + " " + operator.symbol + " " + right, operator.precedence, err
+	}
+
+	if text, ok := formatDslShorthand(node); ok {
+		return text, dslPrecedencePrimary, nil
+	}
+
+	name, err := dslFunctionName(node.Function)
+	if err != nil {
+		return "", 0, err
+	}
+	arguments, err := formatDslArguments(node, func(child Node) (string, error) {
+		text, _, err := formatDslNodeFlat(child)
+		return text, err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return name + "(" + strings.Join(arguments, ", ") + ")", dslPrecedencePrimary, nil
+}
+
+func formatDslFlatOperand(node Node, precedence int, allowEqual bool) (string, error) {
+	text, operandPrecedence, err := formatDslNodeFlat(node)
+	if err != nil {
+		return "", err
+	}
+	if operandPrecedence > precedence || allowEqual && operandPrecedence == precedence {
+		return text, nil
+	}
+	return "(" + text + ")", nil
+}
+
+// formatDslArguments formats the children, then the named children sorted by name
+func formatDslArguments(node Node, format func(Node) (string, error)) ([]string, error) {
+	arguments := make([]string, 0, len(node.Children)+len(node.NamedChildren))
+	for _, child := range node.Children {
+		text, err := format(child)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, text)
+	}
+	names := make([]string, 0, len(node.NamedChildren))
+	for name := range node.NamedChildren {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		text, err := format(node.NamedChildren[name])
+		if err != nil {
+			return nil, err
+		}
+		if !isDslIdent(name) {
+			name = strconv.Quote(name)
+		}
+		arguments = append(arguments, name+": "+text)
+	}
+	return arguments, nil
+}
+
+func isDslOperatorNode(node Node, nbChildren int) bool {
+	return len(node.Children) == nbChildren && len(node.NamedChildren) == 0
+}
+
+func isDslLogical(node Node) bool {
+	return (node.Function == FUNC_AND || node.Function == FUNC_OR) &&
+		len(node.Children) >= 2 && len(node.NamedChildren) == 0
+}
+
+func dslLogicalKeyword(f Function) (string, int) {
+	if f == FUNC_AND {
+		return "and", dslPrecedenceAnd
+	}
+	return "or", dslPrecedenceOr
+}
+
+func isDslInfix(node Node) bool {
+	_, ok := dslInfixOperators[node.Function]
+	return ok && isDslOperatorNode(node, 2)
+}
+
+func dslFunctionName(f Function) (string, error) {
+	attributes, err := f.Attributes()
+	if err != nil {
+		return "", err
+	}
+	if f == FUNC_CONSTANT {
+		return "", fmt.Errorf("constant has no function name")
+	}
+	if isDslIdent(attributes.AstName) {
+		return attributes.AstName, nil
+	}
+	return strconv.Quote(attributes.AstName), nil
+}
+
+func isDslShorthand(node Node) bool {
+	_, ok := formatDslShorthand(node)
+	return ok
+}
+
+// formatDslShorthand writes payload and database accesses as paths, and custom lists as list("id")
+func formatDslShorthand(node Node) (string, bool) {
+	isConstant := func(n Node) bool {
+		return n.Function == FUNC_CONSTANT && len(n.Children) == 0 && len(n.NamedChildren) == 0
+	}
+	isPathName := func(n Node) (string, bool) {
+		name, ok := n.Constant.(string)
+		return name, ok && isConstant(n) && isDslIdent(name)
+	}
+
+	switch node.Function {
+	case FUNC_PAYLOAD:
+		if !isDslOperatorNode(node, 1) {
+			return "", false
+		}
+		if field, ok := isPathName(node.Children[0]); ok {
+			return "payload." + field, true
+		}
+	case FUNC_CUSTOM_LIST_ACCESS:
+		child, ok := node.NamedChildren[AttributeFuncCustomListAccess.ArgumentCustomListId]
+		id, isString := child.Constant.(string)
+		if ok && isString && isConstant(child) && len(node.Children) == 0 && len(node.NamedChildren) == 1 {
+			return "list(" + strconv.Quote(id) + ")", true
+		}
+	case FUNC_DB_ACCESS:
+		if len(node.Children) > 0 || len(node.NamedChildren) != 3 {
+			return "", false
+		}
+		table, tableOk := isPathName(node.NamedChildren[AttributeFuncDbAccess.ArgumentTableName])
+		field, fieldOk := isPathName(node.NamedChildren[AttributeFuncDbAccess.ArgumentFieldName])
+		pathNode, pathOk := node.NamedChildren[AttributeFuncDbAccess.ArgumentPathName]
+		if !tableOk || !fieldOk || !pathOk || !isConstant(pathNode) {
+			return "", false
+		}
+		names := []string{"db", table}
+		switch path := pathNode.Constant.(type) {
+		case []any:
+			for _, link := range path {
+				linkName, ok := link.(string)
+				if !ok || !isDslIdent(linkName) {
+					return "", false
+				}
+				names = append(names, linkName)
+			}
+		case []string:
+			for _, linkName := range path {
+				if !isDslIdent(linkName) {
+					return "", false
+				}
+				names = append(names, linkName)
+			}
+		default:
+			return "", false
+		}
+		return strings.Join(append(names, field), "."), true
+	}
+	return "", false
+}
+
+func formatDslConstant(value any) (string, error) {
+	if value == nil {
+		return "null", nil
+	}
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("number %v cannot be formatted", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return formatDslConstant(float64(v))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for i := range items {
+			item, err := formatDslConstant(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return "", fmt.Errorf("constant of type %T cannot be formatted", value)
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		slices.Sort(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			item, err := formatDslConstant(rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = strconv.Quote(key) + ": " + item
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("constant of type %T cannot be formatted", value)
+}
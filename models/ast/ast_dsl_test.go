@@ -0,0 +1,199 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDsl(t *testing.T) {
+	node, err := ParseDsl(`payload.amount > 1000 and IsInList(payload.country, list("high_risk"))`)
+	require.NoError(t, err)
+
+	expected := Node{Function: FUNC_AND}.
+		AddChild(Node{Function: FUNC_GREATER}.
+			AddChild(Node{Function: FUNC_PAYLOAD}.AddChild(NewNodeConstant("amount"))).
+			AddChild(NewNodeConstant(1000.0))).
+		AddChild(Node{Function: FUNC_IS_IN_LIST}.
+			AddChild(Node{Function: FUNC_PAYLOAD}.AddChild(NewNodeConstant("country"))).
+			AddChild(NewNodeCustomListAccess("high_risk")))
+	assert.Equal(t, expected, node)
+}
+
+func TestParseDsl_precedence(t *testing.T) {
+	node, err := ParseDsl(`1 + 2 * 3 - 4 = 3 or not true and false`)
+	require.NoError(t, err)
+
+	text, err := FormatDsl(node)
+	require.NoError(t, err)
+	assert.Equal(t, `1 + 2 * 3 - 4 = 3 or not true and false`, text)
+
+	assert.Equal(t, FUNC_OR, node.Function)
+	assert.Equal(t, FUNC_EQUAL, node.Children[0].Function)
+	subtraction := node.Children[0].Children[0]
+	assert.Equal(t, FUNC_SUBTRACT, subtraction.Function)
+	assert.Equal(t, FUNC_ADD, subtraction.Children[0].Function)
+	assert.Equal(t, FUNC_AND, node.Children[1].Function)
+	assert.Equal(t, FUNC_NOT, node.Children[1].Children[0].Function)
+}
+
+func TestParseDsl_database_access_and_constants(t *testing.T) {
+	node, err := ParseDsl(`db.transactions.account.company.name = ["a", -1.5, {"k": null}]`)
+	require.NoError(t, err)
+
+	dbAccess := node.Children[0]
+	assert.Equal(t, FUNC_DB_ACCESS, dbAccess.Function)
+	assert.Equal(t, "transactions", dbAccess.NamedChildren["tableName"].Constant)
+	assert.Equal(t, "name", dbAccess.NamedChildren["fieldName"].Constant)
+	assert.Equal(t, []any{"account", "company"}, dbAccess.NamedChildren["path"].Constant)
+	assert.Equal(t, []any{"a", -1.5, map[string]any{"k": nil}}, node.Children[1].Constant)
+}
+
+func TestParseDsl_generic_call(t *testing.T) {
+	node, err := ParseDsl(`TimeAdd(TimeNow(), duration: "P1D", sign: "-",) > ">"(1, 2, 3)`)
+	require.NoError(t, err)
+
+	timeAdd := node.Children[0]
+	assert.Equal(t, FUNC_TIME_ADD, timeAdd.Function)
+	assert.Len(t, timeAdd.Children, 1)
+	assert.Equal(t, "P1D", timeAdd.NamedChildren["duration"].Constant)
+	assert.Equal(t, FUNC_GREATER, node.Children[1].Function)
+	assert.Len(t, node.Children[1].Children, 3)
+}
+
+func TestParseDsl_errors(t *testing.T) {
+	tests := []struct {
+		input   string
+		line    int
+		column  int
+		message string
+	}{
+		{`payload.amount >`, 1, 17, "expected a value, found end of formula"},
+		{"payload.amount > 1\n  and foo", 2, 7, `unknown identifier "foo"`},
+		{`1 < 2 < 3`, 1, 7, "comparisons cannot be chained"},
+		{`Unknown(1)`, 1, 1, `unknown function "Unknown"`},
+		{`"abc`, 1, 1, "unterminated string"},
+		{`IsInList(a: 1, 2)`, 1, 16, "positional arguments must come before named arguments"},
+		{`(1 + 2`, 1, 7, `expected ")"`},
+		{`1 $ 2`, 1, 3, `unexpected character '$'`},
+		{`- payload.a`, 1, 1, "unary minus"},
+		{`db.transactions`, 1, 16, "after the table name"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseDsl(test.input)
+			var syntaxErr DslSyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, test.line, syntaxErr.Position.Line)
+			assert.Equal(t, test.column, syntaxErr.Position.Column)
+			assert.Contains(t, syntaxErr.Message, test.message)
+		})
+	}
+}
+
+func TestParseDsl_nesting_limit(t *testing.T) {
+	nested := func(open, value, close string, depth int) string {
+		return strings.Repeat(open, depth) + value + strings.Repeat(close, depth)
+	}
+
+	_, err := ParseDsl(nested("(", "1", ")", dslMaxDepth-1))
+	assert.NoError(t, err)
+
+	for _, input := range []string{
+		nested("(", "1", ")", 10_000),
+		strings.Repeat("not ", 10_000) + "true",
+		nested("IsInList(1, ", "[]", ")", 1_000),
+		nested("[", "1", "]", 10_000),
+		nested(`{"a": `, "1", "}", 10_000),
+	} {
+		_, err := ParseDsl(input)
+		var syntaxErr DslSyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Contains(t, syntaxErr.Message, "nested more than")
+	}
+}
+
+func TestFormatDsl_round_trip(t *testing.T) {
+	inputs := []string{
+		`payload.amount > 1000 and IsInList(payload.country, list("high_risk"))`,
+		`(1 + 2) * 3`,
+		`1 - (2 - 3)`,
+		`not (payload.a = 1 or payload.b = 2)`,
+		`(payload.a or payload.b) and payload.c`,
+		`payload.a and (payload.b and payload.c)`,
+		`(1 < 2) = true`,
+		`Aggregator(aggregator: "COUNT", fieldName: "id", filters: List(Filter(fieldName: "account_id", operator: "=", tableName: "transactions", value: payload.account_id)), label: "count", tableName: "transactions")`,
+		`">"(1) and Undefined()`,
+		`DatabaseAccess(fieldName: "field with spaces", path: [], tableName: "t")`,
+		`Payload("field-name") = "l\"quote"`,
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			node, err := ParseDsl(input)
+			require.NoError(t, err)
+			text, err := FormatDsl(node)
+			require.NoError(t, err)
+
+			reparsed, err := ParseDsl(text)
+			require.NoError(t, err, text)
+			assert.Equal(t, node, reparsed)
+			if !strings.Contains(text, "\n") {
+				assert.Equal(t, input, text)
+			}
+		})
+	}
+}
+
+func TestFormatDsl_multiline(t *testing.T) {
+	condition := `payload.transaction_amount_in_euros > 1000000 and payload.counterparty_country_code = "FR"`
+	node, err := ParseDsl(condition + ` and (payload.is_new_account = true or payload.account_age_in_days < 30)`)
+	require.NoError(t, err)
+
+	text, err := FormatDsl(node)
+	require.NoError(t, err)
+	assert.Equal(t, `payload.transaction_amount_in_euros > 1000000
+and payload.counterparty_country_code = "FR"
+and (payload.is_new_account = true or payload.account_age_in_days < 30)`, text)
+
+	reparsed, err := ParseDsl(text)
+	require.NoError(t, err)
+	assert.Equal(t, node, reparsed)
+}
+
+func TestFormatDsl_multiline_call(t *testing.T) {
+	node, err := ParseDsl(`Aggregator(aggregator: "COUNT", fieldName: "id", filters: List(Filter(fieldName: "account_id", ` +
+		`operator: "=", tableName: "transactions", value: payload.account_id)), label: "count", tableName: "transactions") > 3`)
+	require.NoError(t, err)
+
+	text, err := FormatDsl(node)
+	require.NoError(t, err)
+	assert.Equal(t, `Aggregator(
+    aggregator: "COUNT",
+    fieldName: "id",
+    filters: List(
+        Filter(
+            fieldName: "account_id",
+            operator: "=",
+            tableName: "transactions",
+            value: payload.account_id,
+        ),
+    ),
+    label: "count",
+    tableName: "transactions",
+) > 3`, text)
+}
+
+func TestFormatDsl_go_values(t *testing.T) {
+	node := Node{Function: FUNC_EQUAL}.
+		AddChild(NewNodeDatabaseAccess("transactions", "amount", []string{"account"})).
+		AddChild(NewNodeConstant(12))
+
+	text, err := FormatDsl(node)
+	require.NoError(t, err)
+	assert.Equal(t, `db.transactions.account.amount = 12`, text)
+
+	_, err = FormatDsl(Node{Function: FUNC_UNKNOWN})
+	assert.Error(t, err)
+}
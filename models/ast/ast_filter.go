@@ -21,13 +21,18 @@ type Filter struct {
 	Value     any
 }
 
+const (
+	FilterArgumentTableName = "tableName"
+	FilterArgumentFieldName = "fieldName"
+)
+
 var FuncFilterAttributes = FuncAttributes{
 	DebugName:         "FUNC_FILTER",
 	AstName:           "Filter",
 	NumberOfArguments: 4,
 	NamedArguments: []string{
-		"tableName",
-		"fieldName",
+		FilterArgumentTableName,
+		FilterArgumentFieldName,
 		"operator",
 		"value",
 	},
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Asynchronous decisions are evaluated by a worker, without the timeout of synchronous decisions. The decision
+// stored once the evaluation succeeds has the same id as the asynchronous decision.
+// The timeout is shorter than the lease of the job (5 minutes), so that the evaluation is over before the job can
+// be claimed by another worker.
+const ASYNC_DECISION_TIMEOUT = 4 * time.Minute
+
+type AsyncDecisionStatus string
+
+const (
+	AsyncDecisionPending AsyncDecisionStatus = "pending"
+	AsyncDecisionSuccess AsyncDecisionStatus = "success"
+	AsyncDecisionFailure AsyncDecisionStatus = "failure"
+)
+
+type AsyncDecision struct {
+	Id                string
+	OrganizationId    string
+	ScenarioId        string
+	TriggerObjectType string
+	PayloadRaw        json.RawMessage
+	Status            AsyncDecisionStatus
+	Error             *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type AsyncDecisionWithResult struct {
+	AsyncDecision
+	// only set once the evaluation succeeded
+	Decision *DecisionWithRuleExecutions
+}
+
+type CreateAsyncDecisionInput struct {
+	OrganizationId     string
+	PayloadRaw         json.RawMessage
+	ScenarioId         string
+	TriggerObjectTable string
+}
+
+// AsyncDecisionErrorMessage returns the reason of the failure of an asynchronous decision shown to the client, in the
+// API and in the webhook event. It does not expose the details of the error, which are logged instead.
+func AsyncDecisionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrScenarioTriggerConditionAndTriggerObjectMismatch):
+		return "the trigger object does not match the trigger condition of the scenario"
+	case errors.Is(err, ErrScenarioHasNoLiveVersion):
+		return "the scenario has no live version"
+	case errors.Is(err, NotFoundError):
+		return "the scenario was not found"
+	case errors.Is(err, BadParameterError):
+		return "the trigger object is not valid for the data model"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the evaluation of the scenario timed out"
+	default:
+		return "the decision could not be evaluated"
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+)
+
+// Number of audit events read at once by an export
+const AUDIT_EVENTS_EXPORT_BATCH_SIZE = 1000
+
+type AuditOperation string
+
+const (
+	AuditOperationInsert AuditOperation = "INSERT"
+	AuditOperationUpdate AuditOperation = "UPDATE"
+	AuditOperationDelete AuditOperation = "DELETE"
+)
+
+// The tables whose changes are recorded by the audit triggers of the database
+var AUDITED_TABLES = []string{
+	"api_keys",
+	"custom_list_values",
+	"custom_roles",
+	"data_model_fields",
+	"data_model_links",
+	"data_model_pivots",
+	"data_model_tables",
+	"inbox_users",
+	"inboxes",
+	"rule_snoozes",
+	"scenario_iteration_rules",
+	"scenario_iterations",
+	"scenario_publications",
+	"scenarios",
+	"snooze_groups",
+	"users",
+	"webhooks",
+}
+
+// AuditEvent is a change of a row of an audited table, with the user or the API key who made it. Data is the row after
+// the change (before a deletion), PreviousData the row before an update.
+type AuditEvent struct {
+	Id             string
+	OrganizationId *string
+	Operation      AuditOperation
+	UserId         *string
+	ApiKeyId       *string
+	Table          string
+	EntityId       string
+	Data           json.RawMessage
+	PreviousData   json.RawMessage
+	CreatedAt      time.Time
+}
+
+type AuditEventFilters struct {
+	Tables     []string
+	Operations []AuditOperation
+	EntityId   string
+	UserId     string
+	ApiKeyId   string
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+func (filters AuditEventFilters) Validate() error {
+	for _, table := range filters.Tables {
+		if !slices.Contains(AUDITED_TABLES, table) {
+			return errors.Wrapf(BadParameterError, "table %s is not audited", table)
+		}
+	}
+	for _, operation := range filters.Operations {
+		switch operation {
+		case AuditOperationInsert, AuditOperationUpdate, AuditOperationDelete:
+		default:
+			return errors.Wrapf(BadParameterError, "invalid audit operation %s", operation)
+		}
+	}
+	if filters.EntityId != "" {
+		if _, err := uuid.Parse(filters.EntityId); err != nil {
+			return errors.Wrapf(BadParameterError, "entity id %s is not a valid UUID", filters.EntityId)
+		}
+	}
+	if !filters.StartDate.IsZero() && !filters.EndDate.IsZero() && filters.StartDate.After(filters.EndDate) {
+		return errors.Wrap(BadParameterError, "start date must be before end date")
+	}
+	return nil
+}
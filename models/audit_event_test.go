@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEventFilters_Validate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, AuditEventFilters{}.Validate())
+	assert.NoError(t, AuditEventFilters{
+		Tables:     []string{"scenarios", "api_keys"},
+		Operations: []AuditOperation{AuditOperationUpdate},
+		EntityId:   "25ab6323-1657-4a52-923a-ef6983fe4532",
+		StartDate:  now.Add(-time.Hour),
+		EndDate:    now,
+	}.Validate())
+
+	assert.ErrorIs(t, AuditEventFilters{Tables: []string{"decisions"}}.Validate(), BadParameterError)
+	assert.ErrorIs(t, AuditEventFilters{Operations: []AuditOperation{"TRUNCATE"}}.Validate(), BadParameterError)
+	assert.ErrorIs(t, AuditEventFilters{EntityId: "not-a-uuid"}.Validate(), BadParameterError)
+	assert.ErrorIs(t, AuditEventFilters{StartDate: now, EndDate: now.Add(-time.Hour)}.Validate(), BadParameterError)
+}
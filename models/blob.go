@@ -0,0 +1,38 @@
+package models
+
+import (
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+type Blob struct {
+	FileName   string
+	Reader     io.ReadCloser
+	BucketName string
+}
+
+// BlobStorageBackend is the storage used for the files handled by Marble: case files, CSV ingestion files and
+// transfer check enrichment files
+type BlobStorageBackend string
+
+const (
+	// Files are stored in Google Cloud Storage buckets
+	BlobStorageBackendGcs BlobStorageBackend = "gcs"
+	// Files are stored in S3 compatible buckets (AWS S3, MinIO...)
+	BlobStorageBackendS3 BlobStorageBackend = "s3"
+	// Files are stored on the local filesystem and downloaded through the backend, for self-hosted deployments
+	BlobStorageBackendLocal BlobStorageBackend = "local"
+)
+
+func BlobStorageBackendFrom(s string) (BlobStorageBackend, error) {
+	switch s {
+	case "", string(BlobStorageBackendGcs):
+		return BlobStorageBackendGcs, nil
+	case string(BlobStorageBackendS3):
+		return BlobStorageBackendS3, nil
+	case string(BlobStorageBackendLocal):
+		return BlobStorageBackendLocal, nil
+	}
+	return "", errors.Errorf("invalid blob storage backend: %s", s)
+}
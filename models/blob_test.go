@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobStorageBackendFrom(t *testing.T) {
+	backend, err := BlobStorageBackendFrom("")
+	assert.NoError(t, err)
+	assert.Equal(t, BlobStorageBackendGcs, backend)
+
+	backend, err = BlobStorageBackendFrom("local")
+	assert.NoError(t, err)
+	assert.Equal(t, BlobStorageBackendLocal, backend)
+
+	_, err = BlobStorageBackendFrom("azure")
+	assert.Error(t, err)
+}
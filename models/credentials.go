@@ -2,6 +2,8 @@ package models
 
 import (
 	"fmt"
+	"net/netip"
+	"slices"
 )
 
 type Identity struct {
@@ -9,6 +11,7 @@ type Identity struct {
 	Email      string
 	FirstName  string
 	LastName   string
+	ApiKeyId   string
 	ApiKeyName string
 }
 
@@ -17,6 +20,34 @@ type Credentials struct {
 	OrganizationId string
 	PartnerId      *string
 	Role           Role
+	// The permissions of the custom role of the actor, if any, replace the permissions of their role
+	CustomRoleId          *string
+	CustomRolePermissions []Permission
+	// The restrictions of the API key of the actor, if any
+	ApiKeyScope     ApiKeyScope
+	AllowedNetworks []netip.Prefix
+}
+
+func (c Credentials) Permissions() []Permission {
+	if c.CustomRoleId != nil {
+		return c.CustomRolePermissions
+	}
+	return c.Role.Permissions()
+}
+
+func (c Credentials) HasPermission(permission Permission) bool {
+	return slices.Contains(c.Permissions(), permission)
+}
+
+func (c Credentials) WithCustomRole(customRole CustomRole) Credentials {
+	c.CustomRoleId = &customRole.Id
+	c.CustomRolePermissions = customRole.Permissions
+	return c
+}
+
+// AllowsIp tells if the request can come from this IP, which the networks of the API key of the actor may restrict
+func (c Credentials) AllowsIp(ip string) bool {
+	return NetworksAllowIp(c.AllowedNetworks, ip)
 }
 
 func (c Credentials) ActorIdentityDescription() string {
@@ -37,13 +68,16 @@ func NewCredentialWithUser(user User) Credentials {
 	}
 }
 
-func NewCredentialWithApiKey(organizationId string, partnerId *string, role Role, apiKeyName string) Credentials {
+func NewCredentialWithApiKey(apiKey ApiKey, apiKeyName string) Credentials {
 	return Credentials{
 		ActorIdentity: Identity{
+			ApiKeyId:   apiKey.Id,
 			ApiKeyName: apiKeyName,
 		},
-		OrganizationId: organizationId,
-		PartnerId:      partnerId,
-		Role:           role,
+		OrganizationId:  apiKey.OrganizationId,
+		PartnerId:       apiKey.PartnerId,
+		Role:            apiKey.Role,
+		ApiKeyScope:     apiKey.Scope,
+		AllowedNetworks: apiKey.AllowedNetworks,
 	}
 }
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Permissions that can be granted by a custom role: an organization cannot grant more than the permissions of its
+// admins
+var CUSTOM_ROLE_PERMISSIONS = func() []Permission {
+	permissions := slices.Clone(ADMIN_PERMISSIONS)
+	slices.Sort(permissions)
+	return slices.Compact(permissions)
+}()
+
+// CustomRole is a set of permissions defined by an organization. A user or an API key with a custom role has its
+// permissions instead of the permissions of their built-in role, which still decides of the rest of their rights
+// (e.g. inbox administration).
+type CustomRole struct {
+	Id             string
+	OrganizationId string
+	Name           string
+	Description    string
+	Permissions    []Permission
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type CreateCustomRoleInput struct {
+	OrganizationId string
+	Name           string
+	Description    string
+	Permissions    []Permission
+}
+
+type UpdateCustomRoleInput struct {
+	Id          string
+	Name        *string
+	Description *string
+	Permissions *[]Permission
+}
+
+func validateCustomRolePermissions(permissions []Permission) error {
+	for _, permission := range permissions {
+		if !slices.Contains(CUSTOM_ROLE_PERMISSIONS, permission) {
+			return errors.Wrapf(BadParameterError, "permission %s cannot be granted by a custom role", permission)
+		}
+	}
+	return nil
+}
+
+func (input CreateCustomRoleInput) Validate() error {
+	if input.Name == "" {
+		return errors.Wrap(BadParameterError, "name is required")
+	}
+	return validateCustomRolePermissions(input.Permissions)
+}
+
+func (input UpdateCustomRoleInput) Validate() error {
+	if input.Name != nil && *input.Name == "" {
+		return errors.Wrap(BadParameterError, "name cannot be empty")
+	}
+	if input.Permissions != nil {
+		return validateCustomRolePermissions(*input.Permissions)
+	}
+	return nil
+}
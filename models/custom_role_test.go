@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionFromString(t *testing.T) {
+	for _, permission := range CUSTOM_ROLE_PERMISSIONS {
+		parsed, err := PermissionFromString(permission.String())
+		assert.NoError(t, err)
+		assert.Equal(t, permission, parsed)
+	}
+
+	_, err := PermissionFromString("NOT_A_PERMISSION")
+	assert.ErrorIs(t, err, BadParameterError)
+}
+
+func TestCreateCustomRoleInput_Validate(t *testing.T) {
+	input := CreateCustomRoleInput{
+		OrganizationId: "organization_id",
+		Name:           "Analyst",
+		Permissions:    []Permission{CASE_READ_WRITE, DECISION_READ},
+	}
+	assert.NoError(t, input.Validate())
+
+	input.Name = ""
+	assert.ErrorIs(t, input.Validate(), BadParameterError)
+
+	input.Name = "Analyst"
+	input.Permissions = []Permission{ORGANIZATIONS_CREATE}
+	assert.ErrorIs(t, input.Validate(), BadParameterError)
+}
+
+func TestUpdateCustomRoleInput_Validate(t *testing.T) {
+	assert.NoError(t, UpdateCustomRoleInput{Id: "id"}.Validate())
+
+	empty := ""
+	assert.ErrorIs(t, UpdateCustomRoleInput{Id: "id", Name: &empty}.Validate(), BadParameterError)
+
+	permissions := []Permission{ORGANIZATIONS_DELETE}
+	assert.ErrorIs(t, UpdateCustomRoleInput{Id: "id", Permissions: &permissions}.Validate(), BadParameterError)
+}
+
+func TestCredentials_Permissions(t *testing.T) {
+	credentials := Credentials{OrganizationId: "organization_id", Role: ADMIN}
+	assert.Equal(t, ADMIN.Permissions(), credentials.Permissions())
+	assert.True(t, credentials.HasPermission(MARBLE_USER_CREATE))
+
+	credentials = credentials.WithCustomRole(CustomRole{
+		Id:          "custom_role_id",
+		Permissions: []Permission{DECISION_READ},
+	})
+	assert.Equal(t, []Permission{DECISION_READ}, credentials.Permissions())
+	assert.True(t, credentials.HasPermission(DECISION_READ))
+	assert.False(t, credentials.HasPermission(MARBLE_USER_CREATE))
+}
@@ -123,6 +123,9 @@ func (t Table) Copy() Table {
 	return out
 }
 
+// Fields created with every table of the data model, which cannot be deleted
+var DATA_MODEL_DEFAULT_FIELDS = []string{"object_id", "updated_at"}
+
 type TableMetadata struct {
 	ID             string
 	Description    string
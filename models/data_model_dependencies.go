@@ -0,0 +1,214 @@
+package models
+
+import (
+	"slices"
+)
+
+type DataModelDependencyKind string
+
+const (
+	// The scenario is triggered by objects of the table
+	DataModelDependencyScenario DataModelDependencyKind = "scenario"
+	// The formulas of the scenario iteration read the table, the field or follow the link
+	DataModelDependencyScenarioIteration DataModelDependencyKind = "scenario_iteration"
+	DataModelDependencyLink              DataModelDependencyKind = "link"
+	DataModelDependencyPivot             DataModelDependencyKind = "pivot"
+)
+
+// DataModelDependency is an element of the organization which uses an element of the data model, and prevents its
+// deletion
+type DataModelDependency struct {
+	Kind       DataModelDependencyKind
+	Id         string
+	Name       string
+	ScenarioId string
+	Version    *int
+}
+
+// DataModelUsage lists the tables, fields and links of the data model used by an element of the organization
+type DataModelUsage struct {
+	Dependency DataModelDependency
+	TableIds   []string
+	FieldIds   []string
+	LinkIds    []string
+}
+
+func (usage DataModelUsage) uses(tableIds, fieldIds, linkIds []string) bool {
+	intersects := func(a, b []string) bool {
+		return slices.ContainsFunc(a, func(id string) bool { return slices.Contains(b, id) })
+	}
+	return intersects(usage.TableIds, tableIds) || intersects(usage.FieldIds, fieldIds) ||
+		intersects(usage.LinkIds, linkIds)
+}
+
+type dataModelUsageBuilder struct {
+	dataModel DataModel
+	usage     DataModelUsage
+}
+
+func (builder *dataModelUsageBuilder) addTable(id string) {
+	if id != "" && !slices.Contains(builder.usage.TableIds, id) {
+		builder.usage.TableIds = append(builder.usage.TableIds, id)
+	}
+}
+
+func (builder *dataModelUsageBuilder) addField(id string) {
+	if id != "" && !slices.Contains(builder.usage.FieldIds, id) {
+		builder.usage.FieldIds = append(builder.usage.FieldIds, id)
+	}
+}
+
+func (builder *dataModelUsageBuilder) addLink(id string) {
+	if id != "" && !slices.Contains(builder.usage.LinkIds, id) {
+		builder.usage.LinkIds = append(builder.usage.LinkIds, id)
+	}
+}
+
+// addFieldRequirement resolves a field read by a formula: the tables and links of its path, and the field itself.
+// The references to elements missing from the data model are ignored, they cannot prevent a deletion.
+func (builder *dataModelUsageBuilder) addFieldRequirement(requirement ScenarioBundleFieldRequirement) {
+	table, ok := builder.dataModel.Tables[requirement.TableName]
+	if !ok {
+		return
+	}
+	builder.addTable(table.ID)
+	for _, linkName := range requirement.Path {
+		link, ok := table.LinksToSingle[linkName]
+		if !ok {
+			return
+		}
+		builder.addLink(link.Id)
+		if table, ok = builder.dataModel.Tables[link.ParentTableName]; !ok {
+			return
+		}
+		builder.addTable(table.ID)
+	}
+	if field, ok := table.Fields[requirement.FieldName]; ok {
+		builder.addField(field.ID)
+	}
+}
+
+// DataModelUsages lists the usages of the data model by the scenarios and their iterations, the pivots and the links
+func DataModelUsages(dataModel DataModel, pivots []Pivot, scenarios []Scenario,
+	iterations []ScenarioIteration,
+) []DataModelUsage {
+	usages := make([]DataModelUsage, 0)
+	add := func(builder dataModelUsageBuilder) {
+		if len(builder.usage.TableIds)+len(builder.usage.FieldIds)+len(builder.usage.LinkIds) > 0 {
+			usages = append(usages, builder.usage)
+		}
+	}
+
+	scenariosById := make(map[string]Scenario, len(scenarios))
+	for _, scenario := range scenarios {
+		scenariosById[scenario.Id] = scenario
+		builder := dataModelUsageBuilder{dataModel: dataModel, usage: DataModelUsage{Dependency: DataModelDependency{
+			Kind:       DataModelDependencyScenario,
+			Id:         scenario.Id,
+			Name:       scenario.Name,
+			ScenarioId: scenario.Id,
+		}}}
+		builder.addTable(dataModel.Tables[scenario.TriggerObjectType].ID)
+		add(builder)
+	}
+
+	for _, iteration := range iterations {
+		scenario := scenariosById[iteration.ScenarioId]
+		builder := dataModelUsageBuilder{dataModel: dataModel, usage: DataModelUsage{Dependency: DataModelDependency{
+			Kind:       DataModelDependencyScenarioIteration,
+			Id:         iteration.Id,
+			Name:       scenario.Name,
+			ScenarioId: iteration.ScenarioId,
+			Version:    iteration.Version,
+		}}}
+		references := CollectScenarioAstReferences(scenario.TriggerObjectType,
+			[]ScenarioBundleIteration{NewScenarioBundleIteration(iteration)})
+		for _, field := range references.Fields {
+			builder.addFieldRequirement(field)
+		}
+		add(builder)
+	}
+
+	for _, pivot := range pivots {
+		builder := dataModelUsageBuilder{dataModel: dataModel, usage: DataModelUsage{Dependency: DataModelDependency{
+			Kind: DataModelDependencyPivot,
+			Id:   pivot.Id,
+			Name: pivot.BaseTable,
+		}}}
+		builder.addTable(pivot.BaseTableId)
+		builder.addTable(pivot.PivotTableId)
+		builder.addField(pivot.FieldId)
+		for _, linkId := range pivot.PathLinkIds {
+			builder.addLink(linkId)
+		}
+		add(builder)
+	}
+
+	for _, table := range dataModel.Tables {
+		for _, link := range table.LinksToSingle {
+			builder := dataModelUsageBuilder{dataModel: dataModel, usage: DataModelUsage{Dependency: DataModelDependency{
+				Kind: DataModelDependencyLink,
+				Id:   link.Id,
+				Name: link.Name,
+			}}}
+			builder.addTable(link.ChildTableId)
+			builder.addField(link.ChildFieldId)
+			builder.addTable(link.ParentTableId)
+			builder.addField(link.ParentFieldId)
+			add(builder)
+		}
+	}
+
+	return usages
+}
+
+func dependenciesOf(usages []DataModelUsage, tableIds, fieldIds, linkIds []string,
+	owned func(DataModelDependency) bool,
+) []DataModelDependency {
+	dependencies := make([]DataModelDependency, 0)
+	for _, usage := range usages {
+		if owned != nil && owned(usage.Dependency) {
+			continue
+		}
+		if usage.uses(tableIds, fieldIds, linkIds) {
+			dependencies = append(dependencies, usage.Dependency)
+		}
+	}
+	return dependencies
+}
+
+// FieldDependencies lists the elements using the field
+func FieldDependencies(usages []DataModelUsage, field Field) []DataModelDependency {
+	return dependenciesOf(usages, nil, []string{field.ID}, nil, nil)
+}
+
+// LinkDependencies lists the elements following the link
+func LinkDependencies(usages []DataModelUsage, link LinkToSingle) []DataModelDependency {
+	return dependenciesOf(usages, nil, nil, []string{link.Id}, func(dependency DataModelDependency) bool {
+		return dependency.Kind == DataModelDependencyLink && dependency.Id == link.Id
+	})
+}
+
+// TableDependencies lists the elements using the table, its fields or its links. The links of the table and its pivot
+// are deleted with the table: they are not dependencies, unless they are used themselves.
+func TableDependencies(usages []DataModelUsage, table Table) []DataModelDependency {
+	fieldIds := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		fieldIds = append(fieldIds, field.ID)
+	}
+	linkIds := make([]string, 0, len(table.LinksToSingle))
+	for _, link := range table.LinksToSingle {
+		linkIds = append(linkIds, link.Id)
+	}
+	pivotIds := make([]string, 0, 1)
+	for _, usage := range usages {
+		if usage.Dependency.Kind == DataModelDependencyPivot && usage.Dependency.Name == table.Name {
+			pivotIds = append(pivotIds, usage.Dependency.Id)
+		}
+	}
+
+	return dependenciesOf(usages, []string{table.ID}, fieldIds, linkIds, func(dependency DataModelDependency) bool {
+		return (dependency.Kind == DataModelDependencyLink && slices.Contains(linkIds, dependency.Id)) ||
+			(dependency.Kind == DataModelDependencyPivot && slices.Contains(pivotIds, dependency.Id))
+	})
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+func dependenciesTestDataModel() DataModel {
+	link := LinkToSingle{
+		Id: "link_id", Name: "account",
+		ChildTableName: "transactions", ChildTableId: "transactions_id", ChildFieldName: "account_id", ChildFieldId: "account_id_id",
+		ParentTableName: "accounts", ParentTableId: "accounts_id", ParentFieldName: "object_id", ParentFieldId: "accounts_object_id",
+	}
+	return DataModel{Tables: map[string]Table{
+		"transactions": {
+			ID:   "transactions_id",
+			Name: "transactions",
+			Fields: map[string]Field{
+				"object_id":  {ID: "transactions_object_id", Name: "object_id"},
+				"account_id": {ID: "account_id_id", Name: "account_id"},
+			},
+			LinksToSingle: map[string]LinkToSingle{"account": link},
+		},
+		"accounts": {
+			ID:   "accounts_id",
+			Name: "accounts",
+			Fields: map[string]Field{
+				"object_id": {ID: "accounts_object_id", Name: "object_id"},
+				"name":      {ID: "name_id", Name: "name"},
+			},
+			LinksToSingle: map[string]LinkToSingle{},
+		},
+	}}
+}
+
+func TestDataModelDependencies(t *testing.T) {
+	dataModel := dependenciesTestDataModel()
+	formula := ast.NewNodeDatabaseAccess("transactions", "name", []string{"account"})
+	scenarios := []Scenario{{Id: "scenario_id", Name: "scenario", TriggerObjectType: "transactions"}}
+	iterations := []ScenarioIteration{{
+		Id:         "iteration_id",
+		ScenarioId: "scenario_id",
+		Rules:      []Rule{{FormulaAstExpression: &formula}},
+	}}
+	pivots := []Pivot{{Id: "pivot_id", BaseTable: "accounts", BaseTableId: "accounts_id", PivotTableId: "accounts_id", FieldId: "accounts_object_id"}}
+	usages := DataModelUsages(dataModel, pivots, scenarios, iterations)
+
+	iteration := DataModelDependency{Kind: DataModelDependencyScenarioIteration, Id: "iteration_id", Name: "scenario", ScenarioId: "scenario_id"}
+	assert.Equal(t, []DataModelDependency{iteration},
+		FieldDependencies(usages, dataModel.Tables["accounts"].Fields["name"]))
+	assert.Equal(t, []DataModelDependency{iteration},
+		LinkDependencies(usages, dataModel.Tables["transactions"].LinksToSingle["account"]))
+
+	// the pivot of the accounts table is deleted with it, the link to it and the iteration reading it are not
+	assert.Equal(t, []DataModelDependency{
+		iteration,
+		{Kind: DataModelDependencyLink, Id: "link_id", Name: "account"},
+	}, TableDependencies(usages, dataModel.Tables["accounts"]))
+
+	assert.Empty(t, FieldDependencies(DataModelUsages(dataModel, nil, scenarios, nil),
+		dataModel.Tables["accounts"].Fields["name"]))
+}
@@ -30,6 +30,10 @@ type Decision struct {
 	Score                int
 	ScheduledExecutionId *string
 	ScenarioIterationId  string
+	// values of the output variables of the scenario iteration, by name
+	OutputValues map[string]any
+	// verdict of an analyst on the decision, nil until it is reviewed
+	Review *DecisionReview
 }
 
 type DecisionCore struct {
@@ -62,6 +66,7 @@ type ScenarioExecution struct {
 	Score               int
 	Outcome             Outcome
 	OrganizationId      string
+	OutputValues        map[string]any
 }
 
 type RuleExecution struct {
@@ -90,6 +95,7 @@ func AdaptScenarExecToDecision(scenarioExecution ScenarioExecution, clientObject
 			ScenarioVersion:      scenarioExecution.ScenarioVersion,
 			ScheduledExecutionId: scheduledExecutionId,
 			Score:                scenarioExecution.Score,
+			OutputValues:         scenarioExecution.OutputValues,
 		},
 		RuleExecutions: scenarioExecution.RuleExecutions,
 	}
@@ -102,12 +108,26 @@ type CreateDecisionInput struct {
 	ClientObject       *ClientObject
 	ScenarioId         string
 	TriggerObjectTable string
+	// optional, only supported with PayloadRaw
+	IdempotencyKey string
 }
 
 type CreateAllDecisionsInput struct {
 	OrganizationId     string
 	PayloadRaw         json.RawMessage
 	TriggerObjectTable string
+	// optional
+	IdempotencyKey string
+}
+
+// Dry run decisions are evaluated against the ingested data, but they are not stored
+type DryRunDecisionInput struct {
+	OrganizationId     string
+	PayloadRaw         json.RawMessage
+	ScenarioId         string
+	TriggerObjectTable string
+	// optional, the iteration to evaluate instead of the live version of the scenario
+	ScenarioIterationId *string
 }
 
 type DecisionFilters struct {
@@ -120,6 +140,8 @@ type DecisionFilters struct {
 	ScheduledExecutionIds []string
 	StartDate             time.Time
 	TriggerObjects        []string
+	ReviewDispositions    []DecisionReviewDisposition
+	IsReviewed            *bool
 }
 
 const (
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+// A decision policy defines how the scenarios of a trigger object type are combined into a single decision: which
+// scenarios run and in what order, whether a rejection stops the evaluation, and how the outcomes are aggregated.
+type DecisionPolicy struct {
+	Id                string
+	OrganizationId    string
+	TriggerObjectType string
+	ScenarioIds       []string
+	StopOnReject      bool
+	Aggregation       DecisionPolicyAggregation
+	// only used by the score sum aggregation
+	ScoreReviewThreshold *int
+	ScoreRejectThreshold *int
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type DecisionPolicyAggregation string
+
+const (
+	// the final outcome is the most severe outcome of the decisions
+	DecisionPolicyAggregationWorstOutcome DecisionPolicyAggregation = "worst_outcome"
+	// the final outcome is computed from the sum of the scores of the decisions, using the thresholds of the policy
+	DecisionPolicyAggregationScoreSum DecisionPolicyAggregation = "score_sum"
+)
+
+var validDecisionPolicyAggregations = []DecisionPolicyAggregation{
+	DecisionPolicyAggregationWorstOutcome,
+	DecisionPolicyAggregationScoreSum,
+}
+
+type CreateDecisionPolicyInput struct {
+	OrganizationId       string
+	TriggerObjectType    string
+	ScenarioIds          []string
+	StopOnReject         bool
+	Aggregation          DecisionPolicyAggregation
+	ScoreReviewThreshold *int
+	ScoreRejectThreshold *int
+}
+
+type UpdateDecisionPolicyInput struct {
+	Id           string
+	ScenarioIds  *[]string
+	StopOnReject *bool
+	Aggregation  *DecisionPolicyAggregation
+	// the thresholds can be cleared, e.g. when switching to the worst outcome aggregation
+	ScoreReviewThreshold pure_utils.Null[int]
+	ScoreRejectThreshold pure_utils.Null[int]
+}
+
+// MergeUpdate returns the policy with the fields of the update applied
+func (policy DecisionPolicy) MergeUpdate(input UpdateDecisionPolicyInput) DecisionPolicy {
+	if input.ScenarioIds != nil {
+		policy.ScenarioIds = *input.ScenarioIds
+	}
+	if input.StopOnReject != nil {
+		policy.StopOnReject = *input.StopOnReject
+	}
+	if input.Aggregation != nil {
+		policy.Aggregation = *input.Aggregation
+	}
+	if input.ScoreReviewThreshold.Set {
+		policy.ScoreReviewThreshold = input.ScoreReviewThreshold.Ptr()
+	}
+	if input.ScoreRejectThreshold.Set {
+		policy.ScoreRejectThreshold = input.ScoreRejectThreshold.Ptr()
+	}
+	return policy
+}
+
+func (policy DecisionPolicy) Validate() error {
+	if policy.TriggerObjectType == "" {
+		return errors.Wrap(BadParameterError, "trigger object type is required")
+	}
+	if len(policy.ScenarioIds) == 0 {
+		return errors.Wrap(BadParameterError, "a decision policy must run at least one scenario")
+	}
+	seen := make(map[string]bool, len(policy.ScenarioIds))
+	for _, scenarioId := range policy.ScenarioIds {
+		if seen[scenarioId] {
+			return errors.Wrapf(BadParameterError, "scenario %s is present twice in the decision policy", scenarioId)
+		}
+		seen[scenarioId] = true
+	}
+	if !slices.Contains(validDecisionPolicyAggregations, policy.Aggregation) {
+		return errors.Wrapf(BadParameterError, "invalid decision policy aggregation %s", policy.Aggregation)
+	}
+	if policy.Aggregation == DecisionPolicyAggregationScoreSum {
+		if policy.ScoreReviewThreshold == nil || policy.ScoreRejectThreshold == nil {
+			return errors.Wrap(BadParameterError, "the score_sum aggregation requires score thresholds")
+		}
+		if *policy.ScoreRejectThreshold < *policy.ScoreReviewThreshold {
+			return errors.Wrap(BadParameterError, "score reject threshold must be greater than score review threshold")
+		}
+	}
+	return nil
+}
+
+// Aggregate computes the final outcome and score of the decisions taken by the policy. The score is the sum of the
+// scores of the decisions. If no decision was taken, the outcome is None.
+func (policy DecisionPolicy) Aggregate(decisions []Decision) (Outcome, int) {
+	if len(decisions) == 0 {
+		return None, 0
+	}
+
+	score := 0
+	worstOutcome := Approve
+	for _, decision := range decisions {
+		score += decision.Score
+		if decision.Outcome > worstOutcome && slices.Contains(ValidOutcomes, decision.Outcome) {
+			worstOutcome = decision.Outcome
+		}
+	}
+
+	if policy.Aggregation == DecisionPolicyAggregationScoreSum &&
+		policy.ScoreReviewThreshold != nil && policy.ScoreRejectThreshold != nil {
+		switch {
+		case score < *policy.ScoreReviewThreshold:
+			return Approve, score
+		case score < *policy.ScoreRejectThreshold:
+			return Review, score
+		default:
+			return Reject, score
+		}
+	}
+	return worstOutcome, score
+}
+
+// A policy decision is the parent record of the decisions taken when running a decision policy
+type PolicyDecision struct {
+	Id                 string
+	OrganizationId     string
+	DecisionPolicyId   *string
+	TriggerObjectType  string
+	Outcome            Outcome
+	Score              int
+	DecisionIds        []string
+	SkippedScenarioIds []string
+	// true if a rejection stopped the evaluation of the remaining scenarios
+	StoppedEarly bool
+	CreatedAt    time.Time
+}
+
+type PolicyDecisionWithDecisions struct {
+	PolicyDecision
+	Decisions []DecisionWithRuleExecutions
+}
+
+type CreatePolicyDecisionInput struct {
+	OrganizationId     string
+	PayloadRaw         json.RawMessage
+	TriggerObjectTable string
+}
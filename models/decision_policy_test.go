@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/pure_utils"
+)
+
+func TestDecisionPolicyValidate(t *testing.T) {
+	valid := DecisionPolicy{
+		TriggerObjectType: "transactions",
+		ScenarioIds:       []string{"a", "b"},
+		Aggregation:       DecisionPolicyAggregationWorstOutcome,
+	}
+	assert.NoError(t, valid.Validate())
+
+	noScenario := valid
+	noScenario.ScenarioIds = nil
+	assert.ErrorIs(t, noScenario.Validate(), BadParameterError)
+
+	duplicate := valid
+	duplicate.ScenarioIds = []string{"a", "a"}
+	assert.ErrorIs(t, duplicate.Validate(), BadParameterError)
+
+	unknownAggregation := valid
+	unknownAggregation.Aggregation = "average"
+	assert.ErrorIs(t, unknownAggregation.Validate(), BadParameterError)
+
+	scoreSumWithoutThresholds := valid
+	scoreSumWithoutThresholds.Aggregation = DecisionPolicyAggregationScoreSum
+	assert.ErrorIs(t, scoreSumWithoutThresholds.Validate(), BadParameterError)
+
+	scoreSum := scoreSumWithoutThresholds
+	scoreSum.ScoreReviewThreshold = ptr(10)
+	scoreSum.ScoreRejectThreshold = ptr(20)
+	assert.NoError(t, scoreSum.Validate())
+}
+
+func TestDecisionPolicyAggregate(t *testing.T) {
+	decisions := []Decision{
+		{Outcome: Approve, Score: 5},
+		{Outcome: Review, Score: 12},
+		{Outcome: Approve, Score: 0},
+	}
+
+	t.Run("no decision", func(t *testing.T) {
+		outcome, score := DecisionPolicy{Aggregation: DecisionPolicyAggregationWorstOutcome}.Aggregate(nil)
+		assert.Equal(t, None, outcome)
+		assert.Equal(t, 0, score)
+	})
+
+	t.Run("worst outcome", func(t *testing.T) {
+		outcome, score := DecisionPolicy{Aggregation: DecisionPolicyAggregationWorstOutcome}.Aggregate(decisions)
+		assert.Equal(t, Review, outcome)
+		assert.Equal(t, 17, score)
+	})
+
+	t.Run("score sum", func(t *testing.T) {
+		policy := DecisionPolicy{
+			Aggregation:          DecisionPolicyAggregationScoreSum,
+			ScoreReviewThreshold: ptr(10),
+			ScoreRejectThreshold: ptr(15),
+		}
+		outcome, score := policy.Aggregate(decisions)
+		assert.Equal(t, Reject, outcome)
+		assert.Equal(t, 17, score)
+	})
+}
+
+func TestDecisionPolicyMergeUpdate(t *testing.T) {
+	policy := DecisionPolicy{
+		ScenarioIds:          []string{"a"},
+		Aggregation:          DecisionPolicyAggregationScoreSum,
+		ScoreReviewThreshold: ptr(10),
+		ScoreRejectThreshold: ptr(20),
+	}
+
+	unchanged := policy.MergeUpdate(UpdateDecisionPolicyInput{StopOnReject: ptr(true)})
+	assert.True(t, unchanged.StopOnReject)
+	assert.Equal(t, ptr(10), unchanged.ScoreReviewThreshold)
+	assert.Equal(t, ptr(20), unchanged.ScoreRejectThreshold)
+
+	updated := policy.MergeUpdate(UpdateDecisionPolicyInput{
+		ScoreReviewThreshold: pure_utils.NullFrom(15),
+		ScoreRejectThreshold: pure_utils.NullCleared[int](),
+	})
+	assert.Equal(t, ptr(15), updated.ScoreReviewThreshold)
+	assert.Nil(t, updated.ScoreRejectThreshold)
+	assert.Equal(t, ptr(20), policy.ScoreRejectThreshold, "the original policy is not modified")
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// The disposition is the final verdict of an analyst on a decision, as opposed to the outcome computed by the engine
+type DecisionReviewDisposition string
+
+const (
+	DecisionReviewApproved       DecisionReviewDisposition = "approved"
+	DecisionReviewDeclined       DecisionReviewDisposition = "declined"
+	DecisionReviewConfirmedFraud DecisionReviewDisposition = "confirmed_fraud"
+	DecisionReviewFalsePositive  DecisionReviewDisposition = "false_positive"
+)
+
+var ValidDecisionReviewDispositions = []DecisionReviewDisposition{
+	DecisionReviewApproved,
+	DecisionReviewDeclined,
+	DecisionReviewConfirmedFraud,
+	DecisionReviewFalsePositive,
+}
+
+const MAX_DECISION_REVIEW_NOTE_LENGTH = 2000
+
+func (d DecisionReviewDisposition) Validate() error {
+	if !slices.Contains(ValidDecisionReviewDispositions, d) {
+		return errors.Wrapf(BadParameterError, "invalid review disposition %s", d)
+	}
+	return nil
+}
+
+// IsPositive returns true if the analyst confirmed that the decision object had to be stopped. It is the label used
+// to measure the performance of the rules: a rule that matched on a positive decision is a true positive.
+func (d DecisionReviewDisposition) IsPositive() bool {
+	return d == DecisionReviewDeclined || d == DecisionReviewConfirmedFraud
+}
+
+type DecisionReview struct {
+	Disposition DecisionReviewDisposition
+	// ReviewedBy is nil when the review was recorded automatically (e.g. from the status of a transfer)
+	ReviewedBy *UserId
+	ReviewedAt time.Time
+	Note       string
+}
+
+type ReviewDecisionInput struct {
+	DecisionId  string
+	Disposition DecisionReviewDisposition
+	Note        string
+}
+
+func (input ReviewDecisionInput) Validate() error {
+	if err := input.Disposition.Validate(); err != nil {
+		return err
+	}
+	if len(input.Note) > MAX_DECISION_REVIEW_NOTE_LENGTH {
+		return errors.Wrapf(BadParameterError, "the review note must be at most %d characters long",
+			MAX_DECISION_REVIEW_NOTE_LENGTH)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewDecisionInputValidate(t *testing.T) {
+	assert.NoError(t, ReviewDecisionInput{Disposition: DecisionReviewFalsePositive, Note: "known customer"}.Validate())
+	assert.ErrorIs(t, ReviewDecisionInput{Disposition: "maybe"}.Validate(), BadParameterError)
+	assert.ErrorIs(t, ReviewDecisionInput{
+		Disposition: DecisionReviewApproved,
+		Note:        strings.Repeat("a", MAX_DECISION_REVIEW_NOTE_LENGTH+1),
+	}.Validate(), BadParameterError)
+}
+
+func TestDecisionReviewDispositionIsPositive(t *testing.T) {
+	assert.True(t, DecisionReviewConfirmedFraud.IsPositive())
+	assert.True(t, DecisionReviewDeclined.IsPositive())
+	assert.False(t, DecisionReviewApproved.IsPositive())
+	assert.False(t, DecisionReviewFalsePositive.IsPositive())
+}
@@ -1,11 +0,0 @@
-package models
-
-import (
-	"io"
-)
-
-type GCSFile struct {
-	FileName   string
-	Reader     io.ReadCloser
-	BucketName string
-}
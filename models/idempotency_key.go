@@ -0,0 +1,81 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Clients can pass an idempotency key when creating decisions, so that retrying a request (e.g. after a network
+// timeout) returns the decisions created by the first request instead of creating them again.
+const (
+	IDEMPOTENCY_KEY_HEADER            = "Idempotency-Key"
+	IDEMPOTENCY_KEY_MAX_LENGTH        = 255
+	DEFAULT_IDEMPOTENCY_KEY_RETENTION = 24 * time.Hour
+)
+
+// IdempotencyKeyScope is the kind of request an idempotency key was used for: the same key can be used
+// independently on different endpoints.
+type IdempotencyKeyScope string
+
+const (
+	IdempotencyKeyScopeCreateDecision     IdempotencyKeyScope = "create_decision"
+	IdempotencyKeyScopeCreateAllDecisions IdempotencyKeyScope = "create_all_decisions"
+)
+
+var ErrIdempotencyKeyReused = errors.Wrap(ConflictError,
+	"the idempotency key has already been used with a different request")
+
+type IdempotencyKey struct {
+	OrganizationId string
+	Scope          IdempotencyKeyScope
+	Key            string
+	RequestHash    string
+	DecisionIds    []string
+	NbSkipped      int
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type IdempotencyKeyCreate struct {
+	OrganizationId string
+	Scope          IdempotencyKeyScope
+	Key            string
+	RequestHash    string
+	DecisionIds    []string
+	NbSkipped      int
+	ExpiresAt      time.Time
+}
+
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > IDEMPOTENCY_KEY_MAX_LENGTH {
+		return errors.Wrapf(BadParameterError, "idempotency key must be at most %d characters long",
+			IDEMPOTENCY_KEY_MAX_LENGTH)
+	}
+	return nil
+}
+
+// IdempotencyRequestHash identifies the content of a request made with an idempotency key. The payload is
+// normalized, so that the formatting and the order of its fields do not matter.
+func IdempotencyRequestHash(scope IdempotencyKeyScope, scenarioId string, triggerObjectTable string,
+	payloadRaw json.RawMessage,
+) (string, error) {
+	var payload any
+	if err := json.Unmarshal(payloadRaw, &payload); err != nil {
+		return "", errors.Wrap(BadParameterError, "trigger object is not valid json")
+	}
+	normalizedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(scope), []byte(scenarioId), []byte(triggerObjectTable), normalizedPayload} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
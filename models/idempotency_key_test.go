@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRequestHash(t *testing.T) {
+	hash := func(scope IdempotencyKeyScope, scenarioId, payload string) string {
+		h, err := IdempotencyRequestHash(scope, scenarioId, "transactions", json.RawMessage(payload))
+		assert.NoError(t, err)
+		return h
+	}
+
+	reference := hash(IdempotencyKeyScopeCreateDecision, "scenario", `{"object_id": "1", "amount": 10}`)
+
+	t.Run("formatting and field order do not matter", func(t *testing.T) {
+		assert.Equal(t, reference, hash(IdempotencyKeyScopeCreateDecision, "scenario", `{"amount":10,"object_id":"1"}`))
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		assert.NotEqual(t, reference, hash(IdempotencyKeyScopeCreateDecision, "scenario", `{"object_id": "1", "amount": 11}`))
+	})
+
+	t.Run("different scenario", func(t *testing.T) {
+		assert.NotEqual(t, reference, hash(IdempotencyKeyScopeCreateDecision, "other", `{"object_id": "1", "amount": 10}`))
+	})
+
+	t.Run("different scope", func(t *testing.T) {
+		assert.NotEqual(t, reference, hash(IdempotencyKeyScopeCreateAllDecisions, "scenario", `{"object_id": "1", "amount": 10}`))
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := IdempotencyRequestHash(IdempotencyKeyScopeCreateDecision, "scenario", "transactions",
+			json.RawMessage(`{"object_id"`))
+		assert.ErrorIs(t, err, BadParameterError)
+	})
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	assert.NoError(t, ValidateIdempotencyKey("8d0f0b1e-3d4b-4a8c-9e0f-1b2c3d4e5f60"))
+	assert.ErrorIs(t, ValidateIdempotencyKey(strings.Repeat("a", IDEMPOTENCY_KEY_MAX_LENGTH+1)), BadParameterError)
+}
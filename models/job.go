@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// A Job is an entry of the postgres-backed job queue. It references an entity (scheduled execution, upload log,
+// webhook event...) that must be processed by a worker. A worker claims a job by taking a lease on it, that it
+// renews with heartbeats while it processes the job. A job whose lease has expired (e.g. the worker died) can be
+// claimed again by another worker.
+type Job struct {
+	Id             string
+	Kind           JobKind
+	EntityId       string
+	Status         JobStatus
+	Attempts       int
+	RunAt          time.Time
+	LockedBy       *string
+	LeaseExpiresAt *time.Time
+	LastError      *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type JobKind string
+
+const (
+	JobKindScheduledExecution JobKind = "scheduled_execution"
+	JobKindCsvIngestion       JobKind = "csv_ingestion"
+	JobKindWebhookEvent       JobKind = "webhook_event"
+	JobKindAsyncDecision      JobKind = "async_decision"
+)
+
+// MaxAttempts is the number of times a job of this kind is tried before being marked as failed
+func (k JobKind) MaxAttempts() int {
+	switch k {
+	case JobKindScheduledExecution:
+		return 3
+	case JobKindCsvIngestion:
+		return 3
+	case JobKindWebhookEvent:
+		return 24
+	case JobKindAsyncDecision:
+		return 3
+	}
+	return 1
+}
+
+// IsLastAttempt returns true if the job will not be retried if the current attempt fails
+func (j Job) IsLastAttempt() bool {
+	return j.Attempts >= j.Kind.MaxAttempts()
+}
+
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobSuccess JobStatus = "success"
+	JobFailure JobStatus = "failure"
+)
+
+type JobEnqueueInput struct {
+	Kind     JobKind
+	EntityId string
+	// if nil, the job can be processed immediately
+	RunAt *time.Time
+}
+
+type JobClaimInput struct {
+	Kind          JobKind
+	WorkerId      string
+	LeaseDuration time.Duration
+	Limit         int
+}
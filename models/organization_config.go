@@ -0,0 +1,576 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Version of the format of organization configurations. Configurations of another version are rejected at import.
+const ORGANIZATION_CONFIG_VERSION = 1
+
+// OrganizationConfig is a snapshot of the setup of an organization, used to promote it from an environment to another.
+// Every element is identified by its name, never by its id, so that the snapshot can be applied to any organization.
+// The scenarios themselves are promoted with scenario bundles, only their workflow settings are part of the snapshot.
+// Webhook secrets are not exported: a webhook created by an import has a new secret.
+type OrganizationConfig struct {
+	Version           int
+	ExportedAt        time.Time
+	DataModel         OrganizationConfigDataModel
+	CustomLists       []OrganizationConfigCustomList
+	Inboxes           []OrganizationConfigInbox
+	Tags              []OrganizationConfigTag
+	Webhooks          []OrganizationConfigWebhook
+	ScenarioWorkflows []OrganizationConfigScenarioWorkflow
+}
+
+type OrganizationConfigDataModel struct {
+	Tables []OrganizationConfigTable
+	Links  []OrganizationConfigLink
+	Pivots []OrganizationConfigPivot
+}
+
+type OrganizationConfigTable struct {
+	Name        string
+	Description string
+	Fields      []OrganizationConfigField
+}
+
+type OrganizationConfigField struct {
+	Name        string
+	Description string
+	DataType    DataType
+	Nullable    bool
+	IsEnum      bool
+	IsUnique    bool
+}
+
+type OrganizationConfigLink struct {
+	Name        string
+	ChildTable  string
+	ChildField  string
+	ParentTable string
+	ParentField string
+}
+
+// OrganizationConfigPivot is the pivot of a base table: either a field of the table, or the field reached by
+// following the links of the path.
+type OrganizationConfigPivot struct {
+	BaseTable string
+	Field     string
+	PathLinks []string
+}
+
+type OrganizationConfigCustomList struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+type OrganizationConfigInbox struct {
+	Name string
+}
+
+type OrganizationConfigTag struct {
+	Name  string
+	Color string
+}
+
+type OrganizationConfigWebhook struct {
+	Url               string
+	EventTypes        []string
+	HttpTimeout       *int
+	RateLimit         *int
+	RateLimitDuration *int
+}
+
+type OrganizationConfigScenarioWorkflow struct {
+	ScenarioName string
+	WorkflowType WorkflowType
+	InboxName    *string
+	Outcomes     []Outcome
+}
+
+type OrganizationConfigResource string
+
+const (
+	OrganizationConfigResourceTable            OrganizationConfigResource = "data_model_table"
+	OrganizationConfigResourceField            OrganizationConfigResource = "data_model_field"
+	OrganizationConfigResourceLink             OrganizationConfigResource = "data_model_link"
+	OrganizationConfigResourcePivot            OrganizationConfigResource = "data_model_pivot"
+	OrganizationConfigResourceCustomList       OrganizationConfigResource = "custom_list"
+	OrganizationConfigResourceInbox            OrganizationConfigResource = "inbox"
+	OrganizationConfigResourceTag              OrganizationConfigResource = "tag"
+	OrganizationConfigResourceWebhook          OrganizationConfigResource = "webhook"
+	OrganizationConfigResourceScenarioWorkflow OrganizationConfigResource = "scenario_workflow"
+)
+
+type OrganizationConfigAction string
+
+const (
+	OrganizationConfigCreate OrganizationConfigAction = "create"
+	OrganizationConfigUpdate OrganizationConfigAction = "update"
+	OrganizationConfigNoOp   OrganizationConfigAction = "no_op"
+	// The element cannot be applied yet, e.g. the workflow of a scenario which is not imported in the organization
+	OrganizationConfigSkip OrganizationConfigAction = "skip"
+)
+
+// OrganizationConfigChange is the action planned for an element of the snapshot. Details lists the updated attributes,
+// or the reason why the element is skipped.
+type OrganizationConfigChange struct {
+	Resource OrganizationConfigResource
+	Key      string
+	Action   OrganizationConfigAction
+	Details  []string
+}
+
+// OrganizationConfigConflict is a difference which cannot be applied, e.g. a change of the type of a field
+type OrganizationConfigConflict struct {
+	Resource OrganizationConfigResource
+	Key      string
+	Message  string
+}
+
+// OrganizationConfigPlan lists the changes needed for the organization to match the snapshot, in the order in which
+// they are applied. The elements of the organization which are not in the snapshot are left untouched.
+type OrganizationConfigPlan struct {
+	Changes   []OrganizationConfigChange
+	Conflicts []OrganizationConfigConflict
+}
+
+// Action returns the action planned for an element, no-op if it is not in the plan
+func (plan OrganizationConfigPlan) Action(resource OrganizationConfigResource, key string) OrganizationConfigAction {
+	for _, change := range plan.Changes {
+		if change.Resource == resource && change.Key == key {
+			return change.Action
+		}
+	}
+	return OrganizationConfigNoOp
+}
+
+func (plan OrganizationConfigPlan) HasChanges() bool {
+	return slices.ContainsFunc(plan.Changes, func(change OrganizationConfigChange) bool {
+		return change.Action == OrganizationConfigCreate || change.Action == OrganizationConfigUpdate
+	})
+}
+
+func OrganizationConfigFieldKey(tableName, fieldName string) string {
+	return tableName + "." + fieldName
+}
+
+func OrganizationConfigLinkKey(childTableName, linkName string) string {
+	return childTableName + "." + linkName
+}
+
+type organizationConfigPlanner struct {
+	plan OrganizationConfigPlan
+}
+
+func (planner *organizationConfigPlanner) change(resource OrganizationConfigResource, key string,
+	action OrganizationConfigAction, details ...string,
+) {
+	if details == nil {
+		details = []string{}
+	}
+	planner.plan.Changes = append(planner.plan.Changes, OrganizationConfigChange{
+		Resource: resource,
+		Key:      key,
+		Action:   action,
+		Details:  details,
+	})
+}
+
+func (planner *organizationConfigPlanner) conflict(resource OrganizationConfigResource, key string, format string, args ...any) {
+	planner.plan.Conflicts = append(planner.plan.Conflicts, OrganizationConfigConflict{
+		Resource: resource,
+		Key:      key,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func findByName[T any](elements []T, name func(T) string, key string) (T, bool) {
+	for _, element := range elements {
+		if name(element) == key {
+			return element, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func findDuplicates[T any](elements []T, key func(T) string) []string {
+	seen := make(map[string]bool, len(elements))
+	duplicates := make([]string, 0)
+	for _, element := range elements {
+		k := key(element)
+		if seen[k] && !slices.Contains(duplicates, k) {
+			duplicates = append(duplicates, k)
+		}
+		seen[k] = true
+	}
+	return duplicates
+}
+
+// PlanOrganizationConfig compares the snapshot with the current configuration of the target organization, exported in
+// the same format. Nothing can be applied if there are conflicts.
+func PlanOrganizationConfig(snapshot, current OrganizationConfig) OrganizationConfigPlan {
+	planner := organizationConfigPlanner{plan: OrganizationConfigPlan{
+		Changes:   make([]OrganizationConfigChange, 0),
+		Conflicts: make([]OrganizationConfigConflict, 0),
+	}}
+	if snapshot.Version != ORGANIZATION_CONFIG_VERSION {
+		planner.conflict("", "", "configuration version %d is not supported, expected version %d",
+			snapshot.Version, ORGANIZATION_CONFIG_VERSION)
+		return planner.plan
+	}
+
+	planner.planTables(snapshot.DataModel.Tables, current.DataModel.Tables)
+	planner.planLinks(snapshot.DataModel, current.DataModel)
+	planner.planPivots(snapshot.DataModel, current.DataModel)
+	planner.planCustomLists(snapshot.CustomLists, current.CustomLists)
+	planner.planInboxes(snapshot.Inboxes, current.Inboxes)
+	planner.planTags(snapshot.Tags, current.Tags)
+	planner.planWebhooks(snapshot.Webhooks, current.Webhooks)
+	planner.planScenarioWorkflows(snapshot, current)
+	return planner.plan
+}
+
+func tableName(table OrganizationConfigTable) string { return table.Name }
+
+func fieldName(field OrganizationConfigField) string { return field.Name }
+
+func (planner *organizationConfigPlanner) planTables(tables, currentTables []OrganizationConfigTable) {
+	for _, duplicate := range findDuplicates(tables, tableName) {
+		planner.conflict(OrganizationConfigResourceTable, duplicate, "table %s appears several times", duplicate)
+	}
+
+	for _, table := range tables {
+		current, exists := findByName(currentTables, tableName, table.Name)
+		switch {
+		case !exists:
+			planner.change(OrganizationConfigResourceTable, table.Name, OrganizationConfigCreate)
+		case current.Description != table.Description:
+			planner.change(OrganizationConfigResourceTable, table.Name, OrganizationConfigUpdate, "description")
+		default:
+			planner.change(OrganizationConfigResourceTable, table.Name, OrganizationConfigNoOp)
+		}
+	}
+
+	for _, table := range tables {
+		current, _ := findByName(currentTables, tableName, table.Name)
+		for _, duplicate := range findDuplicates(table.Fields, fieldName) {
+			planner.conflict(OrganizationConfigResourceField, OrganizationConfigFieldKey(table.Name, duplicate),
+				"field %s appears several times in table %s", duplicate, table.Name)
+		}
+		for _, field := range table.Fields {
+			key := OrganizationConfigFieldKey(table.Name, field.Name)
+			if field.DataType == UnknownDataType {
+				planner.conflict(OrganizationConfigResourceField, key, "unknown data type")
+				continue
+			}
+			currentField, exists := findByName(current.Fields, fieldName, field.Name)
+			if !exists && current.Name == "" && slices.Contains(DATA_MODEL_DEFAULT_FIELDS, field.Name) {
+				// created with the table
+				planner.change(OrganizationConfigResourceField, key, OrganizationConfigNoOp)
+				continue
+			}
+			if !exists {
+				planner.change(OrganizationConfigResourceField, key, OrganizationConfigCreate)
+				continue
+			}
+			if currentField.DataType != field.DataType {
+				planner.conflict(OrganizationConfigResourceField, key, "the type of the field is %s, it cannot be changed to %s",
+					currentField.DataType, field.DataType)
+				continue
+			}
+			if currentField.Nullable != field.Nullable {
+				planner.conflict(OrganizationConfigResourceField, key, "the field cannot be made nullable or required")
+				continue
+			}
+			details := make([]string, 0)
+			if currentField.Description != field.Description {
+				details = append(details, "description")
+			}
+			if currentField.IsEnum != field.IsEnum {
+				details = append(details, "is_enum")
+			}
+			if currentField.IsUnique != field.IsUnique {
+				details = append(details, "is_unique")
+			}
+			if len(details) > 0 {
+				planner.change(OrganizationConfigResourceField, key, OrganizationConfigUpdate, details...)
+			} else {
+				planner.change(OrganizationConfigResourceField, key, OrganizationConfigNoOp)
+			}
+		}
+	}
+}
+
+// fieldExists tells if the field is in the data model of the organization once the snapshot is applied
+func fieldExists(snapshot, current OrganizationConfigDataModel, table, field string) bool {
+	for _, tables := range [][]OrganizationConfigTable{snapshot.Tables, current.Tables} {
+		if t, ok := findByName(tables, tableName, table); ok {
+			if _, ok := findByName(t.Fields, fieldName, field); ok {
+				return true
+			}
+			if slices.Contains(DATA_MODEL_DEFAULT_FIELDS, field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func linkKey(link OrganizationConfigLink) string {
+	return OrganizationConfigLinkKey(link.ChildTable, link.Name)
+}
+
+func (planner *organizationConfigPlanner) planLinks(snapshot, current OrganizationConfigDataModel) {
+	for _, duplicate := range findDuplicates(snapshot.Links, linkKey) {
+		planner.conflict(OrganizationConfigResourceLink, duplicate, "link %s appears several times", duplicate)
+	}
+
+	for _, link := range snapshot.Links {
+		key := linkKey(link)
+		if !fieldExists(snapshot, current, link.ChildTable, link.ChildField) {
+			planner.conflict(OrganizationConfigResourceLink, key, "child field %s not found",
+				OrganizationConfigFieldKey(link.ChildTable, link.ChildField))
+			continue
+		}
+		if !fieldExists(snapshot, current, link.ParentTable, link.ParentField) {
+			planner.conflict(OrganizationConfigResourceLink, key, "parent field %s not found",
+				OrganizationConfigFieldKey(link.ParentTable, link.ParentField))
+			continue
+		}
+
+		currentLink, exists := findByName(current.Links, linkKey, key)
+		switch {
+		case !exists:
+			planner.change(OrganizationConfigResourceLink, key, OrganizationConfigCreate)
+		case currentLink != link:
+			planner.conflict(OrganizationConfigResourceLink, key, "the link exists with another definition: %s.%s -> %s.%s",
+				currentLink.ChildTable, currentLink.ChildField, currentLink.ParentTable, currentLink.ParentField)
+		default:
+			planner.change(OrganizationConfigResourceLink, key, OrganizationConfigNoOp)
+		}
+	}
+}
+
+func pivotBaseTable(pivot OrganizationConfigPivot) string { return pivot.BaseTable }
+
+func (planner *organizationConfigPlanner) planPivots(snapshot, current OrganizationConfigDataModel) {
+	for _, duplicate := range findDuplicates(snapshot.Pivots, pivotBaseTable) {
+		planner.conflict(OrganizationConfigResourcePivot, duplicate, "table %s has several pivots", duplicate)
+	}
+
+	for _, pivot := range snapshot.Pivots {
+		if len(pivot.PathLinks) == 0 && !fieldExists(snapshot, current, pivot.BaseTable, pivot.Field) {
+			planner.conflict(OrganizationConfigResourcePivot, pivot.BaseTable, "field %s not found",
+				OrganizationConfigFieldKey(pivot.BaseTable, pivot.Field))
+			continue
+		}
+		missingLink := false
+		for _, linkName := range pivot.PathLinks {
+			_, inSnapshot := findByName(snapshot.Links, func(l OrganizationConfigLink) string { return l.Name }, linkName)
+			_, inCurrent := findByName(current.Links, func(l OrganizationConfigLink) string { return l.Name }, linkName)
+			if !inSnapshot && !inCurrent {
+				planner.conflict(OrganizationConfigResourcePivot, pivot.BaseTable, "link %s of the path not found", linkName)
+				missingLink = true
+			}
+		}
+		if missingLink {
+			continue
+		}
+
+		currentPivot, exists := findByName(current.Pivots, pivotBaseTable, pivot.BaseTable)
+		switch {
+		case !exists:
+			planner.change(OrganizationConfigResourcePivot, pivot.BaseTable, OrganizationConfigCreate)
+		case currentPivot.Field != pivot.Field || !slices.Equal(currentPivot.PathLinks, pivot.PathLinks):
+			planner.conflict(OrganizationConfigResourcePivot, pivot.BaseTable,
+				"the table has another pivot: %s", strings.Join(slices.Concat(currentPivot.PathLinks, []string{currentPivot.Field}), "."))
+		default:
+			planner.change(OrganizationConfigResourcePivot, pivot.BaseTable, OrganizationConfigNoOp)
+		}
+	}
+}
+
+func (planner *organizationConfigPlanner) planCustomLists(customLists, currentLists []OrganizationConfigCustomList) {
+	name := func(l OrganizationConfigCustomList) string { return l.Name }
+	for _, duplicate := range findDuplicates(customLists, name) {
+		planner.conflict(OrganizationConfigResourceCustomList, duplicate, "custom list %s appears several times", duplicate)
+	}
+
+	for _, customList := range customLists {
+		current, exists := findByName(currentLists, name, customList.Name)
+		if !exists {
+			planner.change(OrganizationConfigResourceCustomList, customList.Name, OrganizationConfigCreate)
+			continue
+		}
+		details := make([]string, 0)
+		if current.Description != customList.Description {
+			details = append(details, "description")
+		}
+		added, removed := diffCustomListValues(current.Values, customList.Values)
+		if len(added) > 0 || len(removed) > 0 {
+			details = append(details, fmt.Sprintf("values: %d added, %d removed", len(added), len(removed)))
+		}
+		if len(details) > 0 {
+			planner.change(OrganizationConfigResourceCustomList, customList.Name, OrganizationConfigUpdate, details...)
+		} else {
+			planner.change(OrganizationConfigResourceCustomList, customList.Name, OrganizationConfigNoOp)
+		}
+	}
+}
+
+// diffCustomListValues returns the values to add to the current values and the current values to remove, to get the
+// values of the snapshot
+func diffCustomListValues(current, snapshot []string) (added, removed []string) {
+	added, removed = make([]string, 0), make([]string, 0)
+	for _, value := range snapshot {
+		if !slices.Contains(current, value) && !slices.Contains(added, value) {
+			added = append(added, value)
+		}
+	}
+	for _, value := range current {
+		if !slices.Contains(snapshot, value) {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+// DiffValues is the update of the values of a custom list of the organization to match the snapshot
+func (customList OrganizationConfigCustomList) DiffValues(currentValues []string) (added, removed []string) {
+	return diffCustomListValues(currentValues, customList.Values)
+}
+
+func (planner *organizationConfigPlanner) planInboxes(inboxes, currentInboxes []OrganizationConfigInbox) {
+	name := func(i OrganizationConfigInbox) string { return i.Name }
+	for _, duplicate := range findDuplicates(inboxes, name) {
+		planner.conflict(OrganizationConfigResourceInbox, duplicate, "inbox %s appears several times", duplicate)
+	}
+
+	for _, inbox := range inboxes {
+		if _, exists := findByName(currentInboxes, name, inbox.Name); exists {
+			planner.change(OrganizationConfigResourceInbox, inbox.Name, OrganizationConfigNoOp)
+		} else {
+			planner.change(OrganizationConfigResourceInbox, inbox.Name, OrganizationConfigCreate)
+		}
+	}
+}
+
+func (planner *organizationConfigPlanner) planTags(tags, currentTags []OrganizationConfigTag) {
+	name := func(t OrganizationConfigTag) string { return t.Name }
+	for _, duplicate := range findDuplicates(tags, name) {
+		planner.conflict(OrganizationConfigResourceTag, duplicate, "tag %s appears several times", duplicate)
+	}
+
+	for _, tag := range tags {
+		current, exists := findByName(currentTags, name, tag.Name)
+		switch {
+		case !exists:
+			planner.change(OrganizationConfigResourceTag, tag.Name, OrganizationConfigCreate)
+		case current.Color != tag.Color:
+			planner.change(OrganizationConfigResourceTag, tag.Name, OrganizationConfigUpdate, "color")
+		default:
+			planner.change(OrganizationConfigResourceTag, tag.Name, OrganizationConfigNoOp)
+		}
+	}
+}
+
+func equalIntPointers(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func (planner *organizationConfigPlanner) planWebhooks(webhooks, currentWebhooks []OrganizationConfigWebhook) {
+	url := func(w OrganizationConfigWebhook) string { return w.Url }
+	for _, duplicate := range findDuplicates(webhooks, url) {
+		planner.conflict(OrganizationConfigResourceWebhook, duplicate, "webhook %s appears several times", duplicate)
+	}
+
+	for _, webhook := range webhooks {
+		current, exists := findByName(currentWebhooks, url, webhook.Url)
+		if !exists {
+			planner.change(OrganizationConfigResourceWebhook, webhook.Url, OrganizationConfigCreate)
+			continue
+		}
+		details := make([]string, 0)
+		if !slices.Equal(sortedStrings(current.EventTypes), sortedStrings(webhook.EventTypes)) {
+			details = append(details, "event_types")
+		}
+		if !equalIntPointers(current.HttpTimeout, webhook.HttpTimeout) {
+			details = append(details, "http_timeout")
+		}
+		if !equalIntPointers(current.RateLimit, webhook.RateLimit) {
+			details = append(details, "rate_limit")
+		}
+		if !equalIntPointers(current.RateLimitDuration, webhook.RateLimitDuration) {
+			details = append(details, "rate_limit_duration")
+		}
+		if len(details) > 0 {
+			planner.change(OrganizationConfigResourceWebhook, webhook.Url, OrganizationConfigUpdate, details...)
+		} else {
+			planner.change(OrganizationConfigResourceWebhook, webhook.Url, OrganizationConfigNoOp)
+		}
+	}
+}
+
+func sortedStrings(s []string) []string {
+	sorted := slices.Clone(s)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func (planner *organizationConfigPlanner) planScenarioWorkflows(snapshot, current OrganizationConfig) {
+	name := func(w OrganizationConfigScenarioWorkflow) string { return w.ScenarioName }
+	for _, duplicate := range findDuplicates(snapshot.ScenarioWorkflows, name) {
+		planner.conflict(OrganizationConfigResourceScenarioWorkflow, duplicate, "scenario %s appears several times", duplicate)
+	}
+	inboxName := func(i OrganizationConfigInbox) string { return i.Name }
+
+	for _, workflow := range snapshot.ScenarioWorkflows {
+		if !slices.Contains(ValidWorkflowTypes, workflow.WorkflowType) {
+			planner.conflict(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName,
+				"invalid workflow type %s", workflow.WorkflowType)
+			continue
+		}
+		if slices.ContainsFunc(workflow.Outcomes, func(o Outcome) bool { return !slices.Contains(ValidOutcomes, o) }) {
+			planner.conflict(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName, "invalid outcome")
+			continue
+		}
+		if workflow.InboxName != nil {
+			_, inSnapshot := findByName(snapshot.Inboxes, inboxName, *workflow.InboxName)
+			_, inCurrent := findByName(current.Inboxes, inboxName, *workflow.InboxName)
+			if !inSnapshot && !inCurrent {
+				planner.conflict(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName,
+					"inbox %s not found", *workflow.InboxName)
+				continue
+			}
+		}
+
+		currentWorkflow, exists := findByName(current.ScenarioWorkflows, name, workflow.ScenarioName)
+		if !exists {
+			planner.change(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName, OrganizationConfigSkip,
+				"the scenario is not in the organization, import it first")
+			continue
+		}
+		details := make([]string, 0)
+		if currentWorkflow.WorkflowType != workflow.WorkflowType {
+			details = append(details, "workflow_type")
+		}
+		if (currentWorkflow.InboxName == nil) != (workflow.InboxName == nil) ||
+			(workflow.InboxName != nil && *currentWorkflow.InboxName != *workflow.InboxName) {
+			details = append(details, "inbox")
+		}
+		if !slices.Equal(currentWorkflow.Outcomes, workflow.Outcomes) {
+			details = append(details, "outcomes")
+		}
+		if len(details) > 0 {
+			planner.change(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName, OrganizationConfigUpdate, details...)
+		} else {
+			planner.change(OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName, OrganizationConfigNoOp)
+		}
+	}
+}
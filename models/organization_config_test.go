@@ -0,0 +1,132 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func organizationConfigTest() OrganizationConfig {
+	return OrganizationConfig{
+		Version: ORGANIZATION_CONFIG_VERSION,
+		DataModel: OrganizationConfigDataModel{
+			Tables: []OrganizationConfigTable{
+				{Name: "accounts", Fields: []OrganizationConfigField{
+					{Name: "object_id", DataType: String, IsUnique: true},
+					{Name: "name", DataType: String, Nullable: true},
+				}},
+				{Name: "transactions", Fields: []OrganizationConfigField{
+					{Name: "object_id", DataType: String, IsUnique: true},
+					{Name: "account_id", DataType: String, Nullable: true},
+				}},
+			},
+			Links: []OrganizationConfigLink{
+				{Name: "account", ChildTable: "transactions", ChildField: "account_id", ParentTable: "accounts", ParentField: "object_id"},
+			},
+			Pivots: []OrganizationConfigPivot{
+				{BaseTable: "transactions", Field: "object_id", PathLinks: []string{"account"}},
+			},
+		},
+		CustomLists:       []OrganizationConfigCustomList{{Name: "blocklist", Values: []string{"a", "b"}}},
+		Inboxes:           []OrganizationConfigInbox{{Name: "fraud"}},
+		Tags:              []OrganizationConfigTag{{Name: "urgent", Color: "#ff0000"}},
+		ScenarioWorkflows: []OrganizationConfigScenarioWorkflow{{ScenarioName: "large transactions", WorkflowType: WorkflowCreateCase, InboxName: ptr("fraud"), Outcomes: []Outcome{Reject}}},
+	}
+}
+
+func TestPlanOrganizationConfig_emptyOrganization(t *testing.T) {
+	plan := PlanOrganizationConfig(organizationConfigTest(), OrganizationConfig{Version: ORGANIZATION_CONFIG_VERSION})
+
+	assert.Empty(t, plan.Conflicts)
+	assert.True(t, plan.HasChanges())
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourceTable, "accounts"))
+	assert.Equal(t, OrganizationConfigNoOp, plan.Action(OrganizationConfigResourceField, "accounts.object_id"),
+		"default fields are created with the table")
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourceField, "accounts.name"))
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourceLink, "transactions.account"))
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourcePivot, "transactions"))
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourceCustomList, "blocklist"))
+	assert.Equal(t, OrganizationConfigCreate, plan.Action(OrganizationConfigResourceInbox, "fraud"))
+	assert.Equal(t, OrganizationConfigSkip, plan.Action(OrganizationConfigResourceScenarioWorkflow, "large transactions"))
+
+	// the tables come first, the scenario workflows last
+	assert.Equal(t, OrganizationConfigResourceTable, plan.Changes[0].Resource)
+	assert.Equal(t, OrganizationConfigResourceScenarioWorkflow, plan.Changes[len(plan.Changes)-1].Resource)
+}
+
+func TestPlanOrganizationConfig_idempotent(t *testing.T) {
+	config := organizationConfigTest()
+	config.ScenarioWorkflows[0].WorkflowType = WorkflowAddToCaseIfPossible
+
+	plan := PlanOrganizationConfig(organizationConfigTest(), config)
+	assert.Empty(t, plan.Conflicts)
+	assert.Equal(t, []OrganizationConfigChange{{
+		Resource: OrganizationConfigResourceScenarioWorkflow,
+		Key:      "large transactions",
+		Action:   OrganizationConfigUpdate,
+		Details:  []string{"workflow_type"},
+	}}, filterChanges(plan))
+
+	plan = PlanOrganizationConfig(organizationConfigTest(), organizationConfigTest())
+	assert.Empty(t, plan.Conflicts)
+	assert.False(t, plan.HasChanges())
+}
+
+func TestPlanOrganizationConfig_updates(t *testing.T) {
+	current := organizationConfigTest()
+	current.DataModel.Tables[0].Fields[1].Description = "old"
+	current.CustomLists[0].Values = []string{"b", "c"}
+	current.Tags[0].Color = "#00ff00"
+
+	plan := PlanOrganizationConfig(organizationConfigTest(), current)
+	assert.Empty(t, plan.Conflicts)
+	assert.Equal(t, []OrganizationConfigChange{
+		{Resource: OrganizationConfigResourceField, Key: "accounts.name", Action: OrganizationConfigUpdate, Details: []string{"description"}},
+		{Resource: OrganizationConfigResourceCustomList, Key: "blocklist", Action: OrganizationConfigUpdate, Details: []string{"values: 1 added, 1 removed"}},
+		{Resource: OrganizationConfigResourceTag, Key: "urgent", Action: OrganizationConfigUpdate, Details: []string{"color"}},
+	}, filterChanges(plan))
+
+	added, removed := organizationConfigTest().CustomLists[0].DiffValues(current.CustomLists[0].Values)
+	assert.Equal(t, []string{"a"}, added)
+	assert.Equal(t, []string{"c"}, removed)
+}
+
+func TestPlanOrganizationConfig_conflicts(t *testing.T) {
+	current := organizationConfigTest()
+	current.DataModel.Tables[0].Fields[1].DataType = Int
+	current.DataModel.Links[0].ChildField = "object_id"
+
+	snapshot := organizationConfigTest()
+	snapshot.ScenarioWorkflows[0].InboxName = ptr("unknown")
+
+	plan := PlanOrganizationConfig(snapshot, current)
+	assert.Equal(t, []OrganizationConfigResource{
+		OrganizationConfigResourceField,
+		OrganizationConfigResourceLink,
+		OrganizationConfigResourceScenarioWorkflow,
+	}, conflictResources(plan))
+
+	snapshot = organizationConfigTest()
+	snapshot.Version = 2
+	plan = PlanOrganizationConfig(snapshot, current)
+	assert.Len(t, plan.Conflicts, 1)
+	assert.Empty(t, plan.Changes)
+}
+
+func filterChanges(plan OrganizationConfigPlan) []OrganizationConfigChange {
+	changes := make([]OrganizationConfigChange, 0)
+	for _, change := range plan.Changes {
+		if change.Action != OrganizationConfigNoOp {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func conflictResources(plan OrganizationConfigPlan) []OrganizationConfigResource {
+	resources := make([]OrganizationConfigResource, len(plan.Conflicts))
+	for i, conflict := range plan.Conflicts {
+		resources[i] = conflict.Resource
+	}
+	return resources
+}
@@ -0,0 +1,6 @@
+package models
+
+// ptr replaces utils.Ptr in the tests of the models package, which cannot import utils as it imports models
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// How long the rate limits of an organization are cached by each instance of the API
+const RATE_LIMIT_SETTINGS_CACHE_DURATION = time.Minute
+
+// RateLimitBackend is where the requests are counted against the monthly quotas
+type RateLimitBackend string
+
+const (
+	// The requests are counted by each instance of the API, which is enough for a single instance deployment
+	RateLimitBackendMemory RateLimitBackend = "memory"
+	// The requests are counted in Postgres, shared by all the instances of the API
+	RateLimitBackendPostgres RateLimitBackend = "postgres"
+)
+
+func RateLimitBackendFrom(s string) (RateLimitBackend, error) {
+	switch s {
+	case "", string(RateLimitBackendMemory):
+		return RateLimitBackendMemory, nil
+	case string(RateLimitBackendPostgres):
+		return RateLimitBackendPostgres, nil
+	}
+	return "", errors.Errorf("invalid rate limit backend: %s", s)
+}
+
+// RateLimitConfiguration is the configuration of the rate limits of the deployment. The default policy applies to the
+// organizations without their own rate limits.
+type RateLimitConfiguration struct {
+	Backend       RateLimitBackend
+	DefaultPolicy RateLimitPolicy
+}
+
+// RateLimitPolicy is a token bucket refilled at RequestsPerSecond up to Burst requests, and a number of requests per
+// calendar month (UTC). Zero values do not limit anything.
+type RateLimitPolicy struct {
+	RequestsPerSecond float64
+	Burst             int
+	MonthlyQuota      int64
+}
+
+func (policy RateLimitPolicy) HasRateLimit() bool {
+	return policy.RequestsPerSecond > 0
+}
+
+func (policy RateLimitPolicy) HasQuota() bool {
+	return policy.MonthlyQuota > 0
+}
+
+func (policy RateLimitPolicy) IsUnlimited() bool {
+	return !policy.HasRateLimit() && !policy.HasQuota()
+}
+
+// BurstOrDefault is the size of the token bucket, at least one second of requests
+func (policy RateLimitPolicy) BurstOrDefault() int {
+	if policy.Burst > 0 {
+		return policy.Burst
+	}
+	return max(1, int(math.Ceil(policy.RequestsPerSecond)))
+}
+
+func (policy RateLimitPolicy) Validate() error {
+	if policy.RequestsPerSecond < 0 || policy.Burst < 0 || policy.MonthlyQuota < 0 {
+		return errors.Wrap(BadParameterError, "rate limits cannot be negative")
+	}
+	if policy.Burst > 0 && policy.RequestsPerSecond == 0 {
+		return errors.Wrap(BadParameterError, "a burst requires a number of requests per second")
+	}
+	return nil
+}
+
+// RateLimitSettings are the rate limits of an organization, or of one of its API keys if ApiKeyId is set. The
+// requests of an API key count against the limits of the key and of its organization.
+type RateLimitSettings struct {
+	Id             string
+	OrganizationId string
+	ApiKeyId       *string
+	Policy         RateLimitPolicy
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type UpsertRateLimitInput struct {
+	OrganizationId string
+	ApiKeyId       *string
+	Policy         RateLimitPolicy
+}
+
+// RateLimitSubject is what the requests are counted for: an organization or an API key
+func RateLimitSubject(organizationId string, apiKeyId *string) string {
+	if apiKeyId != nil {
+		return "api_key:" + *apiKeyId
+	}
+	return "organization:" + organizationId
+}
+
+// RateLimitPeriod is the calendar month of the quotas
+func RateLimitPeriod(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// NextRateLimitPeriod is the start of the next calendar month, when the quotas are reset
+func NextRateLimitPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// RateLimitStatus is the state of a limit after a request, reported in the RateLimit-* headers
+type RateLimitStatus struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// When the limit is fully reset
+	Reset time.Duration
+	// When a refused request can be retried
+	RetryAfter time.Duration
+	Reason     string
+}
+
+type RateLimitUsage struct {
+	Settings        RateLimitSettings
+	Period          string
+	RequestsInMonth int64
+}
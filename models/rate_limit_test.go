@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RateLimitPolicy{}.Validate())
+	assert.NoError(t, RateLimitPolicy{RequestsPerSecond: 0.5, Burst: 10, MonthlyQuota: 1000}.Validate())
+	assert.ErrorIs(t, RateLimitPolicy{RequestsPerSecond: -1}.Validate(), BadParameterError)
+	assert.ErrorIs(t, RateLimitPolicy{MonthlyQuota: -1}.Validate(), BadParameterError)
+	assert.ErrorIs(t, RateLimitPolicy{Burst: 10}.Validate(), BadParameterError)
+}
+
+func TestRateLimitPolicy_BurstOrDefault(t *testing.T) {
+	assert.Equal(t, 10, RateLimitPolicy{RequestsPerSecond: 1, Burst: 10}.BurstOrDefault())
+	assert.Equal(t, 3, RateLimitPolicy{RequestsPerSecond: 2.5}.BurstOrDefault())
+	assert.Equal(t, 1, RateLimitPolicy{RequestsPerSecond: 0.1}.BurstOrDefault())
+}
+
+func TestRateLimitPeriod(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	// the periods are calendar months in UTC
+	now := time.Date(2024, 10, 1, 1, 0, 0, 0, paris)
+	assert.Equal(t, "2024-09", RateLimitPeriod(now))
+	assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), NextRateLimitPeriod(now))
+
+	now = time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-12", RateLimitPeriod(now))
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), NextRateLimitPeriod(now))
+}
+
+func TestRateLimitSubject(t *testing.T) {
+	apiKeyId := "api_key_id"
+	assert.Equal(t, "organization:organization_id", RateLimitSubject("organization_id", nil))
+	assert.Equal(t, "api_key:api_key_id", RateLimitSubject("organization_id", &apiKeyId))
+}
@@ -28,6 +28,7 @@ var (
 		MARBLE_USER_DELETE,
 		INBOX_EDITOR,
 		WEBHOOK,
+		AUDIT_EVENT_READ,
 	)
 )
 
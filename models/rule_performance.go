@@ -0,0 +1,184 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const MAX_RULE_PERFORMANCE_WINDOW = 366 * 24 * time.Hour
+
+type RulePerformanceReportInput struct {
+	OrganizationId string
+	ScenarioId     string
+	StartDate      time.Time
+	EndDate        time.Time
+}
+
+func (input RulePerformanceReportInput) Validate() error {
+	if input.StartDate.IsZero() || input.EndDate.IsZero() {
+		return errors.Wrap(BadParameterError, "start date and end date are required")
+	}
+	if !input.StartDate.Before(input.EndDate) {
+		return errors.Wrap(BadParameterError, "start date must be before end date")
+	}
+	if input.EndDate.Sub(input.StartDate) > MAX_RULE_PERFORMANCE_WINDOW {
+		return errors.Wrapf(BadParameterError, "the time window must be at most %d days long",
+			int(MAX_RULE_PERFORMANCE_WINDOW.Hours()/24))
+	}
+	return nil
+}
+
+// DecisionLabel returns the label of a decision used to measure the performance of the rules: positive if the
+// decision object had to be stopped. The review of an analyst on the decision takes precedence over the status of its
+// case. A decision that was not reviewed and whose case is not closed is not labeled.
+func DecisionLabel(reviewDisposition *DecisionReviewDisposition, caseStatus *CaseStatus) (positive bool, labeled bool) {
+	if reviewDisposition != nil {
+		return reviewDisposition.IsPositive(), true
+	}
+	if caseStatus != nil {
+		switch *caseStatus {
+		case CaseResolved:
+			return true, true
+		case CaseDiscarded:
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// Number of decisions with a given review disposition and case status, counted by the repository
+type DecisionLabelCount struct {
+	ReviewDisposition *DecisionReviewDisposition
+	CaseStatus        *CaseStatus
+	Count             int
+}
+
+// Number of executions of a rule with a given result, on decisions with a given review disposition and case status.
+// The rule is identified by its name across the iterations of the scenario, RuleId is the id of the rule in the latest
+// iteration that executed it.
+type RuleExecutionLabelCount struct {
+	RuleId            string
+	RuleName          string
+	LastExecutedAt    time.Time
+	Result            bool
+	ReviewDisposition *DecisionReviewDisposition
+	CaseStatus        *CaseStatus
+	Count             int
+}
+
+type RulePerformance struct {
+	RuleId   string
+	RuleName string
+	// number of times the rule matched, labeled or not
+	NbMatched      int
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+	TrueNegatives  int
+}
+
+func ratio(numerator, denominator int) *float64 {
+	if denominator == 0 {
+		return nil
+	}
+	r := float64(numerator) / float64(denominator)
+	return &r
+}
+
+// Precision is the share of the labeled decisions matched by the rule that are positive
+func (p RulePerformance) Precision() *float64 {
+	return ratio(p.TruePositives, p.TruePositives+p.FalsePositives)
+}
+
+// Recall is the share of the positive decisions that the rule matched
+func (p RulePerformance) Recall() *float64 {
+	return ratio(p.TruePositives, p.TruePositives+p.FalseNegatives)
+}
+
+// FalsePositiveRate is the share of the negative decisions that the rule matched
+func (p RulePerformance) FalsePositiveRate() *float64 {
+	return ratio(p.FalsePositives, p.FalsePositives+p.TrueNegatives)
+}
+
+// Lift is the precision of the rule divided by the share of positive decisions: how much more likely a decision
+// matched by the rule is to be positive than a random decision
+func (p RulePerformance) Lift() *float64 {
+	precision := p.Precision()
+	baseRate := ratio(p.TruePositives+p.FalseNegatives,
+		p.TruePositives+p.FalsePositives+p.FalseNegatives+p.TrueNegatives)
+	if precision == nil || baseRate == nil || *baseRate == 0 {
+		return nil
+	}
+	lift := *precision / *baseRate
+	return &lift
+}
+
+type RulePerformanceReport struct {
+	ScenarioId  string
+	StartDate   time.Time
+	EndDate     time.Time
+	NbDecisions int
+	NbLabeled   int
+	NbPositives int
+	Rules       []RulePerformance
+}
+
+func NewRulePerformanceReport(
+	input RulePerformanceReportInput,
+	decisionCounts []DecisionLabelCount,
+	ruleExecutionCounts []RuleExecutionLabelCount,
+) RulePerformanceReport {
+	report := RulePerformanceReport{
+		ScenarioId: input.ScenarioId,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+		Rules:      make([]RulePerformance, 0),
+	}
+	for _, count := range decisionCounts {
+		report.NbDecisions += count.Count
+		positive, labeled := DecisionLabel(count.ReviewDisposition, count.CaseStatus)
+		if labeled {
+			report.NbLabeled += count.Count
+		}
+		if labeled && positive {
+			report.NbPositives += count.Count
+		}
+	}
+
+	ruleIndexes := make(map[string]int)
+	lastExecutions := make([]time.Time, 0)
+	for _, count := range ruleExecutionCounts {
+		index, ok := ruleIndexes[count.RuleName]
+		if !ok {
+			index = len(report.Rules)
+			ruleIndexes[count.RuleName] = index
+			report.Rules = append(report.Rules, RulePerformance{RuleId: count.RuleId, RuleName: count.RuleName})
+			lastExecutions = append(lastExecutions, count.LastExecutedAt)
+		}
+		rule := &report.Rules[index]
+		if count.LastExecutedAt.After(lastExecutions[index]) {
+			rule.RuleId = count.RuleId
+			lastExecutions[index] = count.LastExecutedAt
+		}
+
+		if count.Result {
+			rule.NbMatched += count.Count
+		}
+		positive, labeled := DecisionLabel(count.ReviewDisposition, count.CaseStatus)
+		if !labeled {
+			continue
+		}
+		switch {
+		case count.Result && positive:
+			rule.TruePositives += count.Count
+		case count.Result && !positive:
+			rule.FalsePositives += count.Count
+		case !count.Result && positive:
+			rule.FalseNegatives += count.Count
+		default:
+			rule.TrueNegatives += count.Count
+		}
+	}
+	return report
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionLabel(t *testing.T) {
+	positive, labeled := DecisionLabel(ptr(DecisionReviewFalsePositive), ptr(CaseResolved))
+	assert.True(t, labeled)
+	assert.False(t, positive, "the review takes precedence over the case status")
+
+	positive, labeled = DecisionLabel(nil, ptr(CaseResolved))
+	assert.True(t, labeled)
+	assert.True(t, positive)
+
+	positive, labeled = DecisionLabel(nil, ptr(CaseDiscarded))
+	assert.True(t, labeled)
+	assert.False(t, positive)
+
+	_, labeled = DecisionLabel(nil, ptr(CaseInvestigating))
+	assert.False(t, labeled)
+
+	_, labeled = DecisionLabel(nil, nil)
+	assert.False(t, labeled)
+}
+
+func TestNewRulePerformanceReport(t *testing.T) {
+	fraud := ptr(DecisionReviewConfirmedFraud)
+	legit := ptr(DecisionReviewApproved)
+
+	report := NewRulePerformanceReport(
+		RulePerformanceReportInput{ScenarioId: "scenario"},
+		[]DecisionLabelCount{
+			{ReviewDisposition: fraud, Count: 10},
+			{ReviewDisposition: legit, Count: 30},
+			{Count: 60},
+		},
+		[]RuleExecutionLabelCount{
+			{RuleId: "rule", RuleName: "big amount", Result: true, ReviewDisposition: fraud, Count: 8},
+			{RuleId: "rule", RuleName: "big amount", Result: false, ReviewDisposition: fraud, Count: 2},
+			{RuleId: "rule", RuleName: "big amount", Result: true, ReviewDisposition: legit, Count: 2},
+			{RuleId: "rule", RuleName: "big amount", Result: false, ReviewDisposition: legit, Count: 28},
+			{RuleId: "rule", RuleName: "big amount", Result: true, Count: 5},
+			{RuleId: "rule", RuleName: "big amount", Result: false, Count: 55},
+		},
+	)
+
+	assert.Equal(t, 100, report.NbDecisions)
+	assert.Equal(t, 40, report.NbLabeled)
+	assert.Equal(t, 10, report.NbPositives)
+	assert.Len(t, report.Rules, 1)
+
+	rule := report.Rules[0]
+	assert.Equal(t, 15, rule.NbMatched)
+	assert.Equal(t, 8, rule.TruePositives)
+	assert.Equal(t, 2, rule.FalsePositives)
+	assert.Equal(t, 2, rule.FalseNegatives)
+	assert.Equal(t, 28, rule.TrueNegatives)
+	assert.InDelta(t, 0.8, *rule.Precision(), 1e-9)
+	assert.InDelta(t, 0.8, *rule.Recall(), 1e-9)
+	assert.InDelta(t, 2.0/30, *rule.FalsePositiveRate(), 1e-9)
+	assert.InDelta(t, 3.2, *rule.Lift(), 1e-9)
+}
+
+func TestNewRulePerformanceReportAcrossIterations(t *testing.T) {
+	fraud := ptr(DecisionReviewConfirmedFraud)
+	firstIteration := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secondIteration := firstIteration.AddDate(0, 1, 0)
+
+	report := NewRulePerformanceReport(
+		RulePerformanceReportInput{ScenarioId: "scenario"},
+		[]DecisionLabelCount{{ReviewDisposition: fraud, Count: 10}},
+		[]RuleExecutionLabelCount{
+			{RuleId: "rule_v1", RuleName: "big amount", LastExecutedAt: firstIteration, Result: true, ReviewDisposition: fraud, Count: 3},
+			{RuleId: "rule_v2", RuleName: "big amount", LastExecutedAt: secondIteration, Result: true, ReviewDisposition: fraud, Count: 4},
+			{RuleId: "rule_v1", RuleName: "big amount", LastExecutedAt: firstIteration, Result: false, ReviewDisposition: fraud, Count: 3},
+		},
+	)
+
+	// the copies of the rule in both iterations are counted as one rule, with the id of the latest one
+	assert.Equal(t, []RulePerformance{{
+		RuleId:         "rule_v2",
+		RuleName:       "big amount",
+		NbMatched:      7,
+		TruePositives:  7,
+		FalseNegatives: 3,
+	}}, report.Rules)
+}
+
+func TestRulePerformanceWithoutLabels(t *testing.T) {
+	rule := RulePerformance{NbMatched: 3}
+	assert.Nil(t, rule.Precision())
+	assert.Nil(t, rule.Recall())
+	assert.Nil(t, rule.FalsePositiveRate())
+	assert.Nil(t, rule.Lift())
+}
+
+func TestRulePerformanceReportInputValidate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, RulePerformanceReportInput{StartDate: start, EndDate: start.AddDate(0, 1, 0)}.Validate())
+	assert.ErrorIs(t, RulePerformanceReportInput{StartDate: start, EndDate: start}.Validate(), BadParameterError)
+	assert.ErrorIs(t, RulePerformanceReportInput{StartDate: start, EndDate: start.AddDate(2, 0, 0)}.Validate(),
+		BadParameterError)
+}
@@ -0,0 +1,441 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+// Version of the format of scenario bundles. Bundles of another version are rejected at import.
+const SCENARIO_BUNDLE_VERSION = 1
+
+// ScenarioBundle is a portable representation of a scenario, used to copy it between organizations.
+// Custom lists are referenced by their id in the source organization, and matched by name in the target organization.
+type ScenarioBundle struct {
+	Version     int
+	ExportedAt  time.Time
+	Scenario    ScenarioBundleScenario
+	Iterations  []ScenarioBundleIteration
+	CustomLists []ScenarioBundleCustomList
+	DataModel   []ScenarioBundleFieldRequirement
+}
+
+type ScenarioBundleScenario struct {
+	Name              string
+	Description       string
+	TriggerObjectType string
+}
+
+type ScenarioBundleIteration struct {
+	Version                       *int
+	TriggerConditionAstExpression *ast.Node
+	Rules                         []ScenarioBundleRule
+	ScoreReviewThreshold          *int
+	ScoreRejectThreshold          *int
+	BatchTriggerSQL               string
+	Schedule                      string
+	ScheduleTimezone              string
+	OutputVariables               []OutputVariable
+}
+
+type ScenarioBundleRule struct {
+	DisplayOrder         int
+	Name                 string
+	Description          string
+	FormulaAstExpression *ast.Node
+	ScoreModifier        int
+	RuleGroup            string
+}
+
+type ScenarioBundleCustomList struct {
+	Id          string
+	Name        string
+	Description string
+	Values      []string
+}
+
+// ScenarioBundleFieldRequirement is a field of the data model read by the scenario: the field FieldName of the table
+// reached from TableName by following the links of Path.
+type ScenarioBundleFieldRequirement struct {
+	TableName string
+	Path      []string
+	FieldName string
+	DataType  DataType
+}
+
+func (requirement ScenarioBundleFieldRequirement) key() string {
+	return strings.Join(append(append([]string{requirement.TableName}, requirement.Path...), requirement.FieldName), ".")
+}
+
+func (requirement ScenarioBundleFieldRequirement) String() string {
+	return requirement.key()
+}
+
+func NewScenarioBundleIteration(iteration ScenarioIteration) ScenarioBundleIteration {
+	rules := make([]ScenarioBundleRule, len(iteration.Rules))
+	for i, rule := range iteration.Rules {
+		rules[i] = ScenarioBundleRule{
+			DisplayOrder:         rule.DisplayOrder,
+			Name:                 rule.Name,
+			Description:          rule.Description,
+			FormulaAstExpression: rule.FormulaAstExpression,
+			ScoreModifier:        rule.ScoreModifier,
+			RuleGroup:            rule.RuleGroup,
+		}
+	}
+	return ScenarioBundleIteration{
+		Version:                       iteration.Version,
+		TriggerConditionAstExpression: iteration.TriggerConditionAstExpression,
+		Rules:                         rules,
+		ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+		ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+		BatchTriggerSQL:               iteration.BatchTriggerSQL,
+		Schedule:                      iteration.Schedule,
+		ScheduleTimezone:              iteration.ScheduleTimezone,
+		OutputVariables:               iteration.OutputVariables,
+	}
+}
+
+func (iteration ScenarioBundleIteration) astExpressions() []ast.Node {
+	nodes := make([]ast.Node, 0, len(iteration.Rules)+len(iteration.OutputVariables)+1)
+	if iteration.TriggerConditionAstExpression != nil {
+		nodes = append(nodes, *iteration.TriggerConditionAstExpression)
+	}
+	for _, rule := range iteration.Rules {
+		if rule.FormulaAstExpression != nil {
+			nodes = append(nodes, *rule.FormulaAstExpression)
+		}
+	}
+	for _, outputVariable := range iteration.OutputVariables {
+		if outputVariable.FormulaAstExpression != nil {
+			nodes = append(nodes, *outputVariable.FormulaAstExpression)
+		}
+	}
+	return nodes
+}
+
+// CreateScenarioIterationBody returns the body to create the iteration in the target organization, with the custom
+// list ids of its formulas replaced using customListIds (source id => target id).
+func (iteration ScenarioBundleIteration) CreateScenarioIterationBody(customListIds map[string]string) CreateScenarioIterationBody {
+	rules := make([]CreateRuleInput, len(iteration.Rules))
+	for i, rule := range iteration.Rules {
+		rules[i] = CreateRuleInput{
+			DisplayOrder:         rule.DisplayOrder,
+			Name:                 rule.Name,
+			Description:          rule.Description,
+			FormulaAstExpression: mapCustomListIds(rule.FormulaAstExpression, customListIds),
+			ScoreModifier:        rule.ScoreModifier,
+			RuleGroup:            rule.RuleGroup,
+		}
+	}
+	outputVariables := make([]OutputVariable, len(iteration.OutputVariables))
+	for i, outputVariable := range iteration.OutputVariables {
+		outputVariables[i] = OutputVariable{
+			Name:                 outputVariable.Name,
+			FormulaAstExpression: mapCustomListIds(outputVariable.FormulaAstExpression, customListIds),
+		}
+	}
+	return CreateScenarioIterationBody{
+		TriggerConditionAstExpression: mapCustomListIds(iteration.TriggerConditionAstExpression, customListIds),
+		Rules:                         rules,
+		ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+		ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+		BatchTriggerSQL:               iteration.BatchTriggerSQL,
+		Schedule:                      iteration.Schedule,
+		ScheduleTimezone:              iteration.ScheduleTimezone,
+		OutputVariables:               outputVariables,
+	}
+}
+
+func mapCustomListIds(node *ast.Node, customListIds map[string]string) *ast.Node {
+	if node == nil {
+		return nil
+	}
+	mapped := mapNodeCustomListIds(*node, customListIds)
+	return &mapped
+}
+
+func mapNodeCustomListIds(node ast.Node, customListIds map[string]string) ast.Node {
+	if node.Function == ast.FUNC_CUSTOM_LIST_ACCESS {
+		if id, err := node.ReadConstantNamedChildString(ast.AttributeFuncCustomListAccess.ArgumentCustomListId); err == nil {
+			if targetId, ok := customListIds[id]; ok {
+				return ast.NewNodeCustomListAccess(targetId)
+			}
+		}
+	}
+
+	mapped := ast.Node{Function: node.Function, Constant: node.Constant}
+	for _, child := range node.Children {
+		mapped = mapped.AddChild(mapNodeCustomListIds(child, customListIds))
+	}
+	for name, child := range node.NamedChildren {
+		mapped = mapped.AddNamedChild(name, mapNodeCustomListIds(child, customListIds))
+	}
+	return mapped
+}
+
+// ScenarioAstReferences are the data model fields and the custom lists read by the formulas of a scenario
+type ScenarioAstReferences struct {
+	Fields        []ScenarioBundleFieldRequirement
+	CustomListIds []string
+}
+
+// CollectScenarioAstReferences walks the formulas of the iterations. The data types of the fields are not set.
+func CollectScenarioAstReferences(triggerObjectType string, iterations []ScenarioBundleIteration) ScenarioAstReferences {
+	collector := astReferencesCollector{
+		triggerObjectType: triggerObjectType,
+		fields:            make(map[string]ScenarioBundleFieldRequirement),
+		customListIds:     make(map[string]bool),
+	}
+	for _, iteration := range iterations {
+		for _, node := range iteration.astExpressions() {
+			collector.collect(node)
+		}
+	}
+
+	references := ScenarioAstReferences{
+		Fields:        make([]ScenarioBundleFieldRequirement, 0, len(collector.fields)),
+		CustomListIds: make([]string, 0, len(collector.customListIds)),
+	}
+	for _, field := range collector.fields {
+		references.Fields = append(references.Fields, field)
+	}
+	slices.SortFunc(references.Fields, func(a, b ScenarioBundleFieldRequirement) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	for id := range collector.customListIds {
+		references.CustomListIds = append(references.CustomListIds, id)
+	}
+	slices.Sort(references.CustomListIds)
+	return references
+}
+
+type astReferencesCollector struct {
+	triggerObjectType string
+	fields            map[string]ScenarioBundleFieldRequirement
+	customListIds     map[string]bool
+}
+
+func (collector astReferencesCollector) addField(tableName string, path []string, fieldName string) {
+	if tableName == "" || fieldName == "" {
+		return
+	}
+	field := ScenarioBundleFieldRequirement{
+		TableName: tableName,
+		Path:      path,
+		FieldName: fieldName,
+		DataType:  UnknownDataType,
+	}
+	collector.fields[field.key()] = field
+}
+
+func (collector astReferencesCollector) collect(node ast.Node) {
+	switch node.Function {
+	case ast.FUNC_PAYLOAD:
+		if len(node.Children) > 0 {
+			if fieldName, ok := node.Children[0].Constant.(string); ok {
+				collector.addField(collector.triggerObjectType, nil, fieldName)
+			}
+		}
+	case ast.FUNC_DB_ACCESS:
+		tableName, _ := node.ReadConstantNamedChildString(ast.AttributeFuncDbAccess.ArgumentTableName)
+		fieldName, _ := node.ReadConstantNamedChildString(ast.AttributeFuncDbAccess.ArgumentFieldName)
+		collector.addField(tableName, readPath(node.NamedChildren[ast.AttributeFuncDbAccess.ArgumentPathName]), fieldName)
+	case ast.FUNC_AGGREGATOR:
+		tableName, _ := node.ReadConstantNamedChildString(ast.AggregatorArgumentTableName)
+		fieldName, _ := node.ReadConstantNamedChildString(ast.AggregatorArgumentFieldName)
+		collector.addField(tableName, nil, fieldName)
+	case ast.FUNC_FILTER:
+		tableName, _ := node.ReadConstantNamedChildString(ast.FilterArgumentTableName)
+		fieldName, _ := node.ReadConstantNamedChildString(ast.FilterArgumentFieldName)
+		collector.addField(tableName, nil, fieldName)
+	case ast.FUNC_CUSTOM_LIST_ACCESS:
+		if id, err := node.ReadConstantNamedChildString(
+			ast.AttributeFuncCustomListAccess.ArgumentCustomListId); err == nil {
+			collector.customListIds[id] = true
+		}
+	}
+
+	for _, child := range node.Children {
+		collector.collect(child)
+	}
+	for _, child := range node.NamedChildren {
+		collector.collect(child)
+	}
+}
+
+// readPath reads the path of a database access node, a constant list of link names
+func readPath(node ast.Node) []string {
+	var path []string
+	switch constant := node.Constant.(type) {
+	case []string:
+		path = constant
+	case []any:
+		for _, link := range constant {
+			if linkName, ok := link.(string); ok {
+				path = append(path, linkName)
+			}
+		}
+	}
+	return path
+}
+
+// ResolveField returns the field required in the data model, following the links of the path
+func (dataModel DataModel) ResolveField(requirement ScenarioBundleFieldRequirement) (Field, error) {
+	table, ok := dataModel.Tables[requirement.TableName]
+	if !ok {
+		return Field{}, fmt.Errorf("table %s not found", requirement.TableName)
+	}
+	for _, linkName := range requirement.Path {
+		link, ok := table.LinksToSingle[linkName]
+		if !ok {
+			return Field{}, fmt.Errorf("link %s not found in table %s", linkName, table.Name)
+		}
+		table, ok = dataModel.Tables[link.ParentTableName]
+		if !ok {
+			return Field{}, fmt.Errorf("table %s not found", link.ParentTableName)
+		}
+	}
+	field, ok := table.Fields[requirement.FieldName]
+	if !ok {
+		return Field{}, fmt.Errorf("field %s not found in table %s", requirement.FieldName, table.Name)
+	}
+	return field, nil
+}
+
+type ScenarioImportConflictKind string
+
+const (
+	ScenarioImportConflictUnsupportedVersion ScenarioImportConflictKind = "unsupported_version"
+	ScenarioImportConflictInvalidBundle      ScenarioImportConflictKind = "invalid_bundle"
+	ScenarioImportConflictScenarioName       ScenarioImportConflictKind = "scenario_name_taken"
+	ScenarioImportConflictMissingTable       ScenarioImportConflictKind = "missing_table"
+	ScenarioImportConflictMissingField       ScenarioImportConflictKind = "missing_field"
+	ScenarioImportConflictFieldType          ScenarioImportConflictKind = "field_type_mismatch"
+	ScenarioImportConflictMissingCustomList  ScenarioImportConflictKind = "missing_custom_list"
+)
+
+type ScenarioImportConflict struct {
+	Kind    ScenarioImportConflictKind
+	Message string
+}
+
+// ScenarioImportCustomList tells how a custom list of the bundle is mapped in the target organization: to an existing
+// list with the same name, or to a list created by the import.
+type ScenarioImportCustomList struct {
+	Name     string
+	SourceId string
+	TargetId *string
+	Create   bool
+}
+
+type ScenarioImportReport struct {
+	Conflicts   []ScenarioImportConflict
+	CustomLists []ScenarioImportCustomList
+}
+
+type ScenarioImportResult struct {
+	Report ScenarioImportReport
+	// Scenario is set only if the scenario was created
+	Scenario *Scenario
+}
+
+// ValidateScenarioBundle checks that the bundle can be imported in an organization with the given data model,
+// scenarios and custom lists, and plans the mapping of the custom lists. Nothing is created if there are conflicts.
+func ValidateScenarioBundle(
+	bundle ScenarioBundle,
+	dataModel DataModel,
+	existingScenarios []Scenario,
+	existingCustomLists []CustomList,
+) ScenarioImportReport {
+	report := ScenarioImportReport{
+		Conflicts:   make([]ScenarioImportConflict, 0),
+		CustomLists: make([]ScenarioImportCustomList, 0, len(bundle.CustomLists)),
+	}
+	addConflict := func(kind ScenarioImportConflictKind, format string, args ...any) {
+		report.Conflicts = append(report.Conflicts, ScenarioImportConflict{
+			Kind:    kind,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if bundle.Version != SCENARIO_BUNDLE_VERSION {
+		addConflict(ScenarioImportConflictUnsupportedVersion,
+			"bundle version %d is not supported, expected version %d", bundle.Version, SCENARIO_BUNDLE_VERSION)
+		return report
+	}
+	if bundle.Scenario.Name == "" {
+		addConflict(ScenarioImportConflictInvalidBundle, "the scenario has no name")
+	}
+	if len(bundle.Iterations) == 0 {
+		addConflict(ScenarioImportConflictInvalidBundle, "the bundle has no iteration")
+	}
+	nbDrafts := 0
+	versions := make(map[int]bool)
+	for _, iteration := range bundle.Iterations {
+		if iteration.Version == nil {
+			nbDrafts++
+		} else if versions[*iteration.Version] {
+			addConflict(ScenarioImportConflictInvalidBundle, "version %d appears several times", *iteration.Version)
+		} else {
+			versions[*iteration.Version] = true
+		}
+	}
+	if nbDrafts > 1 {
+		addConflict(ScenarioImportConflictInvalidBundle, "the bundle has %d draft iterations, at most one is allowed", nbDrafts)
+	}
+
+	for _, scenario := range existingScenarios {
+		if scenario.Name == bundle.Scenario.Name {
+			addConflict(ScenarioImportConflictScenarioName, "a scenario named %s already exists", scenario.Name)
+		}
+	}
+
+	if _, ok := dataModel.Tables[bundle.Scenario.TriggerObjectType]; !ok {
+		addConflict(ScenarioImportConflictMissingTable,
+			"trigger object type %s is not a table of the data model", bundle.Scenario.TriggerObjectType)
+	}
+
+	expectedTypes := make(map[string]DataType, len(bundle.DataModel))
+	for _, requirement := range bundle.DataModel {
+		expectedTypes[requirement.key()] = requirement.DataType
+	}
+	references := CollectScenarioAstReferences(bundle.Scenario.TriggerObjectType, bundle.Iterations)
+	for _, requirement := range references.Fields {
+		field, err := dataModel.ResolveField(requirement)
+		if err != nil {
+			addConflict(ScenarioImportConflictMissingField, "%s: %s", requirement, err)
+			continue
+		}
+		expectedType, ok := expectedTypes[requirement.key()]
+		if ok && expectedType != UnknownDataType && expectedType != field.DataType {
+			addConflict(ScenarioImportConflictFieldType, "%s: expected type %s, found %s",
+				requirement, expectedType, field.DataType)
+		}
+	}
+
+	bundleCustomLists := make(map[string]bool, len(bundle.CustomLists))
+	for _, customList := range bundle.CustomLists {
+		bundleCustomLists[customList.Id] = true
+		mapping := ScenarioImportCustomList{Name: customList.Name, SourceId: customList.Id, Create: true}
+		for _, existing := range existingCustomLists {
+			if existing.Name == customList.Name {
+				mapping.TargetId = &existing.Id
+				mapping.Create = false
+				break
+			}
+		}
+		report.CustomLists = append(report.CustomLists, mapping)
+	}
+	for _, id := range references.CustomListIds {
+		if !bundleCustomLists[id] {
+			addConflict(ScenarioImportConflictMissingCustomList,
+				"custom list %s is used by the scenario but is not part of the bundle", id)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+func bundleTestDataModel() DataModel {
+	return DataModel{Tables: map[string]Table{
+		"transactions": {
+			Name: "transactions",
+			Fields: map[string]Field{
+				"amount":     {Name: "amount", DataType: Float},
+				"account_id": {Name: "account_id", DataType: String},
+			},
+			LinksToSingle: map[string]LinkToSingle{
+				"account": {Name: "account", ParentTableName: "accounts"},
+			},
+		},
+		"accounts": {
+			Name:   "accounts",
+			Fields: map[string]Field{"name": {Name: "name", DataType: String}},
+		},
+	}}
+}
+
+func bundleTestIteration() ScenarioBundleIteration {
+	trigger := newTestNode(ast.FUNC_GREATER).
+		AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("amount"))).
+		AddChild(ast.NewNodeConstant(10))
+	formula := newTestNode(ast.FUNC_IS_IN_LIST).
+		AddChild(ast.NewNodeDatabaseAccess("transactions", "name", []string{"account"})).
+		AddChild(ast.NewNodeCustomListAccess("list-1"))
+	return ScenarioBundleIteration{
+		Version:                       ptr(1),
+		TriggerConditionAstExpression: &trigger,
+		Rules:                         []ScenarioBundleRule{{Name: "rule", FormulaAstExpression: &formula}},
+	}
+}
+
+func newTestNode(f ast.Function) ast.Node {
+	return ast.Node{Function: f}
+}
+
+func TestCollectScenarioAstReferences(t *testing.T) {
+	references := CollectScenarioAstReferences("transactions", []ScenarioBundleIteration{bundleTestIteration()})
+
+	assert.Equal(t, []ScenarioBundleFieldRequirement{
+		{TableName: "transactions", Path: []string{"account"}, FieldName: "name", DataType: UnknownDataType},
+		{TableName: "transactions", FieldName: "amount", DataType: UnknownDataType},
+	}, references.Fields)
+	assert.Equal(t, []string{"list-1"}, references.CustomListIds)
+}
+
+func TestScenarioBundleIteration_CreateScenarioIterationBody_maps_custom_lists(t *testing.T) {
+	body := bundleTestIteration().CreateScenarioIterationBody(map[string]string{"list-1": "target-list"})
+
+	listAccess := body.Rules[0].FormulaAstExpression.Children[1]
+	id, err := listAccess.ReadConstantNamedChildString("customListId")
+	assert.NoError(t, err)
+	assert.Equal(t, "target-list", id)
+}
+
+func TestValidateScenarioBundle(t *testing.T) {
+	bundle := ScenarioBundle{
+		Version:     SCENARIO_BUNDLE_VERSION,
+		Scenario:    ScenarioBundleScenario{Name: "scenario", TriggerObjectType: "transactions"},
+		Iterations:  []ScenarioBundleIteration{bundleTestIteration()},
+		CustomLists: []ScenarioBundleCustomList{{Id: "list-1", Name: "blocked accounts"}},
+		DataModel: []ScenarioBundleFieldRequirement{
+			{TableName: "transactions", FieldName: "amount", DataType: Float},
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		report := ValidateScenarioBundle(bundle, bundleTestDataModel(), nil,
+			[]CustomList{{Id: "existing", Name: "blocked accounts"}})
+		assert.Empty(t, report.Conflicts)
+		assert.Equal(t, []ScenarioImportCustomList{
+			{Name: "blocked accounts", SourceId: "list-1", TargetId: ptr("existing"), Create: false},
+		}, report.CustomLists)
+	})
+
+	t.Run("custom list to create", func(t *testing.T) {
+		report := ValidateScenarioBundle(bundle, bundleTestDataModel(), nil, nil)
+		assert.Empty(t, report.Conflicts)
+		assert.True(t, report.CustomLists[0].Create)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		dataModel := bundleTestDataModel()
+		dataModel.Tables["accounts"] = Table{Name: "accounts"}
+		dataModel.Tables["transactions"].Fields["amount"] = Field{Name: "amount", DataType: Int}
+		invalidBundle := bundle
+		invalidBundle.CustomLists = nil
+
+		report := ValidateScenarioBundle(invalidBundle, dataModel, []Scenario{{Name: "scenario"}}, nil)
+
+		kinds := make([]ScenarioImportConflictKind, len(report.Conflicts))
+		for i, conflict := range report.Conflicts {
+			kinds[i] = conflict.Kind
+		}
+		assert.Equal(t, []ScenarioImportConflictKind{
+			ScenarioImportConflictScenarioName,
+			ScenarioImportConflictMissingField,
+			ScenarioImportConflictFieldType,
+			ScenarioImportConflictMissingCustomList,
+		}, kinds)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		invalidBundle := bundle
+		invalidBundle.Version = SCENARIO_BUNDLE_VERSION + 1
+		report := ValidateScenarioBundle(invalidBundle, bundleTestDataModel(), nil, nil)
+		assert.Len(t, report.Conflicts, 1)
+		assert.Equal(t, ScenarioImportConflictUnsupportedVersion, report.Conflicts[0].Kind)
+	})
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+type ValueChange[T any] struct {
+	Before T
+	After  T
+}
+
+func newValueChange[T comparable](before, after T) *ValueChange[T] {
+	if before == after {
+		return nil
+	}
+	return &ValueChange[T]{Before: before, After: after}
+}
+
+func newPtrValueChange[T comparable](before, after *T) *ValueChange[*T] {
+	if before == nil && after == nil || before != nil && after != nil && *before == *after {
+		return nil
+	}
+	return &ValueChange[*T]{Before: before, After: after}
+}
+
+// RuleDiff describes how a rule present in both iterations changed. A nil change means the value is unchanged.
+type RuleDiff struct {
+	BaseRule             Rule
+	TargetRule           Rule
+	Name                 *ValueChange[string]
+	Description          *ValueChange[string]
+	ScoreModifier        *ValueChange[int]
+	RuleGroup            *ValueChange[string]
+	DisplayOrder         *ValueChange[int]
+	FormulaAstExpression []ast.NodeDiff
+}
+
+func (diff RuleDiff) HasChanges() bool {
+	return diff.Name != nil ||
+		diff.Description != nil ||
+		diff.ScoreModifier != nil ||
+		diff.RuleGroup != nil ||
+		diff.DisplayOrder != nil ||
+		len(diff.FormulaAstExpression) > 0
+}
+
+// OutputVariableDiff describes how the formula of an output variable present in both iterations changed
+type OutputVariableDiff struct {
+	Name                 string
+	FormulaAstExpression []ast.NodeDiff
+}
+
+type ScenarioIterationDiff struct {
+	BaseIterationId               string
+	TargetIterationId             string
+	ScoreReviewThreshold          *ValueChange[*int]
+	ScoreRejectThreshold          *ValueChange[*int]
+	TriggerConditionAstExpression []ast.NodeDiff
+	BatchTriggerSQL               *ValueChange[string]
+	Schedule                      *ValueChange[string]
+	ScheduleTimezone              *ValueChange[string]
+	AddedRules                    []Rule
+	RemovedRules                  []Rule
+	ModifiedRules                 []RuleDiff
+	AddedOutputVariables          []OutputVariable
+	RemovedOutputVariables        []OutputVariable
+	ModifiedOutputVariables       []OutputVariableDiff
+}
+
+// DiffScenarioIterations compares the target iteration to the base iteration.
+// Rules are matched by their stable id (the snooze group id, kept when an iteration is copied) when both rules
+// have one, and otherwise by name. Output variables are matched by name.
+func DiffScenarioIterations(base, target ScenarioIteration) ScenarioIterationDiff {
+	diff := ScenarioIterationDiff{
+		BaseIterationId:   base.Id,
+		TargetIterationId: target.Id,
+		ScoreReviewThreshold: newPtrValueChange(
+			base.ScoreReviewThreshold, target.ScoreReviewThreshold),
+		ScoreRejectThreshold: newPtrValueChange(
+			base.ScoreRejectThreshold, target.ScoreRejectThreshold),
+		TriggerConditionAstExpression: ast.DiffNodes(
+			base.TriggerConditionAstExpression, target.TriggerConditionAstExpression),
+		BatchTriggerSQL:         newValueChange(base.BatchTriggerSQL, target.BatchTriggerSQL),
+		Schedule:                newValueChange(base.Schedule, target.Schedule),
+		ScheduleTimezone:        newValueChange(base.ScheduleTimezone, target.ScheduleTimezone),
+		AddedRules:              make([]Rule, 0),
+		RemovedRules:            make([]Rule, 0),
+		ModifiedRules:           make([]RuleDiff, 0),
+		AddedOutputVariables:    make([]OutputVariable, 0),
+		RemovedOutputVariables:  make([]OutputVariable, 0),
+		ModifiedOutputVariables: make([]OutputVariableDiff, 0),
+	}
+
+	matchedBaseRules := make(map[int]bool, len(base.Rules))
+	for _, targetRule := range target.Rules {
+		baseIdx := matchRule(base.Rules, matchedBaseRules, targetRule)
+		if baseIdx < 0 {
+			diff.AddedRules = append(diff.AddedRules, targetRule)
+			continue
+		}
+		matchedBaseRules[baseIdx] = true
+
+		ruleDiff := diffRules(base.Rules[baseIdx], targetRule)
+		if ruleDiff.HasChanges() {
+			diff.ModifiedRules = append(diff.ModifiedRules, ruleDiff)
+		}
+	}
+
+	for i, baseRule := range base.Rules {
+		if !matchedBaseRules[i] {
+			diff.RemovedRules = append(diff.RemovedRules, baseRule)
+		}
+	}
+
+	baseOutputVariables := make(map[string]OutputVariable, len(base.OutputVariables))
+	for _, outputVariable := range base.OutputVariables {
+		baseOutputVariables[outputVariable.Name] = outputVariable
+	}
+	for _, targetOutputVariable := range target.OutputVariables {
+		baseOutputVariable, ok := baseOutputVariables[targetOutputVariable.Name]
+		if !ok {
+			diff.AddedOutputVariables = append(diff.AddedOutputVariables, targetOutputVariable)
+			continue
+		}
+		delete(baseOutputVariables, targetOutputVariable.Name)
+
+		formulaDiff := ast.DiffNodes(baseOutputVariable.FormulaAstExpression, targetOutputVariable.FormulaAstExpression)
+		if len(formulaDiff) > 0 {
+			diff.ModifiedOutputVariables = append(diff.ModifiedOutputVariables, OutputVariableDiff{
+				Name:                 targetOutputVariable.Name,
+				FormulaAstExpression: formulaDiff,
+			})
+		}
+	}
+	// keep the order of the base iteration
+	for _, baseOutputVariable := range base.OutputVariables {
+		if _, ok := baseOutputVariables[baseOutputVariable.Name]; ok {
+			diff.RemovedOutputVariables = append(diff.RemovedOutputVariables, baseOutputVariable)
+		}
+	}
+
+	return diff
+}
+
+// matchRule returns the index of the unmatched base rule corresponding to the target rule, or -1
+func matchRule(baseRules []Rule, matched map[int]bool, targetRule Rule) int {
+	if targetRule.SnoozeGroupId != nil {
+		for i, baseRule := range baseRules {
+			if !matched[i] && baseRule.SnoozeGroupId != nil && *baseRule.SnoozeGroupId == *targetRule.SnoozeGroupId {
+				return i
+			}
+		}
+	}
+	for i, baseRule := range baseRules {
+		if matched[i] || baseRule.Name != targetRule.Name {
+			continue
+		}
+		// rules that both have a stable id are only matched on it
+		if baseRule.SnoozeGroupId != nil && targetRule.SnoozeGroupId != nil {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func diffRules(base, target Rule) RuleDiff {
+	return RuleDiff{
+		BaseRule:             base,
+		TargetRule:           target,
+		Name:                 newValueChange(base.Name, target.Name),
+		Description:          newValueChange(base.Description, target.Description),
+		ScoreModifier:        newValueChange(base.ScoreModifier, target.ScoreModifier),
+		RuleGroup:            newValueChange(base.RuleGroup, target.RuleGroup),
+		DisplayOrder:         newValueChange(base.DisplayOrder, target.DisplayOrder),
+		FormulaAstExpression: ast.DiffNodes(base.FormulaAstExpression, target.FormulaAstExpression),
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+func TestDiffScenarioIterations(t *testing.T) {
+	formula := ast.NewNodeConstant(true)
+	otherFormula := ast.NewNodeConstant(false)
+
+	base := ScenarioIteration{
+		Id:                   "base",
+		ScoreReviewThreshold: ptr(10),
+		ScoreRejectThreshold: ptr(20),
+		Rules: []Rule{
+			{Id: "b1", Name: "renamed", SnoozeGroupId: ptr("group-1"), FormulaAstExpression: &formula, ScoreModifier: 5},
+			{Id: "b2", Name: "unchanged", FormulaAstExpression: &formula},
+			{Id: "b3", Name: "removed"},
+			{Id: "b4", Name: "modified", FormulaAstExpression: &formula, ScoreModifier: 1},
+		},
+	}
+	target := ScenarioIteration{
+		Id:                            "target",
+		ScoreReviewThreshold:          ptr(10),
+		ScoreRejectThreshold:          ptr(30),
+		TriggerConditionAstExpression: &formula,
+		Rules: []Rule{
+			{Id: "t1", Name: "new name", SnoozeGroupId: ptr("group-1"), FormulaAstExpression: &formula, ScoreModifier: 5},
+			{Id: "t2", Name: "unchanged", FormulaAstExpression: &formula},
+			{Id: "t4", Name: "modified", FormulaAstExpression: &otherFormula, ScoreModifier: 2},
+			{Id: "t5", Name: "added"},
+		},
+	}
+
+	diff := DiffScenarioIterations(base, target)
+
+	assert.Equal(t, "base", diff.BaseIterationId)
+	assert.Equal(t, "target", diff.TargetIterationId)
+	assert.Nil(t, diff.ScoreReviewThreshold)
+	assert.Equal(t, &ValueChange[*int]{Before: ptr(20), After: ptr(30)}, diff.ScoreRejectThreshold)
+	assert.Len(t, diff.TriggerConditionAstExpression, 1)
+	assert.Equal(t, ast.NodeAdded, diff.TriggerConditionAstExpression[0].Change)
+
+	assert.Len(t, diff.AddedRules, 1)
+	assert.Equal(t, "t5", diff.AddedRules[0].Id)
+	assert.Len(t, diff.RemovedRules, 1)
+	assert.Equal(t, "b3", diff.RemovedRules[0].Id)
+
+	assert.Len(t, diff.ModifiedRules, 2)
+	renamed := diff.ModifiedRules[0]
+	assert.Equal(t, "b1", renamed.BaseRule.Id)
+	assert.Equal(t, "t1", renamed.TargetRule.Id)
+	assert.Equal(t, &ValueChange[string]{Before: "renamed", After: "new name"}, renamed.Name)
+	assert.Nil(t, renamed.ScoreModifier)
+	assert.Empty(t, renamed.FormulaAstExpression)
+
+	modified := diff.ModifiedRules[1]
+	assert.Equal(t, "b4", modified.BaseRule.Id)
+	assert.Nil(t, modified.Name)
+	assert.Equal(t, &ValueChange[int]{Before: 1, After: 2}, modified.ScoreModifier)
+	assert.Len(t, modified.FormulaAstExpression, 1)
+	assert.Equal(t, ast.NodeModified, modified.FormulaAstExpression[0].Change)
+}
+
+func TestDiffScenarioIterations_stable_id_takes_precedence_over_name(t *testing.T) {
+	base := ScenarioIteration{Rules: []Rule{{Id: "b1", Name: "rule", SnoozeGroupId: ptr("group-1")}}}
+	target := ScenarioIteration{Rules: []Rule{{Id: "t1", Name: "rule", SnoozeGroupId: ptr("group-2")}}}
+
+	diff := DiffScenarioIterations(base, target)
+
+	assert.Len(t, diff.AddedRules, 1)
+	assert.Len(t, diff.RemovedRules, 1)
+	assert.Empty(t, diff.ModifiedRules)
+}
+
+func TestDiffScenarioIterations_settings_and_output_variables(t *testing.T) {
+	formula := ast.NewNodeConstant(true)
+	otherFormula := ast.NewNodeConstant(false)
+
+	base := ScenarioIteration{
+		BatchTriggerSQL:  "amount > 100",
+		Schedule:         "0 * * * *",
+		ScheduleTimezone: "Europe/Paris",
+		Rules:            []Rule{{Id: "b1", Name: "rule", DisplayOrder: 1}},
+		OutputVariables: []OutputVariable{
+			{Name: "unchanged", FormulaAstExpression: &formula},
+			{Name: "modified", FormulaAstExpression: &formula},
+			{Name: "removed", FormulaAstExpression: &formula},
+		},
+	}
+	target := ScenarioIteration{
+		BatchTriggerSQL:  "amount > 100",
+		Schedule:         "0 0 * * *",
+		ScheduleTimezone: "UTC",
+		Rules:            []Rule{{Id: "t1", Name: "rule", DisplayOrder: 2}},
+		OutputVariables: []OutputVariable{
+			{Name: "added", FormulaAstExpression: &formula},
+			{Name: "modified", FormulaAstExpression: &otherFormula},
+			{Name: "unchanged", FormulaAstExpression: &formula},
+		},
+	}
+
+	diff := DiffScenarioIterations(base, target)
+
+	assert.Nil(t, diff.BatchTriggerSQL)
+	assert.Equal(t, &ValueChange[string]{Before: "0 * * * *", After: "0 0 * * *"}, diff.Schedule)
+	assert.Equal(t, &ValueChange[string]{Before: "Europe/Paris", After: "UTC"}, diff.ScheduleTimezone)
+
+	assert.Len(t, diff.ModifiedRules, 1)
+	assert.Equal(t, &ValueChange[int]{Before: 1, After: 2}, diff.ModifiedRules[0].DisplayOrder)
+
+	assert.Equal(t, []OutputVariable{target.OutputVariables[0]}, diff.AddedOutputVariables)
+	assert.Equal(t, []OutputVariable{base.OutputVariables[2]}, diff.RemovedOutputVariables)
+	assert.Len(t, diff.ModifiedOutputVariables, 1)
+	assert.Equal(t, "modified", diff.ModifiedOutputVariables[0].Name)
+	assert.Len(t, diff.ModifiedOutputVariables[0].FormulaAstExpression, 1)
+}
@@ -19,6 +19,16 @@ type ScenarioIteration struct {
 	ScoreRejectThreshold          *int
 	BatchTriggerSQL               string
 	Schedule                      string
+	// IANA timezone in which the schedule is interpreted. If empty, the default timezone of the scheduler is used.
+	ScheduleTimezone string
+	OutputVariables  []OutputVariable
+}
+
+// Output variables are named expressions evaluated along with the rules of the iteration. Their values are stored
+// with the decision and returned to the client, so that it can act on computed values without querying them again.
+type OutputVariable struct {
+	Name                 string
+	FormulaAstExpression *ast.Node
 }
 
 type GetScenarioIterationFilters struct {
@@ -37,6 +47,8 @@ type CreateScenarioIterationBody struct {
 	ScoreRejectThreshold          *int
 	BatchTriggerSQL               string
 	Schedule                      string
+	ScheduleTimezone              string
+	OutputVariables               []OutputVariable
 }
 
 type UpdateScenarioIterationInput struct {
@@ -50,4 +62,6 @@ type UpdateScenarioIterationBody struct {
 	ScoreRejectThreshold          *int
 	BatchTriggerSQL               *string
 	Schedule                      *string
+	ScheduleTimezone              *string
+	OutputVariables               *[]OutputVariable
 }
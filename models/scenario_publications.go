@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/cockroachdb/errors"
 )
 
 type ScenarioPublication struct {
@@ -64,6 +65,8 @@ type PublishedScenarioIterationBody struct {
 	ScoreRejectThreshold          int
 	BatchTriggerSQL               string
 	Schedule                      string
+	ScheduleTimezone              string
+	OutputVariables               []OutputVariable
 }
 
 func NewPublishedScenarioIteration(si ScenarioIteration) (PublishedScenarioIteration, error) {
@@ -74,12 +77,21 @@ func NewPublishedScenarioIteration(si ScenarioIteration) (PublishedScenarioItera
 		UpdatedAt:  si.UpdatedAt,
 	}
 
-	result.Version = *si.Version
+	// draft iterations have no version yet, they can only be evaluated in dry runs
+	if si.Version != nil {
+		result.Version = *si.Version
+	}
+	if si.ScoreReviewThreshold == nil || si.ScoreRejectThreshold == nil {
+		return PublishedScenarioIteration{}, errors.Wrap(ErrScenarioIterationNotValid,
+			"scenario iteration has no score thresholds")
+	}
 	result.Body.ScoreReviewThreshold = *si.ScoreReviewThreshold
 	result.Body.ScoreRejectThreshold = *si.ScoreRejectThreshold
 	result.Body.Rules = si.Rules
 	result.Body.BatchTriggerSQL = si.BatchTriggerSQL
 	result.Body.Schedule = si.Schedule
+	result.Body.ScheduleTimezone = si.ScheduleTimezone
+	result.Body.OutputVariables = si.OutputVariables
 	if si.TriggerConditionAstExpression != nil {
 		result.Body.TriggerConditionAstExpression = *si.TriggerConditionAstExpression
 	}
@@ -0,0 +1,33 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func TestNewPublishedScenarioIteration(t *testing.T) {
+	t.Run("draft iteration", func(t *testing.T) {
+		published, err := models.NewPublishedScenarioIteration(models.ScenarioIteration{
+			Id:                   "iteration",
+			ScoreReviewThreshold: utils.Ptr(10),
+			ScoreRejectThreshold: utils.Ptr(20),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published.Version)
+		assert.Equal(t, 10, published.Body.ScoreReviewThreshold)
+		assert.Equal(t, 20, published.Body.ScoreRejectThreshold)
+	})
+
+	t.Run("missing thresholds", func(t *testing.T) {
+		_, err := models.NewPublishedScenarioIteration(models.ScenarioIteration{
+			Id:                   "iteration",
+			Version:              utils.Ptr(1),
+			ScoreReviewThreshold: utils.Ptr(10),
+		})
+		assert.ErrorIs(t, err, models.ErrScenarioIterationNotValid)
+	})
+}
@@ -0,0 +1,369 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+type ScenarioTemplatePlaceholderKind string
+
+const (
+	ScenarioTemplatePlaceholderTable      ScenarioTemplatePlaceholderKind = "table"
+	ScenarioTemplatePlaceholderField      ScenarioTemplatePlaceholderKind = "field"
+	ScenarioTemplatePlaceholderNumber     ScenarioTemplatePlaceholderKind = "number"
+	ScenarioTemplatePlaceholderString     ScenarioTemplatePlaceholderKind = "string"
+	ScenarioTemplatePlaceholderCustomList ScenarioTemplatePlaceholderKind = "custom_list"
+)
+
+var scenarioTemplatePlaceholderKinds = []ScenarioTemplatePlaceholderKind{
+	ScenarioTemplatePlaceholderTable,
+	ScenarioTemplatePlaceholderField,
+	ScenarioTemplatePlaceholderNumber,
+	ScenarioTemplatePlaceholderString,
+	ScenarioTemplatePlaceholderCustomList,
+}
+
+// Placeholders are written {{name}} in the strings of the template: the names of the tables and fields, the constants
+// of the formulas, the names and descriptions of the scenario and of its rules.
+var scenarioTemplatePlaceholderRegexp = regexp.MustCompile(`\{\{([a-zA-Z_][a-zA-Z0-9_]*)\}\}`)
+
+var scenarioTemplatePlaceholderNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ScenarioTemplatePlaceholder is a value chosen when the template is instantiated in an organization. A placeholder
+// without default value is required, except for the custom lists which are always chosen in the organization.
+type ScenarioTemplatePlaceholder struct {
+	Name         string
+	Kind         ScenarioTemplatePlaceholderKind
+	Description  string
+	DefaultValue *string
+}
+
+// ScenarioTemplate is a scenario of the library shared by all the organizations, in the format of a scenario bundle
+// whose strings may contain placeholders. It references no custom list of its own: the custom lists used by its
+// formulas are placeholders, mapped to lists of the organization.
+type ScenarioTemplate struct {
+	Id           string
+	Name         string
+	Description  string
+	Placeholders []ScenarioTemplatePlaceholder
+	Bundle       ScenarioBundle
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type CreateScenarioTemplateInput struct {
+	Name         string
+	Description  string
+	Placeholders []ScenarioTemplatePlaceholder
+	Bundle       ScenarioBundle
+}
+
+type UpdateScenarioTemplateInput struct {
+	Id           string
+	Name         *string
+	Description  *string
+	Placeholders *[]ScenarioTemplatePlaceholder
+	Bundle       *ScenarioBundle
+}
+
+type InstantiateScenarioTemplateInput struct {
+	TemplateId     string
+	OrganizationId string
+	// ScenarioName replaces the name of the scenario of the template if it is not empty
+	ScenarioName string
+	// Values of the placeholders by name. The value of a custom list placeholder is the id of a list of the organization.
+	Values map[string]string
+	DryRun bool
+}
+
+// ScenarioTemplateIterationValidation holds the errors found by the scenario validation in an instantiated iteration.
+// They do not prevent the instantiation: the iterations are created as drafts, to be fixed before their publication.
+type ScenarioTemplateIterationValidation struct {
+	Version *int
+	Errors  []string
+}
+
+type ScenarioTemplateInstantiation struct {
+	ScenarioImportResult
+	Validations []ScenarioTemplateIterationValidation
+}
+
+func (template ScenarioTemplate) placeholder(name string) (ScenarioTemplatePlaceholder, bool) {
+	for _, placeholder := range template.Placeholders {
+		if placeholder.Name == name {
+			return placeholder, true
+		}
+	}
+	return ScenarioTemplatePlaceholder{}, false
+}
+
+// Validate checks the placeholders, and that the bundle only uses declared placeholders and no custom list of its own
+func (template ScenarioTemplate) Validate() error {
+	if template.Name == "" {
+		return errors.Wrap(BadParameterError, "name is required")
+	}
+	if template.Bundle.Version != SCENARIO_BUNDLE_VERSION {
+		return errors.Wrapf(BadParameterError, "bundle version %d is not supported, expected version %d",
+			template.Bundle.Version, SCENARIO_BUNDLE_VERSION)
+	}
+	if len(template.Bundle.Iterations) == 0 {
+		return errors.Wrap(BadParameterError, "the template has no iteration")
+	}
+	if len(template.Bundle.CustomLists) > 0 {
+		return errors.Wrap(BadParameterError,
+			"a template cannot contain custom lists, use custom list placeholders instead")
+	}
+
+	names := make(map[string]bool, len(template.Placeholders))
+	for _, placeholder := range template.Placeholders {
+		if !scenarioTemplatePlaceholderNameRegexp.MatchString(placeholder.Name) {
+			return errors.Wrapf(BadParameterError,
+				"invalid placeholder name %q: it must contain only letters, digits and underscores, and not start with a digit",
+				placeholder.Name)
+		}
+		if names[placeholder.Name] {
+			return errors.Wrapf(BadParameterError, "duplicate placeholder %s", placeholder.Name)
+		}
+		names[placeholder.Name] = true
+		if !slices.Contains(scenarioTemplatePlaceholderKinds, placeholder.Kind) {
+			return errors.Wrapf(BadParameterError, "invalid kind %q of placeholder %s", placeholder.Kind, placeholder.Name)
+		}
+		if placeholder.DefaultValue == nil {
+			continue
+		}
+		if placeholder.Kind == ScenarioTemplatePlaceholderCustomList {
+			return errors.Wrapf(BadParameterError,
+				"custom list placeholder %s cannot have a default value", placeholder.Name)
+		}
+		if err := placeholder.validateValue(*placeholder.DefaultValue); err != nil {
+			return err
+		}
+	}
+
+	// render the template with the placeholders unchanged, to find the undeclared ones
+	renderer := scenarioTemplateRenderer{values: make(map[string]string), unknown: make(map[string]bool)}
+	renderer.renderBundle(template.Bundle)
+	unknown := make([]string, 0)
+	for name := range renderer.unknown {
+		if !names[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return errors.Wrapf(BadParameterError, "undeclared placeholders: %s", strings.Join(unknown, ", "))
+	}
+
+	references := CollectScenarioAstReferences(template.Bundle.Scenario.TriggerObjectType, template.Bundle.Iterations)
+	for _, id := range references.CustomListIds {
+		match := scenarioTemplatePlaceholderRegexp.FindStringSubmatch(id)
+		if match == nil || match[0] != id {
+			return errors.Wrapf(BadParameterError, "custom list %s must be a custom list placeholder", id)
+		}
+		if placeholder, _ := template.placeholder(match[1]); placeholder.Kind != ScenarioTemplatePlaceholderCustomList {
+			return errors.Wrapf(BadParameterError, "placeholder %s is used as a custom list", match[1])
+		}
+	}
+	return nil
+}
+
+func (placeholder ScenarioTemplatePlaceholder) validateValue(value string) error {
+	switch placeholder.Kind {
+	case ScenarioTemplatePlaceholderNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.Wrapf(BadParameterError, "value %q of placeholder %s is not a number", value, placeholder.Name)
+		}
+	case ScenarioTemplatePlaceholderTable, ScenarioTemplatePlaceholderField, ScenarioTemplatePlaceholderCustomList:
+		if value == "" {
+			return errors.Wrapf(BadParameterError, "value of placeholder %s cannot be empty", placeholder.Name)
+		}
+	}
+	return nil
+}
+
+// Render returns the bundle of the template with the placeholders replaced by their values, or their default values.
+// customLists are the lists of the organization chosen for the custom list placeholders, by placeholder name: they are
+// added to the bundle, so that its import maps them to themselves.
+func (template ScenarioTemplate) Render(values map[string]string, customLists map[string]CustomList) (ScenarioBundle, error) {
+	for name := range values {
+		if _, ok := template.placeholder(name); !ok {
+			return ScenarioBundle{}, errors.Wrapf(BadParameterError, "unknown placeholder %s", name)
+		}
+	}
+
+	renderer := scenarioTemplateRenderer{
+		values:  make(map[string]string, len(template.Placeholders)),
+		numbers: make(map[string]float64),
+		unknown: make(map[string]bool),
+	}
+	bundleCustomLists := make([]ScenarioBundleCustomList, 0)
+	for _, placeholder := range template.Placeholders {
+		if placeholder.Kind == ScenarioTemplatePlaceholderCustomList {
+			customList, ok := customLists[placeholder.Name]
+			if !ok {
+				return ScenarioBundle{}, errors.Wrapf(BadParameterError,
+					"a custom list is required for placeholder %s", placeholder.Name)
+			}
+			renderer.values[placeholder.Name] = customList.Id
+			bundleCustomLists = append(bundleCustomLists, ScenarioBundleCustomList{
+				Id:          customList.Id,
+				Name:        customList.Name,
+				Description: customList.Description,
+			})
+			continue
+		}
+
+		value, ok := values[placeholder.Name]
+		if !ok && placeholder.DefaultValue != nil {
+			value, ok = *placeholder.DefaultValue, true
+		}
+		if !ok {
+			return ScenarioBundle{}, errors.Wrapf(BadParameterError, "a value is required for placeholder %s",
+				placeholder.Name)
+		}
+		if err := placeholder.validateValue(value); err != nil {
+			return ScenarioBundle{}, err
+		}
+		renderer.values[placeholder.Name] = value
+		if placeholder.Kind == ScenarioTemplatePlaceholderNumber {
+			renderer.numbers[placeholder.Name], _ = strconv.ParseFloat(value, 64)
+		}
+	}
+
+	bundle := renderer.renderBundle(template.Bundle)
+	bundle.CustomLists = bundleCustomLists
+	return bundle, nil
+}
+
+type scenarioTemplateRenderer struct {
+	values  map[string]string
+	numbers map[string]float64
+	// placeholders found in the template without a value
+	unknown map[string]bool
+}
+
+func (renderer scenarioTemplateRenderer) renderString(s string) string {
+	return scenarioTemplatePlaceholderRegexp.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-2]
+		value, ok := renderer.values[name]
+		if !ok {
+			renderer.unknown[name] = true
+			return match
+		}
+		return value
+	})
+}
+
+// renderConstant replaces the placeholders of a constant of a formula. A constant made of a single number placeholder
+// becomes a number, so that it can be compared in the formula.
+func (renderer scenarioTemplateRenderer) renderConstant(constant any) any {
+	switch constant := constant.(type) {
+	case string:
+		if match := scenarioTemplatePlaceholderRegexp.FindStringSubmatch(constant); match != nil && match[0] == constant {
+			if number, ok := renderer.numbers[match[1]]; ok {
+				return number
+			}
+		}
+		return renderer.renderString(constant)
+	case []string:
+		rendered := make([]string, len(constant))
+		for i, s := range constant {
+			rendered[i] = renderer.renderString(s)
+		}
+		return rendered
+	case []any:
+		rendered := make([]any, len(constant))
+		for i, element := range constant {
+			rendered[i] = renderer.renderConstant(element)
+		}
+		return rendered
+	}
+	return constant
+}
+
+func (renderer scenarioTemplateRenderer) renderNode(node *ast.Node) *ast.Node {
+	if node == nil {
+		return nil
+	}
+	rendered := renderer.renderNodeValue(*node)
+	return &rendered
+}
+
+func (renderer scenarioTemplateRenderer) renderNodeValue(node ast.Node) ast.Node {
+	rendered := ast.Node{Function: node.Function, Constant: renderer.renderConstant(node.Constant)}
+	for _, child := range node.Children {
+		rendered = rendered.AddChild(renderer.renderNodeValue(child))
+	}
+	for name, child := range node.NamedChildren {
+		rendered = rendered.AddNamedChild(name, renderer.renderNodeValue(child))
+	}
+	return rendered
+}
+
+func (renderer scenarioTemplateRenderer) renderBundle(bundle ScenarioBundle) ScenarioBundle {
+	rendered := ScenarioBundle{
+		Version:    bundle.Version,
+		ExportedAt: bundle.ExportedAt,
+		Scenario: ScenarioBundleScenario{
+			Name:              renderer.renderString(bundle.Scenario.Name),
+			Description:       renderer.renderString(bundle.Scenario.Description),
+			TriggerObjectType: renderer.renderString(bundle.Scenario.TriggerObjectType),
+		},
+		Iterations:  make([]ScenarioBundleIteration, len(bundle.Iterations)),
+		CustomLists: bundle.CustomLists,
+		DataModel:   make([]ScenarioBundleFieldRequirement, len(bundle.DataModel)),
+	}
+
+	for i, iteration := range bundle.Iterations {
+		rules := make([]ScenarioBundleRule, len(iteration.Rules))
+		for j, rule := range iteration.Rules {
+			rules[j] = ScenarioBundleRule{
+				DisplayOrder:         rule.DisplayOrder,
+				Name:                 renderer.renderString(rule.Name),
+				Description:          renderer.renderString(rule.Description),
+				FormulaAstExpression: renderer.renderNode(rule.FormulaAstExpression),
+				ScoreModifier:        rule.ScoreModifier,
+				RuleGroup:            renderer.renderString(rule.RuleGroup),
+			}
+		}
+		outputVariables := make([]OutputVariable, len(iteration.OutputVariables))
+		for j, outputVariable := range iteration.OutputVariables {
+			outputVariables[j] = OutputVariable{
+				Name:                 outputVariable.Name,
+				FormulaAstExpression: renderer.renderNode(outputVariable.FormulaAstExpression),
+			}
+		}
+		rendered.Iterations[i] = ScenarioBundleIteration{
+			Version:                       iteration.Version,
+			TriggerConditionAstExpression: renderer.renderNode(iteration.TriggerConditionAstExpression),
+			Rules:                         rules,
+			ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+			ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+			BatchTriggerSQL:               renderer.renderString(iteration.BatchTriggerSQL),
+			Schedule:                      iteration.Schedule,
+			ScheduleTimezone:              iteration.ScheduleTimezone,
+			OutputVariables:               outputVariables,
+		}
+	}
+
+	for i, requirement := range bundle.DataModel {
+		path := make([]string, len(requirement.Path))
+		for j, linkName := range requirement.Path {
+			path[j] = renderer.renderString(linkName)
+		}
+		rendered.DataModel[i] = ScenarioBundleFieldRequirement{
+			TableName: renderer.renderString(requirement.TableName),
+			Path:      path,
+			FieldName: renderer.renderString(requirement.FieldName),
+			DataType:  requirement.DataType,
+		}
+	}
+	return rendered
+}
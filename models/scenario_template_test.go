@@ -0,0 +1,108 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/checkmarble/marble-backend/models/ast"
+)
+
+func templateTest() ScenarioTemplate {
+	trigger := newTestNode(ast.FUNC_GREATER).
+		AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("{{amount_field}}"))).
+		AddChild(ast.NewNodeConstant("{{threshold}}"))
+	formula := newTestNode(ast.FUNC_IS_IN_LIST).
+		AddChild(ast.NewNodeDatabaseAccess("{{table}}", "name", []string{"{{link}}"})).
+		AddChild(ast.NewNodeCustomListAccess("{{blocklist}}"))
+	return ScenarioTemplate{
+		Name: "large transactions",
+		Placeholders: []ScenarioTemplatePlaceholder{
+			{Name: "table", Kind: ScenarioTemplatePlaceholderTable, DefaultValue: ptr("transactions")},
+			{Name: "amount_field", Kind: ScenarioTemplatePlaceholderField},
+			{Name: "link", Kind: ScenarioTemplatePlaceholderString, DefaultValue: ptr("account")},
+			{Name: "threshold", Kind: ScenarioTemplatePlaceholderNumber},
+			{Name: "blocklist", Kind: ScenarioTemplatePlaceholderCustomList},
+		},
+		Bundle: ScenarioBundle{
+			Version:  SCENARIO_BUNDLE_VERSION,
+			Scenario: ScenarioBundleScenario{Name: "Transactions over {{threshold}}", TriggerObjectType: "{{table}}"},
+			Iterations: []ScenarioBundleIteration{{
+				TriggerConditionAstExpression: &trigger,
+				Rules:                         []ScenarioBundleRule{{Name: "blocked account", FormulaAstExpression: &formula}},
+			}},
+			DataModel: []ScenarioBundleFieldRequirement{
+				{TableName: "{{table}}", FieldName: "{{amount_field}}", DataType: Float},
+			},
+		},
+	}
+}
+
+func TestScenarioTemplate_Validate(t *testing.T) {
+	assert.NoError(t, templateTest().Validate())
+
+	undeclared := templateTest()
+	undeclared.Placeholders = undeclared.Placeholders[1:]
+	assert.ErrorIs(t, undeclared.Validate(), BadParameterError)
+
+	notAList := templateTest()
+	notAList.Placeholders[4].Kind = ScenarioTemplatePlaceholderString
+	assert.ErrorIs(t, notAList.Validate(), BadParameterError)
+
+	ownList := templateTest()
+	ownList.Bundle.CustomLists = []ScenarioBundleCustomList{{Id: "list-1", Name: "list"}}
+	assert.ErrorIs(t, ownList.Validate(), BadParameterError)
+
+	badDefault := templateTest()
+	badDefault.Placeholders[3].DefaultValue = ptr("ten")
+	assert.ErrorIs(t, badDefault.Validate(), BadParameterError)
+}
+
+func TestScenarioTemplate_Render(t *testing.T) {
+	customLists := map[string]CustomList{"blocklist": {Id: "list-id", Name: "Blocked accounts"}}
+	bundle, err := templateTest().Render(map[string]string{"amount_field": "amount", "threshold": "1000"}, customLists)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Transactions over 1000", bundle.Scenario.Name)
+	assert.Equal(t, "transactions", bundle.Scenario.TriggerObjectType)
+	assert.Equal(t, []ScenarioBundleCustomList{{Id: "list-id", Name: "Blocked accounts"}}, bundle.CustomLists)
+	assert.Equal(t, []ScenarioBundleFieldRequirement{
+		{TableName: "transactions", Path: []string{}, FieldName: "amount", DataType: Float},
+	}, bundle.DataModel)
+
+	trigger := bundle.Iterations[0].TriggerConditionAstExpression
+	assert.Equal(t, "amount", trigger.Children[0].Children[0].Constant)
+	assert.Equal(t, 1000.0, trigger.Children[1].Constant)
+
+	references := CollectScenarioAstReferences(bundle.Scenario.TriggerObjectType, bundle.Iterations)
+	assert.Equal(t, []string{"list-id"}, references.CustomListIds)
+	assert.Equal(t, []ScenarioBundleFieldRequirement{
+		{TableName: "transactions", Path: []string{"account"}, FieldName: "name", DataType: UnknownDataType},
+		{TableName: "transactions", FieldName: "amount", DataType: UnknownDataType},
+	}, references.Fields)
+
+	report := ValidateScenarioBundle(bundle, bundleTestDataModel(), nil,
+		[]CustomList{{Id: "list-id", Name: "Blocked accounts"}})
+	assert.Empty(t, report.Conflicts)
+	assert.Equal(t, []ScenarioImportCustomList{
+		{Name: "Blocked accounts", SourceId: "list-id", TargetId: ptr("list-id")},
+	}, report.CustomLists)
+}
+
+func TestScenarioTemplate_Render_errors(t *testing.T) {
+	customLists := map[string]CustomList{"blocklist": {Id: "list-id", Name: "Blocked accounts"}}
+
+	_, err := templateTest().Render(map[string]string{"threshold": "1000"}, customLists)
+	assert.ErrorIs(t, err, BadParameterError, "missing value without default")
+
+	_, err = templateTest().Render(map[string]string{"amount_field": "amount", "threshold": "a lot"}, customLists)
+	assert.ErrorIs(t, err, BadParameterError, "not a number")
+
+	_, err = templateTest().Render(map[string]string{"amount_field": "amount", "threshold": "1000", "other": "x"},
+		customLists)
+	assert.ErrorIs(t, err, BadParameterError, "unknown placeholder")
+
+	_, err = templateTest().Render(map[string]string{"amount_field": "amount", "threshold": "1000"}, nil)
+	assert.ErrorIs(t, err, BadParameterError, "missing custom list")
+}
@@ -16,6 +16,10 @@ const (
 	ScoreReviewThresholdRequired
 	ScoreRejectThresholdRequired
 	ScoreRejectReviewThresholdsMissmatch
+	// Schedule
+	ScheduleTimezoneInvalid
+	// Output variables
+	OutputVariableInvalid
 )
 
 // Provide a string value for each outcome
@@ -35,6 +39,10 @@ func (e ScenarioValidationErrorCode) String() string {
 		return "SCORE_REJECT_THRESHOLD_REQUIRED"
 	case ScoreRejectReviewThresholdsMissmatch:
 		return "SCORE_REJECT_REVIEW_THRESHOLDS_MISSMATCH"
+	case ScheduleTimezoneInvalid:
+		return "SCHEDULE_TIMEZONE_INVALID"
+	case OutputVariableInvalid:
+		return "OUTPUT_VARIABLE_INVALID"
 	}
 	return "unknown ScenarioValidationErrorCode"
 }
@@ -10,9 +10,13 @@ type ScheduledExecution struct {
 	Status                   ScheduledExecutionStatus
 	StartedAt                time.Time
 	FinishedAt               *time.Time
+	UpdatedAt                time.Time
 	NumberOfCreatedDecisions int
-	Scenario                 Scenario
-	Manual                   bool
+	NumberOfEvaluatedObjects int
+	// object_id of the last trigger object processed in a committed batch, used to resume the execution
+	LastProcessedObjectId *string
+	Scenario              Scenario
+	Manual                bool
 }
 
 type ScheduledExecutionStatus int
@@ -22,6 +26,7 @@ const (
 	ScheduledExecutionProcessing
 	ScheduledExecutionSuccess
 	ScheduledExecutionFailure
+	ScheduledExecutionCancelled
 )
 
 func (s ScheduledExecutionStatus) String() string {
@@ -34,6 +39,8 @@ func (s ScheduledExecutionStatus) String() string {
 		return "success"
 	case ScheduledExecutionFailure:
 		return "failure"
+	case ScheduledExecutionCancelled:
+		return "cancelled"
 	}
 	return "pending"
 }
@@ -42,18 +49,29 @@ func ScheduledExecutionStatusFrom(s string) ScheduledExecutionStatus {
 	switch s {
 	case "pending":
 		return ScheduledExecutionPending
+	case "processing":
+		return ScheduledExecutionProcessing
 	case "success":
 		return ScheduledExecutionSuccess
 	case "failure":
 		return ScheduledExecutionFailure
+	case "cancelled":
+		return ScheduledExecutionCancelled
 	}
 	return ScheduledExecutionPending
 }
 
+// IsFinished returns true if the execution reached a final status and will not be processed anymore
+func (s ScheduledExecutionStatus) IsFinished() bool {
+	return s == ScheduledExecutionSuccess || s == ScheduledExecutionFailure || s == ScheduledExecutionCancelled
+}
+
 type UpdateScheduledExecutionInput struct {
 	Id                       string
 	Status                   *ScheduledExecutionStatus
 	NumberOfCreatedDecisions *int
+	NumberOfEvaluatedObjects *int
+	LastProcessedObjectId    *string
 }
 
 type CreateScheduledExecutionInput struct {
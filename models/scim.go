@@ -0,0 +1,350 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Maximum number of resources returned in a SCIM list response
+const SCIM_MAX_RESULTS = 200
+
+// Role of the users created by SCIM without a role, until their groups give them one
+const SCIM_DEFAULT_ROLE = VIEWER
+
+// ScimToken authenticates the identity provider of an organization on the SCIM endpoints
+type ScimToken struct {
+	Id             string
+	OrganizationId string
+	Description    string
+	Prefix         string
+	Hash           []byte
+	CreatedAt      time.Time
+}
+
+type CreateScimTokenInput struct {
+	OrganizationId string
+	Description    string
+}
+
+type CreatedScimToken struct {
+	ScimToken
+	Token string
+}
+
+// ScimUser is a user of the organization as seen by its identity provider. Deactivated users are the soft deleted
+// users, and can be activated again.
+type ScimUser struct {
+	User
+	Groups []ScimGroupRef
+}
+
+func (user ScimUser) Active() bool {
+	return user.DeletedAt == nil
+}
+
+type ScimGroupRef struct {
+	Id          string
+	DisplayName string
+}
+
+type ScimUserInput struct {
+	Email     string
+	FirstName string
+	LastName  string
+	Active    bool
+	// NO_ROLE lets the SCIM groups of the user decide of their role
+	Role Role
+}
+
+func (input ScimUserInput) Validate() error {
+	if input.Email == "" {
+		return errors.Wrap(BadParameterError, "userName is required")
+	}
+	if input.Role != NO_ROLE && !slices.Contains(SSO_ROLES, input.Role) {
+		return errors.Wrapf(BadParameterError, "role %s cannot be given with SCIM", input.Role)
+	}
+	return nil
+}
+
+func NewScimUserInput(user User) ScimUserInput {
+	return ScimUserInput{
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Active:    user.DeletedAt == nil,
+	}
+}
+
+// ScimGroup is a group pushed by the identity provider. An admin of the organization maps it to the role and the
+// inbox its members are given.
+type ScimGroup struct {
+	Id             string
+	OrganizationId string
+	DisplayName    string
+	ExternalId     string
+	Role           Role
+	InboxId        *string
+	Members        []ScimGroupMember
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ScimGroupMember struct {
+	GroupId string
+	UserId  UserId
+	Email   string
+}
+
+type ScimGroupInput struct {
+	DisplayName string
+	ExternalId  string
+	MemberIds   []string
+}
+
+func (input ScimGroupInput) Validate() error {
+	if input.DisplayName == "" {
+		return errors.Wrap(BadParameterError, "displayName is required")
+	}
+	return nil
+}
+
+func NewScimGroupInput(group ScimGroup) ScimGroupInput {
+	memberIds := make([]string, 0, len(group.Members))
+	for _, member := range group.Members {
+		memberIds = append(memberIds, string(member.UserId))
+	}
+	return ScimGroupInput{
+		DisplayName: group.DisplayName,
+		ExternalId:  group.ExternalId,
+		MemberIds:   memberIds,
+	}
+}
+
+type UpdateScimGroupMappingInput struct {
+	GroupId string
+	Role    Role
+	InboxId *string
+}
+
+func (input UpdateScimGroupMappingInput) Validate() error {
+	if input.Role != NO_ROLE && !slices.Contains(SSO_ROLES, input.Role) {
+		return errors.Wrapf(BadParameterError, "role %s cannot be given with SCIM", input.Role)
+	}
+	return nil
+}
+
+// ScimGroupsRole returns the most privileged role given by the groups, or NO_ROLE if no group gives a role
+func ScimGroupsRole(groups []ScimGroup) Role {
+	role := NO_ROLE
+	for _, group := range groups {
+		if slices.Index(SSO_ROLES, group.Role) > slices.Index(SSO_ROLES, role) {
+			role = group.Role
+		}
+	}
+	return role
+}
+
+var scimEqFilterRegexp = regexp.MustCompile(`^\s*(\w+)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// ParseScimEqFilter parses the only kind of SCIM filter supported, the equality of an attribute with a string
+// (e.g. `userName eq "jane@example.com"`), which identity providers use to find a resource before creating it
+func ParseScimEqFilter(filter string) (attribute, value string, err error) {
+	matches := scimEqFilterRegexp.FindStringSubmatch(filter)
+	if matches == nil {
+		return "", "", errors.Wrapf(BadParameterError, "unsupported filter %q", filter)
+	}
+	return matches[1], strings.ReplaceAll(matches[2], `\"`, `"`), nil
+}
+
+type ScimPatchOperation struct {
+	Op    string
+	Path  string
+	Value any
+}
+
+func scimPatchString(operation ScimPatchOperation, value any) (string, error) {
+	str, ok := value.(string)
+	if !ok {
+		return "", errors.Wrapf(BadParameterError, "invalid value for %q", operation.Path)
+	}
+	return str, nil
+}
+
+func scimPatchBool(operation ScimPatchOperation, value any) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	// sent as a string by some identity providers
+	case string:
+		switch strings.ToLower(value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, errors.Wrapf(BadParameterError, "invalid value for %q", operation.Path)
+}
+
+// ApplyPatch applies the add and replace operations of a SCIM PATCH request on the attributes of a user. The
+// attributes Marble requires cannot be removed, so remove operations are ignored.
+func (input ScimUserInput) ApplyPatch(operations []ScimPatchOperation) (ScimUserInput, error) {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op == "remove" {
+			continue
+		}
+		if op != "add" && op != "replace" {
+			return ScimUserInput{}, errors.Wrapf(BadParameterError, "unsupported operation %q on a user", operation.Op)
+		}
+
+		values := map[string]any{}
+		if operation.Path == "" {
+			object, ok := operation.Value.(map[string]any)
+			if !ok {
+				return ScimUserInput{}, errors.Wrap(BadParameterError, "an operation without path needs an object value")
+			}
+			flattenScimObject("", object, values)
+		} else {
+			values[operation.Path] = operation.Value
+		}
+
+		for path, value := range values {
+			var err error
+			switch strings.ToLower(path) {
+			case "username":
+				input.Email, err = scimPatchString(operation, value)
+			case "name.givenname":
+				input.FirstName, err = scimPatchString(operation, value)
+			case "name.familyname":
+				input.LastName, err = scimPatchString(operation, value)
+			case "active":
+				input.Active, err = scimPatchBool(operation, value)
+			}
+			// other attributes are not stored by Marble and are ignored
+			if err != nil {
+				return ScimUserInput{}, err
+			}
+		}
+	}
+	return input, nil
+}
+
+func flattenScimObject(prefix string, object map[string]any, values map[string]any) {
+	for key, value := range object {
+		if nested, ok := value.(map[string]any); ok {
+			flattenScimObject(prefix+key+".", nested, values)
+			continue
+		}
+		values[prefix+key] = value
+	}
+}
+
+var scimMemberPathRegexp = regexp.MustCompile(`^members\[\s*value\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+
+func scimMemberIds(value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		if value == nil {
+			return nil, nil
+		}
+		items = []any{value}
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		member, ok := item.(map[string]any)
+		if !ok {
+			return nil, errors.Wrap(BadParameterError, "invalid group member")
+		}
+		id, ok := member["value"].(string)
+		if !ok || id == "" {
+			return nil, errors.Wrap(BadParameterError, "invalid group member")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ApplyPatch applies the operations of a SCIM PATCH request on the name and the members of a group
+func (input ScimGroupInput) ApplyPatch(operations []ScimPatchOperation) (ScimGroupInput, error) {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		path := operation.Path
+
+		if path == "" {
+			object, ok := operation.Value.(map[string]any)
+			if !ok || op == "remove" {
+				return ScimGroupInput{}, errors.Wrap(BadParameterError, "an operation without path needs an object value")
+			}
+			for key, value := range object {
+				var err error
+				input, err = input.ApplyPatch([]ScimPatchOperation{{Op: op, Path: key, Value: value}})
+				if err != nil {
+					return ScimGroupInput{}, err
+				}
+			}
+			continue
+		}
+
+		if matches := scimMemberPathRegexp.FindStringSubmatch(path); matches != nil {
+			if op != "remove" {
+				return ScimGroupInput{}, errors.Wrapf(BadParameterError, "unsupported operation %q on %q", operation.Op, path)
+			}
+			input.MemberIds = slices.DeleteFunc(input.MemberIds, func(id string) bool { return id == matches[1] })
+			continue
+		}
+
+		var err error
+		switch strings.ToLower(path) {
+		case "displayname":
+			if op == "remove" {
+				return ScimGroupInput{}, errors.Wrap(BadParameterError, "displayName cannot be removed")
+			}
+			input.DisplayName, err = scimPatchString(operation, operation.Value)
+		case "externalid":
+			if op == "remove" {
+				input.ExternalId = ""
+			} else {
+				input.ExternalId, err = scimPatchString(operation, operation.Value)
+			}
+		case "members":
+			input.MemberIds, err = patchScimMembers(op, input.MemberIds, operation.Value)
+		default:
+			err = errors.Wrapf(BadParameterError, "unsupported path %q on a group", path)
+		}
+		if err != nil {
+			return ScimGroupInput{}, err
+		}
+	}
+	return input, nil
+}
+
+func patchScimMembers(op string, memberIds []string, value any) ([]string, error) {
+	ids, err := scimMemberIds(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add":
+		for _, id := range ids {
+			if !slices.Contains(memberIds, id) {
+				memberIds = append(memberIds, id)
+			}
+		}
+		return memberIds, nil
+	case "replace":
+		return ids, nil
+	case "remove":
+		// removing without value removes all the members
+		if value == nil {
+			return nil, nil
+		}
+		return slices.DeleteFunc(memberIds, func(id string) bool { return slices.Contains(ids, id) }), nil
+	}
+	return nil, errors.Wrapf(BadParameterError, "unsupported operation %q", op)
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScimEqFilter(t *testing.T) {
+	attribute, value, err := ParseScimEqFilter(`userName eq "jane@example.com"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "userName", attribute)
+	assert.Equal(t, "jane@example.com", value)
+
+	attribute, value, err = ParseScimEqFilter(`displayName EQ "Fraud \"analysts\""`)
+	assert.NoError(t, err)
+	assert.Equal(t, "displayName", attribute)
+	assert.Equal(t, `Fraud "analysts"`, value)
+
+	_, _, err = ParseScimEqFilter(`userName sw "jane"`)
+	assert.ErrorIs(t, err, BadParameterError)
+
+	_, _, err = ParseScimEqFilter(`userName eq "jane" and active eq true`)
+	assert.ErrorIs(t, err, BadParameterError)
+}
+
+func TestScimUserInput_ApplyPatch(t *testing.T) {
+	input := ScimUserInput{Email: "jane@example.com", FirstName: "Jane", Active: true}
+
+	patched, err := input.ApplyPatch([]ScimPatchOperation{
+		{Op: "Replace", Path: "active", Value: "False"},
+		{Op: "replace", Path: "name.familyName", Value: "Doe"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, patched.Active)
+	assert.Equal(t, "Doe", patched.LastName)
+	assert.Equal(t, "Jane", patched.FirstName)
+
+	patched, err = input.ApplyPatch([]ScimPatchOperation{{
+		Op: "replace",
+		Value: map[string]any{
+			"userName": "jane.doe@example.com",
+			"name":     map[string]any{"givenName": "Janet"},
+			"title":    "Analyst",
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe@example.com", patched.Email)
+	assert.Equal(t, "Janet", patched.FirstName)
+
+	_, err = input.ApplyPatch([]ScimPatchOperation{{Op: "replace", Path: "active", Value: 3.0}})
+	assert.ErrorIs(t, err, BadParameterError)
+
+	_, err = input.ApplyPatch([]ScimPatchOperation{{Op: "move", Path: "active", Value: true}})
+	assert.ErrorIs(t, err, BadParameterError)
+}
+
+func TestScimGroupInput_ApplyPatch(t *testing.T) {
+	input := ScimGroupInput{DisplayName: "Analysts", MemberIds: []string{"a", "b"}}
+
+	patched, err := input.ApplyPatch([]ScimPatchOperation{
+		{Op: "add", Path: "members", Value: []any{map[string]any{"value": "c"}, map[string]any{"value": "a"}}},
+		{Op: "remove", Path: `members[value eq "b"]`},
+		{Op: "replace", Path: "displayName", Value: "Fraud analysts"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, patched.MemberIds)
+	assert.Equal(t, "Fraud analysts", patched.DisplayName)
+
+	patched, err = input.ApplyPatch([]ScimPatchOperation{
+		{Op: "remove", Path: "members", Value: []any{map[string]any{"value": "a"}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, patched.MemberIds)
+
+	patched, err = input.ApplyPatch([]ScimPatchOperation{{Op: "remove", Path: "members"}})
+	assert.NoError(t, err)
+	assert.Empty(t, patched.MemberIds)
+
+	patched, err = input.ApplyPatch([]ScimPatchOperation{
+		{Op: "replace", Value: map[string]any{"members": []any{map[string]any{"value": "d"}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, patched.MemberIds)
+
+	_, err = input.ApplyPatch([]ScimPatchOperation{{Op: "remove", Path: "displayName"}})
+	assert.ErrorIs(t, err, BadParameterError)
+}
+
+func TestScimGroupsRole(t *testing.T) {
+	assert.Equal(t, NO_ROLE, ScimGroupsRole(nil))
+	assert.Equal(t, NO_ROLE, ScimGroupsRole([]ScimGroup{{Role: NO_ROLE}}))
+	assert.Equal(t, PUBLISHER, ScimGroupsRole([]ScimGroup{{Role: VIEWER}, {Role: PUBLISHER}, {Role: NO_ROLE}}))
+}
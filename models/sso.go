@@ -0,0 +1,170 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Scopes requested to the identity provider when none are configured
+var SSO_DEFAULT_SCOPES = []string{"openid", "email", "profile"}
+
+// Time allowed to the user to log in on the identity provider page
+const SSO_LOGIN_STATE_LIFETIME = 10 * time.Minute
+
+// Roles that can be given to the users logged in with SSO, from the least to the most privileged
+var SSO_ROLES = []Role{TRANSFER_CHECK_USER, VIEWER, BUILDER, PUBLISHER, ADMIN}
+
+// SsoProvider is the OpenID Connect identity provider of an organization, used by its users to log in instead of Firebase
+type SsoProvider struct {
+	Id             string
+	OrganizationId string
+	IssuerUrl      string
+	ClientId       string
+	ClientSecret   string
+	Scopes         []string
+	// Claim of the ID token holding the groups or roles of the user, as a dotted path for nested claims
+	// (e.g. "realm_access.roles"). Its values are mapped to Marble roles by RoleMappings.
+	RoleClaim    string
+	RoleMappings map[string]Role
+	// Role of the users whose claims match no role mapping. With NO_ROLE, those users cannot log in.
+	DefaultRole Role
+	// Create the users who log in for the first time. Without it, only the existing users of the organization can log in.
+	JitProvisioning bool
+	Enabled         bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type UpsertSsoProviderInput struct {
+	OrganizationId string
+	IssuerUrl      string
+	ClientId       string
+	// nil keeps the current client secret
+	ClientSecret    *string
+	Scopes          []string
+	RoleClaim       string
+	RoleMappings    map[string]Role
+	DefaultRole     Role
+	JitProvisioning bool
+	Enabled         bool
+}
+
+func (input UpsertSsoProviderInput) Validate() error {
+	issuerUrl, err := url.Parse(input.IssuerUrl)
+	if err != nil || (issuerUrl.Scheme != "https" && issuerUrl.Scheme != "http") || issuerUrl.Host == "" {
+		return errors.Wrapf(BadParameterError, "invalid issuer url %q", input.IssuerUrl)
+	}
+	if input.ClientId == "" {
+		return errors.Wrap(BadParameterError, "client_id is required")
+	}
+	if len(input.RoleMappings) > 0 && input.RoleClaim == "" {
+		return errors.Wrap(BadParameterError, "role_claim is required to map claims to roles")
+	}
+	for claimValue, role := range input.RoleMappings {
+		if !slices.Contains(SSO_ROLES, role) {
+			return errors.Wrapf(BadParameterError, "role %s of claim value %q cannot be given with SSO", role, claimValue)
+		}
+	}
+	if input.DefaultRole != NO_ROLE && !slices.Contains(SSO_ROLES, input.DefaultRole) {
+		return errors.Wrapf(BadParameterError, "role %s cannot be given with SSO", input.DefaultRole)
+	}
+	return nil
+}
+
+func (provider SsoProvider) ScopesOrDefault() []string {
+	if len(provider.Scopes) == 0 {
+		return SSO_DEFAULT_SCOPES
+	}
+	return provider.Scopes
+}
+
+// SsoLoginState is kept between the redirection of the user to the identity provider and the exchange of the code
+// returned by the identity provider, and holds the secrets of the PKCE code flow.
+type SsoLoginState struct {
+	State          string
+	OrganizationId string
+	CodeVerifier   string
+	Nonce          string
+	RedirectUri    string
+	ExpiresAt      time.Time
+}
+
+// SsoIdentity is the user identity read from a verified ID token
+type SsoIdentity struct {
+	Subject   string
+	Email     string
+	FirstName string
+	LastName  string
+	Claims    map[string]any
+}
+
+func NewSsoIdentity(claims map[string]any) (SsoIdentity, error) {
+	stringClaim := func(name string) string {
+		value, _ := claims[name].(string)
+		return value
+	}
+
+	email := stringClaim("email")
+	if email == "" {
+		return SsoIdentity{}, errors.Wrap(UnAuthorizedError, "the ID token has no email claim")
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return SsoIdentity{}, errors.Wrapf(UnAuthorizedError, "the email %s is not verified by the identity provider", email)
+	}
+	return SsoIdentity{
+		Subject:   stringClaim("sub"),
+		Email:     email,
+		FirstName: stringClaim("given_name"),
+		LastName:  stringClaim("family_name"),
+		Claims:    claims,
+	}, nil
+}
+
+// claimValues returns the values of the claim at the dotted path, which can be a string or a list of strings
+func (identity SsoIdentity) claimValues(path string) []string {
+	var value any = identity.Claims
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return value
+	}
+	return nil
+}
+
+// RoleOfIdentity returns the most privileged role mapped from the claims of the identity, or the default role of
+// the provider if no claim value is mapped
+func (provider SsoProvider) RoleOfIdentity(identity SsoIdentity) Role {
+	role := NO_ROLE
+	if provider.RoleClaim != "" {
+		for _, value := range identity.claimValues(provider.RoleClaim) {
+			mapped, ok := provider.RoleMappings[value]
+			if ok && slices.Index(SSO_ROLES, mapped) > slices.Index(SSO_ROLES, role) {
+				role = mapped
+			}
+		}
+	}
+	if role == NO_ROLE {
+		return provider.DefaultRole
+	}
+	return role
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSsoProvider_RoleOfIdentity(t *testing.T) {
+	provider := SsoProvider{
+		RoleClaim: "realm_access.roles",
+		RoleMappings: map[string]Role{
+			"fraud-analysts": VIEWER,
+			"fraud-admins":   ADMIN,
+		},
+		DefaultRole: NO_ROLE,
+	}
+	identityWithRoles := func(roles any) SsoIdentity {
+		return SsoIdentity{Claims: map[string]any{"realm_access": map[string]any{"roles": roles}}}
+	}
+
+	assert.Equal(t, ADMIN, provider.RoleOfIdentity(identityWithRoles([]any{"fraud-analysts", "fraud-admins"})))
+	assert.Equal(t, VIEWER, provider.RoleOfIdentity(identityWithRoles("fraud-analysts")))
+	assert.Equal(t, NO_ROLE, provider.RoleOfIdentity(identityWithRoles([]any{"marketing"})))
+	assert.Equal(t, NO_ROLE, provider.RoleOfIdentity(SsoIdentity{Claims: map[string]any{"realm_access": "roles"}}))
+
+	provider.DefaultRole = TRANSFER_CHECK_USER
+	assert.Equal(t, TRANSFER_CHECK_USER, provider.RoleOfIdentity(identityWithRoles([]any{"marketing"})))
+}
+
+func TestUpsertSsoProviderInput_Validate(t *testing.T) {
+	input := UpsertSsoProviderInput{
+		IssuerUrl:    "https://idp.example.com/realms/marble",
+		ClientId:     "marble",
+		RoleClaim:    "groups",
+		RoleMappings: map[string]Role{"admins": ADMIN},
+		DefaultRole:  VIEWER,
+	}
+	assert.NoError(t, input.Validate())
+
+	invalid := input
+	invalid.IssuerUrl = "idp.example.com"
+	assert.ErrorIs(t, invalid.Validate(), BadParameterError)
+
+	invalid = input
+	invalid.RoleClaim = ""
+	assert.ErrorIs(t, invalid.Validate(), BadParameterError)
+
+	invalid = input
+	invalid.RoleMappings = map[string]Role{"admins": MARBLE_ADMIN}
+	assert.ErrorIs(t, invalid.Validate(), BadParameterError)
+
+	invalid = input
+	invalid.DefaultRole = API_CLIENT
+	assert.ErrorIs(t, invalid.Validate(), BadParameterError)
+}
+
+func TestNewSsoIdentity(t *testing.T) {
+	identity, err := NewSsoIdentity(map[string]any{
+		"sub":            "123",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", identity.Email)
+	assert.Equal(t, "Jane", identity.FirstName)
+	assert.Equal(t, "Doe", identity.LastName)
+
+	_, err = NewSsoIdentity(map[string]any{"sub": "123"})
+	assert.ErrorIs(t, err, UnAuthorizedError)
+
+	_, err = NewSsoIdentity(map[string]any{"email": "jane@example.com", "email_verified": false})
+	assert.ErrorIs(t, err, UnAuthorizedError)
+}
@@ -27,6 +27,16 @@ var (
 	isAlphanumeric     = regexp.MustCompile(`^[a-zA-Z0-9]*$`)
 )
 
+// TransferStatusReviewDisposition returns the review disposition recorded on the decisions of a transfer when its
+// status is updated by the partner, if the status is a final verdict on the transfer. The neutral status is the
+// default status of the transfers and a suspected fraud is not confirmed yet, so they do not label the decisions.
+func TransferStatusReviewDisposition(status string) (DecisionReviewDisposition, bool) {
+	if status == TransferStatusConfirmedFraud {
+		return DecisionReviewConfirmedFraud, true
+	}
+	return "", false
+}
+
 const (
 	maxStringLengthTfCheck = 140
 	idMaxLength            = 50
@@ -27,6 +27,8 @@ func UploadStatusFrom(s string) UploadStatus {
 	switch s {
 	case "pending":
 		return UploadPending
+	case "processing":
+		return UploadProcessing
 	case "success":
 		return UploadSuccess
 	case "failure":
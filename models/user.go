@@ -12,6 +12,7 @@ type User struct {
 	PartnerId      *string
 	FirstName      string
 	LastName       string
+	CustomRoleId   *string
 	DeletedAt      *time.Time
 }
 
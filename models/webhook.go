@@ -18,8 +18,30 @@ const (
 	Success WebhookEventDeliveryStatus = "success"
 	// The event delivery previously failed and the automatic retries have kicked in
 	Retry WebhookEventDeliveryStatus = "retry"
+	// The event delivery failed too many times and is not retried anymore (built-in delivery backend only)
+	DeadLetter WebhookEventDeliveryStatus = "dead_letter"
 )
 
+// WebhookDeliveryBackend is the system used to register webhook endpoints and deliver webhook events to them
+type WebhookDeliveryBackend string
+
+const (
+	// Webhooks are registered and delivered by an external Convoy instance
+	WebhookDeliveryBackendConvoy WebhookDeliveryBackend = "convoy"
+	// Webhooks are stored in the Marble database and delivered by Marble's own signed HTTP dispatcher
+	WebhookDeliveryBackendBuiltin WebhookDeliveryBackend = "builtin"
+)
+
+func WebhookDeliveryBackendFrom(s string) (WebhookDeliveryBackend, error) {
+	switch s {
+	case "", string(WebhookDeliveryBackendConvoy):
+		return WebhookDeliveryBackendConvoy, nil
+	case string(WebhookDeliveryBackendBuiltin):
+		return WebhookDeliveryBackendBuiltin, nil
+	}
+	return "", errors.Errorf("invalid webhook delivery backend: %s", s)
+}
+
 type WebhookEventType string
 
 const (
@@ -32,8 +54,12 @@ const (
 	WebhookEventType_CaseFileCreated       WebhookEventType = "case.file_created"
 	WebhookEventType_CaseRuleSnoozeCreated WebhookEventType = "case.rule_snooze_created"
 	WebhookEventType_DecisionCreated       WebhookEventType = "decision.created"
+	WebhookEventType_AsyncDecisionFailed   WebhookEventType = "decision.async_failed"
+	WebhookEventType_DecisionReviewed      WebhookEventType = "decision.reviewed"
 )
 
+var validWebhookEventDeliveryStatuses = []WebhookEventDeliveryStatus{Scheduled, Success, Retry, DeadLetter}
+
 var validWebhookEventTypes = []WebhookEventType{
 	WebhookEventType_CaseUpdated,
 	WebhookEventType_CaseCreatedManually,
@@ -43,6 +69,8 @@ var validWebhookEventTypes = []WebhookEventType{
 	WebhookEventType_CaseCommentCreated,
 	WebhookEventType_CaseFileCreated,
 	WebhookEventType_DecisionCreated,
+	WebhookEventType_AsyncDecisionFailed,
+	WebhookEventType_DecisionReviewed,
 }
 
 type WebhookEventContent struct {
@@ -59,6 +87,15 @@ type WebhookEvent struct {
 	OrganizationId string
 	PartnerId      null.String
 	EventContent   WebhookEventContent
+	// number of times the event has been manually replayed
+	ReplayCount int
+}
+
+// WebhookEventWithDeliveries is a webhook event with the log of its deliveries to the webhook endpoints. The
+// deliveries are only tracked by the built-in delivery backend: they are empty when webhooks are sent through Convoy.
+type WebhookEventWithDeliveries struct {
+	WebhookEvent
+	Deliveries []WebhookDeliveryWithAttempts
 }
 
 type WebhookEventCreate struct {
@@ -74,19 +111,66 @@ type WebhookEventUpdate struct {
 }
 
 type WebhookEventFilters struct {
+	OrganizationId string
+	PartnerId      null.String
 	DeliveryStatus []WebhookEventDeliveryStatus
+	EventTypes     []WebhookEventType
+	StartDate      time.Time
+	EndDate        time.Time
 	Limit          uint64
 }
 
+const WEBHOOK_EVENTS_MAX_LIMIT = 1000
+
 func (f WebhookEventFilters) MergeWithDefaults() WebhookEventFilters {
-	defaultFilters := WebhookEventFilters{
-		Limit: 100,
+	mergedFilters := f
+	if mergedFilters.Limit == 0 {
+		mergedFilters.Limit = 100
+	}
+	return mergedFilters
+}
+
+func (f WebhookEventFilters) Validate() error {
+	for _, eventType := range f.EventTypes {
+		if !slices.Contains(validWebhookEventTypes, eventType) {
+			return errors.Wrapf(BadParameterError, "invalid event type: %s", eventType)
+		}
+	}
+	for _, status := range f.DeliveryStatus {
+		if !slices.Contains(validWebhookEventDeliveryStatuses, status) {
+			return errors.Wrapf(BadParameterError, "invalid delivery status: %s", status)
+		}
+	}
+	if !f.StartDate.IsZero() && !f.EndDate.IsZero() && f.StartDate.After(f.EndDate) {
+		return errors.Wrap(BadParameterError, "start date must be before end date")
+	}
+	if f.Limit > WEBHOOK_EVENTS_MAX_LIMIT {
+		return errors.Wrapf(BadParameterError, "limit must be at most %d", WEBHOOK_EVENTS_MAX_LIMIT)
+	}
+	return nil
+}
+
+// WebhookEventsReplay selects the failed webhook events to replay for a webhook endpoint
+type WebhookEventsReplay struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// WebhookEventsReplayResult is the result of a replay of failed webhook events. At most WEBHOOK_EVENTS_MAX_LIMIT events
+// are replayed at once: if HasMore is true, other failed events of the time range remain to be replayed by another call.
+type WebhookEventsReplayResult struct {
+	ReplayedEvents int
+	HasMore        bool
+}
+
+func (input WebhookEventsReplay) Validate() error {
+	if input.StartDate.IsZero() || input.EndDate.IsZero() {
+		return errors.Wrap(BadParameterError, "start date and end date are required")
 	}
-	defaultFilters.DeliveryStatus = f.DeliveryStatus
-	if f.Limit > 0 {
-		defaultFilters.Limit = f.Limit
+	if input.StartDate.After(input.EndDate) {
+		return errors.Wrap(BadParameterError, "start date must be before end date")
 	}
-	return defaultFilters
+	return nil
 }
 
 type WebhookRegister struct {
@@ -120,12 +204,51 @@ func (input WebhookRegister) Validate() error {
 	return nil
 }
 
-func NewWebhookEventDecisionCreated(id string) WebhookEventContent {
+func NewWebhookEventDecisionCreated(id string, outputValues map[string]any) WebhookEventContent {
+	if outputValues == nil {
+		outputValues = map[string]any{}
+	}
 	return WebhookEventContent{
 		Type: WebhookEventType_DecisionCreated,
 		Data: map[string]any{
-			"type":      WebhookEventType_DecisionCreated,
-			"content":   map[string]any{"decision": map[string]any{"id": id}},
+			"type": WebhookEventType_DecisionCreated,
+			"content": map[string]any{"decision": map[string]any{
+				"id":            id,
+				"output_values": outputValues,
+			}},
+			"timestamp": time.Now(),
+		},
+	}
+}
+
+func NewWebhookEventAsyncDecisionFailed(id string, asyncDecisionError string) WebhookEventContent {
+	return WebhookEventContent{
+		Type: WebhookEventType_AsyncDecisionFailed,
+		Data: map[string]any{
+			"type": WebhookEventType_AsyncDecisionFailed,
+			"content": map[string]any{"decision": map[string]any{
+				"id":    id,
+				"error": asyncDecisionError,
+			}},
+			"timestamp": time.Now(),
+		},
+	}
+}
+
+func NewWebhookEventDecisionReviewed(id string, review DecisionReview) WebhookEventContent {
+	return WebhookEventContent{
+		Type: WebhookEventType_DecisionReviewed,
+		Data: map[string]any{
+			"type": WebhookEventType_DecisionReviewed,
+			"content": map[string]any{"decision": map[string]any{
+				"id": id,
+				"review": map[string]any{
+					"disposition": review.Disposition,
+					"reviewed_by": review.ReviewedBy,
+					"reviewed_at": review.ReviewedAt,
+					"note":        review.Note,
+				},
+			}},
 			"timestamp": time.Now(),
 		},
 	}
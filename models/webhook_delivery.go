@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// A WebhookDelivery tracks the delivery of a webhook event to one of the webhook endpoints it matches, when webhooks
+// are delivered by the built-in backend. Its status is one of Scheduled, Success, Retry or DeadLetter.
+type WebhookDelivery struct {
+	Id             string
+	WebhookEventId string
+	WebhookId      string
+	Status         WebhookEventDeliveryStatus
+	Attempts       int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (d WebhookDelivery) IsFinished() bool {
+	return d.Status == Success || d.Status == DeadLetter
+}
+
+type WebhookDeliveryWithAttempts struct {
+	WebhookDelivery
+	DeliveryAttempts []WebhookDeliveryAttempt
+}
+
+// A WebhookDeliveryAttempt is one HTTP call made to deliver a webhook event to an endpoint
+type WebhookDeliveryAttempt struct {
+	Id              string
+	DeliveryId      string
+	WebhookId       string
+	CreatedAt       time.Time
+	StatusCode      *int
+	Latency         time.Duration
+	ResponseExcerpt string
+	Error           string
+}
+
+type WebhookDeliveryAttemptCreate struct {
+	DeliveryId      string
+	WebhookId       string
+	StatusCode      *int
+	Latency         time.Duration
+	ResponseExcerpt string
+	Error           string
+}
+
+// IsSuccessful returns true if the endpoint answered with a 2xx status code
+func (a WebhookDeliveryAttemptCreate) IsSuccessful() bool {
+	return a.StatusCode != nil && *a.StatusCode >= 200 && *a.StatusCode < 300
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookEventFiltersValidate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, WebhookEventFilters{}.Validate())
+	assert.NoError(t, WebhookEventFilters{
+		DeliveryStatus: []WebhookEventDeliveryStatus{Retry, DeadLetter},
+		EventTypes:     []WebhookEventType{WebhookEventType_DecisionCreated},
+		StartDate:      now.Add(-time.Hour),
+		EndDate:        now,
+		Limit:          WEBHOOK_EVENTS_MAX_LIMIT,
+	}.Validate())
+
+	assert.ErrorIs(t, WebhookEventFilters{
+		DeliveryStatus: []WebhookEventDeliveryStatus{"failed"},
+	}.Validate(), BadParameterError)
+	assert.ErrorIs(t, WebhookEventFilters{
+		EventTypes: []WebhookEventType{"decision.deleted"},
+	}.Validate(), BadParameterError)
+	assert.ErrorIs(t, WebhookEventFilters{StartDate: now, EndDate: now.Add(-time.Hour)}.Validate(), BadParameterError)
+	assert.ErrorIs(t, WebhookEventFilters{Limit: WEBHOOK_EVENTS_MAX_LIMIT + 1}.Validate(), BadParameterError)
+}
+
+func TestWebhookEventsReplayValidate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, WebhookEventsReplay{StartDate: now.Add(-time.Hour), EndDate: now}.Validate())
+	assert.ErrorIs(t, WebhookEventsReplay{EndDate: now}.Validate(), BadParameterError)
+	assert.ErrorIs(t, WebhookEventsReplay{StartDate: now, EndDate: now.Add(-time.Hour)}.Validate(), BadParameterError)
+}
+
+func TestWebhookDeliveryBackendFrom(t *testing.T) {
+	backend, err := WebhookDeliveryBackendFrom("")
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookDeliveryBackendConvoy, backend)
+
+	backend, err = WebhookDeliveryBackendFrom("builtin")
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookDeliveryBackendBuiltin, backend)
+
+	_, err = WebhookDeliveryBackendFrom("kafka")
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package pure_utils
+
+import "encoding/json"
+
+// Null is an optional field of an update input: it is either left unchanged, set to a value, or cleared.
+// Unmarshalled from JSON, it is Set only if the field is present, and Valid only if the field is not null.
+type Null[T any] struct {
+	Value T
+	Valid bool
+	Set   bool
+}
+
+// NullFrom returns a field set to the value
+func NullFrom[T any](value T) Null[T] {
+	return Null[T]{Value: value, Valid: true, Set: true}
+}
+
+// NullCleared returns a field set to null
+func NullCleared[T any]() Null[T] {
+	return Null[T]{Set: true}
+}
+
+// Ptr returns a pointer to the value, or nil if it is null
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	value := n.Value
+	return &value
+}
+
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Valid = false
+		var zero T
+		n.Value = zero
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Value); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,32 @@
+package pure_utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNull_UnmarshalJSON(t *testing.T) {
+	type body struct {
+		Threshold Null[int] `json:"threshold"`
+	}
+
+	var absent body
+	assert.NoError(t, json.Unmarshal([]byte(`{}`), &absent))
+	assert.Equal(t, Null[int]{}, absent.Threshold)
+	assert.Nil(t, absent.Threshold.Ptr())
+
+	var cleared body
+	assert.NoError(t, json.Unmarshal([]byte(`{"threshold": null}`), &cleared))
+	assert.Equal(t, NullCleared[int](), cleared.Threshold)
+	assert.Nil(t, cleared.Threshold.Ptr())
+
+	var set body
+	assert.NoError(t, json.Unmarshal([]byte(`{"threshold": 10}`), &set))
+	assert.Equal(t, NullFrom(10), set.Threshold)
+	assert.Equal(t, 10, *set.Threshold.Ptr())
+
+	var invalid body
+	assert.Error(t, json.Unmarshal([]byte(`{"threshold": "ten"}`), &invalid))
+}
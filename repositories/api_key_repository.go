@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/checkmarble/marble-backend/models"
@@ -77,6 +78,13 @@ func (repo *MarbleDbRepository) CreateApiKey(ctx context.Context, exec Executor,
 				"key_hash",
 				"description",
 				"role",
+				"custom_role_id",
+				"expires_at",
+				"scenario_ids",
+				"trigger_object_types",
+				"ingestion_tables",
+				"allowed_networks",
+				"rotated_from_id",
 			).
 			Values(
 				apiKey.Id,
@@ -85,6 +93,13 @@ func (repo *MarbleDbRepository) CreateApiKey(ctx context.Context, exec Executor,
 				apiKey.Hash,
 				apiKey.Description,
 				apiKey.Role,
+				apiKey.CustomRoleId,
+				apiKey.ExpiresAt,
+				nonNilStrings(apiKey.Scope.ScenarioIds),
+				nonNilStrings(apiKey.Scope.TriggerObjectTypes),
+				nonNilStrings(apiKey.Scope.IngestionTables),
+				dbmodels.SerializeAllowedNetworks(apiKey.AllowedNetworks),
+				apiKey.RotatedFromId,
 			),
 	)
 	return err
@@ -105,3 +120,27 @@ func (repo *MarbleDbRepository) SoftDeleteApiKey(ctx context.Context, exec Execu
 	)
 	return err
 }
+
+// ExpireApiKey brings forward the expiration date of the key, it never postpones it
+func (repo *MarbleDbRepository) ExpireApiKey(ctx context.Context, exec Executor, apiKeyId string, expiresAt time.Time) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_APIKEYS).
+			Set("expires_at", squirrel.Expr("LEAST(COALESCE(expires_at, ?), ?)", expiresAt, expiresAt)).
+			Where(squirrel.Eq{"id": apiKeyId}),
+	)
+}
+
+// the array columns of the api keys cannot be null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+// ErrAsyncDecisionNotPending is returned when updating the status of an asynchronous decision that has already been
+// processed, e.g. by another worker
+var ErrAsyncDecisionNotPending = errors.New("the asynchronous decision is not pending")
+
+func (repo *MarbleDbRepository) CreateAsyncDecision(
+	ctx context.Context,
+	exec Executor,
+	id string,
+	input models.CreateAsyncDecisionInput,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_ASYNC_DECISIONS).
+			Columns(
+				"id",
+				"org_id",
+				"scenario_id",
+				"trigger_object_type",
+				"payload",
+				"status",
+			).
+			Values(
+				id,
+				input.OrganizationId,
+				input.ScenarioId,
+				input.TriggerObjectTable,
+				[]byte(input.PayloadRaw),
+				models.AsyncDecisionPending,
+			),
+	)
+}
+
+func (repo *MarbleDbRepository) GetAsyncDecision(ctx context.Context, exec Executor, id string) (models.AsyncDecision, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.AsyncDecision{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.AsyncDecisionFields...).
+			From(dbmodels.TABLE_ASYNC_DECISIONS).
+			Where(squirrel.Eq{"id": id}),
+		dbmodels.AdaptAsyncDecision,
+	)
+}
+
+// UpdateAsyncDecisionStatus sets the final status of a pending asynchronous decision. The row stays locked until the
+// end of the transaction, so that a concurrent update of the same decision returns ErrAsyncDecisionNotPending.
+func (repo *MarbleDbRepository) UpdateAsyncDecisionStatus(
+	ctx context.Context,
+	exec Executor,
+	id string,
+	status models.AsyncDecisionStatus,
+	asyncDecisionError *string,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	sql, args, err := NewQueryBuilder().
+		Update(dbmodels.TABLE_ASYNC_DECISIONS).
+		Set("status", status).
+		Set("error", asyncDecisionError).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"status": models.AsyncDecisionPending}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "can't build sql query")
+	}
+
+	tag, err := exec.Exec(ctx, sql, args...)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error executing sql query: %s", sql))
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.Wrapf(ErrAsyncDecisionNotPending, "async decision %s", id)
+	}
+	return nil
+}
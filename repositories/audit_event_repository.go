@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+// ListAuditEvents returns a page of the audit events of the organization, ordered by date. The events are paginated by
+// keyset on the date and the id of the event of the offset.
+func (repo *MarbleDbRepository) ListAuditEvents(ctx context.Context, exec Executor, organizationId string,
+	filters models.AuditEventFilters, pagination models.PaginationAndSorting,
+) ([]models.AuditEvent, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	// the previous page is read in the reverse order, then put back in the requested order
+	order := pagination.Order
+	if pagination.Previous {
+		order = models.ReverseOrder(order)
+	}
+	query := NewQueryBuilder().
+		Select(dbmodels.AuditEventFields...).
+		From(dbmodels.TABLE_AUDIT_EVENTS).
+		Where(squirrel.Eq{"org_id": organizationId}).
+		OrderBy(fmt.Sprintf("created_at %s, id %s", order, order)).
+		Limit(uint64(pagination.Limit))
+	query = applyAuditEventFilters(query, filters)
+
+	if pagination.OffsetId != "" {
+		comparison := ">"
+		if order == models.SortingOrderDesc {
+			comparison = "<"
+		}
+		query = query.Where(fmt.Sprintf("(created_at, id) %s (SELECT created_at, id FROM %s WHERE id = ?)",
+			comparison, dbmodels.TABLE_AUDIT_EVENTS), pagination.OffsetId)
+	}
+
+	events, err := SqlToListOfModels(ctx, exec, query, dbmodels.AdaptAuditEvent)
+	if err != nil {
+		return nil, err
+	}
+	if pagination.Previous {
+		slices.Reverse(events)
+	}
+	return events, nil
+}
+
+func applyAuditEventFilters(query squirrel.SelectBuilder, filters models.AuditEventFilters) squirrel.SelectBuilder {
+	if len(filters.Tables) > 0 {
+		query = query.Where(squirrel.Eq{`"table"`: filters.Tables})
+	}
+	if len(filters.Operations) > 0 {
+		query = query.Where(squirrel.Eq{"operation": filters.Operations})
+	}
+	if filters.EntityId != "" {
+		query = query.Where(squirrel.Eq{"entity_id": filters.EntityId})
+	}
+	if filters.UserId != "" {
+		query = query.Where(squirrel.Eq{"user_id": filters.UserId})
+	}
+	if filters.ApiKeyId != "" {
+		query = query.Where(squirrel.Eq{"api_key_id": filters.ApiKeyId})
+	}
+	if !filters.StartDate.IsZero() {
+		query = query.Where(squirrel.GtOrEq{"created_at": filters.StartDate})
+	}
+	if !filters.EndDate.IsZero() {
+		query = query.Where(squirrel.LtOrEq{"created_at": filters.EndDate})
+	}
+	return query
+}
@@ -19,15 +19,17 @@ type AwsS3Repository struct {
 	s3Client *s3.Client
 }
 
-func NewS3Client() *s3.Client {
+func NewS3Client(forcePathStyle bool) *s3.Client {
 	// aws auto configure itself with the following environment variables:
-	// AWS_REGION, AWS_ACCESS_KEY, AWS_SECRET_KEY
+	// AWS_REGION, AWS_ACCESS_KEY, AWS_SECRET_KEY, and AWS_ENDPOINT_URL for S3 compatible storages
 	conf, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
 		panic(fmt.Errorf("fail to load AWS config: %w", err))
 	}
 
-	return s3.NewFromConfig(conf)
+	return s3.NewFromConfig(conf, func(o *s3.Options) {
+		o.UsePathStyle = forcePathStyle
+	})
 }
 
 func (repo *AwsS3Repository) StoreInBucket(ctx context.Context, bucketName string, key string, body io.Reader) error {
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"io"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+const signedUrlExpiryHours = 1
+
+// BlobRepository stores files in buckets, whatever the storage backend is.
+// The writer returned by OpenStream must be closed for the upload to complete, and its Close method returns the upload error.
+type BlobRepository interface {
+	ListFiles(ctx context.Context, bucketName, prefix string) ([]models.Blob, error)
+	GetFile(ctx context.Context, bucketName, fileName string) (models.Blob, error)
+	MoveFile(ctx context.Context, bucketName, source, destination string) error
+	OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser
+	DeleteFile(ctx context.Context, bucketName, fileName string) error
+	UpdateFileMetadata(ctx context.Context, bucketName, fileName string, metadata map[string]string) error
+	GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error)
+}
+
+type BlobStorageConfig struct {
+	Backend models.BlobStorageBackend
+	// Local backend: root directory of the buckets, each bucket being a subdirectory
+	LocalDirectory string
+	// Local backend: public url of the backend, used to build the download urls of the files
+	LocalDownloadUrl string
+	// Local backend: secret used to sign the download urls
+	LocalUrlSigningSecret string
+	// S3 backend: address buckets as http://host/bucket/key instead of http://bucket.host/key, required by MinIO
+	S3ForcePathStyle bool
+}
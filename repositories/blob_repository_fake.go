@@ -10,24 +10,24 @@ import (
 	"github.com/checkmarble/marble-backend/models"
 )
 
-type GcsRepositoryFake struct{}
+type BlobRepositoryFake struct{}
 
 const tempFilesDirectory = "tempFiles"
 
-func (repo *GcsRepositoryFake) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.GCSFile, error) {
+func (repo *BlobRepositoryFake) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.Blob, error) {
 	cwd, _ := os.Getwd()
 	files, err := os.ReadDir(filepath.Join(cwd, tempFilesDirectory))
 	if err != nil {
 		return nil, err
 	}
 
-	var gcsFiles []models.GCSFile
+	var gcsFiles []models.Blob
 	for _, file := range files {
 		fileReader, err := os.Open(filepath.Join(cwd, tempFilesDirectory, file.Name()))
 		if err != nil {
-			return []models.GCSFile{}, err
+			return []models.Blob{}, err
 		}
-		gcsFiles = append(gcsFiles, models.GCSFile{
+		gcsFiles = append(gcsFiles, models.Blob{
 			FileName:   file.Name(),
 			Reader:     fileReader,
 			BucketName: bucketName,
@@ -37,7 +37,7 @@ func (repo *GcsRepositoryFake) ListFiles(ctx context.Context, bucketName, prefix
 	return gcsFiles, nil
 }
 
-func (repo *GcsRepositoryFake) GetFile(ctx context.Context, bucketName, fileName string) (models.GCSFile, error) {
+func (repo *BlobRepositoryFake) GetFile(ctx context.Context, bucketName, fileName string) (models.Blob, error) {
 	cwd, _ := os.Getwd()
 	sanitizedFileName := strings.ReplaceAll(fileName, "/", ":") // Workaround because slashes are not allowed in file names
 	path := filepath.Join(cwd, tempFilesDirectory, sanitizedFileName)
@@ -46,18 +46,18 @@ func (repo *GcsRepositoryFake) GetFile(ctx context.Context, bucketName, fileName
 		panic(err)
 	}
 
-	return models.GCSFile{
+	return models.Blob{
 		FileName:   fileName,
 		Reader:     file,
 		BucketName: bucketName,
 	}, nil
 }
 
-func (repo *GcsRepositoryFake) MoveFile(ctx context.Context, bucketName, source, destination string) error {
+func (repo *BlobRepositoryFake) MoveFile(ctx context.Context, bucketName, source, destination string) error {
 	return nil
 }
 
-func (repo *GcsRepositoryFake) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
+func (repo *BlobRepositoryFake) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
 	cwd, _ := os.Getwd()
 	if _, err := os.Stat(tempFilesDirectory); os.IsNotExist(err) {
 		err := os.Mkdir(tempFilesDirectory, os.ModePerm)
@@ -74,15 +74,15 @@ func (repo *GcsRepositoryFake) OpenStream(ctx context.Context, bucketName, fileN
 	return file
 }
 
-func (repo *GcsRepositoryFake) UpdateFileMetadata(ctx context.Context, bucketName, fileName string, metadata map[string]string) error {
+func (repo *BlobRepositoryFake) UpdateFileMetadata(ctx context.Context, bucketName, fileName string, metadata map[string]string) error {
 	return nil
 }
 
-func (repo *GcsRepositoryFake) DeleteFile(ctx context.Context, bucketName, fileName string) error {
+func (repo *BlobRepositoryFake) DeleteFile(ctx context.Context, bucketName, fileName string) error {
 	return nil
 }
 
-func (repo *GcsRepositoryFake) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
+func (repo *BlobRepositoryFake) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
 	// dummy file, url valid for 3 years from 2023/12/15
 	return "https://storage.googleapis.com/data-ingestion-tokyo-country-381508/test.csv?Expires=1797266654&GoogleAccessId=admintest%40tokyo-country-381508.iam.gserviceaccount.com&Signature=YAVmUMWzR9sQBg9pZiDI%2FOnjRmun%2BT3Mkn84cGb%2FzYdd%2FGovpm6BNV928rAlFF33LnbmEr6JpdnW1SnA72dEOaWqOhRSWuw9pIPkxyZerD9NJyHXCmRSoSSwX7TDHKZZ0lIxz%2FxE8Wtu2Y7Q1Wn83tpigH1y8FNguSX8Zz4OjMKCSSbEXY5PsazNl12yj%2Bp8loqRwG9XIYXstLp0wKpdryz7WkqzORays7OuPs0uPoNFpTgEZtUhaoHTzRV%2FHEHnvEQ0FVFxNYnuTBPyeA%2FADlaSwDxRfGZbt65E4k73XgS1oMgdboPeCEopKAZ0Iikg7th1wdzrfetipvTucWpKOg%3D%3D", nil
 }
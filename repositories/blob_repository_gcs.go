@@ -17,23 +17,11 @@ import (
 	"google.golang.org/api/iterator"
 )
 
-const signedUrlExpiryHours = 1
-
-type GcsRepository interface {
-	ListFiles(ctx context.Context, bucketName, prefix string) ([]models.GCSFile, error)
-	GetFile(ctx context.Context, bucketName, fileName string) (models.GCSFile, error)
-	MoveFile(ctx context.Context, bucketName, source, destination string) error
-	OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser
-	DeleteFile(ctx context.Context, bucketName, fileName string) error
-	UpdateFileMetadata(ctx context.Context, bucketName, fileName string, metadata map[string]string) error
-	GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error)
-}
-
-type GcsRepositoryImpl struct {
+type GcsBlobRepository struct {
 	gcsClient *storage.Client
 }
 
-func (repository *GcsRepositoryImpl) getGCSClient(ctx context.Context) *storage.Client {
+func (repository *GcsBlobRepository) getGCSClient(ctx context.Context) *storage.Client {
 	// Lazy load the GCS client, as it is used only in one batch usecase, to avoid requiring GCS credentials for all devs
 	if repository.gcsClient != nil {
 		return repository.gcsClient
@@ -48,14 +36,14 @@ func (repository *GcsRepositoryImpl) getGCSClient(ctx context.Context) *storage.
 }
 
 // Not used since legacy CSV ingestion has been removed
-func (repository *GcsRepositoryImpl) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.GCSFile, error) {
+func (repository *GcsBlobRepository) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.Blob, error) {
 	bucket := repository.getGCSClient(ctx).Bucket(bucketName)
 	_, err := bucket.Attrs(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bucket to list GCS objects from bucket %s/%s: %w", bucketName, prefix, err)
 	}
 
-	var output []models.GCSFile
+	var output []models.Blob
 
 	query := &storage.Query{Prefix: prefix}
 	it := bucket.Objects(ctx, query)
@@ -73,7 +61,7 @@ func (repository *GcsRepositoryImpl) ListFiles(ctx context.Context, bucketName,
 			return nil, fmt.Errorf("failed to read GCS object %s/%s: %v", bucketName, attrs.Name, err)
 		}
 
-		output = append(output, models.GCSFile{
+		output = append(output, models.Blob{
 			FileName:   attrs.Name,
 			Reader:     r,
 			BucketName: bucketName,
@@ -83,11 +71,11 @@ func (repository *GcsRepositoryImpl) ListFiles(ctx context.Context, bucketName,
 	return output, nil
 }
 
-func (repository *GcsRepositoryImpl) GetFile(ctx context.Context, bucketName, fileName string) (models.GCSFile, error) {
+func (repository *GcsBlobRepository) GetFile(ctx context.Context, bucketName, fileName string) (models.Blob, error) {
 	tracer := utils.OpenTelemetryTracerFromContext(ctx)
 	ctx, span := tracer.Start(
 		ctx,
-		"repositories.GcsRepository.GetFile",
+		"repositories.GcsBlobRepository.GetFile",
 		trace.WithAttributes(attribute.String("bucket", bucketName)),
 		trace.WithAttributes(attribute.String("fileName", fileName)),
 	)
@@ -96,26 +84,26 @@ func (repository *GcsRepositoryImpl) GetFile(ctx context.Context, bucketName, fi
 
 	ctxBucket, span2 := tracer.Start(
 		ctx,
-		"repositories.GcsRepository.GetFile - bucket attrs",
+		"repositories.GcsBlobRepository.GetFile - bucket attrs",
 	)
 	defer span2.End()
 	_, err := bucket.Attrs(ctxBucket)
 	if err != nil {
-		return models.GCSFile{}, fmt.Errorf("failed to get bucket %s: %w", bucketName, err)
+		return models.Blob{}, fmt.Errorf("failed to get bucket %s: %w", bucketName, err)
 	}
 	span2.End()
 
 	ctx, span = tracer.Start(
 		ctx,
-		"repositories.GcsRepository.GetFile - file reader",
+		"repositories.GcsBlobRepository.GetFile - file reader",
 	)
 	defer span.End()
 	reader, err := bucket.Object(fileName).NewReader(ctx)
 	if err != nil {
-		return models.GCSFile{}, fmt.Errorf("failed to read GCS object %s/%s: %v", bucketName, fileName, err)
+		return models.Blob{}, fmt.Errorf("failed to read GCS object %s/%s: %v", bucketName, fileName, err)
 	}
 
-	return models.GCSFile{
+	return models.Blob{
 		FileName:   fileName,
 		Reader:     reader,
 		BucketName: bucketName,
@@ -123,7 +111,7 @@ func (repository *GcsRepositoryImpl) GetFile(ctx context.Context, bucketName, fi
 }
 
 // Not used since legacy CSV ingestion has been removed
-func (repository *GcsRepositoryImpl) MoveFile(ctx context.Context, bucketName, srcName, destName string) error {
+func (repository *GcsBlobRepository) MoveFile(ctx context.Context, bucketName, srcName, destName string) error {
 	gcsClient := repository.getGCSClient(ctx)
 	src := gcsClient.Bucket(bucketName).Object(srcName)
 	dst := gcsClient.Bucket(bucketName).Object(destName)
@@ -144,7 +132,7 @@ func (repository *GcsRepositoryImpl) MoveFile(ctx context.Context, bucketName, s
 	return nil
 }
 
-func (repository *GcsRepositoryImpl) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
+func (repository *GcsBlobRepository) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
 	gcsClient := repository.getGCSClient(ctx)
 
 	writer := gcsClient.Bucket(bucketName).Object(fileName).NewWriter(ctx)
@@ -152,7 +140,7 @@ func (repository *GcsRepositoryImpl) OpenStream(ctx context.Context, bucketName,
 	return writer
 }
 
-func (repository *GcsRepositoryImpl) UpdateFileMetadata(ctx context.Context,
+func (repository *GcsBlobRepository) UpdateFileMetadata(ctx context.Context,
 	bucketName, fileName string, metadata map[string]string,
 ) error {
 	gcsClient := repository.getGCSClient(ctx)
@@ -181,7 +169,7 @@ func (repository *GcsRepositoryImpl) UpdateFileMetadata(ctx context.Context,
 	return nil
 }
 
-func (repository *GcsRepositoryImpl) DeleteFile(ctx context.Context, bucketName, fileName string) error {
+func (repository *GcsBlobRepository) DeleteFile(ctx context.Context, bucketName, fileName string) error {
 	gcsClient := repository.getGCSClient(ctx)
 	defer gcsClient.Close()
 
@@ -197,7 +185,7 @@ func (repository *GcsRepositoryImpl) DeleteFile(ctx context.Context, bucketName,
 	return nil
 }
 
-func (repo *GcsRepositoryImpl) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
+func (repo *GcsBlobRepository) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
 	// This code will typically not run locally if you target the real GCS repository, because SignedURL only works with service account credentials (not end user credentials)
 	// Hence, run the code locally with the fake GCS repository always
 	bucket := repo.getGCSClient(ctx).Bucket(bucketName)
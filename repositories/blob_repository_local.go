@@ -0,0 +1,303 @@
+package repositories
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+// Route of the backend serving the files of the local blob storage: {BLOB_DOWNLOAD_ROUTE}/{bucket}/{file name}
+const BLOB_DOWNLOAD_ROUTE = "/blobs"
+
+const (
+	localBlobMetadataDirectory = ".metadata"
+	localBlobUploadPrefix      = ".upload-"
+)
+
+// BlobUrlSigner builds and checks the signed download urls of the files stored on the local filesystem, which are
+// served by the backend itself instead of a storage service.
+type BlobUrlSigner struct {
+	baseUrl string
+	secret  []byte
+}
+
+func NewBlobUrlSigner(baseUrl, secret string) *BlobUrlSigner {
+	return &BlobUrlSigner{
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+func (signer *BlobUrlSigner) signature(bucketName, fileName string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, signer.secret)
+	mac.Write([]byte(fmt.Sprintf("%s\n%s\n%d", bucketName, fileName, expiresAt)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (signer *BlobUrlSigner) SignedUrl(bucketName, fileName string, expiresAt time.Time) string {
+	segments := strings.Split(fileName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"signature": {signer.signature(bucketName, fileName, expiresAt.Unix())},
+	}
+	return fmt.Sprintf("%s%s/%s/%s?%s", signer.baseUrl, BLOB_DOWNLOAD_ROUTE,
+		url.PathEscape(bucketName), strings.Join(segments, "/"), query.Encode())
+}
+
+func (signer *BlobUrlSigner) Verify(bucketName, fileName string, expiresAt int64, signature string) error {
+	if time.Now().Unix() > expiresAt {
+		return errors.Wrap(models.ForbiddenError, "the download url has expired")
+	}
+	expected := signer.signature(bucketName, fileName, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.Wrap(models.ForbiddenError, "invalid download url signature")
+	}
+	return nil
+}
+
+// LocalBlobRepository stores the files on the local filesystem (or on a mounted volume), each bucket being a
+// subdirectory of the root directory. The metadata of the files is stored as JSON in a separate directory tree.
+type LocalBlobRepository struct {
+	rootDirectory string
+	urlSigner     *BlobUrlSigner
+}
+
+func NewLocalBlobRepository(rootDirectory string, urlSigner *BlobUrlSigner) *LocalBlobRepository {
+	return &LocalBlobRepository{
+		rootDirectory: rootDirectory,
+		urlSigner:     urlSigner,
+	}
+}
+
+func (repository *LocalBlobRepository) bucketPath(bucketName string) (string, error) {
+	if bucketName == "" || strings.HasPrefix(bucketName, ".") || strings.ContainsAny(bucketName, `/\`) {
+		return "", errors.Wrapf(models.BadParameterError, "invalid bucket name %q", bucketName)
+	}
+	return filepath.Join(repository.rootDirectory, bucketName), nil
+}
+
+// relativeFilePath returns the path of the file inside its bucket, rejecting the names that would escape the bucket
+func relativeFilePath(fileName string) (string, error) {
+	relativePath := filepath.Clean(filepath.FromSlash("/" + fileName))
+	if relativePath == string(filepath.Separator) || strings.HasPrefix(filepath.Base(relativePath), localBlobUploadPrefix) {
+		return "", errors.Wrapf(models.BadParameterError, "invalid file name %q", fileName)
+	}
+	return relativePath, nil
+}
+
+func (repository *LocalBlobRepository) filePath(bucketName, fileName string) (string, error) {
+	bucketPath, err := repository.bucketPath(bucketName)
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := relativeFilePath(fileName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(bucketPath, relativePath), nil
+}
+
+func (repository *LocalBlobRepository) metadataPath(bucketName, fileName string) (string, error) {
+	if _, err := repository.bucketPath(bucketName); err != nil {
+		return "", err
+	}
+	relativePath, err := relativeFilePath(fileName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repository.rootDirectory, localBlobMetadataDirectory, bucketName, relativePath+".json"), nil
+}
+
+func (repository *LocalBlobRepository) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.Blob, error) {
+	bucketPath, err := repository.bucketPath(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	var output []models.Blob
+	err = filepath.WalkDir(bucketPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), localBlobUploadPrefix) {
+			return nil
+		}
+		relativePath, err := filepath.Rel(bucketPath, path)
+		if err != nil {
+			return err
+		}
+		fileName := filepath.ToSlash(relativePath)
+		if !strings.HasPrefix(fileName, prefix) {
+			return nil
+		}
+		reader, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		output = append(output, models.Blob{
+			FileName:   fileName,
+			Reader:     reader,
+			BucketName: bucketName,
+		})
+		return nil
+	})
+	if err != nil {
+		for _, file := range output {
+			file.Reader.Close()
+		}
+		return nil, fmt.Errorf("failed to list files from bucket %s/%s: %w", bucketName, prefix, err)
+	}
+	return output, nil
+}
+
+func (repository *LocalBlobRepository) GetFile(ctx context.Context, bucketName, fileName string) (models.Blob, error) {
+	path, err := repository.filePath(bucketName, fileName)
+	if err != nil {
+		return models.Blob{}, err
+	}
+	reader, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return models.Blob{}, errors.Wrapf(models.NotFoundError, "file %s/%s not found", bucketName, fileName)
+	} else if err != nil {
+		return models.Blob{}, fmt.Errorf("failed to read file %s/%s: %w", bucketName, fileName, err)
+	}
+	return models.Blob{
+		FileName:   fileName,
+		Reader:     reader,
+		BucketName: bucketName,
+	}, nil
+}
+
+func (repository *LocalBlobRepository) MoveFile(ctx context.Context, bucketName, srcName, destName string) error {
+	srcPath, err := repository.filePath(bucketName, srcName)
+	if err != nil {
+		return err
+	}
+	destPath, err := repository.filePath(bucketName, destName)
+	if err != nil {
+		return err
+	}
+	// same precondition as the other backends: the destination must not exist
+	if _, err := os.Stat(destPath); err == nil {
+		return errors.Wrapf(models.ConflictError, "file %s/%s already exists", bucketName, destName)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+		return err
+	}
+	if err := os.Rename(srcPath, destPath); err != nil {
+		return fmt.Errorf("failed to move file %s/%s to %s: %w", bucketName, srcName, destName, err)
+	}
+
+	srcMetadataPath, _ := repository.metadataPath(bucketName, srcName)
+	destMetadataPath, _ := repository.metadataPath(bucketName, destName)
+	if _, err := os.Stat(srcMetadataPath); err == nil {
+		if err := os.MkdirAll(filepath.Dir(destMetadataPath), 0o750); err != nil {
+			return err
+		}
+		return os.Rename(srcMetadataPath, destMetadataPath)
+	}
+	return nil
+}
+
+// localUploadWriter writes to a temporary file, renamed to its final name on Close so that readers never see
+// a partially written file
+type localUploadWriter struct {
+	file *os.File
+	path string
+	err  error
+}
+
+func (writer *localUploadWriter) Write(p []byte) (int, error) {
+	if writer.err != nil {
+		return 0, writer.err
+	}
+	return writer.file.Write(p)
+}
+
+func (writer *localUploadWriter) Close() error {
+	if writer.err != nil {
+		return writer.err
+	}
+	if err := writer.file.Close(); err != nil {
+		os.Remove(writer.file.Name())
+		return err
+	}
+	return os.Rename(writer.file.Name(), writer.path)
+}
+
+func (repository *LocalBlobRepository) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
+	path, err := repository.filePath(bucketName, fileName)
+	if err != nil {
+		return &localUploadWriter{err: err}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return &localUploadWriter{err: err}
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), localBlobUploadPrefix+"*")
+	if err != nil {
+		return &localUploadWriter{err: err}
+	}
+	return &localUploadWriter{file: file, path: path}
+}
+
+func (repository *LocalBlobRepository) DeleteFile(ctx context.Context, bucketName, fileName string) error {
+	path, err := repository.filePath(bucketName, fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Error deleting file: %s", fileName))
+	}
+	metadataPath, _ := repository.metadataPath(bucketName, fileName)
+	if err := os.Remove(metadataPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (repository *LocalBlobRepository) UpdateFileMetadata(ctx context.Context,
+	bucketName, fileName string, metadata map[string]string,
+) error {
+	path, err := repository.filePath(bucketName, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to get file %s/%s: %w", bucketName, fileName, err)
+	}
+
+	metadataPath, _ := repository.metadataPath(bucketName, fileName)
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(metadataPath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(metadataPath, encoded, 0o640)
+}
+
+func (repository *LocalBlobRepository) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
+	if _, err := repository.filePath(bucketName, fileName); err != nil {
+		return "", err
+	}
+	return repository.urlSigner.SignedUrl(bucketName, fileName, time.Now().Add(signedUrlExpiryHours*time.Hour)), nil
+}
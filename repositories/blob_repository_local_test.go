@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func writeLocalBlob(t *testing.T, repository *LocalBlobRepository, bucketName, fileName, content string) {
+	writer := repository.OpenStream(context.Background(), bucketName, fileName)
+	_, err := io.WriteString(writer, content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+}
+
+func readLocalBlob(t *testing.T, blob models.Blob) string {
+	defer blob.Reader.Close()
+	content, err := io.ReadAll(blob.Reader)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestLocalBlobRepository(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	repository := NewLocalBlobRepository(root, NewBlobUrlSigner("http://localhost:8080/", "secret"))
+
+	writeLocalBlob(t, repository, "cases", "org/case/file.txt", "hello")
+	writeLocalBlob(t, repository, "cases", "other/file.txt", "world")
+
+	blob, err := repository.GetFile(ctx, "cases", "org/case/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readLocalBlob(t, blob))
+
+	_, err = repository.GetFile(ctx, "cases", "org/case/missing.txt")
+	assert.ErrorIs(t, err, models.NotFoundError)
+
+	files, err := repository.ListFiles(ctx, "cases", "org/")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "org/case/file.txt", files[0].FileName)
+	files[0].Reader.Close()
+
+	require.NoError(t, repository.UpdateFileMetadata(ctx, "cases", "org/case/file.txt",
+		map[string]string{"processed": "true"}))
+	_, err = os.Stat(filepath.Join(root, localBlobMetadataDirectory, "cases", "org", "case", "file.txt.json"))
+	assert.NoError(t, err)
+
+	require.NoError(t, repository.MoveFile(ctx, "cases", "org/case/file.txt", "org/archive/file.txt"))
+	blob, err = repository.GetFile(ctx, "cases", "org/archive/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readLocalBlob(t, blob))
+	_, err = os.Stat(filepath.Join(root, localBlobMetadataDirectory, "cases", "org", "archive", "file.txt.json"))
+	assert.NoError(t, err)
+
+	err = repository.MoveFile(ctx, "cases", "other/file.txt", "org/archive/file.txt")
+	assert.ErrorIs(t, err, models.ConflictError)
+
+	require.NoError(t, repository.DeleteFile(ctx, "cases", "org/archive/file.txt"))
+	_, err = repository.GetFile(ctx, "cases", "org/archive/file.txt")
+	assert.ErrorIs(t, err, models.NotFoundError)
+}
+
+func TestLocalBlobRepositoryStaysInBucket(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	repository := NewLocalBlobRepository(filepath.Join(root, "storage"), NewBlobUrlSigner("", "secret"))
+
+	// ".." cannot escape the bucket
+	writeLocalBlob(t, repository, "cases", "../../outside.txt", "content")
+	_, err := os.Stat(filepath.Join(root, "storage", "cases", "outside.txt"))
+	assert.NoError(t, err)
+
+	for _, bucketName := range []string{"", ".metadata", "../cases", "a/b"} {
+		_, err := repository.GetFile(ctx, bucketName, "file.txt")
+		assert.ErrorIs(t, err, models.BadParameterError, bucketName)
+	}
+
+	writer := repository.OpenStream(ctx, "cases", "/")
+	_, err = writer.Write([]byte("content"))
+	assert.ErrorIs(t, err, models.BadParameterError)
+	assert.ErrorIs(t, writer.Close(), models.BadParameterError)
+}
+
+func TestBlobUrlSigner(t *testing.T) {
+	signer := NewBlobUrlSigner("https://api.marble.test/", "secret")
+	expiresAt := time.Now().Add(time.Hour)
+
+	signedUrl := signer.SignedUrl("cases", "org/case/my file.pdf", expiresAt)
+	parsed, err := url.Parse(signedUrl)
+	require.NoError(t, err)
+	assert.Equal(t, "api.marble.test", parsed.Host)
+	assert.Equal(t, "/blobs/cases/org/case/my file.pdf", parsed.Path)
+	assert.Equal(t, strconv.FormatInt(expiresAt.Unix(), 10), parsed.Query().Get("expires"))
+
+	signature := parsed.Query().Get("signature")
+	assert.NoError(t, signer.Verify("cases", "org/case/my file.pdf", expiresAt.Unix(), signature))
+	assert.ErrorIs(t, signer.Verify("cases", "org/case/other.pdf", expiresAt.Unix(), signature), models.ForbiddenError)
+	assert.ErrorIs(t, signer.Verify("cases", "org/case/my file.pdf", expiresAt.Unix()+1, signature), models.ForbiddenError)
+	assert.ErrorIs(t, NewBlobUrlSigner("", "other").Verify("cases", "org/case/my file.pdf", expiresAt.Unix(), signature),
+		models.ForbiddenError)
+
+	expired := time.Now().Add(-time.Minute)
+	expiredUrl, _ := url.Parse(signer.SignedUrl("cases", "file.pdf", expired))
+	err = signer.Verify("cases", "file.pdf", expired.Unix(), expiredUrl.Query().Get("signature"))
+	assert.ErrorIs(t, err, models.ForbiddenError)
+	assert.True(t, strings.Contains(err.Error(), "expired"))
+}
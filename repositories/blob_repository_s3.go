@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+// S3BlobRepository stores the files in AWS S3 or in any S3 compatible storage (MinIO, Ceph...).
+// The endpoint of S3 compatible storages is configured with the AWS_ENDPOINT_URL environment variable.
+type S3BlobRepository struct {
+	s3Client *s3.Client
+}
+
+func (repository *S3BlobRepository) ListFiles(ctx context.Context, bucketName, prefix string) ([]models.Blob, error) {
+	var output []models.Blob
+
+	paginator := s3.NewListObjectsV2Paginator(repository.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list S3 objects from bucket %s/%s: %w", bucketName, prefix, err)
+		}
+		for _, object := range page.Contents {
+			file, err := repository.GetFile(ctx, bucketName, aws.ToString(object.Key))
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, file)
+		}
+	}
+
+	return output, nil
+}
+
+func (repository *S3BlobRepository) GetFile(ctx context.Context, bucketName, fileName string) (models.Blob, error) {
+	tracer := utils.OpenTelemetryTracerFromContext(ctx)
+	ctx, span := tracer.Start(
+		ctx,
+		"repositories.S3BlobRepository.GetFile",
+		trace.WithAttributes(attribute.String("bucket", bucketName)),
+		trace.WithAttributes(attribute.String("fileName", fileName)),
+	)
+	defer span.End()
+
+	object, err := repository.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		return models.Blob{}, fmt.Errorf("failed to read S3 object %s/%s: %w", bucketName, fileName, err)
+	}
+
+	return models.Blob{
+		FileName:   fileName,
+		Reader:     object.Body,
+		BucketName: bucketName,
+	}, nil
+}
+
+func (repository *S3BlobRepository) MoveFile(ctx context.Context, bucketName, srcName, destName string) error {
+	_, err := repository.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(bucketName),
+		CopySource: aws.String(s3CopySource(bucketName, srcName)),
+		Key:        aws.String(destName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy S3 object %s/%s to %s: %w", bucketName, srcName, destName, err)
+	}
+	return repository.DeleteFile(ctx, bucketName, srcName)
+}
+
+// s3UploadWriter streams what is written to it to a multipart upload running in its own goroutine
+type s3UploadWriter struct {
+	pipeWriter *io.PipeWriter
+	done       <-chan error
+}
+
+func (writer *s3UploadWriter) Write(p []byte) (int, error) {
+	return writer.pipeWriter.Write(p)
+}
+
+func (writer *s3UploadWriter) Close() error {
+	writer.pipeWriter.Close()
+	return <-writer.done
+}
+
+func (repository *S3BlobRepository) OpenStream(ctx context.Context, bucketName, fileName string) io.WriteCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	done := make(chan error, 1)
+
+	uploader := manager.NewUploader(repository.s3Client)
+	go func() {
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(fileName),
+			Body:   pipeReader,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to upload S3 object %s/%s: %w", bucketName, fileName, err)
+			// unblock the writer if the upload stopped before reading everything
+			pipeReader.CloseWithError(err)
+		}
+		done <- err
+	}()
+
+	return &s3UploadWriter{pipeWriter: pipeWriter, done: done}
+}
+
+func (repository *S3BlobRepository) DeleteFile(ctx context.Context, bucketName, fileName string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := repository.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Error deleting file: %s", fileName))
+	}
+	return nil
+}
+
+func (repository *S3BlobRepository) UpdateFileMetadata(ctx context.Context,
+	bucketName, fileName string, metadata map[string]string,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	// S3 objects are immutable: the metadata is replaced by copying the object onto itself, keeping its content type
+	head, err := repository.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get S3 object %s/%s: %w", bucketName, fileName, err)
+	}
+
+	_, err = repository.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(bucketName),
+		CopySource:        aws.String(s3CopySource(bucketName, fileName)),
+		Key:               aws.String(fileName),
+		ContentType:       head.ContentType,
+		Metadata:          metadata,
+		MetadataDirective: types.MetadataDirectiveReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update metadata of S3 object %s/%s: %w", bucketName, fileName, err)
+	}
+	return nil
+}
+
+func (repository *S3BlobRepository) GenerateSignedUrl(ctx context.Context, bucketName, fileName string) (string, error) {
+	request, err := s3.NewPresignClient(repository.s3Client).PresignGetObject(ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(fileName),
+		},
+		s3.WithPresignExpires(signedUrlExpiryHours*time.Hour),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign S3 object %s/%s: %w", bucketName, fileName, err)
+	}
+	return request.URL, nil
+}
+
+// s3CopySource returns the url encoded "bucket/key" expected by CopyObject
+func s3CopySource(bucketName, fileName string) string {
+	segments := strings.Split(fileName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return bucketName + "/" + strings.Join(segments, "/")
+}
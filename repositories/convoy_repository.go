@@ -72,11 +72,16 @@ func (repo ConvoyRepository) SendWebhookEvent(ctx context.Context, webhookEvent
 
 	ownerId := getOwnerId(webhookEvent.OrganizationId, webhookEvent.PartnerId)
 	eventType := string(webhookEvent.EventContent.Type)
+	// a replayed event must not be deduplicated with its previous sends by convoy
+	idempotencyKey := webhookEvent.Id
+	if webhookEvent.ReplayCount > 0 {
+		idempotencyKey = fmt.Sprintf("%s-replay-%d", webhookEvent.Id, webhookEvent.ReplayCount)
+	}
 
 	fanoutEvent, err := convoyClient.CreateEndpointFanoutEventWithResponse(ctx, projectId, convoy.ModelsFanoutEvent{
 		OwnerId:        &ownerId,
 		EventType:      &eventType,
-		IdempotencyKey: &webhookEvent.Id,
+		IdempotencyKey: &idempotencyKey,
 		Data:           &webhookEvent.EventContent.Data,
 	})
 	if err != nil {
@@ -23,13 +23,11 @@ type CustomListRepository interface {
 		exec Executor,
 		addCustomListValue models.AddCustomListValueInput,
 		newCustomListId string,
-		userId *models.UserId,
 	) error
 	DeleteCustomListValue(
 		ctx context.Context,
 		exec Executor,
 		deleteCustomListValue models.DeleteCustomListValueInput,
-		userId *models.UserId,
 	) error
 }
 
@@ -172,18 +170,11 @@ func (repo *CustomListRepositoryPostgresql) AddCustomListValue(
 	exec Executor,
 	addCustomListValue models.AddCustomListValueInput,
 	newId string,
-	userId *models.UserId,
 ) error {
 	if err := validateMarbleDbExecutor(exec); err != nil {
 		return err
 	}
 
-	if userId != nil {
-		if err := setCurrentUserIdContext(ctx, exec, userId); err != nil {
-			return err
-		}
-	}
-
 	err := ExecBuilder(
 		ctx,
 		exec,
@@ -206,18 +197,11 @@ func (repo *CustomListRepositoryPostgresql) DeleteCustomListValue(
 	ctx context.Context,
 	exec Executor,
 	deleteCustomListValue models.DeleteCustomListValueInput,
-	userId *models.UserId,
 ) error {
 	if err := validateMarbleDbExecutor(exec); err != nil {
 		return err
 	}
 
-	if userId != nil {
-		if err := setCurrentUserIdContext(ctx, exec, userId); err != nil {
-			return err
-		}
-	}
-
 	deleteRequest := NewQueryBuilder().Update(dbmodels.TABLE_CUSTOM_LIST_VALUE)
 
 	deleteRequest = deleteRequest.Set("deleted_at", squirrel.Expr("NOW()"))
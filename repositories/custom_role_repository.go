@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func selectCustomRoles() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(dbmodels.CustomRoleFields...).
+		From(dbmodels.TABLE_CUSTOM_ROLES)
+}
+
+func (repo *MarbleDbRepository) GetCustomRoleById(ctx context.Context, exec Executor, customRoleId string) (models.CustomRole, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.CustomRole{}, err
+	}
+
+	return SqlToModel(ctx, exec, selectCustomRoles().Where(squirrel.Eq{"id": customRoleId}), dbmodels.AdaptCustomRole)
+}
+
+func (repo *MarbleDbRepository) ListCustomRoles(ctx context.Context, exec Executor, organizationId string) ([]models.CustomRole, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		selectCustomRoles().
+			Where(squirrel.Eq{"org_id": organizationId}).
+			OrderBy("name"),
+		dbmodels.AdaptCustomRole,
+	)
+}
+
+func (repo *MarbleDbRepository) CreateCustomRole(ctx context.Context, exec Executor,
+	input models.CreateCustomRoleInput, newCustomRoleId string,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_CUSTOM_ROLES).
+			Columns("id", "org_id", "name", "description", "permissions").
+			Values(
+				newCustomRoleId,
+				input.OrganizationId,
+				input.Name,
+				input.Description,
+				dbmodels.SerializeCustomRolePermissions(input.Permissions),
+			),
+	)
+}
+
+func (repo *MarbleDbRepository) UpdateCustomRole(ctx context.Context, exec Executor, input models.UpdateCustomRoleInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_CUSTOM_ROLES).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": input.Id})
+	if input.Name != nil {
+		query = query.Set("name", *input.Name)
+	}
+	if input.Description != nil {
+		query = query.Set("description", *input.Description)
+	}
+	if input.Permissions != nil {
+		query = query.Set("permissions", dbmodels.SerializeCustomRolePermissions(*input.Permissions))
+	}
+	return ExecBuilder(ctx, exec, query)
+}
+
+func (repo *MarbleDbRepository) DeleteCustomRole(ctx context.Context, exec Executor, customRoleId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_CUSTOM_ROLES).
+			Where(squirrel.Eq{"id": customRoleId}),
+	)
+}
+
+// CountCustomRoleAssignments counts the users and the API keys, deleted or not, which have the custom role
+func (repo *MarbleDbRepository) CountCustomRoleAssignments(ctx context.Context, exec Executor, customRoleId string) (int, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := NewQueryBuilder().
+		Select(
+			"(SELECT COUNT(*) FROM " + dbmodels.TABLE_USERS + " WHERE custom_role_id = $1) + " +
+				"(SELECT COUNT(*) FROM " + dbmodels.TABLE_APIKEYS + " WHERE custom_role_id = $1)",
+		).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = exec.QueryRow(ctx, sql, append(args, customRoleId)...).Scan(&count)
+	return count, err
+}
+
+func (repo *MarbleDbRepository) SetUserCustomRole(ctx context.Context, exec Executor, userId models.UserId, customRoleId *string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_USERS).
+			Set("custom_role_id", customRoleId).
+			Where(squirrel.Eq{"id": userId}),
+	)
+}
+
+func (repo *MarbleDbRepository) SetApiKeyCustomRole(ctx context.Context, exec Executor, apiKeyId string, customRoleId *string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_APIKEYS).
+			Set("custom_role_id", customRoleId).
+			Where(squirrel.Eq{"id": apiKeyId}),
+	)
+}
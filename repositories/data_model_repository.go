@@ -33,6 +33,9 @@ type DataModelRepository interface {
 	) error
 	GetLinks(ctx context.Context, exec Executor, organizationId string) ([]models.LinkToSingle, error)
 	DeleteDataModel(ctx context.Context, exec Executor, organizationID string) error
+	DeleteDataModelTable(ctx context.Context, exec Executor, tableID string) error
+	DeleteDataModelField(ctx context.Context, exec Executor, fieldID string) error
+	DeleteDataModelLink(ctx context.Context, exec Executor, linkID string) error
 	GetDataModelField(ctx context.Context, exec Executor, fieldId string) (models.FieldMetadata, error)
 
 	CreatePivot(ctx context.Context, exec Executor, id string, pivot models.CreatePivotInput) error
@@ -356,6 +359,50 @@ func (repo *DataModelRepositoryPostgresql) DeleteDataModel(ctx context.Context,
 	)
 }
 
+// DeleteDataModelTable deletes the table with its fields, its links and its pivot
+func (repo *DataModelRepositoryPostgresql) DeleteDataModelTable(ctx context.Context, exec Executor, tableID string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TableDataModelTables).
+			Where(squirrel.Eq{"id": tableID}),
+	)
+}
+
+// DeleteDataModelField deletes the field with its enum values, and the links and pivot using it
+func (repo *DataModelRepositoryPostgresql) DeleteDataModelField(ctx context.Context, exec Executor, fieldID string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TableDataModelFields).
+			Where(squirrel.Eq{"id": fieldID}),
+	)
+}
+
+func (repo *DataModelRepositoryPostgresql) DeleteDataModelLink(ctx context.Context, exec Executor, linkID string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TableDataModelLinks).
+			Where(squirrel.Eq{"id": linkID}),
+	)
+}
+
 func (repo *DataModelRepositoryPostgresql) GetEnumValues(ctx context.Context, exec Executor, fieldID string) ([]any, error) {
 	if err := validateMarbleDbExecutor(exec); err != nil {
 		return nil, err
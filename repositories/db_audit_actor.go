@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+// The audit triggers of the marble database read the actor of a write from these settings. They are set for the
+// transaction of the write only, so that a connection never carries the actor of another request.
+const setAuditActorQuery = `SELECT
+	set_config('custom.current_user_id', $1, true),
+	set_config('custom.current_api_key_id', $2, true),
+	set_config('custom.current_org_id', $3, true)`
+
+func auditActorArgs(ctx context.Context) ([]any, bool) {
+	creds, found := utils.CredentialsFromCtx(ctx)
+	if !found {
+		return nil, false
+	}
+	return []any{string(creds.ActorIdentity.UserId), creds.ActorIdentity.ApiKeyId, creds.OrganizationId}, true
+}
+
+func setAuditActor(ctx context.Context, tx pgx.Tx) error {
+	actor, found := auditActorArgs(ctx)
+	if !found {
+		return nil
+	}
+	_, err := tx.Exec(ctx, setAuditActorQuery, actor...)
+	return err
+}
+
+// auditedPool runs the statements made outside of a transaction with the actor of the context. The actor and the
+// statement are sent in a batch, which postgres runs in a single implicit transaction, so it costs no round trip.
+type auditedPool struct {
+	pool *pgxpool.Pool
+}
+
+func (p auditedPool) sendBatch(ctx context.Context, actor []any, sql string, args []any) (pgx.BatchResults, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(setAuditActorQuery, actor...)
+	batch.Queue(sql, args...)
+	results := p.pool.SendBatch(ctx, batch)
+	if _, err := results.Exec(); err != nil {
+		results.Close()
+		return nil, err
+	}
+	return results, nil
+}
+
+func (p auditedPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	actor, found := auditActorArgs(ctx)
+	if !found {
+		return p.pool.Exec(ctx, sql, args...)
+	}
+	results, err := p.sendBatch(ctx, actor, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tag, err := results.Exec()
+	if closeErr := results.Close(); err == nil {
+		err = closeErr
+	}
+	return tag, err
+}
+
+func (p auditedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	actor, found := auditActorArgs(ctx)
+	if !found {
+		return p.pool.Query(ctx, sql, args...)
+	}
+	results, err := p.sendBatch(ctx, actor, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := results.Query()
+	if err != nil {
+		results.Close()
+		return nil, err
+	}
+	return &batchRows{Rows: rows, results: results}, nil
+}
+
+func (p auditedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	actor, found := auditActorArgs(ctx)
+	if !found {
+		return p.pool.QueryRow(ctx, sql, args...)
+	}
+	results, err := p.sendBatch(ctx, actor, sql, args)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return batchRow{row: results.QueryRow(), results: results}
+}
+
+// batchRows releases the batch, and its connection, once the rows are read or closed
+type batchRows struct {
+	pgx.Rows
+	results  pgx.BatchResults
+	closeErr error
+}
+
+func (r *batchRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *batchRows) Close() {
+	r.Rows.Close()
+	r.closeErr = r.results.Close()
+}
+
+func (r *batchRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.closeErr
+}
+
+type batchRow struct {
+	row     pgx.Row
+	results pgx.BatchResults
+}
+
+func (r batchRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if closeErr := r.results.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
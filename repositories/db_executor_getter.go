@@ -36,6 +36,11 @@ func (g ExecutorGetter) Transaction(
 	fn func(exec Executor) error,
 ) error {
 	err := pgx.BeginFunc(ctx, g.connectionPool, func(tx pgx.Tx) error {
+		if databaseSchema.SchemaType == models.DATABASE_SCHEMA_TYPE_MARBLE {
+			if err := setAuditActor(ctx, tx); err != nil {
+				return err
+			}
+		}
 		return fn(&ExecutorPostgres{
 			databaseShema: databaseSchema,
 			exec:          tx,
@@ -51,6 +56,13 @@ func (g ExecutorGetter) Transaction(
 }
 
 func (g ExecutorGetter) GetExecutor(databaseSchema models.DatabaseSchema) Executor {
+	// the audited tables are in the marble database
+	if databaseSchema.SchemaType == models.DATABASE_SCHEMA_TYPE_MARBLE {
+		return &ExecutorPostgres{
+			databaseShema: databaseSchema,
+			exec:          auditedPool{pool: g.connectionPool},
+		}
+	}
 	return &ExecutorPostgres{
 		databaseShema: databaseSchema,
 		exec:          g.connectionPool,
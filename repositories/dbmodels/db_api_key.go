@@ -1,24 +1,35 @@
 package dbmodels
 
 import (
+	"net/netip"
 	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
 	"github.com/checkmarble/marble-backend/utils"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type DBApiKey struct {
-	Id             string             `db:"id"`
-	CreatedAt      time.Time          `db:"created_at"`
-	DeletedAt      pgtype.Timestamptz `db:"deleted_at"`
-	Description    string             `db:"description"`
-	Hash           []byte             `db:"key_hash"`
-	Prefix         string             `db:"prefix"`
-	PartnerId      pgtype.Text        `db:"partner_id"`
-	OrganizationId string             `db:"org_id"`
-	Role           int                `db:"role"`
+	Id                 string             `db:"id"`
+	CreatedAt          time.Time          `db:"created_at"`
+	DeletedAt          pgtype.Timestamptz `db:"deleted_at"`
+	Description        string             `db:"description"`
+	Hash               []byte             `db:"key_hash"`
+	Prefix             string             `db:"prefix"`
+	PartnerId          pgtype.Text        `db:"partner_id"`
+	OrganizationId     string             `db:"org_id"`
+	Role               int                `db:"role"`
+	CustomRoleId       *string            `db:"custom_role_id"`
+	ExpiresAt          *time.Time         `db:"expires_at"`
+	ScenarioIds        []string           `db:"scenario_ids"`
+	TriggerObjectTypes []string           `db:"trigger_object_types"`
+	IngestionTables    []string           `db:"ingestion_tables"`
+	AllowedNetworks    []string           `db:"allowed_networks"`
+	RotatedFromId      *string            `db:"rotated_from_id"`
+	LastUsedAt         *time.Time         `db:"last_used_at"`
+	RequestCount       int64              `db:"request_count"`
 }
 
 const TABLE_APIKEYS = "api_keys"
@@ -26,6 +37,11 @@ const TABLE_APIKEYS = "api_keys"
 var ApiKeyFields = utils.ColumnList[DBApiKey]()
 
 func AdaptApikey(db DBApiKey) (models.ApiKey, error) {
+	networks, err := models.ParseAllowedNetworks(db.AllowedNetworks)
+	if err != nil {
+		return models.ApiKey{}, err
+	}
+
 	out := models.ApiKey{
 		Id:             db.Id,
 		CreatedAt:      db.CreatedAt,
@@ -34,6 +50,17 @@ func AdaptApikey(db DBApiKey) (models.ApiKey, error) {
 		OrganizationId: db.OrganizationId,
 		Prefix:         db.Prefix,
 		Role:           models.Role(db.Role),
+		CustomRoleId:   db.CustomRoleId,
+		ExpiresAt:      db.ExpiresAt,
+		Scope: models.ApiKeyScope{
+			ScenarioIds:        db.ScenarioIds,
+			TriggerObjectTypes: db.TriggerObjectTypes,
+			IngestionTables:    db.IngestionTables,
+		},
+		AllowedNetworks: networks,
+		RotatedFromId:   db.RotatedFromId,
+		LastUsedAt:      db.LastUsedAt,
+		RequestCount:    db.RequestCount,
 	}
 	if db.PartnerId.Valid {
 		out.PartnerId = &db.PartnerId.String
@@ -41,3 +68,7 @@ func AdaptApikey(db DBApiKey) (models.ApiKey, error) {
 
 	return out, nil
 }
+
+func SerializeAllowedNetworks(networks []netip.Prefix) []string {
+	return pure_utils.Map(networks, netip.Prefix.String)
+}
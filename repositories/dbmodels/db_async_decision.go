@@ -0,0 +1,38 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const TABLE_ASYNC_DECISIONS = "async_decisions"
+
+type DBAsyncDecision struct {
+	Id                string    `db:"id"`
+	OrganizationId    string    `db:"org_id"`
+	ScenarioId        string    `db:"scenario_id"`
+	TriggerObjectType string    `db:"trigger_object_type"`
+	Payload           []byte    `db:"payload"`
+	Status            string    `db:"status"`
+	Error             *string   `db:"error"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+var AsyncDecisionFields = utils.ColumnList[DBAsyncDecision]()
+
+func AdaptAsyncDecision(db DBAsyncDecision) (models.AsyncDecision, error) {
+	return models.AsyncDecision{
+		Id:                db.Id,
+		OrganizationId:    db.OrganizationId,
+		ScenarioId:        db.ScenarioId,
+		TriggerObjectType: db.TriggerObjectType,
+		PayloadRaw:        db.Payload,
+		Status:            models.AsyncDecisionStatus(db.Status),
+		Error:             db.Error,
+		CreatedAt:         db.CreatedAt,
+		UpdatedAt:         db.UpdatedAt,
+	}, nil
+}
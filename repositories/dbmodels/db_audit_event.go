@@ -0,0 +1,43 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+const TABLE_AUDIT_EVENTS = "audit.audit_events"
+
+type DBAuditEvent struct {
+	Id             string    `db:"id"`
+	OrganizationId *string   `db:"org_id"`
+	Operation      string    `db:"operation"`
+	UserId         *string   `db:"user_id"`
+	ApiKeyId       *string   `db:"api_key_id"`
+	Table          string    `db:"table_name"`
+	EntityId       string    `db:"entity_id"`
+	Data           []byte    `db:"data"`
+	PreviousData   []byte    `db:"previous_data"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// "table" is a reserved word, the column is renamed when it is selected
+var AuditEventFields = []string{
+	"id", "org_id", "operation", "user_id", "api_key_id", `"table" AS table_name`, "entity_id", "data",
+	"previous_data", "created_at",
+}
+
+func AdaptAuditEvent(db DBAuditEvent) (models.AuditEvent, error) {
+	return models.AuditEvent{
+		Id:             db.Id,
+		OrganizationId: db.OrganizationId,
+		Operation:      models.AuditOperation(db.Operation),
+		UserId:         db.UserId,
+		ApiKeyId:       db.ApiKeyId,
+		Table:          db.Table,
+		EntityId:       db.EntityId,
+		Data:           db.Data,
+		PreviousData:   db.PreviousData,
+		CreatedAt:      db.CreatedAt,
+	}, nil
+}
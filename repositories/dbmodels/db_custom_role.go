@@ -0,0 +1,47 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const TABLE_CUSTOM_ROLES = "custom_roles"
+
+type DBCustomRole struct {
+	Id             string    `db:"id"`
+	OrganizationId string    `db:"org_id"`
+	Name           string    `db:"name"`
+	Description    string    `db:"description"`
+	Permissions    []string  `db:"permissions"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+var CustomRoleFields = utils.ColumnList[DBCustomRole]()
+
+func AdaptCustomRole(db DBCustomRole) (models.CustomRole, error) {
+	permissions := make([]models.Permission, 0, len(db.Permissions))
+	for _, name := range db.Permissions {
+		// permissions removed from Marble are no longer granted
+		if permission, err := models.PermissionFromString(name); err == nil {
+			permissions = append(permissions, permission)
+		}
+	}
+	return models.CustomRole{
+		Id:             db.Id,
+		OrganizationId: db.OrganizationId,
+		Name:           db.Name,
+		Description:    db.Description,
+		Permissions:    permissions,
+		CreatedAt:      db.CreatedAt,
+		UpdatedAt:      db.UpdatedAt,
+	}, nil
+}
+
+// Permissions are stored by name, so that the permission catalog can evolve
+func SerializeCustomRolePermissions(permissions []models.Permission) []string {
+	return pure_utils.Map(permissions, func(permission models.Permission) string { return permission.String() })
+}
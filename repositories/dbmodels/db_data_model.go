@@ -44,6 +44,7 @@ type DbDataModelTable struct {
 const (
 	TableDataModelTables = "data_model_tables"
 	TableDataModelFields = "data_model_fields"
+	TableDataModelLinks  = "data_model_links"
 )
 
 var SelectDataModelTableColumns = utils.ColumnList[DbDataModelTable]()
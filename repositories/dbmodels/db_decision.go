@@ -32,6 +32,11 @@ type DbDecision struct {
 	Score                int         `db:"score"`
 	TriggerObjectRaw     []byte      `db:"trigger_object"`
 	TriggerObjectType    string      `db:"trigger_object_type"`
+	OutputValues         []byte      `db:"output_values"`
+	ReviewDisposition    *string     `db:"review_disposition"`
+	ReviewedBy           *string     `db:"reviewed_by"`
+	ReviewedAt           *time.Time  `db:"reviewed_at"`
+	ReviewNote           *string     `db:"review_note"`
 }
 
 type DbJoinDecisionAndCase struct {
@@ -54,6 +59,27 @@ func AdaptDecision(db DbDecision, decisionCase *models.Case) models.Decision {
 		panic(fmt.Errorf("can't decode %w decision's trigger object", err))
 	}
 
+	outputValues := make(map[string]any)
+	if len(db.OutputValues) > 0 {
+		if err := json.Unmarshal(db.OutputValues, &outputValues); err != nil {
+			panic(fmt.Errorf("can't decode %w decision's output values", err))
+		}
+	}
+
+	var review *models.DecisionReview
+	if db.ReviewDisposition != nil {
+		review = &models.DecisionReview{
+			Disposition: models.DecisionReviewDisposition(*db.ReviewDisposition),
+			ReviewedBy:  (*models.UserId)(db.ReviewedBy),
+		}
+		if db.ReviewedAt != nil {
+			review.ReviewedAt = *db.ReviewedAt
+		}
+		if db.ReviewNote != nil {
+			review.Note = *db.ReviewNote
+		}
+	}
+
 	return models.Decision{
 		DecisionId:           db.Id,
 		OrganizationId:       db.OrganizationId,
@@ -70,6 +96,8 @@ func AdaptDecision(db DbDecision, decisionCase *models.Case) models.Decision {
 		ScenarioVersion:      db.ScenarioVersion,
 		Score:                db.Score,
 		ScheduledExecutionId: db.ScheduledExecutionId,
+		OutputValues:         outputValues,
+		Review:               review,
 	}
 }
 
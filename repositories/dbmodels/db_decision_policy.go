@@ -0,0 +1,73 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	TABLE_DECISION_POLICIES = "decision_policies"
+	TABLE_POLICY_DECISIONS  = "policy_decisions"
+)
+
+type DBDecisionPolicy struct {
+	Id                   string    `db:"id"`
+	OrganizationId       string    `db:"org_id"`
+	TriggerObjectType    string    `db:"trigger_object_type"`
+	ScenarioIds          []string  `db:"scenario_ids"`
+	StopOnReject         bool      `db:"stop_on_reject"`
+	Aggregation          string    `db:"aggregation"`
+	ScoreReviewThreshold *int      `db:"score_review_threshold"`
+	ScoreRejectThreshold *int      `db:"score_reject_threshold"`
+	CreatedAt            time.Time `db:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at"`
+}
+
+var DecisionPolicyFields = utils.ColumnList[DBDecisionPolicy]()
+
+func AdaptDecisionPolicy(db DBDecisionPolicy) (models.DecisionPolicy, error) {
+	return models.DecisionPolicy{
+		Id:                   db.Id,
+		OrganizationId:       db.OrganizationId,
+		TriggerObjectType:    db.TriggerObjectType,
+		ScenarioIds:          db.ScenarioIds,
+		StopOnReject:         db.StopOnReject,
+		Aggregation:          models.DecisionPolicyAggregation(db.Aggregation),
+		ScoreReviewThreshold: db.ScoreReviewThreshold,
+		ScoreRejectThreshold: db.ScoreRejectThreshold,
+		CreatedAt:            db.CreatedAt,
+		UpdatedAt:            db.UpdatedAt,
+	}, nil
+}
+
+type DBPolicyDecision struct {
+	Id                 string    `db:"id"`
+	OrganizationId     string    `db:"org_id"`
+	DecisionPolicyId   *string   `db:"decision_policy_id"`
+	TriggerObjectType  string    `db:"trigger_object_type"`
+	Outcome            string    `db:"outcome"`
+	Score              int       `db:"score"`
+	DecisionIds        []string  `db:"decision_ids"`
+	SkippedScenarioIds []string  `db:"skipped_scenario_ids"`
+	StoppedEarly       bool      `db:"stopped_early"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+var PolicyDecisionFields = utils.ColumnList[DBPolicyDecision]()
+
+func AdaptPolicyDecision(db DBPolicyDecision) (models.PolicyDecision, error) {
+	return models.PolicyDecision{
+		Id:                 db.Id,
+		OrganizationId:     db.OrganizationId,
+		DecisionPolicyId:   db.DecisionPolicyId,
+		TriggerObjectType:  db.TriggerObjectType,
+		Outcome:            models.OutcomeFrom(db.Outcome),
+		Score:              db.Score,
+		DecisionIds:        db.DecisionIds,
+		SkippedScenarioIds: db.SkippedScenarioIds,
+		StoppedEarly:       db.StoppedEarly,
+		CreatedAt:          db.CreatedAt,
+	}, nil
+}
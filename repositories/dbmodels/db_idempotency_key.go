@@ -0,0 +1,36 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type DBIdempotencyKey struct {
+	OrganizationId string    `db:"org_id"`
+	Scope          string    `db:"scope"`
+	Key            string    `db:"idempotency_key"`
+	RequestHash    string    `db:"request_hash"`
+	DecisionIds    []string  `db:"decision_ids"`
+	NbSkipped      int       `db:"nb_skipped"`
+	CreatedAt      time.Time `db:"created_at"`
+	ExpiresAt      time.Time `db:"expires_at"`
+}
+
+const TABLE_DECISION_IDEMPOTENCY_KEYS = "decision_idempotency_keys"
+
+var IdempotencyKeyFields = utils.ColumnList[DBIdempotencyKey]()
+
+func AdaptIdempotencyKey(db DBIdempotencyKey) (models.IdempotencyKey, error) {
+	return models.IdempotencyKey{
+		OrganizationId: db.OrganizationId,
+		Scope:          models.IdempotencyKeyScope(db.Scope),
+		Key:            db.Key,
+		RequestHash:    db.RequestHash,
+		DecisionIds:    db.DecisionIds,
+		NbSkipped:      db.NbSkipped,
+		CreatedAt:      db.CreatedAt,
+		ExpiresAt:      db.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type DBJob struct {
+	Id             string     `db:"id"`
+	Kind           string     `db:"kind"`
+	EntityId       string     `db:"entity_id"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	RunAt          time.Time  `db:"run_at"`
+	LockedBy       *string    `db:"locked_by"`
+	LeaseExpiresAt *time.Time `db:"lease_expires_at"`
+	LastError      *string    `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+const TABLE_JOB_QUEUE = "job_queue"
+
+var JobFields = utils.ColumnList[DBJob]()
+
+func AdaptJob(db DBJob) (models.Job, error) {
+	return models.Job{
+		Id:             db.Id,
+		Kind:           models.JobKind(db.Kind),
+		EntityId:       db.EntityId,
+		Status:         models.JobStatus(db.Status),
+		Attempts:       db.Attempts,
+		RunAt:          db.RunAt,
+		LockedBy:       db.LockedBy,
+		LeaseExpiresAt: db.LeaseExpiresAt,
+		LastError:      db.LastError,
+		CreatedAt:      db.CreatedAt,
+		UpdatedAt:      db.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	TABLE_RATE_LIMITS         = "rate_limits"
+	TABLE_RATE_LIMIT_COUNTERS = "rate_limit_counters"
+)
+
+type DBRateLimit struct {
+	Id                string    `db:"id"`
+	OrganizationId    string    `db:"org_id"`
+	ApiKeyId          *string   `db:"api_key_id"`
+	RequestsPerSecond float64   `db:"requests_per_second"`
+	Burst             int       `db:"burst"`
+	MonthlyQuota      int64     `db:"monthly_quota"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+var RateLimitFields = utils.ColumnList[DBRateLimit]()
+
+func AdaptRateLimitSettings(db DBRateLimit) (models.RateLimitSettings, error) {
+	return models.RateLimitSettings{
+		Id:             db.Id,
+		OrganizationId: db.OrganizationId,
+		ApiKeyId:       db.ApiKeyId,
+		Policy: models.RateLimitPolicy{
+			RequestsPerSecond: db.RequestsPerSecond,
+			Burst:             db.Burst,
+			MonthlyQuota:      db.MonthlyQuota,
+		},
+		CreatedAt: db.CreatedAt,
+		UpdatedAt: db.UpdatedAt,
+	}, nil
+}
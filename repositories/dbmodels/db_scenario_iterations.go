@@ -1,6 +1,7 @@
 package dbmodels
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,6 +27,13 @@ type DBScenarioIteration struct {
 	DeletedAt                     pgtype.Time `db:"deleted_at"`
 	BatchTriggerSQL               string      `db:"batch_trigger_sql"`
 	Schedule                      string      `db:"schedule"`
+	ScheduleTimezone              string      `db:"schedule_timezone"`
+	OutputVariables               []byte      `db:"output_variables"`
+}
+
+type DBOutputVariable struct {
+	Name                 string          `json:"name"`
+	FormulaAstExpression json.RawMessage `json:"formula_ast_expression"`
 }
 
 type DBScenarioIterationWithRules struct {
@@ -37,13 +45,14 @@ var SelectScenarioIterationColumn = utils.ColumnList[DBScenarioIteration]()
 
 func AdaptScenarioIteration(dto DBScenarioIteration) (models.ScenarioIteration, error) {
 	scenarioIteration := models.ScenarioIteration{
-		Id:              dto.Id,
-		OrganizationId:  dto.OrganizationId,
-		ScenarioId:      dto.ScenarioId,
-		CreatedAt:       dto.CreatedAt,
-		UpdatedAt:       dto.UpdatedAt,
-		BatchTriggerSQL: dto.BatchTriggerSQL,
-		Schedule:        dto.Schedule,
+		Id:               dto.Id,
+		OrganizationId:   dto.OrganizationId,
+		ScenarioId:       dto.ScenarioId,
+		CreatedAt:        dto.CreatedAt,
+		UpdatedAt:        dto.UpdatedAt,
+		BatchTriggerSQL:  dto.BatchTriggerSQL,
+		Schedule:         dto.Schedule,
+		ScheduleTimezone: dto.ScheduleTimezone,
 	}
 
 	if dto.Version.Valid {
@@ -66,6 +75,11 @@ func AdaptScenarioIteration(dto DBScenarioIteration) (models.ScenarioIteration,
 		return scenarioIteration, fmt.Errorf("unable to unmarshal trigger codition ast expression: %w", err)
 	}
 
+	scenarioIteration.OutputVariables, err = AdaptSerializedOutputVariables(dto.OutputVariables)
+	if err != nil {
+		return scenarioIteration, err
+	}
+
 	return scenarioIteration, nil
 }
 
@@ -82,3 +96,43 @@ func AdaptScenarioIterationWithRules(dto DBScenarioIterationWithRules) (models.S
 
 	return scenarioIteration, nil
 }
+
+func SerializeOutputVariables(outputVariables []models.OutputVariable) ([]byte, error) {
+	dbOutputVariables := make([]DBOutputVariable, len(outputVariables))
+	for i, outputVariable := range outputVariables {
+		formula, err := SerializeFormulaAstExpression(outputVariable.FormulaAstExpression)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal output variable %s: %w", outputVariable.Name, err)
+		}
+		dbOutputVariables[i] = DBOutputVariable{Name: outputVariable.Name}
+		if formula != nil {
+			dbOutputVariables[i].FormulaAstExpression = *formula
+		}
+	}
+	return json.Marshal(dbOutputVariables)
+}
+
+func AdaptSerializedOutputVariables(serializedOutputVariables []byte) ([]models.OutputVariable, error) {
+	if len(serializedOutputVariables) == 0 {
+		return []models.OutputVariable{}, nil
+	}
+
+	var dbOutputVariables []DBOutputVariable
+	if err := json.Unmarshal(serializedOutputVariables, &dbOutputVariables); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal output variables: %w", err)
+	}
+
+	outputVariables := make([]models.OutputVariable, len(dbOutputVariables))
+	for i, dbOutputVariable := range dbOutputVariables {
+		outputVariables[i] = models.OutputVariable{Name: dbOutputVariable.Name}
+		if string(dbOutputVariable.FormulaAstExpression) == "null" {
+			continue
+		}
+		formula, err := AdaptSerializedAstExpression(dbOutputVariable.FormulaAstExpression)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal output variable %s: %w", dbOutputVariable.Name, err)
+		}
+		outputVariables[i].FormulaAstExpression = formula
+	}
+	return outputVariables, nil
+}
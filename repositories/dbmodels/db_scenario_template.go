@@ -0,0 +1,84 @@
+package dbmodels
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const TABLE_SCENARIO_TEMPLATES = "scenario_templates"
+
+type DBScenarioTemplate struct {
+	Id           string    `db:"id"`
+	Name         string    `db:"name"`
+	Description  string    `db:"description"`
+	Placeholders []byte    `db:"placeholders"`
+	Bundle       []byte    `db:"bundle"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+var ScenarioTemplateFields = utils.ColumnList[DBScenarioTemplate]()
+
+type dbScenarioTemplatePlaceholder struct {
+	Name         string  `json:"name"`
+	Kind         string  `json:"kind"`
+	Description  string  `json:"description"`
+	DefaultValue *string `json:"default_value"`
+}
+
+func AdaptScenarioTemplate(db DBScenarioTemplate) (models.ScenarioTemplate, error) {
+	var placeholders []dbScenarioTemplatePlaceholder
+	if err := json.Unmarshal(db.Placeholders, &placeholders); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+	// the bundle is stored in the format of the exported bundles
+	var bundleDto dto.ScenarioBundleDto
+	if err := json.Unmarshal(db.Bundle, &bundleDto); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+	bundle, err := dto.AdaptScenarioBundle(bundleDto)
+	if err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+
+	return models.ScenarioTemplate{
+		Id:          db.Id,
+		Name:        db.Name,
+		Description: db.Description,
+		Placeholders: pure_utils.Map(placeholders, func(p dbScenarioTemplatePlaceholder) models.ScenarioTemplatePlaceholder {
+			return models.ScenarioTemplatePlaceholder{
+				Name:         p.Name,
+				Kind:         models.ScenarioTemplatePlaceholderKind(p.Kind),
+				Description:  p.Description,
+				DefaultValue: p.DefaultValue,
+			}
+		}),
+		Bundle:    bundle,
+		CreatedAt: db.CreatedAt,
+		UpdatedAt: db.UpdatedAt,
+	}, nil
+}
+
+func SerializeScenarioTemplatePlaceholders(placeholders []models.ScenarioTemplatePlaceholder) ([]byte, error) {
+	return json.Marshal(pure_utils.Map(placeholders, func(p models.ScenarioTemplatePlaceholder) dbScenarioTemplatePlaceholder {
+		return dbScenarioTemplatePlaceholder{
+			Name:         p.Name,
+			Kind:         string(p.Kind),
+			Description:  p.Description,
+			DefaultValue: p.DefaultValue,
+		}
+	}))
+}
+
+func SerializeScenarioTemplateBundle(bundle models.ScenarioBundle) ([]byte, error) {
+	bundleDto, err := dto.AdaptScenarioBundleDto(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(bundleDto)
+}
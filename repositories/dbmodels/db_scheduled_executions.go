@@ -16,6 +16,9 @@ type DBScheduledExecution struct {
 	FinishedAt               *time.Time `db:"finished_at"`
 	NumberOfCreatedDecisions int        `db:"number_of_created_decisions"`
 	Manual                   bool       `db:"manual"`
+	NumberOfEvaluatedObjects int        `db:"number_of_evaluated_objects"`
+	LastProcessedObjectId    *string    `db:"last_processed_object_id"`
+	UpdatedAt                time.Time  `db:"updated_at"`
 }
 
 const TABLE_SCHEDULED_EXECUTIONS = "scheduled_executions"
@@ -23,6 +26,7 @@ const TABLE_SCHEDULED_EXECUTIONS = "scheduled_executions"
 var ScheduledExecutionFields = []string{
 	"id", "organization_id", "scenario_id",
 	"scenario_iteration_id", "status", "started_at", "finished_at", "number_of_created_decisions", "manual",
+	"number_of_evaluated_objects", "last_processed_object_id", "updated_at",
 }
 
 func AdaptScheduledExecution(db DBScheduledExecution, scenario models.Scenario) models.ScheduledExecution {
@@ -34,7 +38,10 @@ func AdaptScheduledExecution(db DBScheduledExecution, scenario models.Scenario)
 		Status:                   models.ScheduledExecutionStatusFrom(db.Status),
 		StartedAt:                db.StartedAt,
 		FinishedAt:               db.FinishedAt,
+		UpdatedAt:                db.UpdatedAt,
 		NumberOfCreatedDecisions: db.NumberOfCreatedDecisions,
+		NumberOfEvaluatedObjects: db.NumberOfEvaluatedObjects,
+		LastProcessedObjectId:    db.LastProcessedObjectId,
 		Scenario:                 scenario,
 		Manual:                   db.Manual,
 	}
@@ -0,0 +1,76 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	TABLE_SCIM_TOKENS        = "scim_tokens"
+	TABLE_SCIM_GROUPS        = "scim_groups"
+	TABLE_SCIM_GROUP_MEMBERS = "scim_group_members"
+)
+
+type DBScimToken struct {
+	Id             string    `db:"id"`
+	OrganizationId string    `db:"org_id"`
+	Description    string    `db:"description"`
+	Prefix         string    `db:"prefix"`
+	Hash           []byte    `db:"token_hash"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+var ScimTokenFields = utils.ColumnList[DBScimToken]()
+
+func AdaptScimToken(db DBScimToken) (models.ScimToken, error) {
+	return models.ScimToken{
+		Id:             db.Id,
+		OrganizationId: db.OrganizationId,
+		Description:    db.Description,
+		Prefix:         db.Prefix,
+		Hash:           db.Hash,
+		CreatedAt:      db.CreatedAt,
+	}, nil
+}
+
+type DBScimGroup struct {
+	Id             string    `db:"id"`
+	OrganizationId string    `db:"org_id"`
+	DisplayName    string    `db:"display_name"`
+	ExternalId     string    `db:"external_id"`
+	Role           int       `db:"role"`
+	InboxId        *string   `db:"inbox_id"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+var ScimGroupFields = utils.ColumnList[DBScimGroup]()
+
+func AdaptScimGroup(db DBScimGroup) (models.ScimGroup, error) {
+	return models.ScimGroup{
+		Id:             db.Id,
+		OrganizationId: db.OrganizationId,
+		DisplayName:    db.DisplayName,
+		ExternalId:     db.ExternalId,
+		Role:           models.Role(db.Role),
+		InboxId:        db.InboxId,
+		CreatedAt:      db.CreatedAt,
+		UpdatedAt:      db.UpdatedAt,
+	}, nil
+}
+
+type DBScimGroupMember struct {
+	GroupId string `db:"group_id"`
+	UserId  string `db:"user_id"`
+	Email   string `db:"email"`
+}
+
+func AdaptScimGroupMember(db DBScimGroupMember) (models.ScimGroupMember, error) {
+	return models.ScimGroupMember{
+		GroupId: db.GroupId,
+		UserId:  models.UserId(db.UserId),
+		Email:   db.Email,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package dbmodels
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	TABLE_SSO_PROVIDERS    = "sso_providers"
+	TABLE_SSO_LOGIN_STATES = "sso_login_states"
+)
+
+type DBSsoProvider struct {
+	Id              string    `db:"id"`
+	OrganizationId  string    `db:"org_id"`
+	IssuerUrl       string    `db:"issuer_url"`
+	ClientId        string    `db:"client_id"`
+	ClientSecret    string    `db:"client_secret"`
+	Scopes          []string  `db:"scopes"`
+	RoleClaim       string    `db:"role_claim"`
+	RoleMappings    []byte    `db:"role_mappings"`
+	DefaultRole     int       `db:"default_role"`
+	JitProvisioning bool      `db:"jit_provisioning"`
+	Enabled         bool      `db:"enabled"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+var SsoProviderFields = utils.ColumnList[DBSsoProvider]()
+
+func AdaptSsoProvider(db DBSsoProvider) (models.SsoProvider, error) {
+	var roleNames map[string]string
+	if err := json.Unmarshal(db.RoleMappings, &roleNames); err != nil {
+		return models.SsoProvider{}, errors.Wrap(err, "can't unmarshal sso provider role mappings")
+	}
+	roleMappings := make(map[string]models.Role, len(roleNames))
+	for claimValue, roleName := range roleNames {
+		roleMappings[claimValue] = models.RoleFromString(roleName)
+	}
+
+	return models.SsoProvider{
+		Id:              db.Id,
+		OrganizationId:  db.OrganizationId,
+		IssuerUrl:       db.IssuerUrl,
+		ClientId:        db.ClientId,
+		ClientSecret:    db.ClientSecret,
+		Scopes:          db.Scopes,
+		RoleClaim:       db.RoleClaim,
+		RoleMappings:    roleMappings,
+		DefaultRole:     models.Role(db.DefaultRole),
+		JitProvisioning: db.JitProvisioning,
+		Enabled:         db.Enabled,
+		CreatedAt:       db.CreatedAt,
+		UpdatedAt:       db.UpdatedAt,
+	}, nil
+}
+
+// SerializeSsoRoleMappings stores the roles by name, to keep the mappings readable in the database
+func SerializeSsoRoleMappings(roleMappings map[string]models.Role) ([]byte, error) {
+	roleNames := make(map[string]string, len(roleMappings))
+	for claimValue, role := range roleMappings {
+		roleNames[claimValue] = role.String()
+	}
+	return json.Marshal(roleNames)
+}
+
+type DBSsoLoginState struct {
+	State          string    `db:"state"`
+	OrganizationId string    `db:"org_id"`
+	CodeVerifier   string    `db:"code_verifier"`
+	Nonce          string    `db:"nonce"`
+	RedirectUri    string    `db:"redirect_uri"`
+	ExpiresAt      time.Time `db:"expires_at"`
+}
+
+var SsoLoginStateFields = utils.ColumnList[DBSsoLoginState]()
+
+func AdaptSsoLoginState(db DBSsoLoginState) (models.SsoLoginState, error) {
+	return models.SsoLoginState(db), nil
+}
@@ -14,6 +14,7 @@ type DBUserResult struct {
 	PartnerId      *string            `db:"partner_id"`
 	FirstName      pgtype.Text        `db:"first_name"`
 	LastName       pgtype.Text        `db:"last_name"`
+	CustomRoleId   *string            `db:"custom_role_id"`
 	DeletedAt      pgtype.Timestamptz `db:"deleted_at"`
 }
 
@@ -23,10 +24,11 @@ var UserFields = utils.ColumnList[DBUserResult]()
 
 func AdaptUser(db DBUserResult) (models.User, error) {
 	user := models.User{
-		UserId:    models.UserId(db.Id),
-		Email:     db.Email,
-		Role:      models.Role(db.Role),
-		PartnerId: db.PartnerId,
+		UserId:       models.UserId(db.Id),
+		Email:        db.Email,
+		Role:         models.Role(db.Role),
+		PartnerId:    db.PartnerId,
+		CustomRoleId: db.CustomRoleId,
 	}
 	if db.OrganizationId != nil {
 		user.OrganizationId = *db.OrganizationId
@@ -21,6 +21,7 @@ type DBWebhookEvent struct {
 	PartnerId      pgtype.Text `db:"partner_id"`
 	EventType      string      `db:"event_type"`
 	EventData      []byte      `db:"event_data"`
+	ReplayCount    int         `db:"replay_count"`
 }
 
 const TABLE_WEBHOOK_EVENTS = "webhook_events"
@@ -46,5 +47,6 @@ func AdaptWebhookEvent(db DBWebhookEvent) (models.WebhookEvent, error) {
 			Type: models.WebhookEventType(db.EventType),
 			Data: eventData,
 		},
+		ReplayCount: db.ReplayCount,
 	}, nil
 }
@@ -0,0 +1,128 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+	"github.com/guregu/null/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type DBWebhook struct {
+	Id                string      `db:"id"`
+	OrganizationId    string      `db:"organization_id"`
+	PartnerId         pgtype.Text `db:"partner_id"`
+	Url               string      `db:"url"`
+	EventTypes        []string    `db:"event_types"`
+	HttpTimeout       *int        `db:"http_timeout"`
+	RateLimit         *int        `db:"rate_limit"`
+	RateLimitDuration *int        `db:"rate_limit_duration"`
+	CreatedAt         time.Time   `db:"created_at"`
+	UpdatedAt         time.Time   `db:"updated_at"`
+	DeletedAt         *time.Time  `db:"deleted_at"`
+}
+
+const TABLE_WEBHOOKS = "webhooks"
+
+var WebhookFields = utils.ColumnList[DBWebhook]()
+
+// AdaptWebhook does not set the secrets of the webhook, that are stored in their own table
+func AdaptWebhook(db DBWebhook) (models.Webhook, error) {
+	return models.Webhook{
+		Id:                db.Id,
+		OrganizationId:    db.OrganizationId,
+		PartnerId:         null.NewString(db.PartnerId.String, db.PartnerId.Valid),
+		EventTypes:        db.EventTypes,
+		Url:               db.Url,
+		HttpTimeout:       db.HttpTimeout,
+		RateLimit:         db.RateLimit,
+		RateLimitDuration: db.RateLimitDuration,
+	}, nil
+}
+
+type DBWebhookSecret struct {
+	Id        string     `db:"id"`
+	WebhookId string     `db:"webhook_id"`
+	Value     string     `db:"value"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+const TABLE_WEBHOOK_SECRETS = "webhook_secrets"
+
+var WebhookSecretFields = utils.ColumnList[DBWebhookSecret]()
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func AdaptWebhookSecret(db DBWebhookSecret) (models.Secret, error) {
+	return models.Secret{
+		Uid:       db.Id,
+		Value:     db.Value,
+		CreatedAt: db.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: db.UpdatedAt.Format(time.RFC3339),
+		ExpiresAt: formatOptionalTime(db.ExpiresAt),
+		DeletedAt: formatOptionalTime(db.DeletedAt),
+	}, nil
+}
+
+type DBWebhookDelivery struct {
+	Id             string    `db:"id"`
+	WebhookEventId string    `db:"webhook_event_id"`
+	WebhookId      string    `db:"webhook_id"`
+	Status         string    `db:"status"`
+	Attempts       int       `db:"attempts"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+const TABLE_WEBHOOK_DELIVERIES = "webhook_deliveries"
+
+var WebhookDeliveryFields = utils.ColumnList[DBWebhookDelivery]()
+
+func AdaptWebhookDelivery(db DBWebhookDelivery) (models.WebhookDelivery, error) {
+	return models.WebhookDelivery{
+		Id:             db.Id,
+		WebhookEventId: db.WebhookEventId,
+		WebhookId:      db.WebhookId,
+		Status:         models.WebhookEventDeliveryStatus(db.Status),
+		Attempts:       db.Attempts,
+		CreatedAt:      db.CreatedAt,
+		UpdatedAt:      db.UpdatedAt,
+	}, nil
+}
+
+type DBWebhookDeliveryAttempt struct {
+	Id              string    `db:"id"`
+	DeliveryId      string    `db:"delivery_id"`
+	WebhookId       string    `db:"webhook_id"`
+	CreatedAt       time.Time `db:"created_at"`
+	StatusCode      *int      `db:"status_code"`
+	LatencyMs       int       `db:"latency_ms"`
+	ResponseExcerpt string    `db:"response_excerpt"`
+	Error           string    `db:"error"`
+}
+
+const TABLE_WEBHOOK_DELIVERY_ATTEMPTS = "webhook_delivery_attempts"
+
+var WebhookDeliveryAttemptFields = utils.ColumnList[DBWebhookDeliveryAttempt]()
+
+func AdaptWebhookDeliveryAttempt(db DBWebhookDeliveryAttempt) (models.WebhookDeliveryAttempt, error) {
+	return models.WebhookDeliveryAttempt{
+		Id:              db.Id,
+		DeliveryId:      db.DeliveryId,
+		WebhookId:       db.WebhookId,
+		CreatedAt:       db.CreatedAt,
+		StatusCode:      db.StatusCode,
+		Latency:         time.Duration(db.LatencyMs) * time.Millisecond,
+		ResponseExcerpt: db.ResponseExcerpt,
+		Error:           db.Error,
+	}, nil
+}
@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func (repo *MarbleDbRepository) GetDecisionPolicy(ctx context.Context, exec Executor, id string) (models.DecisionPolicy, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.DecisionPolicy{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.DecisionPolicyFields...).
+			From(dbmodels.TABLE_DECISION_POLICIES).
+			Where(squirrel.Eq{"id": id}),
+		dbmodels.AdaptDecisionPolicy,
+	)
+}
+
+func (repo *MarbleDbRepository) GetDecisionPolicyOfTriggerObjectType(
+	ctx context.Context,
+	exec Executor,
+	organizationId string,
+	triggerObjectType string,
+) (models.DecisionPolicy, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.DecisionPolicy{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.DecisionPolicyFields...).
+			From(dbmodels.TABLE_DECISION_POLICIES).
+			Where(squirrel.Eq{
+				"org_id":              organizationId,
+				"trigger_object_type": triggerObjectType,
+			}),
+		dbmodels.AdaptDecisionPolicy,
+	)
+}
+
+func (repo *MarbleDbRepository) ListDecisionPolicies(ctx context.Context, exec Executor, organizationId string) ([]models.DecisionPolicy, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.DecisionPolicyFields...).
+			From(dbmodels.TABLE_DECISION_POLICIES).
+			Where(squirrel.Eq{"org_id": organizationId}).
+			OrderBy("trigger_object_type"),
+		dbmodels.AdaptDecisionPolicy,
+	)
+}
+
+func (repo *MarbleDbRepository) CreateDecisionPolicy(
+	ctx context.Context,
+	exec Executor,
+	id string,
+	input models.CreateDecisionPolicyInput,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	err := ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_DECISION_POLICIES).
+			Columns(
+				"id",
+				"org_id",
+				"trigger_object_type",
+				"scenario_ids",
+				"stop_on_reject",
+				"aggregation",
+				"score_review_threshold",
+				"score_reject_threshold",
+			).
+			Values(
+				id,
+				input.OrganizationId,
+				input.TriggerObjectType,
+				input.ScenarioIds,
+				input.StopOnReject,
+				input.Aggregation,
+				input.ScoreReviewThreshold,
+				input.ScoreRejectThreshold,
+			),
+	)
+	if IsUniqueViolationError(err) {
+		return errors.Wrapf(models.ConflictError,
+			"a decision policy already exists for trigger object type %s", input.TriggerObjectType)
+	}
+	return err
+}
+
+func (repo *MarbleDbRepository) UpdateDecisionPolicy(ctx context.Context, exec Executor, policy models.DecisionPolicy) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_DECISION_POLICIES).
+			Set("scenario_ids", policy.ScenarioIds).
+			Set("stop_on_reject", policy.StopOnReject).
+			Set("aggregation", policy.Aggregation).
+			Set("score_review_threshold", policy.ScoreReviewThreshold).
+			Set("score_reject_threshold", policy.ScoreRejectThreshold).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": policy.Id}),
+	)
+}
+
+func (repo *MarbleDbRepository) DeleteDecisionPolicy(ctx context.Context, exec Executor, id string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_DECISION_POLICIES).
+			Where(squirrel.Eq{"id": id}),
+	)
+}
+
+func (repo *MarbleDbRepository) StorePolicyDecision(ctx context.Context, exec Executor, policyDecision models.PolicyDecision) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	decisionIds := policyDecision.DecisionIds
+	if decisionIds == nil {
+		decisionIds = []string{}
+	}
+	skippedScenarioIds := policyDecision.SkippedScenarioIds
+	if skippedScenarioIds == nil {
+		skippedScenarioIds = []string{}
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_POLICY_DECISIONS).
+			Columns(
+				"id",
+				"org_id",
+				"decision_policy_id",
+				"trigger_object_type",
+				"outcome",
+				"score",
+				"decision_ids",
+				"skipped_scenario_ids",
+				"stopped_early",
+				"created_at",
+			).
+			Values(
+				policyDecision.Id,
+				policyDecision.OrganizationId,
+				policyDecision.DecisionPolicyId,
+				policyDecision.TriggerObjectType,
+				policyDecision.Outcome.String(),
+				policyDecision.Score,
+				decisionIds,
+				skippedScenarioIds,
+				policyDecision.StoppedEarly,
+				policyDecision.CreatedAt,
+			),
+	)
+}
+
+func (repo *MarbleDbRepository) GetPolicyDecision(ctx context.Context, exec Executor, id string) (models.PolicyDecision, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.PolicyDecision{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.PolicyDecisionFields...).
+			From(dbmodels.TABLE_POLICY_DECISIONS).
+			Where(squirrel.Eq{"id": id}),
+		dbmodels.AdaptPolicyDecision,
+	)
+}
@@ -50,6 +50,9 @@ type DecisionRepository interface {
 	DecisionsOfOrganization(ctx context.Context, exec Executor, organizationId string,
 		paginationAndSorting models.PaginationAndSorting, filters models.DecisionFilters) ([]models.DecisionWithRank, error)
 	UpdateDecisionCaseId(ctx context.Context, exec Executor, decisionsIds []string, caseId string) error
+	ReviewDecision(ctx context.Context, exec Executor, decisionId string, review models.DecisionReview) error
+	LabelDecisions(ctx context.Context, exec Executor, decisionIds []string,
+		review models.DecisionReview) ([]string, error)
 }
 
 type DecisionRepositoryImpl struct{}
@@ -305,6 +308,15 @@ func applyDecisionFilters(query squirrel.SelectBuilder, filters models.DecisionF
 	if filters.PivotValue != nil {
 		query = query.Where(squirrel.Eq{"pivot_value": *filters.PivotValue})
 	}
+	if len(filters.ReviewDispositions) > 0 {
+		query = query.Where(squirrel.Eq{"review_disposition": filters.ReviewDispositions})
+	}
+	if filters.IsReviewed != nil && *filters.IsReviewed {
+		query = query.Where(squirrel.NotEq{"review_disposition": nil})
+	}
+	if filters.IsReviewed != nil && !*filters.IsReviewed {
+		query = query.Where(squirrel.Eq{"review_disposition": nil})
+	}
 	return query
 }
 
@@ -448,6 +460,7 @@ func (repo *DecisionRepositoryImpl) StoreDecision(
 				"trigger_object",
 				"trigger_object_type",
 				"scheduled_execution_id",
+				"output_values",
 			).
 			Values(
 				newDecisionId,
@@ -465,6 +478,7 @@ func (repo *DecisionRepositoryImpl) StoreDecision(
 				decision.ClientObject.Data,
 				decision.ClientObject.TableName,
 				decision.ScheduledExecutionId,
+				decision.OutputValues,
 			),
 	)
 	if err != nil {
@@ -717,3 +731,56 @@ func ChanToSlice[Model any](channel <-chan Model) []Model {
 	}
 	return slice
 }
+
+func (repo *DecisionRepositoryImpl) ReviewDecision(
+	ctx context.Context,
+	exec Executor,
+	decisionId string,
+	review models.DecisionReview,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_DECISIONS).
+		Set("review_disposition", review.Disposition).
+		Set("reviewed_by", review.ReviewedBy).
+		Set("reviewed_at", review.ReviewedAt).
+		Set("review_note", review.Note).
+		Where(squirrel.Eq{"id": decisionId})
+
+	return ExecBuilder(ctx, exec, query)
+}
+
+// LabelDecisions records an automatic review on the decisions that were not reviewed by a user, so that it never
+// overwrites the review of a user. It returns the ids of the decisions the review was recorded on.
+func (repo *DecisionRepositoryImpl) LabelDecisions(
+	ctx context.Context,
+	exec Executor,
+	decisionIds []string,
+	review models.DecisionReview,
+) ([]string, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfRow(ctx, exec, labelDecisionsQuery(decisionIds, review),
+		func(row pgx.CollectableRow) (string, error) {
+			var id string
+			err := row.Scan(&id)
+			return id, err
+		})
+}
+
+func labelDecisionsQuery(decisionIds []string, review models.DecisionReview) squirrel.UpdateBuilder {
+	return NewQueryBuilder().
+		Update(dbmodels.TABLE_DECISIONS).
+		Set("review_disposition", review.Disposition).
+		Set("reviewed_by", review.ReviewedBy).
+		Set("reviewed_at", review.ReviewedAt).
+		Set("review_note", review.Note).
+		Where(squirrel.Eq{"id": decisionIds}).
+		Where(squirrel.Eq{"reviewed_by": nil}).
+		Suffix("RETURNING id")
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+// GetIdempotencyKey returns the idempotency key if it exists and has not expired, nil otherwise
+func (repo *MarbleDbRepository) GetIdempotencyKey(
+	ctx context.Context,
+	exec Executor,
+	organizationId string,
+	scope models.IdempotencyKeyScope,
+	key string,
+) (*models.IdempotencyKey, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToOptionalModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.IdempotencyKeyFields...).
+			From(dbmodels.TABLE_DECISION_IDEMPOTENCY_KEYS).
+			Where(squirrel.Eq{
+				"org_id":          organizationId,
+				"scope":           scope,
+				"idempotency_key": key,
+			}).
+			Where(squirrel.Expr("expires_at > NOW()")),
+		dbmodels.AdaptIdempotencyKey,
+	)
+}
+
+// CreateIdempotencyKey stores an idempotency key, replacing it if it has expired. Returns false if a key that has not
+// expired already exists, in which case nothing is stored.
+func (repo *MarbleDbRepository) CreateIdempotencyKey(
+	ctx context.Context,
+	exec Executor,
+	input models.IdempotencyKeyCreate,
+) (bool, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return false, err
+	}
+
+	decisionIds := input.DecisionIds
+	if decisionIds == nil {
+		decisionIds = []string{}
+	}
+
+	query := NewQueryBuilder().
+		Insert(dbmodels.TABLE_DECISION_IDEMPOTENCY_KEYS).
+		Columns(
+			"org_id",
+			"scope",
+			"idempotency_key",
+			"request_hash",
+			"decision_ids",
+			"nb_skipped",
+			"expires_at",
+		).
+		Values(
+			input.OrganizationId,
+			input.Scope,
+			input.Key,
+			input.RequestHash,
+			decisionIds,
+			input.NbSkipped,
+			input.ExpiresAt,
+		).
+		Suffix(fmt.Sprintf(`ON CONFLICT (org_id, scope, idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			decision_ids = EXCLUDED.decision_ids,
+			nb_skipped = EXCLUDED.nb_skipped,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+			WHERE %s.expires_at <= NOW()
+			RETURNING idempotency_key`, dbmodels.TABLE_DECISION_IDEMPOTENCY_KEYS))
+
+	created, err := SqlToOptionalRow(ctx, exec, query, func(row pgx.CollectableRow) (string, error) {
+		var key string
+		err := row.Scan(&key)
+		return key, err
+	})
+	if err != nil {
+		return false, err
+	}
+	return created != nil, nil
+}
+
+func (repo *MarbleDbRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, exec Executor) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_DECISION_IDEMPOTENCY_KEYS).
+			Where(squirrel.Expr("expires_at <= NOW()")),
+	)
+}
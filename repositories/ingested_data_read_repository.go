@@ -14,7 +14,13 @@ import (
 
 type IngestedDataReadRepository interface {
 	GetDbField(ctx context.Context, exec Executor, readParams models.DbFieldReadParams) (any, error)
-	ListAllObjectsFromTable(ctx context.Context, exec Executor, table models.Table) ([]models.ClientObject, error)
+	ListObjectsFromTableBatch(
+		ctx context.Context,
+		exec Executor,
+		table models.Table,
+		afterObjectId *string,
+		limit int,
+	) ([]models.ClientObject, error)
 	QueryIngestedObject(
 		ctx context.Context,
 		exec Executor,
@@ -190,25 +196,33 @@ func rowIsValid(tableName string) squirrel.Eq {
 	return squirrel.Eq{fmt.Sprintf("%s.valid_until", tableName): "Infinity"}
 }
 
-// "list all fields" methods
-func (repo *IngestedDataReadRepositoryImpl) ListAllObjectsFromTable(
+// ListObjectsFromTableBatch returns at most "limit" valid objects of the table, ordered by object_id.
+// Pass the object_id of the last object of the previous batch as "afterObjectId" to read the next batch.
+func (repo *IngestedDataReadRepositoryImpl) ListObjectsFromTableBatch(
 	ctx context.Context,
 	exec Executor,
 	table models.Table,
+	afterObjectId *string,
+	limit int,
 ) ([]models.ClientObject, error) {
 	if err := validateClientDbExecutor(exec); err != nil {
 		return nil, err
 	}
 
 	columnNames := models.ColumnNames(table)
+	qualifiedTableName := tableNameWithSchema(exec, table.Name)
 
-	objectsAsMap, err := queryWithDynamicColumnList(
-		ctx,
-		exec,
-		tableNameWithSchema(exec, table.Name),
-		columnNames,
-		nil,
-	)
+	query := NewQueryBuilder().
+		Select(columnNames...).
+		From(qualifiedTableName).
+		Where(rowIsValid(qualifiedTableName)).
+		OrderBy(fmt.Sprintf("%s.object_id", qualifiedTableName)).
+		Limit(uint64(limit))
+	if afterObjectId != nil {
+		query = query.Where(squirrel.Gt{fmt.Sprintf("%s.object_id", qualifiedTableName): *afterObjectId})
+	}
+
+	objectsAsMap, err := queryDynamicColumns(ctx, exec, query, columnNames)
 	if err != nil {
 		return nil, err
 	}
@@ -266,6 +280,15 @@ func queryWithDynamicColumnList(
 		q = q.Where(squirrel.Eq{fmt.Sprintf("%s.object_id", qualifiedTableName): *objectId})
 	}
 
+	return queryDynamicColumns(ctx, exec, q, columnNames)
+}
+
+func queryDynamicColumns(
+	ctx context.Context,
+	exec Executor,
+	q squirrel.SelectBuilder,
+	columnNames []string,
+) ([]map[string]any, error) {
 	sql, args, err := q.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("error while building SQL query: %w", err)
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+// ErrJobLeaseLost is returned when a worker tries to update a job it does not hold the lease of anymore
+var ErrJobLeaseLost = errors.New("the lease on the job has been lost")
+
+// EnqueueJob adds a job to the queue. It does nothing if an active (pending or running) job already exists
+// for the same entity.
+func (repo *MarbleDbRepository) EnqueueJob(ctx context.Context, exec Executor, input models.JobEnqueueInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	runAt := time.Now()
+	if input.RunAt != nil {
+		runAt = *input.RunAt
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_JOB_QUEUE).
+			Columns("kind", "entity_id", "status", "run_at").
+			Values(input.Kind, input.EntityId, models.JobPending, runAt).
+			Suffix("ON CONFLICT (kind, entity_id) WHERE status IN ('pending', 'running') DO NOTHING"),
+	)
+}
+
+// ClaimJobs takes a lease on at most input.Limit jobs of the given kind that are due, or whose lease has expired.
+// Jobs locked by a concurrent claim are skipped, so that several workers can claim jobs at the same time.
+func (repo *MarbleDbRepository) ClaimJobs(ctx context.Context, exec Executor, input models.JobClaimInput) ([]models.Job, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	// the subquery must keep the default "?" placeholders: they are numbered when the outer query is built
+	claimable := squirrel.
+		Select("id").
+		From(dbmodels.TABLE_JOB_QUEUE).
+		Where(squirrel.Eq{"kind": input.Kind}).
+		Where(squirrel.Or{
+			squirrel.And{
+				squirrel.Eq{"status": models.JobPending},
+				squirrel.Expr("run_at <= NOW()"),
+			},
+			squirrel.And{
+				squirrel.Eq{"status": models.JobRunning},
+				squirrel.Expr("lease_expires_at < NOW()"),
+			},
+		}).
+		OrderBy("run_at").
+		Limit(uint64(input.Limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_JOB_QUEUE).
+		Set("status", models.JobRunning).
+		Set("locked_by", input.WorkerId).
+		Set("lease_expires_at", squirrel.Expr("NOW() + make_interval(secs => ?)", input.LeaseDuration.Seconds())).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Expr("id IN (?)", claimable)).
+		Suffix(fmt.Sprintf("RETURNING %s", strings.Join(dbmodels.JobFields, ",")))
+
+	return SqlToListOfModels(ctx, exec, query, dbmodels.AdaptJob)
+}
+
+// HeartbeatJob extends the lease of a running job held by the worker
+func (repo *MarbleDbRepository) HeartbeatJob(
+	ctx context.Context,
+	exec Executor,
+	jobId string,
+	workerId string,
+	leaseDuration time.Duration,
+) error {
+	return repo.updateLeasedJob(ctx, exec, jobId, workerId,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_JOB_QUEUE).
+			Set("lease_expires_at", squirrel.Expr("NOW() + make_interval(secs => ?)", leaseDuration.Seconds())).
+			Set("updated_at", squirrel.Expr("NOW()")),
+	)
+}
+
+// CompleteJob marks a running job held by the worker as successful
+func (repo *MarbleDbRepository) CompleteJob(ctx context.Context, exec Executor, jobId string, workerId string) error {
+	return repo.updateLeasedJob(ctx, exec, jobId, workerId,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_JOB_QUEUE).
+			Set("status", models.JobSuccess).
+			Set("locked_by", nil).
+			Set("lease_expires_at", nil).
+			Set("updated_at", squirrel.Expr("NOW()")),
+	)
+}
+
+// FailJob releases a running job held by the worker after an error. If retryAt is not nil, the job is put back in the
+// queue to be retried at this time, otherwise it is marked as failed.
+func (repo *MarbleDbRepository) FailJob(
+	ctx context.Context,
+	exec Executor,
+	jobId string,
+	workerId string,
+	jobError string,
+	retryAt *time.Time,
+) error {
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_JOB_QUEUE).
+		Set("locked_by", nil).
+		Set("lease_expires_at", nil).
+		Set("last_error", jobError).
+		Set("updated_at", squirrel.Expr("NOW()"))
+	if retryAt != nil {
+		query = query.Set("status", models.JobPending).Set("run_at", *retryAt)
+	} else {
+		query = query.Set("status", models.JobFailure)
+	}
+	return repo.updateLeasedJob(ctx, exec, jobId, workerId, query)
+}
+
+// PostponeJob releases a running job held by the worker and puts it back in the queue, to be run at runAt. The current
+// attempt is not counted in the attempts of the job.
+func (repo *MarbleDbRepository) PostponeJob(
+	ctx context.Context,
+	exec Executor,
+	jobId string,
+	workerId string,
+	runAt time.Time,
+) error {
+	return repo.updateLeasedJob(ctx, exec, jobId, workerId,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_JOB_QUEUE).
+			Set("status", models.JobPending).
+			Set("run_at", runAt).
+			Set("attempts", squirrel.Expr("GREATEST(attempts - 1, 0)")).
+			Set("locked_by", nil).
+			Set("lease_expires_at", nil).
+			Set("updated_at", squirrel.Expr("NOW()")),
+	)
+}
+
+func (repo *MarbleDbRepository) updateLeasedJob(
+	ctx context.Context,
+	exec Executor,
+	jobId string,
+	workerId string,
+	query squirrel.UpdateBuilder,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	sql, args, err := query.
+		Where(squirrel.Eq{"id": jobId}).
+		Where(squirrel.Eq{"locked_by": workerId}).
+		Where(squirrel.Eq{"status": models.JobRunning}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "can't build sql query")
+	}
+
+	tag, err := exec.Exec(ctx, sql, args...)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error executing sql query: %s", sql))
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.Wrapf(ErrJobLeaseLost, "job %s", jobId)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE scheduled_executions
+ADD COLUMN number_of_evaluated_objects INT NOT NULL DEFAULT 0,
+ADD COLUMN last_processed_object_id VARCHAR,
+ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+CREATE INDEX scheduled_executions_status_idx ON scheduled_executions (status)
+WHERE status IN ('pending', 'processing');
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX scheduled_executions_status_idx;
+
+ALTER TABLE scheduled_executions
+DROP COLUMN number_of_evaluated_objects,
+DROP COLUMN last_processed_object_id,
+DROP COLUMN updated_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE job_queue (
+      id uuid DEFAULT uuid_generate_v4 (),
+      kind VARCHAR NOT NULL,
+      entity_id uuid NOT NULL,
+      status VARCHAR NOT NULL DEFAULT 'pending',
+      attempts INT NOT NULL DEFAULT 0,
+      run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      locked_by VARCHAR,
+      lease_expires_at TIMESTAMP WITH TIME ZONE,
+      last_error VARCHAR,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      PRIMARY KEY (id)
+);
+
+-- at most one active job per entity
+CREATE UNIQUE INDEX job_queue_active_entity_idx ON job_queue (kind, entity_id)
+WHERE status IN ('pending', 'running');
+
+CREATE INDEX job_queue_claim_idx ON job_queue (kind, run_at)
+WHERE status IN ('pending', 'running');
+
+-- at most one active scheduled execution per scenario, even with several scheduler instances.
+-- Existing duplicates are cancelled first, keeping the execution already processing or else the oldest one.
+UPDATE scheduled_executions
+SET status = 'cancelled', finished_at = NOW()
+WHERE id IN (
+      SELECT id FROM (
+            SELECT id, ROW_NUMBER() OVER (
+                  PARTITION BY scenario_id
+                  ORDER BY (status = 'processing') DESC, started_at
+            ) AS position
+            FROM scheduled_executions
+            WHERE status IN ('pending', 'processing')
+      ) AS active_executions
+      WHERE position > 1
+);
+
+CREATE UNIQUE INDEX scheduled_executions_active_scenario_idx ON scheduled_executions (scenario_id)
+WHERE status IN ('pending', 'processing');
+
+INSERT INTO job_queue (kind, entity_id)
+SELECT 'scheduled_execution', id FROM scheduled_executions WHERE status IN ('pending', 'processing');
+
+INSERT INTO job_queue (kind, entity_id)
+SELECT 'csv_ingestion', id FROM upload_logs WHERE status IN ('pending', 'processing');
+
+INSERT INTO job_queue (kind, entity_id)
+SELECT 'webhook_event', id FROM webhook_events WHERE delivery_status IN ('scheduled', 'retry');
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX scheduled_executions_active_scenario_idx;
+
+DROP TABLE job_queue;
+
+-- +goose StatementEnd
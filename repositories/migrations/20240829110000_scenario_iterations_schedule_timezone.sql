@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE scenario_iterations
+ADD COLUMN schedule_timezone VARCHAR NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scenario_iterations
+DROP COLUMN schedule_timezone;
+
+-- +goose StatementEnd
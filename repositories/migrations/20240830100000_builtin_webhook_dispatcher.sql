@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhooks (
+      id uuid DEFAULT uuid_generate_v4 (),
+      organization_id uuid NOT NULL,
+      partner_id uuid,
+      url VARCHAR NOT NULL,
+      event_types VARCHAR[] NOT NULL DEFAULT '{}',
+      http_timeout INT,
+      rate_limit INT,
+      rate_limit_duration INT,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      deleted_at TIMESTAMP WITH TIME ZONE,
+      PRIMARY KEY (id),
+      CONSTRAINT fk_webhooks_org FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+      CONSTRAINT fk_webhooks_partner FOREIGN KEY (partner_id) REFERENCES partners (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhooks_owner_idx ON webhooks (organization_id, partner_id)
+WHERE deleted_at IS NULL;
+
+CREATE TABLE webhook_secrets (
+      id uuid DEFAULT uuid_generate_v4 (),
+      webhook_id uuid NOT NULL,
+      value VARCHAR NOT NULL,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      expires_at TIMESTAMP WITH TIME ZONE,
+      deleted_at TIMESTAMP WITH TIME ZONE,
+      PRIMARY KEY (id),
+      CONSTRAINT fk_webhook_secrets_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_secrets_webhook_idx ON webhook_secrets (webhook_id);
+
+CREATE TABLE webhook_deliveries (
+      id uuid DEFAULT uuid_generate_v4 (),
+      webhook_event_id uuid NOT NULL,
+      webhook_id uuid NOT NULL,
+      status VARCHAR NOT NULL DEFAULT 'scheduled',
+      attempts INT NOT NULL DEFAULT 0,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      PRIMARY KEY (id),
+      CONSTRAINT fk_webhook_deliveries_event FOREIGN KEY (webhook_event_id) REFERENCES webhook_events (id) ON DELETE CASCADE,
+      CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX webhook_deliveries_event_webhook_idx ON webhook_deliveries (webhook_event_id, webhook_id);
+
+CREATE TABLE webhook_delivery_attempts (
+      id uuid DEFAULT uuid_generate_v4 (),
+      delivery_id uuid NOT NULL,
+      webhook_id uuid NOT NULL,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      status_code INT,
+      latency_ms INT NOT NULL,
+      response_excerpt VARCHAR NOT NULL DEFAULT '',
+      error VARCHAR NOT NULL DEFAULT '',
+      PRIMARY KEY (id),
+      CONSTRAINT fk_webhook_delivery_attempts_delivery FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_idx ON webhook_delivery_attempts (delivery_id, created_at);
+
+-- used to enforce the rate limit of the endpoints
+CREATE INDEX webhook_delivery_attempts_webhook_idx ON webhook_delivery_attempts (webhook_id, created_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE webhook_delivery_attempts;
+
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhook_secrets;
+
+DROP TABLE webhooks;
+
+-- +goose StatementEnd
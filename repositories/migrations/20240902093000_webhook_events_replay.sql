@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_events
+ADD COLUMN replay_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX webhook_events_org_created_at_idx ON webhook_events (organization_id, created_at DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX webhook_events_org_created_at_idx;
+
+ALTER TABLE webhook_events
+DROP COLUMN replay_count;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE decision_idempotency_keys (
+      org_id uuid NOT NULL,
+      scope VARCHAR NOT NULL,
+      idempotency_key VARCHAR NOT NULL,
+      request_hash VARCHAR NOT NULL,
+      decision_ids uuid[] NOT NULL DEFAULT '{}',
+      nb_skipped INT NOT NULL DEFAULT 0,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+      PRIMARY KEY (org_id, scope, idempotency_key),
+      CONSTRAINT fk_decision_idempotency_keys_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX decision_idempotency_keys_expires_at_idx ON decision_idempotency_keys (expires_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE decision_idempotency_keys;
+
+-- +goose StatementEnd
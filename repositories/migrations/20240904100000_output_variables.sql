@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE scenario_iterations
+ADD COLUMN output_variables JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE decisions
+ADD COLUMN output_values JSONB;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE decisions
+DROP COLUMN output_values;
+
+ALTER TABLE scenario_iterations
+DROP COLUMN output_variables;
+
+-- +goose StatementEnd
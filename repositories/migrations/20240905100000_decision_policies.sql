@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE decision_policies (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      trigger_object_type VARCHAR NOT NULL,
+      scenario_ids uuid[] NOT NULL,
+      stop_on_reject BOOLEAN NOT NULL DEFAULT false,
+      aggregation VARCHAR NOT NULL,
+      score_review_threshold INT,
+      score_reject_threshold INT,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_decision_policies_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX decision_policies_org_trigger_object_type_idx ON decision_policies (org_id, trigger_object_type);
+
+CREATE TABLE policy_decisions (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      decision_policy_id uuid,
+      trigger_object_type VARCHAR NOT NULL,
+      outcome VARCHAR NOT NULL,
+      score INT NOT NULL,
+      decision_ids uuid[] NOT NULL DEFAULT '{}',
+      skipped_scenario_ids uuid[] NOT NULL DEFAULT '{}',
+      stopped_early BOOLEAN NOT NULL DEFAULT false,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_policy_decisions_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE,
+      CONSTRAINT fk_policy_decisions_policy FOREIGN KEY (decision_policy_id) REFERENCES decision_policies (id) ON DELETE SET NULL
+);
+
+CREATE INDEX policy_decisions_org_created_at_idx ON policy_decisions (org_id, created_at DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE policy_decisions;
+
+DROP TABLE decision_policies;
+
+-- +goose StatementEnd
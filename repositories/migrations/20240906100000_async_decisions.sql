@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE async_decisions (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      scenario_id uuid NOT NULL,
+      trigger_object_type VARCHAR NOT NULL,
+      payload JSONB NOT NULL,
+      status VARCHAR NOT NULL DEFAULT 'pending',
+      error TEXT,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_async_decisions_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX async_decisions_org_created_at_idx ON async_decisions (org_id, created_at DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE async_decisions;
+
+-- +goose StatementEnd
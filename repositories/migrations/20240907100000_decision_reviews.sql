@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE decisions
+ADD COLUMN review_disposition VARCHAR,
+ADD COLUMN reviewed_by uuid,
+ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN review_note TEXT;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE decisions
+DROP COLUMN review_disposition,
+DROP COLUMN reviewed_by,
+DROP COLUMN reviewed_at,
+DROP COLUMN review_note;
+
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sso_providers (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      issuer_url VARCHAR NOT NULL,
+      client_id VARCHAR NOT NULL,
+      client_secret VARCHAR NOT NULL DEFAULT '',
+      scopes VARCHAR[] NOT NULL DEFAULT '{}',
+      role_claim VARCHAR NOT NULL DEFAULT '',
+      role_mappings JSONB NOT NULL DEFAULT '{}',
+      default_role INTEGER NOT NULL DEFAULT 0,
+      jit_provisioning BOOLEAN NOT NULL DEFAULT false,
+      enabled BOOLEAN NOT NULL DEFAULT true,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_sso_providers_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX sso_providers_org_id_idx ON sso_providers (org_id);
+
+CREATE TABLE sso_login_states (
+      state VARCHAR PRIMARY KEY,
+      org_id uuid NOT NULL,
+      code_verifier VARCHAR NOT NULL,
+      nonce VARCHAR NOT NULL,
+      redirect_uri VARCHAR NOT NULL,
+      expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+      CONSTRAINT fk_sso_login_states_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX sso_login_states_expires_at_idx ON sso_login_states (expires_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sso_login_states;
+
+DROP TABLE sso_providers;
+
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE scim_tokens (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      description VARCHAR NOT NULL DEFAULT '',
+      prefix VARCHAR NOT NULL,
+      token_hash BYTEA NOT NULL,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      deleted_at TIMESTAMP WITH TIME ZONE,
+      CONSTRAINT fk_scim_tokens_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX scim_tokens_token_hash_idx ON scim_tokens (token_hash) WHERE deleted_at IS NULL;
+
+CREATE TABLE scim_groups (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      display_name VARCHAR NOT NULL,
+      external_id VARCHAR NOT NULL DEFAULT '',
+      role INTEGER NOT NULL DEFAULT 0,
+      inbox_id uuid,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_scim_groups_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE,
+      CONSTRAINT fk_scim_groups_inbox FOREIGN KEY (inbox_id) REFERENCES inboxes (id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX scim_groups_org_id_display_name_idx ON scim_groups (org_id, display_name);
+
+CREATE TABLE scim_group_members (
+      group_id uuid NOT NULL,
+      user_id uuid NOT NULL,
+      PRIMARY KEY (group_id, user_id),
+      CONSTRAINT fk_scim_group_members_group FOREIGN KEY (group_id) REFERENCES scim_groups (id) ON DELETE CASCADE,
+      CONSTRAINT fk_scim_group_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX scim_group_members_user_id_idx ON scim_group_members (user_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE scim_group_members;
+
+DROP TABLE scim_groups;
+
+DROP TABLE scim_tokens;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE custom_roles (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      name VARCHAR NOT NULL,
+      description VARCHAR NOT NULL DEFAULT '',
+      permissions VARCHAR[] NOT NULL DEFAULT '{}',
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_custom_roles_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX custom_roles_org_id_name_idx ON custom_roles (org_id, name);
+
+ALTER TABLE users ADD COLUMN custom_role_id uuid REFERENCES custom_roles (id);
+
+ALTER TABLE api_keys ADD COLUMN custom_role_id uuid REFERENCES custom_roles (id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN custom_role_id;
+
+ALTER TABLE users DROP COLUMN custom_role_id;
+
+DROP TABLE custom_roles;
+
+-- +goose StatementEnd
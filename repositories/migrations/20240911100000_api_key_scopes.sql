@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE api_keys
+      ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE,
+      ADD COLUMN scenario_ids VARCHAR[] NOT NULL DEFAULT '{}',
+      ADD COLUMN trigger_object_types VARCHAR[] NOT NULL DEFAULT '{}',
+      ADD COLUMN ingestion_tables VARCHAR[] NOT NULL DEFAULT '{}',
+      ADD COLUMN allowed_networks VARCHAR[] NOT NULL DEFAULT '{}',
+      ADD COLUMN rotated_from_id uuid REFERENCES api_keys (id),
+      ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE,
+      ADD COLUMN request_count BIGINT NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys
+      DROP COLUMN expires_at,
+      DROP COLUMN scenario_ids,
+      DROP COLUMN trigger_object_types,
+      DROP COLUMN ingestion_tables,
+      DROP COLUMN allowed_networks,
+      DROP COLUMN rotated_from_id,
+      DROP COLUMN last_used_at,
+      DROP COLUMN request_count;
+
+-- +goose StatementEnd
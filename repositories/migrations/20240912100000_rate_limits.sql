@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rate_limits (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      org_id uuid NOT NULL,
+      api_key_id uuid,
+      requests_per_second DOUBLE PRECISION NOT NULL DEFAULT 0,
+      burst INT NOT NULL DEFAULT 0,
+      monthly_quota BIGINT NOT NULL DEFAULT 0,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      CONSTRAINT fk_rate_limits_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE,
+      CONSTRAINT fk_rate_limits_api_key FOREIGN KEY (api_key_id) REFERENCES api_keys (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX rate_limits_org_id_idx ON rate_limits (org_id)
+WHERE
+      api_key_id IS NULL;
+
+CREATE UNIQUE INDEX rate_limits_api_key_id_idx ON rate_limits (api_key_id)
+WHERE
+      api_key_id IS NOT NULL;
+
+CREATE TABLE rate_limit_counters (
+      subject VARCHAR NOT NULL,
+      period VARCHAR NOT NULL,
+      count BIGINT NOT NULL DEFAULT 0,
+      PRIMARY KEY (subject, period)
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rate_limit_counters;
+
+DROP TABLE rate_limits;
+
+-- +goose StatementEnd
@@ -0,0 +1,145 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit.audit_events
+ADD COLUMN api_key_id TEXT,
+ADD COLUMN org_id UUID,
+ADD COLUMN previous_data JSONB;
+
+CREATE INDEX audit_events_org_id_idx ON audit.audit_events (org_id, created_at DESC, id DESC);
+
+CREATE INDEX audit_events_entity_idx ON audit.audit_events ("table", entity_id);
+
+-- The arguments of the trigger are the columns which are not audited, either because they are secret or because they
+-- change on every use of the row. The actor and their organization are set in the transaction of the write from the
+-- credentials of the request, the organization of the row is used when it has one.
+CREATE
+OR REPLACE FUNCTION global_audit () RETURNS TRIGGER AS $$
+    DECLARE
+        excluded_columns TEXT[] := COALESCE(TG_ARGV, '{}');
+        old_data JSONB;
+        new_data JSONB;
+        row_data JSONB;
+    BEGIN
+        IF (TG_OP <> 'INSERT') THEN
+            old_data := to_jsonb(OLD) - excluded_columns;
+        END IF;
+        IF (TG_OP <> 'DELETE') THEN
+            new_data := to_jsonb(NEW) - excluded_columns;
+        END IF;
+        IF (TG_OP = 'UPDATE' AND old_data = new_data) THEN
+            RETURN NULL;
+        END IF;
+        row_data := COALESCE(new_data, old_data);
+
+        INSERT INTO audit.audit_events ("operation", "user_id", "api_key_id", "org_id", "table", "entity_id", "data", "previous_data", "created_at")
+        VALUES (
+            TG_OP::audit_operation,
+            NULLIF(current_setting('custom.current_user_id', TRUE), ''),
+            NULLIF(current_setting('custom.current_api_key_id', TRUE), ''),
+            COALESCE(row_data->>'org_id', row_data->>'organization_id', NULLIF(current_setting('custom.current_org_id', TRUE), ''))::UUID,
+            TG_TABLE_NAME,
+            (row_data->>'id')::UUID,
+            row_data,
+            CASE WHEN TG_OP = 'UPDATE' THEN old_data END,
+            now()
+        );
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON scenarios FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON scenario_iterations FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON scenario_iteration_rules FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON scenario_publications FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON users FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON custom_roles FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON api_keys FOR EACH ROW EXECUTE FUNCTION global_audit ('key_hash', 'last_used_at', 'request_count');
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON inboxes FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON inbox_users FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON data_model_tables FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON data_model_fields FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON data_model_links FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON data_model_pivots FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON snooze_groups FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON rule_snoozes FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+CREATE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON webhooks FOR EACH ROW EXECUTE FUNCTION global_audit ();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER audit ON scenarios;
+
+DROP TRIGGER audit ON scenario_iterations;
+
+DROP TRIGGER audit ON scenario_iteration_rules;
+
+DROP TRIGGER audit ON scenario_publications;
+
+DROP TRIGGER audit ON users;
+
+DROP TRIGGER audit ON custom_roles;
+
+DROP TRIGGER audit ON api_keys;
+
+DROP TRIGGER audit ON inboxes;
+
+DROP TRIGGER audit ON inbox_users;
+
+DROP TRIGGER audit ON data_model_tables;
+
+DROP TRIGGER audit ON data_model_fields;
+
+DROP TRIGGER audit ON data_model_links;
+
+DROP TRIGGER audit ON data_model_pivots;
+
+DROP TRIGGER audit ON snooze_groups;
+
+DROP TRIGGER audit ON rule_snoozes;
+
+DROP TRIGGER audit ON webhooks;
+
+CREATE
+OR REPLACE FUNCTION global_audit () RETURNS TRIGGER AS $$
+    BEGIN
+        IF (TG_OP = 'DELETE') THEN
+            INSERT INTO audit.audit_events ("operation", "user_id", "table", "entity_id", "data", "created_at")
+            VALUES ('DELETE', current_setting('custom.current_user_id', TRUE), TG_TABLE_NAME, OLD.id, to_jsonb(OLD), now());
+
+        ELSIF (TG_OP = 'UPDATE') THEN
+            INSERT INTO audit.audit_events ("operation", "user_id", "table", "entity_id", "data", "created_at")
+            VALUES ('UPDATE', current_setting('custom.current_user_id', TRUE), TG_TABLE_NAME, NEW.id, to_jsonb(NEW), now());
+
+        ELSIF (TG_OP = 'INSERT') THEN
+            INSERT INTO audit.audit_events ("operation", "user_id", "table", "entity_id", "data", "created_at")
+            VALUES ('INSERT', current_setting('custom.current_user_id', TRUE), TG_TABLE_NAME, NEW.id, to_jsonb(NEW), now());
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX audit.audit_events_entity_idx;
+
+DROP INDEX audit.audit_events_org_id_idx;
+
+ALTER TABLE audit.audit_events
+DROP COLUMN api_key_id,
+DROP COLUMN org_id,
+DROP COLUMN previous_data;
+
+-- +goose StatementEnd
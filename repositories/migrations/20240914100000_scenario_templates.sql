@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE scenario_templates (
+      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+      name VARCHAR NOT NULL,
+      description VARCHAR NOT NULL DEFAULT '',
+      placeholders JSONB NOT NULL DEFAULT '[]',
+      bundle JSONB NOT NULL,
+      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX scenario_templates_name_idx ON scenario_templates (name);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE scenario_templates;
+
+-- +goose StatementEnd
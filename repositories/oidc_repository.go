@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	"github.com/cockroachdb/errors"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+// Discovery documents are fetched again after this delay, signing keys are refreshed by keyfunc
+const oidcDiscoveryExpiration = time.Hour
+
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type oidcIssuer struct {
+	discovery oidcDiscovery
+	jwks      *keyfunc.JWKS
+	expiresAt time.Time
+}
+
+// oidcIssuerEntry is the cache entry of an issuer. Its lock is held while the issuer is fetched, so that a slow
+// identity provider only blocks the logins of its own organizations.
+type oidcIssuerEntry struct {
+	mu     sync.Mutex
+	issuer *oidcIssuer
+}
+
+// OidcRepository implements the OpenID Connect authorization code flow with PKCE against the identity providers
+// of the organizations. Discovery documents and signing keys are cached by issuer.
+type OidcRepository struct {
+	httpClient *http.Client
+	mu         sync.Mutex
+	issuers    map[string]*oidcIssuerEntry
+}
+
+func NewOidcRepository() *OidcRepository {
+	return &OidcRepository{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		issuers:    make(map[string]*oidcIssuerEntry),
+	}
+}
+
+func (repo *OidcRepository) getIssuer(ctx context.Context, issuerUrl string) (*oidcIssuer, error) {
+	repo.mu.Lock()
+	entry, ok := repo.issuers[issuerUrl]
+	if !ok {
+		entry = &oidcIssuerEntry{}
+		repo.issuers[issuerUrl] = entry
+	}
+	repo.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	cached := entry.issuer
+	if cached != nil && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	discoveryUrl := strings.TrimSuffix(issuerUrl, "/") + "/.well-known/openid-configuration"
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := repo.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch the OpenID configuration of %s", issuerUrl)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Newf("failed to fetch the OpenID configuration of %s: status %d", issuerUrl, response.StatusCode)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(response.Body).Decode(&discovery); err != nil {
+		return nil, errors.Wrapf(err, "invalid OpenID configuration for %s", issuerUrl)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksUri == "" {
+		return nil, errors.Newf("incomplete OpenID configuration for %s", issuerUrl)
+	}
+	// the ID tokens are verified against the issuer of the configuration, which must be the configured issuer
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuerUrl, "/") {
+		return nil, errors.Newf("the OpenID configuration of %s is for another issuer: %s", issuerUrl, discovery.Issuer)
+	}
+
+	var jwks *keyfunc.JWKS
+	if cached != nil && cached.discovery.JwksUri == discovery.JwksUri {
+		jwks = cached.jwks
+	} else {
+		if cached != nil {
+			cached.jwks.EndBackground()
+		}
+		jwks, err = keyfunc.Get(discovery.JwksUri, keyfunc.Options{
+			Client:            repo.httpClient,
+			RefreshInterval:   oidcDiscoveryExpiration,
+			RefreshRateLimit:  5 * time.Minute,
+			RefreshUnknownKID: true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch the signing keys of %s", issuerUrl)
+		}
+	}
+
+	issuer := &oidcIssuer{
+		discovery: discovery,
+		jwks:      jwks,
+		expiresAt: time.Now().Add(oidcDiscoveryExpiration),
+	}
+	entry.issuer = issuer
+	return issuer, nil
+}
+
+func oauth2Config(provider models.SsoProvider, discovery oidcDiscovery, redirectUri string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientId,
+		ClientSecret: provider.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: redirectUri,
+		Scopes:      provider.ScopesOrDefault(),
+	}
+}
+
+// AuthorizationUrl returns the url of the identity provider login page, to which the user is redirected
+func (repo *OidcRepository) AuthorizationUrl(
+	ctx context.Context,
+	provider models.SsoProvider,
+	state models.SsoLoginState,
+) (string, error) {
+	issuer, err := repo.getIssuer(ctx, provider.IssuerUrl)
+	if err != nil {
+		return "", err
+	}
+	return oauth2Config(provider, issuer.discovery, state.RedirectUri).AuthCodeURL(
+		state.State,
+		oauth2.S256ChallengeOption(state.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+	), nil
+}
+
+// ExchangeCode exchanges the authorization code returned by the identity provider for an ID token, and returns
+// the identity it holds once its signature, issuer, audience, expiration and nonce are verified.
+func (repo *OidcRepository) ExchangeCode(
+	ctx context.Context,
+	provider models.SsoProvider,
+	state models.SsoLoginState,
+	code string,
+) (models.SsoIdentity, error) {
+	issuer, err := repo.getIssuer(ctx, provider.IssuerUrl)
+	if err != nil {
+		return models.SsoIdentity{}, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, repo.httpClient)
+	token, err := oauth2Config(provider, issuer.discovery, state.RedirectUri).
+		Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		return models.SsoIdentity{}, errors.Wrap(models.UnAuthorizedError,
+			fmt.Sprintf("failed to exchange the authorization code: %s", err))
+	}
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.SsoIdentity{}, errors.Wrap(models.UnAuthorizedError, "the identity provider returned no ID token")
+	}
+
+	claims, err := verifyIdToken(rawIdToken, issuer, provider.ClientId, state.Nonce)
+	if err != nil {
+		return models.SsoIdentity{}, err
+	}
+	return models.NewSsoIdentity(claims)
+}
+
+func verifyIdToken(rawIdToken string, issuer *oidcIssuer, clientId, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIdToken, claims, issuer.jwks.Keyfunc, jwt.WithValidMethods(oidcSigningMethods))
+	if err != nil {
+		return nil, errors.Wrap(models.UnAuthorizedError, fmt.Sprintf("invalid ID token: %s", err))
+	}
+	if !claims.VerifyIssuer(issuer.discovery.Issuer, true) {
+		return nil, errors.Wrap(models.UnAuthorizedError, "invalid ID token issuer")
+	}
+	if !claims.VerifyAudience(clientId, true) {
+		return nil, errors.Wrap(models.UnAuthorizedError, "invalid ID token audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.Wrap(models.UnAuthorizedError, "the ID token has no expiration")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.Wrap(models.UnAuthorizedError, "invalid ID token nonce")
+	}
+	return claims, nil
+}
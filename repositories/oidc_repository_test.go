@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type fakeIdentityProvider struct {
+	server *httptest.Server
+	// issuer of the discovery document, the url of the server if empty
+	issuer       string
+	key          *rsa.PrivateKey
+	idTokenNonce string
+	verifier     string
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &fakeIdentityProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := idp.issuer
+		if issuer == "" {
+			issuer = idp.server.URL
+		}
+		_ = json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                issuer,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksUri:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("code") != "code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		idp.verifier = r.Form.Get("code_verifier")
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":    idp.server.URL,
+			"aud":    "marble",
+			"sub":    "123",
+			"exp":    time.Now().Add(time.Minute).Unix(),
+			"nonce":  idp.idTokenNonce,
+			"email":  "jane@example.com",
+			"groups": []string{"admins"},
+		})
+		token.Header["kid"] = "key"
+		idToken, _ := token.SignedString(key)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access_token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func TestOidcRepository(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	repo := NewOidcRepository()
+	ctx := context.Background()
+
+	provider := models.SsoProvider{IssuerUrl: idp.server.URL, ClientId: "marble", ClientSecret: "secret"}
+	state := models.SsoLoginState{
+		State:        "state",
+		CodeVerifier: "verifier-verifier-verifier-verifier-verifier",
+		Nonce:        "nonce",
+		RedirectUri:  "https://app.example.com/sso/callback",
+	}
+
+	t.Run("AuthorizationUrl", func(t *testing.T) {
+		authorizationUrl, err := repo.AuthorizationUrl(ctx, provider, state)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(authorizationUrl)
+		require.NoError(t, err)
+		query := parsed.Query()
+		assert.Equal(t, "/authorize", parsed.Path)
+		assert.Equal(t, "state", query.Get("state"))
+		assert.Equal(t, "nonce", query.Get("nonce"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.NotEmpty(t, query.Get("code_challenge"))
+		assert.Equal(t, state.RedirectUri, query.Get("redirect_uri"))
+	})
+
+	t.Run("ExchangeCode", func(t *testing.T) {
+		idp.idTokenNonce = "nonce"
+		identity, err := repo.ExchangeCode(ctx, provider, state, "code")
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com", identity.Email)
+		assert.Equal(t, state.CodeVerifier, idp.verifier)
+	})
+
+	t.Run("ExchangeCode with another nonce", func(t *testing.T) {
+		idp.idTokenNonce = "replayed"
+		_, err := repo.ExchangeCode(ctx, provider, state, "code")
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+	})
+
+	t.Run("ExchangeCode for another client", func(t *testing.T) {
+		idp.idTokenNonce = "nonce"
+		otherClient := provider
+		otherClient.ClientId = "other"
+		_, err := repo.ExchangeCode(ctx, otherClient, state, "code")
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+	})
+
+	t.Run("ExchangeCode with an invalid code", func(t *testing.T) {
+		_, err := repo.ExchangeCode(ctx, provider, state, "invalid")
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+	})
+}
+
+func TestOidcRepositoryWithAnotherIssuer(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	idp.issuer = "https://attacker.example.com"
+	repo := NewOidcRepository()
+
+	provider := models.SsoProvider{IssuerUrl: idp.server.URL, ClientId: "marble", ClientSecret: "secret"}
+	_, err := repo.AuthorizationUrl(context.Background(), provider, models.SsoLoginState{State: "state"})
+	assert.ErrorContains(t, err, "another issuer")
+}
+
+func TestOidcRepositoryLocksByIssuer(t *testing.T) {
+	blocked := make(chan struct{})
+	slowIdp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	t.Cleanup(slowIdp.Close)
+	t.Cleanup(func() { close(blocked) })
+	idp := newFakeIdentityProvider(t)
+	repo := NewOidcRepository()
+	ctx := context.Background()
+
+	go func() {
+		_, _ = repo.getIssuer(ctx, slowIdp.URL)
+	}()
+
+	// the issuers of the other organizations can be fetched while the slow issuer is being fetched
+	done := make(chan error)
+	go func() {
+		// let the slow fetch take its lock first
+		time.Sleep(50 * time.Millisecond)
+		_, err := repo.getIssuer(ctx, idp.server.URL)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetch of an issuer is blocked by the fetch of another issuer")
+	}
+}
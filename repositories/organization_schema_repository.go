@@ -15,6 +15,10 @@ type OrganizationSchemaRepository interface {
 	DeleteSchema(ctx context.Context, exec Executor) error
 	CreateTable(ctx context.Context, exec Executor, tableName string) error
 	CreateField(ctx context.Context, exec Executor, tableName string, field models.CreateFieldInput) error
+	DeleteTable(ctx context.Context, exec Executor, tableName string) error
+	ArchiveTable(ctx context.Context, exec Executor, tableName, archivedName string) error
+	DeleteField(ctx context.Context, exec Executor, tableName, fieldName string) error
+	ArchiveField(ctx context.Context, exec Executor, tableName, fieldName, archivedName string) error
 }
 
 type OrganizationSchemaRepositoryPostgresql struct{}
@@ -83,6 +87,102 @@ func (repo *OrganizationSchemaRepositoryPostgresql) CreateField(
 	return err
 }
 
+func (repo *OrganizationSchemaRepositoryPostgresql) DeleteTable(ctx context.Context, exec Executor, tableName string) error {
+	if err := validateClientDbExecutor(exec); err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("DROP TABLE IF EXISTS %s",
+		pgx.Identifier.Sanitize([]string{exec.DatabaseSchema().Schema, tableName}))
+	_, err := exec.Exec(ctx, sql)
+	return err
+}
+
+// ArchiveTable keeps the ingested data of a table removed from the data model under another name. Its indexes are
+// dropped, so that their names can be used again if a table of the same name is created.
+func (repo *OrganizationSchemaRepositoryPostgresql) ArchiveTable(ctx context.Context, exec Executor, tableName, archivedName string) error {
+	if err := validateClientDbExecutor(exec); err != nil {
+		return err
+	}
+
+	if err := dropIndexesOfColumn(ctx, exec, tableName, nil); err != nil {
+		return err
+	}
+	sql := fmt.Sprintf("ALTER TABLE IF EXISTS %s RENAME TO %s",
+		pgx.Identifier.Sanitize([]string{exec.DatabaseSchema().Schema, tableName}),
+		pgx.Identifier.Sanitize([]string{archivedName}))
+	_, err := exec.Exec(ctx, sql)
+	return err
+}
+
+func (repo *OrganizationSchemaRepositoryPostgresql) DeleteField(ctx context.Context, exec Executor, tableName, fieldName string) error {
+	if err := validateClientDbExecutor(exec); err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s",
+		pgx.Identifier.Sanitize([]string{exec.DatabaseSchema().Schema, tableName}),
+		pgx.Identifier.Sanitize([]string{fieldName}))
+	_, err := exec.Exec(ctx, sql)
+	return err
+}
+
+// ArchiveField keeps the ingested values of a field removed from the data model in a column of another name. The
+// column is made nullable since the ingestion no longer fills it, and its indexes are dropped.
+func (repo *OrganizationSchemaRepositoryPostgresql) ArchiveField(ctx context.Context, exec Executor,
+	tableName, fieldName, archivedName string,
+) error {
+	if err := validateClientDbExecutor(exec); err != nil {
+		return err
+	}
+
+	if err := dropIndexesOfColumn(ctx, exec, tableName, &fieldName); err != nil {
+		return err
+	}
+	sanitizedTableName := pgx.Identifier.Sanitize([]string{exec.DatabaseSchema().Schema, tableName})
+	sanitizedFieldName := pgx.Identifier.Sanitize([]string{fieldName})
+	sql := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL", sanitizedTableName, sanitizedFieldName)
+	if _, err := exec.Exec(ctx, sql); err != nil {
+		return err
+	}
+	sql = fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+		sanitizedTableName, sanitizedFieldName, pgx.Identifier.Sanitize([]string{archivedName}))
+	_, err := exec.Exec(ctx, sql)
+	return err
+}
+
+// dropIndexesOfColumn drops the indexes of the table, other than its primary key, which index or include the column,
+// or all of them if the column is nil
+func dropIndexesOfColumn(ctx context.Context, exec Executor, tableName string, columnName *string) error {
+	query := `
+		SELECT DISTINCT index_class.relname
+		FROM pg_index AS idx
+			JOIN pg_class AS index_class ON (index_class.oid = idx.indexrelid)
+			JOIN pg_class AS table_class ON (table_class.oid = idx.indrelid)
+			JOIN pg_namespace AS ns ON (ns.oid = table_class.relnamespace)
+			JOIN pg_attribute AS attr ON (attr.attrelid = table_class.oid AND attr.attnum = ANY(idx.indkey))
+		WHERE ns.nspname = $1
+			AND table_class.relname = $2
+			AND NOT idx.indisprimary
+			AND ($3::text IS NULL OR attr.attname = $3)`
+
+	rows, err := exec.Query(ctx, query, exec.DatabaseSchema().Schema, tableName, columnName)
+	if err != nil {
+		return err
+	}
+	indexNames, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	for _, indexName := range indexNames {
+		if _, err := exec.Exec(ctx, dropIdxSqlQuery(indexName, exec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func toPgType(dataType models.DataType) string {
 	switch dataType {
 	case models.Int:
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -13,7 +14,8 @@ import (
 
 func (db *Database) GetApiKeyByHash(ctx context.Context, hash []byte) (models.ApiKey, error) {
 	query := `
-		SELECT id, org_id, prefix, description, partner_id, role
+		SELECT id, org_id, prefix, description, partner_id, role, custom_role_id, expires_at,
+			scenario_ids, trigger_object_types, ingestion_tables, allowed_networks
 		FROM api_keys
 		WHERE key_hash = $1
 		AND deleted_at IS NULL
@@ -27,6 +29,12 @@ func (db *Database) GetApiKeyByHash(ctx context.Context, hash []byte) (models.Ap
 		&apiKey.Description,
 		&apiKey.PartnerId,
 		&apiKey.Role,
+		&apiKey.CustomRoleId,
+		&apiKey.ExpiresAt,
+		&apiKey.ScenarioIds,
+		&apiKey.TriggerObjectTypes,
+		&apiKey.IngestionTables,
+		&apiKey.AllowedNetworks,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return models.ApiKey{}, models.NotFoundError
@@ -36,3 +44,60 @@ func (db *Database) GetApiKeyByHash(ctx context.Context, hash []byte) (models.Ap
 	}
 	return dbmodels.AdaptApikey(apiKey)
 }
+
+func (db *Database) GetApiKeyById(ctx context.Context, apiKeyId string) (models.ApiKey, error) {
+	query := `
+		SELECT id, org_id, prefix, description, partner_id, role, custom_role_id, expires_at,
+			scenario_ids, trigger_object_types, ingestion_tables, allowed_networks
+		FROM api_keys
+		WHERE id = $1
+		AND deleted_at IS NULL
+	`
+
+	var apiKey dbmodels.DBApiKey
+	err := db.pool.QueryRow(ctx, query, apiKeyId).Scan(
+		&apiKey.Id,
+		&apiKey.OrganizationId,
+		&apiKey.Prefix,
+		&apiKey.Description,
+		&apiKey.PartnerId,
+		&apiKey.Role,
+		&apiKey.CustomRoleId,
+		&apiKey.ExpiresAt,
+		&apiKey.ScenarioIds,
+		&apiKey.TriggerObjectTypes,
+		&apiKey.IngestionTables,
+		&apiKey.AllowedNetworks,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ApiKey{}, models.NotFoundError
+	}
+	if err != nil {
+		return models.ApiKey{}, fmt.Errorf("pool.QueryRow error: %w", err)
+	}
+	return dbmodels.AdaptApikey(apiKey)
+}
+
+// RecordApiKeyUsages adds the requests authenticated by the keys since the last call, in a single statement
+func (db *Database) RecordApiKeyUsages(ctx context.Context, usages map[string]models.ApiKeyUsage) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = GREATEST(api_keys.last_used_at, usages.last_used_at),
+			request_count = api_keys.request_count + usages.request_count
+		FROM unnest($1::uuid[], $2::bigint[], $3::timestamptz[]) AS usages(id, request_count, last_used_at)
+		WHERE api_keys.id = usages.id
+	`
+
+	ids := make([]string, 0, len(usages))
+	requestCounts := make([]int64, 0, len(usages))
+	lastUsedAts := make([]time.Time, 0, len(usages))
+	for id, usage := range usages {
+		ids = append(ids, id)
+		requestCounts = append(requestCounts, usage.RequestCount)
+		lastUsedAts = append(lastUsedAts, usage.LastUsedAt)
+	}
+	if _, err := db.pool.Exec(ctx, query, ids, requestCounts, lastUsedAts); err != nil {
+		return fmt.Errorf("pool.Exec error: %w", err)
+	}
+	return nil
+}
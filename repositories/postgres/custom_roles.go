@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func (db *Database) GetCustomRoleById(ctx context.Context, customRoleId string) (models.CustomRole, error) {
+	query := `
+		SELECT id, org_id, name, description, permissions, created_at, updated_at
+		FROM custom_roles
+		WHERE id = $1
+	`
+
+	var customRole dbmodels.DBCustomRole
+	err := db.pool.QueryRow(ctx, query, customRoleId).Scan(
+		&customRole.Id,
+		&customRole.OrganizationId,
+		&customRole.Name,
+		&customRole.Description,
+		&customRole.Permissions,
+		&customRole.CreatedAt,
+		&customRole.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.CustomRole{}, models.NotFoundError
+	}
+	if err != nil {
+		return models.CustomRole{}, fmt.Errorf("pool.QueryRow error: %w", err)
+	}
+	return dbmodels.AdaptCustomRole(customRole)
+}
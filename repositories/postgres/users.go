@@ -13,7 +13,7 @@ import (
 
 func (db *Database) UserByEmail(ctx context.Context, email string) (models.User, error) {
 	query := `
-		SELECT id, email, first_name, last_name, role, organization_id, partner_id
+		SELECT id, email, first_name, last_name, role, organization_id, partner_id, custom_role_id
 		FROM users
 		WHERE email = $1
 		AND deleted_at IS NULL
@@ -30,6 +30,7 @@ func (db *Database) UserByEmail(ctx context.Context, email string) (models.User,
 			&user.Role,
 			&organizationID,
 			&user.PartnerId,
+			&user.CustomRoleId,
 		)
 	if firstName.Valid {
 		user.FirstName = firstName.String
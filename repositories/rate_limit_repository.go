@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func selectRateLimits() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(dbmodels.RateLimitFields...).
+		From(dbmodels.TABLE_RATE_LIMITS)
+}
+
+// ListRateLimitSettings returns the rate limits of the organization and of its API keys
+func (repo *MarbleDbRepository) ListRateLimitSettings(ctx context.Context, exec Executor,
+	organizationId string,
+) ([]models.RateLimitSettings, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		selectRateLimits().
+			Where(squirrel.Eq{"org_id": organizationId}).
+			OrderBy("api_key_id NULLS FIRST"),
+		dbmodels.AdaptRateLimitSettings,
+	)
+}
+
+func (repo *MarbleDbRepository) UpsertRateLimitSettings(ctx context.Context, exec Executor,
+	input models.UpsertRateLimitInput,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	conflictTarget := "(org_id) WHERE api_key_id IS NULL"
+	if input.ApiKeyId != nil {
+		conflictTarget = "(api_key_id) WHERE api_key_id IS NOT NULL"
+	}
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_RATE_LIMITS).
+			Columns("org_id", "api_key_id", "requests_per_second", "burst", "monthly_quota").
+			Values(
+				input.OrganizationId,
+				input.ApiKeyId,
+				input.Policy.RequestsPerSecond,
+				input.Policy.Burst,
+				input.Policy.MonthlyQuota,
+			).
+			Suffix(`ON CONFLICT `+conflictTarget+` DO UPDATE SET
+				requests_per_second = EXCLUDED.requests_per_second,
+				burst = EXCLUDED.burst,
+				monthly_quota = EXCLUDED.monthly_quota,
+				updated_at = NOW()`),
+	)
+}
+
+func (repo *MarbleDbRepository) DeleteRateLimitSettings(ctx context.Context, exec Executor,
+	organizationId string, apiKeyId *string,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Delete(dbmodels.TABLE_RATE_LIMITS).
+		Where(squirrel.Eq{"org_id": organizationId})
+	if apiKeyId != nil {
+		query = query.Where(squirrel.Eq{"api_key_id": *apiKeyId})
+	} else {
+		query = query.Where("api_key_id IS NULL")
+	}
+	return ExecBuilder(ctx, exec, query)
+}
+
+// IncrementRateLimitCounter counts a request of the subject in the period, and returns the number of requests
+func (repo *MarbleDbRepository) IncrementRateLimitCounter(ctx context.Context, exec Executor,
+	subject, period string,
+) (int64, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := NewQueryBuilder().
+		Insert(dbmodels.TABLE_RATE_LIMIT_COUNTERS).
+		Columns("subject", "period", "count").
+		Values(subject, period, 1).
+		Suffix(`ON CONFLICT (subject, period) DO UPDATE SET
+			count = ` + dbmodels.TABLE_RATE_LIMIT_COUNTERS + `.count + 1
+			RETURNING count`).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = exec.QueryRow(ctx, sql, args...).Scan(&count)
+	return count, err
+}
+
+func (repo *MarbleDbRepository) GetRateLimitCounter(ctx context.Context, exec Executor,
+	subject, period string,
+) (int64, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := NewQueryBuilder().
+		Select("count").
+		From(dbmodels.TABLE_RATE_LIMIT_COUNTERS).
+		Where(squirrel.Eq{"subject": subject, "period": period}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = exec.QueryRow(ctx, sql, args...).Scan(&count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return count, err
+}
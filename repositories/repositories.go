@@ -3,6 +3,7 @@ package repositories
 import (
 	"firebase.google.com/go/v4/auth"
 	"github.com/Masterminds/squirrel"
+	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories/firebase"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -12,6 +13,7 @@ type options struct {
 	metabase                      Metabase
 	transfercheckEnrichmentBucket string
 	fakeGcsRepository             bool
+	blobStorage                   BlobStorageConfig
 	convoyClientProvider          ConvoyClientProvider
 }
 
@@ -49,6 +51,12 @@ func WithFakeGcsRepository(b bool) Option {
 	}
 }
 
+func WithBlobStorage(config BlobStorageConfig) Option {
+	return func(o *options) {
+		o.blobStorage = config
+	}
+}
+
 func WithConvoyClientProvider(convoyResources ConvoyClientProvider) Option {
 	return func(o *options) {
 		o.convoyClientProvider = convoyResources
@@ -70,7 +78,9 @@ type Repositories struct {
 	ScenarioPublicationRepository     ScenarioPublicationRepository
 	OrganizationSchemaRepository      OrganizationSchemaRepository
 	AwsS3Repository                   AwsS3Repository
-	GcsRepository                     GcsRepository
+	BlobRepository                    BlobRepository
+	BlobUrlSigner                     *BlobUrlSigner
+	OidcRepository                    *OidcRepository
 	CustomListRepository              CustomListRepository
 	UploadLogRepository               UploadLogRepository
 	MarbleAnalyticsRepository         MarbleAnalyticsRepository
@@ -89,11 +99,20 @@ func NewRepositories(
 
 	executorGetter := NewExecutorGetter(marbleConnectionPool)
 
-	var gcsRepository GcsRepository
-	if options.fakeGcsRepository {
-		gcsRepository = &GcsRepositoryFake{}
-	} else {
-		gcsRepository = &GcsRepositoryImpl{}
+	s3Client := NewS3Client(options.blobStorage.S3ForcePathStyle)
+
+	var blobRepository BlobRepository
+	var blobUrlSigner *BlobUrlSigner
+	switch {
+	case options.fakeGcsRepository:
+		blobRepository = &BlobRepositoryFake{}
+	case options.blobStorage.Backend == models.BlobStorageBackendS3:
+		blobRepository = &S3BlobRepository{s3Client: s3Client}
+	case options.blobStorage.Backend == models.BlobStorageBackendLocal:
+		blobUrlSigner = NewBlobUrlSigner(options.blobStorage.LocalDownloadUrl, options.blobStorage.LocalUrlSigningSecret)
+		blobRepository = NewLocalBlobRepository(options.blobStorage.LocalDirectory, blobUrlSigner)
+	default:
+		blobRepository = &GcsBlobRepository{}
 	}
 
 	return Repositories{
@@ -112,13 +131,15 @@ func NewRepositories(
 		OrganizationSchemaRepository:  &OrganizationSchemaRepositoryPostgresql{},
 		CustomListRepository:          &CustomListRepositoryPostgresql{},
 		UploadLogRepository:           &UploadLogRepositoryImpl{},
-		AwsS3Repository:               AwsS3Repository{s3Client: NewS3Client()},
-		GcsRepository:                 gcsRepository,
+		AwsS3Repository:               AwsS3Repository{s3Client: s3Client},
+		BlobRepository:                blobRepository,
+		BlobUrlSigner:                 blobUrlSigner,
+		OidcRepository:                NewOidcRepository(),
 		MarbleAnalyticsRepository: MarbleAnalyticsRepository{
 			metabase: options.metabase,
 		},
 		TransferCheckEnrichmentRepository: NewTransferCheckEnrichmentRepository(
-			gcsRepository,
+			blobRepository,
 			options.transfercheckEnrichmentBucket,
 		),
 	}
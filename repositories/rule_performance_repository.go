@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func decisionsOfScenarioInWindow(query squirrel.SelectBuilder, input models.RulePerformanceReportInput) squirrel.SelectBuilder {
+	return query.
+		LeftJoin(dbmodels.TABLE_CASES + " AS c ON c.id = d.case_id").
+		Where(squirrel.Eq{"d.org_id": input.OrganizationId}).
+		Where(squirrel.Eq{"d.scenario_id": input.ScenarioId}).
+		Where(squirrel.GtOrEq{"d.created_at": input.StartDate}).
+		Where(squirrel.Lt{"d.created_at": input.EndDate})
+}
+
+// CountDecisionLabels counts the decisions of the scenario in the time window, by review disposition and case status
+func (repo *MarbleDbRepository) CountDecisionLabels(
+	ctx context.Context,
+	exec Executor,
+	input models.RulePerformanceReportInput,
+) ([]models.DecisionLabelCount, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	query := decisionsOfScenarioInWindow(
+		NewQueryBuilder().
+			Select("d.review_disposition", "c.status", "COUNT(*)").
+			From(dbmodels.TABLE_DECISIONS+" AS d"),
+		input,
+	).GroupBy("d.review_disposition", "c.status")
+
+	return SqlToListOfRow(ctx, exec, query, func(row pgx.CollectableRow) (models.DecisionLabelCount, error) {
+		var reviewDisposition, caseStatus *string
+		var count int
+		if err := row.Scan(&reviewDisposition, &caseStatus, &count); err != nil {
+			return models.DecisionLabelCount{}, err
+		}
+		return models.DecisionLabelCount{
+			ReviewDisposition: adaptReviewDisposition(reviewDisposition),
+			CaseStatus:        adaptCaseStatus(caseStatus),
+			Count:             count,
+		}, nil
+	})
+}
+
+// CountRuleExecutionLabels counts the executions of the rules on the decisions of the scenario in the time window,
+// by rule, result, review disposition and case status. The rules are identified by their name, which is kept by the
+// copies of a rule in the successive iterations of the scenario.
+func (repo *MarbleDbRepository) CountRuleExecutionLabels(
+	ctx context.Context,
+	exec Executor,
+	input models.RulePerformanceReportInput,
+) ([]models.RuleExecutionLabelCount, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	query := countRuleExecutionLabelsQuery(input)
+	return SqlToListOfRow(ctx, exec, query, func(row pgx.CollectableRow) (models.RuleExecutionLabelCount, error) {
+		var count models.RuleExecutionLabelCount
+		var reviewDisposition, caseStatus *string
+		if err := row.Scan(&count.RuleName, &count.RuleId, &count.LastExecutedAt, &count.Result,
+			&reviewDisposition, &caseStatus, &count.Count); err != nil {
+			return models.RuleExecutionLabelCount{}, err
+		}
+		count.ReviewDisposition = adaptReviewDisposition(reviewDisposition)
+		count.CaseStatus = adaptCaseStatus(caseStatus)
+		return count, nil
+	})
+}
+
+func countRuleExecutionLabelsQuery(input models.RulePerformanceReportInput) squirrel.SelectBuilder {
+	return decisionsOfScenarioInWindow(
+		NewQueryBuilder().
+			Select(
+				"dr.name",
+				// id of the rule in the latest iteration that executed it
+				"COALESCE((ARRAY_AGG(dr.rule_id::text ORDER BY d.created_at DESC) FILTER (WHERE dr.rule_id IS NOT NULL))[1], '')",
+				"MAX(d.created_at)",
+				"dr.result",
+				"d.review_disposition",
+				"c.status",
+				"COUNT(*)",
+			).
+			From(dbmodels.TABLE_DECISIONS+" AS d").
+			Join(dbmodels.TABLE_DECISION_RULES+" AS dr ON dr.decision_id = d.id"),
+		input,
+	).
+		GroupBy("dr.name", "dr.result", "d.review_disposition", "c.status").
+		OrderBy("dr.name")
+}
+
+func adaptReviewDisposition(reviewDisposition *string) *models.DecisionReviewDisposition {
+	if reviewDisposition == nil {
+		return nil
+	}
+	disposition := models.DecisionReviewDisposition(*reviewDisposition)
+	return &disposition
+}
+
+func adaptCaseStatus(caseStatus *string) *models.CaseStatus {
+	if caseStatus == nil {
+		return nil
+	}
+	status := models.CaseStatus(*caseStatus)
+	return &status
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func TestCountRuleExecutionLabelsQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := models.RulePerformanceReportInput{
+		OrganizationId: "organization_id",
+		ScenarioId:     "scenario_id",
+		StartDate:      start,
+		EndDate:        start.AddDate(0, 1, 0),
+	}
+
+	sql, args, err := countRuleExecutionLabelsQuery(input).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT dr.name, "+
+		"COALESCE((ARRAY_AGG(dr.rule_id::text ORDER BY d.created_at DESC) FILTER (WHERE dr.rule_id IS NOT NULL))[1], ''), "+
+		"MAX(d.created_at), dr.result, d.review_disposition, c.status, COUNT(*) "+
+		"FROM decisions AS d JOIN decision_rules AS dr ON dr.decision_id = d.id "+
+		"LEFT JOIN cases AS c ON c.id = d.case_id "+
+		"WHERE d.org_id = $1 AND d.scenario_id = $2 AND d.created_at >= $3 AND d.created_at < $4 "+
+		// the executions are grouped by rule name, not by rule id which changes with each iteration of the scenario
+		"GROUP BY dr.name, dr.result, d.review_disposition, c.status "+
+		"ORDER BY dr.name", sql)
+	assert.Equal(t, []any{"organization_id", "scenario_id", input.StartDate, input.EndDate}, args)
+}
+
+func TestLabelDecisionsQuery(t *testing.T) {
+	reviewedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	review := models.DecisionReview{Disposition: models.DecisionReviewConfirmedFraud, ReviewedAt: reviewedAt}
+
+	sql, args, err := labelDecisionsQuery([]string{"decision_1", "decision_2"}, review).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE decisions SET review_disposition = $1, reviewed_by = $2, reviewed_at = $3, review_note = $4 "+
+		"WHERE id IN ($5,$6) AND reviewed_by IS NULL RETURNING id", sql)
+	assert.Equal(t, []any{models.DecisionReviewConfirmedFraud, (*models.UserId)(nil), reviewedAt, "",
+		"decision_1", "decision_2"}, args)
+}
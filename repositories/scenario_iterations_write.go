@@ -47,12 +47,18 @@ func (repo *MarbleDbRepository) CreateScenarioIterationAndRules(ctx context.Cont
 					"unable to marshal trigger condition ast expression: %w", err)
 			}
 		}
+		outputVariables, err := dbmodels.SerializeOutputVariables(scenarioIterationBodyInput.OutputVariables)
+		if err != nil {
+			return models.ScenarioIteration{}, err
+		}
 		query = query.Columns(
 			"score_review_threshold",
 			"score_reject_threshold",
 			"trigger_condition_ast_expression",
 			"batch_trigger_sql",
 			"schedule",
+			"schedule_timezone",
+			"output_variables",
 		).Values(
 			pure_utils.NewPrimaryKey(organizationId),
 			organizationId,
@@ -62,6 +68,8 @@ func (repo *MarbleDbRepository) CreateScenarioIterationAndRules(ctx context.Cont
 			triggerCondition,
 			scenarioIterationBodyInput.BatchTriggerSQL,
 			scenarioIterationBodyInput.Schedule,
+			scenarioIterationBodyInput.ScheduleTimezone,
+			outputVariables,
 		)
 	} else {
 		query = query.Values(
@@ -124,10 +132,22 @@ func (repo *MarbleDbRepository) UpdateScenarioIteration(ctx context.Context, exe
 		sql = sql.Set("schedule", scenarioIteration.Body.Schedule)
 		countUpdate++
 	}
+	if scenarioIteration.Body.ScheduleTimezone != nil {
+		sql = sql.Set("schedule_timezone", scenarioIteration.Body.ScheduleTimezone)
+		countUpdate++
+	}
 	if scenarioIteration.Body.BatchTriggerSQL != nil {
 		sql = sql.Set("batch_trigger_sql", scenarioIteration.Body.BatchTriggerSQL)
 		countUpdate++
 	}
+	if scenarioIteration.Body.OutputVariables != nil {
+		outputVariables, err := dbmodels.SerializeOutputVariables(*scenarioIteration.Body.OutputVariables)
+		if err != nil {
+			return models.ScenarioIteration{}, err
+		}
+		sql = sql.Set("output_variables", outputVariables)
+		countUpdate++
+	}
 	if scenarioIteration.Body.TriggerConditionAstExpression != nil {
 		triggerCondition, err := dbmodels.SerializeFormulaAstExpression(
 			scenarioIteration.Body.TriggerConditionAstExpression)
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func selectScenarioTemplates() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(dbmodels.ScenarioTemplateFields...).
+		From(dbmodels.TABLE_SCENARIO_TEMPLATES)
+}
+
+func (repo *MarbleDbRepository) GetScenarioTemplateById(ctx context.Context, exec Executor, templateId string) (models.ScenarioTemplate, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+
+	return SqlToModel(ctx, exec, selectScenarioTemplates().Where(squirrel.Eq{"id": templateId}),
+		dbmodels.AdaptScenarioTemplate)
+}
+
+func (repo *MarbleDbRepository) ListScenarioTemplates(ctx context.Context, exec Executor) ([]models.ScenarioTemplate, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(ctx, exec, selectScenarioTemplates().OrderBy("name"), dbmodels.AdaptScenarioTemplate)
+}
+
+func (repo *MarbleDbRepository) CreateScenarioTemplate(ctx context.Context, exec Executor,
+	input models.CreateScenarioTemplateInput, newTemplateId string,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	placeholders, err := dbmodels.SerializeScenarioTemplatePlaceholders(input.Placeholders)
+	if err != nil {
+		return err
+	}
+	bundle, err := dbmodels.SerializeScenarioTemplateBundle(input.Bundle)
+	if err != nil {
+		return err
+	}
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_SCENARIO_TEMPLATES).
+			Columns("id", "name", "description", "placeholders", "bundle").
+			Values(newTemplateId, input.Name, input.Description, placeholders, bundle),
+	)
+}
+
+func (repo *MarbleDbRepository) UpdateScenarioTemplate(ctx context.Context, exec Executor, input models.UpdateScenarioTemplateInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_SCENARIO_TEMPLATES).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": input.Id})
+	if input.Name != nil {
+		query = query.Set("name", *input.Name)
+	}
+	if input.Description != nil {
+		query = query.Set("description", *input.Description)
+	}
+	if input.Placeholders != nil {
+		placeholders, err := dbmodels.SerializeScenarioTemplatePlaceholders(*input.Placeholders)
+		if err != nil {
+			return err
+		}
+		query = query.Set("placeholders", placeholders)
+	}
+	if input.Bundle != nil {
+		bundle, err := dbmodels.SerializeScenarioTemplateBundle(*input.Bundle)
+		if err != nil {
+			return err
+		}
+		query = query.Set("bundle", bundle)
+	}
+	return ExecBuilder(ctx, exec, query)
+}
+
+func (repo *MarbleDbRepository) DeleteScenarioTemplate(ctx context.Context, exec Executor, templateId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SCENARIO_TEMPLATES).
+			Where(squirrel.Eq{"id": templateId}),
+	)
+}
@@ -5,12 +5,17 @@ import (
 	"fmt"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgx/v5"
 
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories/dbmodels"
 )
 
+// ErrScheduledExecutionFinished is returned when changing the status of a scheduled execution that has already finished,
+// e.g. one that was cancelled while it was being processed
+var ErrScheduledExecutionFinished = errors.New("the scheduled execution is already finished")
+
 type dbJoinScheduledExecutionAndScenario struct {
 	dbmodels.DBScheduledExecution
 	dbmodels.DBScenario
@@ -123,11 +128,20 @@ func (repo *MarbleDbRepository) UpdateScheduledExecution(ctx context.Context, ex
 		return err
 	}
 	query := NewQueryBuilder().Update(dbmodels.TABLE_SCHEDULED_EXECUTIONS).
+		Set("updated_at", "NOW()").
 		Where("id = ?", updateScheduledEx.Id)
 
 	if updateScheduledEx.Status != nil {
-		query = query.Set("status", updateScheduledEx.Status.String())
-		if *updateScheduledEx.Status == models.ScheduledExecutionSuccess {
+		// the status of a finished execution is final: the check is done by the update itself, so that a concurrent
+		// cancellation is never overwritten
+		query = query.Set("status", updateScheduledEx.Status.String()).
+			Where(squirrel.NotEq{"status": []string{
+				models.ScheduledExecutionSuccess.String(),
+				models.ScheduledExecutionFailure.String(),
+				models.ScheduledExecutionCancelled.String(),
+			}})
+		if *updateScheduledEx.Status == models.ScheduledExecutionSuccess ||
+			*updateScheduledEx.Status == models.ScheduledExecutionCancelled {
 			query = query.Set("finished_at", "NOW()")
 		}
 	}
@@ -137,6 +151,26 @@ func (repo *MarbleDbRepository) UpdateScheduledExecution(ctx context.Context, ex
 			*updateScheduledEx.NumberOfCreatedDecisions)
 	}
 
-	err := ExecBuilder(ctx, exec, query)
-	return err
+	if updateScheduledEx.NumberOfEvaluatedObjects != nil {
+		query = query.Set("number_of_evaluated_objects",
+			*updateScheduledEx.NumberOfEvaluatedObjects)
+	}
+
+	if updateScheduledEx.LastProcessedObjectId != nil {
+		query = query.Set("last_processed_object_id",
+			*updateScheduledEx.LastProcessedObjectId)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "can't build sql query")
+	}
+	tag, err := exec.Exec(ctx, sql, args...)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error executing sql query: %s", sql))
+	}
+	if updateScheduledEx.Status != nil && tag.RowsAffected() == 0 {
+		return errors.Wrapf(ErrScheduledExecutionFinished, "scheduled execution %s", updateScheduledEx.Id)
+	}
+	return nil
 }
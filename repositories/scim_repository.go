@@ -0,0 +1,287 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func selectScimTokens() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(dbmodels.ScimTokenFields...).
+		From(dbmodels.TABLE_SCIM_TOKENS).
+		Where("deleted_at IS NULL")
+}
+
+func (repo *MarbleDbRepository) GetScimTokenById(ctx context.Context, exec Executor, tokenId string) (models.ScimToken, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.ScimToken{}, err
+	}
+
+	return SqlToModel(ctx, exec, selectScimTokens().Where(squirrel.Eq{"id": tokenId}), dbmodels.AdaptScimToken)
+}
+
+func (repo *MarbleDbRepository) GetScimTokenByHash(ctx context.Context, exec Executor, hash []byte) (models.ScimToken, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.ScimToken{}, err
+	}
+
+	return SqlToModel(ctx, exec, selectScimTokens().Where(squirrel.Eq{"token_hash": hash}), dbmodels.AdaptScimToken)
+}
+
+func (repo *MarbleDbRepository) ListScimTokens(ctx context.Context, exec Executor, organizationId string) ([]models.ScimToken, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		selectScimTokens().
+			Where(squirrel.Eq{"org_id": organizationId}).
+			OrderBy("created_at DESC"),
+		dbmodels.AdaptScimToken,
+	)
+}
+
+func (repo *MarbleDbRepository) CreateScimToken(ctx context.Context, exec Executor, token models.ScimToken) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_SCIM_TOKENS).
+			Columns("id", "org_id", "description", "prefix", "token_hash").
+			Values(token.Id, token.OrganizationId, token.Description, token.Prefix, token.Hash),
+	)
+}
+
+func (repo *MarbleDbRepository) SoftDeleteScimToken(ctx context.Context, exec Executor, tokenId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_SCIM_TOKENS).
+			Set("deleted_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": tokenId}),
+	)
+}
+
+// The identity provider also sees the deactivated (soft deleted) users of the organization
+
+func (repo *MarbleDbRepository) ListScimUsers(ctx context.Context, exec Executor, organizationId, email string) ([]models.User, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	query := NewQueryBuilder().
+		Select(dbmodels.UserFields...).
+		From(dbmodels.TABLE_USERS).
+		Where(squirrel.Eq{"organization_id": organizationId}).
+		OrderBy("email", "id")
+	if email != "" {
+		query = query.Where(squirrel.Eq{"email": email})
+	}
+	return SqlToListOfModels(ctx, exec, query, dbmodels.AdaptUser)
+}
+
+func (repo *MarbleDbRepository) GetScimUser(ctx context.Context, exec Executor, organizationId string, userId models.UserId) (models.User, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.User{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.UserFields...).
+			From(dbmodels.TABLE_USERS).
+			Where(squirrel.Eq{"organization_id": organizationId}).
+			Where(squirrel.Eq{"id": userId}),
+		dbmodels.AdaptUser,
+	)
+}
+
+func (repo *MarbleDbRepository) ReactivateUser(ctx context.Context, exec Executor, userId models.UserId) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_USERS).
+			Set("deleted_at", nil).
+			Where(squirrel.Eq{"id": userId}),
+	)
+}
+
+func selectScimGroups() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(columnsNames("g", dbmodels.ScimGroupFields)...).
+		From(dbmodels.TABLE_SCIM_GROUPS + " AS g")
+}
+
+func (repo *MarbleDbRepository) ListScimGroups(ctx context.Context, exec Executor, organizationId, displayName string) ([]models.ScimGroup, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	query := selectScimGroups().
+		Where(squirrel.Eq{"g.org_id": organizationId}).
+		OrderBy("g.display_name")
+	if displayName != "" {
+		query = query.Where(squirrel.Eq{"g.display_name": displayName})
+	}
+	return SqlToListOfModels(ctx, exec, query, dbmodels.AdaptScimGroup)
+}
+
+func (repo *MarbleDbRepository) GetScimGroup(ctx context.Context, exec Executor, organizationId, groupId string) (models.ScimGroup, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.ScimGroup{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		selectScimGroups().
+			Where(squirrel.Eq{"g.org_id": organizationId}).
+			Where(squirrel.Eq{"g.id": groupId}),
+		dbmodels.AdaptScimGroup,
+	)
+}
+
+func (repo *MarbleDbRepository) ListScimGroupsOfUser(ctx context.Context, exec Executor, userId models.UserId) ([]models.ScimGroup, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		selectScimGroups().
+			Join(dbmodels.TABLE_SCIM_GROUP_MEMBERS+" AS m ON m.group_id = g.id").
+			Where(squirrel.Eq{"m.user_id": userId}).
+			OrderBy("g.display_name"),
+		dbmodels.AdaptScimGroup,
+	)
+}
+
+func (repo *MarbleDbRepository) ListScimGroupMembers(ctx context.Context, exec Executor, groupIds []string) ([]models.ScimGroupMember, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select("m.group_id", "m.user_id", "u.email").
+			From(dbmodels.TABLE_SCIM_GROUP_MEMBERS+" AS m").
+			Join(dbmodels.TABLE_USERS+" AS u ON u.id = m.user_id").
+			Where(squirrel.Eq{"m.group_id": groupIds}).
+			OrderBy("u.email"),
+		dbmodels.AdaptScimGroupMember,
+	)
+}
+
+func (repo *MarbleDbRepository) CreateScimGroup(ctx context.Context, exec Executor,
+	organizationId string, input models.ScimGroupInput, newGroupId string,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_SCIM_GROUPS).
+			Columns("id", "org_id", "display_name", "external_id").
+			Values(newGroupId, organizationId, input.DisplayName, input.ExternalId),
+	)
+}
+
+func (repo *MarbleDbRepository) UpdateScimGroup(ctx context.Context, exec Executor, groupId string, input models.ScimGroupInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_SCIM_GROUPS).
+			Set("display_name", input.DisplayName).
+			Set("external_id", input.ExternalId).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": groupId}),
+	)
+}
+
+func (repo *MarbleDbRepository) UpdateScimGroupMapping(ctx context.Context, exec Executor, input models.UpdateScimGroupMappingInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_SCIM_GROUPS).
+			Set("role", int(input.Role)).
+			Set("inbox_id", input.InboxId).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": input.GroupId}),
+	)
+}
+
+func (repo *MarbleDbRepository) SetScimGroupMembers(ctx context.Context, exec Executor, groupId string, userIds []string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	err := ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SCIM_GROUP_MEMBERS).
+			Where(squirrel.Eq{"group_id": groupId}),
+	)
+	if err != nil || len(userIds) == 0 {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Insert(dbmodels.TABLE_SCIM_GROUP_MEMBERS).
+		Columns("group_id", "user_id")
+	for _, userId := range userIds {
+		query = query.Values(groupId, userId)
+	}
+	return ExecBuilder(ctx, exec, query)
+}
+
+func (repo *MarbleDbRepository) DeleteScimGroup(ctx context.Context, exec Executor, groupId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SCIM_GROUPS).
+			Where(squirrel.Eq{"id": groupId}),
+	)
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+func (repo *MarbleDbRepository) GetSsoProviderOfOrganization(
+	ctx context.Context,
+	exec Executor,
+	organizationId string,
+) (models.SsoProvider, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.SsoProvider{}, err
+	}
+
+	return SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.SsoProviderFields...).
+			From(dbmodels.TABLE_SSO_PROVIDERS).
+			Where(squirrel.Eq{"org_id": organizationId}),
+		dbmodels.AdaptSsoProvider,
+	)
+}
+
+// UpsertSsoProvider creates the identity provider of the organization, or replaces it if it already exists
+func (repo *MarbleDbRepository) UpsertSsoProvider(ctx context.Context, exec Executor, input models.UpsertSsoProviderInput) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	roleMappings, err := dbmodels.SerializeSsoRoleMappings(input.RoleMappings)
+	if err != nil {
+		return err
+	}
+	scopes := input.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	clientSecret := ""
+	if input.ClientSecret != nil {
+		clientSecret = *input.ClientSecret
+	}
+
+	updatedColumns := []string{
+		"issuer_url", "client_id", "client_secret", "scopes", "role_claim",
+		"role_mappings", "default_role", "jit_provisioning", "enabled",
+	}
+	updates := make([]string, 0, len(updatedColumns)+1)
+	for _, column := range updatedColumns {
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+	}
+	updates = append(updates, "updated_at = NOW()")
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_SSO_PROVIDERS).
+			Columns(append([]string{"org_id"}, updatedColumns...)...).
+			Values(
+				input.OrganizationId,
+				input.IssuerUrl,
+				input.ClientId,
+				clientSecret,
+				scopes,
+				input.RoleClaim,
+				roleMappings,
+				int(input.DefaultRole),
+				input.JitProvisioning,
+				input.Enabled,
+			).
+			Suffix("ON CONFLICT (org_id) DO UPDATE SET "+strings.Join(updates, ", ")),
+	)
+}
+
+func (repo *MarbleDbRepository) DeleteSsoProvider(ctx context.Context, exec Executor, organizationId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SSO_PROVIDERS).
+			Where(squirrel.Eq{"org_id": organizationId}),
+	)
+}
+
+func (repo *MarbleDbRepository) CreateSsoLoginState(ctx context.Context, exec Executor, state models.SsoLoginState) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_SSO_LOGIN_STATES).
+			Columns(dbmodels.SsoLoginStateFields...).
+			Values(
+				state.State,
+				state.OrganizationId,
+				state.CodeVerifier,
+				state.Nonce,
+				state.RedirectUri,
+				state.ExpiresAt,
+			),
+	)
+}
+
+// ConsumeSsoLoginState deletes and returns the login state, so that a state can only be used once
+func (repo *MarbleDbRepository) ConsumeSsoLoginState(ctx context.Context, exec Executor, state string) (models.SsoLoginState, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.SsoLoginState{}, err
+	}
+
+	loginState, err := SqlToModel(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SSO_LOGIN_STATES).
+			Where(squirrel.Eq{"state": state}).
+			Suffix("RETURNING "+strings.Join(dbmodels.SsoLoginStateFields, ", ")),
+		dbmodels.AdaptSsoLoginState,
+	)
+	if errors.Is(err, models.NotFoundError) {
+		return models.SsoLoginState{}, errors.Wrap(models.UnAuthorizedError, "unknown or already used SSO login state")
+	}
+	return loginState, err
+}
+
+func (repo *MarbleDbRepository) DeleteExpiredSsoLoginStates(ctx context.Context, exec Executor, now time.Time) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Delete(dbmodels.TABLE_SSO_LOGIN_STATES).
+			Where(squirrel.Lt{"expires_at": now}),
+	)
+}
@@ -38,18 +38,31 @@ func (repo MarbleDbRepository) ListWebhookEvents(ctx context.Context, exec Execu
 
 	mergedFilters := filters.MergeWithDefaults()
 
-	query := selectWebhookEvents().Limit(mergedFilters.Limit)
+	query := selectWebhookEvents().
+		Where(squirrel.Eq{"organization_id": mergedFilters.OrganizationId}).
+		Where(squirrel.Eq{"partner_id": mergedFilters.PartnerId.Ptr()}).
+		OrderBy("created_at DESC", "id").
+		Limit(mergedFilters.Limit)
 
 	if mergedFilters.DeliveryStatus != nil {
 		query = query.Where(squirrel.Eq{"delivery_status": mergedFilters.DeliveryStatus})
 	}
+	if len(mergedFilters.EventTypes) > 0 {
+		query = query.Where(squirrel.Eq{"event_type": mergedFilters.EventTypes})
+	}
+	if !mergedFilters.StartDate.IsZero() {
+		query = query.Where(squirrel.GtOrEq{"created_at": mergedFilters.StartDate})
+	}
+	if !mergedFilters.EndDate.IsZero() {
+		query = query.Where(squirrel.Lt{"created_at": mergedFilters.EndDate})
+	}
 
 	return SqlToListOfRow(
 		ctx,
 		exec,
 		query,
 		func(row pgx.CollectableRow) (models.WebhookEvent, error) {
-			db, err := pgx.RowToStructByPos[dbmodels.DBWebhookEvent](row)
+			db, err := pgx.RowToStructByName[dbmodels.DBWebhookEvent](row)
 			if err != nil {
 				return models.WebhookEvent{}, err
 			}
@@ -59,6 +72,24 @@ func (repo MarbleDbRepository) ListWebhookEvents(ctx context.Context, exec Execu
 	)
 }
 
+// MarkWebhookEventForReplay puts a webhook event back in the scheduled state, so that it is sent again
+func (repo MarbleDbRepository) MarkWebhookEventForReplay(ctx context.Context, exec Executor, webhookEventId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_WEBHOOK_EVENTS).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Set("delivery_status", models.Scheduled).
+			Set("replay_count", squirrel.Expr("replay_count + 1")).
+			Where(squirrel.Eq{"id": webhookEventId}),
+	)
+}
+
 func (repo MarbleDbRepository) CreateWebhookEvent(
 	ctx context.Context,
 	exec Executor,
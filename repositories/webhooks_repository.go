@@ -0,0 +1,397 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/guregu/null/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/dbmodels"
+)
+
+// The methods below store the webhook endpoints and their deliveries when webhooks are delivered by the built-in
+// backend. When Convoy is used, the webhook endpoints are managed by the ConvoyRepository instead.
+
+func selectWebhooks() squirrel.SelectBuilder {
+	return NewQueryBuilder().
+		Select(dbmodels.WebhookFields...).
+		From(dbmodels.TABLE_WEBHOOKS).
+		Where(squirrel.Eq{"deleted_at": nil})
+}
+
+func (repo MarbleDbRepository) GetWebhook(ctx context.Context, exec Executor, webhookId string) (models.Webhook, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook, err := SqlToModel(
+		ctx,
+		exec,
+		selectWebhooks().Where(squirrel.Eq{"id": webhookId}),
+		dbmodels.AdaptWebhook,
+	)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhooks, err := repo.withWebhookSecrets(ctx, exec, []models.Webhook{webhook})
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return webhooks[0], nil
+}
+
+func (repo MarbleDbRepository) ListWebhooks(
+	ctx context.Context,
+	exec Executor,
+	organizationId string,
+	partnerId null.String,
+) ([]models.Webhook, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	query := selectWebhooks().
+		Where(squirrel.Eq{"organization_id": organizationId}).
+		Where(squirrel.Eq{"partner_id": partnerId.Ptr()}).
+		OrderBy("created_at")
+
+	webhooks, err := SqlToListOfModels(ctx, exec, query, dbmodels.AdaptWebhook)
+	if err != nil {
+		return nil, err
+	}
+	return repo.withWebhookSecrets(ctx, exec, webhooks)
+}
+
+func (repo MarbleDbRepository) withWebhookSecrets(
+	ctx context.Context,
+	exec Executor,
+	webhooks []models.Webhook,
+) ([]models.Webhook, error) {
+	if len(webhooks) == 0 {
+		return webhooks, nil
+	}
+
+	webhookIds := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		webhookIds[i] = webhook.Id
+	}
+
+	secretsByWebhookId := make(map[string][]models.Secret, len(webhooks))
+	secrets, err := SqlToListOfRow(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.WebhookSecretFields...).
+			From(dbmodels.TABLE_WEBHOOK_SECRETS).
+			Where(squirrel.Eq{"webhook_id": webhookIds}).
+			Where(squirrel.Eq{"deleted_at": nil}).
+			OrderBy("created_at"),
+		func(row pgx.CollectableRow) (dbmodels.DBWebhookSecret, error) {
+			return pgx.RowToStructByName[dbmodels.DBWebhookSecret](row)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, dbSecret := range secrets {
+		secret, err := dbmodels.AdaptWebhookSecret(dbSecret)
+		if err != nil {
+			return nil, err
+		}
+		secretsByWebhookId[dbSecret.WebhookId] = append(secretsByWebhookId[dbSecret.WebhookId], secret)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secrets = secretsByWebhookId[webhooks[i].Id]
+	}
+	return webhooks, nil
+}
+
+func (repo MarbleDbRepository) CreateWebhook(
+	ctx context.Context,
+	exec Executor,
+	webhookId string,
+	organizationId string,
+	partnerId null.String,
+	input models.WebhookRegister,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	eventTypes := input.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	err := ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_WEBHOOKS).
+			Columns(
+				"id",
+				"organization_id",
+				"partner_id",
+				"url",
+				"event_types",
+				"http_timeout",
+				"rate_limit",
+				"rate_limit_duration",
+			).
+			Values(
+				webhookId,
+				organizationId,
+				partnerId.Ptr(),
+				input.Url,
+				eventTypes,
+				input.HttpTimeout,
+				input.RateLimit,
+				input.RateLimitDuration,
+			),
+	)
+	if err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_WEBHOOK_SECRETS).
+			Columns("webhook_id", "value").
+			Values(webhookId, input.Secret),
+	)
+}
+
+// UpdateWebhook updates the endpoint settings of a webhook. The secrets of the webhook are left untouched.
+func (repo MarbleDbRepository) UpdateWebhook(ctx context.Context, exec Executor, input models.Webhook) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	eventTypes := input.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_WEBHOOKS).
+			Set("url", input.Url).
+			Set("event_types", eventTypes).
+			Set("http_timeout", input.HttpTimeout).
+			Set("rate_limit", input.RateLimit).
+			Set("rate_limit_duration", input.RateLimitDuration).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": input.Id}).
+			Where(squirrel.Eq{"deleted_at": nil}),
+	)
+}
+
+// DeleteWebhook soft deletes a webhook, so that the log of its past deliveries is kept
+func (repo MarbleDbRepository) DeleteWebhook(ctx context.Context, exec Executor, webhookId string) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_WEBHOOKS).
+			Set("deleted_at", squirrel.Expr("NOW()")).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": webhookId}).
+			Where(squirrel.Eq{"deleted_at": nil}),
+	)
+}
+
+// GetOrCreateWebhookDelivery returns the delivery of a webhook event to a webhook endpoint, creating it if needed
+func (repo MarbleDbRepository) GetOrCreateWebhookDelivery(
+	ctx context.Context,
+	exec Executor,
+	webhookEventId string,
+	webhookId string,
+) (models.WebhookDelivery, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	// the no-op update makes the existing row be returned on conflict
+	query := NewQueryBuilder().
+		Insert(dbmodels.TABLE_WEBHOOK_DELIVERIES).
+		Columns("webhook_event_id", "webhook_id", "status").
+		Values(webhookEventId, webhookId, models.Scheduled).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (webhook_event_id, webhook_id) DO UPDATE SET updated_at = %s.updated_at RETURNING %s",
+			dbmodels.TABLE_WEBHOOK_DELIVERIES,
+			strings.Join(dbmodels.WebhookDeliveryFields, ","),
+		))
+
+	return SqlToModel(ctx, exec, query, dbmodels.AdaptWebhookDelivery)
+}
+
+func (repo MarbleDbRepository) ListWebhookDeliveries(
+	ctx context.Context,
+	exec Executor,
+	webhookEventId string,
+) ([]models.WebhookDelivery, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.WebhookDeliveryFields...).
+			From(dbmodels.TABLE_WEBHOOK_DELIVERIES).
+			Where(squirrel.Eq{"webhook_event_id": webhookEventId}).
+			OrderBy("created_at"),
+		dbmodels.AdaptWebhookDelivery,
+	)
+}
+
+func (repo MarbleDbRepository) ListWebhookDeliveryAttempts(
+	ctx context.Context,
+	exec Executor,
+	deliveryIds []string,
+) ([]models.WebhookDeliveryAttempt, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return nil, err
+	}
+
+	return SqlToListOfModels(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select(dbmodels.WebhookDeliveryAttemptFields...).
+			From(dbmodels.TABLE_WEBHOOK_DELIVERY_ATTEMPTS).
+			Where(squirrel.Eq{"delivery_id": deliveryIds}).
+			OrderBy("created_at"),
+		dbmodels.AdaptWebhookDeliveryAttempt,
+	)
+}
+
+// ResetWebhookDeliveries puts the deliveries of a webhook event back in the scheduled state, with their attempts
+// counter reset, so that they are made again. If webhookId is not nil, only the deliveries to this endpoint are reset.
+// If statuses is not empty, only the deliveries in one of these statuses are reset.
+func (repo MarbleDbRepository) ResetWebhookDeliveries(
+	ctx context.Context,
+	exec Executor,
+	webhookEventId string,
+	webhookId *string,
+	statuses []models.WebhookEventDeliveryStatus,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	query := NewQueryBuilder().
+		Update(dbmodels.TABLE_WEBHOOK_DELIVERIES).
+		Set("status", models.Scheduled).
+		Set("attempts", 0).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"webhook_event_id": webhookEventId})
+	if webhookId != nil {
+		query = query.Where(squirrel.Eq{"webhook_id": *webhookId})
+	}
+	if len(statuses) > 0 {
+		query = query.Where(squirrel.Eq{"status": statuses})
+	}
+
+	return ExecBuilder(ctx, exec, query)
+}
+
+// MarkWebhookDeliveryAttempted counts a new delivery attempt and sets the resulting status of the delivery
+func (repo MarbleDbRepository) MarkWebhookDeliveryAttempted(
+	ctx context.Context,
+	exec Executor,
+	deliveryId string,
+	status models.WebhookEventDeliveryStatus,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Update(dbmodels.TABLE_WEBHOOK_DELIVERIES).
+			Set("status", status).
+			Set("attempts", squirrel.Expr("attempts + 1")).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": deliveryId}),
+	)
+}
+
+func (repo MarbleDbRepository) CreateWebhookDeliveryAttempt(
+	ctx context.Context,
+	exec Executor,
+	input models.WebhookDeliveryAttemptCreate,
+) error {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return err
+	}
+
+	return ExecBuilder(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Insert(dbmodels.TABLE_WEBHOOK_DELIVERY_ATTEMPTS).
+			Columns(
+				"delivery_id",
+				"webhook_id",
+				"status_code",
+				"latency_ms",
+				"response_excerpt",
+				"error",
+			).
+			Values(
+				input.DeliveryId,
+				input.WebhookId,
+				input.StatusCode,
+				input.Latency.Milliseconds(),
+				input.ResponseExcerpt,
+				input.Error,
+			),
+	)
+}
+
+// CountWebhookDeliveryAttemptsSince returns the number of delivery attempts made to a webhook endpoint since the
+// given time, to enforce the rate limit of the endpoint
+func (repo MarbleDbRepository) CountWebhookDeliveryAttemptsSince(
+	ctx context.Context,
+	exec Executor,
+	webhookId string,
+	since time.Time,
+) (int, error) {
+	if err := validateMarbleDbExecutor(exec); err != nil {
+		return 0, err
+	}
+
+	return SqlToRow(
+		ctx,
+		exec,
+		NewQueryBuilder().
+			Select("COUNT(*)").
+			From(dbmodels.TABLE_WEBHOOK_DELIVERY_ATTEMPTS).
+			Where(squirrel.Eq{"webhook_id": webhookId}).
+			Where(squirrel.GtOrEq{"created_at": since}),
+		func(row pgx.CollectableRow) (int, error) {
+			var count int
+			err := row.Scan(&count)
+			return count, err
+		},
+	)
+}
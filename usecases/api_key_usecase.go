@@ -6,6 +6,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories"
@@ -20,6 +22,11 @@ type ApiKeyRepository interface {
 	ListApiKeys(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.ApiKey, error)
 	CreateApiKey(ctx context.Context, exec repositories.Executor, apiKey models.ApiKey) error
 	SoftDeleteApiKey(ctx context.Context, exec repositories.Executor, apiKeyId string) error
+	ExpireApiKey(ctx context.Context, exec repositories.Executor, apiKeyId string, expiresAt time.Time) error
+}
+
+type ApiKeyScenarioRepository interface {
+	ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.Scenario, error)
 }
 
 type EnforceSecurityApiKey interface {
@@ -30,9 +37,12 @@ type EnforceSecurityApiKey interface {
 
 type ApiKeyUseCase struct {
 	executorFactory         executor_factory.ExecutorFactory
+	transactionFactory      executor_factory.TransactionFactory
 	organizationIdOfContext func() (string, error)
 	enforceSecurity         EnforceSecurityApiKey
 	apiKeyRepository        ApiKeyRepository
+	scenarioRepository      ApiKeyScenarioRepository
+	dataModelRepository     repositories.DataModelRepository
 }
 
 func (usecase *ApiKeyUseCase) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
@@ -59,17 +69,23 @@ func (usecase *ApiKeyUseCase) CreateApiKey(ctx context.Context, input models.Cre
 	key := generateAPiKey()
 	hash := sha256.Sum256([]byte(key))
 	apiKey := models.ApiKey{
-		Id:             apiKeyId,
-		Description:    input.Description,
-		Hash:           hash[:],
-		Prefix:         key[:3],
-		OrganizationId: input.OrganizationId,
-		Role:           input.Role,
+		Id:              apiKeyId,
+		Description:     input.Description,
+		Hash:            hash[:],
+		Prefix:          key[:3],
+		OrganizationId:  input.OrganizationId,
+		Role:            input.Role,
+		ExpiresAt:       input.ExpiresAt,
+		Scope:           input.Scope,
+		AllowedNetworks: input.AllowedNetworks,
 	}
 
 	if err := usecase.enforceSecurity.CreateApiKey(input.OrganizationId); err != nil {
 		return models.CreatedApiKey{}, err
 	}
+	if err := input.Validate(time.Now()); err != nil {
+		return models.CreatedApiKey{}, err
+	}
 
 	if input.Role != models.API_CLIENT {
 		return models.CreatedApiKey{}, errors.Wrap(
@@ -78,11 +94,12 @@ func (usecase *ApiKeyUseCase) CreateApiKey(ctx context.Context, input models.Cre
 		)
 	}
 
-	err := usecase.apiKeyRepository.CreateApiKey(
-		ctx,
-		usecase.executorFactory.NewExecutor(),
-		apiKey,
-	)
+	exec := usecase.executorFactory.NewExecutor()
+	if err := usecase.validateScope(ctx, exec, input.OrganizationId, input.Scope); err != nil {
+		return models.CreatedApiKey{}, err
+	}
+
+	err := usecase.apiKeyRepository.CreateApiKey(ctx, exec, apiKey)
 	if err != nil {
 		return models.CreatedApiKey{}, err
 	}
@@ -97,6 +114,37 @@ func (usecase *ApiKeyUseCase) CreateApiKey(ctx context.Context, input models.Cre
 	}, nil
 }
 
+// validateScope checks that the scenarios and tables of the scope of a key exist in the organization
+func (usecase *ApiKeyUseCase) validateScope(ctx context.Context, exec repositories.Executor,
+	organizationId string, scope models.ApiKeyScope,
+) error {
+	if len(scope.ScenarioIds) > 0 {
+		scenarios, err := usecase.scenarioRepository.ListScenariosOfOrganization(ctx, exec, organizationId)
+		if err != nil {
+			return err
+		}
+		for _, scenarioId := range scope.ScenarioIds {
+			if !slices.ContainsFunc(scenarios, func(scenario models.Scenario) bool { return scenario.Id == scenarioId }) {
+				return errors.Wrap(models.BadParameterError, fmt.Sprintf("unknown scenario %s", scenarioId))
+			}
+		}
+	}
+
+	tableNames := slices.Concat(scope.TriggerObjectTypes, scope.IngestionTables)
+	if len(tableNames) > 0 {
+		dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, exec, organizationId, false)
+		if err != nil {
+			return err
+		}
+		for _, tableName := range tableNames {
+			if _, ok := dataModel.Tables[tableName]; !ok {
+				return errors.Wrap(models.BadParameterError, fmt.Sprintf("unknown table %s", tableName))
+			}
+		}
+	}
+	return nil
+}
+
 func generateAPiKey() string {
 	key := make([]byte, 32)
 	_, err := rand.Read(key)
@@ -127,3 +175,64 @@ func (usecase *ApiKeyUseCase) DeleteApiKey(ctx context.Context, apiKeyId string)
 	})
 	return nil
 }
+
+// RotateApiKey replaces an API key by a new key with the same role and restrictions. The replaced key is still
+// accepted during the overlap period, so that its clients can switch to the new key.
+func (usecase *ApiKeyUseCase) RotateApiKey(ctx context.Context, input models.RotateApiKeyInput) (models.CreatedApiKey, error) {
+	overlap, err := input.OverlapOrDefault()
+	if err != nil {
+		return models.CreatedApiKey{}, err
+	}
+
+	key := generateAPiKey()
+	hash := sha256.Sum256([]byte(key))
+	createdApiKey, err := executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.CreatedApiKey, error) {
+		apiKey, err := usecase.apiKeyRepository.GetApiKeyById(ctx, tx, input.ApiKeyId)
+		if err != nil {
+			return models.CreatedApiKey{}, err
+		}
+		if err := usecase.enforceSecurity.DeleteApiKey(apiKey); err != nil {
+			return models.CreatedApiKey{}, err
+		}
+		if err := usecase.enforceSecurity.CreateApiKey(apiKey.OrganizationId); err != nil {
+			return models.CreatedApiKey{}, err
+		}
+		now := time.Now()
+		if apiKey.IsExpired(now) {
+			return models.CreatedApiKey{}, errors.Wrap(models.BadParameterError, "an expired api key cannot be rotated")
+		}
+
+		newApiKey := models.ApiKey{
+			Id:              uuid.NewString(),
+			Description:     apiKey.Description,
+			Hash:            hash[:],
+			Prefix:          key[:3],
+			OrganizationId:  apiKey.OrganizationId,
+			PartnerId:       apiKey.PartnerId,
+			Role:            apiKey.Role,
+			CustomRoleId:    apiKey.CustomRoleId,
+			ExpiresAt:       apiKey.ExpiresAt,
+			Scope:           apiKey.Scope,
+			AllowedNetworks: apiKey.AllowedNetworks,
+			RotatedFromId:   &apiKey.Id,
+		}
+		if err := usecase.apiKeyRepository.CreateApiKey(ctx, tx, newApiKey); err != nil {
+			return models.CreatedApiKey{}, err
+		}
+		if err := usecase.apiKeyRepository.ExpireApiKey(ctx, tx, apiKey.Id, now.Add(overlap)); err != nil {
+			return models.CreatedApiKey{}, err
+		}
+		return models.CreatedApiKey{ApiKey: newApiKey, Key: key}, nil
+	})
+	if err != nil {
+		return models.CreatedApiKey{}, err
+	}
+
+	tracking.TrackEvent(ctx, models.AnalyticsApiKeyCreated, map[string]interface{}{
+		"api_key_id":      createdApiKey.Id,
+		"rotated_from_id": input.ApiKeyId,
+	})
+	return createdApiKey, nil
+}
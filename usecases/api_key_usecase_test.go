@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/mock"
@@ -15,10 +16,13 @@ import (
 
 type ApiKeyUsecaseTestSuite struct {
 	suite.Suite
-	enforceSecurity  *mocks.EnforceSecurity
-	transaction      *mocks.Executor
-	executorFactory  *mocks.ExecutorFactory
-	apiKeyRepository *mocks.ApiKeyRepository
+	enforceSecurity     *mocks.EnforceSecurity
+	transaction         *mocks.Executor
+	executorFactory     *mocks.ExecutorFactory
+	transactionFactory  *mocks.TransactionFactory
+	apiKeyRepository    *mocks.ApiKeyRepository
+	scenarioRepository  *mocks.ScenarioListRepository
+	dataModelRepository *mocks.DataModelRepository
 
 	organizationId  string
 	repositoryError error
@@ -28,8 +32,11 @@ type ApiKeyUsecaseTestSuite struct {
 func (suite *ApiKeyUsecaseTestSuite) SetupTest() {
 	suite.enforceSecurity = new(mocks.EnforceSecurity)
 	suite.apiKeyRepository = new(mocks.ApiKeyRepository)
+	suite.scenarioRepository = new(mocks.ScenarioListRepository)
+	suite.dataModelRepository = new(mocks.DataModelRepository)
 	suite.transaction = new(mocks.Executor)
 	suite.executorFactory = new(mocks.ExecutorFactory)
+	suite.transactionFactory = &mocks.TransactionFactory{ExecMock: suite.transaction}
 
 	suite.organizationId = "25ab6323-1657-4a52-923a-ef6983fe4532"
 
@@ -39,9 +46,12 @@ func (suite *ApiKeyUsecaseTestSuite) SetupTest() {
 
 func (suite *ApiKeyUsecaseTestSuite) makeUsecase() *ApiKeyUseCase {
 	return &ApiKeyUseCase{
-		apiKeyRepository: suite.apiKeyRepository,
-		enforceSecurity:  suite.enforceSecurity,
-		executorFactory:  suite.executorFactory,
+		apiKeyRepository:    suite.apiKeyRepository,
+		scenarioRepository:  suite.scenarioRepository,
+		dataModelRepository: suite.dataModelRepository,
+		enforceSecurity:     suite.enforceSecurity,
+		executorFactory:     suite.executorFactory,
+		transactionFactory:  suite.transactionFactory,
 		organizationIdOfContext: func() (string, error) {
 			return suite.organizationId, nil
 		},
@@ -51,8 +61,11 @@ func (suite *ApiKeyUsecaseTestSuite) makeUsecase() *ApiKeyUseCase {
 func (suite *ApiKeyUsecaseTestSuite) AssertExpectations() {
 	t := suite.T()
 	suite.apiKeyRepository.AssertExpectations(t)
+	suite.scenarioRepository.AssertExpectations(t)
+	suite.dataModelRepository.AssertExpectations(t)
 	suite.enforceSecurity.AssertExpectations(t)
 	suite.executorFactory.AssertExpectations(t)
+	suite.transactionFactory.AssertExpectations(t)
 }
 
 func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_nominal() {
@@ -79,6 +92,74 @@ func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_nominal() {
 	suite.AssertExpectations()
 }
 
+func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_with_scope() {
+	ctx := context.Background()
+	input := models.CreateApiKeyInput{
+		OrganizationId: suite.organizationId,
+		Description:    "test key", Role: models.API_CLIENT,
+		Scope: models.ApiKeyScope{
+			ScenarioIds:        []string{"scenario_id"},
+			TriggerObjectTypes: []string{"transactions"},
+			IngestionTables:    []string{"accounts"},
+		},
+	}
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.enforceSecurity.On("CreateApiKey", suite.organizationId).Return(nil)
+	suite.scenarioRepository.On("ListScenariosOfOrganization", ctx, suite.transaction, suite.organizationId).
+		Return([]models.Scenario{{Id: "scenario_id"}}, nil)
+	suite.dataModelRepository.On("GetDataModel", ctx, suite.transaction, suite.organizationId, false).
+		Return(models.DataModel{Tables: map[string]models.Table{
+			"transactions": {Name: "transactions"},
+			"accounts":     {Name: "accounts"},
+		}}, nil)
+	suite.apiKeyRepository.On("CreateApiKey", suite.transaction, mock.MatchedBy(func(apiKey models.ApiKey) bool {
+		return suite.Equal(input.Scope, apiKey.Scope)
+	})).Return(nil)
+
+	_, err := suite.makeUsecase().CreateApiKey(ctx, input)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_unknown_scenario() {
+	ctx := context.Background()
+	input := models.CreateApiKeyInput{
+		OrganizationId: suite.organizationId,
+		Description:    "test key", Role: models.API_CLIENT,
+		Scope: models.ApiKeyScope{ScenarioIds: []string{"other_scenario_id"}},
+	}
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.enforceSecurity.On("CreateApiKey", suite.organizationId).Return(nil)
+	suite.scenarioRepository.On("ListScenariosOfOrganization", ctx, suite.transaction, suite.organizationId).
+		Return([]models.Scenario{{Id: "scenario_id"}}, nil)
+
+	_, err := suite.makeUsecase().CreateApiKey(ctx, input)
+
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+	suite.apiKeyRepository.AssertNotCalled(suite.T(), "CreateApiKey", mock.Anything, mock.Anything)
+}
+
+func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_unknown_table() {
+	ctx := context.Background()
+	input := models.CreateApiKeyInput{
+		OrganizationId: suite.organizationId,
+		Description:    "test key", Role: models.API_CLIENT,
+		Scope: models.ApiKeyScope{IngestionTables: []string{"unknown_table"}},
+	}
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.enforceSecurity.On("CreateApiKey", suite.organizationId).Return(nil)
+	suite.dataModelRepository.On("GetDataModel", ctx, suite.transaction, suite.organizationId, false).
+		Return(models.DataModel{Tables: map[string]models.Table{"transactions": {Name: "transactions"}}}, nil)
+
+	_, err := suite.makeUsecase().CreateApiKey(ctx, input)
+
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+	suite.apiKeyRepository.AssertNotCalled(suite.T(), "CreateApiKey", mock.Anything, mock.Anything)
+}
+
 func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_bad_parameter() {
 	ctx := context.Background()
 	input := models.CreateApiKeyInput{
@@ -127,6 +208,57 @@ func (suite *ApiKeyUsecaseTestSuite) Test_CreateApiKey_repository_error() {
 	suite.AssertExpectations()
 }
 
+func (suite *ApiKeyUsecaseTestSuite) Test_RotateApiKey_nominal() {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(90 * 24 * time.Hour)
+	apiKey := models.ApiKey{
+		Id:             "api_key_id",
+		OrganizationId: suite.organizationId,
+		Description:    "test key",
+		Role:           models.API_CLIENT,
+		ExpiresAt:      &expiresAt,
+		Scope:          models.ApiKeyScope{IngestionTables: []string{"transactions"}},
+	}
+	overlap := time.Hour
+
+	suite.transactionFactory.On("Transaction", ctx, mock.Anything).Return(nil)
+	suite.apiKeyRepository.On("GetApiKeyById", suite.transaction, apiKey.Id).Return(apiKey, nil)
+	suite.enforceSecurity.On("DeleteApiKey", apiKey).Return(nil)
+	suite.enforceSecurity.On("CreateApiKey", suite.organizationId).Return(nil)
+	suite.apiKeyRepository.On("CreateApiKey", suite.transaction, mock.MatchedBy(func(newApiKey models.ApiKey) bool {
+		return newApiKey.Id != apiKey.Id &&
+			*newApiKey.RotatedFromId == apiKey.Id &&
+			newApiKey.ExpiresAt == apiKey.ExpiresAt &&
+			newApiKey.Scope.IngestionTables[0] == "transactions"
+	})).Return(nil)
+	suite.apiKeyRepository.On("ExpireApiKey", suite.transaction, apiKey.Id,
+		mock.MatchedBy(func(expiresAt time.Time) bool {
+			return time.Until(expiresAt) > 59*time.Minute && time.Until(expiresAt) <= time.Hour
+		})).Return(nil)
+
+	createdApiKey, err := suite.makeUsecase().RotateApiKey(ctx,
+		models.RotateApiKeyInput{ApiKeyId: apiKey.Id, Overlap: &overlap})
+
+	suite.NoError(err)
+	suite.Equal(64, len(createdApiKey.Key))
+	suite.Equal(createdApiKey.Key[:3], createdApiKey.Prefix)
+	suite.AssertExpectations()
+}
+
+func (suite *ApiKeyUsecaseTestSuite) Test_RotateApiKey_security_error() {
+	ctx := context.Background()
+	apiKey := models.ApiKey{Id: "api_key_id", OrganizationId: suite.organizationId, Role: models.API_CLIENT}
+
+	suite.transactionFactory.On("Transaction", ctx, mock.Anything).Return(nil)
+	suite.apiKeyRepository.On("GetApiKeyById", suite.transaction, apiKey.Id).Return(apiKey, nil)
+	suite.enforceSecurity.On("DeleteApiKey", apiKey).Return(suite.securityError)
+
+	_, err := suite.makeUsecase().RotateApiKey(ctx, models.RotateApiKeyInput{ApiKeyId: apiKey.Id})
+
+	suite.ErrorIs(err, suite.securityError)
+	suite.AssertExpectations()
+}
+
 func TestApiKeyUsecase(t *testing.T) {
 	suite.Run(t, new(ApiKeyUsecaseTestSuite))
 }
@@ -38,3 +38,26 @@ func (evaluator *EvaluateAstExpression) EvaluateAstExpression(
 	}
 	return returnValue, evaluation, nil
 }
+
+// EvaluateAstExpressionValue evaluates an expression that can return a value of any type, as the output variables
+// of a scenario iteration
+func (evaluator *EvaluateAstExpression) EvaluateAstExpressionValue(
+	ctx context.Context,
+	astExpression ast.Node,
+	organizationId string,
+	payload models.ClientObject,
+	dataModel models.DataModel,
+) (any, ast.NodeEvaluation, error) {
+	environment := evaluator.AstEvaluationEnvironmentFactory(EvaluationEnvironmentFactoryParams{
+		OrganizationId:                organizationId,
+		ClientObject:                  payload,
+		DataModel:                     dataModel,
+		DatabaseAccessReturnFakeValue: false,
+	})
+
+	evaluation, ok := EvaluateAst(ctx, environment, astExpression)
+	if !ok {
+		return nil, evaluation, errors.Join(evaluation.FlattenErrors()...)
+	}
+	return evaluation.ReturnValue, evaluation, nil
+}
@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type AuditEventRepository interface {
+	ListAuditEvents(ctx context.Context, exec repositories.Executor, organizationId string,
+		filters models.AuditEventFilters, pagination models.PaginationAndSorting) ([]models.AuditEvent, error)
+}
+
+// AuditEventUsecase reads the changes of the configuration of an organization, recorded by the audit triggers of the
+// database with the user or the API key who made them
+type AuditEventUsecase struct {
+	enforceSecurity security.EnforceSecurityOrganization
+	executorFactory executor_factory.ExecutorFactory
+	repository      AuditEventRepository
+}
+
+// ListAuditEvents returns a page of audit events, and whether there are more events after it
+func (usecase *AuditEventUsecase) ListAuditEvents(ctx context.Context, organizationId string,
+	filters models.AuditEventFilters, pagination models.PaginationAndSorting,
+) ([]models.AuditEvent, bool, error) {
+	if err := usecase.enforceSecurity.ReadAuditEvents(organizationId); err != nil {
+		return nil, false, err
+	}
+	if err := validateAuditEventsQuery(filters, pagination); err != nil {
+		return nil, false, err
+	}
+
+	// one more event is read to know if there is a next page
+	limit := pagination.Limit
+	pagination.Limit++
+	events, err := usecase.repository.ListAuditEvents(ctx, usecase.executorFactory.NewExecutor(),
+		organizationId, filters, pagination)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) <= limit {
+		return events, false, nil
+	}
+	if pagination.Previous {
+		return events[1:], true, nil
+	}
+	return events[:limit], true, nil
+}
+
+// ExportAuditEvents writes all the audit events matching the filters to dest, as newline delimited JSON, from the most
+// recent. It returns the number of exported events.
+func (usecase *AuditEventUsecase) ExportAuditEvents(ctx context.Context, organizationId string,
+	filters models.AuditEventFilters, dest io.Writer,
+) (int, error) {
+	if err := usecase.enforceSecurity.ReadAuditEvents(organizationId); err != nil {
+		return 0, err
+	}
+	if err := filters.Validate(); err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(dest)
+	pagination := models.PaginationAndSorting{
+		Order: models.SortingOrderDesc,
+		Limit: models.AUDIT_EVENTS_EXPORT_BATCH_SIZE,
+		Next:  true,
+	}
+	exported := 0
+	for {
+		events, err := usecase.repository.ListAuditEvents(ctx, usecase.executorFactory.NewExecutor(),
+			organizationId, filters, pagination)
+		if err != nil {
+			return exported, err
+		}
+		for _, event := range events {
+			if err := encoder.Encode(dto.AdaptAuditEvent(event)); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if len(events) < pagination.Limit {
+			return exported, nil
+		}
+		pagination.OffsetId = events[len(events)-1].Id
+	}
+}
+
+func validateAuditEventsQuery(filters models.AuditEventFilters, pagination models.PaginationAndSorting) error {
+	if err := filters.Validate(); err != nil {
+		return err
+	}
+	if err := models.ValidatePagination(pagination); err != nil {
+		return err
+	}
+	if pagination.OffsetId != "" {
+		return utils.ValidateUuid(pagination.OffsetId)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+)
+
+func makeAuditEvents(n int) []models.AuditEvent {
+	events := make([]models.AuditEvent, n)
+	for i := range events {
+		events[i] = models.AuditEvent{
+			Id:        fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+			Operation: models.AuditOperationUpdate,
+			Table:     "scenarios",
+			Data:      json.RawMessage(`{}`),
+		}
+	}
+	return events
+}
+
+func newAuditEventUsecaseForTest() (AuditEventUsecase, *mocks.AuditEventRepository, *mocks.Executor) {
+	exec := new(mocks.Executor)
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(exec)
+	enforceSecurity := new(mocks.EnforceSecurity)
+	enforceSecurity.On("ReadAuditEvents", "organization_id").Return(nil)
+	repository := new(mocks.AuditEventRepository)
+
+	return AuditEventUsecase{
+		enforceSecurity: enforceSecurity,
+		executorFactory: executorFactory,
+		repository:      repository,
+	}, repository, exec
+}
+
+func TestAuditEventUsecase_ListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	filters := models.AuditEventFilters{Tables: []string{"scenarios"}}
+	pagination := models.PaginationAndSorting{Order: models.SortingOrderDesc, Limit: 2}
+	events := makeAuditEvents(3)
+
+	t.Run("next page", func(t *testing.T) {
+		usecase, repository, exec := newAuditEventUsecaseForTest()
+		readPagination := pagination
+		readPagination.Limit = 3
+		repository.On("ListAuditEvents", exec, "organization_id", filters, readPagination).Return(events, nil)
+
+		page, hasMore, err := usecase.ListAuditEvents(ctx, "organization_id", filters, pagination)
+		assert.NoError(t, err)
+		assert.True(t, hasMore)
+		assert.Equal(t, events[:2], page)
+	})
+
+	t.Run("previous page", func(t *testing.T) {
+		usecase, repository, exec := newAuditEventUsecaseForTest()
+		previous := pagination
+		previous.OffsetId = events[0].Id
+		previous.Previous = true
+		readPagination := previous
+		readPagination.Limit = 3
+		repository.On("ListAuditEvents", exec, "organization_id", filters, readPagination).Return(events, nil)
+
+		page, hasMore, err := usecase.ListAuditEvents(ctx, "organization_id", filters, previous)
+		assert.NoError(t, err)
+		assert.True(t, hasMore)
+		assert.Equal(t, events[1:], page)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		usecase, repository, exec := newAuditEventUsecaseForTest()
+		readPagination := pagination
+		readPagination.Limit = 3
+		repository.On("ListAuditEvents", exec, "organization_id", filters, readPagination).Return(events[:1], nil)
+
+		page, hasMore, err := usecase.ListAuditEvents(ctx, "organization_id", filters, pagination)
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Equal(t, events[:1], page)
+	})
+
+	t.Run("table not audited", func(t *testing.T) {
+		usecase, _, _ := newAuditEventUsecaseForTest()
+		_, _, err := usecase.ListAuditEvents(ctx, "organization_id",
+			models.AuditEventFilters{Tables: []string{"decisions"}}, pagination)
+		assert.ErrorIs(t, err, models.BadParameterError)
+	})
+}
+
+func TestAuditEventUsecase_ExportAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	usecase, repository, exec := newAuditEventUsecaseForTest()
+	events := makeAuditEvents(models.AUDIT_EVENTS_EXPORT_BATCH_SIZE + 1)
+
+	pagination := models.PaginationAndSorting{
+		Order: models.SortingOrderDesc,
+		Limit: models.AUDIT_EVENTS_EXPORT_BATCH_SIZE,
+		Next:  true,
+	}
+	repository.On("ListAuditEvents", exec, "organization_id", models.AuditEventFilters{}, pagination).
+		Return(events[:models.AUDIT_EVENTS_EXPORT_BATCH_SIZE], nil)
+	pagination.OffsetId = events[models.AUDIT_EVENTS_EXPORT_BATCH_SIZE-1].Id
+	repository.On("ListAuditEvents", exec, "organization_id", models.AuditEventFilters{}, pagination).
+		Return(events[models.AUDIT_EVENTS_EXPORT_BATCH_SIZE:], nil)
+
+	var buffer bytes.Buffer
+	exported, err := usecase.ExportAuditEvents(ctx, "organization_id", models.AuditEventFilters{}, &buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, len(events), exported)
+	assert.Equal(t, len(events), strings.Count(buffer.String(), "\n"))
+	repository.AssertExpectations(t)
+}
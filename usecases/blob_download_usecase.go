@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+// BlobDownloadUsecase serves the files of the local blob storage backend, whose download urls point to the backend.
+// Access is granted by the signature of the url, not by the credentials of the caller.
+type BlobDownloadUsecase struct {
+	blobRepository repositories.BlobRepository
+	urlSigner      *repositories.BlobUrlSigner
+}
+
+func (usecase BlobDownloadUsecase) OpenSignedBlob(
+	ctx context.Context,
+	bucketName, fileName string,
+	expiresAt int64,
+	signature string,
+) (models.Blob, error) {
+	if usecase.urlSigner == nil {
+		return models.Blob{}, errors.Wrap(models.NotFoundError, "files are not served by the backend with this storage backend")
+	}
+	if err := usecase.urlSigner.Verify(bucketName, fileName, expiresAt, signature); err != nil {
+		return models.Blob{}, err
+	}
+	return usecase.blobRepository.GetFile(ctx, bucketName, fileName)
+}
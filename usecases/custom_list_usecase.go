@@ -10,7 +10,6 @@ import (
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
 	"github.com/checkmarble/marble-backend/usecases/security"
 	"github.com/checkmarble/marble-backend/usecases/tracking"
-	"github.com/checkmarble/marble-backend/utils"
 )
 
 type CustomListUseCase struct {
@@ -155,12 +154,6 @@ func (usecase *CustomListUseCase) GetCustomListValues(ctx context.Context,
 func (usecase *CustomListUseCase) AddCustomListValue(ctx context.Context,
 	addCustomListValue models.AddCustomListValueInput,
 ) (models.CustomListValue, error) {
-	var userId *models.UserId
-	creds, found := utils.CredentialsFromCtx(ctx)
-	if found {
-		userId = &creds.ActorIdentity.UserId
-	}
-
 	value, err := executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
 		tx repositories.Executor,
 	) (models.CustomListValue, error) {
@@ -173,7 +166,7 @@ func (usecase *CustomListUseCase) AddCustomListValue(ctx context.Context,
 		}
 		newCustomListValueId := uuid.NewString()
 
-		err = usecase.CustomListRepository.AddCustomListValue(ctx, tx, addCustomListValue, newCustomListValueId, userId)
+		err = usecase.CustomListRepository.AddCustomListValue(ctx, tx, addCustomListValue, newCustomListValueId)
 		if err != nil {
 			return models.CustomListValue{}, err
 		}
@@ -193,12 +186,6 @@ func (usecase *CustomListUseCase) AddCustomListValue(ctx context.Context,
 func (usecase *CustomListUseCase) DeleteCustomListValue(ctx context.Context,
 	deleteCustomListValue models.DeleteCustomListValueInput,
 ) error {
-	var userId *models.UserId
-	creds, found := utils.CredentialsFromCtx(ctx)
-	if found {
-		userId = &creds.ActorIdentity.UserId
-	}
-
 	err := usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
 		customList, err := usecase.CustomListRepository.GetCustomListById(ctx, tx, deleteCustomListValue.CustomListId)
 		if err != nil {
@@ -207,7 +194,7 @@ func (usecase *CustomListUseCase) DeleteCustomListValue(ctx context.Context,
 		if err := usecase.enforceSecurity.ModifyCustomList(customList); err != nil {
 			return err
 		}
-		return usecase.CustomListRepository.DeleteCustomListValue(ctx, tx, deleteCustomListValue, userId)
+		return usecase.CustomListRepository.DeleteCustomListValue(ctx, tx, deleteCustomListValue)
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type CustomRoleRepository interface {
+	GetCustomRoleById(ctx context.Context, exec repositories.Executor, customRoleId string) (models.CustomRole, error)
+	ListCustomRoles(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.CustomRole, error)
+	CreateCustomRole(ctx context.Context, exec repositories.Executor, input models.CreateCustomRoleInput, newCustomRoleId string) error
+	UpdateCustomRole(ctx context.Context, exec repositories.Executor, input models.UpdateCustomRoleInput) error
+	DeleteCustomRole(ctx context.Context, exec repositories.Executor, customRoleId string) error
+	CountCustomRoleAssignments(ctx context.Context, exec repositories.Executor, customRoleId string) (int, error)
+	SetUserCustomRole(ctx context.Context, exec repositories.Executor, userId models.UserId, customRoleId *string) error
+	SetApiKeyCustomRole(ctx context.Context, exec repositories.Executor, apiKeyId string, customRoleId *string) error
+	GetApiKeyById(ctx context.Context, exec repositories.Executor, apiKeyId string) (models.ApiKey, error)
+}
+
+// CustomRoleUsecase lets the admins of an organization define roles from the permission catalog, and give them to
+// their users and API keys. The permissions are carried by the tokens, so a change applies to the next tokens.
+type CustomRoleUsecase struct {
+	enforceSecurity    security.EnforceSecurityOrganization
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         CustomRoleRepository
+	userRepository     repositories.UserRepository
+}
+
+func (usecase *CustomRoleUsecase) ListCustomRoles(ctx context.Context, organizationId string) ([]models.CustomRole, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return nil, err
+	}
+	return usecase.repository.ListCustomRoles(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+}
+
+func (usecase *CustomRoleUsecase) GetCustomRole(ctx context.Context, organizationId, customRoleId string) (models.CustomRole, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return models.CustomRole{}, err
+	}
+	return customRoleOfOrganization(ctx, usecase.executorFactory.NewExecutor(), usecase.repository,
+		organizationId, customRoleId)
+}
+
+func (usecase *CustomRoleUsecase) CreateCustomRole(ctx context.Context, input models.CreateCustomRoleInput) (models.CustomRole, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(input.OrganizationId); err != nil {
+		return models.CustomRole{}, err
+	}
+	if err := input.Validate(); err != nil {
+		return models.CustomRole{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.CustomRole, error) {
+		customRoleId := uuid.NewString()
+		err := usecase.repository.CreateCustomRole(ctx, tx, input, customRoleId)
+		if repositories.IsUniqueViolationError(err) {
+			return models.CustomRole{}, errors.Wrap(models.ConflictError, "a custom role with this name already exists")
+		}
+		if err != nil {
+			return models.CustomRole{}, err
+		}
+		return usecase.repository.GetCustomRoleById(ctx, tx, customRoleId)
+	})
+}
+
+func (usecase *CustomRoleUsecase) UpdateCustomRole(ctx context.Context, organizationId string,
+	input models.UpdateCustomRoleInput,
+) (models.CustomRole, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return models.CustomRole{}, err
+	}
+	if err := input.Validate(); err != nil {
+		return models.CustomRole{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.CustomRole, error) {
+		if _, err := customRoleOfOrganization(ctx, tx, usecase.repository, organizationId, input.Id); err != nil {
+			return models.CustomRole{}, err
+		}
+		err := usecase.repository.UpdateCustomRole(ctx, tx, input)
+		if repositories.IsUniqueViolationError(err) {
+			return models.CustomRole{}, errors.Wrap(models.ConflictError, "a custom role with this name already exists")
+		}
+		if err != nil {
+			return models.CustomRole{}, err
+		}
+		return usecase.repository.GetCustomRoleById(ctx, tx, input.Id)
+	})
+}
+
+// DeleteCustomRole deletes a custom role which is given to no user nor API key
+func (usecase *CustomRoleUsecase) DeleteCustomRole(ctx context.Context, organizationId, customRoleId string) error {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return err
+	}
+
+	return usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		if _, err := customRoleOfOrganization(ctx, tx, usecase.repository, organizationId, customRoleId); err != nil {
+			return err
+		}
+		count, err := usecase.repository.CountCustomRoleAssignments(ctx, tx, customRoleId)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.Wrapf(models.ConflictError,
+				"the custom role is given to %d users or API keys", count)
+		}
+		return usecase.repository.DeleteCustomRole(ctx, tx, customRoleId)
+	})
+}
+
+// SetUserCustomRole gives a custom role to a user of the organization, or gives them back the permissions of their
+// built-in role if customRoleId is nil
+func (usecase *CustomRoleUsecase) SetUserCustomRole(ctx context.Context, organizationId string,
+	userId models.UserId, customRoleId *string,
+) (models.User, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return models.User{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.User, error) {
+		user, err := usecase.userRepository.UserByID(ctx, tx, userId)
+		if err != nil {
+			return models.User{}, err
+		}
+		if user.OrganizationId != organizationId {
+			return models.User{}, errors.Wrap(models.NotFoundError, "user not found in this organization")
+		}
+		if customRoleId != nil {
+			if _, err := customRoleOfOrganization(ctx, tx, usecase.repository, organizationId, *customRoleId); err != nil {
+				return models.User{}, err
+			}
+		}
+		if err := usecase.repository.SetUserCustomRole(ctx, tx, userId, customRoleId); err != nil {
+			return models.User{}, err
+		}
+		return usecase.userRepository.UserByID(ctx, tx, userId)
+	})
+}
+
+// SetApiKeyCustomRole gives a custom role to an API key of the organization, or gives it back the permissions of its
+// built-in role if customRoleId is nil
+func (usecase *CustomRoleUsecase) SetApiKeyCustomRole(ctx context.Context, organizationId string,
+	apiKeyId string, customRoleId *string,
+) (models.ApiKey, error) {
+	if err := usecase.enforceSecurity.ManageCustomRoles(organizationId); err != nil {
+		return models.ApiKey{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ApiKey, error) {
+		apiKey, err := usecase.repository.GetApiKeyById(ctx, tx, apiKeyId)
+		if err != nil {
+			return models.ApiKey{}, err
+		}
+		if apiKey.OrganizationId != organizationId {
+			return models.ApiKey{}, errors.Wrap(models.NotFoundError, "API key not found in this organization")
+		}
+		if customRoleId != nil {
+			if _, err := customRoleOfOrganization(ctx, tx, usecase.repository, organizationId, *customRoleId); err != nil {
+				return models.ApiKey{}, err
+			}
+		}
+		if err := usecase.repository.SetApiKeyCustomRole(ctx, tx, apiKeyId, customRoleId); err != nil {
+			return models.ApiKey{}, err
+		}
+		return usecase.repository.GetApiKeyById(ctx, tx, apiKeyId)
+	})
+}
+
+func customRoleOfOrganization(ctx context.Context, exec repositories.Executor, repository CustomRoleRepository,
+	organizationId, customRoleId string,
+) (models.CustomRole, error) {
+	customRole, err := repository.GetCustomRoleById(ctx, exec, customRoleId)
+	if err != nil {
+		return models.CustomRole{}, err
+	}
+	if customRole.OrganizationId != organizationId {
+		return models.CustomRole{}, errors.Wrap(models.NotFoundError, "custom role not found in this organization")
+	}
+	return customRole, nil
+}
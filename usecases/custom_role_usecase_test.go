@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type CustomRoleUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity *mocks.EnforceSecurity
+	repository      *mocks.CustomRoleRepository
+	userRepository  *mocks.UserRepository
+	transaction     *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	customRole     models.CustomRole
+	otherRole      models.CustomRole
+	user           models.User
+	apiKey         models.ApiKey
+}
+
+func (suite *CustomRoleUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.CustomRoleRepository)
+	suite.userRepository = new(mocks.UserRepository)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "organization_id"
+	suite.customRole = models.CustomRole{
+		Id:             "custom_role_id",
+		OrganizationId: suite.organizationId,
+		Name:           "Investigator",
+		Permissions:    []models.Permission{models.DECISION_READ},
+	}
+	suite.otherRole = models.CustomRole{Id: "other_role_id", OrganizationId: "other_organization_id", Name: "Other"}
+	suite.user = models.User{UserId: "user_id", OrganizationId: suite.organizationId, Role: models.VIEWER}
+	suite.apiKey = models.ApiKey{Id: "api_key_id", OrganizationId: suite.organizationId, Role: models.API_CLIENT}
+
+	suite.enforceSecurity.On("ManageCustomRoles", suite.organizationId).Return(nil)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) makeUsecase() *CustomRoleUsecase {
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &CustomRoleUsecase{
+		enforceSecurity:    suite.enforceSecurity,
+		transactionFactory: transactionFactory,
+		repository:         suite.repository,
+		userRepository:     suite.userRepository,
+	}
+}
+
+func (suite *CustomRoleUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.userRepository.AssertExpectations(t)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestUpdateCustomRole_other_organization() {
+	input := models.UpdateCustomRoleInput{Id: suite.otherRole.Id, Name: utils.Ptr("Renamed")}
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.otherRole.Id).Return(suite.otherRole, nil)
+
+	_, err := suite.makeUsecase().UpdateCustomRole(suite.ctx, suite.organizationId, input)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "UpdateCustomRole", mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestDeleteCustomRole() {
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.customRole.Id).Return(suite.customRole, nil)
+	suite.repository.On("CountCustomRoleAssignments", suite.transaction, suite.customRole.Id).Return(0, nil)
+	suite.repository.On("DeleteCustomRole", suite.transaction, suite.customRole.Id).Return(nil)
+
+	err := suite.makeUsecase().DeleteCustomRole(suite.ctx, suite.organizationId, suite.customRole.Id)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestDeleteCustomRole_assigned() {
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.customRole.Id).Return(suite.customRole, nil)
+	suite.repository.On("CountCustomRoleAssignments", suite.transaction, suite.customRole.Id).Return(2, nil)
+
+	err := suite.makeUsecase().DeleteCustomRole(suite.ctx, suite.organizationId, suite.customRole.Id)
+
+	suite.ErrorIs(err, models.ConflictError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "DeleteCustomRole", mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestDeleteCustomRole_other_organization() {
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.otherRole.Id).Return(suite.otherRole, nil)
+
+	err := suite.makeUsecase().DeleteCustomRole(suite.ctx, suite.organizationId, suite.otherRole.Id)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "CountCustomRoleAssignments", mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetUserCustomRole() {
+	updated := suite.user
+	updated.CustomRoleId = &suite.customRole.Id
+	suite.userRepository.On("UserByID", suite.transaction, suite.user.UserId).Return(suite.user, nil).Once()
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.customRole.Id).Return(suite.customRole, nil)
+	suite.repository.On("SetUserCustomRole", suite.transaction, suite.user.UserId, &suite.customRole.Id).Return(nil)
+	suite.userRepository.On("UserByID", suite.transaction, suite.user.UserId).Return(updated, nil).Once()
+
+	user, err := suite.makeUsecase().SetUserCustomRole(suite.ctx, suite.organizationId, suite.user.UserId,
+		&suite.customRole.Id)
+
+	suite.NoError(err)
+	suite.Equal(updated, user)
+	suite.AssertExpectations()
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetUserCustomRole_removed() {
+	// the user gets back the permissions of their role, no custom role is read
+	suite.userRepository.On("UserByID", suite.transaction, suite.user.UserId).Return(suite.user, nil)
+	suite.repository.On("SetUserCustomRole", suite.transaction, suite.user.UserId, (*string)(nil)).Return(nil)
+
+	_, err := suite.makeUsecase().SetUserCustomRole(suite.ctx, suite.organizationId, suite.user.UserId, nil)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetUserCustomRole_user_of_other_organization() {
+	otherUser := suite.user
+	otherUser.OrganizationId = "other_organization_id"
+	suite.userRepository.On("UserByID", suite.transaction, suite.user.UserId).Return(otherUser, nil)
+
+	_, err := suite.makeUsecase().SetUserCustomRole(suite.ctx, suite.organizationId, suite.user.UserId,
+		&suite.customRole.Id)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "SetUserCustomRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetUserCustomRole_role_of_other_organization() {
+	suite.userRepository.On("UserByID", suite.transaction, suite.user.UserId).Return(suite.user, nil)
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.otherRole.Id).Return(suite.otherRole, nil)
+
+	_, err := suite.makeUsecase().SetUserCustomRole(suite.ctx, suite.organizationId, suite.user.UserId,
+		&suite.otherRole.Id)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "SetUserCustomRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetApiKeyCustomRole() {
+	updated := suite.apiKey
+	updated.CustomRoleId = &suite.customRole.Id
+	suite.repository.On("GetApiKeyById", suite.transaction, suite.apiKey.Id).Return(suite.apiKey, nil).Once()
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.customRole.Id).Return(suite.customRole, nil)
+	suite.repository.On("SetApiKeyCustomRole", suite.transaction, suite.apiKey.Id, &suite.customRole.Id).Return(nil)
+	suite.repository.On("GetApiKeyById", suite.transaction, suite.apiKey.Id).Return(updated, nil).Once()
+
+	apiKey, err := suite.makeUsecase().SetApiKeyCustomRole(suite.ctx, suite.organizationId, suite.apiKey.Id,
+		&suite.customRole.Id)
+
+	suite.NoError(err)
+	suite.Equal(updated, apiKey)
+	suite.AssertExpectations()
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetApiKeyCustomRole_key_of_other_organization() {
+	otherKey := suite.apiKey
+	otherKey.OrganizationId = "other_organization_id"
+	suite.repository.On("GetApiKeyById", suite.transaction, suite.apiKey.Id).Return(otherKey, nil)
+
+	_, err := suite.makeUsecase().SetApiKeyCustomRole(suite.ctx, suite.organizationId, suite.apiKey.Id,
+		&suite.customRole.Id)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "SetApiKeyCustomRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CustomRoleUsecaseTestSuite) TestSetApiKeyCustomRole_role_of_other_organization() {
+	suite.repository.On("GetApiKeyById", suite.transaction, suite.apiKey.Id).Return(suite.apiKey, nil)
+	suite.repository.On("GetCustomRoleById", suite.transaction, suite.otherRole.Id).Return(suite.otherRole, nil)
+
+	_, err := suite.makeUsecase().SetApiKeyCustomRole(suite.ctx, suite.organizationId, suite.apiKey.Id,
+		&suite.otherRole.Id)
+
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "SetApiKeyCustomRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCustomRoleUsecase(t *testing.T) {
+	suite.Run(t, new(CustomRoleUsecaseTestSuite))
+}
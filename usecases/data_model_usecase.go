@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories"
@@ -20,6 +21,15 @@ type DataModelUseCase struct {
 	executorFactory              executor_factory.ExecutorFactory
 	organizationSchemaRepository repositories.OrganizationSchemaRepository
 	transactionFactory           executor_factory.TransactionFactory
+	scenarioRepository           DataModelScenarioRepository
+}
+
+// DataModelScenarioRepository reads the scenarios using the data model, whose elements cannot be deleted while they
+// are used
+type DataModelScenarioRepository interface {
+	ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.Scenario, error)
+	ListScenarioIterations(ctx context.Context, exec repositories.Executor, organizationId string,
+		filters models.GetScenarioIterationFilters) ([]models.ScenarioIteration, error)
 }
 
 var (
@@ -381,6 +391,170 @@ func (usecase *DataModelUseCase) DeleteDataModel(ctx context.Context, organizati
 	})
 }
 
+// DeleteDataModelTable deletes the table, its fields, links and pivot, and drops or archives the table of ingested
+// data. Nothing is deleted if the table is used: the elements using it are returned instead. The usages are read in
+// the transaction of the deletion.
+func (usecase *DataModelUseCase) DeleteDataModelTable(ctx context.Context, tableID string,
+	archive bool,
+) ([]models.DataModelDependency, error) {
+	tableMetadata, err := usecase.dataModelRepository.GetDataModelTable(ctx,
+		usecase.executorFactory.NewExecutor(), tableID)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := tableMetadata.OrganizationID
+	if err := usecase.enforceSecurity.WriteDataModel(organizationID); err != nil {
+		return nil, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) ([]models.DataModelDependency, error) {
+		dataModel, usages, err := usecase.dataModelUsages(ctx, tx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		dependencies := models.TableDependencies(usages, dataModel.Tables[tableMetadata.Name])
+		if len(dependencies) > 0 {
+			return dependencies, nil
+		}
+
+		if err := usecase.dataModelRepository.DeleteDataModelTable(ctx, tx, tableID); err != nil {
+			return nil, err
+		}
+
+		// if it returns an error, automatically rolls back the other transaction
+		return dependencies, usecase.transactionFactory.TransactionInOrgSchema(ctx, organizationID, func(
+			orgTx repositories.Executor,
+		) error {
+			if archive {
+				return usecase.organizationSchemaRepository.ArchiveTable(ctx, orgTx,
+					tableMetadata.Name, archivedName(tableMetadata.Name, time.Now()))
+			}
+			return usecase.organizationSchemaRepository.DeleteTable(ctx, orgTx, tableMetadata.Name)
+		})
+	})
+}
+
+// DeleteDataModelField deletes the field and drops or archives its column in the table of ingested data. Nothing is
+// deleted if the field is used: the elements using it are returned instead. The usages are read in the transaction of
+// the deletion.
+func (usecase *DataModelUseCase) DeleteDataModelField(ctx context.Context, fieldID string,
+	archive bool,
+) ([]models.DataModelDependency, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	field, err := usecase.dataModelRepository.GetDataModelField(ctx, exec, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	tableMetadata, err := usecase.dataModelRepository.GetDataModelTable(ctx, exec, field.TableId)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := tableMetadata.OrganizationID
+	if err := usecase.enforceSecurity.WriteDataModel(organizationID); err != nil {
+		return nil, err
+	}
+	if slices.Contains(models.DATA_MODEL_DEFAULT_FIELDS, field.Name) {
+		return nil, errors.Wrapf(models.BadParameterError, "the %s field is required and cannot be deleted", field.Name)
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) ([]models.DataModelDependency, error) {
+		dataModel, usages, err := usecase.dataModelUsages(ctx, tx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		dependencies := models.FieldDependencies(usages, dataModel.Tables[tableMetadata.Name].Fields[field.Name])
+		if len(dependencies) > 0 {
+			return dependencies, nil
+		}
+
+		if err := usecase.dataModelRepository.DeleteDataModelField(ctx, tx, fieldID); err != nil {
+			return nil, err
+		}
+
+		// if it returns an error, automatically rolls back the other transaction
+		return dependencies, usecase.transactionFactory.TransactionInOrgSchema(ctx, organizationID, func(
+			orgTx repositories.Executor,
+		) error {
+			if archive {
+				return usecase.organizationSchemaRepository.ArchiveField(ctx, orgTx,
+					tableMetadata.Name, field.Name, archivedName(field.Name, time.Now()))
+			}
+			return usecase.organizationSchemaRepository.DeleteField(ctx, orgTx, tableMetadata.Name, field.Name)
+		})
+	})
+}
+
+// DeleteDataModelLink deletes the link, unless it is used: the elements using it are returned instead. The usages are
+// read in the transaction of the deletion.
+func (usecase *DataModelUseCase) DeleteDataModelLink(ctx context.Context, organizationID, linkID string) ([]models.DataModelDependency, error) {
+	if err := usecase.enforceSecurity.WriteDataModel(organizationID); err != nil {
+		return nil, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) ([]models.DataModelDependency, error) {
+		dataModel, usages, err := usecase.dataModelUsages(ctx, tx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		link, ok := dataModel.AllLinksAsMap()[linkID]
+		if !ok {
+			return nil, errors.Wrapf(models.NotFoundError, "link %s not found", linkID)
+		}
+		dependencies := models.LinkDependencies(usages, link)
+		if len(dependencies) > 0 {
+			return dependencies, nil
+		}
+
+		return dependencies, usecase.dataModelRepository.DeleteDataModelLink(ctx, tx, linkID)
+	})
+}
+
+// dataModelUsages returns the data model with its usages by the scenarios, the pivots and the links
+func (usecase *DataModelUseCase) dataModelUsages(ctx context.Context, exec repositories.Executor,
+	organizationID string,
+) (models.DataModel, []models.DataModelUsage, error) {
+	dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, exec, organizationID, false)
+	if err != nil {
+		return models.DataModel{}, nil, err
+	}
+	pivotsMeta, err := usecase.dataModelRepository.ListPivots(ctx, exec, organizationID, nil)
+	if err != nil {
+		return models.DataModel{}, nil, err
+	}
+	pivots := make([]models.Pivot, len(pivotsMeta))
+	for i, pivot := range pivotsMeta {
+		pivots[i] = models.AdaptPivot(pivot, dataModel)
+	}
+	scenarios, err := usecase.scenarioRepository.ListScenariosOfOrganization(ctx, exec, organizationID)
+	if err != nil {
+		return models.DataModel{}, nil, err
+	}
+	iterations, err := usecase.scenarioRepository.ListScenarioIterations(ctx, exec, organizationID,
+		models.GetScenarioIterationFilters{})
+	if err != nil {
+		return models.DataModel{}, nil, err
+	}
+
+	return dataModel, models.DataModelUsages(dataModel, pivots, scenarios, iterations), nil
+}
+
+// archivedName is the name under which a table or column removed from the data model is kept, within the 63
+// characters limit of postgresql identifiers. A random part keeps apart the archives of the elements with the same name
+// removed within the same second, e.g. a field removed again right after its creation.
+func archivedName(name string, archivedAt time.Time) string {
+	suffix := "_archived_" + archivedAt.UTC().Format("20060102150405") + "_" + uuid.NewString()[:8]
+	if len(name)+len(suffix) > 63 {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
 // data model pivot methods
 
 func (usecase *DataModelUseCase) CreatePivot(ctx context.Context, input models.CreatePivotInput) (models.Pivot, error) {
@@ -2,10 +2,13 @@ package usecases
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/checkmarble/marble-backend/mocks"
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
 	"github.com/checkmarble/marble-backend/utils"
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/mock"
@@ -19,6 +22,7 @@ type DatamodelUsecaseTestSuite struct {
 	executorFactory              *mocks.ExecutorFactory
 	dataModelRepository          *mocks.DataModelRepository
 	organizationSchemaRepository *mocks.OrganizationSchemaRepository
+	scenarioRepository           *mocks.DataModelScenarioRepository
 	transaction                  *mocks.Executor
 	transactionFactory           *mocks.TransactionFactory
 
@@ -38,6 +42,7 @@ func (suite *DatamodelUsecaseTestSuite) SetupTest() {
 	suite.executorFactory = new(mocks.ExecutorFactory)
 	suite.dataModelRepository = new(mocks.DataModelRepository)
 	suite.organizationSchemaRepository = new(mocks.OrganizationSchemaRepository)
+	suite.scenarioRepository = new(mocks.DataModelScenarioRepository)
 	suite.transaction = new(mocks.Executor)
 	suite.transactionFactory = &mocks.TransactionFactory{ExecMock: suite.transaction}
 
@@ -201,6 +206,7 @@ func (suite *DatamodelUsecaseTestSuite) makeUsecase() *DataModelUseCase {
 		executorFactory:              suite.executorFactory,
 		organizationSchemaRepository: suite.organizationSchemaRepository,
 		transactionFactory:           suite.transactionFactory,
+		scenarioRepository:           suite.scenarioRepository,
 	}
 }
 
@@ -210,6 +216,7 @@ func (suite *DatamodelUsecaseTestSuite) AssertExpectations() {
 	suite.executorFactory.AssertExpectations(t)
 	suite.dataModelRepository.AssertExpectations(t)
 	suite.organizationSchemaRepository.AssertExpectations(t)
+	suite.scenarioRepository.AssertExpectations(t)
 	suite.transaction.AssertExpectations(t)
 	suite.transactionFactory.AssertExpectations(t)
 }
@@ -648,6 +655,223 @@ func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModel_client_schema_reposi
 	suite.AssertExpectations()
 }
 
+// DeleteDataModelTable, DeleteDataModelField and DeleteDataModelLink
+func (suite *DatamodelUsecaseTestSuite) deletionDataModel() models.DataModel {
+	link := models.LinkToSingle{
+		Id:              "link_id",
+		Name:            "account",
+		ChildTableName:  "transactions",
+		ChildTableId:    "transactions_id",
+		ChildFieldName:  "account_id",
+		ChildFieldId:    "account_id_id",
+		ParentTableName: "accounts",
+		ParentTableId:   "accounts_id",
+		ParentFieldName: "object_id",
+		ParentFieldId:   "accounts_object_id",
+	}
+	return models.DataModel{
+		Tables: map[string]models.Table{
+			"transactions": {
+				ID:   "transactions_id",
+				Name: "transactions",
+				Fields: map[string]models.Field{
+					"object_id":  {ID: "transactions_object_id", Name: "object_id", DataType: models.String},
+					"value":      {ID: "value_id", Name: "value", DataType: models.Float},
+					"account_id": {ID: "account_id_id", Name: "account_id", DataType: models.String},
+				},
+				LinksToSingle: map[string]models.LinkToSingle{"account": link},
+			},
+			"accounts": {
+				ID:   "accounts_id",
+				Name: "accounts",
+				Fields: map[string]models.Field{
+					"object_id": {ID: "accounts_object_id", Name: "object_id", DataType: models.String},
+				},
+				LinksToSingle: map[string]models.LinkToSingle{},
+			},
+		},
+	}
+}
+
+// setupDataModelUsages expects the reads of the usages of the data model, in the transaction of the deletion
+func (suite *DatamodelUsecaseTestSuite) setupDataModelUsages(scenarios []models.Scenario,
+	iterations []models.ScenarioIteration, pivots []models.PivotMetadata,
+) {
+	suite.transactionFactory.On("Transaction", suite.ctx, mock.Anything).Return(nil)
+	suite.dataModelRepository.On("GetDataModel", suite.ctx, suite.transaction, suite.organizationId, false).
+		Return(suite.deletionDataModel(), nil)
+	suite.dataModelRepository.On("ListPivots", suite.ctx, suite.transaction, suite.organizationId, (*string)(nil)).
+		Return(pivots, nil)
+	suite.scenarioRepository.On("ListScenariosOfOrganization", suite.ctx, suite.transaction, suite.organizationId).
+		Return(scenarios, nil)
+	suite.scenarioRepository.On("ListScenarioIterations", suite.ctx, suite.transaction, suite.organizationId,
+		models.GetScenarioIterationFilters{}).Return(iterations, nil)
+}
+
+func (suite *DatamodelUsecaseTestSuite) setupDeleteDataModelTable(tableId, tableName string) {
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.dataModelRepository.On("GetDataModelTable", suite.ctx, suite.transaction, tableId).
+		Return(models.TableMetadata{ID: tableId, Name: tableName, OrganizationID: suite.organizationId}, nil)
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelTable_nominal_drop() {
+	usecase := suite.makeUsecase()
+	suite.setupDeleteDataModelTable("transactions_id", "transactions")
+	// the link of the table is deleted with it
+	suite.setupDataModelUsages([]models.Scenario{}, []models.ScenarioIteration{}, []models.PivotMetadata{})
+	suite.dataModelRepository.On("DeleteDataModelTable", suite.ctx, suite.transaction, "transactions_id").Return(nil)
+	suite.transactionFactory.On("TransactionInOrgSchema", suite.ctx, suite.organizationId, mock.Anything).Return(nil)
+	suite.organizationSchemaRepository.On("DeleteTable", suite.ctx, suite.transaction, "transactions").Return(nil)
+
+	dependencies, err := usecase.DeleteDataModelTable(suite.ctx, "transactions_id", false)
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().Empty(dependencies)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelTable_used_by_scenario_and_link() {
+	usecase := suite.makeUsecase()
+	suite.setupDeleteDataModelTable("accounts_id", "accounts")
+	suite.setupDataModelUsages([]models.Scenario{{Id: "scenario_id", Name: "scenario", TriggerObjectType: "accounts"}},
+		[]models.ScenarioIteration{}, []models.PivotMetadata{})
+
+	dependencies, err := usecase.DeleteDataModelTable(suite.ctx, "accounts_id", true)
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().ElementsMatch([]models.DataModelDependency{
+		{Kind: models.DataModelDependencyScenario, Id: "scenario_id", Name: "scenario", ScenarioId: "scenario_id"},
+		{Kind: models.DataModelDependencyLink, Id: "link_id", Name: "account"},
+	}, dependencies, "the table is not deleted")
+	suite.dataModelRepository.AssertNotCalled(suite.T(), "DeleteDataModelTable", mock.Anything, mock.Anything, mock.Anything)
+	suite.organizationSchemaRepository.AssertNotCalled(suite.T(), "ArchiveTable",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) setupDeleteDataModelField() {
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.dataModelRepository.On("GetDataModelField", suite.ctx, suite.transaction, "value_id").
+		Return(models.FieldMetadata{ID: "value_id", Name: "value", TableId: "transactions_id"}, nil)
+	suite.dataModelRepository.On("GetDataModelTable", suite.ctx, suite.transaction, "transactions_id").
+		Return(models.TableMetadata{ID: "transactions_id", Name: "transactions", OrganizationID: suite.organizationId}, nil)
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelField_nominal_archive() {
+	usecase := suite.makeUsecase()
+	suite.setupDeleteDataModelField()
+	suite.setupDataModelUsages([]models.Scenario{{Id: "scenario_id", Name: "scenario", TriggerObjectType: "transactions"}},
+		[]models.ScenarioIteration{}, []models.PivotMetadata{})
+	suite.dataModelRepository.On("DeleteDataModelField", suite.ctx, suite.transaction, "value_id").Return(nil)
+	suite.transactionFactory.On("TransactionInOrgSchema", suite.ctx, suite.organizationId, mock.Anything).Return(nil)
+	suite.organizationSchemaRepository.On("ArchiveField", suite.ctx, suite.transaction, "transactions", "value",
+		mock.MatchedBy(func(name string) bool { return strings.HasPrefix(name, "value_archived_") })).Return(nil)
+
+	dependencies, err := usecase.DeleteDataModelField(suite.ctx, "value_id", true)
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().Empty(dependencies)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelField_used_by_iteration() {
+	usecase := suite.makeUsecase()
+	trigger := ast.Node{Function: ast.FUNC_GREATER}.
+		AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("value"))).
+		AddChild(ast.NewNodeConstant(100))
+	suite.setupDeleteDataModelField()
+	suite.setupDataModelUsages([]models.Scenario{{Id: "scenario_id", Name: "scenario", TriggerObjectType: "transactions"}},
+		[]models.ScenarioIteration{
+			{Id: "iteration_id", ScenarioId: "scenario_id", Version: utils.Ptr(2), TriggerConditionAstExpression: &trigger},
+		}, []models.PivotMetadata{})
+
+	dependencies, err := usecase.DeleteDataModelField(suite.ctx, "value_id", false)
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().Equal([]models.DataModelDependency{{
+		Kind:       models.DataModelDependencyScenarioIteration,
+		Id:         "iteration_id",
+		Name:       "scenario",
+		ScenarioId: "scenario_id",
+		Version:    utils.Ptr(2),
+	}}, dependencies, "the field is not deleted")
+	suite.dataModelRepository.AssertNotCalled(suite.T(), "DeleteDataModelField", mock.Anything, mock.Anything, mock.Anything)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelField_default_field() {
+	usecase := suite.makeUsecase()
+	suite.executorFactory.On("NewExecutor").Return(suite.transaction)
+	suite.dataModelRepository.On("GetDataModelField", suite.ctx, suite.transaction, "transactions_object_id").
+		Return(models.FieldMetadata{ID: "transactions_object_id", Name: "object_id", TableId: "transactions_id"}, nil)
+	suite.dataModelRepository.On("GetDataModelTable", suite.ctx, suite.transaction, "transactions_id").
+		Return(models.TableMetadata{ID: "transactions_id", Name: "transactions", OrganizationID: suite.organizationId}, nil)
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+
+	_, err := usecase.DeleteDataModelField(suite.ctx, "transactions_object_id", false)
+	suite.Require().ErrorIs(err, models.BadParameterError)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelLink_nominal() {
+	usecase := suite.makeUsecase()
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+	suite.setupDataModelUsages([]models.Scenario{}, []models.ScenarioIteration{}, []models.PivotMetadata{})
+	suite.dataModelRepository.On("DeleteDataModelLink", suite.ctx, suite.transaction, "link_id").Return(nil)
+
+	dependencies, err := usecase.DeleteDataModelLink(suite.ctx, suite.organizationId, "link_id")
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().Empty(dependencies)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelLink_used_by_pivot() {
+	usecase := suite.makeUsecase()
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+	suite.setupDataModelUsages([]models.Scenario{}, []models.ScenarioIteration{}, []models.PivotMetadata{{
+		Id:             "pivot_id",
+		OrganizationId: suite.organizationId,
+		BaseTableId:    "transactions_id",
+		PathLinkIds:    []string{"link_id"},
+	}})
+
+	dependencies, err := usecase.DeleteDataModelLink(suite.ctx, suite.organizationId, "link_id")
+	suite.Require().NoError(err, "no error expected")
+	suite.Require().Equal([]models.DataModelDependency{
+		{Kind: models.DataModelDependencyPivot, Id: "pivot_id", Name: "transactions"},
+	}, dependencies, "the link is not deleted")
+	suite.dataModelRepository.AssertNotCalled(suite.T(), "DeleteDataModelLink", mock.Anything, mock.Anything, mock.Anything)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestDeleteDataModelLink_not_found() {
+	usecase := suite.makeUsecase()
+	suite.enforceSecurity.On("WriteDataModel", suite.organizationId).Return(nil)
+	suite.setupDataModelUsages([]models.Scenario{}, []models.ScenarioIteration{}, []models.PivotMetadata{})
+
+	_, err := usecase.DeleteDataModelLink(suite.ctx, suite.organizationId, "unknown_link_id")
+	suite.Require().ErrorIs(err, models.NotFoundError)
+	suite.dataModelRepository.AssertNotCalled(suite.T(), "DeleteDataModelLink", mock.Anything, mock.Anything, mock.Anything)
+
+	suite.AssertExpectations()
+}
+
+func (suite *DatamodelUsecaseTestSuite) TestArchivedName() {
+	archivedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	first, second := archivedName("value", archivedAt), archivedName("value", archivedAt)
+	suite.Require().True(strings.HasPrefix(first, "value_archived_20240301123000_"))
+	suite.Require().NotEqual(first, second, "archives of the same second have distinct names")
+
+	long := archivedName(strings.Repeat("a", 63), archivedAt)
+	suite.Require().Len(long, 63)
+	suite.Require().Contains(long, "_archived_20240301123000_")
+}
+
 // CreateDataModelLink
 func (suite *DatamodelUsecaseTestSuite) TestCreateDataModelLink_nominal() {
 	parentTableName := "accounts"
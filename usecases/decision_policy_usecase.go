@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type DecisionPolicyRepository interface {
+	GetDecisionPolicy(ctx context.Context, exec repositories.Executor, id string) (models.DecisionPolicy, error)
+	GetDecisionPolicyOfTriggerObjectType(ctx context.Context, exec repositories.Executor, organizationId string,
+		triggerObjectType string) (models.DecisionPolicy, error)
+	ListDecisionPolicies(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.DecisionPolicy, error)
+	CreateDecisionPolicy(ctx context.Context, exec repositories.Executor, id string, input models.CreateDecisionPolicyInput) error
+	UpdateDecisionPolicy(ctx context.Context, exec repositories.Executor, policy models.DecisionPolicy) error
+	DeleteDecisionPolicy(ctx context.Context, exec repositories.Executor, id string) error
+
+	GetScenarioById(ctx context.Context, exec repositories.Executor, scenarioId string) (models.Scenario, error)
+}
+
+type DecisionPolicyUsecase struct {
+	enforceSecurity    security.EnforceSecurityDecisionPolicy
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         DecisionPolicyRepository
+}
+
+func (usecase *DecisionPolicyUsecase) ListDecisionPolicies(ctx context.Context, organizationId string) ([]models.DecisionPolicy, error) {
+	if err := usecase.enforceSecurity.ListDecisionPolicies(organizationId); err != nil {
+		return nil, err
+	}
+	return usecase.repository.ListDecisionPolicies(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+}
+
+func (usecase *DecisionPolicyUsecase) GetDecisionPolicy(ctx context.Context, id string) (models.DecisionPolicy, error) {
+	policy, err := usecase.repository.GetDecisionPolicy(ctx, usecase.executorFactory.NewExecutor(), id)
+	if err != nil {
+		return models.DecisionPolicy{}, err
+	}
+	if err := usecase.enforceSecurity.ReadDecisionPolicy(policy); err != nil {
+		return models.DecisionPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (usecase *DecisionPolicyUsecase) CreateDecisionPolicy(
+	ctx context.Context,
+	input models.CreateDecisionPolicyInput,
+) (models.DecisionPolicy, error) {
+	if err := usecase.enforceSecurity.WriteDecisionPolicy(input.OrganizationId); err != nil {
+		return models.DecisionPolicy{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.DecisionPolicy, error) {
+		err := usecase.validateDecisionPolicy(ctx, tx, models.DecisionPolicy{
+			OrganizationId:       input.OrganizationId,
+			TriggerObjectType:    input.TriggerObjectType,
+			ScenarioIds:          input.ScenarioIds,
+			StopOnReject:         input.StopOnReject,
+			Aggregation:          input.Aggregation,
+			ScoreReviewThreshold: input.ScoreReviewThreshold,
+			ScoreRejectThreshold: input.ScoreRejectThreshold,
+		})
+		if err != nil {
+			return models.DecisionPolicy{}, err
+		}
+
+		id := uuid.NewString()
+		if err := usecase.repository.CreateDecisionPolicy(ctx, tx, id, input); err != nil {
+			return models.DecisionPolicy{}, err
+		}
+		return usecase.repository.GetDecisionPolicy(ctx, tx, id)
+	})
+}
+
+func (usecase *DecisionPolicyUsecase) UpdateDecisionPolicy(
+	ctx context.Context,
+	input models.UpdateDecisionPolicyInput,
+) (models.DecisionPolicy, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.DecisionPolicy, error) {
+		policy, err := usecase.repository.GetDecisionPolicy(ctx, tx, input.Id)
+		if err != nil {
+			return models.DecisionPolicy{}, err
+		}
+		if err := usecase.enforceSecurity.WriteDecisionPolicy(policy.OrganizationId); err != nil {
+			return models.DecisionPolicy{}, err
+		}
+
+		updatedPolicy := policy.MergeUpdate(input)
+		if err := usecase.validateDecisionPolicy(ctx, tx, updatedPolicy); err != nil {
+			return models.DecisionPolicy{}, err
+		}
+		if err := usecase.repository.UpdateDecisionPolicy(ctx, tx, updatedPolicy); err != nil {
+			return models.DecisionPolicy{}, err
+		}
+		return usecase.repository.GetDecisionPolicy(ctx, tx, input.Id)
+	})
+}
+
+func (usecase *DecisionPolicyUsecase) DeleteDecisionPolicy(ctx context.Context, id string) error {
+	exec := usecase.executorFactory.NewExecutor()
+	policy, err := usecase.repository.GetDecisionPolicy(ctx, exec, id)
+	if err != nil {
+		return err
+	}
+	if err := usecase.enforceSecurity.WriteDecisionPolicy(policy.OrganizationId); err != nil {
+		return err
+	}
+	return usecase.repository.DeleteDecisionPolicy(ctx, exec, id)
+}
+
+// validateDecisionPolicy checks the policy, and that its scenarios belong to the organization and are triggered
+// by the trigger object type of the policy
+func (usecase *DecisionPolicyUsecase) validateDecisionPolicy(
+	ctx context.Context,
+	exec repositories.Executor,
+	policy models.DecisionPolicy,
+) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	for _, scenarioId := range policy.ScenarioIds {
+		if _, err := uuid.Parse(scenarioId); err != nil {
+			return errors.Wrapf(models.BadParameterError, "invalid scenario id %s", scenarioId)
+		}
+		scenario, err := usecase.repository.GetScenarioById(ctx, exec, scenarioId)
+		if errors.Is(err, models.NotFoundError) {
+			return errors.Wrapf(models.BadParameterError, "scenario %s not found", scenarioId)
+		} else if err != nil {
+			return err
+		}
+		if scenario.OrganizationId != policy.OrganizationId {
+			return errors.Wrapf(models.BadParameterError, "scenario %s not found", scenarioId)
+		}
+		if scenario.TriggerObjectType != policy.TriggerObjectType {
+			return errors.Wrapf(models.BadParameterError,
+				"scenario %s is triggered by %s, not by %s", scenarioId,
+				scenario.TriggerObjectType, policy.TriggerObjectType)
+		}
+	}
+	return nil
+}
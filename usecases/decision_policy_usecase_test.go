@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type DecisionPolicyUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity *mocks.EnforceSecurity
+	repository      *mocks.DecisionPolicyRepository
+	transaction     *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	scenarioId     string
+	policy         models.DecisionPolicy
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.DecisionPolicyRepository)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "organization_id"
+	suite.scenarioId = "5b6bd6e5-2e4c-4c4c-8f0a-7b1c1a0c3e6f"
+	suite.policy = models.DecisionPolicy{
+		Id:                   "policy_id",
+		OrganizationId:       suite.organizationId,
+		TriggerObjectType:    "transactions",
+		ScenarioIds:          []string{suite.scenarioId},
+		Aggregation:          models.DecisionPolicyAggregationScoreSum,
+		ScoreReviewThreshold: utils.Ptr(10),
+		ScoreRejectThreshold: utils.Ptr(20),
+	}
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) makeUsecase() *DecisionPolicyUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.transaction)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &DecisionPolicyUsecase{
+		enforceSecurity:    suite.enforceSecurity,
+		executorFactory:    executorFactory,
+		transactionFactory: transactionFactory,
+		repository:         suite.repository,
+	}
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) TestValidateDecisionPolicy() {
+	suite.repository.On("GetScenarioById", suite.transaction, suite.scenarioId).Return(models.Scenario{
+		Id:                suite.scenarioId,
+		OrganizationId:    suite.organizationId,
+		TriggerObjectType: "transactions",
+	}, nil)
+
+	err := suite.makeUsecase().validateDecisionPolicy(suite.ctx, suite.transaction, suite.policy)
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) TestValidateDecisionPolicy_invalidScenarios() {
+	tests := []struct {
+		name     string
+		scenario models.Scenario
+		err      error
+	}{
+		{
+			name:     "scenario of another organization",
+			scenario: models.Scenario{OrganizationId: "other_organization_id", TriggerObjectType: "transactions"},
+		},
+		{
+			name:     "scenario of another trigger object type",
+			scenario: models.Scenario{OrganizationId: suite.organizationId, TriggerObjectType: "accounts"},
+		},
+		{
+			name: "scenario not found",
+			err:  models.NotFoundError,
+		},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			suite.SetupTest()
+			suite.repository.On("GetScenarioById", suite.transaction, suite.scenarioId).Return(test.scenario, test.err)
+
+			err := suite.makeUsecase().validateDecisionPolicy(suite.ctx, suite.transaction, suite.policy)
+			suite.ErrorIs(err, models.BadParameterError)
+			suite.AssertExpectations()
+		})
+	}
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) TestValidateDecisionPolicy_invalidScenarioId() {
+	suite.policy.ScenarioIds = []string{"not a uuid"}
+
+	err := suite.makeUsecase().validateDecisionPolicy(suite.ctx, suite.transaction, suite.policy)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioById", mock.Anything, mock.Anything)
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) TestUpdateDecisionPolicy_clearThresholds() {
+	worstOutcome := models.DecisionPolicyAggregationWorstOutcome
+	updated := suite.policy
+	updated.Aggregation = worstOutcome
+	updated.ScoreReviewThreshold = nil
+	updated.ScoreRejectThreshold = nil
+	suite.repository.On("GetDecisionPolicy", suite.transaction, suite.policy.Id).Return(suite.policy, nil).Once()
+	suite.enforceSecurity.On("WriteDecisionPolicy", suite.organizationId).Return(nil)
+	suite.repository.On("GetScenarioById", suite.transaction, suite.scenarioId).Return(models.Scenario{
+		Id:                suite.scenarioId,
+		OrganizationId:    suite.organizationId,
+		TriggerObjectType: "transactions",
+	}, nil)
+	suite.repository.On("UpdateDecisionPolicy", suite.transaction, updated).Return(nil)
+	suite.repository.On("GetDecisionPolicy", suite.transaction, suite.policy.Id).Return(updated, nil).Once()
+
+	result, err := suite.makeUsecase().UpdateDecisionPolicy(suite.ctx, models.UpdateDecisionPolicyInput{
+		Id:                   suite.policy.Id,
+		Aggregation:          &worstOutcome,
+		ScoreReviewThreshold: pure_utils.NullCleared[int](),
+		ScoreRejectThreshold: pure_utils.NullCleared[int](),
+	})
+	suite.NoError(err)
+	suite.Equal(updated, result)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionPolicyUsecaseTestSuite) TestUpdateDecisionPolicy_clearThresholdsOfScoreSum() {
+	suite.repository.On("GetDecisionPolicy", suite.transaction, suite.policy.Id).Return(suite.policy, nil)
+	suite.enforceSecurity.On("WriteDecisionPolicy", suite.organizationId).Return(nil)
+
+	_, err := suite.makeUsecase().UpdateDecisionPolicy(suite.ctx, models.UpdateDecisionPolicyInput{
+		Id:                   suite.policy.Id,
+		ScoreRejectThreshold: pure_utils.NullCleared[int](),
+	})
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.repository.AssertNotCalled(suite.T(), "UpdateDecisionPolicy", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func TestDecisionPolicyUsecase(t *testing.T) {
+	suite.Run(t, new(DecisionPolicyUsecaseTestSuite))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/checkmarble/marble-backend/dto"
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
 	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases/ast_eval"
 	"github.com/checkmarble/marble-backend/usecases/evaluate_scenario"
@@ -52,6 +54,32 @@ type snoozesForDecisionReader interface {
 	) ([]models.RuleSnooze, error)
 }
 
+type idempotencyKeyRepository interface {
+	GetIdempotencyKey(ctx context.Context, exec repositories.Executor, organizationId string,
+		scope models.IdempotencyKeyScope, key string) (*models.IdempotencyKey, error)
+	CreateIdempotencyKey(ctx context.Context, exec repositories.Executor, input models.IdempotencyKeyCreate) (bool, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, exec repositories.Executor) error
+}
+
+type decisionPolicyRepository interface {
+	GetDecisionPolicyOfTriggerObjectType(ctx context.Context, exec repositories.Executor, organizationId string,
+		triggerObjectType string) (models.DecisionPolicy, error)
+	StorePolicyDecision(ctx context.Context, exec repositories.Executor, policyDecision models.PolicyDecision) error
+	GetPolicyDecision(ctx context.Context, exec repositories.Executor, id string) (models.PolicyDecision, error)
+}
+
+type asyncDecisionRepository interface {
+	CreateAsyncDecision(ctx context.Context, exec repositories.Executor, id string,
+		input models.CreateAsyncDecisionInput) error
+	GetAsyncDecision(ctx context.Context, exec repositories.Executor, id string) (models.AsyncDecision, error)
+	UpdateAsyncDecisionStatus(ctx context.Context, exec repositories.Executor, id string,
+		status models.AsyncDecisionStatus, asyncDecisionError *string) error
+}
+
+// errIdempotencyKeyAlreadyStored is returned when a concurrent request with the same idempotency key has stored its
+// decisions first
+var errIdempotencyKeyAlreadyStored = errors.New("idempotency key already stored by a concurrent request")
+
 type DecisionUsecase struct {
 	enforceSecurity            security.EnforceSecurityDecision
 	enforceSecurityScenario    security.EnforceSecurityScenario
@@ -66,6 +94,12 @@ type DecisionUsecase struct {
 	organizationIdOfContext    func() (string, error)
 	webhookEventsSender        webhookEventsUsecase
 	snoozesReader              snoozesForDecisionReader
+	idempotencyKeyRepository   idempotencyKeyRepository
+	idempotencyKeyRetention    time.Duration
+	decisionPolicyRepository   decisionPolicyRepository
+	asyncDecisionRepository    asyncDecisionRepository
+	jobEnqueuer                jobEnqueuer
+	jobQueueWorker             jobQueueWorker
 }
 
 func (usecase *DecisionUsecase) GetDecision(ctx context.Context, decisionId string) (models.DecisionWithRuleExecutions, error) {
@@ -81,6 +115,60 @@ func (usecase *DecisionUsecase) GetDecision(ctx context.Context, decisionId stri
 	return decision, nil
 }
 
+// ReviewDecision records the verdict of the current user on a decision. A decision can be reviewed again, in which
+// case the last review replaces the previous one.
+func (usecase *DecisionUsecase) ReviewDecision(
+	ctx context.Context,
+	input models.ReviewDecisionInput,
+) (models.DecisionWithRuleExecutions, error) {
+	if err := input.Validate(); err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+	creds, found := utils.CredentialsFromCtx(ctx)
+	if !found || creds.ActorIdentity.UserId == "" {
+		return models.DecisionWithRuleExecutions{}, errors.Wrap(models.ForbiddenError,
+			"only users can review decisions")
+	}
+
+	webhookEventId := uuid.NewString()
+	decision, err := executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.DecisionWithRuleExecutions, error) {
+		decision, err := usecase.decisionRepository.DecisionWithRuleExecutionsById(ctx, tx, input.DecisionId)
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		if err := usecase.enforceSecurity.ReviewDecision(decision.Decision); err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+
+		review := models.DecisionReview{
+			Disposition: input.Disposition,
+			ReviewedBy:  utils.Ptr(creds.ActorIdentity.UserId),
+			ReviewedAt:  time.Now(),
+			Note:        input.Note,
+		}
+		if err := usecase.decisionRepository.ReviewDecision(ctx, tx, input.DecisionId, review); err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		err = usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
+			Id:             webhookEventId,
+			OrganizationId: decision.OrganizationId,
+			EventContent:   models.NewWebhookEventDecisionReviewed(decision.DecisionId, review),
+		})
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		return usecase.decisionRepository.DecisionWithRuleExecutionsById(ctx, tx, input.DecisionId)
+	})
+	if err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+
+	usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	return decision, nil
+}
+
 func (usecase *DecisionUsecase) ListDecisions(
 	ctx context.Context,
 	organizationId string,
@@ -107,6 +195,11 @@ func (usecase *DecisionUsecase) ListDecisions(
 		return []models.DecisionWithRank{}, err
 	}
 
+	reviewDispositions, err := usecase.validateReviewDispositions(filters.ReviewDispositions)
+	if err != nil {
+		return []models.DecisionWithRank{}, err
+	}
+
 	if err := models.ValidatePagination(paginationAndSorting); err != nil {
 		return []models.DecisionWithRank{}, err
 	}
@@ -126,6 +219,8 @@ func (usecase *DecisionUsecase) ListDecisions(
 			ScheduledExecutionIds: filters.ScheduledExecutionIds,
 			StartDate:             filters.StartDate,
 			TriggerObjects:        triggerObjectTypes,
+			ReviewDispositions:    reviewDispositions,
+			IsReviewed:            filters.IsReviewed,
 		})
 	if err != nil {
 		return []models.DecisionWithRank{}, err
@@ -169,6 +264,19 @@ func (usecase *DecisionUsecase) validateOutcomes(_ context.Context, filtersOutco
 	return outcomes, nil
 }
 
+func (usecase *DecisionUsecase) validateReviewDispositions(
+	filtersReviewDispositions []string,
+) ([]models.DecisionReviewDisposition, error) {
+	reviewDispositions := make([]models.DecisionReviewDisposition, len(filtersReviewDispositions))
+	for i, reviewDisposition := range filtersReviewDispositions {
+		reviewDispositions[i] = models.DecisionReviewDisposition(reviewDisposition)
+		if err := reviewDispositions[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return reviewDispositions, nil
+}
+
 func (usecase *DecisionUsecase) validateTriggerObjects(ctx context.Context,
 	filtersTriggerObjects []string, organizationId string,
 ) ([]string, error) {
@@ -216,47 +324,36 @@ func (usecase *DecisionUsecase) CreateDecision(
 		if err := usecase.enforceSecurityScenario.ReadScenario(scenario); err != nil {
 			return models.DecisionWithRuleExecutions{}, err
 		}
+		if err := usecase.enforceSecurity.DecideOnScenario(scenario); err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
 	}
 
-	payload, dataModel, err := usecase.validatePayload(
-		ctx,
-		input.OrganizationId,
-		input.TriggerObjectTable,
-		input.ClientObject,
-		input.PayloadRaw,
-	)
-	if err != nil {
-		return models.DecisionWithRuleExecutions{}, err
+	var requestHash string
+	if input.IdempotencyKey != "" {
+		if err := models.ValidateIdempotencyKey(input.IdempotencyKey); err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		requestHash, err = models.IdempotencyRequestHash(models.IdempotencyKeyScopeCreateDecision,
+			input.ScenarioId, input.TriggerObjectTable, input.PayloadRaw)
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		storedDecisions, _, found, err := usecase.decisionsOfIdempotencyKey(ctx, input.OrganizationId,
+			models.IdempotencyKeyScopeCreateDecision, input.IdempotencyKey, requestHash)
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		if found {
+			return storedDecisions[0], nil
+		}
 	}
 
-	pivotsMeta, err := usecase.dataModelRepository.ListPivots(ctx, exec, input.OrganizationId, nil)
+	decision, err := usecase.evaluateDecision(ctx, scenario, nil, input.TriggerObjectTable,
+		input.ClientObject, input.PayloadRaw)
 	if err != nil {
 		return models.DecisionWithRuleExecutions{}, err
 	}
-	pivot := models.FindPivot(pivotsMeta, input.TriggerObjectTable, dataModel)
-
-	evaluationParameters := evaluate_scenario.ScenarioEvaluationParameters{
-		Scenario:     scenario,
-		ClientObject: payload,
-		DataModel:    dataModel,
-		Pivot:        pivot,
-	}
-
-	evaluationRepositories := evaluate_scenario.ScenarioEvaluationRepositories{
-		EvalScenarioRepository:     usecase.repository,
-		ExecutorFactory:            usecase.executorFactory,
-		IngestedDataReadRepository: usecase.ingestedDataReadRepository,
-		EvaluateAstExpression:      usecase.evaluateAstExpression,
-		SnoozeReader:               usecase.snoozesReader,
-	}
-
-	scenarioExecution, err := evaluate_scenario.EvalScenario(ctx, evaluationParameters, evaluationRepositories)
-	if err != nil {
-		return models.DecisionWithRuleExecutions{},
-			fmt.Errorf("error evaluating scenario: %w", err)
-	}
-
-	decision := models.AdaptScenarExecToDecision(scenarioExecution, payload, nil)
 
 	ctx, span = tracer.Start(
 		ctx,
@@ -285,7 +382,7 @@ func (usecase *DecisionUsecase) CreateDecision(
 			err := usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
 				Id:             webhookEventId,
 				OrganizationId: decision.OrganizationId,
-				EventContent:   models.NewWebhookEventDecisionCreated(decision.DecisionId),
+				EventContent:   models.NewWebhookEventDecisionCreated(decision.DecisionId, decision.OutputValues),
 			})
 			if err != nil {
 				return models.DecisionWithRuleExecutions{}, err
@@ -307,8 +404,33 @@ func (usecase *DecisionUsecase) CreateDecision(
 			sendWebhookEventId = append(sendWebhookEventId, caseWebhookEventId)
 		}
 
+		if input.IdempotencyKey != "" {
+			err := usecase.storeIdempotencyKey(ctx, tx, models.IdempotencyKeyCreate{
+				OrganizationId: input.OrganizationId,
+				Scope:          models.IdempotencyKeyScopeCreateDecision,
+				Key:            input.IdempotencyKey,
+				RequestHash:    requestHash,
+				DecisionIds:    []string{decision.DecisionId},
+			})
+			if err != nil {
+				return models.DecisionWithRuleExecutions{}, err
+			}
+		}
+
 		return usecase.decisionRepository.DecisionWithRuleExecutionsById(ctx, tx, decision.DecisionId)
 	})
+	if errors.Is(err, errIdempotencyKeyAlreadyStored) {
+		storedDecisions, _, found, err := usecase.decisionsOfIdempotencyKey(ctx, input.OrganizationId,
+			models.IdempotencyKeyScopeCreateDecision, input.IdempotencyKey, requestHash)
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		if !found {
+			return models.DecisionWithRuleExecutions{}, errors.Wrapf(models.ConflictError,
+				"idempotency key %s is being used by a concurrent request", input.IdempotencyKey)
+		}
+		return storedDecisions[0], nil
+	}
 	if err != nil {
 		return models.DecisionWithRuleExecutions{}, err
 	}
@@ -320,6 +442,103 @@ func (usecase *DecisionUsecase) CreateDecision(
 	return newDecision, nil
 }
 
+// DryRunDecision evaluates a scenario against the ingested data like CreateDecision, but the decision is not stored:
+// no case is created and no webhook is sent. If an iteration is passed, it is evaluated instead of the live version
+// of the scenario, even if it is a draft.
+func (usecase *DecisionUsecase) DryRunDecision(
+	ctx context.Context,
+	input models.DryRunDecisionInput,
+) (models.DecisionWithRuleExecutions, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	tracer := utils.OpenTelemetryTracerFromContext(ctx)
+	ctx, span := tracer.Start(ctx, "DecisionUsecase.DryRunDecision")
+	defer span.End()
+
+	scenarioId := input.ScenarioId
+	if input.ScenarioIterationId != nil {
+		iteration, err := usecase.repository.GetScenarioIteration(ctx, exec, *input.ScenarioIterationId)
+		if err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		if err := usecase.enforceSecurityScenario.DryRunScenarioIteration(iteration); err != nil {
+			return models.DecisionWithRuleExecutions{}, err
+		}
+		scenarioId = iteration.ScenarioId
+	} else if err := usecase.enforceSecurity.CreateDecision(input.OrganizationId); err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+
+	scenario, err := usecase.repository.GetScenarioById(ctx, exec, scenarioId)
+	if errors.Is(err, models.NotFoundError) {
+		return models.DecisionWithRuleExecutions{}, errors.Wrap(err, "scenario not found")
+	} else if err != nil {
+		return models.DecisionWithRuleExecutions{},
+			errors.Wrap(err, "error getting scenario")
+	}
+	if err := usecase.enforceSecurityScenario.ReadScenario(scenario); err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+	if err := usecase.enforceSecurity.DecideOnScenario(scenario); err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+
+	return usecase.evaluateDecision(ctx, scenario, input.ScenarioIterationId, input.TriggerObjectTable,
+		nil, input.PayloadRaw)
+}
+
+// evaluateDecision evaluates the live version of the scenario (or the target iteration, if any) against the trigger
+// object, and returns the resulting decision without storing it
+func (usecase *DecisionUsecase) evaluateDecision(
+	ctx context.Context,
+	scenario models.Scenario,
+	targetIterationId *string,
+	triggerObjectTable string,
+	clientObject *models.ClientObject,
+	payloadRaw json.RawMessage,
+) (models.DecisionWithRuleExecutions, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	payload, dataModel, err := usecase.validatePayload(
+		ctx,
+		scenario.OrganizationId,
+		triggerObjectTable,
+		clientObject,
+		payloadRaw,
+	)
+	if err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+
+	pivotsMeta, err := usecase.dataModelRepository.ListPivots(ctx, exec, scenario.OrganizationId, nil)
+	if err != nil {
+		return models.DecisionWithRuleExecutions{}, err
+	}
+	pivot := models.FindPivot(pivotsMeta, triggerObjectTable, dataModel)
+
+	evaluationParameters := evaluate_scenario.ScenarioEvaluationParameters{
+		Scenario:          scenario,
+		ClientObject:      payload,
+		DataModel:         dataModel,
+		Pivot:             pivot,
+		TargetIterationId: targetIterationId,
+	}
+
+	evaluationRepositories := evaluate_scenario.ScenarioEvaluationRepositories{
+		EvalScenarioRepository:     usecase.repository,
+		ExecutorFactory:            usecase.executorFactory,
+		IngestedDataReadRepository: usecase.ingestedDataReadRepository,
+		EvaluateAstExpression:      usecase.evaluateAstExpression,
+		SnoozeReader:               usecase.snoozesReader,
+	}
+
+	scenarioExecution, err := evaluate_scenario.EvalScenario(ctx, evaluationParameters, evaluationRepositories)
+	if err != nil {
+		return models.DecisionWithRuleExecutions{},
+			fmt.Errorf("error evaluating scenario: %w", err)
+	}
+
+	return models.AdaptScenarExecToDecision(scenarioExecution, payload, nil), nil
+}
+
 func (usecase *DecisionUsecase) CreateAllDecisions(
 	ctx context.Context,
 	input models.CreateAllDecisionsInput,
@@ -332,6 +551,26 @@ func (usecase *DecisionUsecase) CreateAllDecisions(
 	if err = usecase.enforceSecurity.CreateDecision(input.OrganizationId); err != nil {
 		return
 	}
+	if err = usecase.enforceSecurity.DecideOnTriggerObject(input.TriggerObjectTable); err != nil {
+		return
+	}
+
+	var requestHash string
+	if input.IdempotencyKey != "" {
+		if err = models.ValidateIdempotencyKey(input.IdempotencyKey); err != nil {
+			return nil, 0, err
+		}
+		requestHash, err = models.IdempotencyRequestHash(models.IdempotencyKeyScopeCreateAllDecisions,
+			"", input.TriggerObjectTable, input.PayloadRaw)
+		if err != nil {
+			return nil, 0, err
+		}
+		storedDecisions, storedNbSkipped, found, err := usecase.decisionsOfIdempotencyKey(ctx,
+			input.OrganizationId, models.IdempotencyKeyScopeCreateAllDecisions, input.IdempotencyKey, requestHash)
+		if err != nil || found {
+			return storedDecisions, storedNbSkipped, err
+		}
+	}
 
 	payload, dataModel, err := usecase.validatePayload(
 		ctx,
@@ -360,6 +599,10 @@ func (usecase *DecisionUsecase) CreateAllDecisions(
 			if err := usecase.enforceSecurityScenario.ReadScenario(scenario); err != nil {
 				return nil, 0, err
 			}
+			// a scoped API key decides on the scenarios of its scope only
+			if usecase.enforceSecurity.DecideOnScenario(scenario) != nil {
+				continue
+			}
 			filteredScenarios = append(filteredScenarios, scenario)
 		}
 	}
@@ -372,10 +615,6 @@ func (usecase *DecisionUsecase) CreateAllDecisions(
 		SnoozeReader:               usecase.snoozesReader,
 	}
 
-	type decisionAndScenario struct {
-		decision models.DecisionWithRuleExecutions
-		scenario models.Scenario
-	}
 	var items []decisionAndScenario
 	for _, scenario := range filteredScenarios {
 		evaluationParameters := evaluate_scenario.ScenarioEvaluationParameters{
@@ -409,47 +648,37 @@ func (usecase *DecisionUsecase) CreateAllDecisions(
 	ctx, span2 := tracer.Start(ctx, "DecisionUsecase.CreateAllDecisions - store decisions")
 	defer span2.End()
 
-	sendWebhookEventIds := make([]string, 0)
+	var sendWebhookEventIds []string
 	decisions, err = executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
 		tx repositories.Executor,
 	) ([]models.DecisionWithRuleExecutions, error) {
 		var ids []string
-		for _, item := range items {
-			ids = append(ids, item.decision.DecisionId)
-			if err = usecase.decisionRepository.StoreDecision(
-				ctx,
-				tx,
-				item.decision,
-				input.OrganizationId,
-				item.decision.DecisionId,
-			); err != nil {
-				return nil, fmt.Errorf("error storing decision in CreateAllDecisions: %w", err)
-			}
+		ids, sendWebhookEventIds, err = usecase.storeDecisions(ctx, tx, input.OrganizationId, items)
+		if err != nil {
+			return nil, err
+		}
 
-			webhookEventId := uuid.NewString()
-			err := usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
-				Id:             webhookEventId,
-				OrganizationId: item.decision.OrganizationId,
-				EventContent:   models.NewWebhookEventDecisionCreated(item.decision.DecisionId),
+		if input.IdempotencyKey != "" {
+			err := usecase.storeIdempotencyKey(ctx, tx, models.IdempotencyKeyCreate{
+				OrganizationId: input.OrganizationId,
+				Scope:          models.IdempotencyKeyScopeCreateAllDecisions,
+				Key:            input.IdempotencyKey,
+				RequestHash:    requestHash,
+				DecisionIds:    ids,
+				NbSkipped:      nbSkipped,
 			})
 			if err != nil {
 				return nil, err
 			}
-			sendWebhookEventIds = append(sendWebhookEventIds, webhookEventId)
-
-			caseWebhookEventId := uuid.NewString()
-			webhookEventCreated, err := usecase.decisionWorkflows.AutomaticDecisionToCase(
-				ctx, tx, item.scenario, item.decision, caseWebhookEventId)
-			if err != nil {
-				return nil, err
-			}
-			if webhookEventCreated {
-				sendWebhookEventIds = append(sendWebhookEventIds, caseWebhookEventId)
-			}
 		}
 
 		return usecase.decisionRepository.DecisionsWithRuleExecutionsByIds(ctx, tx, ids)
 	})
+	if errors.Is(err, errIdempotencyKeyAlreadyStored) {
+		storedDecisions, storedNbSkipped, _, err := usecase.decisionsOfIdempotencyKey(ctx,
+			input.OrganizationId, models.IdempotencyKeyScopeCreateAllDecisions, input.IdempotencyKey, requestHash)
+		return storedDecisions, storedNbSkipped, err
+	}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -461,6 +690,481 @@ func (usecase *DecisionUsecase) CreateAllDecisions(
 	return
 }
 
+// CreateAsyncDecision validates the payload and enqueues the evaluation of the scenario on it. The decision is taken
+// later by a worker, with the id of the returned asynchronous decision.
+func (usecase *DecisionUsecase) CreateAsyncDecision(
+	ctx context.Context,
+	input models.CreateAsyncDecisionInput,
+) (models.AsyncDecision, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	if err := usecase.enforceSecurity.CreateDecision(input.OrganizationId); err != nil {
+		return models.AsyncDecision{}, err
+	}
+	scenario, err := usecase.repository.GetScenarioById(ctx, exec, input.ScenarioId)
+	if errors.Is(err, models.NotFoundError) {
+		return models.AsyncDecision{}, errors.Wrap(err, "scenario not found")
+	} else if err != nil {
+		return models.AsyncDecision{}, errors.Wrap(err, "error getting scenario")
+	}
+	if err := usecase.enforceSecurityScenario.ReadScenario(scenario); err != nil {
+		return models.AsyncDecision{}, err
+	}
+	if err := usecase.enforceSecurity.DecideOnScenario(scenario); err != nil {
+		return models.AsyncDecision{}, err
+	}
+	if scenario.LiveVersionID == nil {
+		return models.AsyncDecision{}, models.ErrScenarioHasNoLiveVersion
+	}
+
+	// reject invalid payloads right away, rather than in the worker
+	if _, _, err := usecase.validatePayload(ctx, input.OrganizationId, input.TriggerObjectTable,
+		nil, input.PayloadRaw); err != nil {
+		return models.AsyncDecision{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.AsyncDecision, error) {
+		id := pure_utils.NewPrimaryKey(input.OrganizationId)
+		if err := usecase.asyncDecisionRepository.CreateAsyncDecision(ctx, tx, id, input); err != nil {
+			return models.AsyncDecision{}, err
+		}
+		if err := usecase.jobEnqueuer.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+			Kind:     models.JobKindAsyncDecision,
+			EntityId: id,
+		}); err != nil {
+			return models.AsyncDecision{}, err
+		}
+		return usecase.asyncDecisionRepository.GetAsyncDecision(ctx, tx, id)
+	})
+}
+
+// GetAsyncDecision returns the status of an asynchronous decision, and the decision once it has been taken
+func (usecase *DecisionUsecase) GetAsyncDecision(ctx context.Context, id string) (models.AsyncDecisionWithResult, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	asyncDecision, err := usecase.asyncDecisionRepository.GetAsyncDecision(ctx, exec, id)
+	if err != nil {
+		return models.AsyncDecisionWithResult{}, err
+	}
+	if err := usecase.enforceSecurity.ReadAsyncDecision(asyncDecision); err != nil {
+		return models.AsyncDecisionWithResult{}, err
+	}
+
+	result := models.AsyncDecisionWithResult{AsyncDecision: asyncDecision}
+	if asyncDecision.Status == models.AsyncDecisionSuccess {
+		decision, err := usecase.decisionRepository.DecisionWithRuleExecutionsById(ctx, exec, id)
+		if err != nil {
+			return models.AsyncDecisionWithResult{}, err
+		}
+		result.Decision = &decision
+	}
+	return result, nil
+}
+
+// ProcessAsyncDecisions claims asynchronous decision jobs from the job queue and takes the decisions, with at most
+// "concurrency" decisions evaluated at the same time. An asynchronous decision is marked as failed if its payload or
+// scenario is invalid, or once its job has used all its attempts.
+func (usecase *DecisionUsecase) ProcessAsyncDecisions(ctx context.Context, concurrency int) error {
+	return usecase.jobQueueWorker.ProcessJobs(
+		ctx,
+		models.JobKindAsyncDecision,
+		concurrency,
+		concurrency,
+		func(ctx context.Context, job models.Job) error {
+			return usecase.processAsyncDecision(ctx, job.EntityId, job.IsLastAttempt())
+		},
+	)
+}
+
+// processAsyncDecision evaluates a pending asynchronous decision and stores the decision. The status of the
+// asynchronous decision is updated in the same transaction, which fails if it has already been processed.
+func (usecase *DecisionUsecase) processAsyncDecision(ctx context.Context, id string, lastAttempt bool) error {
+	logger := utils.LoggerFromContext(ctx)
+	exec := usecase.executorFactory.NewExecutor()
+	asyncDecision, err := usecase.asyncDecisionRepository.GetAsyncDecision(ctx, exec, id)
+	if err != nil {
+		return err
+	}
+	if asyncDecision.Status != models.AsyncDecisionPending {
+		return nil
+	}
+
+	decision, scenario, err := usecase.evaluateAsyncDecision(ctx, asyncDecision)
+	if err != nil {
+		// retrying does not help if the payload or the scenario is invalid
+		permanent := errors.Is(err, models.BadParameterError) ||
+			errors.Is(err, models.NotFoundError) ||
+			errors.Is(err, models.ErrScenarioTriggerConditionAndTriggerObjectMismatch)
+		if permanent || lastAttempt {
+			if err2 := usecase.failAsyncDecision(ctx, asyncDecision, err); err2 != nil {
+				return errors.Join(err, err2)
+			}
+		}
+		if permanent {
+			return nil
+		}
+		return err
+	}
+
+	var sendWebhookEventIds []string
+	err = usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		if err := usecase.asyncDecisionRepository.UpdateAsyncDecisionStatus(ctx, tx, asyncDecision.Id,
+			models.AsyncDecisionSuccess, nil); err != nil {
+			return err
+		}
+		var err error
+		_, sendWebhookEventIds, err = usecase.storeDecisions(ctx, tx, asyncDecision.OrganizationId,
+			[]decisionAndScenario{{decision: decision, scenario: scenario}})
+		return err
+	})
+	if errors.Is(err, repositories.ErrAsyncDecisionNotPending) {
+		logger.InfoContext(ctx, fmt.Sprintf("async decision %s has already been processed", asyncDecision.Id))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, webhookEventId := range sendWebhookEventIds {
+		usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	}
+	return nil
+}
+
+func (usecase *DecisionUsecase) evaluateAsyncDecision(
+	ctx context.Context,
+	asyncDecision models.AsyncDecision,
+) (models.DecisionWithRuleExecutions, models.Scenario, error) {
+	scenario, err := usecase.repository.GetScenarioById(ctx, usecase.executorFactory.NewExecutor(),
+		asyncDecision.ScenarioId)
+	if err != nil {
+		return models.DecisionWithRuleExecutions{}, models.Scenario{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, models.ASYNC_DECISION_TIMEOUT)
+	defer cancel()
+	decision, err := usecase.evaluateDecision(ctx, scenario, nil, asyncDecision.TriggerObjectType,
+		nil, asyncDecision.PayloadRaw)
+	if err != nil {
+		return models.DecisionWithRuleExecutions{}, models.Scenario{}, err
+	}
+	decision.DecisionId = asyncDecision.Id
+	return decision, scenario, nil
+}
+
+// failAsyncDecision marks a pending asynchronous decision as failed and sends a webhook event. The client only sees
+// a generic reason of the failure: the error is logged.
+func (usecase *DecisionUsecase) failAsyncDecision(
+	ctx context.Context,
+	asyncDecision models.AsyncDecision,
+	evaluationErr error,
+) error {
+	utils.LoggerFromContext(ctx).WarnContext(ctx,
+		fmt.Sprintf("async decision %s failed: %s", asyncDecision.Id, evaluationErr.Error()))
+
+	errorMessage := models.AsyncDecisionErrorMessage(evaluationErr)
+	webhookEventId := uuid.NewString()
+	err := usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		if err := usecase.asyncDecisionRepository.UpdateAsyncDecisionStatus(ctx, tx, asyncDecision.Id,
+			models.AsyncDecisionFailure, &errorMessage); err != nil {
+			return err
+		}
+		return usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
+			Id:             webhookEventId,
+			OrganizationId: asyncDecision.OrganizationId,
+			EventContent:   models.NewWebhookEventAsyncDecisionFailed(asyncDecision.Id, errorMessage),
+		})
+	})
+	if errors.Is(err, repositories.ErrAsyncDecisionNotPending) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	return nil
+}
+
+type decisionAndScenario struct {
+	decision models.DecisionWithRuleExecutions
+	scenario models.Scenario
+}
+
+// storeDecisions stores the decisions, creates their webhook events and runs the case workflows of their scenarios.
+// Returns the ids of the decisions and of the webhook events to send once the transaction is committed.
+func (usecase *DecisionUsecase) storeDecisions(
+	ctx context.Context,
+	tx repositories.Executor,
+	organizationId string,
+	items []decisionAndScenario,
+) (decisionIds []string, sendWebhookEventIds []string, err error) {
+	decisionIds = make([]string, 0, len(items))
+	sendWebhookEventIds = make([]string, 0, len(items))
+	for _, item := range items {
+		decisionIds = append(decisionIds, item.decision.DecisionId)
+		if err = usecase.decisionRepository.StoreDecision(
+			ctx,
+			tx,
+			item.decision,
+			organizationId,
+			item.decision.DecisionId,
+		); err != nil {
+			return nil, nil, fmt.Errorf("error storing decision: %w", err)
+		}
+
+		webhookEventId := uuid.NewString()
+		err := usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
+			Id:             webhookEventId,
+			OrganizationId: item.decision.OrganizationId,
+			EventContent:   models.NewWebhookEventDecisionCreated(item.decision.DecisionId, item.decision.OutputValues),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		sendWebhookEventIds = append(sendWebhookEventIds, webhookEventId)
+
+		caseWebhookEventId := uuid.NewString()
+		webhookEventCreated, err := usecase.decisionWorkflows.AutomaticDecisionToCase(
+			ctx, tx, item.scenario, item.decision, caseWebhookEventId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if webhookEventCreated {
+			sendWebhookEventIds = append(sendWebhookEventIds, caseWebhookEventId)
+		}
+	}
+	return decisionIds, sendWebhookEventIds, nil
+}
+
+// CreatePolicyDecision runs the scenarios of the decision policy of the trigger object type in the order of the
+// policy, and aggregates their decisions into a single outcome. The decisions are stored like the ones of
+// CreateAllDecisions, and linked to a parent policy decision.
+func (usecase *DecisionUsecase) CreatePolicyDecision(
+	ctx context.Context,
+	input models.CreatePolicyDecisionInput,
+) (models.PolicyDecisionWithDecisions, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	tracer := utils.OpenTelemetryTracerFromContext(ctx)
+	ctx, span := tracer.Start(ctx, "DecisionUsecase.CreatePolicyDecision")
+	defer span.End()
+
+	if err := usecase.enforceSecurity.CreateDecision(input.OrganizationId); err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+	if err := usecase.enforceSecurity.DecideOnTriggerObject(input.TriggerObjectTable); err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+
+	policy, err := usecase.decisionPolicyRepository.GetDecisionPolicyOfTriggerObjectType(ctx, exec,
+		input.OrganizationId, input.TriggerObjectTable)
+	if errors.Is(err, models.NotFoundError) {
+		return models.PolicyDecisionWithDecisions{}, errors.Wrapf(err,
+			"no decision policy for trigger object type %s", input.TriggerObjectTable)
+	} else if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+
+	payload, dataModel, err := usecase.validatePayload(ctx, input.OrganizationId, input.TriggerObjectTable,
+		nil, input.PayloadRaw)
+	if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+
+	pivotsMeta, err := usecase.dataModelRepository.ListPivots(ctx, exec, input.OrganizationId, nil)
+	if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+	pivot := models.FindPivot(pivotsMeta, input.TriggerObjectTable, dataModel)
+
+	evaluationRepositories := evaluate_scenario.ScenarioEvaluationRepositories{
+		EvalScenarioRepository:     usecase.repository,
+		ExecutorFactory:            usecase.executorFactory,
+		IngestedDataReadRepository: usecase.ingestedDataReadRepository,
+		EvaluateAstExpression:      usecase.evaluateAstExpression,
+		SnoozeReader:               usecase.snoozesReader,
+	}
+
+	policyDecision := models.PolicyDecision{
+		Id:                 uuid.NewString(),
+		OrganizationId:     input.OrganizationId,
+		DecisionPolicyId:   &policy.Id,
+		TriggerObjectType:  input.TriggerObjectTable,
+		SkippedScenarioIds: make([]string, 0),
+		CreatedAt:          time.Now(),
+	}
+	var items []decisionAndScenario
+	for i, scenarioId := range policy.ScenarioIds {
+		scenario, err := usecase.repository.GetScenarioById(ctx, exec, scenarioId)
+		if errors.Is(err, models.NotFoundError) {
+			policyDecision.SkippedScenarioIds = append(policyDecision.SkippedScenarioIds, scenarioId)
+			continue
+		} else if err != nil {
+			return models.PolicyDecisionWithDecisions{}, err
+		}
+		if err := usecase.enforceSecurityScenario.ReadScenario(scenario); err != nil {
+			return models.PolicyDecisionWithDecisions{}, err
+		}
+		if scenario.LiveVersionID == nil || usecase.enforceSecurity.DecideOnScenario(scenario) != nil {
+			policyDecision.SkippedScenarioIds = append(policyDecision.SkippedScenarioIds, scenarioId)
+			continue
+		}
+
+		scenarioExecution, err := usecase.evalScenarioWithTimeout(ctx, evaluate_scenario.ScenarioEvaluationParameters{
+			Scenario:     scenario,
+			ClientObject: payload,
+			DataModel:    dataModel,
+			Pivot:        pivot,
+		}, evaluationRepositories)
+		if errors.Is(err, models.ErrScenarioTriggerConditionAndTriggerObjectMismatch) {
+			policyDecision.SkippedScenarioIds = append(policyDecision.SkippedScenarioIds, scenarioId)
+			continue
+		} else if err != nil {
+			return models.PolicyDecisionWithDecisions{}, errors.Wrap(err,
+				"error evaluating scenario in CreatePolicyDecision")
+		}
+
+		decision := models.AdaptScenarExecToDecision(scenarioExecution, payload, nil)
+		items = append(items, decisionAndScenario{decision: decision, scenario: scenario})
+
+		if policy.StopOnReject && decision.Outcome == models.Reject {
+			remaining := policy.ScenarioIds[i+1:]
+			policyDecision.SkippedScenarioIds = append(policyDecision.SkippedScenarioIds, remaining...)
+			policyDecision.StoppedEarly = len(remaining) > 0
+			break
+		}
+	}
+
+	childDecisions := make([]models.Decision, len(items))
+	for i, item := range items {
+		childDecisions[i] = item.decision.Decision
+	}
+	policyDecision.Outcome, policyDecision.Score = policy.Aggregate(childDecisions)
+
+	var sendWebhookEventIds []string
+	decisions, err := executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) ([]models.DecisionWithRuleExecutions, error) {
+		var err error
+		policyDecision.DecisionIds, sendWebhookEventIds, err = usecase.storeDecisions(ctx, tx,
+			input.OrganizationId, items)
+		if err != nil {
+			return nil, err
+		}
+		if err := usecase.decisionPolicyRepository.StorePolicyDecision(ctx, tx, policyDecision); err != nil {
+			return nil, err
+		}
+		return usecase.decisionRepository.DecisionsWithRuleExecutionsByIds(ctx, tx, policyDecision.DecisionIds)
+	})
+	if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+
+	for _, webhookEventId := range sendWebhookEventIds {
+		usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	}
+
+	return models.PolicyDecisionWithDecisions{
+		PolicyDecision: policyDecision,
+		Decisions:      sortDecisionsByIds(decisions, policyDecision.DecisionIds),
+	}, nil
+}
+
+func (usecase *DecisionUsecase) GetPolicyDecision(ctx context.Context, id string) (models.PolicyDecisionWithDecisions, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	policyDecision, err := usecase.decisionPolicyRepository.GetPolicyDecision(ctx, exec, id)
+	if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+	if err := usecase.enforceSecurity.ReadPolicyDecision(policyDecision); err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+
+	decisions, err := usecase.decisionRepository.DecisionsWithRuleExecutionsByIds(ctx, exec, policyDecision.DecisionIds)
+	if err != nil {
+		return models.PolicyDecisionWithDecisions{}, err
+	}
+	return models.PolicyDecisionWithDecisions{
+		PolicyDecision: policyDecision,
+		Decisions:      sortDecisionsByIds(decisions, policyDecision.DecisionIds),
+	}, nil
+}
+
+func (usecase *DecisionUsecase) evalScenarioWithTimeout(
+	ctx context.Context,
+	params evaluate_scenario.ScenarioEvaluationParameters,
+	repositories evaluate_scenario.ScenarioEvaluationRepositories,
+) (models.ScenarioExecution, error) {
+	ctx, cancel := context.WithTimeout(ctx, models.DECISION_TIMEOUT)
+	defer cancel()
+	return evaluate_scenario.EvalScenario(ctx, params, repositories)
+}
+
+// sortDecisionsByIds returns the decisions in the order of the ids
+func sortDecisionsByIds(decisions []models.DecisionWithRuleExecutions, ids []string) []models.DecisionWithRuleExecutions {
+	positions := make(map[string]int, len(ids))
+	for i, id := range ids {
+		positions[id] = i
+	}
+	sorted := slices.Clone(decisions)
+	slices.SortFunc(sorted, func(a, b models.DecisionWithRuleExecutions) int {
+		return positions[a.DecisionId] - positions[b.DecisionId]
+	})
+	return sorted
+}
+
+// decisionsOfIdempotencyKey returns the decisions (and number of skipped scenarios) created by a previous request made
+// with the same idempotency key, if the key has not expired. Returns a conflict error if the previous request was different.
+func (usecase *DecisionUsecase) decisionsOfIdempotencyKey(
+	ctx context.Context,
+	organizationId string,
+	scope models.IdempotencyKeyScope,
+	key string,
+	requestHash string,
+) (decisions []models.DecisionWithRuleExecutions, nbSkipped int, found bool, err error) {
+	exec := usecase.executorFactory.NewExecutor()
+	storedKey, err := usecase.idempotencyKeyRepository.GetIdempotencyKey(ctx, exec, organizationId, scope, key)
+	if err != nil || storedKey == nil {
+		return nil, 0, false, err
+	}
+	if storedKey.RequestHash != requestHash {
+		return nil, 0, false, errors.Wrapf(models.ErrIdempotencyKeyReused,
+			"idempotency key %s was used with a different request", key)
+	}
+
+	decisions, err = usecase.decisionRepository.DecisionsWithRuleExecutionsByIds(ctx, exec, storedKey.DecisionIds)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if scope == models.IdempotencyKeyScopeCreateDecision && len(decisions) == 0 {
+		return nil, 0, false, errors.Wrapf(models.NotFoundError,
+			"decision of idempotency key %s not found", key)
+	}
+	return decisions, storedKey.NbSkipped, true, nil
+}
+
+// storeIdempotencyKey records the decisions created for an idempotency key. If a concurrent request with the same key
+// has stored its decisions first, it returns errIdempotencyKeyAlreadyStored so that the transaction is rolled back.
+func (usecase *DecisionUsecase) storeIdempotencyKey(
+	ctx context.Context,
+	tx repositories.Executor,
+	input models.IdempotencyKeyCreate,
+) error {
+	input.ExpiresAt = time.Now().Add(usecase.idempotencyKeyRetention)
+	created, err := usecase.idempotencyKeyRepository.CreateIdempotencyKey(ctx, tx, input)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errIdempotencyKeyAlreadyStored
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes the idempotency keys past their retention period
+func (usecase *DecisionUsecase) PurgeExpiredIdempotencyKeys(ctx context.Context) error {
+	return usecase.idempotencyKeyRepository.DeleteExpiredIdempotencyKeys(ctx, usecase.executorFactory.NewExecutor())
+}
+
 // used in different contexts, so allow different cases of input: pass client object or raw payload
 func (usecase DecisionUsecase) validatePayload(
 	ctx context.Context,
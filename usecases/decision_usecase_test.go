@@ -0,0 +1,831 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/dto"
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/ast_eval"
+	"github.com/checkmarble/marble-backend/usecases/jobqueue"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type DecisionUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity          *mocks.EnforceSecurity
+	repository               *mocks.DecisionUsecaseRepository
+	decisionRepository       *mocks.DecisionRepository
+	idempotencyKeyRepository *mocks.IdempotencyKeyRepository
+	dataModelRepository      *mocks.DataModelRepository
+	decisionPolicyRepository *mocks.DecisionPolicyRepository
+	webhookEventsSender      *mocks.WebhookEventsSender
+	decisionWorkflows        *mocks.DecisionWorkflows
+	asyncDecisionRepository  *mocks.AsyncDecisionRepository
+	jobEnqueuer              *mocks.JobEnqueuer
+	exec                     *mocks.Executor
+	transaction              *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	scenario       models.Scenario
+	payload        json.RawMessage
+}
+
+func (suite *DecisionUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.DecisionUsecaseRepository)
+	suite.decisionRepository = new(mocks.DecisionRepository)
+	suite.idempotencyKeyRepository = new(mocks.IdempotencyKeyRepository)
+	suite.dataModelRepository = new(mocks.DataModelRepository)
+	suite.decisionPolicyRepository = new(mocks.DecisionPolicyRepository)
+	suite.webhookEventsSender = new(mocks.WebhookEventsSender)
+	suite.decisionWorkflows = new(mocks.DecisionWorkflows)
+	suite.asyncDecisionRepository = new(mocks.AsyncDecisionRepository)
+	suite.jobEnqueuer = new(mocks.JobEnqueuer)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "25ab6323-1657-4a52-923a-ef6983fe4532"
+	suite.scenario = models.Scenario{
+		Id:                "scenario_id",
+		OrganizationId:    suite.organizationId,
+		TriggerObjectType: "transactions",
+	}
+	suite.payload = json.RawMessage(`{"object_id": "transaction_id", "amount": 100}`)
+}
+
+func (suite *DecisionUsecaseTestSuite) makeUsecase() *DecisionUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &DecisionUsecase{
+		enforceSecurity:          suite.enforceSecurity,
+		enforceSecurityScenario:  suite.enforceSecurity,
+		executorFactory:          executorFactory,
+		transactionFactory:       transactionFactory,
+		repository:               suite.repository,
+		decisionRepository:       suite.decisionRepository,
+		idempotencyKeyRepository: suite.idempotencyKeyRepository,
+		idempotencyKeyRetention:  models.DEFAULT_IDEMPOTENCY_KEY_RETENTION,
+		dataModelRepository:      suite.dataModelRepository,
+		decisionPolicyRepository: suite.decisionPolicyRepository,
+		webhookEventsSender:      suite.webhookEventsSender,
+		decisionWorkflows:        suite.decisionWorkflows,
+		asyncDecisionRepository:  suite.asyncDecisionRepository,
+		jobEnqueuer:              suite.jobEnqueuer,
+		evaluateAstExpression: ast_eval.EvaluateAstExpression{
+			AstEvaluationEnvironmentFactory: func(ast_eval.EvaluationEnvironmentFactoryParams) ast_eval.AstEvaluationEnvironment {
+				return ast_eval.NewAstEvaluationEnvironment()
+			},
+		},
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.decisionRepository.AssertExpectations(t)
+	suite.idempotencyKeyRepository.AssertExpectations(t)
+	suite.dataModelRepository.AssertExpectations(t)
+	suite.decisionPolicyRepository.AssertExpectations(t)
+	suite.webhookEventsSender.AssertExpectations(t)
+	suite.decisionWorkflows.AssertExpectations(t)
+	suite.asyncDecisionRepository.AssertExpectations(t)
+	suite.jobEnqueuer.AssertExpectations(t)
+}
+
+func (suite *DecisionUsecaseTestSuite) createDecisionInput() models.CreateDecisionInput {
+	return models.CreateDecisionInput{
+		OrganizationId:     suite.organizationId,
+		PayloadRaw:         suite.payload,
+		ScenarioId:         suite.scenario.Id,
+		TriggerObjectTable: suite.scenario.TriggerObjectType,
+		IdempotencyKey:     "idempotency_key",
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) requestHash(scope models.IdempotencyKeyScope, scenarioId string) string {
+	hash, err := models.IdempotencyRequestHash(scope, scenarioId, suite.scenario.TriggerObjectType, suite.payload)
+	suite.Require().NoError(err)
+	return hash
+}
+
+func (suite *DecisionUsecaseTestSuite) expectCreateDecisionPermissions() {
+	suite.enforceSecurity.On("CreateDecision", suite.organizationId).Return(nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(nil)
+	suite.enforceSecurity.On("DecideOnScenario", suite.scenario).Return(nil)
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateDecision_idempotencyKeyReplayed() {
+	decision := models.DecisionWithRuleExecutions{
+		Decision: models.Decision{DecisionId: "decision_id", OrganizationId: suite.organizationId},
+	}
+	suite.expectCreateDecisionPermissions()
+	// the payload is sent again with another formatting
+	suite.payload = json.RawMessage(`{"amount":100,"object_id":"transaction_id"}`)
+	suite.idempotencyKeyRepository.On("GetIdempotencyKey", suite.exec, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key").
+		Return(&models.IdempotencyKey{
+			RequestHash: suite.requestHash(models.IdempotencyKeyScopeCreateDecision, suite.scenario.Id),
+			DecisionIds: []string{"decision_id"},
+		}, nil)
+	suite.decisionRepository.On("DecisionsWithRuleExecutionsByIds", suite.exec, []string{"decision_id"}).
+		Return([]models.DecisionWithRuleExecutions{decision}, nil)
+
+	result, err := suite.makeUsecase().CreateDecision(suite.ctx, suite.createDecisionInput(), false, true)
+	suite.NoError(err)
+	suite.Equal(decision, result)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "StoreDecision",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateDecision_idempotencyKeyReused() {
+	suite.expectCreateDecisionPermissions()
+	suite.idempotencyKeyRepository.On("GetIdempotencyKey", suite.exec, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key").
+		Return(&models.IdempotencyKey{
+			RequestHash: suite.requestHash(models.IdempotencyKeyScopeCreateDecision, "other_scenario_id"),
+			DecisionIds: []string{"decision_id"},
+		}, nil)
+
+	_, err := suite.makeUsecase().CreateDecision(suite.ctx, suite.createDecisionInput(), false, true)
+	suite.ErrorIs(err, models.ErrIdempotencyKeyReused)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "DecisionsWithRuleExecutionsByIds",
+		mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateDecision_idempotencyKeyTooLong() {
+	suite.expectCreateDecisionPermissions()
+	input := suite.createDecisionInput()
+	input.IdempotencyKey = string(make([]byte, models.IDEMPOTENCY_KEY_MAX_LENGTH+1))
+
+	_, err := suite.makeUsecase().CreateDecision(suite.ctx, input, false, true)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.idempotencyKeyRepository.AssertNotCalled(suite.T(), "GetIdempotencyKey",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateAllDecisions_idempotencyKeyReplayed() {
+	decisions := []models.DecisionWithRuleExecutions{
+		{Decision: models.Decision{DecisionId: "decision_1"}},
+		{Decision: models.Decision{DecisionId: "decision_2"}},
+	}
+	suite.enforceSecurity.On("CreateDecision", suite.organizationId).Return(nil)
+	suite.enforceSecurity.On("DecideOnTriggerObject", suite.scenario.TriggerObjectType).Return(nil)
+	suite.idempotencyKeyRepository.On("GetIdempotencyKey", suite.exec, suite.organizationId,
+		models.IdempotencyKeyScopeCreateAllDecisions, "idempotency_key").
+		Return(&models.IdempotencyKey{
+			RequestHash: suite.requestHash(models.IdempotencyKeyScopeCreateAllDecisions, ""),
+			DecisionIds: []string{"decision_1", "decision_2"},
+			NbSkipped:   3,
+		}, nil)
+	suite.decisionRepository.On("DecisionsWithRuleExecutionsByIds", suite.exec,
+		[]string{"decision_1", "decision_2"}).Return(decisions, nil)
+
+	result, nbSkipped, err := suite.makeUsecase().CreateAllDecisions(suite.ctx, models.CreateAllDecisionsInput{
+		OrganizationId:     suite.organizationId,
+		PayloadRaw:         suite.payload,
+		TriggerObjectTable: suite.scenario.TriggerObjectType,
+		IdempotencyKey:     "idempotency_key",
+	})
+	suite.NoError(err)
+	suite.Equal(decisions, result)
+	suite.Equal(3, nbSkipped)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDecisionsOfIdempotencyKey_notFound() {
+	suite.idempotencyKeyRepository.On("GetIdempotencyKey", suite.exec, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key").
+		Return((*models.IdempotencyKey)(nil), nil)
+
+	decisions, _, found, err := suite.makeUsecase().decisionsOfIdempotencyKey(suite.ctx, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key", "hash")
+	suite.NoError(err)
+	suite.False(found)
+	suite.Empty(decisions)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDecisionsOfIdempotencyKey_decisionDeleted() {
+	suite.idempotencyKeyRepository.On("GetIdempotencyKey", suite.exec, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key").
+		Return(&models.IdempotencyKey{RequestHash: "hash", DecisionIds: []string{"decision_id"}}, nil)
+	suite.decisionRepository.On("DecisionsWithRuleExecutionsByIds", suite.exec, []string{"decision_id"}).
+		Return([]models.DecisionWithRuleExecutions{}, nil)
+
+	_, _, _, err := suite.makeUsecase().decisionsOfIdempotencyKey(suite.ctx, suite.organizationId,
+		models.IdempotencyKeyScopeCreateDecision, "idempotency_key", "hash")
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestStoreIdempotencyKey() {
+	input := models.IdempotencyKeyCreate{
+		OrganizationId: suite.organizationId,
+		Scope:          models.IdempotencyKeyScopeCreateDecision,
+		Key:            "idempotency_key",
+		RequestHash:    "hash",
+		DecisionIds:    []string{"decision_id"},
+	}
+	before := time.Now()
+	suite.idempotencyKeyRepository.On("CreateIdempotencyKey", suite.transaction,
+		mock.MatchedBy(func(stored models.IdempotencyKeyCreate) bool {
+			// the key expires after the retention period
+			return stored.Key == input.Key && stored.RequestHash == input.RequestHash &&
+				!stored.ExpiresAt.Before(before.Add(models.DEFAULT_IDEMPOTENCY_KEY_RETENTION)) &&
+				!stored.ExpiresAt.After(time.Now().Add(models.DEFAULT_IDEMPOTENCY_KEY_RETENTION))
+		})).Return(true, nil)
+
+	err := suite.makeUsecase().storeIdempotencyKey(suite.ctx, suite.transaction, input)
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestStoreIdempotencyKey_storedByConcurrentRequest() {
+	suite.idempotencyKeyRepository.On("CreateIdempotencyKey", suite.transaction, mock.Anything).Return(false, nil)
+
+	err := suite.makeUsecase().storeIdempotencyKey(suite.ctx, suite.transaction, models.IdempotencyKeyCreate{
+		OrganizationId: suite.organizationId,
+		Scope:          models.IdempotencyKeyScopeCreateDecision,
+		Key:            "idempotency_key",
+	})
+	suite.ErrorIs(err, errIdempotencyKeyAlreadyStored)
+	suite.AssertExpectations()
+}
+
+// testIteration returns an iteration whose only rule always matches, adding 15 to the score: the outcome is review
+func (suite *DecisionUsecaseTestSuite) testIteration(id string) models.ScenarioIteration {
+	return models.ScenarioIteration{
+		Id:                            id,
+		ScenarioId:                    suite.scenario.Id,
+		ScoreReviewThreshold:          utils.Ptr(10),
+		ScoreRejectThreshold:          utils.Ptr(20),
+		TriggerConditionAstExpression: utils.Ptr(ast.NewNodeConstant(true)),
+		Rules: []models.Rule{{
+			Id:                   "rule_id",
+			Name:                 "always matches",
+			FormulaAstExpression: utils.Ptr(ast.NewNodeConstant(true)),
+			ScoreModifier:        15,
+		}},
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) expectEvaluation(iteration models.ScenarioIteration) {
+	suite.expectDataModel()
+	suite.expectPivots()
+	suite.repository.On("GetScenarioIteration", suite.exec, iteration.Id).Return(iteration, nil)
+}
+
+func (suite *DecisionUsecaseTestSuite) expectDataModel() {
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.exec, suite.organizationId, false).
+		Return(models.DataModel{Tables: map[string]models.Table{
+			"transactions": {
+				Name: "transactions",
+				Fields: map[string]models.Field{
+					"object_id":  {Name: "object_id", DataType: models.String},
+					"updated_at": {Name: "updated_at", DataType: models.Timestamp},
+					"amount":     {Name: "amount", DataType: models.Float},
+				},
+			},
+		}}, nil)
+}
+
+func (suite *DecisionUsecaseTestSuite) expectPivots() {
+	suite.dataModelRepository.On("ListPivots", mock.Anything, suite.exec, suite.organizationId, (*string)(nil)).
+		Return([]models.PivotMetadata{}, nil)
+}
+
+func (suite *DecisionUsecaseTestSuite) dryRunInput() models.DryRunDecisionInput {
+	return models.DryRunDecisionInput{
+		OrganizationId:     suite.organizationId,
+		PayloadRaw:         json.RawMessage(`{"object_id": "transaction_id", "updated_at": "2024-09-01T00:00:00Z", "amount": 100}`),
+		ScenarioId:         suite.scenario.Id,
+		TriggerObjectTable: suite.scenario.TriggerObjectType,
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDryRunDecision_liveVersion() {
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.expectCreateDecisionPermissions()
+	suite.expectEvaluation(suite.testIteration("live_iteration_id"))
+
+	decision, err := suite.makeUsecase().DryRunDecision(suite.ctx, suite.dryRunInput())
+	suite.NoError(err)
+	suite.Equal("live_iteration_id", decision.ScenarioIterationId)
+	suite.Equal(15, decision.Score)
+	suite.Equal(models.Review, decision.Outcome)
+	suite.Len(decision.RuleExecutions, 1)
+	suite.True(decision.RuleExecutions[0].Result)
+	// the decision is not stored
+	suite.decisionRepository.AssertNotCalled(suite.T(), "StoreDecision",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDryRunDecision_draftIteration() {
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	draft := suite.testIteration("draft_iteration_id")
+	draft.Rules[0].ScoreModifier = 25
+	suite.expectEvaluation(draft)
+	suite.enforceSecurity.On("DryRunScenarioIteration", draft).Return(nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(nil)
+	suite.enforceSecurity.On("DecideOnScenario", suite.scenario).Return(nil)
+	input := suite.dryRunInput()
+	input.ScenarioId = ""
+	input.ScenarioIterationId = &draft.Id
+
+	decision, err := suite.makeUsecase().DryRunDecision(suite.ctx, input)
+	suite.NoError(err)
+	suite.Equal("draft_iteration_id", decision.ScenarioIterationId)
+	suite.Equal(25, decision.Score)
+	suite.Equal(models.Reject, decision.Outcome)
+	suite.enforceSecurity.AssertNotCalled(suite.T(), "CreateDecision", mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDryRunDecision_iterationForbidden() {
+	draft := suite.testIteration("draft_iteration_id")
+	suite.repository.On("GetScenarioIteration", suite.exec, draft.Id).Return(draft, nil)
+	suite.enforceSecurity.On("DryRunScenarioIteration", draft).Return(models.ForbiddenError)
+	input := suite.dryRunInput()
+	input.ScenarioIterationId = &draft.Id
+
+	_, err := suite.makeUsecase().DryRunDecision(suite.ctx, input)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioById", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestDryRunDecision_invalidPayload() {
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.expectCreateDecisionPermissions()
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.exec, suite.organizationId, false).
+		Return(models.DataModel{Tables: map[string]models.Table{
+			"transactions": {Name: "transactions", Fields: map[string]models.Field{
+				"object_id":  {Name: "object_id", DataType: models.String},
+				"updated_at": {Name: "updated_at", DataType: models.Timestamp},
+			}},
+		}}, nil)
+	input := suite.dryRunInput()
+	input.PayloadRaw = json.RawMessage(`{"object_id": "transaction_id"}`)
+
+	_, err := suite.makeUsecase().DryRunDecision(suite.ctx, input)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioIteration", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+// policyScenario returns a live scenario of the trigger object type, whose decisions have the score
+func (suite *DecisionUsecaseTestSuite) policyScenario(id string, score int) models.Scenario {
+	scenario := models.Scenario{
+		Id:                id,
+		OrganizationId:    suite.organizationId,
+		TriggerObjectType: "transactions",
+		LiveVersionID:     utils.Ptr(id + "_iteration"),
+	}
+	iteration := suite.testIteration(id + "_iteration")
+	iteration.ScenarioId = id
+	iteration.Rules[0].ScoreModifier = score
+	suite.repository.On("GetScenarioById", suite.exec, id).Return(scenario, nil)
+	suite.repository.On("GetScenarioIteration", suite.exec, iteration.Id).Return(iteration, nil)
+	return scenario
+}
+
+func (suite *DecisionUsecaseTestSuite) expectPolicyDecisionStored(scenarios ...models.Scenario) {
+	for _, scenario := range scenarios {
+		suite.enforceSecurity.On("ReadScenario", scenario).Return(nil)
+		suite.enforceSecurity.On("DecideOnScenario", scenario).Return(nil)
+		suite.decisionWorkflows.On("AutomaticDecisionToCase", suite.transaction, scenario,
+			mock.Anything, mock.Anything).Return(false, nil)
+	}
+	suite.decisionRepository.On("StoreDecision", suite.transaction, mock.Anything, suite.organizationId,
+		mock.Anything).Return(nil).Times(len(scenarios))
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction, mock.Anything).
+		Return(nil).Times(len(scenarios))
+	suite.webhookEventsSender.On("SendWebhookEventAsync", mock.Anything).Return().Times(len(scenarios))
+	suite.decisionRepository.On("DecisionsWithRuleExecutionsByIds", suite.transaction, mock.Anything).
+		Return([]models.DecisionWithRuleExecutions{}, nil)
+}
+
+func (suite *DecisionUsecaseTestSuite) policyDecisionInput() models.CreatePolicyDecisionInput {
+	return models.CreatePolicyDecisionInput{
+		OrganizationId:     suite.organizationId,
+		PayloadRaw:         suite.dryRunInput().PayloadRaw,
+		TriggerObjectTable: "transactions",
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreatePolicyDecision_stopOnReject() {
+	review := suite.policyScenario("review_scenario", 15)
+	reject := suite.policyScenario("reject_scenario", 25)
+	policy := models.DecisionPolicy{
+		Id:                "policy_id",
+		OrganizationId:    suite.organizationId,
+		TriggerObjectType: "transactions",
+		ScenarioIds:       []string{"missing_scenario", review.Id, reject.Id, "not_evaluated_scenario"},
+		StopOnReject:      true,
+		Aggregation:       models.DecisionPolicyAggregationWorstOutcome,
+	}
+	suite.enforceSecurity.On("CreateDecision", suite.organizationId).Return(nil)
+	suite.enforceSecurity.On("DecideOnTriggerObject", "transactions").Return(nil)
+	suite.decisionPolicyRepository.On("GetDecisionPolicyOfTriggerObjectType", suite.exec, suite.organizationId,
+		"transactions").Return(policy, nil)
+	suite.expectDataModel()
+	suite.expectPivots()
+	suite.repository.On("GetScenarioById", suite.exec, "missing_scenario").
+		Return(models.Scenario{}, models.NotFoundError)
+	suite.expectPolicyDecisionStored(review, reject)
+	suite.decisionPolicyRepository.On("StorePolicyDecision", suite.transaction,
+		mock.MatchedBy(func(policyDecision models.PolicyDecision) bool {
+			return len(policyDecision.DecisionIds) == 2 && policyDecision.Outcome == models.Reject &&
+				policyDecision.Score == 40 && policyDecision.StoppedEarly
+		})).Return(nil)
+
+	result, err := suite.makeUsecase().CreatePolicyDecision(suite.ctx, suite.policyDecisionInput())
+	suite.NoError(err)
+	suite.Equal(models.Reject, result.Outcome)
+	suite.Equal(40, result.Score)
+	suite.True(result.StoppedEarly)
+	suite.Equal([]string{"missing_scenario", "not_evaluated_scenario"}, result.SkippedScenarioIds)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioById", suite.exec, "not_evaluated_scenario")
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreatePolicyDecision_scoreSum() {
+	first := suite.policyScenario("first_scenario", 15)
+	second := suite.policyScenario("second_scenario", 25)
+	policy := models.DecisionPolicy{
+		Id:                   "policy_id",
+		OrganizationId:       suite.organizationId,
+		TriggerObjectType:    "transactions",
+		ScenarioIds:          []string{first.Id, second.Id},
+		StopOnReject:         false,
+		Aggregation:          models.DecisionPolicyAggregationScoreSum,
+		ScoreReviewThreshold: utils.Ptr(30),
+		ScoreRejectThreshold: utils.Ptr(50),
+	}
+	suite.enforceSecurity.On("CreateDecision", suite.organizationId).Return(nil)
+	suite.enforceSecurity.On("DecideOnTriggerObject", "transactions").Return(nil)
+	suite.decisionPolicyRepository.On("GetDecisionPolicyOfTriggerObjectType", suite.exec, suite.organizationId,
+		"transactions").Return(policy, nil)
+	suite.expectDataModel()
+	suite.expectPivots()
+	suite.expectPolicyDecisionStored(first, second)
+	suite.decisionPolicyRepository.On("StorePolicyDecision", suite.transaction, mock.Anything).Return(nil)
+
+	result, err := suite.makeUsecase().CreatePolicyDecision(suite.ctx, suite.policyDecisionInput())
+	suite.NoError(err)
+	// the second decision is a rejection, but the sum of the scores is below the reject threshold of the policy
+	suite.Equal(models.Review, result.Outcome)
+	suite.Equal(40, result.Score)
+	suite.False(result.StoppedEarly)
+	suite.Empty(result.SkippedScenarioIds)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreatePolicyDecision_noPolicy() {
+	suite.enforceSecurity.On("CreateDecision", suite.organizationId).Return(nil)
+	suite.enforceSecurity.On("DecideOnTriggerObject", "transactions").Return(nil)
+	suite.decisionPolicyRepository.On("GetDecisionPolicyOfTriggerObjectType", suite.exec, suite.organizationId,
+		"transactions").Return(models.DecisionPolicy{}, models.NotFoundError)
+
+	_, err := suite.makeUsecase().CreatePolicyDecision(suite.ctx, suite.policyDecisionInput())
+	suite.ErrorIs(err, models.NotFoundError)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) userContext(userId models.UserId) context.Context {
+	return context.WithValue(suite.ctx, utils.ContextKeyCredentials, models.Credentials{
+		OrganizationId: suite.organizationId,
+		ActorIdentity:  models.Identity{UserId: userId},
+	})
+}
+
+func (suite *DecisionUsecaseTestSuite) TestReviewDecision() {
+	decision := models.DecisionWithRuleExecutions{Decision: models.Decision{
+		DecisionId:     "decision_id",
+		OrganizationId: suite.organizationId,
+	}}
+	reviewed := decision
+	reviewed.Review = &models.DecisionReview{Disposition: models.DecisionReviewConfirmedFraud}
+	suite.decisionRepository.On("DecisionWithRuleExecutionsById", suite.transaction, "decision_id").
+		Return(decision, nil).Once()
+	suite.enforceSecurity.On("ReviewDecision", decision.Decision).Return(nil)
+	isExpectedReview := mock.MatchedBy(func(review models.DecisionReview) bool {
+		return review.Disposition == models.DecisionReviewConfirmedFraud && review.Note == "stolen card" &&
+			review.ReviewedBy != nil && *review.ReviewedBy == "user_id"
+	})
+	suite.decisionRepository.On("ReviewDecision", suite.transaction, "decision_id", isExpectedReview).Return(nil)
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction,
+		mock.MatchedBy(func(input models.WebhookEventCreate) bool {
+			return input.OrganizationId == suite.organizationId &&
+				input.EventContent.Type == models.WebhookEventType_DecisionReviewed
+		})).Return(nil)
+	suite.decisionRepository.On("DecisionWithRuleExecutionsById", suite.transaction, "decision_id").
+		Return(reviewed, nil).Once()
+	suite.webhookEventsSender.On("SendWebhookEventAsync", mock.Anything).Return()
+
+	result, err := suite.makeUsecase().ReviewDecision(suite.userContext("user_id"), models.ReviewDecisionInput{
+		DecisionId:  "decision_id",
+		Disposition: models.DecisionReviewConfirmedFraud,
+		Note:        "stolen card",
+	})
+	suite.NoError(err)
+	suite.Equal(reviewed, result)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestReviewDecision_forbidden() {
+	decision := models.DecisionWithRuleExecutions{Decision: models.Decision{
+		DecisionId:     "decision_id",
+		OrganizationId: "other_organization_id",
+	}}
+	suite.decisionRepository.On("DecisionWithRuleExecutionsById", suite.transaction, "decision_id").
+		Return(decision, nil)
+	suite.enforceSecurity.On("ReviewDecision", decision.Decision).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().ReviewDecision(suite.userContext("user_id"), models.ReviewDecisionInput{
+		DecisionId:  "decision_id",
+		Disposition: models.DecisionReviewApproved,
+	})
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "ReviewDecision", mock.Anything, mock.Anything, mock.Anything)
+	suite.webhookEventsSender.AssertNotCalled(suite.T(), "SendWebhookEventAsync", mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestReviewDecision_invalidInput() {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		input models.ReviewDecisionInput
+		err   error
+	}{
+		{
+			name:  "invalid disposition",
+			ctx:   suite.userContext("user_id"),
+			input: models.ReviewDecisionInput{DecisionId: "decision_id", Disposition: "maybe"},
+			err:   models.BadParameterError,
+		},
+		{
+			name:  "API key without user",
+			ctx:   suite.userContext(""),
+			input: models.ReviewDecisionInput{DecisionId: "decision_id", Disposition: models.DecisionReviewApproved},
+			err:   models.ForbiddenError,
+		},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			_, err := suite.makeUsecase().ReviewDecision(test.ctx, test.input)
+			suite.ErrorIs(err, test.err)
+			suite.decisionRepository.AssertNotCalled(suite.T(), "DecisionWithRuleExecutionsById",
+				mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) TestListDecisions_invalidReviewDisposition() {
+	suite.repository.On("ListScenariosOfOrganization", suite.exec, suite.organizationId).
+		Return([]models.Scenario{}, nil)
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.exec, suite.organizationId, true).
+		Return(models.DataModel{}, nil)
+
+	_, err := suite.makeUsecase().ListDecisions(suite.ctx, suite.organizationId, models.PaginationAndSorting{},
+		dto.DecisionFilters{ReviewDispositions: []string{"false_positive", "maybe"}})
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "DecisionsOfOrganization",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DecisionUsecaseTestSuite) asyncDecisionInput() models.CreateAsyncDecisionInput {
+	return models.CreateAsyncDecisionInput{
+		OrganizationId:     suite.organizationId,
+		PayloadRaw:         suite.dryRunInput().PayloadRaw,
+		ScenarioId:         suite.scenario.Id,
+		TriggerObjectTable: suite.scenario.TriggerObjectType,
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateAsyncDecision() {
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.expectCreateDecisionPermissions()
+	suite.expectDataModel()
+	asyncDecision := models.AsyncDecision{Id: "async_decision_id", Status: models.AsyncDecisionPending}
+	var id string
+	suite.asyncDecisionRepository.On("CreateAsyncDecision", suite.transaction, mock.Anything,
+		suite.asyncDecisionInput()).Run(func(args mock.Arguments) { id = args.String(1) }).Return(nil)
+	suite.jobEnqueuer.On("EnqueueJob", suite.transaction, mock.MatchedBy(func(input models.JobEnqueueInput) bool {
+		return input.Kind == models.JobKindAsyncDecision && input.EntityId == id
+	})).Return(nil)
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.transaction, mock.Anything).Return(asyncDecision, nil)
+
+	result, err := suite.makeUsecase().CreateAsyncDecision(suite.ctx, suite.asyncDecisionInput())
+	suite.NoError(err)
+	suite.Equal(asyncDecision, result)
+	// the scenario is only evaluated by the worker
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioIteration", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateAsyncDecision_invalidPayload() {
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.expectCreateDecisionPermissions()
+	suite.expectDataModel()
+	input := suite.asyncDecisionInput()
+	input.PayloadRaw = json.RawMessage(`{"object_id": "transaction_id", "amount": "a lot"}`)
+
+	_, err := suite.makeUsecase().CreateAsyncDecision(suite.ctx, input)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.asyncDecisionRepository.AssertNotCalled(suite.T(), "CreateAsyncDecision",
+		mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestCreateAsyncDecision_noLiveVersion() {
+	suite.expectCreateDecisionPermissions()
+
+	_, err := suite.makeUsecase().CreateAsyncDecision(suite.ctx, suite.asyncDecisionInput())
+	suite.ErrorIs(err, models.ErrScenarioHasNoLiveVersion)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestGetAsyncDecision() {
+	pending := models.AsyncDecision{Id: "pending_id", OrganizationId: suite.organizationId,
+		Status: models.AsyncDecisionPending}
+	success := models.AsyncDecision{Id: "success_id", OrganizationId: suite.organizationId,
+		Status: models.AsyncDecisionSuccess}
+	decision := models.DecisionWithRuleExecutions{Decision: models.Decision{DecisionId: "success_id"}}
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, "pending_id").Return(pending, nil)
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, "success_id").Return(success, nil)
+	suite.enforceSecurity.On("ReadAsyncDecision", pending).Return(nil)
+	suite.enforceSecurity.On("ReadAsyncDecision", success).Return(nil)
+	suite.decisionRepository.On("DecisionWithRuleExecutionsById", suite.exec, "success_id").Return(decision, nil)
+	usecase := suite.makeUsecase()
+
+	result, err := usecase.GetAsyncDecision(suite.ctx, "pending_id")
+	suite.NoError(err)
+	suite.Equal(models.AsyncDecisionWithResult{AsyncDecision: pending}, result)
+
+	result, err = usecase.GetAsyncDecision(suite.ctx, "success_id")
+	suite.NoError(err)
+	suite.Equal(models.AsyncDecisionWithResult{AsyncDecision: success, Decision: &decision}, result)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestGetAsyncDecision_forbidden() {
+	asyncDecision := models.AsyncDecision{Id: "async_decision_id", OrganizationId: "other_organization_id",
+		Status: models.AsyncDecisionSuccess}
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.enforceSecurity.On("ReadAsyncDecision", asyncDecision).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().GetAsyncDecision(suite.ctx, asyncDecision.Id)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "DecisionWithRuleExecutionsById", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) pendingAsyncDecision() models.AsyncDecision {
+	return models.AsyncDecision{
+		Id:                "8c4b7f4e-6d3a-4b0e-9f1e-2a5c6d7e8f90",
+		OrganizationId:    suite.organizationId,
+		ScenarioId:        suite.scenario.Id,
+		TriggerObjectType: suite.scenario.TriggerObjectType,
+		PayloadRaw:        suite.dryRunInput().PayloadRaw,
+		Status:            models.AsyncDecisionPending,
+	}
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision() {
+	asyncDecision := suite.pendingAsyncDecision()
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.expectEvaluation(suite.testIteration("live_iteration_id"))
+	suite.asyncDecisionRepository.On("UpdateAsyncDecisionStatus", suite.transaction, asyncDecision.Id,
+		models.AsyncDecisionSuccess, (*string)(nil)).Return(nil)
+	// the decision has the id of the asynchronous decision
+	suite.decisionRepository.On("StoreDecision", suite.transaction,
+		mock.MatchedBy(func(decision models.DecisionWithRuleExecutions) bool {
+			return decision.DecisionId == asyncDecision.Id && decision.Outcome == models.Review
+		}), suite.organizationId, asyncDecision.Id).Return(nil)
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction, mock.Anything).Return(nil)
+	suite.decisionWorkflows.On("AutomaticDecisionToCase", suite.transaction, suite.scenario,
+		mock.Anything, mock.Anything).Return(false, nil)
+	suite.webhookEventsSender.On("SendWebhookEventAsync", mock.Anything).Return()
+
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, false)
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision_alreadyProcessed() {
+	asyncDecision := suite.pendingAsyncDecision()
+	asyncDecision.Status = models.AsyncDecisionSuccess
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, false)
+	suite.NoError(err)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioById", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision_processedConcurrently() {
+	asyncDecision := suite.pendingAsyncDecision()
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.expectEvaluation(suite.testIteration("live_iteration_id"))
+	suite.asyncDecisionRepository.On("UpdateAsyncDecisionStatus", suite.transaction, asyncDecision.Id,
+		models.AsyncDecisionSuccess, (*string)(nil)).Return(repositories.ErrAsyncDecisionNotPending)
+
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, false)
+	suite.NoError(err)
+	suite.decisionRepository.AssertNotCalled(suite.T(), "StoreDecision",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.webhookEventsSender.AssertNotCalled(suite.T(), "SendWebhookEventAsync", mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision_invalidPayload() {
+	asyncDecision := suite.pendingAsyncDecision()
+	asyncDecision.PayloadRaw = json.RawMessage(`{"object_id": "transaction_id", "internal": "secret"}`)
+	suite.scenario.LiveVersionID = utils.Ptr("live_iteration_id")
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.expectDataModel()
+	// the client only sees a generic reason of the failure
+	expectedMessage := "the trigger object is not valid for the data model"
+	suite.asyncDecisionRepository.On("UpdateAsyncDecisionStatus", suite.transaction, asyncDecision.Id,
+		models.AsyncDecisionFailure, &expectedMessage).Return(nil)
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction,
+		mock.MatchedBy(func(input models.WebhookEventCreate) bool {
+			return reflect.DeepEqual(input.EventContent.Data["content"], map[string]any{
+				"decision": map[string]any{"id": asyncDecision.Id, "error": expectedMessage},
+			})
+		})).Return(nil)
+	suite.webhookEventsSender.On("SendWebhookEventAsync", mock.Anything).Return()
+
+	// the failure is permanent: the job is not retried
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, false)
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision_retried() {
+	asyncDecision := suite.pendingAsyncDecision()
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).
+		Return(models.Scenario{}, errors.New("connection reset by peer"))
+
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, false)
+	suite.Error(err)
+	suite.asyncDecisionRepository.AssertNotCalled(suite.T(), "UpdateAsyncDecisionStatus",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestProcessAsyncDecision_lastAttempt() {
+	asyncDecision := suite.pendingAsyncDecision()
+	suite.asyncDecisionRepository.On("GetAsyncDecision", suite.exec, asyncDecision.Id).Return(asyncDecision, nil)
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).
+		Return(models.Scenario{}, errors.New("connection reset by peer"))
+	expectedMessage := "the decision could not be evaluated"
+	suite.asyncDecisionRepository.On("UpdateAsyncDecisionStatus", suite.transaction, asyncDecision.Id,
+		models.AsyncDecisionFailure, &expectedMessage).Return(nil)
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction, mock.Anything).Return(nil)
+	suite.webhookEventsSender.On("SendWebhookEventAsync", mock.Anything).Return()
+
+	err := suite.makeUsecase().processAsyncDecision(suite.ctx, asyncDecision.Id, true)
+	suite.Error(err)
+	suite.AssertExpectations()
+}
+
+func (suite *DecisionUsecaseTestSuite) TestAsyncDecisionTimeout() {
+	suite.Less(models.ASYNC_DECISION_TIMEOUT, jobqueue.DEFAULT_LEASE_DURATION,
+		"the evaluation must end before the lease of the job expires")
+}
+
+func TestDecisionUsecase(t *testing.T) {
+	suite.Run(t, new(DecisionUsecaseTestSuite))
+}
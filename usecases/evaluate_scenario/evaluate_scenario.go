@@ -29,6 +29,8 @@ type ScenarioEvaluationParameters struct {
 	ClientObject models.ClientObject
 	DataModel    models.DataModel
 	Pivot        *models.Pivot
+	// Iteration evaluated instead of the live version of the scenario, for dry runs
+	TargetIterationId *string
 }
 
 type EvalScenarioRepository interface {
@@ -80,17 +82,26 @@ func EvalScenario(
 	ctx, span := tracer.Start(ctx, "evaluate_scenario.EvalScenario")
 	defer span.End()
 
+	iterationId := params.Scenario.LiveVersionID
+	if params.TargetIterationId != nil {
+		iterationId = params.TargetIterationId
+	}
+
 	// If the scenario has no live version, don't try to Eval() it, return early
-	if params.Scenario.LiveVersionID == nil {
+	if iterationId == nil {
 		return models.ScenarioExecution{}, errors.Wrap(models.ErrScenarioHasNoLiveVersion,
 			"scenario has no live version in EvalScenario")
 	}
 
-	liveVersion, err := repositories.EvalScenarioRepository.GetScenarioIteration(ctx, exec, *params.Scenario.LiveVersionID)
+	liveVersion, err := repositories.EvalScenarioRepository.GetScenarioIteration(ctx, exec, *iterationId)
 	if err != nil {
 		return models.ScenarioExecution{}, errors.Wrap(err,
 			"error getting scenario iteration in EvalScenario")
 	}
+	if liveVersion.ScenarioId != params.Scenario.Id {
+		return models.ScenarioExecution{}, errors.Wrapf(models.BadParameterError,
+			"scenario iteration %s does not belong to scenario %s", liveVersion.Id, params.Scenario.Id)
+	}
 
 	publishedVersion, err := models.NewPublishedScenarioIteration(liveVersion)
 	if err != nil {
@@ -158,6 +169,9 @@ func EvalScenario(
 			"error during concurrent rule evaluation")
 	}
 
+	outputValues := evalOutputVariables(ctx, repositories, publishedVersion.Body.OutputVariables,
+		dataAccessor, params.DataModel)
+
 	// Compute outcome from score
 	outcome := models.None
 
@@ -180,6 +194,7 @@ func EvalScenario(
 		Score:               score,
 		Outcome:             outcome,
 		OrganizationId:      params.Scenario.OrganizationId,
+		OutputValues:        outputValues,
 	}
 	if params.Pivot != nil {
 		se.PivotId = &params.Pivot.Id
@@ -264,6 +279,45 @@ func evalScenarioRule(
 	return ruleExecution.ResultScoreModifier, ruleExecution, nil
 }
 
+// evalOutputVariables returns the values of the output variables by name. An output variable that cannot be evaluated
+// (e.g. because of a missing field in the payload) does not fail the decision: its value is null.
+func evalOutputVariables(
+	ctx context.Context,
+	repositories ScenarioEvaluationRepositories,
+	outputVariables []models.OutputVariable,
+	dataAccessor DataAccessor,
+	dataModel models.DataModel,
+) map[string]any {
+	tracer := utils.OpenTelemetryTracerFromContext(ctx)
+	ctx, span := tracer.Start(ctx, "evaluate_scenario.evalOutputVariables",
+		trace.WithAttributes(attribute.Int("nb_output_variables", len(outputVariables))))
+	defer span.End()
+	logger := utils.LoggerFromContext(ctx)
+
+	outputValues := make(map[string]any, len(outputVariables))
+	for _, outputVariable := range outputVariables {
+		outputValues[outputVariable.Name] = nil
+		if outputVariable.FormulaAstExpression == nil {
+			continue
+		}
+
+		value, _, err := repositories.EvaluateAstExpression.EvaluateAstExpressionValue(
+			ctx,
+			*outputVariable.FormulaAstExpression,
+			dataAccessor.organizationId,
+			dataAccessor.ClientObject,
+			dataModel,
+		)
+		if err != nil {
+			logger.InfoContext(ctx, fmt.Sprintf("error evaluating output variable: %v", err),
+				slog.String("outputVariable", outputVariable.Name))
+			continue
+		}
+		outputValues[outputVariable.Name] = value
+	}
+	return outputValues
+}
+
 func evalScenarioTrigger(
 	ctx context.Context,
 	repositories ScenarioEvaluationRepositories,
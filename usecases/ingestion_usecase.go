@@ -9,7 +9,6 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/avast/retry-go/v4"
@@ -29,6 +28,8 @@ import (
 
 const (
 	batchSize = 1000
+	// number of upload logs processed at the same time by one instance
+	maxConcurrentCsvIngestions = 10
 )
 
 type IngestionUseCase struct {
@@ -36,9 +37,11 @@ type IngestionUseCase struct {
 	executorFactory     executor_factory.ExecutorFactory
 	enforceSecurity     security.EnforceSecurityIngestion
 	ingestionRepository repositories.IngestionRepository
-	gcsRepository       repositories.GcsRepository
+	blobRepository      repositories.BlobRepository
 	dataModelRepository repositories.DataModelRepository
 	uploadLogRepository repositories.UploadLogRepository
+	jobEnqueuer         jobEnqueuer
+	jobQueueWorker      jobQueueWorker
 	GcsIngestionBucket  string
 }
 
@@ -60,6 +63,9 @@ func (usecase *IngestionUseCase) IngestObjects(
 	if err := usecase.enforceSecurity.CanIngest(organizationId); err != nil {
 		return 0, err
 	}
+	if err := usecase.enforceSecurity.IngestInTable(objectType); err != nil {
+		return 0, err
+	}
 
 	exec := usecase.executorFactory.NewExecutor()
 	dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, exec, organizationId, false)
@@ -117,6 +123,9 @@ func (usecase *IngestionUseCase) ValidateAndUploadIngestionCsv(ctx context.Conte
 	if err := usecase.enforceSecurity.CanIngest(organizationId); err != nil {
 		return models.UploadLog{}, err
 	}
+	if err := usecase.enforceSecurity.IngestInTable(objectType); err != nil {
+		return models.UploadLog{}, err
+	}
 	dataModel, err := usecase.dataModelRepository.GetDataModel(
 		ctx,
 		usecase.executorFactory.NewExecutor(),
@@ -137,7 +146,7 @@ func (usecase *IngestionUseCase) ValidateAndUploadIngestionCsv(ctx context.Conte
 	}
 
 	fileName := computeFileName(organizationId, table.Name)
-	writer := usecase.gcsRepository.OpenStream(ctx, usecase.GcsIngestionBucket, fileName)
+	writer := usecase.blobRepository.OpenStream(ctx, usecase.GcsIngestionBucket, fileName)
 	csvWriter := csv.NewWriter(writer)
 
 	for name, field := range table.Fields {
@@ -183,7 +192,7 @@ func (usecase *IngestionUseCase) ValidateAndUploadIngestionCsv(ctx context.Conte
 	if err := writer.Close(); err != nil {
 		return models.UploadLog{}, err
 	}
-	if err := usecase.gcsRepository.UpdateFileMetadata(ctx, usecase.GcsIngestionBucket,
+	if err := usecase.blobRepository.UpdateFileMetadata(ctx, usecase.GcsIngestionBucket,
 		fileName, map[string]string{"processed": "true"}); err != nil {
 		return models.UploadLog{}, err
 	}
@@ -204,40 +213,48 @@ func (usecase *IngestionUseCase) ValidateAndUploadIngestionCsv(ctx context.Conte
 			if err := usecase.uploadLogRepository.CreateUploadLog(ctx, tx, newUploadLoad); err != nil {
 				return models.UploadLog{}, err
 			}
+			if err := usecase.jobEnqueuer.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+				Kind:     models.JobKindCsvIngestion,
+				EntityId: newUploadListId,
+			}); err != nil {
+				return models.UploadLog{}, err
+			}
 			return usecase.uploadLogRepository.UploadLogById(ctx, tx, newUploadListId)
 		})
 }
 
+// IngestDataFromCsv claims CSV ingestion jobs from the job queue and ingests the corresponding files.
+// An upload log is marked as failed once its job has used all its attempts.
 func (usecase *IngestionUseCase) IngestDataFromCsv(ctx context.Context, logger *slog.Logger) error {
-	pendingUploadLogs, err := usecase.uploadLogRepository.AllUploadLogsByStatus(ctx,
-		usecase.executorFactory.NewExecutor(), models.UploadPending)
-	if err != nil {
-		return err
-	}
-	logger.InfoContext(ctx, fmt.Sprintf("Found %d upload logs of data to ingest", len(pendingUploadLogs)))
-
-	var waitGroup sync.WaitGroup
-	// The channel needs to be big enough to store any possible errors to avoid deadlock due to the presence of a waitGroup
-	uploadErrorChan := make(chan error, len(pendingUploadLogs))
-
-	startProcessUploadLog := func(uploadLog models.UploadLog) {
-		defer waitGroup.Done()
-		logger := logger.With("uploadLogId", uploadLog.Id).With("organization_id", uploadLog.OrganizationId)
-		if err := usecase.processUploadLog(ctx, uploadLog, logger); err != nil {
-			uploadErrorChan <- err
-		}
-	}
-
-	for _, uploadLog := range pendingUploadLogs {
-		waitGroup.Add(1)
-		go startProcessUploadLog(uploadLog)
-	}
-
-	waitGroup.Wait()
-	close(uploadErrorChan)
+	return usecase.jobQueueWorker.ProcessJobs(
+		ctx,
+		models.JobKindCsvIngestion,
+		maxConcurrentCsvIngestions,
+		maxConcurrentCsvIngestions,
+		func(ctx context.Context, job models.Job) error {
+			exec := usecase.executorFactory.NewExecutor()
+			uploadLog, err := usecase.uploadLogRepository.UploadLogById(ctx, exec, job.EntityId)
+			if err != nil {
+				return err
+			}
+			if uploadLog.UploadStatus == models.UploadSuccess || uploadLog.UploadStatus == models.UploadFailure {
+				return nil
+			}
 
-	uploadErr := <-uploadErrorChan
-	return uploadErr
+			logger := logger.With("uploadLogId", uploadLog.Id).With("organization_id", uploadLog.OrganizationId)
+			err = usecase.processUploadLog(ctx, uploadLog, logger)
+			if err != nil && job.IsLastAttempt() && ctx.Err() == nil {
+				currentTime := time.Now()
+				err2 := usecase.uploadLogRepository.UpdateUploadLog(ctx, exec, models.UpdateUploadLogInput{
+					Id: uploadLog.Id, UploadStatus: models.UploadFailure, FinishedAt: &currentTime,
+				})
+				if err2 != nil {
+					return errors.Join(err, err2)
+				}
+			}
+			return err
+		},
+	)
 }
 
 func (usecase *IngestionUseCase) processUploadLog(ctx context.Context, uploadLog models.UploadLog, logger *slog.Logger) error {
@@ -251,7 +268,7 @@ func (usecase *IngestionUseCase) processUploadLog(ctx context.Context, uploadLog
 		return err
 	}
 
-	file, err := usecase.gcsRepository.GetFile(ctx, usecase.GcsIngestionBucket, uploadLog.FileName)
+	file, err := usecase.blobRepository.GetFile(ctx, usecase.GcsIngestionBucket, uploadLog.FileName)
 	if err != nil {
 		return err
 	}
@@ -269,7 +286,7 @@ func (usecase *IngestionUseCase) processUploadLog(ctx context.Context, uploadLog
 	return nil
 }
 
-func (usecase *IngestionUseCase) readFileIngestObjects(ctx context.Context, file models.GCSFile, logger *slog.Logger) error {
+func (usecase *IngestionUseCase) readFileIngestObjects(ctx context.Context, file models.Blob, logger *slog.Logger) error {
 	fullFileName := file.FileName
 	logger.InfoContext(ctx, fmt.Sprintf("Ingesting data from CSV %s", fullFileName))
 
@@ -309,7 +326,7 @@ func (usecase *IngestionUseCase) readFileIngestObjects(ctx context.Context, file
 }
 
 func (usecase *IngestionUseCase) ingestObjectsFromCSV(ctx context.Context, organizationId string,
-	file models.GCSFile, table models.Table, logger *slog.Logger,
+	file models.Blob, table models.Table, logger *slog.Logger,
 ) error {
 	start := time.Now()
 	r := csv.NewReader(pure_utils.NewReaderWithoutBom(file.Reader))
@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/jobqueue"
+)
+
+type jobEnqueuer interface {
+	EnqueueJob(ctx context.Context, exec repositories.Executor, input models.JobEnqueueInput) error
+}
+
+type jobQueueWorker interface {
+	ProcessJobs(
+		ctx context.Context,
+		kind models.JobKind,
+		limit int,
+		concurrency int,
+		handler jobqueue.JobHandler,
+	) error
+}
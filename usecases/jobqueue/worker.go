@@ -0,0 +1,202 @@
+package jobqueue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	DEFAULT_LEASE_DURATION     = 5 * time.Minute
+	DEFAULT_HEARTBEAT_INTERVAL = 1 * time.Minute
+	RETRY_BASE_DELAY           = 30 * time.Second
+	RETRY_MAX_DELAY            = 1 * time.Hour
+	POSTPONE_DELAY             = 1 * time.Minute
+)
+
+type JobQueueRepository interface {
+	ClaimJobs(ctx context.Context, exec repositories.Executor, input models.JobClaimInput) ([]models.Job, error)
+	HeartbeatJob(
+		ctx context.Context,
+		exec repositories.Executor,
+		jobId string,
+		workerId string,
+		leaseDuration time.Duration,
+	) error
+	CompleteJob(ctx context.Context, exec repositories.Executor, jobId string, workerId string) error
+	FailJob(
+		ctx context.Context,
+		exec repositories.Executor,
+		jobId string,
+		workerId string,
+		jobError string,
+		retryAt *time.Time,
+	) error
+	PostponeJob(ctx context.Context, exec repositories.Executor, jobId string, workerId string, runAt time.Time) error
+}
+
+// ErrRetryLater can be returned (wrapped) by a job handler to have the job retried later, without reporting an error
+var ErrRetryLater = errors.New("the job must be retried later")
+
+// ErrPostponed can be returned (wrapped) by a job handler when the job could not be processed for a reason unrelated
+// to the job itself, e.g. a rate limit: the job is run again after POSTPONE_DELAY, without counting the attempt
+var ErrPostponed = errors.New("the job is postponed")
+
+// JobHandler processes the entity referenced by a job. The context is cancelled if the worker loses the lease on the job.
+type JobHandler func(ctx context.Context, job models.Job) error
+
+// identifies the current process in the leases it takes on jobs
+var currentWorkerId = newWorkerId()
+
+func newWorkerId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}
+
+// Worker claims jobs from the postgres job queue and processes them. Several workers (in the same or in different
+// processes) can process the same kind of jobs concurrently: every job is processed by only one of them at a time.
+type Worker struct {
+	repository        JobQueueRepository
+	executorFactory   executor_factory.ExecutorFactory
+	workerId          string
+	leaseDuration     time.Duration
+	heartbeatInterval time.Duration
+}
+
+func NewWorker(repository JobQueueRepository, executorFactory executor_factory.ExecutorFactory) *Worker {
+	return &Worker{
+		repository:        repository,
+		executorFactory:   executorFactory,
+		workerId:          currentWorkerId,
+		leaseDuration:     DEFAULT_LEASE_DURATION,
+		heartbeatInterval: DEFAULT_HEARTBEAT_INTERVAL,
+	}
+}
+
+// ProcessJobs claims at most "limit" due jobs of the given kind and processes them with at most "concurrency" jobs
+// handled at the same time. Failed jobs are retried later with an exponential backoff, until the maximum number of
+// attempts of their kind is reached. Returns the first error returned by the handler (other than ErrRetryLater), if any.
+func (w *Worker) ProcessJobs(
+	ctx context.Context,
+	kind models.JobKind,
+	limit int,
+	concurrency int,
+	handler JobHandler,
+) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	jobs, err := w.repository.ClaimJobs(ctx, w.executorFactory.NewExecutor(), models.JobClaimInput{
+		Kind:          kind,
+		WorkerId:      w.workerId,
+		LeaseDuration: w.leaseDuration,
+		Limit:         limit,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error while claiming %s jobs", kind)
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("Claimed %d %s jobs", len(jobs), kind))
+
+	var group errgroup.Group
+	group.SetLimit(concurrency)
+	for _, job := range jobs {
+		group.Go(func() error {
+			ctx := utils.StoreLoggerInContext(ctx, logger.With("job_id", job.Id, "entity_id", job.EntityId))
+			return w.processJob(ctx, job, handler)
+		})
+	}
+	return group.Wait()
+}
+
+func (w *Worker) processJob(ctx context.Context, job models.Job, handler JobHandler) error {
+	logger := utils.LoggerFromContext(ctx)
+	exec := w.executorFactory.NewExecutor()
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+
+	var leaseLost atomic.Bool
+
+	heartbeatDone := make(chan struct{})
+	stopHeartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(w.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				err := w.repository.HeartbeatJob(jobCtx, exec, job.Id, w.workerId, w.leaseDuration)
+				if errors.Is(err, repositories.ErrJobLeaseLost) {
+					logger.WarnContext(ctx, fmt.Sprintf("Lost the lease on job %s, stop processing it", job.Id))
+					leaseLost.Store(true)
+					cancelJob()
+					return
+				} else if err != nil {
+					logger.WarnContext(ctx, fmt.Sprintf("Error while renewing the lease on job %s: %s", job.Id, err.Error()))
+				}
+			}
+		}
+	}()
+
+	handlerErr := handler(jobCtx, job)
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	if leaseLost.Load() {
+		// the job may already be claimed by another worker, it must not be released
+		return nil
+	}
+	if handlerErr == nil {
+		return w.repository.CompleteJob(ctx, exec, job.Id, w.workerId)
+	}
+	if errors.Is(handlerErr, ErrPostponed) {
+		logger.InfoContext(ctx, fmt.Sprintf("Job %s postponed: %s", job.Id, handlerErr.Error()))
+		return w.repository.PostponeJob(ctx, exec, job.Id, w.workerId, time.Now().Add(POSTPONE_DELAY))
+	}
+
+	var retryAt *time.Time
+	if !job.IsLastAttempt() {
+		retryAt = utils.Ptr(time.Now().Add(RetryDelay(job.Attempts)))
+	}
+	logger.WarnContext(ctx, fmt.Sprintf("Error while processing job %s (attempt %d): %s",
+		job.Id, job.Attempts, handlerErr.Error()))
+	if err := w.repository.FailJob(ctx, exec, job.Id, w.workerId, handlerErr.Error(), retryAt); err != nil {
+		return errors.Join(handlerErr, err)
+	}
+	if errors.Is(handlerErr, ErrRetryLater) {
+		return nil
+	}
+	return handlerErr
+}
+
+// RetryDelay returns the delay before retrying a job that failed after the given number of attempts:
+// it doubles at every attempt, from RETRY_BASE_DELAY up to RETRY_MAX_DELAY.
+func RetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		return RETRY_BASE_DELAY
+	}
+	delay := float64(RETRY_BASE_DELAY) * math.Pow(2, float64(attempts-1))
+	if delay > float64(RETRY_MAX_DELAY) {
+		return RETRY_MAX_DELAY
+	}
+	return time.Duration(delay)
+}
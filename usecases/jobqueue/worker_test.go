@@ -0,0 +1,155 @@
+package jobqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+)
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, RETRY_BASE_DELAY, RetryDelay(0))
+	assert.Equal(t, RETRY_BASE_DELAY, RetryDelay(1))
+	assert.Equal(t, 2*RETRY_BASE_DELAY, RetryDelay(2))
+	assert.Equal(t, 8*RETRY_BASE_DELAY, RetryDelay(4))
+	assert.Equal(t, RETRY_MAX_DELAY, RetryDelay(24))
+	assert.Equal(t, RETRY_MAX_DELAY, RetryDelay(1000))
+	assert.LessOrEqual(t, RetryDelay(8), time.Hour)
+}
+
+func workerTest(jobs ...models.Job) (*Worker, *mocks.JobQueueRepository, *mocks.Executor) {
+	exec := new(mocks.Executor)
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(exec)
+	repository := new(mocks.JobQueueRepository)
+	repository.On("ClaimJobs", exec, models.JobClaimInput{
+		Kind:          models.JobKindScheduledExecution,
+		WorkerId:      "worker",
+		LeaseDuration: DEFAULT_LEASE_DURATION,
+		Limit:         10,
+	}).Return(jobs, nil)
+
+	worker := NewWorker(repository, executorFactory)
+	worker.workerId = "worker"
+	return worker, repository, exec
+}
+
+func scheduledExecutionJob(id string, attempts int) models.Job {
+	return models.Job{Id: id, Kind: models.JobKindScheduledExecution, EntityId: "entity_" + id, Attempts: attempts}
+}
+
+func TestProcessJobs_complete(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 1), scheduledExecutionJob("2", 1))
+	repository.On("CompleteJob", exec, "1", "worker").Return(nil).Once()
+	repository.On("CompleteJob", exec, "2", "worker").Return(nil).Once()
+
+	processed := make(chan string, 2)
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 2,
+		func(ctx context.Context, job models.Job) error {
+			processed <- job.EntityId
+			return nil
+		})
+	assert.NoError(t, err)
+	close(processed)
+	entityIds := make([]string, 0)
+	for entityId := range processed {
+		entityIds = append(entityIds, entityId)
+	}
+	assert.ElementsMatch(t, []string{"entity_1", "entity_2"}, entityIds)
+	repository.AssertExpectations(t)
+}
+
+func TestProcessJobs_retry(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 2))
+	var retryAt *time.Time
+	repository.On("FailJob", exec, "1", "worker", "boom", mock.Anything).
+		Run(func(args mock.Arguments) { retryAt = args.Get(4).(*time.Time) }).
+		Return(nil).Once()
+
+	before := time.Now()
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error { return errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+	repository.AssertExpectations(t)
+	if assert.NotNil(t, retryAt) {
+		assert.WithinDuration(t, before.Add(RetryDelay(2)), *retryAt, time.Second)
+	}
+}
+
+func TestProcessJobs_retryLater(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 1))
+	repository.On("FailJob", exec, "1", "worker", mock.Anything, mock.AnythingOfType("*time.Time")).
+		Return(nil).Once()
+
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error { return errors.Wrap(ErrRetryLater, "rate limited") })
+	assert.NoError(t, err, "retrying later is not an error")
+	repository.AssertExpectations(t)
+}
+
+func TestProcessJobs_deadJob(t *testing.T) {
+	job := scheduledExecutionJob("1", models.JobKindScheduledExecution.MaxAttempts())
+	assert.True(t, job.IsLastAttempt())
+	worker, repository, exec := workerTest(job)
+	repository.On("FailJob", exec, "1", "worker", "boom", (*time.Time)(nil)).Return(nil).Once()
+
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error { return errors.New("boom") })
+	assert.Error(t, err)
+	repository.AssertExpectations(t)
+}
+
+func TestProcessJobs_heartbeat(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 1))
+	worker.heartbeatInterval = 5 * time.Millisecond
+	heartbeats := make(chan struct{}, 100)
+	repository.On("HeartbeatJob", exec, "1", "worker", DEFAULT_LEASE_DURATION).
+		Run(func(mock.Arguments) { heartbeats <- struct{}{} }).
+		Return(nil)
+	repository.On("CompleteJob", exec, "1", "worker").Return(nil).Once()
+
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error {
+			// the lease is renewed while the job is processed
+			<-heartbeats
+			<-heartbeats
+			return nil
+		})
+	assert.NoError(t, err)
+	repository.AssertExpectations(t)
+}
+
+func TestProcessJobs_leaseLost(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 1))
+	worker.heartbeatInterval = 5 * time.Millisecond
+	repository.On("HeartbeatJob", exec, "1", "worker", DEFAULT_LEASE_DURATION).
+		Return(repositories.ErrJobLeaseLost).Once()
+
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	assert.NoError(t, err)
+	// the job may have been claimed by another worker: it is neither completed nor released
+	repository.AssertNotCalled(t, "CompleteJob", mock.Anything, mock.Anything, mock.Anything)
+	repository.AssertNotCalled(t, "FailJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessJobs_postponed(t *testing.T) {
+	worker, repository, exec := workerTest(scheduledExecutionJob("1", 1))
+	repository.On("PostponeJob", exec, "1", "worker", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	err := worker.ProcessJobs(context.Background(), models.JobKindScheduledExecution, 10, 1,
+		func(ctx context.Context, job models.Job) error { return errors.Wrap(ErrPostponed, "rate limited") })
+	assert.NoError(t, err)
+	repository.AssertExpectations(t)
+	repository.AssertNotCalled(t, "FailJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
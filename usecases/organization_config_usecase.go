@@ -0,0 +1,614 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/guregu/null/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type OrganizationConfigRepository interface {
+	ListInboxes(ctx context.Context, exec repositories.Executor, organizationId string,
+		inboxIds []string, withCaseCount bool) ([]models.Inbox, error)
+	ListOrganizationTags(ctx context.Context, exec repositories.Executor, organizationId string,
+		withCaseCount bool) ([]models.Tag, error)
+	ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.Scenario, error)
+}
+
+// OrganizationConfigUsecase exports the configuration of an organization as a snapshot, and applies a snapshot to an
+// organization to promote a configuration from an environment to another (e.g. from staging to production).
+// The changes are made with the usecases of each element, so that they are validated and audited like the changes made
+// one by one.
+type OrganizationConfigUsecase struct {
+	enforceSecurity           security.EnforceSecurityOrganization
+	enforceSecurityCustomList security.EnforceSecurityCustomList
+	executorFactory           executor_factory.ExecutorFactory
+	transactionFactory        executor_factory.TransactionFactory
+	repository                OrganizationConfigRepository
+	customListRepository      repositories.CustomListRepository
+	dataModelUsecase          DataModelUseCase
+	inboxUsecase              InboxUsecase
+	tagUsecase                TagUseCase
+	scenarioUsecase           ScenarioUsecase
+	webhooksUsecase           WebhooksUsecase
+}
+
+func (usecase *OrganizationConfigUsecase) ExportOrganizationConfig(ctx context.Context,
+	organizationId string,
+) (models.OrganizationConfig, error) {
+	if err := usecase.enforceSecurity.ExportOrganizationConfig(organizationId); err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	return usecase.currentConfig(ctx, organizationId)
+}
+
+// PlanOrganizationConfig computes the changes that applying the snapshot would make, without making them
+func (usecase *OrganizationConfigUsecase) PlanOrganizationConfig(ctx context.Context,
+	organizationId string, config models.OrganizationConfig,
+) (models.OrganizationConfigPlan, error) {
+	if err := usecase.enforceSecurity.ImportOrganizationConfig(organizationId); err != nil {
+		return models.OrganizationConfigPlan{}, err
+	}
+	current, err := usecase.currentConfig(ctx, organizationId)
+	if err != nil {
+		return models.OrganizationConfigPlan{}, err
+	}
+	return models.PlanOrganizationConfig(config, current), nil
+}
+
+// ApplyOrganizationConfig makes the changes of the plan, unless it has conflicts, and returns the plan.
+// The data model changes run DDL in the organization schema and webhooks live in the webhook delivery backend, so the
+// changes are not made in a single transaction: if the application stops midway, applying the snapshot again resumes it.
+// Likewise a link to a field made unique by the same snapshot can only be created once its unique index is built, by
+// applying the snapshot again.
+func (usecase *OrganizationConfigUsecase) ApplyOrganizationConfig(ctx context.Context,
+	organizationId string, config models.OrganizationConfig,
+) (models.OrganizationConfigPlan, error) {
+	plan, err := usecase.PlanOrganizationConfig(ctx, organizationId, config)
+	if err != nil || len(plan.Conflicts) > 0 {
+		return plan, err
+	}
+
+	// each step needs the ids of the elements created by the previous ones
+	steps := []func(context.Context, string, models.OrganizationConfig, models.OrganizationConfigPlan) error{
+		usecase.applyTables,
+		usecase.applyFields,
+		usecase.applyLinks,
+		usecase.applyPivots,
+		usecase.applyCustomLists,
+		usecase.applyInboxes,
+		usecase.applyTags,
+		usecase.applyWebhooks,
+		usecase.applyScenarioWorkflows,
+	}
+	for _, step := range steps {
+		if err := step(ctx, organizationId, config, plan); err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+func (usecase *OrganizationConfigUsecase) currentConfig(ctx context.Context, organizationId string) (models.OrganizationConfig, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	config := models.OrganizationConfig{
+		Version:    models.ORGANIZATION_CONFIG_VERSION,
+		ExportedAt: time.Now(),
+	}
+
+	dataModel, err := usecase.dataModelUsecase.GetDataModel(ctx, organizationId)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	pivots, err := usecase.dataModelUsecase.ListPivots(ctx, organizationId, nil)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.DataModel = adaptOrganizationConfigDataModel(dataModel, pivots)
+
+	customLists, err := usecase.customListRepository.AllCustomLists(ctx, exec, organizationId)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.CustomLists = make([]models.OrganizationConfigCustomList, len(customLists))
+	for i, customList := range customLists {
+		values, err := usecase.customListRepository.GetCustomListValues(ctx, exec,
+			models.GetCustomListValuesInput{Id: customList.Id})
+		if err != nil {
+			return models.OrganizationConfig{}, err
+		}
+		config.CustomLists[i] = models.OrganizationConfigCustomList{
+			Name:        customList.Name,
+			Description: customList.Description,
+			Values:      pure_utils.Map(values, func(value models.CustomListValue) string { return value.Value }),
+		}
+	}
+
+	inboxes, err := usecase.repository.ListInboxes(ctx, exec, organizationId, nil, false)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.Inboxes = pure_utils.Map(inboxes, func(inbox models.Inbox) models.OrganizationConfigInbox {
+		return models.OrganizationConfigInbox{Name: inbox.Name}
+	})
+
+	tags, err := usecase.repository.ListOrganizationTags(ctx, exec, organizationId, false)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.Tags = pure_utils.Map(tags, func(tag models.Tag) models.OrganizationConfigTag {
+		return models.OrganizationConfigTag{Name: tag.Name, Color: tag.Color}
+	})
+
+	webhooks, err := usecase.webhooksUsecase.ListWebhooks(ctx, organizationId, null.String{})
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.Webhooks = pure_utils.Map(webhooks, func(webhook models.Webhook) models.OrganizationConfigWebhook {
+		return models.OrganizationConfigWebhook{
+			Url:               webhook.Url,
+			EventTypes:        webhook.EventTypes,
+			HttpTimeout:       webhook.HttpTimeout,
+			RateLimit:         webhook.RateLimit,
+			RateLimitDuration: webhook.RateLimitDuration,
+		}
+	})
+
+	scenarios, err := usecase.repository.ListScenariosOfOrganization(ctx, exec, organizationId)
+	if err != nil {
+		return models.OrganizationConfig{}, err
+	}
+	config.ScenarioWorkflows = make([]models.OrganizationConfigScenarioWorkflow, len(scenarios))
+	for i, scenario := range scenarios {
+		workflow := models.OrganizationConfigScenarioWorkflow{
+			ScenarioName: scenario.Name,
+			WorkflowType: scenario.DecisionToCaseWorkflowType,
+			Outcomes:     scenario.DecisionToCaseOutcomes,
+		}
+		if scenario.DecisionToCaseInboxId != nil {
+			if inbox, ok := findInboxById(inboxes, *scenario.DecisionToCaseInboxId); ok {
+				workflow.InboxName = &inbox.Name
+			}
+		}
+		config.ScenarioWorkflows[i] = workflow
+	}
+
+	return config, nil
+}
+
+func findInboxById(inboxes []models.Inbox, inboxId string) (models.Inbox, bool) {
+	for _, inbox := range inboxes {
+		if inbox.Id == inboxId {
+			return inbox, true
+		}
+	}
+	return models.Inbox{}, false
+}
+
+// adaptOrganizationConfigDataModel lists the tables, fields and links sorted by name, so that the snapshots of two
+// organizations can be compared
+func adaptOrganizationConfigDataModel(dataModel models.DataModel, pivots []models.Pivot) models.OrganizationConfigDataModel {
+	configDataModel := models.OrganizationConfigDataModel{
+		Tables: make([]models.OrganizationConfigTable, 0, len(dataModel.Tables)),
+		Links:  make([]models.OrganizationConfigLink, 0),
+		Pivots: make([]models.OrganizationConfigPivot, 0, len(pivots)),
+	}
+	for _, table := range dataModel.Tables {
+		configTable := models.OrganizationConfigTable{
+			Name:        table.Name,
+			Description: table.Description,
+			Fields:      make([]models.OrganizationConfigField, 0, len(table.Fields)),
+		}
+		for _, field := range table.Fields {
+			configTable.Fields = append(configTable.Fields, models.OrganizationConfigField{
+				Name:        field.Name,
+				Description: field.Description,
+				DataType:    field.DataType,
+				Nullable:    field.Nullable,
+				IsEnum:      field.IsEnum,
+				IsUnique:    field.UnicityConstraint != models.NoUnicityConstraint,
+			})
+		}
+		sort.Slice(configTable.Fields, func(i, j int) bool {
+			return configTable.Fields[i].Name < configTable.Fields[j].Name
+		})
+		configDataModel.Tables = append(configDataModel.Tables, configTable)
+
+		for _, link := range table.LinksToSingle {
+			configDataModel.Links = append(configDataModel.Links, models.OrganizationConfigLink{
+				Name:        link.Name,
+				ChildTable:  link.ChildTableName,
+				ChildField:  link.ChildFieldName,
+				ParentTable: link.ParentTableName,
+				ParentField: link.ParentFieldName,
+			})
+		}
+	}
+	sort.Slice(configDataModel.Tables, func(i, j int) bool {
+		return configDataModel.Tables[i].Name < configDataModel.Tables[j].Name
+	})
+	sort.Slice(configDataModel.Links, func(i, j int) bool {
+		return models.OrganizationConfigLinkKey(configDataModel.Links[i].ChildTable, configDataModel.Links[i].Name) <
+			models.OrganizationConfigLinkKey(configDataModel.Links[j].ChildTable, configDataModel.Links[j].Name)
+	})
+
+	for _, pivot := range pivots {
+		configPivot := models.OrganizationConfigPivot{
+			BaseTable: pivot.BaseTable,
+			Field:     pivot.Field,
+			PathLinks: pivot.PathLinks,
+		}
+		if configPivot.PathLinks == nil {
+			configPivot.PathLinks = []string{}
+		}
+		configDataModel.Pivots = append(configDataModel.Pivots, configPivot)
+	}
+	sort.Slice(configDataModel.Pivots, func(i, j int) bool {
+		return configDataModel.Pivots[i].BaseTable < configDataModel.Pivots[j].BaseTable
+	})
+	return configDataModel
+}
+
+func (usecase *OrganizationConfigUsecase) applyTables(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	dataModel, err := usecase.dataModelUsecase.GetDataModel(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	for _, table := range config.DataModel.Tables {
+		switch plan.Action(models.OrganizationConfigResourceTable, table.Name) {
+		case models.OrganizationConfigCreate:
+			_, err = usecase.dataModelUsecase.CreateDataModelTable(ctx, organizationId, table.Name, table.Description)
+		case models.OrganizationConfigUpdate:
+			err = usecase.dataModelUsecase.UpdateDataModelTable(ctx, dataModel.Tables[table.Name].ID, table.Description)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error applying table %s", table.Name)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyFields(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	dataModel, err := usecase.dataModelUsecase.GetDataModel(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	for _, table := range config.DataModel.Tables {
+		currentTable := dataModel.Tables[table.Name]
+		for _, field := range table.Fields {
+			key := models.OrganizationConfigFieldKey(table.Name, field.Name)
+			switch plan.Action(models.OrganizationConfigResourceField, key) {
+			case models.OrganizationConfigCreate:
+				_, err = usecase.dataModelUsecase.CreateDataModelField(ctx, models.CreateFieldInput{
+					TableId:     currentTable.ID,
+					Name:        field.Name,
+					Description: field.Description,
+					DataType:    field.DataType,
+					Nullable:    field.Nullable,
+					IsEnum:      field.IsEnum,
+					IsUnique:    field.IsUnique,
+				})
+			case models.OrganizationConfigUpdate:
+				err = usecase.dataModelUsecase.UpdateDataModelField(ctx, currentTable.Fields[field.Name].ID,
+					models.UpdateFieldInput{
+						Description: &field.Description,
+						IsEnum:      &field.IsEnum,
+						IsUnique:    &field.IsUnique,
+					})
+			}
+			if err != nil {
+				return errors.Wrapf(err, "error applying field %s", key)
+			}
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyLinks(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	dataModel, err := usecase.dataModelUsecase.GetDataModel(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	for _, link := range config.DataModel.Links {
+		key := models.OrganizationConfigLinkKey(link.ChildTable, link.Name)
+		if plan.Action(models.OrganizationConfigResourceLink, key) != models.OrganizationConfigCreate {
+			continue
+		}
+		childTable, parentTable := dataModel.Tables[link.ChildTable], dataModel.Tables[link.ParentTable]
+		_, err := usecase.dataModelUsecase.CreateDataModelLink(ctx, models.DataModelLinkCreateInput{
+			OrganizationID: organizationId,
+			Name:           link.Name,
+			ParentTableID:  parentTable.ID,
+			ParentFieldID:  parentTable.Fields[link.ParentField].ID,
+			ChildTableID:   childTable.ID,
+			ChildFieldID:   childTable.Fields[link.ChildField].ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error applying link %s", key)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyPivots(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	dataModel, err := usecase.dataModelUsecase.GetDataModel(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	for _, pivot := range config.DataModel.Pivots {
+		if plan.Action(models.OrganizationConfigResourcePivot, pivot.BaseTable) != models.OrganizationConfigCreate {
+			continue
+		}
+		baseTable := dataModel.Tables[pivot.BaseTable]
+		input := models.CreatePivotInput{
+			BaseTableId:    baseTable.ID,
+			OrganizationId: organizationId,
+			PathLinkIds:    make([]string, 0, len(pivot.PathLinks)),
+		}
+		if len(pivot.PathLinks) == 0 {
+			fieldId := baseTable.Fields[pivot.Field].ID
+			input.FieldId = &fieldId
+		}
+		// the path starts from the base table, each link leads to the parent table of the previous one
+		table := baseTable
+		for _, linkName := range pivot.PathLinks {
+			link, ok := table.LinksToSingle[linkName]
+			if !ok {
+				return errors.Wrapf(models.BadParameterError,
+					"error applying pivot %s: link %s not found in table %s", pivot.BaseTable, linkName, table.Name)
+			}
+			input.PathLinkIds = append(input.PathLinkIds, link.Id)
+			table = dataModel.Tables[link.ParentTableName]
+		}
+		if _, err := usecase.dataModelUsecase.CreatePivot(ctx, input); err != nil {
+			return errors.Wrapf(err, "error applying pivot %s", pivot.BaseTable)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyCustomLists(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	return usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		customLists, err := usecase.customListRepository.AllCustomLists(ctx, tx, organizationId)
+		if err != nil {
+			return err
+		}
+		for _, configList := range config.CustomLists {
+			action := plan.Action(models.OrganizationConfigResourceCustomList, configList.Name)
+			var customList models.CustomList
+			switch action {
+			case models.OrganizationConfigCreate:
+				if err := usecase.enforceSecurityCustomList.CreateCustomList(); err != nil {
+					return err
+				}
+				customList = models.CustomList{Id: uuid.NewString(), Name: configList.Name}
+				err = usecase.customListRepository.CreateCustomList(ctx, tx, models.CreateCustomListInput{
+					Name:        configList.Name,
+					Description: configList.Description,
+				}, organizationId, customList.Id)
+			case models.OrganizationConfigUpdate:
+				idx := slices.IndexFunc(customLists, func(l models.CustomList) bool { return l.Name == configList.Name })
+				if idx < 0 {
+					return errOrganizationConfigDrift(models.OrganizationConfigResourceCustomList, configList.Name)
+				}
+				customList = customLists[idx]
+				if err := usecase.enforceSecurityCustomList.ModifyCustomList(customList); err != nil {
+					return err
+				}
+				err = usecase.customListRepository.UpdateCustomList(ctx, tx, models.UpdateCustomListInput{
+					Id:          customList.Id,
+					Description: &configList.Description,
+				})
+			default:
+				continue
+			}
+			if err != nil {
+				return errors.Wrapf(err, "error applying custom list %s", configList.Name)
+			}
+			if err := usecase.applyCustomListValues(ctx, tx, customList, configList); err != nil {
+				return errors.Wrapf(err, "error applying values of custom list %s", configList.Name)
+			}
+		}
+		return nil
+	})
+}
+
+func (usecase *OrganizationConfigUsecase) applyCustomListValues(ctx context.Context, tx repositories.Executor,
+	customList models.CustomList, configList models.OrganizationConfigCustomList,
+) error {
+	values, err := usecase.customListRepository.GetCustomListValues(ctx, tx,
+		models.GetCustomListValuesInput{Id: customList.Id})
+	if err != nil {
+		return err
+	}
+	added, removed := configList.DiffValues(pure_utils.Map(values,
+		func(value models.CustomListValue) string { return value.Value }))
+	for _, value := range added {
+		err := usecase.customListRepository.AddCustomListValue(ctx, tx, models.AddCustomListValueInput{
+			CustomListId: customList.Id,
+			Value:        value,
+		}, uuid.NewString())
+		if err != nil {
+			return err
+		}
+	}
+	for _, value := range values {
+		if !slices.Contains(removed, value.Value) {
+			continue
+		}
+		err := usecase.customListRepository.DeleteCustomListValue(ctx, tx, models.DeleteCustomListValueInput{
+			Id:           value.Id,
+			CustomListId: customList.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyInboxes(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	for _, inbox := range config.Inboxes {
+		if plan.Action(models.OrganizationConfigResourceInbox, inbox.Name) != models.OrganizationConfigCreate {
+			continue
+		}
+		_, err := usecase.inboxUsecase.CreateInbox(ctx, models.CreateInboxInput{
+			Name:           inbox.Name,
+			OrganizationId: organizationId,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error applying inbox %s", inbox.Name)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyTags(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	tags, err := usecase.repository.ListOrganizationTags(ctx, usecase.executorFactory.NewExecutor(), organizationId, false)
+	if err != nil {
+		return err
+	}
+	for _, tag := range config.Tags {
+		switch plan.Action(models.OrganizationConfigResourceTag, tag.Name) {
+		case models.OrganizationConfigCreate:
+			_, err = usecase.tagUsecase.CreateTag(ctx, models.CreateTagAttributes{
+				Color:          tag.Color,
+				OrganizationId: organizationId,
+				Name:           tag.Name,
+			})
+		case models.OrganizationConfigUpdate:
+			idx := slices.IndexFunc(tags, func(t models.Tag) bool { return t.Name == tag.Name })
+			if idx < 0 {
+				return errOrganizationConfigDrift(models.OrganizationConfigResourceTag, tag.Name)
+			}
+			_, err = usecase.tagUsecase.UpdateTag(ctx, organizationId, models.UpdateTagAttributes{
+				Color: tag.Color,
+				Name:  tag.Name,
+				TagId: tags[idx].Id,
+			})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error applying tag %s", tag.Name)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyWebhooks(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	if !slices.ContainsFunc(plan.Changes, func(change models.OrganizationConfigChange) bool {
+		return change.Resource == models.OrganizationConfigResourceWebhook && change.Action != models.OrganizationConfigNoOp
+	}) {
+		return nil
+	}
+	webhooks, err := usecase.webhooksUsecase.ListWebhooks(ctx, organizationId, null.String{})
+	if err != nil {
+		return err
+	}
+	for _, webhook := range config.Webhooks {
+		switch plan.Action(models.OrganizationConfigResourceWebhook, webhook.Url) {
+		case models.OrganizationConfigCreate:
+			_, err = usecase.webhooksUsecase.RegisterWebhook(ctx, organizationId, null.String{}, models.WebhookRegister{
+				EventTypes:        webhook.EventTypes,
+				Url:               webhook.Url,
+				HttpTimeout:       webhook.HttpTimeout,
+				RateLimit:         webhook.RateLimit,
+				RateLimitDuration: webhook.RateLimitDuration,
+			})
+		case models.OrganizationConfigUpdate:
+			idx := slices.IndexFunc(webhooks, func(w models.Webhook) bool { return w.Url == webhook.Url })
+			if idx < 0 {
+				return errOrganizationConfigDrift(models.OrganizationConfigResourceWebhook, webhook.Url)
+			}
+			_, err = usecase.webhooksUsecase.UpdateWebhook(ctx, organizationId, null.String{}, webhooks[idx].Id,
+				models.WebhookUpdate{
+					EventTypes:        &webhook.EventTypes,
+					HttpTimeout:       webhook.HttpTimeout,
+					RateLimit:         webhook.RateLimit,
+					RateLimitDuration: webhook.RateLimitDuration,
+				})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error applying webhook %s", webhook.Url)
+		}
+	}
+	return nil
+}
+
+func (usecase *OrganizationConfigUsecase) applyScenarioWorkflows(ctx context.Context, organizationId string,
+	config models.OrganizationConfig, plan models.OrganizationConfigPlan,
+) error {
+	exec := usecase.executorFactory.NewExecutor()
+	scenarios, err := usecase.repository.ListScenariosOfOrganization(ctx, exec, organizationId)
+	if err != nil {
+		return err
+	}
+	inboxes, err := usecase.repository.ListInboxes(ctx, exec, organizationId, nil, false)
+	if err != nil {
+		return err
+	}
+	for _, workflow := range config.ScenarioWorkflows {
+		if plan.Action(models.OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName) !=
+			models.OrganizationConfigUpdate {
+			continue
+		}
+		idx := slices.IndexFunc(scenarios, func(s models.Scenario) bool { return s.Name == workflow.ScenarioName })
+		if idx < 0 {
+			return errOrganizationConfigDrift(models.OrganizationConfigResourceScenarioWorkflow, workflow.ScenarioName)
+		}
+		input := models.UpdateScenarioInput{
+			Id:                         scenarios[idx].Id,
+			DecisionToCaseOutcomes:     workflow.Outcomes,
+			DecisionToCaseInboxId:      null.StringFrom(""),
+			DecisionToCaseWorkflowType: &workflow.WorkflowType,
+		}
+		if input.DecisionToCaseOutcomes == nil {
+			input.DecisionToCaseOutcomes = []models.Outcome{}
+		}
+		if workflow.InboxName != nil {
+			inboxIdx := slices.IndexFunc(inboxes, func(i models.Inbox) bool { return i.Name == *workflow.InboxName })
+			if inboxIdx < 0 {
+				return errors.Wrapf(models.ConflictError,
+					"error applying workflow of scenario %s: inbox %s was deleted since the plan was computed",
+					workflow.ScenarioName, *workflow.InboxName)
+			}
+			input.DecisionToCaseInboxId = null.StringFrom(inboxes[inboxIdx].Id)
+		}
+		if _, err := usecase.scenarioUsecase.UpdateScenario(ctx, input); err != nil {
+			return errors.Wrapf(err, "error applying workflow of scenario %s", workflow.ScenarioName)
+		}
+	}
+	return nil
+}
+
+// errOrganizationConfigDrift is returned when an element planned for an update was deleted since the plan was computed.
+// The organization changed during the application: the snapshot must be applied again, with a new plan.
+func errOrganizationConfigDrift(resource models.OrganizationConfigResource, name string) error {
+	return errors.Wrapf(models.ConflictError, "error applying %s %s: it was deleted since the plan was computed",
+		resource, name)
+}
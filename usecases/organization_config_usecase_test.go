@@ -0,0 +1,271 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type OrganizationConfigUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity        *mocks.EnforceSecurity
+	enforceSecurityWebhook *mocks.EnforceSecurityWebhook
+	repository             *mocks.OrganizationConfigRepository
+	customListRepository   *mocks.CustomListRepository
+	dataModelRepository    *mocks.DataModelRepository
+	clientDbIndexEditor    *mocks.ClientDbIndexEditor
+	webhooksBackend        *mocks.WebhooksBackend
+	exec                   *mocks.Executor
+	transaction            *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	customList     models.CustomList
+	inbox          models.Inbox
+	scenario       models.Scenario
+	webhook        models.Webhook
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.enforceSecurityWebhook = new(mocks.EnforceSecurityWebhook)
+	suite.repository = new(mocks.OrganizationConfigRepository)
+	suite.customListRepository = new(mocks.CustomListRepository)
+	suite.dataModelRepository = new(mocks.DataModelRepository)
+	suite.clientDbIndexEditor = new(mocks.ClientDbIndexEditor)
+	suite.webhooksBackend = new(mocks.WebhooksBackend)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "organization_id"
+	suite.customList = models.CustomList{Id: "custom_list_id", OrganizationId: suite.organizationId, Name: "blocked accounts"}
+	suite.inbox = models.Inbox{Id: "inbox_id", OrganizationId: suite.organizationId, Name: "fraud"}
+	suite.scenario = models.Scenario{
+		Id:                         "scenario_id",
+		OrganizationId:             suite.organizationId,
+		Name:                       "big transactions",
+		DecisionToCaseWorkflowType: models.WorkflowDisabled,
+	}
+	suite.webhook = models.Webhook{
+		Id:             "webhook_id",
+		OrganizationId: suite.organizationId,
+		Url:            "https://example.com/webhook",
+		EventTypes:     []string{"case.created"},
+	}
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) makeUsecase() *OrganizationConfigUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &OrganizationConfigUsecase{
+		enforceSecurity:           suite.enforceSecurity,
+		enforceSecurityCustomList: suite.enforceSecurity,
+		executorFactory:           executorFactory,
+		transactionFactory:        transactionFactory,
+		repository:                suite.repository,
+		customListRepository:      suite.customListRepository,
+		dataModelUsecase: DataModelUseCase{
+			clientDbIndexEditor: suite.clientDbIndexEditor,
+			dataModelRepository: suite.dataModelRepository,
+			enforceSecurity:     suite.enforceSecurity,
+			executorFactory:     executorFactory,
+			transactionFactory:  transactionFactory,
+		},
+		webhooksUsecase: WebhooksUsecase{
+			enforceSecurity: suite.enforceSecurityWebhook,
+			executorFactory: executorFactory,
+			webhooksBackend: suite.webhooksBackend,
+		},
+	}
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.customListRepository.AssertExpectations(t)
+	suite.dataModelRepository.AssertExpectations(t)
+	suite.webhooksBackend.AssertExpectations(t)
+}
+
+// config is the snapshot of the organization with an empty data model
+func (suite *OrganizationConfigUsecaseTestSuite) config() models.OrganizationConfig {
+	return models.OrganizationConfig{
+		Version: models.ORGANIZATION_CONFIG_VERSION,
+		DataModel: models.OrganizationConfigDataModel{
+			Tables: []models.OrganizationConfigTable{},
+			Links:  []models.OrganizationConfigLink{},
+			Pivots: []models.OrganizationConfigPivot{},
+		},
+		CustomLists:       []models.OrganizationConfigCustomList{},
+		Inboxes:           []models.OrganizationConfigInbox{},
+		Tags:              []models.OrganizationConfigTag{},
+		Webhooks:          []models.OrganizationConfigWebhook{},
+		ScenarioWorkflows: []models.OrganizationConfigScenarioWorkflow{},
+	}
+}
+
+// expectDataModel expects the reads of the empty data model, by the plan and by each data model step
+func (suite *OrganizationConfigUsecaseTestSuite) expectDataModel() {
+	suite.enforceSecurity.On("ImportOrganizationConfig", suite.organizationId).Return(nil)
+	suite.enforceSecurity.On("ReadDataModel").Return(nil)
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.exec, suite.organizationId, mock.Anything).
+		Return(models.DataModel{Tables: map[string]models.Table{}}, nil)
+	suite.dataModelRepository.On("ListPivots", mock.Anything, suite.exec, suite.organizationId, mock.Anything).
+		Return([]models.PivotMetadata{}, nil)
+	suite.clientDbIndexEditor.On("ListAllUniqueIndexes", mock.Anything).Return([]models.UnicityIndex{}, nil)
+}
+
+// expectCurrentConfig expects the reads of the plan, which finds the elements given, then the reads of the
+// application steps, which find the elements given in the organization at that time
+func (suite *OrganizationConfigUsecaseTestSuite) expectCurrentConfig(planned, applied organizationConfigElements) {
+	suite.customListRepository.On("AllCustomLists", suite.exec, suite.organizationId).
+		Return(planned.customLists, nil).Once()
+	for _, customList := range planned.customLists {
+		suite.customListRepository.On("GetCustomListValues", suite.exec,
+			models.GetCustomListValuesInput{Id: customList.Id}).Return([]models.CustomListValue{}, nil).Once()
+	}
+	suite.repository.On("ListInboxes", suite.exec, suite.organizationId, []string(nil), false).
+		Return(planned.inboxes, nil).Once()
+	suite.repository.On("ListOrganizationTags", suite.exec, suite.organizationId, false).
+		Return(planned.tags, nil).Once()
+	suite.webhooksBackend.On("ListWebhooks", suite.organizationId, null.String{}).Return(planned.webhooks, nil).Once()
+	suite.enforceSecurityWebhook.On("CanReadWebhook", mock.Anything, mock.Anything).Return(nil)
+	suite.repository.On("ListScenariosOfOrganization", suite.exec, suite.organizationId).
+		Return(planned.scenarios, nil).Once()
+
+	suite.customListRepository.On("AllCustomLists", suite.transaction, suite.organizationId).
+		Return(applied.customLists, nil).Maybe()
+	suite.repository.On("ListOrganizationTags", suite.exec, suite.organizationId, false).
+		Return(applied.tags, nil).Maybe()
+	suite.webhooksBackend.On("ListWebhooks", suite.organizationId, null.String{}).Return(applied.webhooks, nil).Maybe()
+	suite.repository.On("ListScenariosOfOrganization", suite.exec, suite.organizationId).
+		Return(applied.scenarios, nil).Maybe()
+	suite.repository.On("ListInboxes", suite.exec, suite.organizationId, []string(nil), false).
+		Return(applied.inboxes, nil).Maybe()
+}
+
+type organizationConfigElements struct {
+	customLists []models.CustomList
+	inboxes     []models.Inbox
+	tags        []models.Tag
+	webhooks    []models.Webhook
+	scenarios   []models.Scenario
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_customListValues() {
+	suite.expectDataModel()
+	elements := organizationConfigElements{customLists: []models.CustomList{suite.customList}}
+	suite.expectCurrentConfig(elements, elements)
+	suite.enforceSecurity.On("ModifyCustomList", suite.customList).Return(nil)
+	suite.customListRepository.On("UpdateCustomList", suite.transaction, models.UpdateCustomListInput{
+		Id:          suite.customList.Id,
+		Description: utils.Ptr(""),
+	}).Return(nil)
+	suite.customListRepository.On("GetCustomListValues", suite.transaction,
+		models.GetCustomListValuesInput{Id: suite.customList.Id}).
+		Return([]models.CustomListValue{{Id: "value_id", Value: "account_1"}}, nil)
+	suite.customListRepository.On("AddCustomListValue", suite.ctx, suite.transaction,
+		mock.MatchedBy(func(input models.AddCustomListValueInput) bool {
+			return input.CustomListId == suite.customList.Id && input.Value == "account_2"
+		})).Return(nil)
+	suite.customListRepository.On("DeleteCustomListValue", suite.ctx, suite.transaction,
+		models.DeleteCustomListValueInput{Id: "value_id", CustomListId: suite.customList.Id}).Return(nil)
+
+	config := suite.config()
+	config.CustomLists = []models.OrganizationConfigCustomList{{Name: "blocked accounts", Values: []string{"account_2"}}}
+	plan, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.NoError(err)
+	suite.Empty(plan.Conflicts)
+	suite.Equal(models.OrganizationConfigUpdate,
+		plan.Action(models.OrganizationConfigResourceCustomList, "blocked accounts"))
+	suite.AssertExpectations()
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_customListDeleted() {
+	suite.expectDataModel()
+	suite.expectCurrentConfig(organizationConfigElements{customLists: []models.CustomList{suite.customList}},
+		organizationConfigElements{customLists: []models.CustomList{}})
+
+	config := suite.config()
+	config.CustomLists = []models.OrganizationConfigCustomList{{Name: "blocked accounts", Values: []string{"account_1"}}}
+	_, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.enforceSecurity.AssertNotCalled(suite.T(), "ModifyCustomList", mock.Anything)
+	suite.customListRepository.AssertNotCalled(suite.T(), "UpdateCustomList", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_tagDeleted() {
+	suite.expectDataModel()
+	suite.expectCurrentConfig(
+		organizationConfigElements{tags: []models.Tag{{Id: "tag_id", Name: "urgent", Color: "#000000"}}},
+		organizationConfigElements{tags: []models.Tag{}})
+
+	config := suite.config()
+	config.Tags = []models.OrganizationConfigTag{{Name: "urgent", Color: "#ff0000"}}
+	_, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.AssertExpectations()
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_webhookDeleted() {
+	suite.expectDataModel()
+	suite.expectCurrentConfig(organizationConfigElements{webhooks: []models.Webhook{suite.webhook}},
+		organizationConfigElements{webhooks: []models.Webhook{}})
+
+	config := suite.config()
+	config.Webhooks = []models.OrganizationConfigWebhook{
+		{Url: suite.webhook.Url, EventTypes: []string{"case.created", "case.updated"}},
+	}
+	_, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.webhooksBackend.AssertNotCalled(suite.T(), "UpdateWebhook", mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_scenarioDeleted() {
+	suite.expectDataModel()
+	suite.expectCurrentConfig(organizationConfigElements{scenarios: []models.Scenario{suite.scenario}},
+		organizationConfigElements{scenarios: []models.Scenario{}})
+
+	config := suite.config()
+	config.ScenarioWorkflows = []models.OrganizationConfigScenarioWorkflow{
+		{ScenarioName: suite.scenario.Name, WorkflowType: models.WorkflowCreateCase},
+	}
+	_, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.AssertExpectations()
+}
+
+func (suite *OrganizationConfigUsecaseTestSuite) TestApplyOrganizationConfig_inboxDeleted() {
+	suite.expectDataModel()
+	suite.expectCurrentConfig(
+		organizationConfigElements{inboxes: []models.Inbox{suite.inbox}, scenarios: []models.Scenario{suite.scenario}},
+		organizationConfigElements{inboxes: []models.Inbox{}, scenarios: []models.Scenario{suite.scenario}})
+
+	config := suite.config()
+	config.ScenarioWorkflows = []models.OrganizationConfigScenarioWorkflow{{
+		ScenarioName: suite.scenario.Name,
+		WorkflowType: models.WorkflowCreateCase,
+		InboxName:    &suite.inbox.Name,
+	}}
+	_, err := suite.makeUsecase().ApplyOrganizationConfig(suite.ctx, suite.organizationId, config)
+	suite.ErrorIs(err, models.ConflictError)
+	suite.AssertExpectations()
+}
+
+func TestOrganizationConfigUsecase(t *testing.T) {
+	suite.Run(t, new(OrganizationConfigUsecaseTestSuite))
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/ratelimit"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type RateLimitRepository interface {
+	ListRateLimitSettings(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.RateLimitSettings, error)
+	UpsertRateLimitSettings(ctx context.Context, exec repositories.Executor, input models.UpsertRateLimitInput) error
+	DeleteRateLimitSettings(ctx context.Context, exec repositories.Executor, organizationId string, apiKeyId *string) error
+	GetApiKeyById(ctx context.Context, exec repositories.Executor, apiKeyId string) (models.ApiKey, error)
+}
+
+// RateLimitUsecase enforces the rate limits and monthly quotas of the organizations and of their API keys, and lets
+// the Marble admins configure them.
+type RateLimitUsecase struct {
+	enforceSecurity security.EnforceSecurityOrganization
+	executorFactory executor_factory.ExecutorFactory
+	repository      RateLimitRepository
+	limiter         *ratelimit.Limiter
+	credentials     models.Credentials
+}
+
+// Allow counts the current request against the limits of the caller. It returns nil if the request is not limited.
+func (usecase *RateLimitUsecase) Allow(ctx context.Context) (*models.RateLimitStatus, error) {
+	if usecase.limiter == nil {
+		return nil, nil
+	}
+	return usecase.limiter.Allow(ctx, usecase.credentials)
+}
+
+// ListRateLimits returns the rate limits of the organization and of its API keys, with their usage in the current
+// month. The default limits are returned for an organization without its own.
+func (usecase *RateLimitUsecase) ListRateLimits(ctx context.Context, organizationId string) ([]models.RateLimitUsage, error) {
+	if err := usecase.enforceSecurity.ReadRateLimits(organizationId); err != nil {
+		return nil, err
+	}
+	settings, err := usecase.repository.ListRateLimitSettings(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+	if err != nil {
+		return nil, err
+	}
+	if len(settings) == 0 || settings[0].ApiKeyId != nil {
+		settings = append([]models.RateLimitSettings{{
+			OrganizationId: organizationId,
+			Policy:         usecase.limiter.DefaultPolicy(),
+		}}, settings...)
+	}
+
+	usages := make([]models.RateLimitUsage, 0, len(settings))
+	for _, s := range settings {
+		period, count, err := usecase.limiter.RequestsInMonth(ctx, models.RateLimitSubject(s.OrganizationId, s.ApiKeyId))
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, models.RateLimitUsage{Settings: s, Period: period, RequestsInMonth: count})
+	}
+	return usages, nil
+}
+
+func (usecase *RateLimitUsecase) UpsertRateLimit(ctx context.Context, input models.UpsertRateLimitInput) error {
+	if err := usecase.enforceSecurity.ManageRateLimits(input.OrganizationId); err != nil {
+		return err
+	}
+	if err := input.Policy.Validate(); err != nil {
+		return err
+	}
+	exec := usecase.executorFactory.NewExecutor()
+	if err := usecase.checkApiKey(ctx, exec, input.OrganizationId, input.ApiKeyId); err != nil {
+		return err
+	}
+
+	if err := usecase.repository.UpsertRateLimitSettings(ctx, exec, input); err != nil {
+		return err
+	}
+	usecase.limiter.Invalidate(input.OrganizationId)
+	return nil
+}
+
+// DeleteRateLimit removes the rate limits of the organization, which falls back to the default limits, or of one of
+// its API keys, which is then only limited by its organization
+func (usecase *RateLimitUsecase) DeleteRateLimit(ctx context.Context, organizationId string, apiKeyId *string) error {
+	if err := usecase.enforceSecurity.ManageRateLimits(organizationId); err != nil {
+		return err
+	}
+	exec := usecase.executorFactory.NewExecutor()
+	if err := usecase.checkApiKey(ctx, exec, organizationId, apiKeyId); err != nil {
+		return err
+	}
+
+	if err := usecase.repository.DeleteRateLimitSettings(ctx, exec, organizationId, apiKeyId); err != nil {
+		return err
+	}
+	usecase.limiter.Invalidate(organizationId)
+	return nil
+}
+
+func (usecase *RateLimitUsecase) checkApiKey(ctx context.Context, exec repositories.Executor,
+	organizationId string, apiKeyId *string,
+) error {
+	if apiKeyId == nil {
+		return nil
+	}
+	apiKey, err := usecase.repository.GetApiKeyById(ctx, exec, *apiKeyId)
+	if err != nil {
+		return err
+	}
+	if apiKey.OrganizationId != organizationId {
+		return errors.Wrap(models.NotFoundError, "api key not found in the organization")
+	}
+	return nil
+}
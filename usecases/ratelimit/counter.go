@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+)
+
+// Counter counts the requests of the subjects against their monthly quotas
+type Counter interface {
+	Increment(ctx context.Context, subject, period string) (int64, error)
+	Get(ctx context.Context, subject, period string) (int64, error)
+}
+
+// MemoryCounter counts the requests received by this instance of the API only, for the current period
+type MemoryCounter struct {
+	mutex  sync.Mutex
+	period string
+	counts map[string]int64
+}
+
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{counts: make(map[string]int64)}
+}
+
+func (counter *MemoryCounter) Increment(ctx context.Context, subject, period string) (int64, error) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	// the counts of the previous period are forgotten
+	if period != counter.period {
+		counter.period = period
+		counter.counts = make(map[string]int64)
+	}
+	counter.counts[subject]++
+	return counter.counts[subject], nil
+}
+
+func (counter *MemoryCounter) Get(ctx context.Context, subject, period string) (int64, error) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	if period != counter.period {
+		return 0, nil
+	}
+	return counter.counts[subject], nil
+}
+
+type CounterRepository interface {
+	IncrementRateLimitCounter(ctx context.Context, exec repositories.Executor, subject, period string) (int64, error)
+	GetRateLimitCounter(ctx context.Context, exec repositories.Executor, subject, period string) (int64, error)
+}
+
+// PostgresCounter counts the requests in Postgres, so that the quotas are shared by all the instances of the API
+type PostgresCounter struct {
+	executorFactory executor_factory.ExecutorFactory
+	repository      CounterRepository
+}
+
+func NewPostgresCounter(executorFactory executor_factory.ExecutorFactory, repository CounterRepository) *PostgresCounter {
+	return &PostgresCounter{executorFactory: executorFactory, repository: repository}
+}
+
+func (counter *PostgresCounter) Increment(ctx context.Context, subject, period string) (int64, error) {
+	return counter.repository.IncrementRateLimitCounter(ctx, counter.executorFactory.NewExecutor(), subject, period)
+}
+
+func (counter *PostgresCounter) Get(ctx context.Context, subject, period string) (int64, error) {
+	return counter.repository.GetRateLimitCounter(ctx, counter.executorFactory.NewExecutor(), subject, period)
+}
@@ -0,0 +1,263 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/repositories/clock"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+)
+
+type SettingsRepository interface {
+	ListRateLimitSettings(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.RateLimitSettings, error)
+}
+
+type cachedSettings struct {
+	settings  []models.RateLimitSettings
+	fetchedAt time.Time
+}
+
+type bucket struct {
+	policy  models.RateLimitPolicy
+	limiter *rate.Limiter
+}
+
+type limit struct {
+	subject string
+	policy  models.RateLimitPolicy
+}
+
+// Limiter enforces the rate limits and the monthly quotas of the organizations and of their API keys. The token buckets
+// are kept in memory by each instance of the API, the quotas are counted by the Counter.
+type Limiter struct {
+	configuration   models.RateLimitConfiguration
+	executorFactory executor_factory.ExecutorFactory
+	repository      SettingsRepository
+	counter         Counter
+	clock           clock.Clock
+
+	mutex    sync.Mutex
+	settings map[string]cachedSettings
+	buckets  map[string]*bucket
+}
+
+func NewLimiter(
+	configuration models.RateLimitConfiguration,
+	executorFactory executor_factory.ExecutorFactory,
+	repository SettingsRepository,
+	counter Counter,
+	clock clock.Clock,
+) *Limiter {
+	return &Limiter{
+		configuration:   configuration,
+		executorFactory: executorFactory,
+		repository:      repository,
+		counter:         counter,
+		clock:           clock,
+		settings:        make(map[string]cachedSettings),
+		buckets:         make(map[string]*bucket),
+	}
+}
+
+// Allow counts a request of the credentials against the limits of their organization and of their API key. It returns
+// the status of the most restrictive limit, or nil if the request is not limited at all.
+func (l *Limiter) Allow(ctx context.Context, credentials models.Credentials) (*models.RateLimitStatus, error) {
+	if credentials.OrganizationId == "" {
+		return nil, nil
+	}
+	limits, err := l.limitsOf(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+	now := l.clock.Now()
+
+	var mostRestrictive *models.RateLimitStatus
+	keep := func(status models.RateLimitStatus) {
+		if mostRestrictive == nil || status.Remaining < mostRestrictive.Remaining {
+			mostRestrictive = &status
+		}
+	}
+
+	// the token buckets are checked first, so that the refused requests do not count against the quotas. A request
+	// refused by one bucket gives back the tokens it already took from the others.
+	var reservations []*rate.Reservation
+	for _, limit := range limits {
+		if !limit.policy.HasRateLimit() {
+			continue
+		}
+		status, reservation := l.take(limit, now)
+		if !status.Allowed {
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			return &status, nil
+		}
+		reservations = append(reservations, reservation)
+		keep(status)
+	}
+
+	// all the quotas are checked before any of them is counted, so that a request refused by the quota of its API key
+	// does not count against the quota of its organization
+	period := models.RateLimitPeriod(now)
+	for _, limit := range limits {
+		if !limit.policy.HasQuota() {
+			continue
+		}
+		count, err := l.counter.Get(ctx, limit.subject, period)
+		if err != nil {
+			return nil, err
+		}
+		if count >= limit.policy.MonthlyQuota {
+			status := quotaStatus(limit.policy, count+1, now)
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			return &status, nil
+		}
+	}
+	for _, limit := range limits {
+		if !limit.policy.HasQuota() {
+			continue
+		}
+		count, err := l.counter.Increment(ctx, limit.subject, period)
+		if err != nil {
+			return nil, err
+		}
+		// concurrent requests may still exceed the quota between the check and the count
+		status := quotaStatus(limit.policy, count, now)
+		if !status.Allowed {
+			return &status, nil
+		}
+		keep(status)
+	}
+
+	return mostRestrictive, nil
+}
+
+// RequestsInMonth is the number of requests of the subject counted in the current month
+func (l *Limiter) RequestsInMonth(ctx context.Context, subject string) (string, int64, error) {
+	period := models.RateLimitPeriod(l.clock.Now())
+	count, err := l.counter.Get(ctx, subject, period)
+	return period, count, err
+}
+
+// DefaultPolicy applies to the organizations without their own rate limits
+func (l *Limiter) DefaultPolicy() models.RateLimitPolicy {
+	return l.configuration.DefaultPolicy
+}
+
+// Invalidate forgets the cached rate limits of the organization, after they have been updated
+func (l *Limiter) Invalidate(organizationId string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.settings, organizationId)
+}
+
+func (l *Limiter) limitsOf(ctx context.Context, credentials models.Credentials) ([]limit, error) {
+	settings, err := l.settingsOf(ctx, credentials.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationLimit := limit{
+		subject: models.RateLimitSubject(credentials.OrganizationId, nil),
+		policy:  l.configuration.DefaultPolicy,
+	}
+	var apiKeyLimit *limit
+	for _, s := range settings {
+		switch {
+		case s.ApiKeyId == nil:
+			organizationLimit.policy = s.Policy
+		case *s.ApiKeyId == credentials.ActorIdentity.ApiKeyId:
+			apiKeyLimit = &limit{subject: models.RateLimitSubject(s.OrganizationId, s.ApiKeyId), policy: s.Policy}
+		}
+	}
+
+	limits := []limit{organizationLimit}
+	if apiKeyLimit != nil {
+		limits = append(limits, *apiKeyLimit)
+	}
+	return limits, nil
+}
+
+func (l *Limiter) settingsOf(ctx context.Context, organizationId string) ([]models.RateLimitSettings, error) {
+	now := l.clock.Now()
+
+	l.mutex.Lock()
+	cached, ok := l.settings[organizationId]
+	l.mutex.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < models.RATE_LIMIT_SETTINGS_CACHE_DURATION {
+		return cached.settings, nil
+	}
+
+	settings, err := l.repository.ListRateLimitSettings(ctx, l.executorFactory.NewExecutor(), organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.settings[organizationId] = cachedSettings{settings: settings, fetchedAt: now}
+	return settings, nil
+}
+
+func quotaStatus(policy models.RateLimitPolicy, count int64, now time.Time) models.RateLimitStatus {
+	status := models.RateLimitStatus{
+		Allowed:   count <= policy.MonthlyQuota,
+		Limit:     policy.MonthlyQuota,
+		Remaining: max(0, policy.MonthlyQuota-count),
+		Reset:     models.NextRateLimitPeriod(now).Sub(now),
+	}
+	if !status.Allowed {
+		status.RetryAfter = status.Reset
+		status.Reason = "monthly quota exceeded"
+	}
+	return status
+}
+
+// take reserves a token in the bucket of the limit. The reservation is only returned if the request is allowed, to be
+// cancelled if another limit refuses it.
+func (l *Limiter) take(limit limit, now time.Time) (models.RateLimitStatus, *rate.Reservation) {
+	l.mutex.Lock()
+	b, ok := l.buckets[limit.subject]
+	if !ok || b.policy != limit.policy {
+		b = &bucket{
+			policy:  limit.policy,
+			limiter: rate.NewLimiter(rate.Limit(limit.policy.RequestsPerSecond), limit.policy.BurstOrDefault()),
+		}
+		l.buckets[limit.subject] = b
+	}
+	l.mutex.Unlock()
+
+	burst := float64(limit.policy.BurstOrDefault())
+	reservation := b.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return models.RateLimitStatus{
+			Allowed:    false,
+			Limit:      int64(burst),
+			Remaining:  0,
+			Reset:      secondsToDuration(burst / limit.policy.RequestsPerSecond),
+			RetryAfter: delay,
+			Reason:     "rate limit exceeded",
+		}, nil
+	}
+
+	tokens := max(0, b.limiter.TokensAt(now))
+	return models.RateLimitStatus{
+		Allowed:   true,
+		Limit:     int64(burst),
+		Remaining: int64(math.Floor(tokens)),
+		Reset:     secondsToDuration((burst - tokens) / limit.policy.RequestsPerSecond),
+	}, reservation
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
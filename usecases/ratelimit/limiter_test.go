@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/clock"
+)
+
+func newTestLimiter(settings []models.RateLimitSettings, defaultPolicy models.RateLimitPolicy,
+	now time.Time,
+) (*Limiter, *mocks.RateLimitRepository) {
+	exec := new(mocks.Executor)
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(exec)
+	repository := new(mocks.RateLimitRepository)
+	repository.On("ListRateLimitSettings", exec, "organization_id").Return(settings, nil)
+
+	limiter := NewLimiter(
+		models.RateLimitConfiguration{Backend: models.RateLimitBackendMemory, DefaultPolicy: defaultPolicy},
+		executorFactory,
+		repository,
+		NewMemoryCounter(),
+		clock.NewMock(now),
+	)
+	return limiter, repository
+}
+
+var testCredentials = models.Credentials{
+	OrganizationId: "organization_id",
+	ActorIdentity:  models.Identity{ApiKeyId: "api_key_id"},
+}
+
+func TestLimiter_Allow_unlimited(t *testing.T) {
+	limiter, _ := newTestLimiter(nil, models.RateLimitPolicy{}, time.Now())
+
+	status, err := limiter.Allow(context.Background(), testCredentials)
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestLimiter_Allow_burst(t *testing.T) {
+	now := time.Date(2024, 9, 12, 10, 0, 0, 0, time.UTC)
+	limiter, _ := newTestLimiter(nil, models.RateLimitPolicy{RequestsPerSecond: 1, Burst: 2}, now)
+	ctx := context.Background()
+
+	status, err := limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RateLimitStatus{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, *status)
+
+	status, err = limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, int64(0), status.Remaining)
+
+	status, err = limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.False(t, status.Allowed)
+	assert.Equal(t, time.Second, status.RetryAfter)
+
+	// the bucket is refilled at one request per second
+	limiter.clock = clock.NewMock(now.Add(time.Second))
+	status, err = limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+}
+
+func TestLimiter_Allow_quota(t *testing.T) {
+	now := time.Date(2024, 9, 30, 23, 0, 0, 0, time.UTC)
+	apiKeyId := "api_key_id"
+	settings := []models.RateLimitSettings{
+		{Id: "1", OrganizationId: "organization_id", Policy: models.RateLimitPolicy{MonthlyQuota: 10}},
+		{Id: "2", OrganizationId: "organization_id", ApiKeyId: &apiKeyId, Policy: models.RateLimitPolicy{MonthlyQuota: 2}},
+	}
+	limiter, repository := newTestLimiter(settings, models.RateLimitPolicy{}, now)
+	ctx := context.Background()
+
+	for i := range 2 {
+		status, err := limiter.Allow(ctx, testCredentials)
+		assert.NoError(t, err)
+		// the quota of the API key is the most restrictive
+		assert.Equal(t, models.RateLimitStatus{Allowed: true, Limit: 2, Remaining: int64(1 - i), Reset: time.Hour}, *status)
+	}
+
+	status, err := limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.False(t, status.Allowed)
+	assert.Equal(t, time.Hour, status.RetryAfter)
+
+	// another API key of the organization is only limited by the quota of the organization
+	otherCredentials := models.Credentials{
+		OrganizationId: "organization_id",
+		ActorIdentity:  models.Identity{ApiKeyId: "other_api_key_id"},
+	}
+	status, err = limiter.Allow(ctx, otherCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, int64(10), status.Limit)
+	// the request refused by the quota of the API key was not counted against the quota of the organization
+	assert.Equal(t, int64(7), status.Remaining)
+
+	// the settings of the organization are cached
+	repository.AssertNumberOfCalls(t, "ListRateLimitSettings", 1)
+
+	// the quotas are reset at the start of the month
+	limiter.clock = clock.NewMock(now.Add(time.Hour))
+	status, err = limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+}
+
+func TestLimiter_Allow_throttled_api_key(t *testing.T) {
+	now := time.Date(2024, 9, 12, 10, 0, 0, 0, time.UTC)
+	apiKeyId := "api_key_id"
+	settings := []models.RateLimitSettings{
+		{Id: "1", OrganizationId: "organization_id", Policy: models.RateLimitPolicy{RequestsPerSecond: 1, Burst: 3}},
+		{Id: "2", OrganizationId: "organization_id", ApiKeyId: &apiKeyId, Policy: models.RateLimitPolicy{RequestsPerSecond: 1, Burst: 1}},
+	}
+	limiter, _ := newTestLimiter(settings, models.RateLimitPolicy{}, now)
+	ctx := context.Background()
+
+	status, err := limiter.Allow(ctx, testCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+
+	// the API key is throttled: the requests it sends do not take tokens from the bucket of the organization
+	for i := 0; i < 5; i++ {
+		status, err = limiter.Allow(ctx, testCredentials)
+		assert.NoError(t, err)
+		assert.False(t, status.Allowed)
+	}
+
+	otherCredentials := models.Credentials{
+		OrganizationId: "organization_id",
+		ActorIdentity:  models.Identity{ApiKeyId: "other_api_key_id"},
+	}
+	status, err = limiter.Allow(ctx, otherCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, int64(1), status.Remaining)
+
+	status, err = limiter.Allow(ctx, otherCredentials)
+	assert.NoError(t, err)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, int64(0), status.Remaining)
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type RulePerformanceRepository interface {
+	GetScenarioById(ctx context.Context, exec repositories.Executor, scenarioId string) (models.Scenario, error)
+	CountDecisionLabels(ctx context.Context, exec repositories.Executor,
+		input models.RulePerformanceReportInput) ([]models.DecisionLabelCount, error)
+	CountRuleExecutionLabels(ctx context.Context, exec repositories.Executor,
+		input models.RulePerformanceReportInput) ([]models.RuleExecutionLabelCount, error)
+}
+
+// RulePerformanceUsecase measures how well the rules of a scenario predict the labels given to its decisions by the
+// analysts (review of the decision, status of its case, status of the transfer for transfer checks).
+type RulePerformanceUsecase struct {
+	enforceSecurity security.EnforceSecurityScenario
+	executorFactory executor_factory.ExecutorFactory
+	repository      RulePerformanceRepository
+}
+
+func (usecase *RulePerformanceUsecase) GetRulePerformanceReport(
+	ctx context.Context,
+	input models.RulePerformanceReportInput,
+) (models.RulePerformanceReport, error) {
+	if err := input.Validate(); err != nil {
+		return models.RulePerformanceReport{}, err
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	scenario, err := usecase.repository.GetScenarioById(ctx, exec, input.ScenarioId)
+	if err != nil {
+		return models.RulePerformanceReport{}, err
+	}
+	if err := usecase.enforceSecurity.ReadScenario(scenario); err != nil {
+		return models.RulePerformanceReport{}, err
+	}
+	input.OrganizationId = scenario.OrganizationId
+
+	decisionCounts, err := usecase.repository.CountDecisionLabels(ctx, exec, input)
+	if err != nil {
+		return models.RulePerformanceReport{}, err
+	}
+	ruleExecutionCounts, err := usecase.repository.CountRuleExecutionLabels(ctx, exec, input)
+	if err != nil {
+		return models.RulePerformanceReport{}, err
+	}
+	return models.NewRulePerformanceReport(input, decisionCounts, ruleExecutionCounts), nil
+}
@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type RulePerformanceUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity *mocks.EnforceSecurity
+	repository      *mocks.RulePerformanceRepository
+	exec            *mocks.Executor
+
+	ctx      context.Context
+	scenario models.Scenario
+	input    models.RulePerformanceReportInput
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.RulePerformanceRepository)
+	suite.exec = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.scenario = models.Scenario{Id: "scenario_id", OrganizationId: "organization_id"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.input = models.RulePerformanceReportInput{
+		ScenarioId: suite.scenario.Id,
+		StartDate:  start,
+		EndDate:    start.AddDate(0, 1, 0),
+	}
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) makeUsecase() *RulePerformanceUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+
+	return &RulePerformanceUsecase{
+		enforceSecurity: suite.enforceSecurity,
+		executorFactory: executorFactory,
+		repository:      suite.repository,
+	}
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) TestGetRulePerformanceReport() {
+	fraud := utils.Ptr(models.DecisionReviewConfirmedFraud)
+	resolved := utils.Ptr(models.CaseResolved)
+	discarded := utils.Ptr(models.CaseDiscarded)
+	// the organization of the report is the organization of the scenario
+	input := suite.input
+	input.OrganizationId = suite.scenario.OrganizationId
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(nil)
+	suite.repository.On("CountDecisionLabels", suite.exec, input).Return([]models.DecisionLabelCount{
+		{ReviewDisposition: fraud, Count: 2},
+		{CaseStatus: resolved, Count: 3},
+		{CaseStatus: discarded, Count: 5},
+		{Count: 10},
+	}, nil)
+	suite.repository.On("CountRuleExecutionLabels", suite.exec, input).Return([]models.RuleExecutionLabelCount{
+		{RuleId: "rule_id", RuleName: "big amount", Result: true, ReviewDisposition: fraud, Count: 2},
+		{RuleId: "rule_id", RuleName: "big amount", Result: false, CaseStatus: resolved, Count: 3},
+		{RuleId: "rule_id", RuleName: "big amount", Result: true, CaseStatus: discarded, Count: 1},
+		{RuleId: "rule_id", RuleName: "big amount", Result: false, CaseStatus: discarded, Count: 4},
+		{RuleId: "rule_id", RuleName: "big amount", Result: true, Count: 6},
+		{RuleId: "rule_id", RuleName: "big amount", Result: false, Count: 4},
+	}, nil)
+
+	report, err := suite.makeUsecase().GetRulePerformanceReport(suite.ctx, suite.input)
+	suite.NoError(err)
+	suite.Equal(models.RulePerformanceReport{
+		ScenarioId:  suite.scenario.Id,
+		StartDate:   suite.input.StartDate,
+		EndDate:     suite.input.EndDate,
+		NbDecisions: 20,
+		NbLabeled:   10,
+		NbPositives: 5,
+		Rules: []models.RulePerformance{{
+			RuleId:         "rule_id",
+			RuleName:       "big amount",
+			NbMatched:      9,
+			TruePositives:  2,
+			FalsePositives: 1,
+			FalseNegatives: 3,
+			TrueNegatives:  4,
+		}},
+	}, report)
+	suite.AssertExpectations()
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) TestGetRulePerformanceReport_forbidden() {
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().GetRulePerformanceReport(suite.ctx, suite.input)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "CountDecisionLabels", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *RulePerformanceUsecaseTestSuite) TestGetRulePerformanceReport_invalidWindow() {
+	suite.input.EndDate = suite.input.StartDate
+
+	_, err := suite.makeUsecase().GetRulePerformanceReport(suite.ctx, suite.input)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioById", mock.Anything, mock.Anything)
+}
+
+func TestRulePerformanceUsecase(t *testing.T) {
+	suite.Run(t, new(RulePerformanceUsecaseTestSuite))
+}
@@ -0,0 +1,261 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/pure_utils"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type ScenarioBundleRepository interface {
+	GetScenarioById(ctx context.Context, exec repositories.Executor, scenarioId string) (models.Scenario, error)
+	ListScenariosOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.Scenario, error)
+	CreateScenario(
+		ctx context.Context,
+		exec repositories.Executor,
+		organizationId string,
+		scenario models.CreateScenarioInput,
+		newScenarioId string,
+	) error
+	ListScenarioIterations(
+		ctx context.Context,
+		exec repositories.Executor,
+		organizationId string,
+		filters models.GetScenarioIterationFilters,
+	) ([]models.ScenarioIteration, error)
+	CreateScenarioIterationAndRules(
+		ctx context.Context,
+		exec repositories.Executor,
+		organizationId string,
+		scenarioIteration models.CreateScenarioIterationInput,
+	) (models.ScenarioIteration, error)
+	UpdateScenarioIterationVersion(
+		ctx context.Context,
+		exec repositories.Executor,
+		scenarioIterationId string,
+		newVersion int,
+	) error
+}
+
+type ScenarioBundleUsecase struct {
+	enforceSecurity           security.EnforceSecurityScenario
+	enforceSecurityCustomList security.EnforceSecurityCustomList
+	executorFactory           executor_factory.ExecutorFactory
+	transactionFactory        executor_factory.TransactionFactory
+	repository                ScenarioBundleRepository
+	customListRepository      repositories.CustomListRepository
+	dataModelRepository       repositories.DataModelRepository
+}
+
+// ExportScenario returns the scenario with its committed iterations and its draft, the custom lists used by their
+// formulas and the data model fields they read.
+func (usecase *ScenarioBundleUsecase) ExportScenario(ctx context.Context, scenarioId string) (models.ScenarioBundle, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	scenario, err := usecase.repository.GetScenarioById(ctx, exec, scenarioId)
+	if err != nil {
+		return models.ScenarioBundle{}, err
+	}
+	if err := usecase.enforceSecurity.ReadScenario(scenario); err != nil {
+		return models.ScenarioBundle{}, err
+	}
+
+	iterations, err := usecase.repository.ListScenarioIterations(ctx, exec, scenario.OrganizationId,
+		models.GetScenarioIterationFilters{ScenarioId: &scenario.Id})
+	if err != nil {
+		return models.ScenarioBundle{}, err
+	}
+	slices.SortFunc(iterations, func(a, b models.ScenarioIteration) int {
+		// drafts come last
+		switch {
+		case a.Version == nil && b.Version == nil:
+			return 0
+		case a.Version == nil:
+			return 1
+		case b.Version == nil:
+			return -1
+		}
+		return *a.Version - *b.Version
+	})
+
+	bundle := models.ScenarioBundle{
+		Version:    models.SCENARIO_BUNDLE_VERSION,
+		ExportedAt: time.Now(),
+		Scenario: models.ScenarioBundleScenario{
+			Name:              scenario.Name,
+			Description:       scenario.Description,
+			TriggerObjectType: scenario.TriggerObjectType,
+		},
+		Iterations: pure_utils.Map(iterations, models.NewScenarioBundleIteration),
+	}
+
+	dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, exec, scenario.OrganizationId, false)
+	if err != nil {
+		return models.ScenarioBundle{}, err
+	}
+	references := models.CollectScenarioAstReferences(scenario.TriggerObjectType, bundle.Iterations)
+	bundle.DataModel = references.Fields
+	for i, requirement := range bundle.DataModel {
+		if field, err := dataModel.ResolveField(requirement); err == nil {
+			bundle.DataModel[i].DataType = field.DataType
+		}
+	}
+
+	bundle.CustomLists = make([]models.ScenarioBundleCustomList, 0, len(references.CustomListIds))
+	for _, customListId := range references.CustomListIds {
+		customList, err := usecase.customListRepository.GetCustomListById(ctx, exec, customListId)
+		if errors.Is(err, models.NotFoundError) {
+			// the formula references a deleted list, the import will report it
+			continue
+		} else if err != nil {
+			return models.ScenarioBundle{}, err
+		}
+		if err := usecase.enforceSecurityCustomList.ReadCustomList(customList); err != nil {
+			return models.ScenarioBundle{}, err
+		}
+		values, err := usecase.customListRepository.GetCustomListValues(ctx, exec,
+			models.GetCustomListValuesInput{Id: customListId})
+		if err != nil {
+			return models.ScenarioBundle{}, err
+		}
+		bundle.CustomLists = append(bundle.CustomLists, models.ScenarioBundleCustomList{
+			Id:          customList.Id,
+			Name:        customList.Name,
+			Description: customList.Description,
+			Values:      pure_utils.Map(values, func(v models.CustomListValue) string { return v.Value }),
+		})
+	}
+
+	return bundle, nil
+}
+
+// ImportScenario validates the bundle against the organization and, if there is no conflict and it is not a dry run,
+// creates the scenario, its iterations and the missing custom lists. Committed iterations keep their version number,
+// but none of them is published: publication must be done in the target organization.
+func (usecase *ScenarioBundleUsecase) ImportScenario(
+	ctx context.Context,
+	organizationId string,
+	bundle models.ScenarioBundle,
+	dryRun bool,
+) (models.ScenarioImportResult, error) {
+	if err := usecase.enforceSecurity.CreateScenario(organizationId); err != nil {
+		return models.ScenarioImportResult{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScenarioImportResult, error) {
+		dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, tx, organizationId, false)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+		scenarios, err := usecase.repository.ListScenariosOfOrganization(ctx, tx, organizationId)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+		customLists, err := usecase.customListRepository.AllCustomLists(ctx, tx, organizationId)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+
+		report := models.ValidateScenarioBundle(bundle, dataModel, scenarios, customLists)
+		result := models.ScenarioImportResult{Report: report}
+		if len(report.Conflicts) > 0 || dryRun {
+			return result, nil
+		}
+
+		customListIds, err := usecase.createCustomLists(ctx, tx, organizationId, bundle, report.CustomLists)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+
+		scenarioId := pure_utils.NewPrimaryKey(organizationId)
+		err = usecase.repository.CreateScenario(ctx, tx, organizationId, models.CreateScenarioInput{
+			Name:              bundle.Scenario.Name,
+			Description:       bundle.Scenario.Description,
+			TriggerObjectType: bundle.Scenario.TriggerObjectType,
+		}, scenarioId)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+
+		for _, bundleIteration := range bundle.Iterations {
+			body := bundleIteration.CreateScenarioIterationBody(customListIds)
+			iteration, err := usecase.repository.CreateScenarioIterationAndRules(ctx, tx, organizationId,
+				models.CreateScenarioIterationInput{ScenarioId: scenarioId, Body: &body})
+			if err != nil {
+				return models.ScenarioImportResult{}, err
+			}
+			if bundleIteration.Version != nil {
+				err := usecase.repository.UpdateScenarioIterationVersion(ctx, tx, iteration.Id, *bundleIteration.Version)
+				if err != nil {
+					return models.ScenarioImportResult{}, err
+				}
+			}
+		}
+
+		scenario, err := usecase.repository.GetScenarioById(ctx, tx, scenarioId)
+		if err != nil {
+			return models.ScenarioImportResult{}, err
+		}
+		result.Scenario = &scenario
+		return result, nil
+	})
+}
+
+// createCustomLists creates the custom lists planned for creation in the report, and returns the mapping of the custom
+// list ids of the bundle to the ids in the organization.
+func (usecase *ScenarioBundleUsecase) createCustomLists(
+	ctx context.Context,
+	tx repositories.Executor,
+	organizationId string,
+	bundle models.ScenarioBundle,
+	mappings []models.ScenarioImportCustomList,
+) (map[string]string, error) {
+	customListIds := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		if !mapping.Create {
+			customListIds[mapping.SourceId] = *mapping.TargetId
+			continue
+		}
+		if err := usecase.enforceSecurityCustomList.CreateCustomList(); err != nil {
+			return nil, err
+		}
+
+		bundleCustomListIdx := slices.IndexFunc(bundle.CustomLists, func(customList models.ScenarioBundleCustomList) bool {
+			return customList.Id == mapping.SourceId
+		})
+		if bundleCustomListIdx < 0 {
+			return nil, errors.Wrapf(models.BadParameterError, "custom list %s is not part of the bundle", mapping.SourceId)
+		}
+		bundleCustomList := bundle.CustomLists[bundleCustomListIdx]
+		newCustomListId := uuid.NewString()
+		err := usecase.customListRepository.CreateCustomList(ctx, tx, models.CreateCustomListInput{
+			Name:        bundleCustomList.Name,
+			Description: bundleCustomList.Description,
+		}, organizationId, newCustomListId)
+		if repositories.IsUniqueViolationError(err) {
+			return nil, errors.Wrapf(models.ConflictError, "custom list %s already exists", bundleCustomList.Name)
+		} else if err != nil {
+			return nil, err
+		}
+		for _, value := range bundleCustomList.Values {
+			err := usecase.customListRepository.AddCustomListValue(ctx, tx, models.AddCustomListValueInput{
+				CustomListId: newCustomListId,
+				Value:        value,
+			}, uuid.NewString())
+			if err != nil {
+				return nil, err
+			}
+		}
+		customListIds[mapping.SourceId] = newCustomListId
+	}
+	return customListIds, nil
+}
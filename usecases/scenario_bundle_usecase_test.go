@@ -0,0 +1,260 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type ScenarioBundleUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity      *mocks.EnforceSecurity
+	repository           *mocks.ScenarioBundleRepository
+	customListRepository *mocks.CustomListRepository
+	dataModelRepository  *mocks.DataModelRepository
+	exec                 *mocks.Executor
+	transaction          *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	scenario       models.Scenario
+	dataModel      models.DataModel
+	formula        ast.Node
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.ScenarioBundleRepository)
+	suite.customListRepository = new(mocks.CustomListRepository)
+	suite.dataModelRepository = new(mocks.DataModelRepository)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "3c5f1b5e-2d8e-4f7a-9b1c-6e0d4a2f8b71"
+	suite.scenario = models.Scenario{
+		Id:                "scenario_id",
+		OrganizationId:    suite.organizationId,
+		Name:              "big transactions",
+		TriggerObjectType: "transactions",
+	}
+	suite.dataModel = models.DataModel{Tables: map[string]models.Table{
+		"transactions": {
+			Name: "transactions",
+			Fields: map[string]models.Field{
+				"object_id": {Name: "object_id", DataType: models.String},
+				"amount":    {Name: "amount", DataType: models.Float},
+			},
+		},
+	}}
+	// amount > 1000 and the account is in the list
+	suite.formula = ast.Node{Function: ast.FUNC_AND}.
+		AddChild(ast.Node{Function: ast.FUNC_GREATER}.
+			AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("amount"))).
+			AddChild(ast.NewNodeConstant(1000))).
+		AddChild(ast.Node{Function: ast.FUNC_IS_IN_LIST}.
+			AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("object_id"))).
+			AddChild(ast.NewNodeCustomListAccess("source_list_id")))
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) makeUsecase() *ScenarioBundleUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &ScenarioBundleUsecase{
+		enforceSecurity:           suite.enforceSecurity,
+		enforceSecurityCustomList: suite.enforceSecurity,
+		executorFactory:           executorFactory,
+		transactionFactory:        transactionFactory,
+		repository:                suite.repository,
+		customListRepository:      suite.customListRepository,
+		dataModelRepository:       suite.dataModelRepository,
+	}
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.customListRepository.AssertExpectations(t)
+	suite.dataModelRepository.AssertExpectations(t)
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) bundle() models.ScenarioBundle {
+	return models.ScenarioBundle{
+		Version: models.SCENARIO_BUNDLE_VERSION,
+		Scenario: models.ScenarioBundleScenario{
+			Name:              "imported scenario",
+			TriggerObjectType: "transactions",
+		},
+		Iterations: []models.ScenarioBundleIteration{
+			{
+				Version: utils.Ptr(3),
+				Rules:   []models.ScenarioBundleRule{{Name: "rule", FormulaAstExpression: &suite.formula}},
+			},
+			{Rules: []models.ScenarioBundleRule{{Name: "rule", FormulaAstExpression: &suite.formula}}},
+		},
+		CustomLists: []models.ScenarioBundleCustomList{
+			{Id: "existing_source_list_id", Name: "existing list"},
+			{Id: "source_list_id", Name: "blocked accounts", Values: []string{"account_1", "account_2"}},
+		},
+		DataModel: []models.ScenarioBundleFieldRequirement{
+			{TableName: "transactions", FieldName: "amount", DataType: models.Float},
+			{TableName: "transactions", FieldName: "object_id", DataType: models.String},
+		},
+	}
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) expectImportValidation(scenarios []models.Scenario) {
+	suite.enforceSecurity.On("CreateScenario", suite.organizationId).Return(nil)
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.transaction, suite.organizationId, false).
+		Return(suite.dataModel, nil)
+	suite.repository.On("ListScenariosOfOrganization", suite.transaction, suite.organizationId).Return(scenarios, nil)
+	suite.customListRepository.On("AllCustomLists", suite.transaction, suite.organizationId).
+		Return([]models.CustomList{{Id: "existing_list_id", Name: "existing list"}}, nil)
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestExportScenario() {
+	draft := models.ScenarioIteration{
+		Id:    "draft_id",
+		Rules: []models.Rule{{Name: "rule", FormulaAstExpression: &suite.formula}},
+	}
+	committed := models.ScenarioIteration{
+		Id:      "committed_id",
+		Version: utils.Ptr(1),
+		Rules:   []models.Rule{{Name: "rule", FormulaAstExpression: &suite.formula}},
+	}
+	customList := models.CustomList{Id: "source_list_id", Name: "blocked accounts"}
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(nil)
+	suite.repository.On("ListScenarioIterations", suite.exec, suite.organizationId,
+		models.GetScenarioIterationFilters{ScenarioId: &suite.scenario.Id}).
+		Return([]models.ScenarioIteration{draft, committed}, nil)
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.exec, suite.organizationId, false).
+		Return(suite.dataModel, nil)
+	suite.customListRepository.On("GetCustomListById", suite.exec, customList.Id).Return(customList, nil)
+	suite.enforceSecurity.On("ReadCustomList", customList).Return(nil)
+	suite.customListRepository.On("GetCustomListValues", suite.exec,
+		models.GetCustomListValuesInput{Id: customList.Id}).
+		Return([]models.CustomListValue{{Value: "account_1"}, {Value: "account_2"}}, nil)
+
+	bundle, err := suite.makeUsecase().ExportScenario(suite.ctx, suite.scenario.Id)
+	suite.NoError(err)
+	suite.Equal(models.ScenarioBundleScenario{Name: "big transactions", TriggerObjectType: "transactions"},
+		bundle.Scenario)
+	// the draft comes after the committed iterations
+	suite.Len(bundle.Iterations, 2)
+	suite.Equal(utils.Ptr(1), bundle.Iterations[0].Version)
+	suite.Nil(bundle.Iterations[1].Version)
+	suite.Equal([]models.ScenarioBundleCustomList{{
+		Id:     "source_list_id",
+		Name:   "blocked accounts",
+		Values: []string{"account_1", "account_2"},
+	}}, bundle.CustomLists)
+	suite.Equal(suite.bundle().DataModel, bundle.DataModel)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestExportScenario_forbidden() {
+	suite.repository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenario", suite.scenario).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().ExportScenario(suite.ctx, suite.scenario.Id)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "ListScenarioIterations", mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestImportScenario() {
+	suite.expectImportValidation([]models.Scenario{suite.scenario})
+	suite.enforceSecurity.On("CreateCustomList").Return(nil)
+	suite.customListRepository.On("CreateCustomList", suite.transaction,
+		models.CreateCustomListInput{Name: "blocked accounts"}).Return(nil)
+	var newCustomListId string
+	suite.customListRepository.On("AddCustomListValue", suite.ctx, suite.transaction,
+		mock.MatchedBy(func(input models.AddCustomListValueInput) bool {
+			newCustomListId = input.CustomListId
+			return input.Value == "account_1" || input.Value == "account_2"
+		})).Return(nil).Twice()
+	var scenarioId string
+	suite.repository.On("CreateScenario", suite.transaction, suite.organizationId, models.CreateScenarioInput{
+		Name:              "imported scenario",
+		TriggerObjectType: "transactions",
+	}, mock.Anything).Run(func(args mock.Arguments) { scenarioId = args.String(3) }).Return(nil)
+	// the formulas use the id of the created list
+	usesNewCustomList := mock.MatchedBy(func(input models.CreateScenarioIterationInput) bool {
+		formula := input.Body.Rules[0].FormulaAstExpression
+		listId, err := formula.Children[1].Children[1].ReadConstantNamedChildString(
+			ast.AttributeFuncCustomListAccess.ArgumentCustomListId)
+		return err == nil && listId == newCustomListId && input.ScenarioId == scenarioId
+	})
+	suite.repository.On("CreateScenarioIterationAndRules", suite.transaction, suite.organizationId,
+		usesNewCustomList).Return(models.ScenarioIteration{Id: "committed_id"}, nil).Once()
+	suite.repository.On("UpdateScenarioIterationVersion", suite.transaction, "committed_id", 3).Return(nil)
+	suite.repository.On("CreateScenarioIterationAndRules", suite.transaction, suite.organizationId,
+		usesNewCustomList).Return(models.ScenarioIteration{Id: "draft_id"}, nil).Once()
+	imported := models.Scenario{Id: "imported_id", Name: "imported scenario"}
+	suite.repository.On("GetScenarioById", suite.transaction, mock.Anything).Return(imported, nil)
+
+	result, err := suite.makeUsecase().ImportScenario(suite.ctx, suite.organizationId, suite.bundle(), false)
+	suite.NoError(err)
+	suite.Empty(result.Report.Conflicts)
+	suite.Equal([]models.ScenarioImportCustomList{
+		{Name: "existing list", SourceId: "existing_source_list_id", TargetId: utils.Ptr("existing_list_id")},
+		{Name: "blocked accounts", SourceId: "source_list_id", Create: true},
+	}, result.Report.CustomLists)
+	suite.Equal(&imported, result.Scenario)
+	suite.NotEmpty(newCustomListId)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestImportScenario_dryRun() {
+	suite.expectImportValidation([]models.Scenario{suite.scenario})
+
+	result, err := suite.makeUsecase().ImportScenario(suite.ctx, suite.organizationId, suite.bundle(), true)
+	suite.NoError(err)
+	suite.Empty(result.Report.Conflicts)
+	suite.Len(result.Report.CustomLists, 2)
+	suite.Nil(result.Scenario)
+	suite.customListRepository.AssertNotCalled(suite.T(), "CreateCustomList", mock.Anything, mock.Anything)
+	suite.repository.AssertNotCalled(suite.T(), "CreateScenario", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestImportScenario_conflicts() {
+	taken := models.Scenario{Id: "other_scenario_id", Name: "imported scenario"}
+	suite.expectImportValidation([]models.Scenario{taken})
+	bundle := suite.bundle()
+	bundle.DataModel[0].DataType = models.Int
+
+	result, err := suite.makeUsecase().ImportScenario(suite.ctx, suite.organizationId, bundle, false)
+	suite.NoError(err)
+	suite.Equal([]models.ScenarioImportConflictKind{
+		models.ScenarioImportConflictScenarioName,
+		models.ScenarioImportConflictFieldType,
+	}, []models.ScenarioImportConflictKind{result.Report.Conflicts[0].Kind, result.Report.Conflicts[1].Kind})
+	suite.Nil(result.Scenario)
+	suite.repository.AssertNotCalled(suite.T(), "CreateScenario", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioBundleUsecaseTestSuite) TestImportScenario_forbidden() {
+	suite.enforceSecurity.On("CreateScenario", suite.organizationId).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().ImportScenario(suite.ctx, suite.organizationId, suite.bundle(), false)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.AssertExpectations()
+}
+
+func TestScenarioBundleUsecase(t *testing.T) {
+	suite.Run(t, new(ScenarioBundleUsecaseTestSuite))
+}
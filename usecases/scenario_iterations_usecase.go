@@ -97,6 +97,43 @@ func (usecase *ScenarioIterationUsecase) GetScenarioIteration(ctx context.Contex
 	return si, nil
 }
 
+// DiffScenarioIteration compares the iteration to a base iteration of the same scenario.
+// If `baseIterationId` is nil, the iteration is compared to the live version of the scenario.
+func (usecase *ScenarioIterationUsecase) DiffScenarioIteration(ctx context.Context,
+	scenarioIterationId string, baseIterationId *string,
+) (models.ScenarioIterationDiff, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	target, err := usecase.scenarioFetcher.FetchScenarioAndIteration(ctx, exec, scenarioIterationId)
+	if err != nil {
+		return models.ScenarioIterationDiff{}, err
+	}
+	if err := usecase.enforceSecurity.ReadScenarioIteration(target.Iteration); err != nil {
+		return models.ScenarioIterationDiff{}, err
+	}
+
+	if baseIterationId == nil {
+		if target.Scenario.LiveVersionID == nil {
+			return models.ScenarioIterationDiff{}, errors.Wrap(models.BadParameterError,
+				"the scenario has no live version to compare to, provide a base iteration")
+		}
+		baseIterationId = target.Scenario.LiveVersionID
+	}
+
+	base, err := usecase.repository.GetScenarioIteration(ctx, exec, *baseIterationId)
+	if err != nil {
+		return models.ScenarioIterationDiff{}, err
+	}
+	if err := usecase.enforceSecurity.ReadScenarioIteration(base); err != nil {
+		return models.ScenarioIterationDiff{}, err
+	}
+	if base.ScenarioId != target.Iteration.ScenarioId {
+		return models.ScenarioIterationDiff{}, errors.Wrap(models.BadParameterError,
+			"the base iteration does not belong to the same scenario")
+	}
+
+	return models.DiffScenarioIterations(base, target.Iteration), nil
+}
+
 func (usecase *ScenarioIterationUsecase) CreateScenarioIteration(ctx context.Context,
 	organizationId string, scenarioIteration models.CreateScenarioIterationInput,
 ) (models.ScenarioIteration, error) {
@@ -111,6 +148,14 @@ func (usecase *ScenarioIterationUsecase) CreateScenarioIteration(ctx context.Con
 			return models.ScenarioIteration{}, fmt.Errorf("invalid schedule: %w", models.BadParameterError)
 		}
 	}
+	if body != nil {
+		if err := scenarios.ValidateScheduleTimezone(body.ScheduleTimezone); err != nil {
+			return models.ScenarioIteration{}, err
+		}
+		if err := scenarios.ValidateOutputVariables(body.OutputVariables); err != nil {
+			return models.ScenarioIteration{}, err
+		}
+	}
 
 	if body == nil {
 		body = &models.CreateScenarioIterationBody{}
@@ -159,6 +204,16 @@ func (usecase *ScenarioIterationUsecase) UpdateScenarioIteration(ctx context.Con
 			return iteration, fmt.Errorf("invalid schedule: %w", models.BadParameterError)
 		}
 	}
+	if body.ScheduleTimezone != nil {
+		if err := scenarios.ValidateScheduleTimezone(*body.ScheduleTimezone); err != nil {
+			return iteration, err
+		}
+	}
+	if body.OutputVariables != nil {
+		if err := scenarios.ValidateOutputVariables(*body.OutputVariables); err != nil {
+			return iteration, err
+		}
+	}
 	if scenarioAndIteration.Iteration.Version != nil {
 		return iteration, errors.Wrap(
 			models.ErrScenarioIterationNotDraft,
@@ -207,6 +262,8 @@ func (usecase *ScenarioIterationUsecase) CreateDraftFromScenarioIteration(ctx co
 				ScoreRejectThreshold:          si.ScoreRejectThreshold,
 				BatchTriggerSQL:               si.BatchTriggerSQL,
 				Schedule:                      si.Schedule,
+				ScheduleTimezone:              si.ScheduleTimezone,
+				OutputVariables:               si.OutputVariables,
 				Rules:                         make([]models.CreateRuleInput, len(si.Rules)),
 				TriggerConditionAstExpression: si.TriggerConditionAstExpression,
 			}
@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/usecases/scenarios"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type ScenarioIterationUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity   *mocks.EnforceSecurity
+	repository        *mocks.IterationUsecaseRepository
+	fetcherRepository *mocks.DecisionUsecaseRepository
+	exec              *mocks.Executor
+
+	ctx      context.Context
+	scenario models.Scenario
+	live     models.ScenarioIteration
+	draft    models.ScenarioIteration
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.IterationUsecaseRepository)
+	suite.fetcherRepository = new(mocks.DecisionUsecaseRepository)
+	suite.exec = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.scenario = models.Scenario{
+		Id:             "scenario_id",
+		OrganizationId: "organization_id",
+		LiveVersionID:  utils.Ptr("live_iteration_id"),
+	}
+	suite.live = models.ScenarioIteration{
+		Id:                   "live_iteration_id",
+		ScenarioId:           suite.scenario.Id,
+		ScoreReviewThreshold: utils.Ptr(10),
+		Schedule:             "0 * * * *",
+		Rules:                []models.Rule{{Id: "live_rule_id", Name: "rule", DisplayOrder: 1}},
+	}
+	suite.draft = models.ScenarioIteration{
+		Id:                   "draft_iteration_id",
+		ScenarioId:           suite.scenario.Id,
+		ScoreReviewThreshold: utils.Ptr(20),
+		Schedule:             "0 0 * * *",
+		Rules:                []models.Rule{{Id: "draft_rule_id", Name: "rule", DisplayOrder: 2}},
+	}
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) makeUsecase() *ScenarioIterationUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+
+	return &ScenarioIterationUsecase{
+		repository:      suite.repository,
+		enforceSecurity: suite.enforceSecurity,
+		scenarioFetcher: scenarios.ScenarioFetcher{Repository: suite.fetcherRepository},
+		executorFactory: executorFactory,
+	}
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.fetcherRepository.AssertExpectations(t)
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) expectDraft() {
+	suite.fetcherRepository.On("GetScenarioIteration", suite.exec, suite.draft.Id).Return(suite.draft, nil)
+	suite.fetcherRepository.On("GetScenarioById", suite.exec, suite.scenario.Id).Return(suite.scenario, nil)
+	suite.enforceSecurity.On("ReadScenarioIteration", suite.draft).Return(nil)
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) TestDiffScenarioIteration_liveVersion() {
+	suite.expectDraft()
+	suite.repository.On("GetScenarioIteration", suite.exec, suite.live.Id).Return(suite.live, nil)
+	suite.enforceSecurity.On("ReadScenarioIteration", suite.live).Return(nil)
+
+	diff, err := suite.makeUsecase().DiffScenarioIteration(suite.ctx, suite.draft.Id, nil)
+	suite.NoError(err)
+	suite.Equal(suite.live.Id, diff.BaseIterationId)
+	suite.Equal(suite.draft.Id, diff.TargetIterationId)
+	suite.Equal(&models.ValueChange[*int]{Before: utils.Ptr(10), After: utils.Ptr(20)}, diff.ScoreReviewThreshold)
+	suite.Equal(&models.ValueChange[string]{Before: "0 * * * *", After: "0 0 * * *"}, diff.Schedule)
+	suite.Len(diff.ModifiedRules, 1)
+	suite.Equal(&models.ValueChange[int]{Before: 1, After: 2}, diff.ModifiedRules[0].DisplayOrder)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) TestDiffScenarioIteration_noLiveVersion() {
+	suite.scenario.LiveVersionID = nil
+	suite.expectDraft()
+
+	_, err := suite.makeUsecase().DiffScenarioIteration(suite.ctx, suite.draft.Id, nil)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioIteration", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) TestDiffScenarioIteration_otherScenario() {
+	suite.expectDraft()
+	other := models.ScenarioIteration{Id: "other_iteration_id", ScenarioId: "other_scenario_id"}
+	suite.repository.On("GetScenarioIteration", suite.exec, other.Id).Return(other, nil)
+	suite.enforceSecurity.On("ReadScenarioIteration", other).Return(nil)
+
+	_, err := suite.makeUsecase().DiffScenarioIteration(suite.ctx, suite.draft.Id, &other.Id)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioIterationUsecaseTestSuite) TestDiffScenarioIteration_forbiddenBase() {
+	suite.expectDraft()
+	other := models.ScenarioIteration{Id: "other_iteration_id", ScenarioId: suite.scenario.Id}
+	suite.repository.On("GetScenarioIteration", suite.exec, other.Id).Return(other, nil)
+	suite.enforceSecurity.On("ReadScenarioIteration", other).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().DiffScenarioIteration(suite.ctx, suite.draft.Id, &other.Id)
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.AssertExpectations()
+}
+
+func TestScenarioIterationUsecase(t *testing.T) {
+	suite.Run(t, new(ScenarioIterationUsecaseTestSuite))
+}
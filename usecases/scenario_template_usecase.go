@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/scenarios"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type ScenarioTemplateRepository interface {
+	GetScenarioTemplateById(ctx context.Context, exec repositories.Executor, templateId string) (models.ScenarioTemplate, error)
+	ListScenarioTemplates(ctx context.Context, exec repositories.Executor) ([]models.ScenarioTemplate, error)
+	CreateScenarioTemplate(ctx context.Context, exec repositories.Executor,
+		input models.CreateScenarioTemplateInput, newTemplateId string) error
+	UpdateScenarioTemplate(ctx context.Context, exec repositories.Executor, input models.UpdateScenarioTemplateInput) error
+	DeleteScenarioTemplate(ctx context.Context, exec repositories.Executor, templateId string) error
+}
+
+// ScenarioTemplateUsecase manages the library of scenario templates shared by the organizations, and instantiates them
+// in an organization by importing the template rendered with the values chosen for its placeholders.
+type ScenarioTemplateUsecase struct {
+	enforceSecurity           security.EnforceSecurityScenario
+	enforceSecurityCustomList security.EnforceSecurityCustomList
+	executorFactory           executor_factory.ExecutorFactory
+	transactionFactory        executor_factory.TransactionFactory
+	repository                ScenarioTemplateRepository
+	customListRepository      repositories.CustomListRepository
+	scenarioBundleUsecase     ScenarioBundleUsecase
+	validateScenarioIteration scenarios.ValidateScenarioIteration
+}
+
+func (usecase *ScenarioTemplateUsecase) ListScenarioTemplates(ctx context.Context) ([]models.ScenarioTemplate, error) {
+	if err := usecase.enforceSecurity.ReadScenarioTemplates(); err != nil {
+		return nil, err
+	}
+	return usecase.repository.ListScenarioTemplates(ctx, usecase.executorFactory.NewExecutor())
+}
+
+func (usecase *ScenarioTemplateUsecase) GetScenarioTemplate(ctx context.Context, templateId string) (models.ScenarioTemplate, error) {
+	if err := usecase.enforceSecurity.ReadScenarioTemplates(); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+	return usecase.repository.GetScenarioTemplateById(ctx, usecase.executorFactory.NewExecutor(), templateId)
+}
+
+func (usecase *ScenarioTemplateUsecase) CreateScenarioTemplate(ctx context.Context,
+	input models.CreateScenarioTemplateInput,
+) (models.ScenarioTemplate, error) {
+	if err := usecase.enforceSecurity.ManageScenarioTemplates(); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+	template := models.ScenarioTemplate{
+		Name:         input.Name,
+		Description:  input.Description,
+		Placeholders: input.Placeholders,
+		Bundle:       input.Bundle,
+	}
+	if err := template.Validate(); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScenarioTemplate, error) {
+		templateId := uuid.NewString()
+		err := usecase.repository.CreateScenarioTemplate(ctx, tx, input, templateId)
+		if repositories.IsUniqueViolationError(err) {
+			return models.ScenarioTemplate{}, errors.Wrap(models.ConflictError, "a scenario template with this name already exists")
+		}
+		if err != nil {
+			return models.ScenarioTemplate{}, err
+		}
+		return usecase.repository.GetScenarioTemplateById(ctx, tx, templateId)
+	})
+}
+
+func (usecase *ScenarioTemplateUsecase) UpdateScenarioTemplate(ctx context.Context,
+	input models.UpdateScenarioTemplateInput,
+) (models.ScenarioTemplate, error) {
+	if err := usecase.enforceSecurity.ManageScenarioTemplates(); err != nil {
+		return models.ScenarioTemplate{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScenarioTemplate, error) {
+		template, err := usecase.repository.GetScenarioTemplateById(ctx, tx, input.Id)
+		if err != nil {
+			return models.ScenarioTemplate{}, err
+		}
+		// the placeholders and the bundle depend on each other, the template is validated as a whole
+		if input.Name != nil {
+			template.Name = *input.Name
+		}
+		if input.Description != nil {
+			template.Description = *input.Description
+		}
+		if input.Placeholders != nil {
+			template.Placeholders = *input.Placeholders
+		}
+		if input.Bundle != nil {
+			template.Bundle = *input.Bundle
+		}
+		if err := template.Validate(); err != nil {
+			return models.ScenarioTemplate{}, err
+		}
+
+		err = usecase.repository.UpdateScenarioTemplate(ctx, tx, input)
+		if repositories.IsUniqueViolationError(err) {
+			return models.ScenarioTemplate{}, errors.Wrap(models.ConflictError, "a scenario template with this name already exists")
+		}
+		if err != nil {
+			return models.ScenarioTemplate{}, err
+		}
+		return usecase.repository.GetScenarioTemplateById(ctx, tx, input.Id)
+	})
+}
+
+func (usecase *ScenarioTemplateUsecase) DeleteScenarioTemplate(ctx context.Context, templateId string) error {
+	if err := usecase.enforceSecurity.ManageScenarioTemplates(); err != nil {
+		return err
+	}
+	exec := usecase.executorFactory.NewExecutor()
+	if _, err := usecase.repository.GetScenarioTemplateById(ctx, exec, templateId); err != nil {
+		return err
+	}
+	return usecase.repository.DeleteScenarioTemplate(ctx, exec, templateId)
+}
+
+// InstantiateScenarioTemplate renders the template with the values of its placeholders, and imports it in the
+// organization like a scenario bundle: the import reports the tables and fields missing from the data model of the
+// organization. The formulas are also checked by the scenario validation, whose errors are reported without preventing
+// the creation of the scenario.
+func (usecase *ScenarioTemplateUsecase) InstantiateScenarioTemplate(ctx context.Context,
+	input models.InstantiateScenarioTemplateInput,
+) (models.ScenarioTemplateInstantiation, error) {
+	if err := usecase.enforceSecurity.ReadScenarioTemplates(); err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+	if err := usecase.enforceSecurity.CreateScenario(input.OrganizationId); err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	template, err := usecase.repository.GetScenarioTemplateById(ctx, exec, input.TemplateId)
+	if err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+	customLists, err := usecase.customListsOfPlaceholders(ctx, exec, template, input)
+	if err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+	bundle, err := template.Render(input.Values, customLists)
+	if err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+	if input.ScenarioName != "" {
+		bundle.Scenario.Name = input.ScenarioName
+	}
+
+	validations := make([]models.ScenarioTemplateIterationValidation, len(bundle.Iterations))
+	for i, iteration := range bundle.Iterations {
+		validation := usecase.validateScenarioIteration.Validate(ctx,
+			templateScenarioAndIteration(input.OrganizationId, bundle, iteration))
+		validations[i] = models.ScenarioTemplateIterationValidation{
+			Version: iteration.Version,
+			Errors:  scenarioValidationMessages(validation),
+		}
+	}
+
+	result, err := usecase.scenarioBundleUsecase.ImportScenario(ctx, input.OrganizationId, bundle, input.DryRun)
+	if err != nil {
+		return models.ScenarioTemplateInstantiation{}, err
+	}
+	return models.ScenarioTemplateInstantiation{ScenarioImportResult: result, Validations: validations}, nil
+}
+
+// customListsOfPlaceholders returns the custom lists of the organization chosen for the custom list placeholders
+func (usecase *ScenarioTemplateUsecase) customListsOfPlaceholders(ctx context.Context, exec repositories.Executor,
+	template models.ScenarioTemplate, input models.InstantiateScenarioTemplateInput,
+) (map[string]models.CustomList, error) {
+	customLists := make(map[string]models.CustomList)
+	for _, placeholder := range template.Placeholders {
+		if placeholder.Kind != models.ScenarioTemplatePlaceholderCustomList {
+			continue
+		}
+		customListId, ok := input.Values[placeholder.Name]
+		if !ok {
+			continue
+		}
+		customList, err := usecase.customListRepository.GetCustomListById(ctx, exec, customListId)
+		if errors.Is(err, models.NotFoundError) || (err == nil && customList.OrganizationId != input.OrganizationId) {
+			return nil, errors.Wrapf(models.BadParameterError,
+				"custom list %s of placeholder %s not found in the organization", customListId, placeholder.Name)
+		} else if err != nil {
+			return nil, err
+		}
+		if err := usecase.enforceSecurityCustomList.ReadCustomList(customList); err != nil {
+			return nil, err
+		}
+		customLists[placeholder.Name] = customList
+	}
+	return customLists, nil
+}
+
+// templateScenarioAndIteration is the scenario and the iteration that the instantiation would create, to be validated
+// before their creation. The rendered template has no custom list of its own: its formulas use the lists of the
+// organization.
+func templateScenarioAndIteration(organizationId string, bundle models.ScenarioBundle,
+	iteration models.ScenarioBundleIteration,
+) models.ScenarioAndIteration {
+	rules := make([]models.Rule, len(iteration.Rules))
+	for i, rule := range iteration.Rules {
+		rules[i] = models.Rule{
+			// the validation of the rules is keyed by their id
+			Id:                   strconv.Itoa(i),
+			OrganizationId:       organizationId,
+			DisplayOrder:         rule.DisplayOrder,
+			Name:                 rule.Name,
+			Description:          rule.Description,
+			FormulaAstExpression: rule.FormulaAstExpression,
+			ScoreModifier:        rule.ScoreModifier,
+			RuleGroup:            rule.RuleGroup,
+		}
+	}
+	return models.ScenarioAndIteration{
+		Scenario: models.Scenario{
+			OrganizationId:    organizationId,
+			Name:              bundle.Scenario.Name,
+			Description:       bundle.Scenario.Description,
+			TriggerObjectType: bundle.Scenario.TriggerObjectType,
+		},
+		Iteration: models.ScenarioIteration{
+			OrganizationId:                organizationId,
+			Version:                       iteration.Version,
+			TriggerConditionAstExpression: iteration.TriggerConditionAstExpression,
+			Rules:                         rules,
+			ScoreReviewThreshold:          iteration.ScoreReviewThreshold,
+			ScoreRejectThreshold:          iteration.ScoreRejectThreshold,
+			BatchTriggerSQL:               iteration.BatchTriggerSQL,
+			Schedule:                      iteration.Schedule,
+			ScheduleTimezone:              iteration.ScheduleTimezone,
+			OutputVariables:               iteration.OutputVariables,
+		},
+	}
+}
+
+func scenarioValidationMessages(validation models.ScenarioValidation) []string {
+	err := scenarios.ScenarioValidationToError(validation)
+	if err == nil {
+		return []string{}
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	errs := joined.Unwrap()
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return messages
+}
@@ -0,0 +1,296 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type ScenarioTemplateUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity           *mocks.EnforceSecurity
+	repository                *mocks.ScenarioTemplateRepository
+	bundleRepository          *mocks.ScenarioBundleRepository
+	customListRepository      *mocks.CustomListRepository
+	dataModelRepository       *mocks.DataModelRepository
+	validateScenarioIteration *mocks.ValidateScenarioIteration
+	exec                      *mocks.Executor
+	transaction               *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	customList     models.CustomList
+	dataModel      models.DataModel
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurity)
+	suite.repository = new(mocks.ScenarioTemplateRepository)
+	suite.bundleRepository = new(mocks.ScenarioBundleRepository)
+	suite.customListRepository = new(mocks.CustomListRepository)
+	suite.dataModelRepository = new(mocks.DataModelRepository)
+	suite.validateScenarioIteration = new(mocks.ValidateScenarioIteration)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "3c5f1b5e-2d8e-4f7a-9b1c-6e0d4a2f8b71"
+	suite.customList = models.CustomList{
+		Id:             "blocked_accounts_id",
+		OrganizationId: suite.organizationId,
+		Name:           "blocked accounts",
+	}
+	suite.dataModel = models.DataModel{Tables: map[string]models.Table{
+		"transactions": {
+			Name: "transactions",
+			Fields: map[string]models.Field{
+				"object_id": {Name: "object_id", DataType: models.String},
+				"amount":    {Name: "amount", DataType: models.Float},
+			},
+		},
+	}}
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) makeUsecase() *ScenarioTemplateUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &ScenarioTemplateUsecase{
+		enforceSecurity:           suite.enforceSecurity,
+		enforceSecurityCustomList: suite.enforceSecurity,
+		executorFactory:           executorFactory,
+		transactionFactory:        transactionFactory,
+		repository:                suite.repository,
+		customListRepository:      suite.customListRepository,
+		scenarioBundleUsecase: ScenarioBundleUsecase{
+			enforceSecurity:           suite.enforceSecurity,
+			enforceSecurityCustomList: suite.enforceSecurity,
+			executorFactory:           executorFactory,
+			transactionFactory:        transactionFactory,
+			repository:                suite.bundleRepository,
+			customListRepository:      suite.customListRepository,
+			dataModelRepository:       suite.dataModelRepository,
+		},
+		validateScenarioIteration: suite.validateScenarioIteration,
+	}
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.bundleRepository.AssertExpectations(t)
+	suite.customListRepository.AssertExpectations(t)
+	suite.dataModelRepository.AssertExpectations(t)
+	suite.validateScenarioIteration.AssertExpectations(t)
+}
+
+// template flags the transactions above a threshold whose account is in a list chosen by the organization
+func (suite *ScenarioTemplateUsecaseTestSuite) template() models.ScenarioTemplate {
+	trigger := ast.Node{Function: ast.FUNC_GREATER}.
+		AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("{{amount_field}}"))).
+		AddChild(ast.NewNodeConstant("{{threshold}}"))
+	formula := ast.Node{Function: ast.FUNC_IS_IN_LIST}.
+		AddChild(ast.Node{Function: ast.FUNC_PAYLOAD}.AddChild(ast.NewNodeConstant("object_id"))).
+		AddChild(ast.NewNodeCustomListAccess("{{blocklist}}"))
+	return models.ScenarioTemplate{
+		Id:   "template_id",
+		Name: "large transactions",
+		Placeholders: []models.ScenarioTemplatePlaceholder{
+			{Name: "table", Kind: models.ScenarioTemplatePlaceholderTable, DefaultValue: utils.Ptr("transactions")},
+			{Name: "amount_field", Kind: models.ScenarioTemplatePlaceholderField, DefaultValue: utils.Ptr("amount")},
+			{Name: "threshold", Kind: models.ScenarioTemplatePlaceholderNumber},
+			{Name: "blocklist", Kind: models.ScenarioTemplatePlaceholderCustomList},
+		},
+		Bundle: models.ScenarioBundle{
+			Version:  models.SCENARIO_BUNDLE_VERSION,
+			Scenario: models.ScenarioBundleScenario{Name: "Transactions over {{threshold}}", TriggerObjectType: "{{table}}"},
+			Iterations: []models.ScenarioBundleIteration{{
+				TriggerConditionAstExpression: &trigger,
+				Rules: []models.ScenarioBundleRule{
+					{Name: "blocked account", FormulaAstExpression: &formula, ScoreModifier: 100},
+				},
+			}},
+			DataModel: []models.ScenarioBundleFieldRequirement{
+				{TableName: "{{table}}", FieldName: "{{amount_field}}", DataType: models.Float},
+			},
+		},
+	}
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) input() models.InstantiateScenarioTemplateInput {
+	return models.InstantiateScenarioTemplateInput{
+		TemplateId:     "template_id",
+		OrganizationId: suite.organizationId,
+		Values:         map[string]string{"threshold": "1000", "blocklist": suite.customList.Id},
+	}
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) expectTemplate() {
+	suite.enforceSecurity.On("ReadScenarioTemplates").Return(nil)
+	suite.enforceSecurity.On("CreateScenario", suite.organizationId).Return(nil)
+	suite.repository.On("GetScenarioTemplateById", suite.exec, "template_id").Return(suite.template(), nil)
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) expectCustomList() {
+	suite.customListRepository.On("GetCustomListById", suite.exec, suite.customList.Id).Return(suite.customList, nil)
+	suite.enforceSecurity.On("ReadCustomList", suite.customList).Return(nil)
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) expectImportValidation() {
+	suite.dataModelRepository.On("GetDataModel", mock.Anything, suite.transaction, suite.organizationId, false).
+		Return(suite.dataModel, nil)
+	suite.bundleRepository.On("ListScenariosOfOrganization", suite.transaction, suite.organizationId).
+		Return([]models.Scenario{}, nil)
+	suite.customListRepository.On("AllCustomLists", suite.transaction, suite.organizationId).
+		Return([]models.CustomList{suite.customList}, nil)
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate() {
+	suite.expectTemplate()
+	suite.expectCustomList()
+	suite.expectImportValidation()
+	validation := models.NewScenarioValidation()
+	validation.Decision.Errors = append(validation.Decision.Errors, models.ScenarioValidationError{
+		Error: errors.New("score review threshold is required"),
+		Code:  models.ScoreReviewThresholdRequired,
+	})
+	// the placeholders are replaced before the validation
+	rendered := mock.MatchedBy(func(si models.ScenarioAndIteration) bool {
+		trigger := si.Iteration.TriggerConditionAstExpression
+		return si.Scenario.Name == "renamed scenario" &&
+			si.Scenario.TriggerObjectType == "transactions" &&
+			trigger.Children[0].Children[0].Constant == "amount" &&
+			trigger.Children[1].Constant == 1000.0
+	})
+	suite.validateScenarioIteration.On("Validate", rendered).Return(validation)
+	var scenarioId string
+	suite.bundleRepository.On("CreateScenario", suite.transaction, suite.organizationId, models.CreateScenarioInput{
+		Name:              "renamed scenario",
+		TriggerObjectType: "transactions",
+	}, mock.Anything).Run(func(args mock.Arguments) { scenarioId = args.String(3) }).Return(nil)
+	// the formulas use the list of the organization, which is not copied
+	usesCustomList := mock.MatchedBy(func(input models.CreateScenarioIterationInput) bool {
+		formula := input.Body.Rules[0].FormulaAstExpression
+		listId, err := formula.Children[1].ReadConstantNamedChildString(
+			ast.AttributeFuncCustomListAccess.ArgumentCustomListId)
+		return err == nil && listId == suite.customList.Id && input.ScenarioId == scenarioId
+	})
+	suite.bundleRepository.On("CreateScenarioIterationAndRules", suite.transaction, suite.organizationId,
+		usesCustomList).Return(models.ScenarioIteration{Id: "draft_id"}, nil)
+	created := models.Scenario{Id: "created_id", Name: "renamed scenario"}
+	suite.bundleRepository.On("GetScenarioById", suite.transaction, mock.Anything).Return(created, nil)
+
+	input := suite.input()
+	input.ScenarioName = "renamed scenario"
+	result, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, input)
+	suite.NoError(err)
+	suite.Empty(result.Report.Conflicts)
+	suite.Equal([]models.ScenarioImportCustomList{{
+		Name:     "blocked accounts",
+		SourceId: suite.customList.Id,
+		TargetId: utils.Ptr(suite.customList.Id),
+	}}, result.Report.CustomLists)
+	suite.Equal(&created, result.Scenario)
+	suite.Equal([]models.ScenarioTemplateIterationValidation{
+		{Errors: []string{"score review threshold is required"}},
+	}, result.Validations)
+	suite.customListRepository.AssertNotCalled(suite.T(), "CreateCustomList",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_dryRun() {
+	suite.expectTemplate()
+	suite.expectCustomList()
+	suite.expectImportValidation()
+	suite.validateScenarioIteration.On("Validate", mock.Anything).Return(models.NewScenarioValidation())
+
+	input := suite.input()
+	input.DryRun = true
+	result, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, input)
+	suite.NoError(err)
+	suite.Empty(result.Report.Conflicts)
+	suite.Nil(result.Scenario)
+	suite.Equal([]models.ScenarioTemplateIterationValidation{{Errors: []string{}}}, result.Validations)
+	suite.bundleRepository.AssertNotCalled(suite.T(), "CreateScenario",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.bundleRepository.AssertNotCalled(suite.T(), "CreateScenarioIterationAndRules",
+		mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_missingField() {
+	suite.expectTemplate()
+	suite.expectCustomList()
+	suite.expectImportValidation()
+	suite.validateScenarioIteration.On("Validate", mock.Anything).Return(models.NewScenarioValidation())
+
+	input := suite.input()
+	input.Values["amount_field"] = "total"
+	result, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, input)
+	suite.NoError(err)
+	suite.Len(result.Report.Conflicts, 1)
+	suite.Nil(result.Scenario)
+	suite.bundleRepository.AssertNotCalled(suite.T(), "CreateScenario",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_customListOfAnotherOrganization() {
+	suite.expectTemplate()
+	otherList := suite.customList
+	otherList.OrganizationId = "other_organization_id"
+	suite.customListRepository.On("GetCustomListById", suite.exec, suite.customList.Id).Return(otherList, nil)
+
+	_, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, suite.input())
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.enforceSecurity.AssertNotCalled(suite.T(), "ReadCustomList", mock.Anything)
+	suite.validateScenarioIteration.AssertNotCalled(suite.T(), "Validate", mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_customListNotFound() {
+	suite.expectTemplate()
+	suite.customListRepository.On("GetCustomListById", suite.exec, suite.customList.Id).
+		Return(models.CustomList{}, models.NotFoundError)
+
+	_, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, suite.input())
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_missingCustomList() {
+	suite.expectTemplate()
+
+	input := suite.input()
+	delete(input.Values, "blocklist")
+	_, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, input)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.customListRepository.AssertNotCalled(suite.T(), "GetCustomListById", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *ScenarioTemplateUsecaseTestSuite) TestInstantiateScenarioTemplate_forbidden() {
+	suite.enforceSecurity.On("ReadScenarioTemplates").Return(nil)
+	suite.enforceSecurity.On("CreateScenario", suite.organizationId).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().InstantiateScenarioTemplate(suite.ctx, suite.input())
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "GetScenarioTemplateById", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func TestScenarioTemplateUsecase(t *testing.T) {
+	suite.Run(t, new(ScenarioTemplateUsecaseTestSuite))
+}
@@ -3,6 +3,8 @@ package scenarios
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/cockroachdb/errors"
 
@@ -37,6 +39,51 @@ func ScenarioValidationToError(validation models.ScenarioValidation) error {
 	return errors.Join(errs...)
 }
 
+// ValidateScheduleTimezone checks that the timezone of a scenario iteration schedule is empty (the default timezone
+// of the scheduler is used) or a valid IANA timezone name.
+func ValidateScheduleTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	// "Local" is accepted by time.LoadLocation but depends on the server configuration
+	if timezone == "Local" {
+		return errors.Wrap(models.BadParameterError, "schedule timezone must be an IANA timezone name")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return errors.Wrapf(models.BadParameterError, "invalid schedule timezone %s", timezone)
+	}
+	return nil
+}
+
+const MAX_OUTPUT_VARIABLES = 50
+
+// Output variable names are used as keys in the decisions returned to the client
+var outputVariableNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,63}$`)
+
+// ValidateOutputVariables checks that the output variables of a scenario iteration have unique valid names and a formula
+func ValidateOutputVariables(outputVariables []models.OutputVariable) error {
+	if len(outputVariables) > MAX_OUTPUT_VARIABLES {
+		return errors.Wrapf(models.BadParameterError, "a scenario iteration can have at most %d output variables",
+			MAX_OUTPUT_VARIABLES)
+	}
+	names := make(map[string]bool, len(outputVariables))
+	for _, outputVariable := range outputVariables {
+		if !outputVariableNameRegexp.MatchString(outputVariable.Name) {
+			return errors.Wrapf(models.BadParameterError,
+				"invalid output variable name %q: it must contain only letters, digits and underscores, and not start with a digit",
+				outputVariable.Name)
+		}
+		if names[outputVariable.Name] {
+			return errors.Wrapf(models.BadParameterError, "duplicate output variable name %s", outputVariable.Name)
+		}
+		names[outputVariable.Name] = true
+		if outputVariable.FormulaAstExpression == nil {
+			return errors.Wrapf(models.BadParameterError, "output variable %s has no formula", outputVariable.Name)
+		}
+	}
+	return nil
+}
+
 type ValidateScenarioIteration interface {
 	Validate(ctx context.Context, si models.ScenarioAndIteration) models.ScenarioValidation
 }
@@ -78,6 +125,22 @@ func (validator *ValidateScenarioIterationImpl) Validate(ctx context.Context, si
 		})
 	}
 
+	// validate schedule
+	if err := ValidateScheduleTimezone(iteration.ScheduleTimezone); err != nil {
+		result.Errors = append(result.Errors, models.ScenarioValidationError{
+			Error: err,
+			Code:  models.ScheduleTimezoneInvalid,
+		})
+	}
+
+	// validate output variables
+	if err := ValidateOutputVariables(iteration.OutputVariables); err != nil {
+		result.Errors = append(result.Errors, models.ScenarioValidationError{
+			Error: err,
+			Code:  models.OutputVariableInvalid,
+		})
+	}
+
 	dryRunEnvironment, err := validator.makeDryRunEnvironment(ctx, si)
 	if err != nil {
 		result.Errors = append(result.Errors, *err)
@@ -96,6 +159,20 @@ func (validator *ValidateScenarioIterationImpl) Validate(ctx context.Context, si
 		result.Trigger.TriggerEvaluation, _ = ast_eval.EvaluateAst(ctx, dryRunEnvironment, *trigger)
 	}
 
+	for _, outputVariable := range iteration.OutputVariables {
+		if outputVariable.FormulaAstExpression == nil {
+			continue
+		}
+		evaluation, _ := ast_eval.EvaluateAst(ctx, dryRunEnvironment, *outputVariable.FormulaAstExpression)
+		if evaluationErrors := evaluation.FlattenErrors(); len(evaluationErrors) > 0 {
+			result.Errors = append(result.Errors, models.ScenarioValidationError{
+				Error: errors.Wrapf(errors.Join(evaluationErrors...), "invalid formula of output variable %s",
+					outputVariable.Name),
+				Code: models.OutputVariableInvalid,
+			})
+		}
+	}
+
 	// validate each rule
 	for _, rule := range iteration.Rules {
 		formula := rule.FormulaAstExpression
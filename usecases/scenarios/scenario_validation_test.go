@@ -102,3 +102,29 @@ func TestValidateScenarioIterationImpl_Validate(t *testing.T) {
 	})
 	assert.Empty(t, ScenarioValidationToError(result))
 }
+
+func TestValidateOutputVariables(t *testing.T) {
+	formula := utils.Ptr(ast.Node{Constant: utils.Ptr(1)})
+
+	assert.NoError(t, ValidateOutputVariables(nil))
+	assert.NoError(t, ValidateOutputVariables([]models.OutputVariable{
+		{Name: "transaction_count_24h", FormulaAstExpression: formula},
+		{Name: "RiskCategory", FormulaAstExpression: formula},
+	}))
+
+	invalid := map[string][]models.OutputVariable{
+		"empty name":        {{Name: "", FormulaAstExpression: formula}},
+		"starts with digit": {{Name: "24h_count", FormulaAstExpression: formula}},
+		"invalid character": {{Name: "risk-category", FormulaAstExpression: formula}},
+		"no formula":        {{Name: "risk_category"}},
+		"duplicate name": {
+			{Name: "risk_category", FormulaAstExpression: formula},
+			{Name: "risk_category", FormulaAstExpression: formula},
+		},
+	}
+	for name, outputVariables := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateOutputVariables(outputVariables), models.BadParameterError)
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/pure_utils"
 	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
 	"github.com/checkmarble/marble-backend/usecases/security"
+	"github.com/checkmarble/marble-backend/utils"
 )
 
 type ExportDecisions interface {
@@ -29,6 +32,8 @@ type ScheduledExecutionUsecaseRepository interface {
 		input models.CreateScheduledExecutionInput, id string) error
 	UpdateScheduledExecution(ctx context.Context, exec repositories.Executor,
 		input models.UpdateScheduledExecutionInput) error
+
+	EnqueueJob(ctx context.Context, exec repositories.Executor, input models.JobEnqueueInput) error
 }
 
 type ScheduledExecutionUsecase struct {
@@ -143,18 +148,29 @@ func (usecase *ScheduledExecutionUsecase) CreateScheduledExecution(ctx context.C
 		return err
 	}
 	if len(pendingExecutions) > 0 {
-		return fmt.Errorf("a pending execution already exists for this scenario %w", models.BadParameterError)
+		return fmt.Errorf("a pending execution already exists for this scenario: %w", models.BadParameterError)
 	}
 
 	id := pure_utils.NewPrimaryKey(input.OrganizationId)
-	return usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
-		return usecase.repository.CreateScheduledExecution(ctx, tx, models.CreateScheduledExecutionInput{
+	err = usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		err := usecase.repository.CreateScheduledExecution(ctx, tx, models.CreateScheduledExecutionInput{
 			OrganizationId:      input.OrganizationId,
 			ScenarioId:          scenario.Id,
 			ScenarioIterationId: input.ScenarioIterationId,
 			Manual:              true,
 		}, id)
+		if err != nil {
+			return err
+		}
+		return usecase.repository.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+			Kind:     models.JobKindScheduledExecution,
+			EntityId: id,
+		})
 	})
+	if repositories.IsUniqueViolationError(err) {
+		return fmt.Errorf("a pending execution already exists for this scenario: %w", models.BadParameterError)
+	}
+	return err
 }
 
 func (usecase *ScheduledExecutionUsecase) UpdateScheduledExecution(ctx context.Context, input models.UpdateScheduledExecutionInput) error {
@@ -169,3 +185,36 @@ func (usecase *ScheduledExecutionUsecase) UpdateScheduledExecution(ctx context.C
 		return usecase.repository.UpdateScheduledExecution(ctx, tx, input)
 	})
 }
+
+// CancelScheduledExecution stops a pending or processing execution. A processing execution stops after the batch of
+// objects it is currently evaluating, the decisions already created are kept.
+func (usecase *ScheduledExecutionUsecase) CancelScheduledExecution(ctx context.Context, id string) (models.ScheduledExecution, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScheduledExecution, error) {
+		execution, err := usecase.repository.GetScheduledExecution(ctx, tx, id)
+		if err != nil {
+			return models.ScheduledExecution{}, err
+		}
+		if err := usecase.enforceSecurity.CreateScheduledExecution(execution.OrganizationId); err != nil {
+			return models.ScheduledExecution{}, err
+		}
+		if execution.Status.IsFinished() {
+			return models.ScheduledExecution{}, fmt.Errorf("scheduled execution is already %s: %w",
+				execution.Status.String(), models.BadParameterError)
+		}
+
+		err = usecase.repository.UpdateScheduledExecution(ctx, tx, models.UpdateScheduledExecutionInput{
+			Id:     id,
+			Status: utils.Ptr(models.ScheduledExecutionCancelled),
+		})
+		if errors.Is(err, repositories.ErrScheduledExecutionFinished) {
+			return models.ScheduledExecution{}, fmt.Errorf("scheduled execution is already finished: %w",
+				models.BadParameterError)
+		}
+		if err != nil {
+			return models.ScheduledExecution{}, err
+		}
+		return usecase.repository.GetScheduledExecution(ctx, tx, id)
+	})
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/checkmarble/marble-backend/mocks"
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/utils"
 )
 
 type ScheduledExecutionsTestSuite struct {
@@ -114,6 +116,79 @@ func (suite *ScheduledExecutionsTestSuite) TestListScheduledExecutions_security(
 	suite.AssertExpectations()
 }
 
+func (suite *ScheduledExecutionsTestSuite) TestCancelScheduledExecution() {
+	ctx := context.Background()
+	execution := models.ScheduledExecution{
+		Id:             "some ScheduledExecution id",
+		OrganizationId: "some org id",
+		Status:         models.ScheduledExecutionProcessing,
+	}
+	cancelledExecution := execution
+	cancelledExecution.Status = models.ScheduledExecutionCancelled
+
+	suite.transactionFactory.On("Transaction", ctx, mock.Anything).Return(nil)
+	suite.repository.On("GetScheduledExecution", suite.transaction, execution.Id).Return(execution, nil).Once()
+	suite.enforceSecurity.On("CreateScheduledExecution", execution.OrganizationId).Return(nil)
+	suite.repository.On("UpdateScheduledExecution", suite.transaction, models.UpdateScheduledExecutionInput{
+		Id:     execution.Id,
+		Status: utils.Ptr(models.ScheduledExecutionCancelled),
+	}).Return(nil)
+	suite.repository.On("GetScheduledExecution", suite.transaction, execution.Id).Return(cancelledExecution, nil).Once()
+
+	result, err := suite.makeUsecase().CancelScheduledExecution(ctx, execution.Id)
+
+	t := suite.T()
+	assert.NoError(t, err)
+	assert.Equal(t, cancelledExecution, result)
+
+	suite.AssertExpectations()
+}
+
+func (suite *ScheduledExecutionsTestSuite) TestCancelScheduledExecution_already_finished() {
+	ctx := context.Background()
+	execution := models.ScheduledExecution{
+		Id:             "some ScheduledExecution id",
+		OrganizationId: "some org id",
+		Status:         models.ScheduledExecutionSuccess,
+	}
+
+	suite.transactionFactory.On("Transaction", ctx, mock.Anything).Return(nil)
+	suite.repository.On("GetScheduledExecution", suite.transaction, execution.Id).Return(execution, nil)
+	suite.enforceSecurity.On("CreateScheduledExecution", execution.OrganizationId).Return(nil)
+
+	_, err := suite.makeUsecase().CancelScheduledExecution(ctx, execution.Id)
+
+	t := suite.T()
+	assert.ErrorIs(t, err, models.BadParameterError)
+
+	suite.AssertExpectations()
+}
+
+func (suite *ScheduledExecutionsTestSuite) TestCancelScheduledExecution_finished_concurrently() {
+	ctx := context.Background()
+	execution := models.ScheduledExecution{
+		Id:             "some ScheduledExecution id",
+		OrganizationId: "some org id",
+		Status:         models.ScheduledExecutionProcessing,
+	}
+
+	suite.transactionFactory.On("Transaction", ctx, mock.Anything).Return(nil)
+	suite.repository.On("GetScheduledExecution", suite.transaction, execution.Id).Return(execution, nil)
+	suite.enforceSecurity.On("CreateScheduledExecution", execution.OrganizationId).Return(nil)
+	// the execution succeeded after it was read
+	suite.repository.On("UpdateScheduledExecution", suite.transaction, models.UpdateScheduledExecutionInput{
+		Id:     execution.Id,
+		Status: utils.Ptr(models.ScheduledExecutionCancelled),
+	}).Return(repositories.ErrScheduledExecutionFinished)
+
+	_, err := suite.makeUsecase().CancelScheduledExecution(ctx, execution.Id)
+
+	t := suite.T()
+	assert.ErrorIs(t, err, models.BadParameterError)
+
+	suite.AssertExpectations()
+}
+
 func TestScheduledExecutions(t *testing.T) {
 	suite.Run(t, new(ScheduledExecutionsTestSuite))
 }
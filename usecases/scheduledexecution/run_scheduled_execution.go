@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 
 	"github.com/adhocore/gronx"
@@ -19,9 +18,19 @@ import (
 	"github.com/checkmarble/marble-backend/usecases/ast_eval"
 	"github.com/checkmarble/marble-backend/usecases/evaluate_scenario"
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/jobqueue"
 	"github.com/checkmarble/marble-backend/utils"
 )
 
+const (
+	// number of trigger objects evaluated and committed together during a scheduled execution
+	scheduledExecutionBatchSize = 1000
+	// number of scheduled executions processed at the same time by one scheduler instance
+	maxConcurrentScheduledExecutions = 10
+)
+
+var errScheduledExecutionCancelled = errors.New("scheduled execution was cancelled")
+
 type decisionWorkflowsUsecase interface {
 	AutomaticDecisionToCase(
 		ctx context.Context,
@@ -52,6 +61,18 @@ type RunScheduledExecutionRepository interface {
 	UpdateScheduledExecution(ctx context.Context, exec repositories.Executor,
 		updateScheduledEx models.UpdateScheduledExecutionInput) error
 	GetScheduledExecution(ctx context.Context, exec repositories.Executor, id string) (models.ScheduledExecution, error)
+
+	EnqueueJob(ctx context.Context, exec repositories.Executor, input models.JobEnqueueInput) error
+}
+
+type jobQueueWorker interface {
+	ProcessJobs(
+		ctx context.Context,
+		kind models.JobKind,
+		limit int,
+		concurrency int,
+		handler jobqueue.JobHandler,
+	) error
 }
 
 type snoozesForDecisionReader interface {
@@ -76,6 +97,8 @@ type RunScheduledExecution struct {
 	decisionWorkflows              decisionWorkflowsUsecase
 	webhookEventsSender            webhookEventsUsecase
 	snoozesReader                  snoozesForDecisionReader
+	jobQueueWorker                 jobQueueWorker
+	defaultScheduleTimezone        string
 }
 
 func NewRunScheduledExecution(
@@ -91,6 +114,8 @@ func NewRunScheduledExecution(
 	decisionWorkflows decisionWorkflowsUsecase,
 	webhookEventsSender webhookEventsUsecase,
 	snoozesReader snoozesForDecisionReader,
+	jobQueueWorker jobQueueWorker,
+	defaultScheduleTimezone string,
 ) *RunScheduledExecution {
 	return &RunScheduledExecution{
 		repository:                     repository,
@@ -105,6 +130,8 @@ func NewRunScheduledExecution(
 		decisionWorkflows:              decisionWorkflows,
 		webhookEventsSender:            webhookEventsSender,
 		snoozesReader:                  snoozesReader,
+		jobQueueWorker:                 jobQueueWorker,
+		defaultScheduleTimezone:        defaultScheduleTimezone,
 	}
 }
 
@@ -148,95 +175,125 @@ func (usecase *RunScheduledExecution) ScheduleScenarioIfDue(ctx context.Context,
 
 	logger.DebugContext(ctx, fmt.Sprintf("Scenario iteration %s is due", publishedVersion.Id))
 	scheduledExecutionId := pure_utils.NewPrimaryKey(organizationId)
-	return usecase.repository.CreateScheduledExecution(ctx, exec, models.CreateScheduledExecutionInput{
-		OrganizationId:      organizationId,
-		ScenarioId:          scenarioId,
-		ScenarioIterationId: publishedVersion.Id,
-		Manual:              false,
-	}, scheduledExecutionId)
+	err = usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		err := usecase.repository.CreateScheduledExecution(ctx, tx, models.CreateScheduledExecutionInput{
+			OrganizationId:      organizationId,
+			ScenarioId:          scenarioId,
+			ScenarioIterationId: publishedVersion.Id,
+			Manual:              false,
+		}, scheduledExecutionId)
+		if err != nil {
+			return err
+		}
+		return usecase.repository.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+			Kind:     models.JobKindScheduledExecution,
+			EntityId: scheduledExecutionId,
+		})
+	})
+	if repositories.IsUniqueViolationError(err) {
+		// another scheduler instance created the execution concurrently
+		logger.DebugContext(ctx, fmt.Sprintf("scenario %s has already a pending or processing scheduled execution", scenarioId))
+		return nil
+	}
+	return err
 }
 
+// ExecuteAllScheduledScenarios claims scheduled execution jobs from the job queue and executes them. Executions
+// interrupted in another process (whose lease on the job expired) are resumed from their last checkpoint.
 func (usecase *RunScheduledExecution) ExecuteAllScheduledScenarios(ctx context.Context) error {
 	logger := utils.LoggerFromContext(ctx)
 
-	pendingScheduledExecutions, err := usecase.repository.ListScheduledExecutions(ctx,
-		usecase.executorFactory.NewExecutor(), models.ListScheduledExecutionsFilters{
-			Status: []models.ScheduledExecutionStatus{models.ScheduledExecutionPending},
-		})
-	if err != nil {
-		return fmt.Errorf("error while listing pending ScheduledExecutions: %w", err)
-	}
-
-	logger.InfoContext(ctx, fmt.Sprintf("Found %d pending scheduled executions", len(pendingScheduledExecutions)))
-
-	var waitGroup sync.WaitGroup
-	executionErrorChan := make(chan error, len(pendingScheduledExecutions))
-
-	startScheduledExecution := func(scheduledExecution models.ScheduledExecution) {
-		defer waitGroup.Done()
-		if err := usecase.ExecuteScheduledScenario(ctx, logger, scheduledExecution); err != nil {
-			executionErrorChan <- err
-		}
-	}
-
-	for _, pendingExecution := range pendingScheduledExecutions {
-		waitGroup.Add(1)
-		go startScheduledExecution(pendingExecution)
-	}
-
-	waitGroup.Wait()
-	close(executionErrorChan)
-
-	executionErr := <-executionErrorChan
-	return executionErr
+	return usecase.jobQueueWorker.ProcessJobs(
+		ctx,
+		models.JobKindScheduledExecution,
+		maxConcurrentScheduledExecutions,
+		maxConcurrentScheduledExecutions,
+		func(ctx context.Context, job models.Job) error {
+			scheduledExecution, err := usecase.repository.GetScheduledExecution(
+				ctx, usecase.executorFactory.NewExecutor(), job.EntityId)
+			if err != nil {
+				return err
+			}
+			if scheduledExecution.Status.IsFinished() {
+				logger.InfoContext(ctx, fmt.Sprintf("Execution %s is already %s",
+					scheduledExecution.Id, scheduledExecution.Status.String()))
+				return nil
+			}
+			return usecase.ExecuteScheduledScenario(ctx, logger, scheduledExecution, job.IsLastAttempt())
+		},
+	)
 }
 
+// ExecuteScheduledScenario evaluates the scenario on all the objects of its trigger table, by batches. Every batch is
+// committed together with a checkpoint on the scheduled execution, so that an interrupted execution can be resumed
+// where it stopped. The execution stops after the current batch if it is cancelled.
+// On error, the execution is only marked as failed if lastAttempt is true: otherwise its job is retried and resumes it.
 func (usecase *RunScheduledExecution) ExecuteScheduledScenario(
 	ctx context.Context,
 	logger *slog.Logger,
 	scheduledExecution models.ScheduledExecution,
+	lastAttempt bool,
 ) error {
 	exec := usecase.executorFactory.NewExecutor()
-	logger.InfoContext(ctx, fmt.Sprintf("Start execution %s", scheduledExecution.Id))
+	if scheduledExecution.LastProcessedObjectId != nil {
+		logger.InfoContext(ctx, fmt.Sprintf("Resume execution %s after object %s (%d objects already evaluated)",
+			scheduledExecution.Id, *scheduledExecution.LastProcessedObjectId, scheduledExecution.NumberOfEvaluatedObjects))
+	} else {
+		logger.InfoContext(ctx, fmt.Sprintf("Start execution %s", scheduledExecution.Id))
+	}
 
-	if err := usecase.repository.UpdateScheduledExecution(ctx, exec, models.UpdateScheduledExecutionInput{
+	// the execution may have been cancelled since it was read: the status update fails if it is already finished
+	err := usecase.repository.UpdateScheduledExecution(ctx, exec, models.UpdateScheduledExecutionInput{
 		Id:     scheduledExecution.Id,
 		Status: utils.PtrTo(models.ScheduledExecutionProcessing, nil),
-	}); err != nil {
+	})
+	if errors.Is(err, repositories.ErrScheduledExecutionFinished) {
+		logger.InfoContext(ctx, fmt.Sprintf("Execution %s was cancelled", scheduledExecution.Id))
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
-	scheduledExecution, err := executor_factory.TransactionReturnValue(
-		ctx,
-		usecase.transactionFactory,
-		func(tx repositories.Executor) (models.ScheduledExecution, error) {
-			numberOfCreatedDecisions, err := usecase.executeScheduledScenario(
-				ctx,
-				scheduledExecution.Id,
-				scheduledExecution.Scenario,
-			)
-			if err != nil {
-				return scheduledExecution, err
-			}
-			err = usecase.repository.UpdateScheduledExecution(
-				ctx,
-				tx,
-				models.UpdateScheduledExecutionInput{
-					Id:                       scheduledExecution.Id,
-					Status:                   utils.PtrTo(models.ScheduledExecutionSuccess, nil),
-					NumberOfCreatedDecisions: &numberOfCreatedDecisions,
-				},
-			)
-			if err != nil {
-				return scheduledExecution, err
-			}
-			return usecase.repository.GetScheduledExecution(ctx, tx, scheduledExecution.Id)
-		})
+	err = usecase.executeScheduledScenario(ctx, scheduledExecution)
+	if err == nil {
+		scheduledExecution, err = executor_factory.TransactionReturnValue(
+			ctx,
+			usecase.transactionFactory,
+			func(tx repositories.Executor) (models.ScheduledExecution, error) {
+				err := usecase.repository.UpdateScheduledExecution(ctx, tx, models.UpdateScheduledExecutionInput{
+					Id:     scheduledExecution.Id,
+					Status: utils.PtrTo(models.ScheduledExecutionSuccess, nil),
+				})
+				if errors.Is(err, repositories.ErrScheduledExecutionFinished) {
+					return models.ScheduledExecution{}, errScheduledExecutionCancelled
+				}
+				if err != nil {
+					return models.ScheduledExecution{}, err
+				}
+				return usecase.repository.GetScheduledExecution(ctx, tx, scheduledExecution.Id)
+			})
+	}
+	if errors.Is(err, errScheduledExecutionCancelled) {
+		logger.InfoContext(ctx, fmt.Sprintf("Execution %s was cancelled", scheduledExecution.Id))
+		return nil
+	}
+	if err != nil && (ctx.Err() != nil || !lastAttempt) {
+		// the context is cancelled if the lease on the job was lost: another worker may already resume the execution
+		logger.WarnContext(ctx, fmt.Sprintf("Execution %s interrupted, it will be resumed: %s",
+			scheduledExecution.Id, err.Error()))
+		return err
+	}
 	if err != nil {
 		err2 := usecase.repository.UpdateScheduledExecution(ctx, exec, models.UpdateScheduledExecutionInput{
 			Id:     scheduledExecution.Id,
 			Status: utils.PtrTo(models.ScheduledExecutionFailure, nil),
 		})
+		if errors.Is(err2, repositories.ErrScheduledExecutionFinished) {
+			// cancelled while it was failing: the cancelled status is kept
+			logger.InfoContext(ctx, fmt.Sprintf("Execution %s was cancelled", scheduledExecution.Id))
+			return err
+		}
 		if err2 != nil {
 			return errors.Join(err, err2)
 		}
@@ -279,11 +336,15 @@ func (usecase *RunScheduledExecution) scenarioIsDue(
 		return false, err
 	}
 
-	tz, err := time.LoadLocation("Europe/Paris")
+	timezone := usecase.defaultScheduleTimezone
+	if publishedVersion.Body.ScheduleTimezone != "" {
+		timezone = publishedVersion.Body.ScheduleTimezone
+	}
+	tz, err := time.LoadLocation(timezone)
 	if err != nil {
-		return false, errors.Wrap(err, "error loading timezone")
+		return false, errors.Wrapf(err, "error loading timezone %s", timezone)
 	}
-	return executionIsDueNow(publishedVersion.Body.Schedule, previousExecutions, publications, tz)
+	return executionIsDueNow(publishedVersion.Body.Schedule, previousExecutions, publications, tz, time.Now())
 }
 
 func executionIsDueNow(
@@ -291,65 +352,146 @@ func executionIsDueNow(
 	previousExecutions []models.ScheduledExecution,
 	publications []models.ScenarioPublication,
 	tz *time.Location,
+	now time.Time,
 ) (bool, error) {
 	if tz == nil {
 		return false, errors.New("Nil timezone passed in executionIsDueNow")
 	}
 	var referenceTime time.Time
 	if len(previousExecutions) > 0 {
-		referenceTime = previousExecutions[0].StartedAt.In(tz)
+		referenceTime = previousExecutions[0].StartedAt
 	} else {
 		// if there is no previous execution, consider the last iteration publication time to be the last execution time
-		referenceTime = publications[0].CreatedAt.In(tz)
+		referenceTime = publications[0].CreatedAt
 	}
 
-	nextTick, err := gronx.NextTickAfter(schedule, referenceTime, false)
+	nextTick, err := nextScheduleTick(schedule, referenceTime, tz)
 	if err != nil {
 		return true, err
 	}
-	if nextTick.After(time.Now()) {
+	if nextTick.After(now) {
 		return false, nil
 	}
 	return true, nil
 }
 
-func (usecase *RunScheduledExecution) executeScheduledScenario(ctx context.Context,
-	scheduledExecutionId string, scenario models.Scenario,
-) (int, error) {
+// nextScheduleTick returns the first time after referenceTime matching the cron schedule, evaluated on the wall clock
+// of the timezone. Around DST transitions, a wall clock time that does not exist (spring forward) is shifted forward
+// by the length of the transition instead of being skipped, and a wall clock time that happens twice (fall back)
+// only matches once.
+func nextScheduleTick(schedule string, referenceTime time.Time, tz *time.Location) (time.Time, error) {
+	// compute the next tick on a clock without DST, that has the same wall clock time as the timezone
+	wallClock := referenceTime.In(tz)
+	wallClockAsUtc := time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(),
+		wallClock.Hour(), wallClock.Minute(), wallClock.Second(), wallClock.Nanosecond(), time.UTC)
+
+	nextTick, err := gronx.NextTickAfter(schedule, wallClockAsUtc, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(nextTick.Year(), nextTick.Month(), nextTick.Day(),
+		nextTick.Hour(), nextTick.Minute(), nextTick.Second(), nextTick.Nanosecond(), tz), nil
+}
+
+func (usecase *RunScheduledExecution) executeScheduledScenario(
+	ctx context.Context,
+	scheduledExecution models.ScheduledExecution,
+) error {
+	scenario := scheduledExecution.Scenario
 	exec := usecase.executorFactory.NewExecutor()
 	dataModel, err := usecase.dataModelRepository.GetDataModel(ctx, exec, scenario.OrganizationId, false)
 	if err != nil {
-		return 0, err
+		return err
 	}
 	tables := dataModel.Tables
 	table, ok := tables[scenario.TriggerObjectType]
 	if !ok {
-		return 0, fmt.Errorf("trigger object type %s not found in data model: %w",
+		return fmt.Errorf("trigger object type %s not found in data model: %w",
 			scenario.TriggerObjectType, models.NotFoundError)
 	}
 
 	pivotsMeta, err := usecase.dataModelRepository.ListPivots(ctx, exec, scenario.OrganizationId, nil)
 	if err != nil {
-		return 0, err
+		return err
 	}
 	pivot := models.FindPivot(pivotsMeta, scenario.TriggerObjectType, dataModel)
 
-	// list objects to score
-	numberOfCreatedDecisions := 0
-	var objects []models.ClientObject
 	db, err := usecase.executorFactory.NewClientDbExecutor(ctx, scenario.OrganizationId)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	objects, err = usecase.ingestedDataReadRepository.ListAllObjectsFromTable(ctx, db, table)
-	if err != nil {
-		return 0, err
+
+	progress := scheduledExecutionProgress{
+		lastProcessedObjectId:    scheduledExecution.LastProcessedObjectId,
+		numberOfEvaluatedObjects: scheduledExecution.NumberOfEvaluatedObjects,
+		numberOfCreatedDecisions: scheduledExecution.NumberOfCreatedDecisions,
+	}
+	for {
+		// list the next batch of objects to score
+		objects, err := usecase.ingestedDataReadRepository.ListObjectsFromTableBatch(
+			ctx, db, table, progress.lastProcessedObjectId, scheduledExecutionBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+
+		progress, err = usecase.executeScheduledScenarioBatch(
+			ctx,
+			scheduledExecution.Id,
+			scenario,
+			dataModel,
+			pivot,
+			objects,
+			progress,
+		)
+		if err != nil {
+			return err
+		}
 	}
+}
+
+type scheduledExecutionProgress struct {
+	lastProcessedObjectId    *string
+	numberOfEvaluatedObjects int
+	numberOfCreatedDecisions int
+}
 
+// executeScheduledScenarioBatch evaluates the scenario on a batch of objects and stores the resulting decisions
+// in a single transaction, together with the new checkpoint of the scheduled execution.
+func (usecase *RunScheduledExecution) executeScheduledScenarioBatch(
+	ctx context.Context,
+	scheduledExecutionId string,
+	scenario models.Scenario,
+	dataModel models.DataModel,
+	pivot *models.Pivot,
+	objects []models.ClientObject,
+	progress scheduledExecutionProgress,
+) (scheduledExecutionProgress, error) {
 	tracer := utils.OpenTelemetryTracerFromContext(ctx)
 
+	lastObjectId, ok := objects[len(objects)-1].Data["object_id"].(string)
+	if !ok {
+		return progress, fmt.Errorf("object_id of the last object of the batch is not a string")
+	}
+	newProgress := scheduledExecutionProgress{
+		lastProcessedObjectId:    &lastObjectId,
+		numberOfEvaluatedObjects: progress.numberOfEvaluatedObjects + len(objects),
+		numberOfCreatedDecisions: progress.numberOfCreatedDecisions,
+	}
+
 	sendWebhookEventId := make([]string, 0)
-	err = usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+	err := usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		execution, err := usecase.repository.GetScheduledExecution(ctx, tx, scheduledExecutionId)
+		if err != nil {
+			return err
+		}
+		if execution.Status == models.ScheduledExecutionCancelled {
+			return errScheduledExecutionCancelled
+		}
+
 		executionScenario := func(ctx context.Context, object models.ClientObject, i int) error {
 			ctx, span := tracer.Start(
 				ctx,
@@ -406,7 +548,7 @@ func (usecase *RunScheduledExecution) executeScheduledScenario(ctx context.Conte
 			err = usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
 				Id:             webhookEventId,
 				OrganizationId: decision.OrganizationId,
-				EventContent:   models.NewWebhookEventDecisionCreated(decision.DecisionId),
+				EventContent:   models.NewWebhookEventDecisionCreated(decision.DecisionId, decision.OutputValues),
 			})
 			if err != nil {
 				return err
@@ -423,27 +565,33 @@ func (usecase *RunScheduledExecution) executeScheduledScenario(ctx context.Conte
 				sendWebhookEventId = append(sendWebhookEventId, caseWebhookEventId)
 			}
 
-			numberOfCreatedDecisions += 1
+			newProgress.numberOfCreatedDecisions += 1
 			return nil
 		}
 
 		// execute scenario for each object
 		for i, object := range objects {
-			if err := executionScenario(ctx, object, i); err != nil {
+			if err := executionScenario(ctx, object, progress.numberOfEvaluatedObjects+i); err != nil {
 				return err
 			}
 		}
-		return nil
+
+		return usecase.repository.UpdateScheduledExecution(ctx, tx, models.UpdateScheduledExecutionInput{
+			Id:                       scheduledExecutionId,
+			NumberOfCreatedDecisions: &newProgress.numberOfCreatedDecisions,
+			NumberOfEvaluatedObjects: &newProgress.numberOfEvaluatedObjects,
+			LastProcessedObjectId:    newProgress.lastProcessedObjectId,
+		})
 	})
 	if err != nil {
-		return numberOfCreatedDecisions, err
+		return progress, err
 	}
 
 	for _, webhookEventId := range sendWebhookEventId {
 		usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
 	}
 
-	return numberOfCreatedDecisions, nil
+	return newProgress, nil
 }
 
 func (usecase *RunScheduledExecution) getPublishedScenarioIteration(
@@ -0,0 +1,276 @@
+package scheduledexecution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/models/ast"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/ast_eval"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func TestNextScheduleTick(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	t.Run("regular day", func(t *testing.T) {
+		reference := time.Date(2024, 6, 10, 12, 0, 0, 0, paris)
+		nextTick, err := nextScheduleTick("30 2 * * *", reference, paris)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 6, 11, 2, 30, 0, 0, paris), nextTick)
+	})
+
+	t.Run("spring forward: nonexistent wall clock time is shifted, not skipped", func(t *testing.T) {
+		reference := time.Date(2024, 3, 30, 12, 0, 0, 0, paris)
+		nextTick, err := nextScheduleTick("30 2 * * *", reference, paris)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC), nextTick.UTC())
+	})
+
+	t.Run("fall back: repeated wall clock time runs only once", func(t *testing.T) {
+		reference := time.Date(2024, 10, 26, 12, 0, 0, 0, paris)
+		firstTick, err := nextScheduleTick("30 2 * * *", reference, paris)
+		assert.NoError(t, err)
+		assert.Equal(t, 27, firstTick.In(paris).Day())
+		assert.Equal(t, 2, firstTick.In(paris).Hour())
+		assert.Equal(t, 30, firstTick.In(paris).Minute())
+
+		secondTick, err := nextScheduleTick("30 2 * * *", firstTick, paris)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 10, 28, 1, 30, 0, 0, time.UTC), secondTick.UTC())
+	})
+}
+
+func TestExecutionIsDueNow(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	previousExecutions := []models.ScheduledExecution{
+		{StartedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, newYork)},
+	}
+
+	isDue, err := executionIsDueNow("0 9 * * *", previousExecutions, nil, newYork,
+		time.Date(2024, 6, 11, 8, 59, 0, 0, newYork))
+	assert.NoError(t, err)
+	assert.False(t, isDue)
+
+	isDue, err = executionIsDueNow("0 9 * * *", previousExecutions, nil, newYork,
+		time.Date(2024, 6, 11, 9, 0, 0, 0, newYork))
+	assert.NoError(t, err)
+	assert.True(t, isDue)
+
+	_, err = executionIsDueNow("0 9 * * *", previousExecutions, nil, nil, time.Now())
+	assert.Error(t, err)
+}
+
+func scheduledExecutionTestUsecase() (*RunScheduledExecution, *mocks.ScheduledExecutionUsecaseRepository,
+	*mocks.IngestedDataReadRepository, *mocks.Executor,
+) {
+	exec := new(mocks.Executor)
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(exec)
+	executorFactory.On("NewClientDbExecutor", mock.Anything, "org_id").Return(exec, nil)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: exec}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	dataModelRepository := new(mocks.DataModelRepository)
+	dataModelRepository.On("GetDataModel", mock.Anything, exec, "org_id", false).Return(models.DataModel{
+		Tables: map[string]models.Table{"transactions": {Name: "transactions"}},
+	}, nil)
+	dataModelRepository.On("ListPivots", mock.Anything, exec, "org_id", (*string)(nil)).
+		Return([]models.PivotMetadata{}, nil)
+
+	repository := new(mocks.ScheduledExecutionUsecaseRepository)
+	// the trigger condition never matches: the objects are evaluated without creating decisions
+	repository.On("GetScenarioIteration", exec, "iteration_id").Return(models.ScenarioIteration{
+		Id:                            "iteration_id",
+		ScenarioId:                    "scenario_id",
+		ScoreReviewThreshold:          utils.Ptr(10),
+		ScoreRejectThreshold:          utils.Ptr(20),
+		TriggerConditionAstExpression: utils.Ptr(ast.NewNodeConstant(false)),
+	}, nil)
+
+	ingestedDataReadRepository := new(mocks.IngestedDataReadRepository)
+	evaluateAstExpression := ast_eval.EvaluateAstExpression{
+		AstEvaluationEnvironmentFactory: func(ast_eval.EvaluationEnvironmentFactoryParams) ast_eval.AstEvaluationEnvironment {
+			return ast_eval.NewAstEvaluationEnvironment()
+		},
+	}
+	usecase := NewRunScheduledExecution(repository, executorFactory, ExportScheduleExecution{}, nil,
+		dataModelRepository, ingestedDataReadRepository, evaluateAstExpression, nil,
+		transactionFactory, nil, nil, nil, nil, "UTC")
+	return usecase, repository, ingestedDataReadRepository, exec
+}
+
+func scheduledExecutionTest() models.ScheduledExecution {
+	return models.ScheduledExecution{
+		Id:     "execution_id",
+		Status: models.ScheduledExecutionProcessing,
+		Scenario: models.Scenario{
+			Id:                "scenario_id",
+			OrganizationId:    "org_id",
+			TriggerObjectType: "transactions",
+			LiveVersionID:     utils.Ptr("iteration_id"),
+		},
+	}
+}
+
+func transactionObjects(ids ...string) []models.ClientObject {
+	objects := make([]models.ClientObject, len(ids))
+	for i, id := range ids {
+		objects[i] = models.ClientObject{TableName: "transactions", Data: map[string]any{"object_id": id}}
+	}
+	return objects
+}
+
+func TestExecuteScheduledScenario_resumesFromCheckpoint(t *testing.T) {
+	usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+	execution := scheduledExecutionTest()
+	execution.LastProcessedObjectId = utils.Ptr("b")
+	execution.NumberOfEvaluatedObjects = 2
+	table := models.Table{Name: "transactions"}
+
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, table,
+		utils.Ptr("b"), scheduledExecutionBatchSize).Return(transactionObjects("c", "d"), nil)
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, table,
+		utils.Ptr("d"), scheduledExecutionBatchSize).Return(transactionObjects("e"), nil)
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, table,
+		utils.Ptr("e"), scheduledExecutionBatchSize).Return([]models.ClientObject{}, nil)
+	repository.On("GetScheduledExecution", exec, "execution_id").Return(execution, nil)
+	// every batch is committed with its checkpoint, the counters continue from the previous run
+	repository.On("UpdateScheduledExecution", exec, models.UpdateScheduledExecutionInput{
+		Id:                       "execution_id",
+		NumberOfCreatedDecisions: utils.Ptr(0),
+		NumberOfEvaluatedObjects: utils.Ptr(4),
+		LastProcessedObjectId:    utils.Ptr("d"),
+	}).Return(nil).Once()
+	repository.On("UpdateScheduledExecution", exec, models.UpdateScheduledExecutionInput{
+		Id:                       "execution_id",
+		NumberOfCreatedDecisions: utils.Ptr(0),
+		NumberOfEvaluatedObjects: utils.Ptr(5),
+		LastProcessedObjectId:    utils.Ptr("e"),
+	}).Return(nil).Once()
+
+	err := usecase.executeScheduledScenario(context.Background(), execution)
+	assert.NoError(t, err)
+	repository.AssertExpectations(t)
+	ingestedDataReadRepository.AssertExpectations(t)
+}
+
+func TestExecuteScheduledScenario_stopsWhenCancelled(t *testing.T) {
+	usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+	execution := scheduledExecutionTest()
+	cancelled := execution
+	cancelled.Status = models.ScheduledExecutionCancelled
+
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, mock.Anything,
+		(*string)(nil), scheduledExecutionBatchSize).Return(transactionObjects("a", "b"), nil)
+	repository.On("GetScheduledExecution", exec, "execution_id").Return(cancelled, nil)
+
+	err := usecase.executeScheduledScenario(context.Background(), execution)
+	assert.ErrorIs(t, err, errScheduledExecutionCancelled)
+	repository.AssertNotCalled(t, "UpdateScheduledExecution", mock.Anything, mock.Anything)
+}
+
+func TestExecuteScheduledScenario_cancelledBeforeStart(t *testing.T) {
+	usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+	execution := scheduledExecutionTest()
+	execution.Status = models.ScheduledExecutionPending
+	// the cancelled status is not overwritten
+	processing := models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionProcessing),
+	}
+	repository.On("UpdateScheduledExecution", exec, processing).Return(repositories.ErrScheduledExecutionFinished)
+
+	err := usecase.ExecuteScheduledScenario(context.Background(),
+		utils.LoggerFromContext(context.Background()), execution, false)
+	assert.NoError(t, err)
+	repository.AssertCalled(t, "UpdateScheduledExecution", exec, processing)
+	ingestedDataReadRepository.AssertNotCalled(t, "ListObjectsFromTableBatch",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExecuteScheduledScenario_cancelledBeforeSuccess(t *testing.T) {
+	usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+	execution := scheduledExecutionTest()
+
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, mock.Anything,
+		(*string)(nil), scheduledExecutionBatchSize).Return([]models.ClientObject{}, nil)
+	repository.On("UpdateScheduledExecution", exec, models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionProcessing),
+	}).Return(nil)
+	// the execution is cancelled after its last batch, before it is marked as successful
+	success := models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionSuccess),
+	}
+	repository.On("UpdateScheduledExecution", exec, success).Return(repositories.ErrScheduledExecutionFinished)
+
+	err := usecase.ExecuteScheduledScenario(context.Background(),
+		utils.LoggerFromContext(context.Background()), execution, true)
+	assert.NoError(t, err)
+	repository.AssertCalled(t, "UpdateScheduledExecution", exec, success)
+	// the cancelled execution is neither read back for its export nor marked as failed
+	repository.AssertNotCalled(t, "GetScheduledExecution", mock.Anything, mock.Anything)
+	repository.AssertNotCalled(t, "UpdateScheduledExecution", exec, models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionFailure),
+	})
+}
+
+func TestExecuteScheduledScenario_cancelledBeforeFailure(t *testing.T) {
+	usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+	execution := scheduledExecutionTest()
+
+	ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, mock.Anything,
+		(*string)(nil), scheduledExecutionBatchSize).Return([]models.ClientObject{}, assert.AnError)
+	repository.On("UpdateScheduledExecution", exec, models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionProcessing),
+	}).Return(nil)
+	failure := models.UpdateScheduledExecutionInput{
+		Id:     "execution_id",
+		Status: utils.Ptr(models.ScheduledExecutionFailure),
+	}
+	repository.On("UpdateScheduledExecution", exec, failure).Return(repositories.ErrScheduledExecutionFinished)
+
+	err := usecase.ExecuteScheduledScenario(context.Background(),
+		utils.LoggerFromContext(context.Background()), execution, true)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, repositories.ErrScheduledExecutionFinished, "the cancelled status is kept")
+	repository.AssertCalled(t, "UpdateScheduledExecution", exec, failure)
+}
+
+func TestExecuteScheduledScenario_failureOnLastAttempt(t *testing.T) {
+	for _, lastAttempt := range []bool{false, true} {
+		usecase, repository, ingestedDataReadRepository, exec := scheduledExecutionTestUsecase()
+		execution := scheduledExecutionTest()
+		failure := models.UpdateScheduledExecutionInput{
+			Id:     "execution_id",
+			Status: utils.Ptr(models.ScheduledExecutionFailure),
+		}
+		repository.On("GetScheduledExecution", exec, "execution_id").Return(execution, nil)
+		repository.On("UpdateScheduledExecution", exec, mock.Anything).Return(nil)
+		ingestedDataReadRepository.On("ListObjectsFromTableBatch", mock.Anything, exec, mock.Anything,
+			(*string)(nil), scheduledExecutionBatchSize).Return([]models.ClientObject{}, assert.AnError)
+
+		err := usecase.ExecuteScheduledScenario(context.Background(),
+			utils.LoggerFromContext(context.Background()), execution, lastAttempt)
+		assert.ErrorIs(t, err, assert.AnError, "the error is returned for the job to be retried")
+		if lastAttempt {
+			repository.AssertCalled(t, "UpdateScheduledExecution", exec, failure)
+		} else {
+			// the job is retried: the execution is resumed, not failed
+			repository.AssertNotCalled(t, "UpdateScheduledExecution", exec, failure)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+// ScimConfigUsecase lets the admins of an organization create the tokens of their identity provider, and map the
+// groups it pushes to roles and inboxes
+type ScimConfigUsecase struct {
+	enforceSecurity    security.EnforceSecurityOrganization
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         ScimRepository
+	userRepository     repositories.UserRepository
+	hasLicense         bool
+}
+
+func (usecase *ScimConfigUsecase) ListScimTokens(ctx context.Context, organizationId string) ([]models.ScimToken, error) {
+	if err := usecase.enforceSecurity.ManageScim(organizationId); err != nil {
+		return nil, err
+	}
+	return usecase.repository.ListScimTokens(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+}
+
+func (usecase *ScimConfigUsecase) CreateScimToken(ctx context.Context, input models.CreateScimTokenInput) (models.CreatedScimToken, error) {
+	if err := usecase.enforceSecurity.ManageScim(input.OrganizationId); err != nil {
+		return models.CreatedScimToken{}, err
+	}
+	if !usecase.hasLicense {
+		return models.CreatedScimToken{}, errScimNotInLicense
+	}
+
+	token := generateAPiKey()
+	hash := sha256.Sum256([]byte(token))
+	scimToken := models.ScimToken{
+		Id:             uuid.NewString(),
+		OrganizationId: input.OrganizationId,
+		Description:    input.Description,
+		Prefix:         token[:3],
+		Hash:           hash[:],
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	if err := usecase.repository.CreateScimToken(ctx, exec, scimToken); err != nil {
+		return models.CreatedScimToken{}, err
+	}
+	created, err := usecase.repository.GetScimTokenById(ctx, exec, scimToken.Id)
+	if err != nil {
+		return models.CreatedScimToken{}, err
+	}
+	return models.CreatedScimToken{ScimToken: created, Token: token}, nil
+}
+
+func (usecase *ScimConfigUsecase) DeleteScimToken(ctx context.Context, organizationId, tokenId string) error {
+	if err := usecase.enforceSecurity.ManageScim(organizationId); err != nil {
+		return err
+	}
+	exec := usecase.executorFactory.NewExecutor()
+	token, err := usecase.repository.GetScimTokenById(ctx, exec, tokenId)
+	if err != nil {
+		return err
+	}
+	if token.OrganizationId != organizationId {
+		return errors.Wrap(models.NotFoundError, "SCIM token not found in this organization")
+	}
+	return usecase.repository.SoftDeleteScimToken(ctx, exec, token.Id)
+}
+
+func (usecase *ScimConfigUsecase) ListScimGroups(ctx context.Context, organizationId string) ([]models.ScimGroup, error) {
+	if err := usecase.enforceSecurity.ManageScim(organizationId); err != nil {
+		return nil, err
+	}
+	exec := usecase.executorFactory.NewExecutor()
+	groups, err := usecase.repository.ListScimGroups(ctx, exec, organizationId, "")
+	if err != nil {
+		return nil, err
+	}
+	return withScimGroupMembers(ctx, exec, usecase.repository, groups)
+}
+
+// UpdateScimGroupMapping sets the role and the inbox given by a group, and updates its members accordingly
+func (usecase *ScimConfigUsecase) UpdateScimGroupMapping(ctx context.Context, organizationId string,
+	input models.UpdateScimGroupMappingInput,
+) (models.ScimGroup, error) {
+	if err := usecase.enforceSecurity.ManageScim(organizationId); err != nil {
+		return models.ScimGroup{}, err
+	}
+	if err := input.Validate(); err != nil {
+		return models.ScimGroup{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimGroup, error) {
+		group, err := usecase.repository.GetScimGroup(ctx, tx, organizationId, input.GroupId)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		if input.InboxId != nil {
+			inbox, err := usecase.repository.GetInboxById(ctx, tx, *input.InboxId)
+			if err != nil {
+				return models.ScimGroup{}, err
+			}
+			if inbox.OrganizationId != organizationId {
+				return models.ScimGroup{}, errors.Wrap(models.NotFoundError, "inbox not found in this organization")
+			}
+		}
+		if err := usecase.repository.UpdateScimGroupMapping(ctx, tx, input); err != nil {
+			return models.ScimGroup{}, err
+		}
+
+		groups, err := withScimGroupMembers(ctx, tx, usecase.repository, []models.ScimGroup{group})
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		for _, member := range groups[0].Members {
+			err := syncScimGroupsOfUser(ctx, tx, usecase.repository, usecase.userRepository,
+				organizationId, member.UserId, group.InboxId)
+			if err != nil {
+				return models.ScimGroup{}, err
+			}
+		}
+
+		updated, err := usecase.repository.GetScimGroup(ctx, tx, organizationId, input.GroupId)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		groups, err = withScimGroupMembers(ctx, tx, usecase.repository, []models.ScimGroup{updated})
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		return groups[0], nil
+	})
+}
@@ -0,0 +1,551 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+)
+
+type ScimRepository interface {
+	GetScimTokenById(ctx context.Context, exec repositories.Executor, tokenId string) (models.ScimToken, error)
+	GetScimTokenByHash(ctx context.Context, exec repositories.Executor, hash []byte) (models.ScimToken, error)
+	ListScimTokens(ctx context.Context, exec repositories.Executor, organizationId string) ([]models.ScimToken, error)
+	CreateScimToken(ctx context.Context, exec repositories.Executor, token models.ScimToken) error
+	SoftDeleteScimToken(ctx context.Context, exec repositories.Executor, tokenId string) error
+
+	ListScimUsers(ctx context.Context, exec repositories.Executor, organizationId, email string) ([]models.User, error)
+	GetScimUser(ctx context.Context, exec repositories.Executor, organizationId string, userId models.UserId) (models.User, error)
+	ReactivateUser(ctx context.Context, exec repositories.Executor, userId models.UserId) error
+
+	ListScimGroups(ctx context.Context, exec repositories.Executor, organizationId, displayName string) ([]models.ScimGroup, error)
+	GetScimGroup(ctx context.Context, exec repositories.Executor, organizationId, groupId string) (models.ScimGroup, error)
+	ListScimGroupsOfUser(ctx context.Context, exec repositories.Executor, userId models.UserId) ([]models.ScimGroup, error)
+	ListScimGroupMembers(ctx context.Context, exec repositories.Executor, groupIds []string) ([]models.ScimGroupMember, error)
+	CreateScimGroup(ctx context.Context, exec repositories.Executor, organizationId string,
+		input models.ScimGroupInput, newGroupId string) error
+	UpdateScimGroup(ctx context.Context, exec repositories.Executor, groupId string, input models.ScimGroupInput) error
+	UpdateScimGroupMapping(ctx context.Context, exec repositories.Executor, input models.UpdateScimGroupMappingInput) error
+	SetScimGroupMembers(ctx context.Context, exec repositories.Executor, groupId string, userIds []string) error
+	DeleteScimGroup(ctx context.Context, exec repositories.Executor, groupId string) error
+
+	GetInboxById(ctx context.Context, exec repositories.Executor, inboxId string) (models.Inbox, error)
+	ListInboxUsers(ctx context.Context, exec repositories.Executor, filters models.InboxUserFilterInput) ([]models.InboxUser, error)
+	CreateInboxUser(ctx context.Context, exec repositories.Executor, input models.CreateInboxUserInput, newInboxUserId string) error
+	DeleteInboxUser(ctx context.Context, exec repositories.Executor, inboxUserId string) error
+}
+
+var errScimNotInLicense = errors.Wrap(models.ForbiddenError, "SCIM provisioning is not included in your license")
+
+// ScimUsecase serves the SCIM endpoints called by the identity provider of an organization, authenticated by a SCIM
+// token instead of Marble credentials
+type ScimUsecase struct {
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         ScimRepository
+	userRepository     repositories.UserRepository
+	hasLicense         bool
+}
+
+func (usecase *ScimUsecase) OrganizationIdOfToken(ctx context.Context, token string) (string, error) {
+	if !usecase.hasLicense {
+		return "", errScimNotInLicense
+	}
+	hash := sha256.Sum256([]byte(token))
+	scimToken, err := usecase.repository.GetScimTokenByHash(ctx, usecase.executorFactory.NewExecutor(), hash[:])
+	if errors.Is(err, models.NotFoundError) {
+		return "", errors.Wrap(models.UnAuthorizedError, "invalid SCIM token")
+	}
+	if err != nil {
+		return "", err
+	}
+	return scimToken.OrganizationId, nil
+}
+
+// scimPage returns the page of items starting at the 1-based startIndex
+func scimPage[T any](items []T, startIndex, count int) []T {
+	start := min(max(startIndex, 1)-1, len(items))
+	end := min(start+min(max(count, 0), models.SCIM_MAX_RESULTS), len(items))
+	return items[start:end]
+}
+
+func uniqueSortedIds(ids []string) []string {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func normalizeScimEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (usecase *ScimUsecase) scimUser(ctx context.Context, exec repositories.Executor, user models.User) (models.ScimUser, error) {
+	groups, err := usecase.repository.ListScimGroupsOfUser(ctx, exec, user.UserId)
+	if err != nil {
+		return models.ScimUser{}, err
+	}
+	scimUser := models.ScimUser{User: user, Groups: make([]models.ScimGroupRef, 0, len(groups))}
+	for _, group := range groups {
+		scimUser.Groups = append(scimUser.Groups, models.ScimGroupRef{Id: group.Id, DisplayName: group.DisplayName})
+	}
+	return scimUser, nil
+}
+
+func (usecase *ScimUsecase) ListUsers(ctx context.Context, organizationId, filter string,
+	startIndex, count int,
+) ([]models.ScimUser, int, error) {
+	email := ""
+	if filter != "" {
+		attribute, value, err := models.ParseScimEqFilter(filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !strings.EqualFold(attribute, "userName") {
+			return nil, 0, errors.Wrapf(models.BadParameterError, "users cannot be filtered by %s", attribute)
+		}
+		email = normalizeScimEmail(value)
+		if email == "" {
+			return []models.ScimUser{}, 0, nil
+		}
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	users, err := usecase.repository.ListScimUsers(ctx, exec, organizationId, email)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := scimPage(users, startIndex, count)
+	scimUsers := make([]models.ScimUser, 0, len(page))
+	for _, user := range page {
+		scimUser, err := usecase.scimUser(ctx, exec, user)
+		if err != nil {
+			return nil, 0, err
+		}
+		scimUsers = append(scimUsers, scimUser)
+	}
+	return scimUsers, len(users), nil
+}
+
+func (usecase *ScimUsecase) GetUser(ctx context.Context, organizationId, userId string) (models.ScimUser, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	user, err := usecase.repository.GetScimUser(ctx, exec, organizationId, models.UserId(userId))
+	if err != nil {
+		return models.ScimUser{}, err
+	}
+	return usecase.scimUser(ctx, exec, user)
+}
+
+func (usecase *ScimUsecase) checkEmailIsFree(ctx context.Context, exec repositories.Executor, email string) error {
+	existing, err := usecase.userRepository.UserByEmail(ctx, exec, email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.Wrapf(models.ConflictError, "a user with the email %s already exists", email)
+	}
+	return nil
+}
+
+func (usecase *ScimUsecase) CreateUser(ctx context.Context, organizationId string, input models.ScimUserInput) (models.ScimUser, error) {
+	input.Email = normalizeScimEmail(input.Email)
+	if err := input.Validate(); err != nil {
+		return models.ScimUser{}, err
+	}
+	role := input.Role
+	if role == models.NO_ROLE {
+		role = models.SCIM_DEFAULT_ROLE
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimUser, error) {
+		if err := usecase.checkEmailIsFree(ctx, tx, input.Email); err != nil {
+			return models.ScimUser{}, err
+		}
+		userId, err := usecase.userRepository.CreateUser(ctx, tx, models.CreateUser{
+			Email:          input.Email,
+			Role:           role,
+			OrganizationId: organizationId,
+			FirstName:      input.FirstName,
+			LastName:       input.LastName,
+		})
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+		if !input.Active {
+			if err := usecase.userRepository.DeleteUser(ctx, tx, userId); err != nil {
+				return models.ScimUser{}, err
+			}
+		}
+
+		user, err := usecase.repository.GetScimUser(ctx, tx, organizationId, userId)
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+		return usecase.scimUser(ctx, tx, user)
+	})
+}
+
+func (usecase *ScimUsecase) updateUser(ctx context.Context, tx repositories.Executor, user models.User,
+	input models.ScimUserInput,
+) (models.ScimUser, error) {
+	input.Email = normalizeScimEmail(input.Email)
+	if err := input.Validate(); err != nil {
+		return models.ScimUser{}, err
+	}
+	if input.Email != user.Email {
+		if err := usecase.checkEmailIsFree(ctx, tx, input.Email); err != nil {
+			return models.ScimUser{}, err
+		}
+	}
+
+	err := usecase.userRepository.UpdateUser(ctx, tx, models.UpdateUser{
+		UserId:    user.UserId,
+		Email:     input.Email,
+		Role:      input.Role,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+	})
+	if err != nil {
+		return models.ScimUser{}, err
+	}
+
+	switch {
+	case input.Active && user.DeletedAt != nil:
+		if err := usecase.repository.ReactivateUser(ctx, tx, user.UserId); err != nil {
+			return models.ScimUser{}, err
+		}
+		err := syncScimGroupsOfUser(ctx, tx, usecase.repository, usecase.userRepository, user.OrganizationId, user.UserId)
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+	case !input.Active && user.DeletedAt == nil:
+		if err := usecase.userRepository.DeleteUser(ctx, tx, user.UserId); err != nil {
+			return models.ScimUser{}, err
+		}
+	}
+
+	updated, err := usecase.repository.GetScimUser(ctx, tx, user.OrganizationId, user.UserId)
+	if err != nil {
+		return models.ScimUser{}, err
+	}
+	return usecase.scimUser(ctx, tx, updated)
+}
+
+func (usecase *ScimUsecase) ReplaceUser(ctx context.Context, organizationId, userId string,
+	input models.ScimUserInput,
+) (models.ScimUser, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimUser, error) {
+		user, err := usecase.repository.GetScimUser(ctx, tx, organizationId, models.UserId(userId))
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+		return usecase.updateUser(ctx, tx, user, input)
+	})
+}
+
+func (usecase *ScimUsecase) PatchUser(ctx context.Context, organizationId, userId string,
+	operations []models.ScimPatchOperation,
+) (models.ScimUser, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimUser, error) {
+		user, err := usecase.repository.GetScimUser(ctx, tx, organizationId, models.UserId(userId))
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+		input, err := models.NewScimUserInput(user).ApplyPatch(operations)
+		if err != nil {
+			return models.ScimUser{}, err
+		}
+		return usecase.updateUser(ctx, tx, user, input)
+	})
+}
+
+// DeleteUser deactivates the user: users are only soft deleted in Marble, as their past actions reference them
+func (usecase *ScimUsecase) DeleteUser(ctx context.Context, organizationId, userId string) error {
+	return usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		user, err := usecase.repository.GetScimUser(ctx, tx, organizationId, models.UserId(userId))
+		if err != nil {
+			return err
+		}
+		if user.DeletedAt != nil {
+			return nil
+		}
+		return usecase.userRepository.DeleteUser(ctx, tx, user.UserId)
+	})
+}
+
+func withScimGroupMembers(ctx context.Context, exec repositories.Executor, repository ScimRepository,
+	groups []models.ScimGroup,
+) ([]models.ScimGroup, error) {
+	groupIds := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIds = append(groupIds, group.Id)
+	}
+	members, err := repository.ListScimGroupMembers(ctx, exec, groupIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Members = []models.ScimGroupMember{}
+		for _, member := range members {
+			if member.GroupId == groups[i].Id {
+				groups[i].Members = append(groups[i].Members, member)
+			}
+		}
+	}
+	return groups, nil
+}
+
+func (usecase *ScimUsecase) ListGroups(ctx context.Context, organizationId, filter string,
+	startIndex, count int,
+) ([]models.ScimGroup, int, error) {
+	displayName := ""
+	if filter != "" {
+		attribute, value, err := models.ParseScimEqFilter(filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !strings.EqualFold(attribute, "displayName") {
+			return nil, 0, errors.Wrapf(models.BadParameterError, "groups cannot be filtered by %s", attribute)
+		}
+		if value == "" {
+			return []models.ScimGroup{}, 0, nil
+		}
+		displayName = value
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	groups, err := usecase.repository.ListScimGroups(ctx, exec, organizationId, displayName)
+	if err != nil {
+		return nil, 0, err
+	}
+	page, err := withScimGroupMembers(ctx, exec, usecase.repository, scimPage(groups, startIndex, count))
+	if err != nil {
+		return nil, 0, err
+	}
+	return page, len(groups), nil
+}
+
+func (usecase *ScimUsecase) getGroup(ctx context.Context, exec repositories.Executor, organizationId, groupId string) (models.ScimGroup, error) {
+	group, err := usecase.repository.GetScimGroup(ctx, exec, organizationId, groupId)
+	if err != nil {
+		return models.ScimGroup{}, err
+	}
+	groups, err := withScimGroupMembers(ctx, exec, usecase.repository, []models.ScimGroup{group})
+	if err != nil {
+		return models.ScimGroup{}, err
+	}
+	return groups[0], nil
+}
+
+func (usecase *ScimUsecase) GetGroup(ctx context.Context, organizationId, groupId string) (models.ScimGroup, error) {
+	return usecase.getGroup(ctx, usecase.executorFactory.NewExecutor(), organizationId, groupId)
+}
+
+// saveGroup writes the group and its members, then updates the roles and inboxes of the users who joined or left it
+func (usecase *ScimUsecase) saveGroup(ctx context.Context, tx repositories.Executor, organizationId string,
+	group *models.ScimGroup, input models.ScimGroupInput,
+) (models.ScimGroup, error) {
+	if err := input.Validate(); err != nil {
+		return models.ScimGroup{}, err
+	}
+	if group == nil || input.DisplayName != group.DisplayName {
+		sameName, err := usecase.repository.ListScimGroups(ctx, tx, organizationId, input.DisplayName)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		if len(sameName) > 0 {
+			return models.ScimGroup{}, errors.Wrapf(models.ConflictError, "a group named %s already exists", input.DisplayName)
+		}
+	}
+
+	users, err := usecase.repository.ListScimUsers(ctx, tx, organizationId, "")
+	if err != nil {
+		return models.ScimGroup{}, err
+	}
+	input.MemberIds = uniqueSortedIds(input.MemberIds)
+	for _, memberId := range input.MemberIds {
+		if !slices.ContainsFunc(users, func(user models.User) bool { return string(user.UserId) == memberId }) {
+			return models.ScimGroup{}, errors.Wrapf(models.BadParameterError, "unknown group member %s", memberId)
+		}
+	}
+
+	groupId := uuid.NewString()
+	affectedUserIds := slices.Clone(input.MemberIds)
+	if group == nil {
+		err = usecase.repository.CreateScimGroup(ctx, tx, organizationId, input, groupId)
+	} else {
+		groupId = group.Id
+		for _, member := range group.Members {
+			affectedUserIds = append(affectedUserIds, string(member.UserId))
+		}
+		err = usecase.repository.UpdateScimGroup(ctx, tx, groupId, input)
+	}
+	if err != nil {
+		return models.ScimGroup{}, err
+	}
+	if err := usecase.repository.SetScimGroupMembers(ctx, tx, groupId, input.MemberIds); err != nil {
+		return models.ScimGroup{}, err
+	}
+
+	for _, userId := range uniqueSortedIds(affectedUserIds) {
+		err := syncScimGroupsOfUser(ctx, tx, usecase.repository, usecase.userRepository, organizationId, models.UserId(userId))
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+	}
+	return usecase.getGroup(ctx, tx, organizationId, groupId)
+}
+
+func (usecase *ScimUsecase) CreateGroup(ctx context.Context, organizationId string, input models.ScimGroupInput) (models.ScimGroup, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimGroup, error) {
+		return usecase.saveGroup(ctx, tx, organizationId, nil, input)
+	})
+}
+
+func (usecase *ScimUsecase) ReplaceGroup(ctx context.Context, organizationId, groupId string,
+	input models.ScimGroupInput,
+) (models.ScimGroup, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimGroup, error) {
+		group, err := usecase.getGroup(ctx, tx, organizationId, groupId)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		return usecase.saveGroup(ctx, tx, organizationId, &group, input)
+	})
+}
+
+func (usecase *ScimUsecase) PatchGroup(ctx context.Context, organizationId, groupId string,
+	operations []models.ScimPatchOperation,
+) (models.ScimGroup, error) {
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.ScimGroup, error) {
+		group, err := usecase.getGroup(ctx, tx, organizationId, groupId)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		input, err := models.NewScimGroupInput(group).ApplyPatch(operations)
+		if err != nil {
+			return models.ScimGroup{}, err
+		}
+		return usecase.saveGroup(ctx, tx, organizationId, &group, input)
+	})
+}
+
+func (usecase *ScimUsecase) DeleteGroup(ctx context.Context, organizationId, groupId string) error {
+	return usecase.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		group, err := usecase.getGroup(ctx, tx, organizationId, groupId)
+		if err != nil {
+			return err
+		}
+		if err := usecase.repository.DeleteScimGroup(ctx, tx, group.Id); err != nil {
+			return err
+		}
+		for _, member := range group.Members {
+			err := syncScimGroupsOfUser(ctx, tx, usecase.repository, usecase.userRepository,
+				organizationId, member.UserId, group.InboxId)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// syncScimGroupsOfUser gives an active user the most privileged role of their SCIM groups, and makes them a member
+// of the inboxes of their groups. The user keeps their role if no group gives a role, and only the memberships of the
+// inboxes managed by SCIM groups are removed. The inboxes of deleted or remapped groups are passed as
+// formerlyManagedInboxIds.
+func syncScimGroupsOfUser(
+	ctx context.Context,
+	tx repositories.Executor,
+	repository ScimRepository,
+	userRepository repositories.UserRepository,
+	organizationId string,
+	userId models.UserId,
+	formerlyManagedInboxIds ...*string,
+) error {
+	user, err := repository.GetScimUser(ctx, tx, organizationId, userId)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt != nil {
+		return nil
+	}
+
+	groups, err := repository.ListScimGroupsOfUser(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+	if role := models.ScimGroupsRole(groups); role != models.NO_ROLE && role != user.Role {
+		if err := userRepository.UpdateUser(ctx, tx, models.UpdateUser{UserId: userId, Role: role}); err != nil {
+			return err
+		}
+	}
+
+	organizationGroups, err := repository.ListScimGroups(ctx, tx, organizationId, "")
+	if err != nil {
+		return err
+	}
+	managedInboxIds := map[string]bool{}
+	for _, inboxId := range formerlyManagedInboxIds {
+		if inboxId != nil {
+			managedInboxIds[*inboxId] = true
+		}
+	}
+	for _, group := range organizationGroups {
+		if group.InboxId != nil {
+			managedInboxIds[*group.InboxId] = true
+		}
+	}
+	missingInboxIds := map[string]bool{}
+	for _, group := range groups {
+		if group.InboxId != nil {
+			missingInboxIds[*group.InboxId] = true
+		}
+	}
+
+	inboxUsers, err := repository.ListInboxUsers(ctx, tx, models.InboxUserFilterInput{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, inboxUser := range inboxUsers {
+		if missingInboxIds[inboxUser.InboxId] {
+			delete(missingInboxIds, inboxUser.InboxId)
+			continue
+		}
+		if managedInboxIds[inboxUser.InboxId] {
+			if err := repository.DeleteInboxUser(ctx, tx, inboxUser.Id); err != nil {
+				return err
+			}
+		}
+	}
+	for inboxId := range missingInboxIds {
+		err := repository.CreateInboxUser(ctx, tx, models.CreateInboxUserInput{
+			InboxId: inboxId,
+			UserId:  string(userId),
+			Role:    models.InboxUserRoleMember,
+		}, uuid.NewString())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
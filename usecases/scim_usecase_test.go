@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func TestScimPage(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, []int{1, 2}, scimPage(items, 1, 2))
+	assert.Equal(t, []int{4, 5}, scimPage(items, 4, 10))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, scimPage(items, 0, 10))
+	assert.Empty(t, scimPage(items, 6, 10))
+	assert.Empty(t, scimPage(items, 1, 0))
+}
+
+type ScimUsecaseTestSuite struct {
+	suite.Suite
+	repository     *mocks.ScimRepository
+	userRepository *mocks.UserRepository
+	transaction    *mocks.Executor
+
+	ctx            context.Context
+	organizationId string
+	analyst        models.User
+	viewer         models.User
+	group          models.ScimGroup
+}
+
+func (suite *ScimUsecaseTestSuite) SetupTest() {
+	suite.repository = new(mocks.ScimRepository)
+	suite.userRepository = new(mocks.UserRepository)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.organizationId = "organization_id"
+	suite.analyst = models.User{UserId: "analyst_id", Role: models.BUILDER, OrganizationId: suite.organizationId}
+	suite.viewer = models.User{UserId: "viewer_id", Role: models.VIEWER, OrganizationId: suite.organizationId}
+	suite.group = models.ScimGroup{
+		Id:             "group_id",
+		OrganizationId: suite.organizationId,
+		DisplayName:    "Analysts",
+		Role:           models.PUBLISHER,
+		InboxId:        utils.Ptr("group_inbox_id"),
+		Members: []models.ScimGroupMember{
+			{GroupId: "group_id", UserId: suite.analyst.UserId},
+		},
+	}
+}
+
+func (suite *ScimUsecaseTestSuite) makeUsecase() *ScimUsecase {
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &ScimUsecase{
+		transactionFactory: transactionFactory,
+		repository:         suite.repository,
+		userRepository:     suite.userRepository,
+		hasLicense:         true,
+	}
+}
+
+func (suite *ScimUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.repository.AssertExpectations(t)
+	suite.userRepository.AssertExpectations(t)
+}
+
+// expectSync expects the synchronization of a user who is a member of the given groups and of no inbox
+func (suite *ScimUsecaseTestSuite) expectSync(user models.User, groups []models.ScimGroup,
+	organizationGroups []models.ScimGroup,
+) {
+	suite.repository.On("GetScimUser", suite.transaction, suite.organizationId, user.UserId).Return(user, nil).Once()
+	suite.repository.On("ListScimGroupsOfUser", suite.transaction, user.UserId).Return(groups, nil).Once()
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, "").
+		Return(organizationGroups, nil).Once()
+	suite.repository.On("ListInboxUsers", suite.transaction, models.InboxUserFilterInput{UserId: user.UserId}).
+		Return([]models.InboxUser{}, nil).Once()
+}
+
+func (suite *ScimUsecaseTestSuite) TestSyncScimGroupsOfUser() {
+	groups := []models.ScimGroup{
+		{Id: "viewers", Role: models.VIEWER, InboxId: utils.Ptr("inbox_a")},
+		{Id: "publishers", Role: models.PUBLISHER},
+	}
+	organizationGroups := append([]models.ScimGroup{
+		{Id: "others", Role: models.ADMIN, InboxId: utils.Ptr("inbox_b")},
+	}, groups...)
+	suite.repository.On("GetScimUser", suite.transaction, suite.organizationId, suite.viewer.UserId).
+		Return(suite.viewer, nil)
+	suite.repository.On("ListScimGroupsOfUser", suite.transaction, suite.viewer.UserId).Return(groups, nil)
+	// the user gets the most privileged role of their groups
+	suite.userRepository.On("UpdateUser", suite.transaction, models.UpdateUser{
+		UserId: suite.viewer.UserId,
+		Role:   models.PUBLISHER,
+	}).Return(nil)
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, "").Return(organizationGroups, nil)
+	suite.repository.On("ListInboxUsers", suite.transaction, models.InboxUserFilterInput{UserId: suite.viewer.UserId}).
+		Return([]models.InboxUser{
+			{Id: "member_b", InboxId: "inbox_b"},
+			{Id: "member_c", InboxId: "inbox_c"},
+			{Id: "member_d", InboxId: "inbox_d"},
+		}, nil)
+	// the memberships of the inboxes managed by other groups, or formerly managed by a group, are removed
+	suite.repository.On("DeleteInboxUser", suite.transaction, "member_b").Return(nil)
+	suite.repository.On("DeleteInboxUser", suite.transaction, "member_c").Return(nil)
+	// the user joins the inbox of their group
+	suite.repository.On("CreateInboxUser", suite.transaction, models.CreateInboxUserInput{
+		InboxId: "inbox_a",
+		UserId:  string(suite.viewer.UserId),
+		Role:    models.InboxUserRoleMember,
+	}).Return(nil)
+
+	err := syncScimGroupsOfUser(suite.ctx, suite.transaction, suite.repository, suite.userRepository,
+		suite.organizationId, suite.viewer.UserId, utils.Ptr("inbox_c"), nil)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+	// the memberships of the inboxes not managed by SCIM are kept
+	suite.repository.AssertNotCalled(suite.T(), "DeleteInboxUser", suite.transaction, "member_d")
+}
+
+func (suite *ScimUsecaseTestSuite) TestSyncScimGroupsOfUser_without_role() {
+	// the groups give no role, the user keeps theirs
+	suite.expectSync(suite.analyst, []models.ScimGroup{{Id: "group", Role: models.NO_ROLE}}, nil)
+
+	err := syncScimGroupsOfUser(suite.ctx, suite.transaction, suite.repository, suite.userRepository,
+		suite.organizationId, suite.analyst.UserId)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+	suite.userRepository.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUsecaseTestSuite) TestSyncScimGroupsOfUser_deleted_user() {
+	deleted := suite.viewer
+	deleted.DeletedAt = utils.Ptr(time.Now())
+	suite.repository.On("GetScimUser", suite.transaction, suite.organizationId, deleted.UserId).Return(deleted, nil)
+
+	err := syncScimGroupsOfUser(suite.ctx, suite.transaction, suite.repository, suite.userRepository,
+		suite.organizationId, deleted.UserId)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func (suite *ScimUsecaseTestSuite) TestCreateGroup() {
+	input := models.ScimGroupInput{
+		DisplayName: "Viewers",
+		MemberIds:   []string{string(suite.viewer.UserId), string(suite.viewer.UserId)},
+	}
+	created := models.ScimGroup{Id: "new_group_id", DisplayName: input.DisplayName}
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, input.DisplayName).
+		Return([]models.ScimGroup{}, nil)
+	suite.repository.On("ListScimUsers", suite.transaction, suite.organizationId, "").
+		Return([]models.User{suite.analyst, suite.viewer}, nil)
+	// the members are deduplicated
+	savedInput := models.ScimGroupInput{DisplayName: input.DisplayName, MemberIds: []string{string(suite.viewer.UserId)}}
+	suite.repository.On("CreateScimGroup", suite.transaction, suite.organizationId, savedInput).Return(nil)
+	suite.repository.On("SetScimGroupMembers", suite.transaction, mock.Anything, savedInput.MemberIds).Return(nil)
+	suite.expectSync(suite.viewer, []models.ScimGroup{created}, []models.ScimGroup{created})
+	suite.repository.On("GetScimGroup", suite.transaction, suite.organizationId, mock.Anything).Return(created, nil)
+	suite.repository.On("ListScimGroupMembers", suite.transaction, []string{created.Id}).
+		Return([]models.ScimGroupMember{{GroupId: created.Id, UserId: suite.viewer.UserId}}, nil)
+
+	group, err := suite.makeUsecase().CreateGroup(suite.ctx, suite.organizationId, input)
+
+	suite.NoError(err)
+	suite.Equal([]models.ScimGroupMember{{GroupId: created.Id, UserId: suite.viewer.UserId}}, group.Members)
+	suite.AssertExpectations()
+}
+
+func (suite *ScimUsecaseTestSuite) TestCreateGroup_name_conflict() {
+	input := models.ScimGroupInput{DisplayName: suite.group.DisplayName}
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, input.DisplayName).
+		Return([]models.ScimGroup{suite.group}, nil)
+
+	_, err := suite.makeUsecase().CreateGroup(suite.ctx, suite.organizationId, input)
+
+	suite.ErrorIs(err, models.ConflictError)
+	suite.AssertExpectations()
+}
+
+func (suite *ScimUsecaseTestSuite) TestCreateGroup_unknown_member() {
+	input := models.ScimGroupInput{DisplayName: "Viewers", MemberIds: []string{"unknown_id"}}
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, input.DisplayName).
+		Return([]models.ScimGroup{}, nil)
+	suite.repository.On("ListScimUsers", suite.transaction, suite.organizationId, "").
+		Return([]models.User{suite.analyst}, nil)
+
+	_, err := suite.makeUsecase().CreateGroup(suite.ctx, suite.organizationId, input)
+
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "CreateScimGroup", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ScimUsecaseTestSuite) TestReplaceGroup() {
+	// the analyst leaves the group and the viewer joins it, the name is unchanged so it is not checked
+	input := models.ScimGroupInput{DisplayName: suite.group.DisplayName, MemberIds: []string{string(suite.viewer.UserId)}}
+	replaced := suite.group
+	replaced.Members = []models.ScimGroupMember{{GroupId: suite.group.Id, UserId: suite.viewer.UserId}}
+	suite.repository.On("GetScimGroup", suite.transaction, suite.organizationId, suite.group.Id).
+		Return(suite.group, nil)
+	suite.repository.On("ListScimGroupMembers", suite.transaction, []string{suite.group.Id}).
+		Return(suite.group.Members, nil).Once()
+	suite.repository.On("ListScimUsers", suite.transaction, suite.organizationId, "").
+		Return([]models.User{suite.analyst, suite.viewer}, nil)
+	suite.repository.On("UpdateScimGroup", suite.transaction, suite.group.Id, input).Return(nil)
+	suite.repository.On("SetScimGroupMembers", suite.transaction, suite.group.Id, input.MemberIds).Return(nil)
+	// both the former and the new members are synchronized
+	suite.expectSync(suite.analyst, []models.ScimGroup{}, []models.ScimGroup{suite.group})
+	suite.expectSync(suite.viewer, []models.ScimGroup{suite.group}, []models.ScimGroup{suite.group})
+	suite.userRepository.On("UpdateUser", suite.transaction, models.UpdateUser{
+		UserId: suite.viewer.UserId,
+		Role:   suite.group.Role,
+	}).Return(nil)
+	suite.repository.On("CreateInboxUser", suite.transaction, models.CreateInboxUserInput{
+		InboxId: *suite.group.InboxId,
+		UserId:  string(suite.viewer.UserId),
+		Role:    models.InboxUserRoleMember,
+	}).Return(nil)
+	suite.repository.On("ListScimGroupMembers", suite.transaction, []string{suite.group.Id}).
+		Return(replaced.Members, nil).Once()
+
+	group, err := suite.makeUsecase().ReplaceGroup(suite.ctx, suite.organizationId, suite.group.Id, input)
+
+	suite.NoError(err)
+	suite.Equal(replaced, group)
+	suite.AssertExpectations()
+	suite.repository.AssertNotCalled(suite.T(), "ListScimGroups", suite.transaction, suite.organizationId, input.DisplayName)
+}
+
+func (suite *ScimUsecaseTestSuite) TestDeleteGroup() {
+	suite.repository.On("GetScimGroup", suite.transaction, suite.organizationId, suite.group.Id).
+		Return(suite.group, nil)
+	suite.repository.On("ListScimGroupMembers", suite.transaction, []string{suite.group.Id}).
+		Return(suite.group.Members, nil)
+	suite.repository.On("DeleteScimGroup", suite.transaction, suite.group.Id).Return(nil)
+	// the inbox of the deleted group is no longer managed by any group, its members still leave it
+	suite.repository.On("GetScimUser", suite.transaction, suite.organizationId, suite.analyst.UserId).
+		Return(suite.analyst, nil)
+	suite.repository.On("ListScimGroupsOfUser", suite.transaction, suite.analyst.UserId).
+		Return([]models.ScimGroup{}, nil)
+	suite.repository.On("ListScimGroups", suite.transaction, suite.organizationId, "").
+		Return([]models.ScimGroup{}, nil)
+	suite.repository.On("ListInboxUsers", suite.transaction, models.InboxUserFilterInput{UserId: suite.analyst.UserId}).
+		Return([]models.InboxUser{{Id: "inbox_user_id", InboxId: *suite.group.InboxId}}, nil)
+	suite.repository.On("DeleteInboxUser", suite.transaction, "inbox_user_id").Return(nil)
+
+	err := suite.makeUsecase().DeleteGroup(suite.ctx, suite.organizationId, suite.group.Id)
+
+	suite.NoError(err)
+	suite.AssertExpectations()
+}
+
+func TestScimUsecase(t *testing.T) {
+	suite.Run(t, new(ScimUsecaseTestSuite))
+}
@@ -27,7 +27,7 @@ func (e *EnforceSecurityImpl) ReadOrganization(organizationId string) error {
 }
 
 func (e *EnforceSecurityImpl) Permission(permission models.Permission) error {
-	if !e.Credentials.Role.HasPermission(permission) {
+	if !e.Credentials.HasPermission(permission) {
 		return errors.Wrap(models.ForbiddenError, "missing permission "+permission.String())
 	}
 	return nil
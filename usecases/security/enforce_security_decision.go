@@ -2,6 +2,7 @@ package security
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/checkmarble/marble-backend/models"
 )
@@ -11,7 +12,12 @@ type EnforceSecurityDecision interface {
 	ReadDecision(decision models.Decision) error
 	ReadScheduledExecution(scheduledExecution models.ScheduledExecution) error
 	CreateDecision(organizationId string) error
+	DecideOnScenario(scenario models.Scenario) error
+	DecideOnTriggerObject(triggerObjectType string) error
 	CreateScheduledExecution(organizationId string) error
+	ReadPolicyDecision(policyDecision models.PolicyDecision) error
+	ReadAsyncDecision(asyncDecision models.AsyncDecision) error
+	ReviewDecision(decision models.Decision) error
 }
 
 type EnforceSecurityDecisionImpl struct {
@@ -33,6 +39,21 @@ func (e *EnforceSecurityDecisionImpl) CreateDecision(organizationId string) erro
 	)
 }
 
+// A scoped API key can only take decisions on some scenarios or trigger objects
+func (e *EnforceSecurityDecisionImpl) DecideOnScenario(scenario models.Scenario) error {
+	if !e.Credentials.ApiKeyScope.AllowsScenario(scenario) {
+		return fmt.Errorf("the api key cannot take decisions on scenario %s: %w", scenario.Id, models.ForbiddenError)
+	}
+	return nil
+}
+
+func (e *EnforceSecurityDecisionImpl) DecideOnTriggerObject(triggerObjectType string) error {
+	if !e.Credentials.ApiKeyScope.AllowsTriggerObjectType(triggerObjectType) {
+		return fmt.Errorf("the api key cannot take decisions on %s: %w", triggerObjectType, models.ForbiddenError)
+	}
+	return nil
+}
+
 func (e *EnforceSecurityDecisionImpl) ReadScheduledExecution(scheduledExecution models.ScheduledExecution) error {
 	return errors.Join(
 		e.Permission(models.DECISION_READ),
@@ -46,3 +67,25 @@ func (e *EnforceSecurityDecisionImpl) CreateScheduledExecution(organizationId st
 		e.ReadOrganization(organizationId),
 	)
 }
+
+func (e *EnforceSecurityDecisionImpl) ReadPolicyDecision(policyDecision models.PolicyDecision) error {
+	return errors.Join(
+		e.Permission(models.DECISION_READ),
+		e.ReadOrganization(policyDecision.OrganizationId),
+	)
+}
+
+func (e *EnforceSecurityDecisionImpl) ReadAsyncDecision(asyncDecision models.AsyncDecision) error {
+	return errors.Join(
+		e.Permission(models.DECISION_READ),
+		e.ReadOrganization(asyncDecision.OrganizationId),
+	)
+}
+
+// Reviewing a decision is done by the analysts working on cases
+func (e *EnforceSecurityDecisionImpl) ReviewDecision(decision models.Decision) error {
+	return errors.Join(
+		e.Permission(models.CASE_READ_WRITE),
+		e.ReadOrganization(decision.OrganizationId),
+	)
+}
@@ -0,0 +1,41 @@
+package security
+
+import (
+	"errors"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type EnforceSecurityDecisionPolicy interface {
+	EnforceSecurity
+	ReadDecisionPolicy(policy models.DecisionPolicy) error
+	ListDecisionPolicies(organizationId string) error
+	WriteDecisionPolicy(organizationId string) error
+}
+
+type EnforceSecurityDecisionPolicyImpl struct {
+	EnforceSecurity
+	Credentials models.Credentials
+}
+
+func (e *EnforceSecurityDecisionPolicyImpl) ReadDecisionPolicy(policy models.DecisionPolicy) error {
+	return errors.Join(
+		e.Permission(models.SCENARIO_READ),
+		e.ReadOrganization(policy.OrganizationId),
+	)
+}
+
+func (e *EnforceSecurityDecisionPolicyImpl) ListDecisionPolicies(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.SCENARIO_READ),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// Decision policies decide which scenarios are run, they are edited by the users who can publish scenarios
+func (e *EnforceSecurityDecisionPolicyImpl) WriteDecisionPolicy(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.SCENARIO_PUBLISH),
+		e.ReadOrganization(organizationId),
+	)
+}
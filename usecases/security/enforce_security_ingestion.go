@@ -2,6 +2,7 @@ package security
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/checkmarble/marble-backend/models"
 )
@@ -9,6 +10,7 @@ import (
 type EnforceSecurityIngestion interface {
 	EnforceSecurity
 	CanIngest(organizationId string) error
+	IngestInTable(tableName string) error
 }
 
 type EnforceSecurityIngestionImpl struct {
@@ -22,3 +24,11 @@ func (e *EnforceSecurityIngestionImpl) CanIngest(organizationId string) error {
 		e.ReadOrganization(organizationId),
 	)
 }
+
+// A scoped API key can only ingest data in some tables
+func (e *EnforceSecurityIngestionImpl) IngestInTable(tableName string) error {
+	if !e.Credentials.ApiKeyScope.AllowsIngestion(tableName) {
+		return fmt.Errorf("the api key cannot ingest data in %s: %w", tableName, models.ForbiddenError)
+	}
+	return nil
+}
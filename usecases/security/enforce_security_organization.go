@@ -13,6 +13,14 @@ type EnforceSecurityOrganization interface {
 	DeleteOrganization() error
 	ReadDataModel() error
 	WriteDataModel(organizationId string) error
+	ManageSsoProvider(organizationId string) error
+	ManageScim(organizationId string) error
+	ManageCustomRoles(organizationId string) error
+	ReadRateLimits(organizationId string) error
+	ManageRateLimits(organizationId string) error
+	ReadAuditEvents(organizationId string) error
+	ExportOrganizationConfig(organizationId string) error
+	ImportOrganizationConfig(organizationId string) error
 }
 
 type EnforceSecurityOrganizationImpl struct {
@@ -50,3 +58,67 @@ func (e *EnforceSecurityOrganizationImpl) WriteDataModel(organizationId string)
 		e.ReadOrganization(organizationId),
 	)
 }
+
+// The SSO identity provider decides who can log in to the organization and with which role, it is managed by the
+// users who can create users
+func (e *EnforceSecurityOrganizationImpl) ManageSsoProvider(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.MARBLE_USER_CREATE),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// Like the SSO identity provider, the SCIM provisioning creates users and gives them roles
+func (e *EnforceSecurityOrganizationImpl) ManageScim(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.MARBLE_USER_CREATE),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// Custom roles give permissions to the users and API keys of the organization, they are managed by the users who can
+// create users
+func (e *EnforceSecurityOrganizationImpl) ManageCustomRoles(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.MARBLE_USER_CREATE),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+func (e *EnforceSecurityOrganizationImpl) ReadRateLimits(organizationId string) error {
+	return errors.Join(
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// The rate limits and quotas of an organization are part of its plan, they are managed by the Marble admins who can
+// create organizations
+func (e *EnforceSecurityOrganizationImpl) ManageRateLimits(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.ORGANIZATIONS_CREATE),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+func (e *EnforceSecurityOrganizationImpl) ReadAuditEvents(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.AUDIT_EVENT_READ),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// Exporting the configuration of an organization reads its data model and its settings
+func (e *EnforceSecurityOrganizationImpl) ExportOrganizationConfig(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.DATA_MODEL_READ),
+		e.ReadOrganization(organizationId),
+	)
+}
+
+// Importing a configuration changes the data model of the organization, it needs the permission to write it
+func (e *EnforceSecurityOrganizationImpl) ImportOrganizationConfig(organizationId string) error {
+	return errors.Join(
+		e.Permission(models.DATA_MODEL_WRITE),
+		e.ReadOrganization(organizationId),
+	)
+}
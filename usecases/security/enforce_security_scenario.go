@@ -16,6 +16,9 @@ type EnforceSecurityScenario interface {
 	ListScenarios(organizationId string) error
 	CreateScenario(organizationId string) error
 	CreateRule(scenarioIteration models.ScenarioIteration) error
+	DryRunScenarioIteration(scenarioIteration models.ScenarioIteration) error
+	ReadScenarioTemplates() error
+	ManageScenarioTemplates() error
 }
 
 type EnforceSecurityScenarioImpl struct {
@@ -44,6 +47,14 @@ func (e *EnforceSecurityScenarioImpl) CreateRule(scenarioIteration models.Scenar
 	)
 }
 
+// Evaluating an iteration that is not live is reserved to the users who can edit scenarios
+func (e *EnforceSecurityScenarioImpl) DryRunScenarioIteration(scenarioIteration models.ScenarioIteration) error {
+	return errors.Join(
+		e.Permission(models.SCENARIO_CREATE),
+		e.ReadOrganization(scenarioIteration.OrganizationId),
+	)
+}
+
 func (e *EnforceSecurityScenarioImpl) ReadScenarioPublication(scenarioPublication models.ScenarioPublication) error {
 	return errors.Join(
 		e.Permission(models.SCENARIO_READ),
@@ -78,3 +89,17 @@ func (e *EnforceSecurityScenarioImpl) CreateScenario(organizationId string) erro
 		e.ReadOrganization(organizationId),
 	)
 }
+
+// The scenario templates are shared by all the organizations: anyone who can create scenarios can browse them, but only
+// the Marble admins can edit the library
+func (e *EnforceSecurityScenarioImpl) ReadScenarioTemplates() error {
+	return errors.Join(
+		e.Permission(models.SCENARIO_CREATE),
+	)
+}
+
+func (e *EnforceSecurityScenarioImpl) ManageScenarioTemplates() error {
+	return errors.Join(
+		e.Permission(models.ORGANIZATIONS_CREATE),
+	)
+}
@@ -31,6 +31,28 @@ func (e *EnforceSecurityImpl) CanReadWebhook(ctx context.Context, webhook models
 	)
 }
 
+func (e *EnforceSecurityImpl) CanReadWebhookEvents(ctx context.Context, organizationId string, partnerId null.String) error {
+	return errors.Join(
+		e.Permission(models.WEBHOOK),
+		utils.EnforceOrganizationAndPartnerAccess(e.Credentials, organizationId, partnerId),
+	)
+}
+
+func (e *EnforceSecurityImpl) CanReadWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error {
+	return errors.Join(
+		e.Permission(models.WEBHOOK),
+		utils.EnforceOrganizationAndPartnerAccess(e.Credentials, webhookEvent.OrganizationId, webhookEvent.PartnerId),
+	)
+}
+
+func (e *EnforceSecurityImpl) CanReplayWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error {
+	return errors.Join(
+		e.Permission(models.WEBHOOK),
+		e.Permission(models.WEBHOOK_EVENT),
+		utils.EnforceOrganizationAndPartnerAccess(e.Credentials, webhookEvent.OrganizationId, webhookEvent.PartnerId),
+	)
+}
+
 func (e *EnforceSecurityImpl) CanModifyWebhook(ctx context.Context, webhook models.Webhook) error {
 	return errors.Join(
 		e.Permission(models.WEBHOOK),
@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/security"
+)
+
+type SsoRepository interface {
+	GetSsoProviderOfOrganization(ctx context.Context, exec repositories.Executor, organizationId string) (models.SsoProvider, error)
+	UpsertSsoProvider(ctx context.Context, exec repositories.Executor, input models.UpsertSsoProviderInput) error
+	DeleteSsoProvider(ctx context.Context, exec repositories.Executor, organizationId string) error
+	CreateSsoLoginState(ctx context.Context, exec repositories.Executor, state models.SsoLoginState) error
+	ConsumeSsoLoginState(ctx context.Context, exec repositories.Executor, state string) (models.SsoLoginState, error)
+	DeleteExpiredSsoLoginStates(ctx context.Context, exec repositories.Executor, now time.Time) error
+	// also returns the deactivated (soft deleted) users of the organization
+	ListScimUsers(ctx context.Context, exec repositories.Executor, organizationId, email string) ([]models.User, error)
+}
+
+type OidcClient interface {
+	AuthorizationUrl(ctx context.Context, provider models.SsoProvider, state models.SsoLoginState) (string, error)
+	ExchangeCode(ctx context.Context, provider models.SsoProvider, state models.SsoLoginState, code string) (models.SsoIdentity, error)
+}
+
+var errSsoNotInLicense = errors.Wrap(models.ForbiddenError, "SSO is not included in your license")
+
+type SsoProviderUsecase struct {
+	enforceSecurity    security.EnforceSecurityOrganization
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         SsoRepository
+	hasLicense         bool
+}
+
+func (usecase *SsoProviderUsecase) GetSsoProvider(ctx context.Context, organizationId string) (models.SsoProvider, error) {
+	if err := usecase.enforceSecurity.ManageSsoProvider(organizationId); err != nil {
+		return models.SsoProvider{}, err
+	}
+	return usecase.repository.GetSsoProviderOfOrganization(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+}
+
+func (usecase *SsoProviderUsecase) UpsertSsoProvider(ctx context.Context, input models.UpsertSsoProviderInput) (models.SsoProvider, error) {
+	if err := usecase.enforceSecurity.ManageSsoProvider(input.OrganizationId); err != nil {
+		return models.SsoProvider{}, err
+	}
+	if !usecase.hasLicense {
+		return models.SsoProvider{}, errSsoNotInLicense
+	}
+	if err := input.Validate(); err != nil {
+		return models.SsoProvider{}, err
+	}
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.SsoProvider, error) {
+		if input.ClientSecret == nil {
+			current, err := usecase.repository.GetSsoProviderOfOrganization(ctx, tx, input.OrganizationId)
+			if err != nil && !errors.Is(err, models.NotFoundError) {
+				return models.SsoProvider{}, err
+			}
+			input.ClientSecret = &current.ClientSecret
+		}
+		if err := usecase.repository.UpsertSsoProvider(ctx, tx, input); err != nil {
+			return models.SsoProvider{}, err
+		}
+		return usecase.repository.GetSsoProviderOfOrganization(ctx, tx, input.OrganizationId)
+	})
+}
+
+func (usecase *SsoProviderUsecase) DeleteSsoProvider(ctx context.Context, organizationId string) error {
+	if err := usecase.enforceSecurity.ManageSsoProvider(organizationId); err != nil {
+		return err
+	}
+	return usecase.repository.DeleteSsoProvider(ctx, usecase.executorFactory.NewExecutor(), organizationId)
+}
+
+// SsoLoginUsecase logs the users in with the identity provider of their organization. It runs before the user has
+// a Marble token, so it is not bound to credentials.
+type SsoLoginUsecase struct {
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	repository         SsoRepository
+	userRepository     repositories.UserRepository
+	oidcClient         OidcClient
+	hasLicense         bool
+}
+
+func generateSsoSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Errorf("generateSsoSecret: %w", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(secret)
+}
+
+func (usecase *SsoLoginUsecase) getEnabledProvider(ctx context.Context, exec repositories.Executor, organizationId string) (models.SsoProvider, error) {
+	provider, err := usecase.repository.GetSsoProviderOfOrganization(ctx, exec, organizationId)
+	if err != nil {
+		return models.SsoProvider{}, err
+	}
+	if !provider.Enabled {
+		return models.SsoProvider{}, errors.Wrap(models.NotFoundError, "SSO is disabled for this organization")
+	}
+	return provider, nil
+}
+
+// StartLogin returns the url of the identity provider login page. Once logged in, the user is redirected to
+// redirectUri with the state and code to pass to FinishLogin.
+func (usecase *SsoLoginUsecase) StartLogin(ctx context.Context, organizationId, redirectUri string) (string, error) {
+	if !usecase.hasLicense {
+		return "", errSsoNotInLicense
+	}
+	parsedRedirectUri, err := url.Parse(redirectUri)
+	if err != nil || !parsedRedirectUri.IsAbs() {
+		return "", errors.Wrapf(models.BadParameterError, "invalid redirect_uri %q", redirectUri)
+	}
+
+	exec := usecase.executorFactory.NewExecutor()
+	provider, err := usecase.getEnabledProvider(ctx, exec, organizationId)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	state := models.SsoLoginState{
+		State:          generateSsoSecret(),
+		OrganizationId: organizationId,
+		CodeVerifier:   generateSsoSecret(),
+		Nonce:          generateSsoSecret(),
+		RedirectUri:    redirectUri,
+		ExpiresAt:      now.Add(models.SSO_LOGIN_STATE_LIFETIME),
+	}
+	if err := usecase.repository.DeleteExpiredSsoLoginStates(ctx, exec, now); err != nil {
+		return "", err
+	}
+	if err := usecase.repository.CreateSsoLoginState(ctx, exec, state); err != nil {
+		return "", err
+	}
+	return usecase.oidcClient.AuthorizationUrl(ctx, provider, state)
+}
+
+// FinishLogin exchanges the code returned by the identity provider and returns the Marble user it identifies.
+// Unknown users are created in the organization if the provider allows just-in-time provisioning, and the role of
+// existing users follows their claims if the provider maps claims to roles.
+func (usecase *SsoLoginUsecase) FinishLogin(ctx context.Context, state, code string) (models.User, error) {
+	if !usecase.hasLicense {
+		return models.User{}, errSsoNotInLicense
+	}
+
+	// consumed outside of the transaction: a state cannot be reused even if the login fails
+	exec := usecase.executorFactory.NewExecutor()
+	loginState, err := usecase.repository.ConsumeSsoLoginState(ctx, exec, state)
+	if err != nil {
+		return models.User{}, err
+	}
+	if time.Now().After(loginState.ExpiresAt) {
+		return models.User{}, errors.Wrap(models.UnAuthorizedError, "the SSO login has expired")
+	}
+	provider, err := usecase.getEnabledProvider(ctx, exec, loginState.OrganizationId)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	identity, err := usecase.oidcClient.ExchangeCode(ctx, provider, loginState, code)
+	if err != nil {
+		return models.User{}, err
+	}
+	role := provider.RoleOfIdentity(identity)
+
+	return executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.User, error) {
+		user, err := usecase.userRepository.UserByEmail(ctx, tx, identity.Email)
+		if err != nil {
+			return models.User{}, err
+		}
+
+		if user == nil {
+			// a user deleted by an admin or deactivated by SCIM is not provisioned again
+			deactivated, err := usecase.isDeactivatedUser(ctx, tx, provider.OrganizationId, identity.Email)
+			if err != nil {
+				return models.User{}, err
+			}
+			if deactivated {
+				return models.User{}, errors.Wrapf(models.ForbiddenError, "the user %s is deactivated", identity.Email)
+			}
+			if !provider.JitProvisioning {
+				return models.User{}, models.ErrUnknownUser
+			}
+			if role == models.NO_ROLE {
+				return models.User{}, errors.Wrapf(models.ForbiddenError, "no role is granted to %s", identity.Email)
+			}
+			userId, err := usecase.userRepository.CreateUser(ctx, tx, models.CreateUser{
+				Email:          identity.Email,
+				Role:           role,
+				OrganizationId: provider.OrganizationId,
+				FirstName:      identity.FirstName,
+				LastName:       identity.LastName,
+			})
+			if err != nil {
+				return models.User{}, err
+			}
+			return usecase.userRepository.UserByID(ctx, tx, userId)
+		}
+
+		if user.OrganizationId != provider.OrganizationId {
+			return models.User{}, errors.Wrapf(models.ForbiddenError,
+				"%s cannot log in with the identity provider of this organization", identity.Email)
+		}
+		if provider.RoleClaim == "" || role == user.Role {
+			return *user, nil
+		}
+		if role == models.NO_ROLE {
+			return models.User{}, errors.Wrapf(models.ForbiddenError, "no role is granted to %s", identity.Email)
+		}
+		err = usecase.userRepository.UpdateUser(ctx, tx, models.UpdateUser{UserId: user.UserId, Role: role})
+		if err != nil {
+			return models.User{}, err
+		}
+		return usecase.userRepository.UserByID(ctx, tx, user.UserId)
+	})
+}
+
+func (usecase *SsoLoginUsecase) isDeactivatedUser(ctx context.Context, exec repositories.Executor,
+	organizationId, email string,
+) (bool, error) {
+	users, err := usecase.repository.ListScimUsers(ctx, exec, organizationId, email)
+	if err != nil {
+		return false, err
+	}
+	for _, user := range users {
+		if user.DeletedAt != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
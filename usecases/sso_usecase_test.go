@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+type SsoLoginUsecaseTestSuite struct {
+	suite.Suite
+	repository     *mocks.SsoRepository
+	userRepository *mocks.UserRepository
+	oidcClient     *mocks.OidcClient
+	exec           *mocks.Executor
+	transaction    *mocks.Executor
+
+	ctx        context.Context
+	provider   models.SsoProvider
+	loginState models.SsoLoginState
+	identity   models.SsoIdentity
+}
+
+func (suite *SsoLoginUsecaseTestSuite) SetupTest() {
+	suite.repository = new(mocks.SsoRepository)
+	suite.userRepository = new(mocks.UserRepository)
+	suite.oidcClient = new(mocks.OidcClient)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.provider = models.SsoProvider{
+		Id:              "provider_id",
+		OrganizationId:  "organization_id",
+		DefaultRole:     models.VIEWER,
+		JitProvisioning: true,
+		Enabled:         true,
+	}
+	suite.loginState = models.SsoLoginState{
+		State:          "state",
+		OrganizationId: "organization_id",
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}
+	suite.identity = models.SsoIdentity{Subject: "subject", Email: "user@example.com"}
+
+	suite.repository.On("ConsumeSsoLoginState", suite.exec, "state").Return(suite.loginState, nil)
+	suite.repository.On("GetSsoProviderOfOrganization", suite.exec, "organization_id").Return(suite.provider, nil)
+	suite.oidcClient.On("ExchangeCode", suite.provider, suite.loginState, "code").Return(suite.identity, nil)
+}
+
+func (suite *SsoLoginUsecaseTestSuite) makeUsecase() *SsoLoginUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return &SsoLoginUsecase{
+		executorFactory:    executorFactory,
+		transactionFactory: transactionFactory,
+		repository:         suite.repository,
+		userRepository:     suite.userRepository,
+		oidcClient:         suite.oidcClient,
+		hasLicense:         true,
+	}
+}
+
+func (suite *SsoLoginUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.repository.AssertExpectations(t)
+	suite.userRepository.AssertExpectations(t)
+	suite.oidcClient.AssertExpectations(t)
+}
+
+func (suite *SsoLoginUsecaseTestSuite) TestFinishLogin_jit_provisioning() {
+	user := models.User{
+		UserId:         "user_id",
+		Email:          "user@example.com",
+		Role:           models.VIEWER,
+		OrganizationId: "organization_id",
+	}
+	suite.userRepository.On("UserByEmail", suite.transaction, "user@example.com").Return((*models.User)(nil), nil)
+	suite.repository.On("ListScimUsers", suite.transaction, "organization_id", "user@example.com").
+		Return([]models.User{}, nil)
+	suite.userRepository.On("CreateUser", suite.transaction, models.CreateUser{
+		Email:          "user@example.com",
+		Role:           models.VIEWER,
+		OrganizationId: "organization_id",
+	}).Return(models.UserId("user_id"), nil)
+	suite.userRepository.On("UserByID", suite.transaction, models.UserId("user_id")).Return(user, nil)
+
+	result, err := suite.makeUsecase().FinishLogin(suite.ctx, "state", "code")
+
+	t := suite.T()
+	assert.NoError(t, err)
+	assert.Equal(t, user, result)
+	suite.AssertExpectations()
+}
+
+func (suite *SsoLoginUsecaseTestSuite) TestFinishLogin_deactivated_user() {
+	deactivated := models.User{
+		UserId:         "user_id",
+		Email:          "user@example.com",
+		Role:           models.VIEWER,
+		OrganizationId: "organization_id",
+		DeletedAt:      utils.Ptr(time.Now()),
+	}
+	// the deleted users are not returned by UserByEmail
+	suite.userRepository.On("UserByEmail", suite.transaction, "user@example.com").Return((*models.User)(nil), nil)
+	suite.repository.On("ListScimUsers", suite.transaction, "organization_id", "user@example.com").
+		Return([]models.User{deactivated}, nil)
+
+	_, err := suite.makeUsecase().FinishLogin(suite.ctx, "state", "code")
+
+	t := suite.T()
+	assert.ErrorIs(t, err, models.ForbiddenError)
+	suite.userRepository.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func TestSsoLoginUsecase(t *testing.T) {
+	suite.Run(t, new(SsoLoginUsecaseTestSuite))
+}
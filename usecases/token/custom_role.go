@@ -0,0 +1,27 @@
+package token
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type customRoleGetter interface {
+	GetCustomRoleById(ctx context.Context, customRoleId string) (models.CustomRole, error)
+}
+
+// withCustomRole gives the credentials the permissions of the custom role of the user or API key, if any. They are
+// carried by the token, so changes of a custom role apply to the new tokens.
+func withCustomRole(ctx context.Context, getter customRoleGetter, credentials models.Credentials,
+	customRoleId *string,
+) (models.Credentials, error) {
+	if customRoleId == nil {
+		return credentials, nil
+	}
+	customRole, err := getter.GetCustomRoleById(ctx, *customRoleId)
+	if err != nil {
+		return models.Credentials{}, fmt.Errorf("GetCustomRoleById error: %w", err)
+	}
+	return credentials.WithCustomRole(customRole), nil
+}
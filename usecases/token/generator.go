@@ -16,6 +16,7 @@ type marbleRepository interface {
 	GetApiKeyByHash(ctx context.Context, hash []byte) (models.ApiKey, error)
 	GetOrganizationByID(ctx context.Context, organizationID string) (models.Organization, error)
 	UserByEmail(ctx context.Context, email string) (models.User, error)
+	GetCustomRoleById(ctx context.Context, customRoleId string) (models.CustomRole, error)
 }
 
 type encoder interface {
@@ -34,8 +35,12 @@ type Generator struct {
 	tokenLifetime time.Duration
 }
 
-func (g *Generator) encodeToken(credentials models.Credentials) (string, time.Time, models.Credentials, error) {
+// encodeToken encodes a token valid for the token lifetime, but not after notAfter if it is set
+func (g *Generator) encodeToken(credentials models.Credentials, notAfter *time.Time) (string, time.Time, models.Credentials, error) {
 	expirationTime := g.clock.Now().Add(g.tokenLifetime)
+	if notAfter != nil && notAfter.Before(expirationTime) {
+		expirationTime = *notAfter
+	}
 
 	token, err := g.encoder.EncodeMarbleToken(expirationTime, credentials)
 	if err != nil {
@@ -53,6 +58,10 @@ func (g *Generator) FromAPIKey(ctx context.Context, apiKey string) (string, time
 		return "", time.Time{}, models.Credentials{},
 			fmt.Errorf("GetApiKeyByHash error: %w", err)
 	}
+	if key.IsExpired(g.clock.Now()) {
+		return "", time.Time{}, models.Credentials{},
+			fmt.Errorf("api key %s*** has expired: %w", key.Prefix, models.UnAuthorizedError)
+	}
 
 	organization, err := g.repository.GetOrganizationByID(ctx, key.OrganizationId)
 	if err != nil {
@@ -61,8 +70,12 @@ func (g *Generator) FromAPIKey(ctx context.Context, apiKey string) (string, time
 	}
 
 	name := fmt.Sprintf("Api key %s*** of %s", key.Prefix, organization.Name)
-	credentials := models.NewCredentialWithApiKey(key.OrganizationId, key.PartnerId, key.Role, name)
-	return g.encodeToken(credentials)
+	credentials, err := withCustomRole(ctx, g.repository,
+		models.NewCredentialWithApiKey(key, name), key.CustomRoleId)
+	if err != nil {
+		return "", time.Time{}, models.Credentials{}, err
+	}
+	return g.encodeToken(credentials, key.ExpiresAt)
 }
 
 func (g *Generator) fromFirebaseToken(ctx context.Context, firebaseToken string) (string, time.Time, models.Credentials, error) {
@@ -81,12 +94,14 @@ func (g *Generator) fromFirebaseToken(ctx context.Context, firebaseToken string)
 			fmt.Errorf("repository.UserByEmail error: %w", err)
 	}
 
-	credentials := models.NewCredentialWithUser(user)
-	return g.encodeToken(credentials)
+	credentials, err := withCustomRole(ctx, g.repository, models.NewCredentialWithUser(user), user.CustomRoleId)
+	if err != nil {
+		return "", time.Time{}, models.Credentials{}, err
+	}
+	return g.encodeToken(credentials, nil)
 }
 
 func (g *Generator) GenerateToken(ctx context.Context, key string, firebaseToken string) (string, time.Time, error) {
-	// segment analytics events only for login by an end user with firebase
 	if key != "" {
 		token, expirationTime, _, err := g.FromAPIKey(ctx, key)
 		return token, expirationTime, err
@@ -97,27 +112,53 @@ func (g *Generator) GenerateToken(ctx context.Context, key string, firebaseToken
 		return "", time.Time{}, err
 	}
 
-	if credentials.Role != models.MARBLE_ADMIN {
-		organization, err := g.repository.GetOrganizationByID(ctx, credentials.OrganizationId)
-		if err != nil {
-			return "", time.Time{}, fmt.Errorf("GetOrganizationByID error: %w", err)
-		}
+	if err := g.trackLogin(ctx, credentials); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expirationTime, nil
+}
 
-		tracking.Identify(ctx, credentials.ActorIdentity.UserId, map[string]any{
-			"email": credentials.ActorIdentity.Email,
-		})
-		tracking.Group(ctx, credentials.ActorIdentity.UserId, credentials.OrganizationId, map[string]any{
-			"name": organization.Name,
-		})
-		tracking.TrackEventWithUserId(ctx, models.AnalyticsTokenCreated,
-			credentials.ActorIdentity.UserId, map[string]any{
-				"organization_id": credentials.OrganizationId,
-			})
+// FromUser returns a token for a user authenticated by other means than Firebase, such as the SSO of their organization
+func (g *Generator) FromUser(ctx context.Context, user models.User) (string, time.Time, error) {
+	credentials, err := withCustomRole(ctx, g.repository, models.NewCredentialWithUser(user), user.CustomRoleId)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	token, expirationTime, credentials, err := g.encodeToken(credentials, nil)
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
+	if err := g.trackLogin(ctx, credentials); err != nil {
+		return "", time.Time{}, err
+	}
 	return token, expirationTime, nil
 }
 
+// segment analytics events only for login by an end user
+func (g *Generator) trackLogin(ctx context.Context, credentials models.Credentials) error {
+	if credentials.Role == models.MARBLE_ADMIN {
+		return nil
+	}
+
+	organization, err := g.repository.GetOrganizationByID(ctx, credentials.OrganizationId)
+	if err != nil {
+		return fmt.Errorf("GetOrganizationByID error: %w", err)
+	}
+
+	tracking.Identify(ctx, credentials.ActorIdentity.UserId, map[string]any{
+		"email": credentials.ActorIdentity.Email,
+	})
+	tracking.Group(ctx, credentials.ActorIdentity.UserId, credentials.OrganizationId, map[string]any{
+		"name": organization.Name,
+	})
+	tracking.TrackEventWithUserId(ctx, models.AnalyticsTokenCreated,
+		credentials.ActorIdentity.UserId, map[string]any{
+			"organization_id": credentials.OrganizationId,
+		})
+	return nil
+}
+
 func NewGenerator(repository marbleRepository, encoder encoder, verifier firebaseTokenVerifier, tokenLifetime int) *Generator {
 	return &Generator{
 		repository:    repository,
@@ -49,6 +49,7 @@ func TestGenerator_GenerateToken_APIKey(t *testing.T) {
 			OrganizationId: "organization_id",
 			Role:           models.ADMIN,
 			ActorIdentity: models.Identity{
+				ApiKeyId:   "api_key_id",
 				ApiKeyName: "Api key abc*** of organization",
 			},
 		}).
@@ -77,6 +78,7 @@ func TestGenerator_GenerateToken_APIKey(t *testing.T) {
 
 		generator := Generator{
 			repository: mockRepository,
+			clock:      clock.NewMock(now),
 		}
 
 		_, _, err := generator.GenerateToken(ctx, key, "")
@@ -94,6 +96,7 @@ func TestGenerator_GenerateToken_APIKey(t *testing.T) {
 
 		generator := Generator{
 			repository: mockRepository,
+			clock:      clock.NewMock(now),
 		}
 
 		_, _, err := generator.GenerateToken(ctx, key, "")
@@ -114,6 +117,7 @@ func TestGenerator_GenerateToken_APIKey(t *testing.T) {
 			OrganizationId: "organization_id",
 			Role:           models.ADMIN,
 			ActorIdentity: models.Identity{
+				ApiKeyId:   "api_key_id",
 				ApiKeyName: "Api key abc*** of organization",
 			},
 		}).
@@ -298,3 +302,96 @@ func TestGenerator_GenerateToken_FirebaseToken(t *testing.T) {
 		mockEncoder.AssertExpectations(t)
 	})
 }
+
+func TestGenerator_FromUser(t *testing.T) {
+	token := "token"
+	now := time.Now()
+	user := models.User{
+		UserId:         "user_id",
+		Email:          "user@email.com",
+		Role:           models.VIEWER,
+		OrganizationId: "organization_id",
+	}
+
+	mockRepository := new(mocks.Database)
+	mockRepository.On("GetOrganizationByID", mock.Anything, "organization_id").
+		Return(models.Organization{}, nil)
+
+	mockEncoder := new(mocks.JWTEncoderValidator)
+	mockEncoder.On("EncodeMarbleToken", now.Add(60*time.Second), models.NewCredentialWithUser(user)).
+		Return(token, nil)
+
+	generator := Generator{
+		repository:    mockRepository,
+		encoder:       mockEncoder,
+		clock:         clock.NewMock(now),
+		tokenLifetime: 60 * time.Second,
+	}
+
+	receivedToken, expirationTime, err := generator.FromUser(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, token, receivedToken)
+	assert.Equal(t, now.Add(60*time.Second), expirationTime)
+	mockRepository.AssertExpectations(t)
+	mockEncoder.AssertExpectations(t)
+}
+
+func TestGenerator_FromUser_CustomRole(t *testing.T) {
+	token := "token"
+	now := time.Now()
+	customRole := models.CustomRole{
+		Id:             "custom_role_id",
+		OrganizationId: "organization_id",
+		Permissions:    []models.Permission{models.DECISION_READ, models.CASE_READ_WRITE},
+	}
+	user := models.User{
+		UserId:         "user_id",
+		Email:          "user@email.com",
+		Role:           models.VIEWER,
+		OrganizationId: "organization_id",
+		CustomRoleId:   &customRole.Id,
+	}
+
+	t.Run("nominal", func(t *testing.T) {
+		mockRepository := new(mocks.Database)
+		mockRepository.On("GetCustomRoleById", mock.Anything, customRole.Id).
+			Return(customRole, nil)
+		mockRepository.On("GetOrganizationByID", mock.Anything, "organization_id").
+			Return(models.Organization{}, nil)
+
+		// the token carries the permissions of the custom role instead of the permissions of the role of the user
+		credentials := models.NewCredentialWithUser(user).WithCustomRole(customRole)
+		mockEncoder := new(mocks.JWTEncoderValidator)
+		mockEncoder.On("EncodeMarbleToken", now.Add(60*time.Second), credentials).
+			Return(token, nil)
+
+		generator := Generator{
+			repository:    mockRepository,
+			encoder:       mockEncoder,
+			clock:         clock.NewMock(now),
+			tokenLifetime: 60 * time.Second,
+		}
+
+		receivedToken, _, err := generator.FromUser(context.Background(), user)
+		assert.NoError(t, err)
+		assert.Equal(t, token, receivedToken)
+		assert.Equal(t, customRole.Permissions, credentials.Permissions())
+		mockRepository.AssertExpectations(t)
+		mockEncoder.AssertExpectations(t)
+	})
+
+	t.Run("GetCustomRoleById error", func(t *testing.T) {
+		mockRepository := new(mocks.Database)
+		mockRepository.On("GetCustomRoleById", mock.Anything, customRole.Id).
+			Return(models.CustomRole{}, assert.AnError)
+
+		generator := Generator{
+			repository: mockRepository,
+			clock:      clock.NewMock(now),
+		}
+
+		_, _, err := generator.FromUser(context.Background(), user)
+		assert.Error(t, err)
+		mockRepository.AssertExpectations(t)
+	})
+}
@@ -3,23 +3,50 @@ package token
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/clock"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	// The API keys of the tokens are read again after this delay, so that a deleted or expired key stops being
+	// accepted within this delay instead of at the expiration of the tokens obtained from it
+	apiKeyCacheDuration = time.Minute
+	// The usage of the API keys is counted in memory and written to the database at this interval
+	apiKeyUsageFlushInterval = 10 * time.Second
 )
 
 type keyAndOrganizationGetter interface {
 	GetApiKeyByHash(ctx context.Context, hash []byte) (models.ApiKey, error)
+	GetApiKeyById(ctx context.Context, apiKeyId string) (models.ApiKey, error)
 	GetOrganizationByID(ctx context.Context, organizationID string) (models.Organization, error)
+	GetCustomRoleById(ctx context.Context, customRoleId string) (models.CustomRole, error)
+	RecordApiKeyUsages(ctx context.Context, usages map[string]models.ApiKeyUsage) error
 }
 
 type marbleTokenValidator interface {
 	ValidateMarbleToken(marbleToken string) (models.Credentials, error)
 }
 
+// cachedApiKey is an API key read by the validator, apiKey is nil if the key was deleted
+type cachedApiKey struct {
+	apiKey    *models.ApiKey
+	fetchedAt time.Time
+}
+
 type Validator struct {
 	getter    keyAndOrganizationGetter
 	validator marbleTokenValidator
+	clock     clock.Clock
+
+	mu      sync.Mutex
+	apiKeys map[string]cachedApiKey
+	usages  map[string]models.ApiKeyUsage
 }
 
 func (v *Validator) fromAPIKey(ctx context.Context, key string) (models.Credentials, error) {
@@ -28,26 +55,140 @@ func (v *Validator) fromAPIKey(ctx context.Context, key string) (models.Credenti
 	if err != nil {
 		return models.Credentials{}, fmt.Errorf("getter.GetApiKeyByHash error: %w", err)
 	}
+	if apiKey.IsExpired(v.clock.Now()) {
+		return models.Credentials{}, fmt.Errorf("api key %s*** has expired: %w", apiKey.Prefix, models.UnAuthorizedError)
+	}
 
 	organization, err := v.getter.GetOrganizationByID(ctx, apiKey.OrganizationId)
 	if err != nil {
 		return models.Credentials{}, fmt.Errorf("getter.GetOrganizationByID error: %w", err)
 	}
 	name := fmt.Sprintf("Api key %s*** of %s", apiKey.Prefix, organization.Name)
-	credentials := models.NewCredentialWithApiKey(apiKey.OrganizationId, apiKey.PartnerId, apiKey.Role, name)
-	return credentials, nil
+	credentials := models.NewCredentialWithApiKey(apiKey, name)
+	return withCustomRole(ctx, v.getter, credentials, apiKey.CustomRoleId)
 }
 
 func (v *Validator) Validate(ctx context.Context, marbleToken, apiKey string) (models.Credentials, error) {
+	var credentials models.Credentials
+	var err error
 	if apiKey != "" {
-		return v.fromAPIKey(ctx, apiKey)
+		credentials, err = v.fromAPIKey(ctx, apiKey)
+	} else {
+		credentials, err = v.fromMarbleToken(ctx, marbleToken)
+	}
+	if err != nil {
+		return models.Credentials{}, err
+	}
+
+	// the requests made with a token obtained from an API key are counted as requests of the key
+	if apiKeyId := credentials.ActorIdentity.ApiKeyId; apiKeyId != "" {
+		v.recordApiKeyUsage(apiKeyId)
+	}
+	return credentials, nil
+}
+
+func (v *Validator) fromMarbleToken(ctx context.Context, marbleToken string) (models.Credentials, error) {
+	credentials, err := v.validator.ValidateMarbleToken(marbleToken)
+	if err != nil {
+		return models.Credentials{}, err
+	}
+	if apiKeyId := credentials.ActorIdentity.ApiKeyId; apiKeyId != "" {
+		if err := v.checkApiKeyOfToken(ctx, apiKeyId); err != nil {
+			return models.Credentials{}, err
+		}
+	}
+	return credentials, nil
+}
+
+// checkApiKeyOfToken refuses the tokens obtained from an API key which has since been deleted or has expired, such as
+// a key replaced by a rotation
+func (v *Validator) checkApiKeyOfToken(ctx context.Context, apiKeyId string) error {
+	now := v.clock.Now()
+	v.mu.Lock()
+	cached, ok := v.apiKeys[apiKeyId]
+	v.mu.Unlock()
+
+	if !ok || now.Sub(cached.fetchedAt) >= apiKeyCacheDuration {
+		apiKey, err := v.getter.GetApiKeyById(ctx, apiKeyId)
+		switch {
+		case errors.Is(err, models.NotFoundError):
+			cached = cachedApiKey{fetchedAt: now}
+		case err != nil:
+			return fmt.Errorf("getter.GetApiKeyById error: %w", err)
+		default:
+			cached = cachedApiKey{apiKey: &apiKey, fetchedAt: now}
+		}
+
+		v.mu.Lock()
+		if v.apiKeys == nil {
+			v.apiKeys = make(map[string]cachedApiKey)
+		}
+		v.apiKeys[apiKeyId] = cached
+		v.mu.Unlock()
+	}
+
+	if cached.apiKey == nil || cached.apiKey.IsExpired(now) {
+		return fmt.Errorf("the api key of the token is deleted or expired: %w", models.UnAuthorizedError)
+	}
+	return nil
+}
+
+func (v *Validator) recordApiKeyUsage(apiKeyId string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.usages == nil {
+		v.usages = make(map[string]models.ApiKeyUsage)
+	}
+	v.usages[apiKeyId] = v.usages[apiKeyId].Add(models.ApiKeyUsage{RequestCount: 1, LastUsedAt: v.clock.Now()})
+}
+
+// FlushApiKeyUsages writes the usage of the API keys counted since the last flush. It is kept for the next flush if it
+// cannot be written.
+func (v *Validator) FlushApiKeyUsages(ctx context.Context) error {
+	v.mu.Lock()
+	usages := v.usages
+	v.usages = nil
+	v.mu.Unlock()
+	if len(usages) == 0 {
+		return nil
+	}
+
+	if err := v.getter.RecordApiKeyUsages(ctx, usages); err != nil {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.usages == nil {
+			v.usages = make(map[string]models.ApiKeyUsage)
+		}
+		for apiKeyId, usage := range usages {
+			v.usages[apiKeyId] = v.usages[apiKeyId].Add(usage)
+		}
+		return fmt.Errorf("getter.RecordApiKeyUsages error: %w", err)
+	}
+	return nil
+}
+
+// RunApiKeyUsageFlush flushes the usage of the API keys at regular intervals until the context is cancelled. The usage
+// statistics must not slow down the requests, so they are not written by the requests themselves.
+func (v *Validator) RunApiKeyUsageFlush(ctx context.Context) {
+	ticker := time.NewTicker(apiKeyUsageFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.FlushApiKeyUsages(ctx); err != nil {
+				utils.LoggerFromContext(ctx).WarnContext(ctx,
+					fmt.Sprintf("could not record the usage of the api keys: %v", err))
+			}
+		}
 	}
-	return v.validator.ValidateMarbleToken(marbleToken)
 }
 
 func NewValidator(getter keyAndOrganizationGetter, validator marbleTokenValidator) *Validator {
 	return &Validator{
 		getter:    getter,
 		validator: validator,
+		clock:     clock.New(),
 	}
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/hex"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/checkmarble/marble-backend/mocks"
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories/clock"
 )
 
 func TestValidator_Validate_APIKey(t *testing.T) {
@@ -33,11 +35,13 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 		OrganizationId: "organization_id",
 		Role:           models.ADMIN,
 		ActorIdentity: models.Identity{
+			ApiKeyId:   "api_key_id",
 			ApiKeyName: "Api key abc*** of organization",
 		},
 	}
 
 	ctx := context.Background()
+	now := time.Now()
 
 	t.Run("nominal", func(t *testing.T) {
 		mockKeyAndOrganizationGetter := new(mocks.Database)
@@ -48,6 +52,7 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 
 		v := Validator{
 			getter: mockKeyAndOrganizationGetter,
+			clock:  clock.NewMock(now),
 		}
 
 		credentials, err := v.Validate(ctx, "", key)
@@ -56,6 +61,37 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 		mockKeyAndOrganizationGetter.AssertExpectations(t)
 	})
 
+	t.Run("with a custom role", func(t *testing.T) {
+		customRole := models.CustomRole{
+			Id:             "custom_role_id",
+			OrganizationId: "organization_id",
+			Permissions:    []models.Permission{models.DECISION_READ},
+		}
+		apiKeyWithCustomRole := apiKey
+		apiKeyWithCustomRole.CustomRoleId = &customRole.Id
+
+		mockKeyAndOrganizationGetter := new(mocks.Database)
+		mockKeyAndOrganizationGetter.On("GetApiKeyByHash", ctx, keyHash).
+			Return(apiKeyWithCustomRole, nil)
+		mockKeyAndOrganizationGetter.On("GetOrganizationByID", ctx, apiKey.OrganizationId).
+			Return(organization, nil)
+		mockKeyAndOrganizationGetter.On("GetCustomRoleById", ctx, customRole.Id).
+			Return(customRole, nil)
+
+		v := Validator{
+			getter: mockKeyAndOrganizationGetter,
+			clock:  clock.NewMock(now),
+		}
+
+		// the permissions of the custom role replace the permissions of the role of the key
+		credentials, err := v.Validate(ctx, "", key)
+		assert.NoError(t, err)
+		assert.Equal(t, creds.WithCustomRole(customRole), credentials)
+		assert.Equal(t, customRole.Permissions, credentials.Permissions())
+		assert.False(t, credentials.HasPermission(models.SCENARIO_CREATE))
+		mockKeyAndOrganizationGetter.AssertExpectations(t)
+	})
+
 	t.Run("GetApiKeyByHash error", func(t *testing.T) {
 		mockKeyAndOrganizationGetter := new(mocks.Database)
 		mockKeyAndOrganizationGetter.On("GetApiKeyByHash", ctx, keyHash).
@@ -63,6 +99,7 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 
 		v := Validator{
 			getter: mockKeyAndOrganizationGetter,
+			clock:  clock.NewMock(now),
 		}
 
 		_, err := v.Validate(ctx, "", key)
@@ -70,7 +107,26 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 		mockKeyAndOrganizationGetter.AssertExpectations(t)
 	})
 
-	t.Run("nominal", func(t *testing.T) {
+	t.Run("expired api key", func(t *testing.T) {
+		expiresAt := now.Add(-time.Minute)
+		expiredApiKey := apiKey
+		expiredApiKey.ExpiresAt = &expiresAt
+
+		mockKeyAndOrganizationGetter := new(mocks.Database)
+		mockKeyAndOrganizationGetter.On("GetApiKeyByHash", ctx, keyHash).
+			Return(expiredApiKey, nil)
+
+		v := Validator{
+			getter: mockKeyAndOrganizationGetter,
+			clock:  clock.NewMock(now),
+		}
+
+		_, err := v.Validate(ctx, "", key)
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+		mockKeyAndOrganizationGetter.AssertExpectations(t)
+	})
+
+	t.Run("GetOrganizationByID error", func(t *testing.T) {
 		mockKeyAndOrganizationGetter := new(mocks.Database)
 		mockKeyAndOrganizationGetter.On("GetApiKeyByHash", ctx, keyHash).
 			Return(apiKey, nil)
@@ -79,6 +135,7 @@ func TestValidator_Validate_APIKey(t *testing.T) {
 
 		v := Validator{
 			getter: mockKeyAndOrganizationGetter,
+			clock:  clock.NewMock(now),
 		}
 
 		_, err := v.Validate(ctx, "", key)
@@ -128,3 +185,131 @@ func TestValidator_Validate_Token(t *testing.T) {
 		mockValidator.AssertExpectations(t)
 	})
 }
+
+func TestValidator_Validate_TokenOfApiKey(t *testing.T) {
+	token := "token"
+	ctx := context.Background()
+	now := time.Now()
+	creds := models.Credentials{
+		OrganizationId: "organization_id",
+		Role:           models.API_CLIENT,
+		ActorIdentity: models.Identity{
+			ApiKeyId:   "api_key_id",
+			ApiKeyName: "Api key abc*** of organization",
+		},
+	}
+	apiKey := models.ApiKey{Id: "api_key_id", OrganizationId: "organization_id"}
+
+	t.Run("nominal", func(t *testing.T) {
+		mockValidator := new(mocks.JWTEncoderValidator)
+		mockValidator.On("ValidateMarbleToken", token).
+			Return(creds, nil)
+		mockGetter := new(mocks.Database)
+		// the key is cached, it is read once for both requests
+		mockGetter.On("GetApiKeyById", ctx, "api_key_id").
+			Return(apiKey, nil).Once()
+
+		v := Validator{
+			getter:    mockGetter,
+			validator: mockValidator,
+			clock:     clock.NewMock(now),
+		}
+
+		for i := 0; i < 2; i++ {
+			credentials, err := v.Validate(ctx, token, "")
+			assert.NoError(t, err)
+			assert.Equal(t, creds, credentials)
+		}
+		mockValidator.AssertExpectations(t)
+		mockGetter.AssertExpectations(t)
+	})
+
+	t.Run("deleted api key", func(t *testing.T) {
+		mockValidator := new(mocks.JWTEncoderValidator)
+		mockValidator.On("ValidateMarbleToken", token).
+			Return(creds, nil)
+		mockGetter := new(mocks.Database)
+		mockGetter.On("GetApiKeyById", ctx, "api_key_id").
+			Return(models.ApiKey{}, models.NotFoundError)
+
+		v := Validator{
+			getter:    mockGetter,
+			validator: mockValidator,
+			clock:     clock.NewMock(now),
+		}
+
+		_, err := v.Validate(ctx, token, "")
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+		mockGetter.AssertExpectations(t)
+	})
+
+	t.Run("api key expired by a rotation", func(t *testing.T) {
+		expiresAt := now.Add(-time.Minute)
+		expiredApiKey := apiKey
+		expiredApiKey.ExpiresAt = &expiresAt
+
+		mockValidator := new(mocks.JWTEncoderValidator)
+		mockValidator.On("ValidateMarbleToken", token).
+			Return(creds, nil)
+		mockGetter := new(mocks.Database)
+		mockGetter.On("GetApiKeyById", ctx, "api_key_id").
+			Return(expiredApiKey, nil)
+
+		v := Validator{
+			getter:    mockGetter,
+			validator: mockValidator,
+			clock:     clock.NewMock(now),
+		}
+
+		_, err := v.Validate(ctx, token, "")
+		assert.ErrorIs(t, err, models.UnAuthorizedError)
+		mockGetter.AssertExpectations(t)
+	})
+}
+
+func TestValidator_FlushApiKeyUsages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	creds := models.Credentials{
+		OrganizationId: "organization_id",
+		Role:           models.API_CLIENT,
+		ActorIdentity:  models.Identity{ApiKeyId: "api_key_id"},
+	}
+	apiKey := models.ApiKey{Id: "api_key_id", OrganizationId: "organization_id"}
+
+	mockValidator := new(mocks.JWTEncoderValidator)
+	mockValidator.On("ValidateMarbleToken", "token").
+		Return(creds, nil)
+	mockGetter := new(mocks.Database)
+	mockGetter.On("GetApiKeyById", ctx, "api_key_id").
+		Return(apiKey, nil)
+
+	v := Validator{
+		getter:    mockGetter,
+		validator: mockValidator,
+		clock:     clock.NewMock(now),
+	}
+
+	// the requests are counted in memory
+	for i := 0; i < 2; i++ {
+		_, err := v.Validate(ctx, "token", "")
+		assert.NoError(t, err)
+	}
+
+	// the usage is kept if it cannot be written
+	mockGetter.On("RecordApiKeyUsages", ctx, map[string]models.ApiKeyUsage{
+		"api_key_id": {RequestCount: 2, LastUsedAt: now},
+	}).Return(assert.AnError).Once()
+	assert.Error(t, v.FlushApiKeyUsages(ctx))
+
+	_, err := v.Validate(ctx, "token", "")
+	assert.NoError(t, err)
+	mockGetter.On("RecordApiKeyUsages", ctx, map[string]models.ApiKeyUsage{
+		"api_key_id": {RequestCount: 3, LastUsedAt: now},
+	}).Return(nil).Once()
+	assert.NoError(t, v.FlushApiKeyUsages(ctx))
+
+	// nothing is written when the keys were not used
+	assert.NoError(t, v.FlushApiKeyUsages(ctx))
+	mockGetter.AssertExpectations(t)
+}
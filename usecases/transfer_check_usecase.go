@@ -61,6 +61,7 @@ type TransferCheckUsecase struct {
 	transferCheckEnrichmentRepository transferCheckEnrichmentRepository
 	transferDataReader                transferDataReader
 	partnersRepository                partnersRepository
+	webhookEventsSender               webhookEventsUsecase
 }
 
 func transfersAreDifferent(t1, t2 map[string]any) bool {
@@ -289,44 +290,58 @@ func (usecase *TransferCheckUsecase) UpdateTransfer(
 
 	var beneficiaryInNetwork bool
 	var transfersData []models.TransferData
-	err = usecase.transactionFactory.TransactionInOrgSchema(ctx, organizationId, func(tx repositories.Executor) error {
-		transfersData, err = usecase.transferDataReader.QueryTransferDataFromMapping(ctx, tx, transferMapping)
+	var webhookEventIds []string
+	// the decisions are labeled in a transaction wrapping the update of the transfer, so that they are only labeled
+	// if the transfer is updated
+	err = usecase.transactionFactory.Transaction(ctx, func(marbleTx repositories.Executor) error {
+		webhookEventIds, err = usecase.labelDecisionsOfTransfer(ctx, marbleTx, previousDecisions, transfer.Status)
 		if err != nil {
 			return err
 		}
-		if len(transfersData) == 0 {
-			return errors.Wrap(
-				models.NotFoundError,
-				fmt.Sprintf("transfer %s not found", transferMapping.ClientTransferId),
-			)
-		}
 
-		beneficiaryInNetwork, err = usecase.beneficiaryIsInNetwork(ctx, transfersData[0])
-		if err != nil {
-			return err
-		}
+		return usecase.transactionFactory.TransactionInOrgSchema(ctx, organizationId, func(tx repositories.Executor) error {
+			transfersData, err = usecase.transferDataReader.QueryTransferDataFromMapping(ctx, tx, transferMapping)
+			if err != nil {
+				return err
+			}
+			if len(transfersData) == 0 {
+				return errors.Wrap(
+					models.NotFoundError,
+					fmt.Sprintf("transfer %s not found", transferMapping.ClientTransferId),
+				)
+			}
 
-		previous := transfersData[0].ToIngestionMap(transferMapping)
-		previous["status"] = transfer.Status
-		previous["updated_at"] = time.Now()
+			beneficiaryInNetwork, err = usecase.beneficiaryIsInNetwork(ctx, transfersData[0])
+			if err != nil {
+				return err
+			}
 
-		_, err = usecase.ingestionRepository.IngestObjects(ctx, tx, []models.ClientObject{
-			{Data: previous, TableName: models.TransferCheckTable},
-		}, table)
-		if err != nil {
-			return err
-		}
+			previous := transfersData[0].ToIngestionMap(transferMapping)
+			previous["status"] = transfer.Status
+			previous["updated_at"] = time.Now()
 
-		transfersData, err = usecase.transferDataReader.QueryTransferDataFromMapping(ctx, tx, transferMapping)
-		if err != nil {
-			return err
-		}
-		return nil
+			_, err = usecase.ingestionRepository.IngestObjects(ctx, tx, []models.ClientObject{
+				{Data: previous, TableName: models.TransferCheckTable},
+			}, table)
+			if err != nil {
+				return err
+			}
+
+			transfersData, err = usecase.transferDataReader.QueryTransferDataFromMapping(ctx, tx, transferMapping)
+			if err != nil {
+				return err
+			}
+			return nil
+		})
 	})
 	if err != nil {
 		return models.Transfer{}, err
 	}
 
+	for _, webhookEventId := range webhookEventIds {
+		usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	}
+
 	out := models.Transfer{
 		Id:                   id,
 		TransferData:         transfersData[0],
@@ -340,6 +355,47 @@ func (usecase *TransferCheckUsecase) UpdateTransfer(
 	return out, nil
 }
 
+// labelDecisionsOfTransfer records the final status of the transfer as a review of its decisions, so that the transfer
+// statuses set by the partners are used to measure the performance of the rules. The decisions already reviewed by a
+// user are left untouched. It returns the ids of the decision.reviewed webhook events to send once the transaction is
+// committed.
+func (usecase *TransferCheckUsecase) labelDecisionsOfTransfer(
+	ctx context.Context,
+	tx repositories.Executor,
+	decisions []models.DecisionCore,
+	status string,
+) ([]string, error) {
+	disposition, ok := models.TransferStatusReviewDisposition(status)
+	if !ok || len(decisions) == 0 {
+		return nil, nil
+	}
+
+	review := models.DecisionReview{Disposition: disposition, ReviewedAt: time.Now()}
+	decisionIds := make([]string, len(decisions))
+	for i, decision := range decisions {
+		decisionIds[i] = decision.DecisionId
+	}
+	labeledDecisionIds, err := usecase.decisionRepository.LabelDecisions(ctx, tx, decisionIds, review)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationId := decisions[0].OrganizationId
+	webhookEventIds := make([]string, len(labeledDecisionIds))
+	for i, decisionId := range labeledDecisionIds {
+		webhookEventIds[i] = uuid.NewString()
+		err := usecase.webhookEventsSender.CreateWebhookEvent(ctx, tx, models.WebhookEventCreate{
+			Id:             webhookEventIds[i],
+			OrganizationId: organizationId,
+			EventContent:   models.NewWebhookEventDecisionReviewed(decisionId, review),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return webhookEventIds, nil
+}
+
 func validateTranferUpdate(transfer models.TransferUpdateBody) error {
 	if !slices.Contains(models.TransferStatuses, transfer.Status) {
 		return errors.Wrap(
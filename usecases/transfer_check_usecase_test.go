@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type TransferCheckUsecaseTestSuite struct {
+	suite.Suite
+	decisionRepository  *mocks.DecisionRepository
+	webhookEventsSender *mocks.WebhookEventsSender
+	transaction         *mocks.Executor
+
+	ctx       context.Context
+	decisions []models.DecisionCore
+}
+
+func (suite *TransferCheckUsecaseTestSuite) SetupTest() {
+	suite.decisionRepository = new(mocks.DecisionRepository)
+	suite.webhookEventsSender = new(mocks.WebhookEventsSender)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.decisions = []models.DecisionCore{
+		{DecisionId: "decision_1", OrganizationId: "organization_id"},
+		{DecisionId: "decision_2", OrganizationId: "organization_id"},
+	}
+}
+
+func (suite *TransferCheckUsecaseTestSuite) makeUsecase() *TransferCheckUsecase {
+	return &TransferCheckUsecase{
+		decisionRepository:  suite.decisionRepository,
+		webhookEventsSender: suite.webhookEventsSender,
+	}
+}
+
+func (suite *TransferCheckUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.decisionRepository.AssertExpectations(t)
+	suite.webhookEventsSender.AssertExpectations(t)
+}
+
+func (suite *TransferCheckUsecaseTestSuite) TestLabelDecisionsOfTransfer() {
+	isAutomaticReview := mock.MatchedBy(func(review models.DecisionReview) bool {
+		return review.Disposition == models.DecisionReviewConfirmedFraud && review.ReviewedBy == nil
+	})
+	// the second decision was already reviewed by a user
+	suite.decisionRepository.On("LabelDecisions", suite.transaction, []string{"decision_1", "decision_2"},
+		isAutomaticReview).Return([]string{"decision_1"}, nil)
+	suite.webhookEventsSender.On("CreateWebhookEvent", suite.transaction,
+		mock.MatchedBy(func(input models.WebhookEventCreate) bool {
+			content := input.EventContent.Data["content"].(map[string]any)["decision"].(map[string]any)
+			return input.OrganizationId == "organization_id" &&
+				input.EventContent.Type == models.WebhookEventType_DecisionReviewed &&
+				content["id"] == "decision_1"
+		})).Return(nil).Once()
+
+	webhookEventIds, err := suite.makeUsecase().labelDecisionsOfTransfer(suite.ctx, suite.transaction,
+		suite.decisions, models.TransferStatusConfirmedFraud)
+	suite.NoError(err)
+	suite.Len(webhookEventIds, 1)
+	suite.AssertExpectations()
+}
+
+func (suite *TransferCheckUsecaseTestSuite) TestLabelDecisionsOfTransfer_notFinalStatus() {
+	for _, status := range []string{models.TransferStatusNeutral, models.TransferStatusSuspectedFraud} {
+		suite.Run(status, func() {
+			webhookEventIds, err := suite.makeUsecase().labelDecisionsOfTransfer(suite.ctx, suite.transaction,
+				suite.decisions, status)
+			suite.NoError(err)
+			suite.Empty(webhookEventIds)
+			suite.decisionRepository.AssertNotCalled(suite.T(), "LabelDecisions",
+				mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransferCheckUsecase(t *testing.T) {
+	suite.Run(t, new(TransferCheckUsecaseTestSuite))
+}
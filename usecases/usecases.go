@@ -1,28 +1,41 @@
 package usecases
 
 import (
+	"time"
+
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/models/ast"
 	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/repositories/clock"
 	"github.com/checkmarble/marble-backend/usecases/ast_eval"
 	"github.com/checkmarble/marble-backend/usecases/ast_eval/evaluate"
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/jobqueue"
 	"github.com/checkmarble/marble-backend/usecases/organization"
+	"github.com/checkmarble/marble-backend/usecases/ratelimit"
 	"github.com/checkmarble/marble-backend/usecases/scenarios"
 	"github.com/checkmarble/marble-backend/usecases/scheduledexecution"
 	"github.com/checkmarble/marble-backend/usecases/security"
+	"github.com/checkmarble/marble-backend/usecases/webhooks"
 )
 
 type Usecases struct {
 	Repositories                repositories.Repositories
 	fakeAwsS3Repository         bool
-	fakeGcsRepository           bool
 	gcsIngestionBucket          string
 	gcsCaseManagerBucket        string
 	failedWebhooksRetryPageSize int
 	license                     models.LicenseValidation
+	schedulerTimezone           string
+	webhookDeliveryBackend      models.WebhookDeliveryBackend
+	webhooksAllowPrivateNetwork bool
+	idempotencyKeyRetention     time.Duration
+	rateLimiter                 *ratelimit.Limiter
 }
 
+// Timezone used by the scheduler for the scenarios that do not define the timezone of their schedule
+const DEFAULT_SCHEDULER_TIMEZONE = "Europe/Paris"
+
 type Option func(*options)
 
 func WithFakeAwsS3Repository(b bool) Option {
@@ -31,12 +44,6 @@ func WithFakeAwsS3Repository(b bool) Option {
 	}
 }
 
-func WithFakeGcsRepository(b bool) Option {
-	return func(o *options) {
-		o.fakeGcsRepository = b
-	}
-}
-
 func WithGcsIngestionBucket(bucket string) Option {
 	return func(o *options) {
 		o.gcsIngestionBucket = bucket
@@ -61,24 +68,79 @@ func WithLicense(license models.LicenseValidation) Option {
 	}
 }
 
+func WithSchedulerTimezone(timezone string) Option {
+	return func(o *options) {
+		o.schedulerTimezone = timezone
+	}
+}
+
+func WithWebhookDeliveryBackend(backend models.WebhookDeliveryBackend) Option {
+	return func(o *options) {
+		o.webhookDeliveryBackend = backend
+	}
+}
+
+// WithWebhooksAllowPrivateNetwork lets the built-in webhook dispatcher deliver events to private network addresses
+func WithWebhooksAllowPrivateNetwork(allow bool) Option {
+	return func(o *options) {
+		o.webhooksAllowPrivateNetwork = allow
+	}
+}
+
+func WithIdempotencyKeyRetention(retention time.Duration) Option {
+	return func(o *options) {
+		o.idempotencyKeyRetention = retention
+	}
+}
+
+func WithRateLimitConfiguration(configuration models.RateLimitConfiguration) Option {
+	return func(o *options) {
+		o.rateLimitConfiguration = configuration
+	}
+}
+
 type options struct {
 	fakeAwsS3Repository         bool
-	fakeGcsRepository           bool
 	gcsIngestionBucket          string
 	gcsCaseManagerBucket        string
 	failedWebhooksRetryPageSize int
 	license                     models.LicenseValidation
+	schedulerTimezone           string
+	webhookDeliveryBackend      models.WebhookDeliveryBackend
+	webhooksAllowPrivateNetwork bool
+	idempotencyKeyRetention     time.Duration
+	rateLimitConfiguration      models.RateLimitConfiguration
 }
 
 func newUsecasesWithOptions(repositories repositories.Repositories, o *options) Usecases {
+	if o.schedulerTimezone == "" {
+		o.schedulerTimezone = DEFAULT_SCHEDULER_TIMEZONE
+	}
+	if o.idempotencyKeyRetention == 0 {
+		o.idempotencyKeyRetention = models.DEFAULT_IDEMPOTENCY_KEY_RETENTION
+	}
+	executorFactory := executor_factory.NewDbExecutorFactory(
+		repositories.OrganizationRepository,
+		repositories.ExecutorGetter,
+	)
+	// the counters are shared by all the copies of the usecases, like the token buckets of the limiter
+	var rateLimitCounter ratelimit.Counter = ratelimit.NewMemoryCounter()
+	if o.rateLimitConfiguration.Backend == models.RateLimitBackendPostgres {
+		rateLimitCounter = ratelimit.NewPostgresCounter(executorFactory, &repositories.MarbleDbRepository)
+	}
 	return Usecases{
 		Repositories:                repositories,
 		fakeAwsS3Repository:         o.fakeAwsS3Repository,
-		fakeGcsRepository:           o.fakeGcsRepository,
 		gcsIngestionBucket:          o.gcsIngestionBucket,
 		gcsCaseManagerBucket:        o.gcsCaseManagerBucket,
 		failedWebhooksRetryPageSize: o.failedWebhooksRetryPageSize,
 		license:                     o.license,
+		schedulerTimezone:           o.schedulerTimezone,
+		webhookDeliveryBackend:      o.webhookDeliveryBackend,
+		webhooksAllowPrivateNetwork: o.webhooksAllowPrivateNetwork,
+		idempotencyKeyRetention:     o.idempotencyKeyRetention,
+		rateLimiter: ratelimit.NewLimiter(o.rateLimitConfiguration, executorFactory,
+			&repositories.MarbleDbRepository, rateLimitCounter, clock.New()),
 	}
 }
 
@@ -124,6 +186,31 @@ func (usecases *Usecases) NewOrganizationCreator() organization.OrganizationCrea
 	}
 }
 
+func (usecases *Usecases) NewJobQueueWorker() *jobqueue.Worker {
+	return jobqueue.NewWorker(
+		&usecases.Repositories.MarbleDbRepository,
+		usecases.NewExecutorFactory(),
+	)
+}
+
+// webhookDeliveryBackend is implemented by Convoy and by the built-in webhook dispatcher
+type webhookDeliveryBackend interface {
+	webhooksBackend
+	webhookEventSender
+}
+
+func (usecases *Usecases) newWebhookDeliveryBackend() webhookDeliveryBackend {
+	if usecases.webhookDeliveryBackend == models.WebhookDeliveryBackendBuiltin {
+		return webhooks.NewDispatcher(
+			&usecases.Repositories.MarbleDbRepository,
+			usecases.NewExecutorFactory(),
+			usecases.NewTransactionFactory(),
+			usecases.webhooksAllowPrivateNetwork,
+		)
+	}
+	return usecases.Repositories.ConvoyRepository
+}
+
 func (usecases *Usecases) NewExportScheduleExecution() *scheduledexecution.ExportScheduleExecution {
 	var awsS3Repository scheduledexecution.AwsS3Repository
 	if usecases.fakeAwsS3Repository {
@@ -222,3 +309,31 @@ func (usecases *Usecases) NewLicenseUsecase() PublicLicenseUseCase {
 		licenseRepository: &usecases.Repositories.MarbleDbRepository,
 	}
 }
+
+func (usecases *Usecases) NewBlobDownloadUsecase() BlobDownloadUsecase {
+	return BlobDownloadUsecase{
+		blobRepository: usecases.Repositories.BlobRepository,
+		urlSigner:      usecases.Repositories.BlobUrlSigner,
+	}
+}
+
+func (usecases *Usecases) NewSsoLoginUsecase() SsoLoginUsecase {
+	return SsoLoginUsecase{
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
+		userRepository:     usecases.Repositories.UserRepository,
+		oidcClient:         usecases.Repositories.OidcRepository,
+		hasLicense:         usecases.license.Sso,
+	}
+}
+
+func (usecases *Usecases) NewScimUsecase() ScimUsecase {
+	return ScimUsecase{
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
+		userRepository:     usecases.Repositories.UserRepository,
+		hasLicense:         usecases.license.Sso,
+	}
+}
@@ -2,7 +2,6 @@ package usecases
 
 import (
 	"github.com/checkmarble/marble-backend/models"
-	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases/decision_workflows"
 	"github.com/checkmarble/marble-backend/usecases/inboxes"
 	"github.com/checkmarble/marble-backend/usecases/indexes"
@@ -87,6 +86,32 @@ func (usecases *UsecasesWithCreds) NewDecisionUsecase() DecisionUsecase {
 		decisionWorkflows:          usecases.NewDecisionWorkflows(),
 		webhookEventsSender:        usecases.NewWebhookEventsUsecase(),
 		snoozesReader:              &usecases.Repositories.MarbleDbRepository,
+		idempotencyKeyRepository:   &usecases.Repositories.MarbleDbRepository,
+		idempotencyKeyRetention:    usecases.idempotencyKeyRetention,
+		decisionPolicyRepository:   &usecases.Repositories.MarbleDbRepository,
+		asyncDecisionRepository:    &usecases.Repositories.MarbleDbRepository,
+		jobEnqueuer:                &usecases.Repositories.MarbleDbRepository,
+		jobQueueWorker:             usecases.NewJobQueueWorker(),
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewRulePerformanceUsecase() RulePerformanceUsecase {
+	return RulePerformanceUsecase{
+		enforceSecurity: usecases.NewEnforceScenarioSecurity(),
+		executorFactory: usecases.NewExecutorFactory(),
+		repository:      &usecases.Repositories.MarbleDbRepository,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewDecisionPolicyUsecase() DecisionPolicyUsecase {
+	return DecisionPolicyUsecase{
+		enforceSecurity: &security.EnforceSecurityDecisionPolicyImpl{
+			EnforceSecurity: usecases.NewEnforceSecurity(),
+			Credentials:     usecases.Credentials,
+		},
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
 	}
 }
 
@@ -120,6 +145,31 @@ func (usecases *UsecasesWithCreds) NewScenarioIterationUsecase() ScenarioIterati
 	}
 }
 
+func (usecases *UsecasesWithCreds) NewScenarioBundleUsecase() ScenarioBundleUsecase {
+	return ScenarioBundleUsecase{
+		enforceSecurity:           usecases.NewEnforceScenarioSecurity(),
+		enforceSecurityCustomList: usecases.NewEnforceCustomListSecurity(),
+		executorFactory:           usecases.NewExecutorFactory(),
+		transactionFactory:        usecases.NewTransactionFactory(),
+		repository:                &usecases.Repositories.MarbleDbRepository,
+		customListRepository:      usecases.Repositories.CustomListRepository,
+		dataModelRepository:       usecases.Repositories.DataModelRepository,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewScenarioTemplateUsecase() ScenarioTemplateUsecase {
+	return ScenarioTemplateUsecase{
+		enforceSecurity:           usecases.NewEnforceScenarioSecurity(),
+		enforceSecurityCustomList: usecases.NewEnforceCustomListSecurity(),
+		executorFactory:           usecases.NewExecutorFactory(),
+		transactionFactory:        usecases.NewTransactionFactory(),
+		repository:                &usecases.Repositories.MarbleDbRepository,
+		customListRepository:      usecases.Repositories.CustomListRepository,
+		scenarioBundleUsecase:     usecases.NewScenarioBundleUsecase(),
+		validateScenarioIteration: usecases.NewValidateScenarioIteration(),
+	}
+}
+
 func (usecases *UsecasesWithCreds) NewRuleUsecase() RuleUsecase {
 	return RuleUsecase{
 		organizationIdOfContext: usecases.OrganizationIdOfContext,
@@ -194,25 +244,37 @@ func (usecases *UsecasesWithCreds) NewDataModelUseCase() DataModelUseCase {
 		executorFactory:              usecases.NewExecutorFactory(),
 		organizationSchemaRepository: usecases.Repositories.OrganizationSchemaRepository,
 		transactionFactory:           usecases.NewTransactionFactory(),
+		scenarioRepository:           &usecases.Repositories.MarbleDbRepository,
 	}
 }
 
-func (usecases *UsecasesWithCreds) NewIngestionUseCase() IngestionUseCase {
-	var gcsRepository repositories.GcsRepository
-	if usecases.fakeGcsRepository {
-		gcsRepository = &repositories.GcsRepositoryFake{}
-	} else {
-		gcsRepository = usecases.Repositories.GcsRepository
+func (usecases *UsecasesWithCreds) NewOrganizationConfigUsecase() OrganizationConfigUsecase {
+	return OrganizationConfigUsecase{
+		enforceSecurity:           usecases.NewEnforceOrganizationSecurity(),
+		enforceSecurityCustomList: usecases.NewEnforceCustomListSecurity(),
+		executorFactory:           usecases.NewExecutorFactory(),
+		transactionFactory:        usecases.NewTransactionFactory(),
+		repository:                &usecases.Repositories.MarbleDbRepository,
+		customListRepository:      usecases.Repositories.CustomListRepository,
+		dataModelUsecase:          usecases.NewDataModelUseCase(),
+		inboxUsecase:              usecases.NewInboxUsecase(),
+		tagUsecase:                usecases.NewTagUseCase(),
+		scenarioUsecase:           usecases.NewScenarioUsecase(),
+		webhooksUsecase:           usecases.NewWebhooksUsecase(),
 	}
+}
 
+func (usecases *UsecasesWithCreds) NewIngestionUseCase() IngestionUseCase {
 	return IngestionUseCase{
 		enforceSecurity:     usecases.NewEnforceIngestionSecurity(),
 		transactionFactory:  usecases.NewTransactionFactory(),
 		executorFactory:     usecases.NewExecutorFactory(),
 		ingestionRepository: usecases.Repositories.IngestionRepository,
-		gcsRepository:       gcsRepository,
+		blobRepository:      usecases.Repositories.BlobRepository,
 		dataModelRepository: usecases.Repositories.DataModelRepository,
 		uploadLogRepository: usecases.Repositories.UploadLogRepository,
+		jobEnqueuer:         &usecases.Repositories.MarbleDbRepository,
+		jobQueueWorker:      usecases.NewJobQueueWorker(),
 		GcsIngestionBucket:  usecases.gcsIngestionBucket,
 	}
 }
@@ -231,6 +293,8 @@ func (usecases *UsecasesWithCreds) NewRunScheduledExecution() scheduledexecution
 		usecases.NewDecisionWorkflows(),
 		usecases.NewWebhookEventsUsecase(),
 		&usecases.Repositories.MarbleDbRepository,
+		usecases.NewJobQueueWorker(),
+		usecases.schedulerTimezone,
 	)
 }
 
@@ -254,12 +318,6 @@ func (usecases *UsecasesWithCreds) NewUserUseCase() UserUseCase {
 }
 
 func (usecases *UsecasesWithCreds) NewCaseUseCase() *CaseUseCase {
-	var gcsRepository repositories.GcsRepository
-	if usecases.fakeGcsRepository {
-		gcsRepository = &repositories.GcsRepositoryFake{}
-	} else {
-		gcsRepository = usecases.Repositories.GcsRepository
-	}
 	sec := security.EnforceSecurityInboxes{
 		EnforceSecurity: usecases.NewEnforceSecurity(),
 		Credentials:     usecases.Credentials,
@@ -278,7 +336,7 @@ func (usecases *UsecasesWithCreds) NewCaseUseCase() *CaseUseCase {
 			ExecutorFactory:         usecases.NewExecutorFactory(),
 		},
 		gcsCaseManagerBucket: usecases.gcsCaseManagerBucket,
-		gcsRepository:        gcsRepository,
+		blobRepository:       usecases.Repositories.BlobRepository,
 		webhookEventsUsecase: usecases.NewWebhookEventsUsecase(),
 	}
 }
@@ -338,12 +396,15 @@ func (usecases *UsecasesWithCreds) NewTagUseCase() TagUseCase {
 func (usecases *UsecasesWithCreds) NewApiKeyUseCase() ApiKeyUseCase {
 	return ApiKeyUseCase{
 		executorFactory:         usecases.NewExecutorFactory(),
+		transactionFactory:      usecases.NewTransactionFactory(),
 		organizationIdOfContext: usecases.OrganizationIdOfContext,
 		enforceSecurity: &security.EnforceSecurityApiKeyImpl{
 			EnforceSecurity: usecases.NewEnforceSecurity(),
 			Credentials:     usecases.Credentials,
 		},
-		apiKeyRepository: &usecases.Repositories.MarbleDbRepository,
+		apiKeyRepository:    &usecases.Repositories.MarbleDbRepository,
+		scenarioRepository:  &usecases.Repositories.MarbleDbRepository,
+		dataModelRepository: usecases.Repositories.DataModelRepository,
 	}
 }
 
@@ -372,6 +433,7 @@ func (usecases *UsecasesWithCreds) NewTransferCheckUsecase() TransferCheckUsecas
 		transferCheckEnrichmentRepository: usecases.Repositories.TransferCheckEnrichmentRepository,
 		transferDataReader:                usecases.NewTransferDataReader(),
 		partnersRepository:                usecases.Repositories.MarbleDbRepository,
+		webhookEventsSender:               usecases.NewWebhookEventsUsecase(),
 	}
 }
 
@@ -419,8 +481,10 @@ func (usecases *UsecasesWithCreds) NewWebhookEventsUsecase() WebhookEventsUsecas
 	return NewWebhookEventsUsecase(
 		security.NewEnforceSecurity(usecases.Credentials),
 		usecases.NewExecutorFactory(),
-		usecases.Repositories.ConvoyRepository,
+		usecases.newWebhookDeliveryBackend(),
 		usecases.Repositories.MarbleDbRepository,
+		&usecases.Repositories.MarbleDbRepository,
+		usecases.NewJobQueueWorker(),
 		usecases.Usecases.failedWebhooksRetryPageSize,
 		usecases.Usecases.license.Webhooks,
 	)
@@ -431,7 +495,10 @@ func (usecases *UsecasesWithCreds) NewWebhooksUsecase() WebhooksUsecase {
 		security.NewEnforceSecurity(usecases.Credentials),
 		usecases.NewExecutorFactory(),
 		usecases.NewTransactionFactory(),
-		usecases.Repositories.ConvoyRepository,
+		usecases.newWebhookDeliveryBackend(),
+		usecases.Repositories.MarbleDbRepository,
+		&usecases.Repositories.MarbleDbRepository,
+		usecases.NewWebhookEventsUsecase(),
 	)
 }
 
@@ -448,3 +515,52 @@ func (usecases *UsecasesWithCreds) NewRuleSnoozeUsecase() RuleSnoozeUsecase {
 		usecases.NewWebhookEventsUsecase(),
 	)
 }
+
+func (usecases *UsecasesWithCreds) NewSsoProviderUsecase() SsoProviderUsecase {
+	return SsoProviderUsecase{
+		enforceSecurity:    usecases.NewEnforceOrganizationSecurity(),
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
+		hasLicense:         usecases.Usecases.license.Sso,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewScimConfigUsecase() ScimConfigUsecase {
+	return ScimConfigUsecase{
+		enforceSecurity:    usecases.NewEnforceOrganizationSecurity(),
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
+		userRepository:     usecases.Repositories.UserRepository,
+		hasLicense:         usecases.Usecases.license.Sso,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewCustomRoleUsecase() CustomRoleUsecase {
+	return CustomRoleUsecase{
+		enforceSecurity:    usecases.NewEnforceOrganizationSecurity(),
+		executorFactory:    usecases.NewExecutorFactory(),
+		transactionFactory: usecases.NewTransactionFactory(),
+		repository:         &usecases.Repositories.MarbleDbRepository,
+		userRepository:     usecases.Repositories.UserRepository,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewRateLimitUsecase() RateLimitUsecase {
+	return RateLimitUsecase{
+		enforceSecurity: usecases.NewEnforceOrganizationSecurity(),
+		executorFactory: usecases.NewExecutorFactory(),
+		repository:      &usecases.Repositories.MarbleDbRepository,
+		limiter:         usecases.rateLimiter,
+		credentials:     usecases.Credentials,
+	}
+}
+
+func (usecases *UsecasesWithCreds) NewAuditEventUsecase() AuditEventUsecase {
+	return AuditEventUsecase{
+		enforceSecurity: usecases.NewEnforceOrganizationSecurity(),
+		executorFactory: usecases.NewExecutorFactory(),
+		repository:      &usecases.Repositories.MarbleDbRepository,
+	}
+}
@@ -8,31 +8,29 @@ import (
 	"github.com/checkmarble/marble-backend/models"
 	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/usecases/jobqueue"
+	"github.com/checkmarble/marble-backend/usecases/webhooks"
 	"github.com/checkmarble/marble-backend/utils"
 	"github.com/guregu/null/v5"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/sync/errgroup"
 )
 
 const (
 	MAX_CONCURRENT_WEBHOOKS_SENT      = 20
-	WEBHOOKS_SEND_MAX_RETRIES         = 24
 	DEFAULT_FAILED_WEBHOOKS_PAGE_SIZE = 1000
+	// delay before the first retry of a webhook event, if sending it right after its creation failed
+	WEBHOOK_FIRST_RETRY_DELAY = 1 * time.Minute
 )
 
-type convoyWebhookEventRepository interface {
+// webhookEventSender delivers webhook events: Convoy, or the built-in webhook dispatcher
+type webhookEventSender interface {
 	SendWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error
 }
 
 type webhookEventsRepository interface {
 	GetWebhookEvent(ctx context.Context, exec repositories.Executor, webhookEventId string) (models.WebhookEvent, error)
-	ListWebhookEvents(
-		ctx context.Context,
-		exec repositories.Executor,
-		filters models.WebhookEventFilters,
-	) ([]models.WebhookEvent, error)
 	CreateWebhookEvent(
 		ctx context.Context,
 		exec repositories.Executor,
@@ -52,8 +50,10 @@ type enforceSecurityWebhookEvents interface {
 type WebhookEventsUsecase struct {
 	enforceSecurity             enforceSecurityWebhookEvents
 	executorFactory             executor_factory.ExecutorFactory
-	convoyRepository            convoyWebhookEventRepository
+	webhookEventSender          webhookEventSender
 	webhookEventsRepository     webhookEventsRepository
+	jobEnqueuer                 jobEnqueuer
+	jobQueueWorker              jobQueueWorker
 	failedWebhooksRetryPageSize int
 	hasLicense                  bool
 }
@@ -61,8 +61,10 @@ type WebhookEventsUsecase struct {
 func NewWebhookEventsUsecase(
 	enforceSecurity enforceSecurityWebhookEvents,
 	executorFactory executor_factory.ExecutorFactory,
-	convoyRepository convoyWebhookEventRepository,
+	webhookEventSender webhookEventSender,
 	webhookEventsRepository webhookEventsRepository,
+	jobEnqueuer jobEnqueuer,
+	jobQueueWorker jobQueueWorker,
 	failedWebhooksRetryPageSize int,
 	hasLicense bool,
 ) WebhookEventsUsecase {
@@ -73,8 +75,10 @@ func NewWebhookEventsUsecase(
 	return WebhookEventsUsecase{
 		enforceSecurity:             enforceSecurity,
 		executorFactory:             executorFactory,
-		convoyRepository:            convoyRepository,
+		webhookEventSender:          webhookEventSender,
 		webhookEventsRepository:     webhookEventsRepository,
+		jobEnqueuer:                 jobEnqueuer,
+		jobQueueWorker:              jobQueueWorker,
 		failedWebhooksRetryPageSize: failedWebhooksRetryPageSize,
 		hasLicense:                  hasLicense,
 	}
@@ -99,7 +103,14 @@ func (usecase WebhookEventsUsecase) CreateWebhookEvent(
 	if err != nil {
 		return errors.Wrap(err, "error creating webhook event")
 	}
-	return nil
+
+	// the event is sent right after the transaction is committed, the job only handles retries if sending fails
+	err = usecase.jobEnqueuer.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+		Kind:     models.JobKindWebhookEvent,
+		EntityId: input.Id,
+		RunAt:    utils.Ptr(time.Now().Add(WEBHOOK_FIRST_RETRY_DELAY)),
+	})
+	return errors.Wrap(err, "error enqueuing webhook event job")
 }
 
 // SendWebhookEventAsync sends a webhook event asynchronously, with a new context and timeout and a child span.
@@ -123,14 +134,15 @@ func (usecase WebhookEventsUsecase) SendWebhookEventAsync(ctx context.Context, w
 			trace.WithAttributes(attribute.String("webhook_event_id", webhookEventId)))
 		defer span.End()
 
-		_, err := usecase._sendWebhookEvent(ctx, webhookEventId)
+		_, _, err := usecase._sendWebhookEvent(ctx, webhookEventId)
 		if err != nil {
 			logger.ErrorContext(ctx, fmt.Sprintf("Error sending webhook event %s: %s", webhookEventId, err.Error()))
 		}
 	}()
 }
 
-// RetrySendWebhookEvents retries sending webhook events that have failed to be sent.
+// RetrySendWebhookEvents claims webhook event jobs from the job queue and retries sending the events that have
+// failed to be sent. Events that still fail are retried later with an exponential backoff.
 // Does nothing if the license is not active
 func (usecase WebhookEventsUsecase) RetrySendWebhookEvents(
 	ctx context.Context,
@@ -139,96 +151,70 @@ func (usecase WebhookEventsUsecase) RetrySendWebhookEvents(
 		return nil
 	}
 
-	logger := utils.LoggerFromContext(ctx)
-	exec := usecase.executorFactory.NewExecutor()
-
-	pendingWebhookEvents, err := usecase.webhookEventsRepository.ListWebhookEvents(ctx, exec, models.WebhookEventFilters{
-		DeliveryStatus: []models.WebhookEventDeliveryStatus{models.Scheduled, models.Retry},
-		Limit:          uint64(usecase.failedWebhooksRetryPageSize),
-	})
-	if err != nil {
-		return errors.Wrap(err, "error while listing pending webhook events")
-	}
-	logger.InfoContext(ctx, fmt.Sprintf("Found %d webhook events to send", len(pendingWebhookEvents)))
-	if len(pendingWebhookEvents) == 0 {
-		return nil
-	}
-
-	group, ctx := errgroup.WithContext(ctx)
-	group.SetLimit(MAX_CONCURRENT_WEBHOOKS_SENT)
-
-	deliveryStatuses := make([]models.WebhookEventDeliveryStatus, len(pendingWebhookEvents))
-
-	for i, webhookEvent := range pendingWebhookEvents {
-		group.Go(func() error {
-			ctx := utils.StoreLoggerInContext(
-				ctx,
-				logger.With("webhook_event_id", webhookEvent.Id))
-
-			select {
-			case <-ctx.Done():
-				return errors.Wrapf(ctx.Err(), "context cancelled before retrying webhook %s", webhookEvent.Id)
-			default:
+	return usecase.jobQueueWorker.ProcessJobs(
+		ctx,
+		models.JobKindWebhookEvent,
+		usecase.failedWebhooksRetryPageSize,
+		MAX_CONCURRENT_WEBHOOKS_SENT,
+		func(ctx context.Context, job models.Job) error {
+			deliveryStatus, rateLimited, err := usecase._sendWebhookEvent(ctx, job.EntityId)
+			if err != nil {
+				return err
 			}
-
-			deliveryStatus, err := usecase._sendWebhookEvent(ctx, webhookEvent.Id)
-			deliveryStatuses[i] = deliveryStatus
-			return err
-		})
-	}
-
-	err = group.Wait()
-	if err != nil {
-		return errors.Wrap(err, "error while sending webhook events")
-	}
-
-	successCount := 0
-	retryCount := 0
-	for _, status := range deliveryStatuses {
-		switch status {
-		case models.Success:
-			successCount++
-		case models.Retry:
-			retryCount++
-		}
-	}
-	logger.InfoContext(ctx, fmt.Sprintf("Webhook events sent: %d success, %d retry out of %d events",
-		successCount, retryCount, len(pendingWebhookEvents)))
-
-	return nil
+			if rateLimited {
+				// the delivery was not attempted, it does not use an attempt of the job
+				return errors.Wrapf(jobqueue.ErrPostponed, "webhook event %s is rate limited", job.EntityId)
+			}
+			if deliveryStatus != models.Success && deliveryStatus != models.DeadLetter {
+				return errors.Wrapf(jobqueue.ErrRetryLater, "webhook event %s could not be delivered", job.EntityId)
+			}
+			return nil
+		},
+	)
 }
 
-// _sendWebhookEvent actually sends a webhook event and updates its status in the database.
-func (usecase WebhookEventsUsecase) _sendWebhookEvent(ctx context.Context, webhookEventId string) (models.WebhookEventDeliveryStatus, error) {
+// _sendWebhookEvent actually sends a webhook event and updates its status in the database. Also returns true if the
+// event was not delivered only because of the rate limits of its endpoints.
+func (usecase WebhookEventsUsecase) _sendWebhookEvent(
+	ctx context.Context,
+	webhookEventId string,
+) (models.WebhookEventDeliveryStatus, bool, error) {
 	logger := utils.LoggerFromContext(ctx)
 	exec := usecase.executorFactory.NewExecutor()
 	webhookEvent, err := usecase.webhookEventsRepository.GetWebhookEvent(ctx, exec, webhookEventId)
 	if err != nil {
-		return models.Scheduled, err
+		return models.Scheduled, false, err
 	}
-	if webhookEvent.DeliveryStatus == models.Success {
-		return webhookEvent.DeliveryStatus, nil
+	if webhookEvent.DeliveryStatus == models.Success || webhookEvent.DeliveryStatus == models.DeadLetter {
+		return webhookEvent.DeliveryStatus, false, nil
 	}
 
 	err = usecase.enforceSecurity.SendWebhookEvent(ctx, webhookEvent.OrganizationId, webhookEvent.PartnerId)
 	if err != nil {
-		return models.Scheduled, err
+		return models.Scheduled, false, err
 	}
 
 	logger.InfoContext(ctx, fmt.Sprintf("Start processing webhook event %s", webhookEvent.Id))
 
 	webhookEventUpdate := models.WebhookEventUpdate{Id: webhookEvent.Id}
 
-	err = usecase.convoyRepository.SendWebhookEvent(ctx, webhookEvent)
+	err = usecase.webhookEventSender.SendWebhookEvent(ctx, webhookEvent)
+	rateLimited := errors.Is(err, webhooks.ErrDeliveryRateLimited)
 	if err == nil {
 		webhookEventUpdate.DeliveryStatus = models.Success
+	} else if rateLimited {
+		logger.InfoContext(ctx, fmt.Sprintf("Webhook event %s postponed: %s", webhookEvent.Id, err.Error()))
+		webhookEventUpdate.DeliveryStatus = models.Retry
+	} else if errors.Is(err, webhooks.ErrDeliveryDeadLettered) {
+		logger.WarnContext(ctx, fmt.Sprintf("Webhook event %s not delivered: %s", webhookEvent.Id, err.Error()))
+		webhookEventUpdate.DeliveryStatus = models.DeadLetter
 	} else {
 		logger.ErrorContext(ctx, fmt.Sprintf("Error sending webhook event %s: %s", webhookEvent.Id, err.Error()))
 		webhookEventUpdate.DeliveryStatus = models.Retry
 	}
 
 	err = usecase.webhookEventsRepository.MarkWebhookEventRetried(ctx, exec, webhookEventUpdate)
-	return webhookEventUpdate.DeliveryStatus, errors.Wrapf(
+	return webhookEventUpdate.DeliveryStatus, rateLimited, errors.Wrapf(
 		err,
 		"error while updating webhook event %s", webhookEvent.Id,
 	)
@@ -0,0 +1,360 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/guregu/null/v5"
+
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
+	"github.com/checkmarble/marble-backend/usecases/executor_factory"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+const (
+	// a delivery that has failed this many times is moved to the dead letter state and is not retried anymore
+	MAX_DELIVERY_ATTEMPTS       = 20
+	DEFAULT_HTTP_TIMEOUT        = 10 * time.Second
+	DEFAULT_RATE_LIMIT_DURATION = 1 * time.Minute
+	RESPONSE_EXCERPT_MAX_BYTES  = 1024
+	SIGNATURE_HEADER            = "X-Marble-Signature"
+	WEBHOOK_ID_HEADER           = "X-Marble-Webhook-Id"
+	WEBHOOK_EVENT_ID_HEADER     = "X-Marble-Event-Id"
+	WEBHOOK_EVENT_TYPE_HEADER   = "X-Marble-Event-Type"
+	USER_AGENT                  = "Marble-Webhooks/1.0"
+
+	generatedSecretNumberOfBytes = 32
+	errorMessageMaxLength        = 1024
+)
+
+// ErrDeliveryRateLimited is returned when a webhook event was not delivered to some of its endpoints only because
+// their rate limit is reached: the delivery was not attempted
+var ErrDeliveryRateLimited = errors.New("the delivery of the webhook event is postponed by the rate limit of its endpoint")
+
+// ErrDeliveryDeadLettered is returned when a webhook event will not be delivered to some of its endpoints, because
+// their delivery has been dead-lettered
+var ErrDeliveryDeadLettered = errors.New("the delivery of the webhook event has been dead-lettered")
+
+type DispatcherRepository interface {
+	GetWebhook(ctx context.Context, exec repositories.Executor, webhookId string) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, exec repositories.Executor, organizationId string,
+		partnerId null.String) ([]models.Webhook, error)
+	CreateWebhook(ctx context.Context, exec repositories.Executor, webhookId string, organizationId string,
+		partnerId null.String, input models.WebhookRegister) error
+	UpdateWebhook(ctx context.Context, exec repositories.Executor, input models.Webhook) error
+	DeleteWebhook(ctx context.Context, exec repositories.Executor, webhookId string) error
+
+	GetOrCreateWebhookDelivery(ctx context.Context, exec repositories.Executor, webhookEventId string,
+		webhookId string) (models.WebhookDelivery, error)
+	MarkWebhookDeliveryAttempted(ctx context.Context, exec repositories.Executor, deliveryId string,
+		status models.WebhookEventDeliveryStatus) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, exec repositories.Executor,
+		input models.WebhookDeliveryAttemptCreate) error
+	CountWebhookDeliveryAttemptsSince(ctx context.Context, exec repositories.Executor, webhookId string,
+		since time.Time) (int, error)
+}
+
+// Dispatcher is the built-in webhook delivery backend, used instead of Convoy for self-hosted deployments.
+// Webhook endpoints are stored in the Marble database, and webhook events are POSTed directly to them with an
+// HMAC-SHA256 signature. Every call is stored as a delivery attempt. A webhook event whose delivery to some endpoints
+// failed is retried by the webhook event job, with the exponential backoff of the job queue, until each delivery
+// either succeeds or reaches MAX_DELIVERY_ATTEMPTS and is dead-lettered.
+type Dispatcher struct {
+	repository         DispatcherRepository
+	executorFactory    executor_factory.ExecutorFactory
+	transactionFactory executor_factory.TransactionFactory
+	httpClient         *http.Client
+	// self-hosted deployments can deliver webhooks to the services of their own network
+	allowPrivateNetworks bool
+}
+
+func NewDispatcher(
+	repository DispatcherRepository,
+	executorFactory executor_factory.ExecutorFactory,
+	transactionFactory executor_factory.TransactionFactory,
+	allowPrivateNetworks bool,
+) Dispatcher {
+	return Dispatcher{
+		repository:           repository,
+		executorFactory:      executorFactory,
+		transactionFactory:   transactionFactory,
+		httpClient:           newEndpointHttpClient(allowPrivateNetworks),
+		allowPrivateNetworks: allowPrivateNetworks,
+	}
+}
+
+func (d Dispatcher) GetWebhook(ctx context.Context, webhookId string) (models.Webhook, error) {
+	return d.repository.GetWebhook(ctx, d.executorFactory.NewExecutor(), webhookId)
+}
+
+func (d Dispatcher) ListWebhooks(ctx context.Context, organizationId string, partnerId null.String) ([]models.Webhook, error) {
+	return d.repository.ListWebhooks(ctx, d.executorFactory.NewExecutor(), organizationId, partnerId)
+}
+
+// RegisterWebhook stores a new webhook endpoint. A signing secret is generated if none is provided.
+func (d Dispatcher) RegisterWebhook(
+	ctx context.Context,
+	organizationId string,
+	partnerId null.String,
+	input models.WebhookRegister,
+) (models.Webhook, error) {
+	if err := ValidateEndpointUrl(input.Url, d.allowPrivateNetworks); err != nil {
+		return models.Webhook{}, err
+	}
+	if input.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return models.Webhook{}, err
+		}
+		input.Secret = secret
+	}
+
+	webhookId := uuid.NewString()
+	return executor_factory.TransactionReturnValue(ctx, d.transactionFactory, func(tx repositories.Executor) (models.Webhook, error) {
+		if err := d.repository.CreateWebhook(ctx, tx, webhookId, organizationId, partnerId, input); err != nil {
+			return models.Webhook{}, err
+		}
+		return d.repository.GetWebhook(ctx, tx, webhookId)
+	})
+}
+
+func (d Dispatcher) UpdateWebhook(ctx context.Context, input models.Webhook) (models.Webhook, error) {
+	if err := ValidateEndpointUrl(input.Url, d.allowPrivateNetworks); err != nil {
+		return models.Webhook{}, err
+	}
+	return executor_factory.TransactionReturnValue(ctx, d.transactionFactory, func(tx repositories.Executor) (models.Webhook, error) {
+		if err := d.repository.UpdateWebhook(ctx, tx, input); err != nil {
+			return models.Webhook{}, err
+		}
+		return d.repository.GetWebhook(ctx, tx, input.Id)
+	})
+}
+
+func (d Dispatcher) DeleteWebhook(ctx context.Context, webhookId string) error {
+	return d.repository.DeleteWebhook(ctx, d.executorFactory.NewExecutor(), webhookId)
+}
+
+// SendWebhookEvent delivers the webhook event to all the endpoints of its owner that subscribed to its type, skipping
+// the deliveries that are already finished. Returns an error if some deliveries must be retried later,
+// ErrDeliveryRateLimited if they were only postponed by rate limits, or ErrDeliveryDeadLettered if all deliveries are
+// finished but some of them have been dead-lettered.
+func (d Dispatcher) SendWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error {
+	exec := d.executorFactory.NewExecutor()
+	webhooks, err := d.repository.ListWebhooks(ctx, exec, webhookEvent.OrganizationId, webhookEvent.PartnerId)
+	if err != nil {
+		return errors.Wrap(err, "error while listing webhooks")
+	}
+
+	payload, err := json.Marshal(webhookEvent.EventContent.Data)
+	if err != nil {
+		return errors.Wrapf(err, "can't encode the data of webhook event %s", webhookEvent.Id)
+	}
+
+	pendingDeliveries, rateLimitedDeliveries, deadLetteredDeliveries := 0, 0, 0
+	for _, webhook := range webhooks {
+		if !subscribesTo(webhook, webhookEvent.EventContent.Type) {
+			continue
+		}
+
+		status, err := d.deliver(ctx, webhook, webhookEvent, payload)
+		if errors.Is(err, ErrDeliveryRateLimited) {
+			rateLimitedDeliveries += 1
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch status {
+		case models.Success:
+		case models.DeadLetter:
+			deadLetteredDeliveries += 1
+		default:
+			pendingDeliveries += 1
+		}
+	}
+
+	if pendingDeliveries > 0 {
+		return errors.Newf("%d deliveries of webhook event %s must be retried", pendingDeliveries, webhookEvent.Id)
+	}
+	if rateLimitedDeliveries > 0 {
+		return errors.Wrapf(ErrDeliveryRateLimited, "%d deliveries of webhook event %s postponed",
+			rateLimitedDeliveries, webhookEvent.Id)
+	}
+	if deadLetteredDeliveries > 0 {
+		return errors.Wrapf(ErrDeliveryDeadLettered, "%d deliveries of webhook event %s dead-lettered",
+			deadLetteredDeliveries, webhookEvent.Id)
+	}
+	return nil
+}
+
+func subscribesTo(webhook models.Webhook, eventType models.WebhookEventType) bool {
+	return len(webhook.EventTypes) == 0 || slices.Contains(webhook.EventTypes, string(eventType))
+}
+
+// deliver makes one delivery attempt of the webhook event to the endpoint, unless the delivery is already finished
+// or the rate limit of the endpoint is reached (ErrDeliveryRateLimited). Returns the resulting status of the delivery.
+func (d Dispatcher) deliver(
+	ctx context.Context,
+	webhook models.Webhook,
+	webhookEvent models.WebhookEvent,
+	payload []byte,
+) (models.WebhookEventDeliveryStatus, error) {
+	logger := utils.LoggerFromContext(ctx)
+	exec := d.executorFactory.NewExecutor()
+
+	delivery, err := d.repository.GetOrCreateWebhookDelivery(ctx, exec, webhookEvent.Id, webhook.Id)
+	if err != nil {
+		return "", errors.Wrap(err, "error while getting the webhook delivery")
+	}
+	if delivery.IsFinished() {
+		return delivery.Status, nil
+	}
+
+	rateLimited, err := d.isRateLimited(ctx, webhook)
+	if err != nil {
+		return "", err
+	}
+	if rateLimited {
+		logger.InfoContext(ctx, fmt.Sprintf("Rate limit of webhook %s reached, delivery of webhook event %s postponed",
+			webhook.Id, webhookEvent.Id))
+		return delivery.Status, ErrDeliveryRateLimited
+	}
+
+	attempt := d.post(ctx, webhook, webhookEvent, payload)
+	attempt.DeliveryId = delivery.Id
+
+	status := models.Success
+	if !attempt.IsSuccessful() {
+		status = models.Retry
+		if delivery.Attempts+1 >= MAX_DELIVERY_ATTEMPTS {
+			status = models.DeadLetter
+			logger.WarnContext(ctx, fmt.Sprintf("Delivery of webhook event %s to webhook %s dead-lettered after %d attempts",
+				webhookEvent.Id, webhook.Id, delivery.Attempts+1))
+		}
+	}
+
+	err = d.transactionFactory.Transaction(ctx, func(tx repositories.Executor) error {
+		if err := d.repository.CreateWebhookDeliveryAttempt(ctx, tx, attempt); err != nil {
+			return err
+		}
+		return d.repository.MarkWebhookDeliveryAttempted(ctx, tx, delivery.Id, status)
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error while storing the webhook delivery attempt")
+	}
+
+	return status, nil
+}
+
+func (d Dispatcher) isRateLimited(ctx context.Context, webhook models.Webhook) (bool, error) {
+	if webhook.RateLimit == nil || *webhook.RateLimit == 0 {
+		return false, nil
+	}
+	duration := DEFAULT_RATE_LIMIT_DURATION
+	if webhook.RateLimitDuration != nil && *webhook.RateLimitDuration > 0 {
+		duration = time.Duration(*webhook.RateLimitDuration) * time.Second
+	}
+
+	count, err := d.repository.CountWebhookDeliveryAttemptsSince(ctx,
+		d.executorFactory.NewExecutor(), webhook.Id, time.Now().Add(-duration))
+	if err != nil {
+		return false, errors.Wrap(err, "error while counting webhook delivery attempts")
+	}
+	return count >= *webhook.RateLimit, nil
+}
+
+// post makes the HTTP call to the endpoint. Errors are not returned but stored in the delivery attempt.
+func (d Dispatcher) post(
+	ctx context.Context,
+	webhook models.Webhook,
+	webhookEvent models.WebhookEvent,
+	payload []byte,
+) models.WebhookDeliveryAttemptCreate {
+	attempt := models.WebhookDeliveryAttemptCreate{WebhookId: webhook.Id}
+
+	timeout := DEFAULT_HTTP_TIMEOUT
+	if webhook.HttpTimeout != nil && *webhook.HttpTimeout > 0 {
+		timeout = time.Duration(*webhook.HttpTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(payload))
+	if err != nil {
+		attempt.Error = truncate(err.Error(), errorMessageMaxLength)
+		return attempt
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", USER_AGENT)
+	request.Header.Set(WEBHOOK_ID_HEADER, webhook.Id)
+	request.Header.Set(WEBHOOK_EVENT_ID_HEADER, webhookEvent.Id)
+	request.Header.Set(WEBHOOK_EVENT_TYPE_HEADER, string(webhookEvent.EventContent.Type))
+	request.Header.Set(SIGNATURE_HEADER, SignatureHeader(webhook.Secrets, time.Now(), payload))
+
+	start := time.Now()
+	response, err := d.httpClient.Do(request)
+	attempt.Latency = time.Since(start)
+	if err != nil {
+		attempt.Error = truncate(err.Error(), errorMessageMaxLength)
+		return attempt
+	}
+	defer response.Body.Close()
+
+	attempt.StatusCode = utils.Ptr(response.StatusCode)
+	excerpt, err := io.ReadAll(io.LimitReader(response.Body, RESPONSE_EXCERPT_MAX_BYTES))
+	if err != nil {
+		attempt.Error = truncate(err.Error(), errorMessageMaxLength)
+	}
+	attempt.ResponseExcerpt = strings.ToValidUTF8(string(excerpt), "")
+	return attempt
+}
+
+// SignatureHeader returns the value of the signature header of a delivery: "t=<unix timestamp>,v1=<signature>",
+// with one v1 signature per active secret of the endpoint. A signature is the hex encoded HMAC-SHA256, keyed with the
+// secret, of "<unix timestamp>.<payload>".
+func SignatureHeader(secrets []models.Secret, timestamp time.Time, payload []byte) string {
+	parts := []string{fmt.Sprintf("t=%d", timestamp.Unix())}
+	for _, secret := range secrets {
+		if secret.DeletedAt != "" {
+			continue
+		}
+		parts = append(parts, "v1="+Sign(secret.Value, timestamp, payload))
+	}
+	return strings.Join(parts, ",")
+}
+
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	bytes := make([]byte, generatedSecretNumberOfBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.Wrap(err, "can't generate webhook secret")
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	return s[:maxLength]
+}
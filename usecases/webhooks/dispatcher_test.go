@@ -0,0 +1,213 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/utils"
+)
+
+func TestSignatureHeader(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	payload := []byte(`{"type":"decision.created"}`)
+
+	header := SignatureHeader([]models.Secret{
+		{Value: "secret_1"},
+		{Value: "secret_2", DeletedAt: "2024-01-01T00:00:00Z"},
+		{Value: "secret_3"},
+	}, timestamp, payload)
+
+	assert.Equal(t, "t=1700000000,v1="+Sign("secret_1", timestamp, payload)+
+		",v1="+Sign("secret_3", timestamp, payload), header)
+	assert.NotEqual(t, Sign("secret_1", timestamp, payload), Sign("secret_1", timestamp.Add(time.Second), payload))
+	assert.Len(t, Sign("secret_1", timestamp, payload), 64)
+}
+
+// the test servers listen on the loopback interface: private networks are allowed, unless specified
+func newTestDispatcher(repository *mocks.WebhookDispatcherRepository, allowPrivateNetworks ...bool) Dispatcher {
+	exec := new(mocks.Executor)
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: exec}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+	return NewDispatcher(repository, executorFactory, transactionFactory,
+		len(allowPrivateNetworks) == 0 || allowPrivateNetworks[0])
+}
+
+func testWebhookEvent() models.WebhookEvent {
+	return models.WebhookEvent{
+		Id:             "event_id",
+		OrganizationId: "org_id",
+		EventContent:   models.NewWebhookEventDecisionCreated("decision_id", nil),
+	}
+}
+
+func TestSendWebhookEvent_delivered(t *testing.T) {
+	var receivedSignature string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedSignature = r.Header.Get(SIGNATURE_HEADER)
+		receivedBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	repository := new(mocks.WebhookDispatcherRepository)
+	webhook := models.Webhook{Id: "webhook_id", Url: server.URL, Secrets: []models.Secret{{Value: "secret"}}}
+	otherWebhook := models.Webhook{Id: "other_webhook_id", Url: server.URL, EventTypes: []string{"case.updated"}}
+	repository.On("ListWebhooks", mock.Anything, "org_id", null.String{}).
+		Return([]models.Webhook{webhook, otherWebhook}, nil)
+	repository.On("GetOrCreateWebhookDelivery", mock.Anything, "event_id", "webhook_id").
+		Return(models.WebhookDelivery{Id: "delivery_id", Status: models.Scheduled}, nil)
+	repository.On("CreateWebhookDeliveryAttempt", mock.Anything, mock.MatchedBy(
+		func(attempt models.WebhookDeliveryAttemptCreate) bool {
+			return attempt.DeliveryId == "delivery_id" && attempt.StatusCode != nil &&
+				*attempt.StatusCode == http.StatusOK && attempt.ResponseExcerpt == "ok"
+		})).Return(nil)
+	repository.On("MarkWebhookDeliveryAttempted", mock.Anything, "delivery_id", models.Success).Return(nil)
+
+	err := newTestDispatcher(repository).SendWebhookEvent(context.Background(), testWebhookEvent())
+	assert.NoError(t, err)
+	repository.AssertExpectations(t)
+
+	timestamp, signature, found := strings.Cut(strings.TrimPrefix(receivedSignature, "t="), ",v1=")
+	assert.True(t, found)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, Sign("secret", time.Unix(seconds, 0), receivedBody), signature)
+}
+
+func TestSendWebhookEvent_failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repository := new(mocks.WebhookDispatcherRepository)
+	repository.On("ListWebhooks", mock.Anything, "org_id", null.String{}).
+		Return([]models.Webhook{{Id: "webhook_id", Url: server.URL}}, nil)
+	repository.On("GetOrCreateWebhookDelivery", mock.Anything, "event_id", "webhook_id").
+		Return(models.WebhookDelivery{Id: "delivery_id", Status: models.Retry, Attempts: 1}, nil)
+	repository.On("CreateWebhookDeliveryAttempt", mock.Anything, mock.Anything).Return(nil)
+	repository.On("MarkWebhookDeliveryAttempted", mock.Anything, "delivery_id", models.Retry).Return(nil)
+
+	err := newTestDispatcher(repository).SendWebhookEvent(context.Background(), testWebhookEvent())
+	assert.Error(t, err)
+	repository.AssertExpectations(t)
+}
+
+func TestSendWebhookEvent_dead_letter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	repository := new(mocks.WebhookDispatcherRepository)
+	repository.On("ListWebhooks", mock.Anything, "org_id", null.String{}).
+		Return([]models.Webhook{{Id: "webhook_id", Url: server.URL}}, nil)
+	repository.On("GetOrCreateWebhookDelivery", mock.Anything, "event_id", "webhook_id").
+		Return(models.WebhookDelivery{
+			Id:       "delivery_id",
+			Status:   models.Retry,
+			Attempts: MAX_DELIVERY_ATTEMPTS - 1,
+		}, nil)
+	repository.On("CreateWebhookDeliveryAttempt", mock.Anything, mock.Anything).Return(nil)
+	repository.On("MarkWebhookDeliveryAttempted", mock.Anything, "delivery_id", models.DeadLetter).Return(nil)
+
+	err := newTestDispatcher(repository).SendWebhookEvent(context.Background(), testWebhookEvent())
+	assert.ErrorIs(t, err, ErrDeliveryDeadLettered)
+	repository.AssertExpectations(t)
+}
+
+func TestSendWebhookEvent_rate_limited(t *testing.T) {
+	repository := new(mocks.WebhookDispatcherRepository)
+	repository.On("ListWebhooks", mock.Anything, "org_id", null.String{}).
+		Return([]models.Webhook{{
+			Id:                "webhook_id",
+			Url:               "http://localhost:1",
+			RateLimit:         utils.Ptr(10),
+			RateLimitDuration: utils.Ptr(60),
+		}}, nil)
+	repository.On("GetOrCreateWebhookDelivery", mock.Anything, "event_id", "webhook_id").
+		Return(models.WebhookDelivery{Id: "delivery_id", Status: models.Scheduled}, nil)
+	repository.On("CountWebhookDeliveryAttemptsSince", mock.Anything, "webhook_id", mock.Anything).Return(10, nil)
+
+	err := newTestDispatcher(repository).SendWebhookEvent(context.Background(), testWebhookEvent())
+	assert.ErrorIs(t, err, ErrDeliveryRateLimited)
+	repository.AssertExpectations(t)
+	repository.AssertNotCalled(t, "CreateWebhookDeliveryAttempt", mock.Anything, mock.Anything)
+	repository.AssertNotCalled(t, "MarkWebhookDeliveryAttempted", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendWebhookEvent_private_address(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	repository := new(mocks.WebhookDispatcherRepository)
+	repository.On("ListWebhooks", mock.Anything, "org_id", null.String{}).
+		Return([]models.Webhook{{Id: "webhook_id", Url: server.URL}}, nil)
+	repository.On("GetOrCreateWebhookDelivery", mock.Anything, "event_id", "webhook_id").
+		Return(models.WebhookDelivery{Id: "delivery_id", Status: models.Scheduled}, nil)
+	repository.On("CreateWebhookDeliveryAttempt", mock.Anything, mock.MatchedBy(
+		func(attempt models.WebhookDeliveryAttemptCreate) bool {
+			return attempt.StatusCode == nil && strings.Contains(attempt.Error, "refused to connect")
+		})).Return(nil)
+	repository.On("MarkWebhookDeliveryAttempted", mock.Anything, "delivery_id", models.Retry).Return(nil)
+
+	err := newTestDispatcher(repository, false).SendWebhookEvent(context.Background(), testWebhookEvent())
+	assert.Error(t, err)
+	assert.False(t, called, "the endpoint on the loopback interface must not be reached")
+	repository.AssertExpectations(t)
+}
+
+func TestRegisterWebhook_private_address(t *testing.T) {
+	repository := new(mocks.WebhookDispatcherRepository)
+	_, err := newTestDispatcher(repository, false).RegisterWebhook(context.Background(), "org_id", null.String{},
+		models.WebhookRegister{Url: "http://169.254.169.254/latest/meta-data"})
+	assert.ErrorIs(t, err, models.BadParameterError)
+	repository.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestValidateEndpointUrl(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://example.com/webhooks",
+		"http://example.com:8080",
+		"https://93.184.216.34/webhooks",
+	} {
+		assert.NoError(t, ValidateEndpointUrl(endpoint, false), endpoint)
+	}
+	for _, endpoint := range []string{
+		"ftp://example.com",
+		"example.com/webhooks",
+		"https://",
+		"http://localhost:8080",
+		"http://api.localhost",
+		"http://127.0.0.1",
+		"http://10.1.2.3",
+		"http://192.168.0.1",
+		"http://169.254.169.254",
+		"http://100.64.0.1",
+		"http://[::1]:8080",
+		"http://[fd00::1]",
+		"http://0.0.0.0",
+	} {
+		assert.ErrorIs(t, ValidateEndpointUrl(endpoint, false), models.BadParameterError, endpoint)
+	}
+	assert.NoError(t, ValidateEndpointUrl("http://10.1.2.3", true))
+	assert.Error(t, ValidateEndpointUrl("file:///etc/passwd", true))
+}
@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/checkmarble/marble-backend/models"
+)
+
+// ErrForbiddenAddress is returned when a webhook endpoint resolves to an address of the private network of Marble
+var ErrForbiddenAddress = errors.New("webhook endpoints cannot be in a private, loopback or link-local network")
+
+// carrier-grade NAT addresses, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isForbiddenIp(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// ValidateEndpointUrl checks that a webhook endpoint is an absolute http(s) URL. Unless private networks are allowed,
+// it also rejects the hosts that are literal private addresses or localhost. Host names are only resolved when an
+// event is delivered: the dialer of the dispatcher checks the addresses they resolve to.
+func ValidateEndpointUrl(endpoint string, allowPrivateNetworks bool) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.Wrapf(models.BadParameterError, "invalid Url: %s", endpoint)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.Wrapf(models.BadParameterError, "invalid Url scheme, expected http or https: %s", endpoint)
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return errors.Wrapf(models.BadParameterError, "invalid Url, the host is missing: %s", endpoint)
+	}
+	if allowPrivateNetworks {
+		return nil
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.Wrap(models.BadParameterError, ErrForbiddenAddress.Error())
+	}
+	if ip := net.ParseIP(host); ip != nil && isForbiddenIp(ip) {
+		return errors.Wrap(models.BadParameterError, ErrForbiddenAddress.Error())
+	}
+	return nil
+}
+
+// newEndpointHttpClient returns the http client used to deliver webhook events. Unless private networks are allowed,
+// its dialer refuses to connect to private addresses, after the resolution of the host name and on every redirect,
+// so that a webhook cannot be used to reach the internal services of the Marble deployment.
+func newEndpointHttpClient(allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isForbiddenIp(ip) {
+				return errors.Wrapf(ErrForbiddenAddress, "refused to connect to %s", address)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	if !allowPrivateNetworks {
+		// a proxy would make the connection on behalf of the dispatcher, without the checks of the dialer
+		transport.Proxy = nil
+	}
+	// the timeout is set per request, from the configuration of the endpoint
+	return &http.Client{Transport: transport}
+}
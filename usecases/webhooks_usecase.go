@@ -2,14 +2,17 @@ package usecases
 
 import (
 	"context"
+	"time"
 
 	"github.com/checkmarble/marble-backend/models"
+	"github.com/checkmarble/marble-backend/repositories"
 	"github.com/checkmarble/marble-backend/usecases/executor_factory"
 	"github.com/guregu/null/v5"
 	"github.com/pkg/errors"
 )
 
-type convoyWebhooksRepository interface {
+// webhooksBackend manages the webhook endpoints: Convoy, or the built-in webhook dispatcher
+type webhooksBackend interface {
 	GetWebhook(ctx context.Context, webhookId string) (models.Webhook, error)
 	ListWebhooks(ctx context.Context, organizationId string, partnerId null.String) ([]models.Webhook, error)
 	RegisterWebhook(ctx context.Context, organizationId string, partnerId null.String,
@@ -18,35 +21,60 @@ type convoyWebhooksRepository interface {
 	DeleteWebhook(ctx context.Context, webhookId string) error
 }
 
+type webhookEventsLogRepository interface {
+	GetWebhookEvent(ctx context.Context, exec repositories.Executor, webhookEventId string) (models.WebhookEvent, error)
+	ListWebhookEvents(ctx context.Context, exec repositories.Executor,
+		filters models.WebhookEventFilters) ([]models.WebhookEvent, error)
+	MarkWebhookEventForReplay(ctx context.Context, exec repositories.Executor, webhookEventId string) error
+	ListWebhookDeliveries(ctx context.Context, exec repositories.Executor,
+		webhookEventId string) ([]models.WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, exec repositories.Executor,
+		deliveryIds []string) ([]models.WebhookDeliveryAttempt, error)
+	ResetWebhookDeliveries(ctx context.Context, exec repositories.Executor, webhookEventId string,
+		webhookId *string, statuses []models.WebhookEventDeliveryStatus) error
+}
+
 type enforceSecurityWebhook interface {
 	CanCreateWebhook(ctx context.Context, organizationId string, partnerId null.String) error
 	CanReadWebhook(ctx context.Context, webhook models.Webhook) error
 	CanModifyWebhook(ctx context.Context, webhook models.Webhook) error
+	CanReadWebhookEvents(ctx context.Context, organizationId string, partnerId null.String) error
+	CanReadWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error
+	CanReplayWebhookEvent(ctx context.Context, webhookEvent models.WebhookEvent) error
 }
 
 type WebhooksUsecase struct {
-	enforceSecurity    enforceSecurityWebhook
-	executorFactory    executor_factory.ExecutorFactory
-	transactionFactory executor_factory.TransactionFactory
-	convoyRepository   convoyWebhooksRepository
+	enforceSecurity            enforceSecurityWebhook
+	executorFactory            executor_factory.ExecutorFactory
+	transactionFactory         executor_factory.TransactionFactory
+	webhooksBackend            webhooksBackend
+	webhookEventsLogRepository webhookEventsLogRepository
+	jobEnqueuer                jobEnqueuer
+	webhookEventsSender        webhookEventsUsecase
 }
 
 func NewWebhooksUsecase(
 	enforceSecurity enforceSecurityWebhook,
 	executorFactory executor_factory.ExecutorFactory,
 	transactionFactory executor_factory.TransactionFactory,
-	convoyRepository convoyWebhooksRepository,
+	webhooksBackend webhooksBackend,
+	webhookEventsLogRepository webhookEventsLogRepository,
+	jobEnqueuer jobEnqueuer,
+	webhookEventsSender webhookEventsUsecase,
 ) WebhooksUsecase {
 	return WebhooksUsecase{
-		enforceSecurity:    enforceSecurity,
-		executorFactory:    executorFactory,
-		transactionFactory: transactionFactory,
-		convoyRepository:   convoyRepository,
+		enforceSecurity:            enforceSecurity,
+		executorFactory:            executorFactory,
+		transactionFactory:         transactionFactory,
+		webhooksBackend:            webhooksBackend,
+		webhookEventsLogRepository: webhookEventsLogRepository,
+		jobEnqueuer:                jobEnqueuer,
+		webhookEventsSender:        webhookEventsSender,
 	}
 }
 
 func (usecase WebhooksUsecase) ListWebhooks(ctx context.Context, organizationId string, partnerId null.String) ([]models.Webhook, error) {
-	webhooks, err := usecase.convoyRepository.ListWebhooks(ctx, organizationId, partnerId)
+	webhooks, err := usecase.webhooksBackend.ListWebhooks(ctx, organizationId, partnerId)
 	if err != nil {
 		return nil, errors.Wrap(err, "error listing webhooks")
 	}
@@ -75,14 +103,14 @@ func (usecase WebhooksUsecase) RegisterWebhook(
 		return models.Webhook{}, err
 	}
 
-	webhook, err := usecase.convoyRepository.RegisterWebhook(ctx, organizationId, partnerId, input)
+	webhook, err := usecase.webhooksBackend.RegisterWebhook(ctx, organizationId, partnerId, input)
 	return webhook, errors.Wrap(err, "error registering webhook")
 }
 
 func (usecase WebhooksUsecase) GetWebhook(
 	ctx context.Context, organizationId string, partnerId null.String, webhookId string,
 ) (models.Webhook, error) {
-	webhook, err := usecase.convoyRepository.GetWebhook(ctx, webhookId)
+	webhook, err := usecase.webhooksBackend.GetWebhook(ctx, webhookId)
 	if err != nil {
 		return models.Webhook{}, models.NotFoundError
 	}
@@ -95,7 +123,7 @@ func (usecase WebhooksUsecase) GetWebhook(
 func (usecase WebhooksUsecase) DeleteWebhook(
 	ctx context.Context, organizationId string, partnerId null.String, webhookId string,
 ) error {
-	webhook, err := usecase.convoyRepository.GetWebhook(ctx, webhookId)
+	webhook, err := usecase.webhooksBackend.GetWebhook(ctx, webhookId)
 	if err != nil {
 		return models.NotFoundError
 	}
@@ -103,7 +131,7 @@ func (usecase WebhooksUsecase) DeleteWebhook(
 		return err
 	}
 
-	err = usecase.convoyRepository.DeleteWebhook(ctx, webhook.Id)
+	err = usecase.webhooksBackend.DeleteWebhook(ctx, webhook.Id)
 	return errors.Wrap(err, "error deleting webhook")
 }
 
@@ -114,7 +142,7 @@ func (usecase WebhooksUsecase) UpdateWebhook(
 		return models.Webhook{}, err
 	}
 
-	webhook, err := usecase.convoyRepository.GetWebhook(ctx, webhookId)
+	webhook, err := usecase.webhooksBackend.GetWebhook(ctx, webhookId)
 	if err != nil {
 		return models.Webhook{}, models.NotFoundError
 	}
@@ -122,7 +150,193 @@ func (usecase WebhooksUsecase) UpdateWebhook(
 		return models.Webhook{}, err
 	}
 
-	updatedWebhook, err := usecase.convoyRepository.UpdateWebhook(ctx,
+	updatedWebhook, err := usecase.webhooksBackend.UpdateWebhook(ctx,
 		models.MergeWebhookWithUpdate(webhook, input))
 	return updatedWebhook, errors.Wrap(err, "error updating webhook")
 }
+
+func (usecase WebhooksUsecase) ListWebhookEvents(
+	ctx context.Context,
+	organizationId string,
+	partnerId null.String,
+	filters models.WebhookEventFilters,
+) ([]models.WebhookEvent, error) {
+	if err := usecase.enforceSecurity.CanReadWebhookEvents(ctx, organizationId, partnerId); err != nil {
+		return nil, err
+	}
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters.OrganizationId = organizationId
+	filters.PartnerId = partnerId
+	webhookEvents, err := usecase.webhookEventsLogRepository.ListWebhookEvents(ctx,
+		usecase.executorFactory.NewExecutor(), filters)
+	return webhookEvents, errors.Wrap(err, "error listing webhook events")
+}
+
+// GetWebhookEvent returns a webhook event with the log of its delivery attempts
+func (usecase WebhooksUsecase) GetWebhookEvent(
+	ctx context.Context,
+	webhookEventId string,
+) (models.WebhookEventWithDeliveries, error) {
+	exec := usecase.executorFactory.NewExecutor()
+	webhookEvent, err := usecase.webhookEventsLogRepository.GetWebhookEvent(ctx, exec, webhookEventId)
+	if err != nil {
+		return models.WebhookEventWithDeliveries{}, err
+	}
+	if err := usecase.enforceSecurity.CanReadWebhookEvent(ctx, webhookEvent); err != nil {
+		return models.WebhookEventWithDeliveries{}, err
+	}
+
+	deliveries, err := usecase.webhookEventsLogRepository.ListWebhookDeliveries(ctx, exec, webhookEventId)
+	if err != nil {
+		return models.WebhookEventWithDeliveries{}, errors.Wrap(err, "error listing webhook deliveries")
+	}
+	deliveryIds := make([]string, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryIds[i] = delivery.Id
+	}
+	attempts, err := usecase.webhookEventsLogRepository.ListWebhookDeliveryAttempts(ctx, exec, deliveryIds)
+	if err != nil {
+		return models.WebhookEventWithDeliveries{}, errors.Wrap(err, "error listing webhook delivery attempts")
+	}
+
+	attemptsByDeliveryId := make(map[string][]models.WebhookDeliveryAttempt, len(deliveries))
+	for _, attempt := range attempts {
+		attemptsByDeliveryId[attempt.DeliveryId] = append(attemptsByDeliveryId[attempt.DeliveryId], attempt)
+	}
+	result := models.WebhookEventWithDeliveries{
+		WebhookEvent: webhookEvent,
+		Deliveries:   make([]models.WebhookDeliveryWithAttempts, len(deliveries)),
+	}
+	for i, delivery := range deliveries {
+		result.Deliveries[i] = models.WebhookDeliveryWithAttempts{
+			WebhookDelivery:  delivery,
+			DeliveryAttempts: attemptsByDeliveryId[delivery.Id],
+		}
+	}
+	return result, nil
+}
+
+// ReplayWebhookEvent sends a webhook event again to all the endpoints it matches, whatever its delivery status
+func (usecase WebhooksUsecase) ReplayWebhookEvent(ctx context.Context, webhookEventId string) (models.WebhookEvent, error) {
+	webhookEvent, err := usecase.webhookEventsLogRepository.GetWebhookEvent(ctx,
+		usecase.executorFactory.NewExecutor(), webhookEventId)
+	if err != nil {
+		return models.WebhookEvent{}, err
+	}
+	if err := usecase.enforceSecurity.CanReplayWebhookEvent(ctx, webhookEvent); err != nil {
+		return models.WebhookEvent{}, err
+	}
+
+	webhookEvent, err = executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) (models.WebhookEvent, error) {
+		// the job only sends the event if sending it right after the transaction fails
+		runAt := time.Now().Add(WEBHOOK_FIRST_RETRY_DELAY)
+		if err := usecase.replayWebhookEvent(ctx, tx, webhookEventId, nil, nil, runAt); err != nil {
+			return models.WebhookEvent{}, err
+		}
+		return usecase.webhookEventsLogRepository.GetWebhookEvent(ctx, tx, webhookEventId)
+	})
+	if err != nil {
+		return models.WebhookEvent{}, errors.Wrap(err, "error replaying webhook event")
+	}
+
+	usecase.webhookEventsSender.SendWebhookEventAsync(ctx, webhookEventId)
+	return webhookEvent, nil
+}
+
+// ReplayWebhookEvents sends again to a webhook endpoint the events created in the time range whose delivery failed
+// (they are being retried or have been dead-lettered). At most WEBHOOK_EVENTS_MAX_LIMIT events are replayed at once:
+// the result tells if other events remain, they are replayed by calling it again.
+func (usecase WebhooksUsecase) ReplayWebhookEvents(
+	ctx context.Context,
+	organizationId string,
+	partnerId null.String,
+	webhookId string,
+	input models.WebhookEventsReplay,
+) (models.WebhookEventsReplayResult, error) {
+	if err := input.Validate(); err != nil {
+		return models.WebhookEventsReplayResult{}, err
+	}
+
+	webhook, err := usecase.webhooksBackend.GetWebhook(ctx, webhookId)
+	if err != nil {
+		return models.WebhookEventsReplayResult{}, models.NotFoundError
+	}
+	if err = usecase.enforceSecurity.CanModifyWebhook(ctx, webhook); err != nil {
+		return models.WebhookEventsReplayResult{}, err
+	}
+
+	filters := models.WebhookEventFilters{
+		OrganizationId: webhook.OrganizationId,
+		PartnerId:      webhook.PartnerId,
+		DeliveryStatus: []models.WebhookEventDeliveryStatus{models.Retry, models.DeadLetter},
+		StartDate:      input.StartDate,
+		EndDate:        input.EndDate,
+		// one more event than replayed, to know if some remain
+		Limit: models.WEBHOOK_EVENTS_MAX_LIMIT + 1,
+	}
+	for _, eventType := range webhook.EventTypes {
+		filters.EventTypes = append(filters.EventTypes, models.WebhookEventType(eventType))
+	}
+
+	hasMore := false
+	webhookEvents, err := executor_factory.TransactionReturnValue(ctx, usecase.transactionFactory, func(
+		tx repositories.Executor,
+	) ([]models.WebhookEvent, error) {
+		webhookEvents, err := usecase.webhookEventsLogRepository.ListWebhookEvents(ctx, tx, filters)
+		if err != nil {
+			return nil, err
+		}
+		if len(webhookEvents) > models.WEBHOOK_EVENTS_MAX_LIMIT {
+			hasMore = true
+			webhookEvents = webhookEvents[:models.WEBHOOK_EVENTS_MAX_LIMIT]
+		}
+		for _, webhookEvent := range webhookEvents {
+			if err := usecase.enforceSecurity.CanReplayWebhookEvent(ctx, webhookEvent); err != nil {
+				return nil, err
+			}
+			// the events are not sent right away but by their jobs, so that the workers of the job queue pace
+			// the delivery of a large replay
+			err := usecase.replayWebhookEvent(ctx, tx, webhookEvent.Id, &webhook.Id,
+				[]models.WebhookEventDeliveryStatus{models.Retry, models.DeadLetter}, time.Now())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return webhookEvents, nil
+	})
+	if err != nil {
+		return models.WebhookEventsReplayResult{}, errors.Wrap(err, "error replaying webhook events")
+	}
+
+	return models.WebhookEventsReplayResult{ReplayedEvents: len(webhookEvents), HasMore: hasMore}, nil
+}
+
+// replayWebhookEvent resets the event and the selected deliveries of the event, and enqueues a job to send it again
+// at runAt. The deliveries are only tracked by the built-in delivery
+// backend: resetting them does nothing with Convoy.
+func (usecase WebhooksUsecase) replayWebhookEvent(
+	ctx context.Context,
+	tx repositories.Executor,
+	webhookEventId string,
+	webhookId *string,
+	deliveryStatuses []models.WebhookEventDeliveryStatus,
+	runAt time.Time,
+) error {
+	if err := usecase.webhookEventsLogRepository.MarkWebhookEventForReplay(ctx, tx, webhookEventId); err != nil {
+		return err
+	}
+	err := usecase.webhookEventsLogRepository.ResetWebhookDeliveries(ctx, tx, webhookEventId, webhookId, deliveryStatuses)
+	if err != nil {
+		return err
+	}
+	return usecase.jobEnqueuer.EnqueueJob(ctx, tx, models.JobEnqueueInput{
+		Kind:     models.JobKindWebhookEvent,
+		EntityId: webhookEventId,
+		RunAt:    &runAt,
+	})
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/checkmarble/marble-backend/mocks"
+	"github.com/checkmarble/marble-backend/models"
+)
+
+type WebhooksUsecaseTestSuite struct {
+	suite.Suite
+	enforceSecurity     *mocks.EnforceSecurityWebhook
+	webhooksBackend     *mocks.WebhooksBackend
+	repository          *mocks.WebhookEventsLogRepository
+	jobEnqueuer         *mocks.JobEnqueuer
+	webhookEventsSender *mocks.WebhookEventsSender
+	exec                *mocks.Executor
+	transaction         *mocks.Executor
+
+	ctx     context.Context
+	webhook models.Webhook
+}
+
+func (suite *WebhooksUsecaseTestSuite) SetupTest() {
+	suite.enforceSecurity = new(mocks.EnforceSecurityWebhook)
+	suite.webhooksBackend = new(mocks.WebhooksBackend)
+	suite.repository = new(mocks.WebhookEventsLogRepository)
+	suite.jobEnqueuer = new(mocks.JobEnqueuer)
+	suite.webhookEventsSender = new(mocks.WebhookEventsSender)
+	suite.exec = new(mocks.Executor)
+	suite.transaction = new(mocks.Executor)
+
+	suite.ctx = context.Background()
+	suite.webhook = models.Webhook{
+		Id:             "webhook_id",
+		OrganizationId: "organization_id",
+		EventTypes:     []string{string(models.WebhookEventType_DecisionCreated)},
+	}
+}
+
+func (suite *WebhooksUsecaseTestSuite) makeUsecase() WebhooksUsecase {
+	executorFactory := new(mocks.ExecutorFactory)
+	executorFactory.On("NewExecutor").Return(suite.exec)
+	transactionFactory := &mocks.TransactionFactory{ExecMock: suite.transaction}
+	transactionFactory.On("Transaction", mock.Anything, mock.Anything).Return(nil)
+
+	return NewWebhooksUsecase(
+		suite.enforceSecurity,
+		executorFactory,
+		transactionFactory,
+		suite.webhooksBackend,
+		suite.repository,
+		suite.jobEnqueuer,
+		suite.webhookEventsSender,
+	)
+}
+
+func (suite *WebhooksUsecaseTestSuite) AssertExpectations() {
+	t := suite.T()
+	suite.enforceSecurity.AssertExpectations(t)
+	suite.webhooksBackend.AssertExpectations(t)
+	suite.repository.AssertExpectations(t)
+	suite.jobEnqueuer.AssertExpectations(t)
+	suite.webhookEventsSender.AssertExpectations(t)
+}
+
+// expectReplay expects the reset of the event. It is either sent right away with a job as fallback, or only by a job
+// that runs right away.
+func (suite *WebhooksUsecaseTestSuite) expectReplay(webhookEventId string, webhookId *string,
+	statuses []models.WebhookEventDeliveryStatus, sentRightAway bool,
+) {
+	suite.repository.On("MarkWebhookEventForReplay", suite.transaction, webhookEventId).Return(nil).Once()
+	suite.repository.On("ResetWebhookDeliveries", suite.transaction, webhookEventId, webhookId, statuses).
+		Return(nil).Once()
+	suite.jobEnqueuer.On("EnqueueJob", suite.transaction, mock.MatchedBy(func(input models.JobEnqueueInput) bool {
+		return input.Kind == models.JobKindWebhookEvent && input.EntityId == webhookEventId && input.RunAt != nil &&
+			input.RunAt.After(time.Now()) == sentRightAway
+	})).Return(nil).Once()
+	if sentRightAway {
+		suite.webhookEventsSender.On("SendWebhookEventAsync", webhookEventId).Return().Once()
+	}
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestGetWebhookEvent() {
+	webhookEvent := models.WebhookEvent{Id: "event_id", OrganizationId: "organization_id"}
+	deliveries := []models.WebhookDelivery{{Id: "delivery_1"}, {Id: "delivery_2"}}
+	attempts := []models.WebhookDeliveryAttempt{
+		{Id: "attempt_1", DeliveryId: "delivery_1"},
+		{Id: "attempt_2", DeliveryId: "delivery_1"},
+	}
+	suite.repository.On("GetWebhookEvent", suite.exec, "event_id").Return(webhookEvent, nil)
+	suite.enforceSecurity.On("CanReadWebhookEvent", webhookEvent).Return(nil)
+	suite.repository.On("ListWebhookDeliveries", suite.exec, "event_id").Return(deliveries, nil)
+	suite.repository.On("ListWebhookDeliveryAttempts", suite.exec, []string{"delivery_1", "delivery_2"}).
+		Return(attempts, nil)
+
+	result, err := suite.makeUsecase().GetWebhookEvent(suite.ctx, "event_id")
+	suite.NoError(err)
+	suite.Equal(models.WebhookEventWithDeliveries{
+		WebhookEvent: webhookEvent,
+		Deliveries: []models.WebhookDeliveryWithAttempts{
+			{WebhookDelivery: deliveries[0], DeliveryAttempts: attempts},
+			{WebhookDelivery: deliveries[1]},
+		},
+	}, result)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestGetWebhookEvent_forbidden() {
+	webhookEvent := models.WebhookEvent{Id: "event_id", OrganizationId: "other_organization_id"}
+	suite.repository.On("GetWebhookEvent", suite.exec, "event_id").Return(webhookEvent, nil)
+	suite.enforceSecurity.On("CanReadWebhookEvent", webhookEvent).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().GetWebhookEvent(suite.ctx, "event_id")
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "ListWebhookDeliveries", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvent() {
+	webhookEvent := models.WebhookEvent{Id: "event_id", OrganizationId: "organization_id",
+		DeliveryStatus: models.DeadLetter}
+	replayed := webhookEvent
+	replayed.DeliveryStatus = models.Scheduled
+	replayed.ReplayCount = 1
+	suite.repository.On("GetWebhookEvent", suite.exec, "event_id").Return(webhookEvent, nil).Once()
+	suite.enforceSecurity.On("CanReplayWebhookEvent", webhookEvent).Return(nil)
+	// all the deliveries of the event are reset
+	suite.expectReplay("event_id", nil, nil, true)
+	suite.repository.On("GetWebhookEvent", suite.transaction, "event_id").Return(replayed, nil).Once()
+
+	result, err := suite.makeUsecase().ReplayWebhookEvent(suite.ctx, "event_id")
+	suite.NoError(err)
+	suite.Equal(replayed, result)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvent_forbidden() {
+	webhookEvent := models.WebhookEvent{Id: "event_id", OrganizationId: "other_organization_id"}
+	suite.repository.On("GetWebhookEvent", suite.exec, "event_id").Return(webhookEvent, nil)
+	suite.enforceSecurity.On("CanReplayWebhookEvent", webhookEvent).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().ReplayWebhookEvent(suite.ctx, "event_id")
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "MarkWebhookEventForReplay", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) replayInput() models.WebhookEventsReplay {
+	end := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	return models.WebhookEventsReplay{StartDate: end.Add(-24 * time.Hour), EndDate: end}
+}
+
+func (suite *WebhooksUsecaseTestSuite) replayFilters() models.WebhookEventFilters {
+	return models.WebhookEventFilters{
+		OrganizationId: "organization_id",
+		DeliveryStatus: []models.WebhookEventDeliveryStatus{models.Retry, models.DeadLetter},
+		EventTypes:     []models.WebhookEventType{models.WebhookEventType_DecisionCreated},
+		StartDate:      suite.replayInput().StartDate,
+		EndDate:        suite.replayInput().EndDate,
+		Limit:          models.WEBHOOK_EVENTS_MAX_LIMIT + 1,
+	}
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvents() {
+	webhookEvents := []models.WebhookEvent{
+		{Id: "event_1", OrganizationId: "organization_id", DeliveryStatus: models.Retry},
+		{Id: "event_2", OrganizationId: "organization_id", DeliveryStatus: models.DeadLetter},
+	}
+	suite.webhooksBackend.On("GetWebhook", "webhook_id").Return(suite.webhook, nil)
+	suite.enforceSecurity.On("CanModifyWebhook", suite.webhook).Return(nil)
+	suite.repository.On("ListWebhookEvents", suite.transaction, suite.replayFilters()).Return(webhookEvents, nil)
+	failedStatuses := []models.WebhookEventDeliveryStatus{models.Retry, models.DeadLetter}
+	for _, webhookEvent := range webhookEvents {
+		suite.enforceSecurity.On("CanReplayWebhookEvent", webhookEvent).Return(nil)
+		// only the failed deliveries to the webhook are reset
+		suite.expectReplay(webhookEvent.Id, &suite.webhook.Id, failedStatuses, false)
+	}
+
+	result, err := suite.makeUsecase().ReplayWebhookEvents(suite.ctx, "organization_id", null.String{},
+		"webhook_id", suite.replayInput())
+	suite.NoError(err)
+	suite.Equal(models.WebhookEventsReplayResult{ReplayedEvents: 2, HasMore: false}, result)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvents_hasMore() {
+	webhookEvents := make([]models.WebhookEvent, models.WEBHOOK_EVENTS_MAX_LIMIT+1)
+	for i := range webhookEvents {
+		webhookEvents[i] = models.WebhookEvent{Id: fmt.Sprintf("event_%d", i), OrganizationId: "organization_id"}
+	}
+	suite.webhooksBackend.On("GetWebhook", "webhook_id").Return(suite.webhook, nil)
+	suite.enforceSecurity.On("CanModifyWebhook", suite.webhook).Return(nil)
+	suite.enforceSecurity.On("CanReplayWebhookEvent", mock.Anything).Return(nil)
+	suite.repository.On("ListWebhookEvents", suite.transaction, suite.replayFilters()).Return(webhookEvents, nil)
+	suite.repository.On("MarkWebhookEventForReplay", suite.transaction, mock.Anything).Return(nil)
+	suite.repository.On("ResetWebhookDeliveries", suite.transaction, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.jobEnqueuer.On("EnqueueJob", suite.transaction, mock.Anything).Return(nil)
+
+	result, err := suite.makeUsecase().ReplayWebhookEvents(suite.ctx, "organization_id", null.String{},
+		"webhook_id", suite.replayInput())
+	suite.NoError(err)
+	suite.Equal(models.WebhookEventsReplayResult{ReplayedEvents: models.WEBHOOK_EVENTS_MAX_LIMIT, HasMore: true}, result)
+	// the extra event is left for the next call
+	suite.repository.AssertNotCalled(suite.T(), "MarkWebhookEventForReplay", suite.transaction,
+		fmt.Sprintf("event_%d", models.WEBHOOK_EVENTS_MAX_LIMIT))
+	suite.jobEnqueuer.AssertNumberOfCalls(suite.T(), "EnqueueJob", models.WEBHOOK_EVENTS_MAX_LIMIT)
+	// the events are delivered by their jobs only
+	suite.webhookEventsSender.AssertNotCalled(suite.T(), "SendWebhookEventAsync", mock.Anything)
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvents_forbidden() {
+	suite.webhooksBackend.On("GetWebhook", "webhook_id").Return(suite.webhook, nil)
+	suite.enforceSecurity.On("CanModifyWebhook", suite.webhook).Return(models.ForbiddenError)
+
+	_, err := suite.makeUsecase().ReplayWebhookEvents(suite.ctx, "organization_id", null.String{},
+		"webhook_id", suite.replayInput())
+	suite.ErrorIs(err, models.ForbiddenError)
+	suite.repository.AssertNotCalled(suite.T(), "ListWebhookEvents", mock.Anything, mock.Anything)
+	suite.AssertExpectations()
+}
+
+func (suite *WebhooksUsecaseTestSuite) TestReplayWebhookEvents_invalidRange() {
+	input := suite.replayInput()
+	input.StartDate, input.EndDate = input.EndDate, input.StartDate
+
+	_, err := suite.makeUsecase().ReplayWebhookEvents(suite.ctx, "organization_id", null.String{},
+		"webhook_id", input)
+	suite.ErrorIs(err, models.BadParameterError)
+	suite.AssertExpectations()
+}
+
+func TestWebhooksUsecase(t *testing.T) {
+	suite.Run(t, new(WebhooksUsecaseTestSuite))
+}
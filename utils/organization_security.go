@@ -8,7 +8,7 @@ import (
 )
 
 func EnforceOrganizationAccess(creds models.Credentials, organizationId string) error {
-	noOrgIdSecurity := creds.Role.HasPermission(models.ANY_ORGANIZATION_ID_IN_CONTEXT)
+	noOrgIdSecurity := creds.HasPermission(models.ANY_ORGANIZATION_ID_IN_CONTEXT)
 	if noOrgIdSecurity {
 		return nil
 	}
@@ -28,7 +28,7 @@ func EnforceOrganizationAccess(creds models.Credentials, organizationId string)
 }
 
 func EnforcePartnerAccess(creds models.Credentials, partnerId string) error {
-	noPartnerIdSecurity := creds.Role.HasPermission(models.ANY_PARTNER_ID_IN_CONTEXT)
+	noPartnerIdSecurity := creds.HasPermission(models.ANY_PARTNER_ID_IN_CONTEXT)
 	if noPartnerIdSecurity {
 		return nil
 	}